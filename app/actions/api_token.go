@@ -0,0 +1,105 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/models/query"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/getfider/fider/app/pkg/validate"
+)
+
+// CreateAPIToken is used to create a new API Token for current user
+type CreateAPIToken struct {
+	Model *models.CreateAPIToken
+}
+
+// Initialize the model
+func (input *CreateAPIToken) Initialize() interface{} {
+	input.Model = new(models.CreateAPIToken)
+	return input.Model
+}
+
+// IsAuthorized returns true if current user is authorized to perform this action
+func (input *CreateAPIToken) IsAuthorized(ctx context.Context, user *models.User) bool {
+	return user != nil && user.IsCollaborator()
+}
+
+// Validate if current model is valid
+func (input *CreateAPIToken) Validate(ctx context.Context, user *models.User) *validate.Result {
+	result := validate.Success()
+
+	input.Model.Name = strings.TrimSpace(input.Model.Name)
+	if input.Model.Name == "" {
+		result.AddFieldFailure("name", "Name is required.")
+	} else if len(input.Model.Name) > 60 {
+		result.AddFieldFailure("name", "Name must have less than 60 characters.")
+	}
+
+	if len(input.Model.Scopes) == 0 {
+		result.AddFieldFailure("scopes", "At least one scope is required.")
+	} else {
+		seen := make(map[enum.APIScope]bool)
+		scopes := make([]enum.APIScope, 0, len(input.Model.Scopes))
+		for _, scope := range input.Model.Scopes {
+			if !scope.IsValid() {
+				result.AddFieldFailure("scopes", fmt.Sprintf("Scope '%s' is invalid.", scope))
+			} else if scope.IsAdministratorOnly() && !user.IsAdministrator() {
+				result.AddFieldFailure("scopes", fmt.Sprintf("Only administrators can grant scope '%s'.", scope))
+			} else if !seen[scope] {
+				seen[scope] = true
+				scopes = append(scopes, scope)
+			}
+		}
+		input.Model.Scopes = scopes
+	}
+
+	if input.Model.ExpiresInDays < 0 || input.Model.ExpiresInDays > 365 {
+		result.AddFieldFailure("expiresInDays", "Expiration must be between 1 and 365 days, or 0 for tokens that never expire.")
+	}
+
+	return result
+}
+
+// RevokeAPIToken is used to revoke an existing API Token
+type RevokeAPIToken struct {
+	Token *models.APIToken
+	Model *models.RevokeAPIToken
+}
+
+// Initialize the model
+func (input *RevokeAPIToken) Initialize() interface{} {
+	input.Model = new(models.RevokeAPIToken)
+	return input.Model
+}
+
+// IsAuthorized returns true if current user is authorized to perform this action
+func (input *RevokeAPIToken) IsAuthorized(ctx context.Context, user *models.User) bool {
+	return user != nil
+}
+
+// Validate if current model is valid
+func (input *RevokeAPIToken) Validate(ctx context.Context, user *models.User) *validate.Result {
+	getToken := &query.GetAPITokenByID{TokenID: input.Model.ID}
+	if err := bus.Dispatch(ctx, getToken); err != nil {
+		if errors.Cause(err) == app.ErrNotFound {
+			result := validate.Success()
+			result.AddFieldFailure("id", "API Token not found.")
+			return result
+		}
+		return validate.Error(err)
+	}
+
+	//Administrators can revoke any token of the tenant, everyone else only their own
+	if getToken.Result.User.ID != user.ID && !user.IsAdministrator() {
+		return validate.Unauthorized()
+	}
+
+	input.Token = getToken.Result
+	return validate.Success()
+}
@@ -0,0 +1,110 @@
+package actions_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/getfider/fider/app/actions"
+	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/models/query"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/rand"
+)
+
+func TestCreateAPIToken_InvalidInput(t *testing.T) {
+	RegisterT(t)
+
+	collaborator := &models.User{ID: 2, Role: enum.RoleCollaborator}
+
+	testCases := []struct {
+		expected []string
+		input    *models.CreateAPIToken
+	}{
+		{
+			expected: []string{"name", "scopes"},
+			input:    &models.CreateAPIToken{},
+		},
+		{
+			expected: []string{"name", "scopes", "expiresInDays"},
+			input: &models.CreateAPIToken{
+				Name:          rand.String(61),
+				Scopes:        []enum.APIScope{"posts:everything"},
+				ExpiresInDays: 366,
+			},
+		},
+		{
+			expected: []string{"scopes", "expiresInDays"},
+			input: &models.CreateAPIToken{
+				Name:          "CI Bot",
+				Scopes:        []enum.APIScope{enum.APIScopePostsRead, enum.APIScopeUsersAdmin},
+				ExpiresInDays: -1,
+			},
+		},
+		{
+			expected: []string{"scopes"},
+			input: &models.CreateAPIToken{
+				Name:   "Compliance Bot",
+				Scopes: []enum.APIScope{enum.APIScopeAuditRead},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		action := &actions.CreateAPIToken{
+			Model: testCase.input,
+		}
+		result := action.Validate(context.Background(), collaborator)
+		ExpectFailed(result, testCase.expected...)
+	}
+}
+
+func TestCreateAPIToken_ValidInput(t *testing.T) {
+	RegisterT(t)
+
+	administrator := &models.User{ID: 1, Role: enum.RoleAdministrator}
+
+	action := &actions.CreateAPIToken{Model: &models.CreateAPIToken{
+		Name:          " CI Bot ",
+		Scopes:        []enum.APIScope{enum.APIScopePostsRead, enum.APIScopeUsersAdmin, enum.APIScopePostsRead},
+		ExpiresInDays: 30,
+	}}
+	result := action.Validate(context.Background(), administrator)
+	ExpectSuccess(result)
+	Expect(action.Model.Name).Equals("CI Bot")
+	Expect(action.Model.Scopes).Equals([]enum.APIScope{enum.APIScopePostsRead, enum.APIScopeUsersAdmin})
+}
+
+func TestCreateAPIToken_IsAuthorized(t *testing.T) {
+	RegisterT(t)
+
+	action := &actions.CreateAPIToken{}
+	Expect(action.IsAuthorized(context.Background(), nil)).IsFalse()
+	Expect(action.IsAuthorized(context.Background(), &models.User{Role: enum.RoleVisitor})).IsFalse()
+	Expect(action.IsAuthorized(context.Background(), &models.User{Role: enum.RoleCollaborator})).IsTrue()
+}
+
+func TestRevokeAPIToken(t *testing.T) {
+	RegisterT(t)
+
+	owner := &models.User{ID: 2, Role: enum.RoleCollaborator}
+	token := &models.APIToken{ID: 4, Name: "CI Bot", User: owner}
+	bus.AddHandler(func(ctx context.Context, q *query.GetAPITokenByID) error {
+		q.Result = token
+		return nil
+	})
+
+	action := &actions.RevokeAPIToken{Model: &models.RevokeAPIToken{ID: 4}}
+	result := action.Validate(context.Background(), owner)
+	ExpectSuccess(result)
+	Expect(action.Token).Equals(token)
+
+	action = &actions.RevokeAPIToken{Model: &models.RevokeAPIToken{ID: 4}}
+	result = action.Validate(context.Background(), &models.User{ID: 3, Role: enum.RoleCollaborator})
+	Expect(result.Authorized).IsFalse()
+
+	action = &actions.RevokeAPIToken{Model: &models.RevokeAPIToken{ID: 4}}
+	result = action.Validate(context.Background(), &models.User{ID: 1, Role: enum.RoleAdministrator})
+	ExpectSuccess(result)
+}
@@ -0,0 +1,70 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/pkg/i18n"
+	"github.com/getfider/fider/app/pkg/validate"
+	"github.com/getfider/fider/app/services/email"
+)
+
+// SaveEmailTemplate is used to customize an email template
+type SaveEmailTemplate struct {
+	Model *models.SaveEmailTemplate
+}
+
+// Initialize the model
+func (input *SaveEmailTemplate) Initialize() interface{} {
+	input.Model = new(models.SaveEmailTemplate)
+	return input.Model
+}
+
+// IsAuthorized returns true if current user is authorized to perform this action
+func (input *SaveEmailTemplate) IsAuthorized(ctx context.Context, user *models.User) bool {
+	return user != nil && user.IsAdministrator()
+}
+
+// Validate if current model is valid
+func (input *SaveEmailTemplate) Validate(ctx context.Context, user *models.User) *validate.Result {
+	result := validate.Success()
+
+	if !email.IsEditable(input.Model.Name) {
+		return validate.Failed(fmt.Sprintf("Email template '%s' can't be customized.", input.Model.Name))
+	}
+
+	input.Model.Subject = strings.TrimSpace(input.Model.Subject)
+	if input.Model.Subject == "" {
+		result.AddFieldFailure("subject", "Subject is required.")
+	} else if len(input.Model.Subject) > email.MaxTemplateSubjectLength {
+		result.AddFieldFailure("subject", fmt.Sprintf("Subject must have less than %d characters.", email.MaxTemplateSubjectLength))
+	} else if strings.ContainsAny(input.Model.Subject, "\r\n") {
+		result.AddFieldFailure("subject", "Subject must be a single line.")
+	} else if err := email.ValidateTemplate(input.Model.Subject); err != nil {
+		result.AddFieldFailure("subject", fmt.Sprintf("Subject is invalid: %s.", err.Error()))
+	}
+
+	if strings.TrimSpace(input.Model.Body) == "" {
+		result.AddFieldFailure("body", "Body is required.")
+	} else if len(input.Model.Body) > email.MaxTemplateBodyLength {
+		result.AddFieldFailure("body", fmt.Sprintf("Body must have less than %d characters.", email.MaxTemplateBodyLength))
+	} else if err := email.ValidateTemplate(input.Model.Body); err != nil {
+		result.AddFieldFailure("body", fmt.Sprintf("Body is invalid: %s.", err.Error()))
+	}
+
+	if result.Ok {
+		//Templates that are valid but fail to render with sample data would fail when sending emails
+		_, err := email.RenderTemplate(i18n.DefaultLocale, &models.EmailTemplate{
+			Name:    input.Model.Name,
+			Subject: input.Model.Subject,
+			Body:    input.Model.Body,
+		}, email.SampleProps(i18n.DefaultLocale, input.Model.Name, "", ""))
+		if err != nil {
+			result.AddFieldFailure("body", fmt.Sprintf("Template can't be rendered: %s.", err.Error()))
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,83 @@
+package actions_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/getfider/fider/app/actions"
+	"github.com/getfider/fider/app/models"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/mock"
+)
+
+func TestSaveEmailTemplate_InvalidInput(t *testing.T) {
+	RegisterT(t)
+
+	testCases := []struct {
+		expected []string
+		input    *models.SaveEmailTemplate
+	}{
+		{
+			expected: []string{"subject", "body"},
+			input:    &models.SaveEmailTemplate{Name: "new_post"},
+		},
+		{
+			expected: []string{"subject"},
+			input:    &models.SaveEmailTemplate{Name: "new_post", Subject: "Hello\nWorld", Body: "Hello"},
+		},
+		{
+			expected: []string{"subject"},
+			input:    &models.SaveEmailTemplate{Name: "new_post", Subject: strings.Repeat("a", 201), Body: "Hello"},
+		},
+		{
+			expected: []string{"subject", "body"},
+			input:    &models.SaveEmailTemplate{Name: "new_post", Subject: "{{ .title", Body: `{{ printf "%s" .title }}`},
+		},
+		{
+			expected: []string{"body"},
+			input:    &models.SaveEmailTemplate{Name: "new_post", Subject: "Hello", Body: strings.Repeat("a", 20001)},
+		},
+		{
+			expected: []string{"body"},
+			input:    &models.SaveEmailTemplate{Name: "new_post", Subject: "Hello", Body: "{{ range .title }}{{ end }}"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		action := &actions.SaveEmailTemplate{Model: testCase.input}
+		result := action.Validate(context.Background(), nil)
+		ExpectFailed(result, testCase.expected...)
+	}
+}
+
+func TestSaveEmailTemplate_NotEditable(t *testing.T) {
+	RegisterT(t)
+
+	action := &actions.SaveEmailTemplate{Model: &models.SaveEmailTemplate{Name: "signup_email", Subject: "Hello", Body: "Hello"}}
+	result := action.Validate(context.Background(), nil)
+	ExpectFailed(result)
+}
+
+func TestSaveEmailTemplate_ValidInput(t *testing.T) {
+	RegisterT(t)
+
+	action := &actions.SaveEmailTemplate{Model: &models.SaveEmailTemplate{
+		Name:    "new_comment",
+		Subject: "  [{{ .tenantName }}] {{ .title }}  ",
+		Body:    "<tr><td>{{ if .reply }}Reply!{{ end }}{{ .content }}</td></tr>",
+	}}
+	result := action.Validate(context.Background(), nil)
+	ExpectSuccess(result)
+	Expect(action.Model.Subject).Equals("[{{ .tenantName }}] {{ .title }}")
+}
+
+func TestSaveEmailTemplate_IsAuthorized(t *testing.T) {
+	RegisterT(t)
+	mock.NewServer()
+
+	action := &actions.SaveEmailTemplate{}
+	Expect(action.IsAuthorized(context.Background(), mock.JonSnow)).IsTrue()
+	Expect(action.IsAuthorized(context.Background(), mock.AryaStark)).IsFalse()
+	Expect(action.IsAuthorized(context.Background(), nil)).IsFalse()
+}
@@ -0,0 +1,47 @@
+package actions
+
+import (
+	"context"
+
+	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/pkg/validate"
+)
+
+var importKinds = map[string]bool{
+	"backup":   true,
+	"posts":    true,
+	"comments": true,
+	"votes":    true,
+}
+
+// ImportData is used to import data from a backup.zip or CSV file
+type ImportData struct {
+	Model *models.ImportData
+}
+
+// Initialize the model
+func (input *ImportData) Initialize() interface{} {
+	input.Model = new(models.ImportData)
+	return input.Model
+}
+
+// IsAuthorized returns true if current user is authorized to perform this action
+func (input *ImportData) IsAuthorized(ctx context.Context, user *models.User) bool {
+	return user != nil && user.Role == enum.RoleAdministrator
+}
+
+// Validate if current model is valid
+func (input *ImportData) Validate(ctx context.Context, user *models.User) *validate.Result {
+	result := validate.Success()
+
+	if !importKinds[input.Model.Kind] {
+		result.AddFieldFailure("kind", "Kind must be one of 'backup', 'posts', 'comments' or 'votes'.")
+	}
+
+	if len(input.Model.Content) == 0 {
+		result.AddFieldFailure("content", "File is required.")
+	}
+
+	return result
+}
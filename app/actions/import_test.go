@@ -0,0 +1,40 @@
+package actions_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/getfider/fider/app/actions"
+	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/models/enum"
+	. "github.com/getfider/fider/app/pkg/assert"
+)
+
+func TestImportData_Unauthorized(t *testing.T) {
+	RegisterT(t)
+
+	admin := &models.User{ID: 1, Role: enum.RoleAdministrator}
+	collaborator := &models.User{ID: 2, Role: enum.RoleCollaborator}
+
+	action := actions.ImportData{}
+	action.Initialize()
+
+	Expect(action.IsAuthorized(context.Background(), admin)).IsTrue()
+	Expect(action.IsAuthorized(context.Background(), collaborator)).IsFalse()
+	Expect(action.IsAuthorized(context.Background(), nil)).IsFalse()
+}
+
+func TestImportData_Validate(t *testing.T) {
+	RegisterT(t)
+
+	action := actions.ImportData{Model: &models.ImportData{}}
+	ExpectFailed(action.Validate(context.Background(), nil), "kind", "content")
+
+	action = actions.ImportData{Model: &models.ImportData{Kind: "tags", Content: []byte("name\n")}}
+	ExpectFailed(action.Validate(context.Background(), nil), "kind")
+
+	for _, kind := range []string{"backup", "posts", "comments", "votes"} {
+		action = actions.ImportData{Model: &models.ImportData{Kind: kind, Content: []byte("content")}}
+		ExpectSuccess(action.Validate(context.Background(), nil))
+	}
+}
@@ -15,7 +15,8 @@ import (
 
 // CreateEditOAuthConfig is used to create/edit OAuth config
 type CreateEditOAuthConfig struct {
-	Model *models.CreateEditOAuthConfig
+	Model  *models.CreateEditOAuthConfig
+	Config *models.OAuthConfig
 }
 
 // Initialize the model
@@ -41,6 +42,7 @@ func (input *CreateEditOAuthConfig) Validate(ctx context.Context, user *models.U
 			return validate.Error(err)
 		}
 
+		input.Config = getConfig.Result
 		input.Model.ID = getConfig.Result.ID
 		input.Model.Logo.BlobKey = getConfig.Result.LogoBlobKey
 		if input.Model.ClientSecret == "" {
@@ -85,6 +87,41 @@ func (input *CreateEditOAuthConfig) Validate(ctx context.Context, user *models.U
 		result.AddFieldFailure("clientSecret", "Client Secret must have less than 500 characters.")
 	}
 
+	if input.Model.Protocol == 0 {
+		input.Model.Protocol = enum.OAuthProtocolOAuth2
+	}
+
+	if input.Model.Protocol != enum.OAuthProtocolOAuth2 &&
+		input.Model.Protocol != enum.OAuthProtocolOIDC {
+		result.AddFieldFailure("protocol", "Invalid protocol.")
+	}
+
+	if input.Model.Protocol == enum.OAuthProtocolOIDC {
+		if input.Model.Scope == "" {
+			input.Model.Scope = "openid profile email"
+		}
+
+		if input.Model.IssuerURL == "" {
+			result.AddFieldFailure("issuerURL", "Issuer URL is required.")
+		} else if messages := validate.URL(input.Model.IssuerURL); len(messages) > 0 {
+			result.AddFieldFailure("issuerURL", messages...)
+		}
+
+		if len(input.Model.Scope) > 100 {
+			result.AddFieldFailure("scope", "Scope must have less than 100 characters.")
+		}
+
+		input.Model.AuthorizeURL = ""
+		input.Model.TokenURL = ""
+		input.Model.ProfileURL = ""
+		input.Model.JSONUserIDPath = ""
+		input.Model.JSONUserNamePath = ""
+		input.Model.JSONUserEmailPath = ""
+		return result
+	}
+
+	input.Model.IssuerURL = ""
+
 	if input.Model.Scope == "" {
 		result.AddFieldFailure("scope", "Scope is required.")
 	} else if len(input.Model.Scope) > 100 {
@@ -86,6 +86,50 @@ func TestCreateEditOAuthConfig_AddNew_ValidInput(t *testing.T) {
 	Expect(string(input.Provider[0])).Equals("_")
 }
 
+func TestCreateEditOAuthConfig_OIDC_InvalidInput(t *testing.T) {
+	RegisterT(t)
+
+	action := &actions.CreateEditOAuthConfig{
+		Model: &models.CreateEditOAuthConfig{
+			Protocol:     enum.OAuthProtocolOIDC,
+			DisplayName:  "Keycloak",
+			Status:       enum.OAuthConfigEnabled,
+			ClientID:     "fider",
+			ClientSecret: "my-secret",
+			IssuerURL:    "not-an-url",
+		},
+	}
+	ExpectFailed(action.Validate(context.Background(), nil), "issuerURL")
+
+	action.Model.Provider = ""
+	action.Model.Protocol = 3
+	ExpectFailed(action.Validate(context.Background(), nil), "protocol", "authorizeURL", "tokenURL", "jsonUserIDPath")
+}
+
+func TestCreateEditOAuthConfig_OIDC_ValidInput(t *testing.T) {
+	RegisterT(t)
+
+	input := &models.CreateEditOAuthConfig{
+		Protocol:       enum.OAuthProtocolOIDC,
+		DisplayName:    "Keycloak",
+		Status:         enum.OAuthConfigEnabled,
+		ClientID:       "fider",
+		ClientSecret:   "my-secret",
+		IssuerURL:      "https://auth.example.com/realms/Fider",
+		AuthorizeURL:   "http://provider/oauth/authorize",
+		JSONUserIDPath: "user.id",
+	}
+	action := &actions.CreateEditOAuthConfig{
+		Model: input,
+	}
+	result := action.Validate(context.Background(), nil)
+	ExpectSuccess(result)
+	Expect(input.Scope).Equals("openid profile email")
+	Expect(input.IssuerURL).Equals("https://auth.example.com/realms/Fider")
+	Expect(input.AuthorizeURL).Equals("")
+	Expect(input.JSONUserIDPath).Equals("")
+}
+
 func TestCreateEditOAuthConfig_EditExisting_NewSecret(t *testing.T) {
 	RegisterT(t)
 
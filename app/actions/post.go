@@ -2,6 +2,8 @@ package actions
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/getfider/fider/app/models/enum"
 	"github.com/getfider/fider/app/models/query"
@@ -10,6 +12,7 @@ import (
 
 	"github.com/getfider/fider/app"
 	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/pkg/env"
 	"github.com/getfider/fider/app/pkg/errors"
 	"github.com/getfider/fider/app/pkg/validate"
 )
@@ -59,6 +62,12 @@ func (input *CreateNewPost) Validate(ctx context.Context, user *models.User) *va
 	}
 	result.AddFieldFailure("attachments", messages...)
 
+	messages, err = validate.MultiFileUpload(ctx, nil, input.Model.Files, fileUploadOpts(ctx, 3))
+	if err != nil {
+		return validate.Error(err)
+	}
+	result.AddFieldFailure("files", messages...)
+
 	return result
 }
 
@@ -126,6 +135,19 @@ func (input *UpdatePost) Validate(ctx context.Context, user *models.User) *valid
 		result.AddFieldFailure("attachments", messages...)
 	}
 
+	if len(input.Model.Files) > 0 {
+		getFiles := &query.GetFileAttachments{Post: input.Post}
+		if err := bus.Dispatch(ctx, getFiles); err != nil {
+			return validate.Error(err)
+		}
+
+		messages, err := validate.MultiFileUpload(ctx, getFiles.Result, input.Model.Files, fileUploadOpts(ctx, 3))
+		if err != nil {
+			return validate.Error(err)
+		}
+		result.AddFieldFailure("files", messages...)
+	}
+
 	return result
 }
 
@@ -163,6 +185,12 @@ func (input *AddNewComment) Validate(ctx context.Context, user *models.User) *va
 	}
 	result.AddFieldFailure("attachments", messages...)
 
+	messages, err = validate.MultiFileUpload(ctx, nil, input.Model.Files, fileUploadOpts(ctx, 2))
+	if err != nil {
+		return validate.Error(err)
+	}
+	result.AddFieldFailure("files", messages...)
+
 	return result
 }
 
@@ -252,6 +280,165 @@ func (input *DeletePost) Validate(ctx context.Context, user *models.User) *valid
 	return validate.Success()
 }
 
+// AddVote represents the action of a user voting on a post
+type AddVote struct {
+	Model *models.AddVote
+	Post  *models.Post
+}
+
+// Initialize the model
+func (input *AddVote) Initialize() interface{} {
+	input.Model = new(models.AddVote)
+	return input.Model
+}
+
+// IsAuthorized returns true if current user is authorized to perform this action
+func (input *AddVote) IsAuthorized(ctx context.Context, user *models.User) bool {
+	return user != nil
+}
+
+// Validate if current model is valid
+func (input *AddVote) Validate(ctx context.Context, user *models.User) *validate.Result {
+	getPost := &query.GetPostByNumber{Number: input.Model.Number}
+	if err := bus.Dispatch(ctx, getPost); err != nil {
+		return validate.Error(err)
+	}
+	input.Post = getPost.Result
+
+	tenant, _ := ctx.Value(app.TenantCtxKey).(*models.Tenant)
+	if tenant == nil || tenant.VotingMode != enum.VotingModeBudgeted {
+		input.Model.Credits = 0
+		return validate.Success()
+	}
+
+	if input.Model.Credits == 0 {
+		input.Model.Credits = 1
+	}
+
+	if input.Model.Credits < 1 || input.Model.Credits > models.MaxVoteCredits {
+		result := validate.Success()
+		result.AddFieldFailure("credits", fmt.Sprintf("Credits must be between 1 and %d.", models.MaxVoteCredits))
+		return result
+	}
+
+	usedCredits := &query.GetUsedVoteCredits{ExcludePostID: input.Post.ID}
+	if err := bus.Dispatch(ctx, usedCredits); err != nil {
+		return validate.Error(err)
+	}
+
+	if usedCredits.Result+input.Model.Credits > tenant.VoteBudget {
+		return validate.Failed(fmt.Sprintf("You don't have enough credits left. You have %d of %d credits available.", tenant.VoteBudget-usedCredits.Result, tenant.VoteBudget))
+	}
+
+	return validate.Success()
+}
+
+// MergePost represents the action of an administrator merging a post into another
+type MergePost struct {
+	Model    *models.MergePost
+	Post     *models.Post
+	Original *models.Post
+}
+
+// Initialize the model
+func (input *MergePost) Initialize() interface{} {
+	input.Model = new(models.MergePost)
+	return input.Model
+}
+
+// IsAuthorized returns true if current user is authorized to perform this action
+func (input *MergePost) IsAuthorized(ctx context.Context, user *models.User) bool {
+	return user != nil && user.IsAdministrator()
+}
+
+// Validate if current model is valid
+func (input *MergePost) Validate(ctx context.Context, user *models.User) *validate.Result {
+	getPost := &query.GetPostByNumber{Number: input.Model.Number}
+	if err := bus.Dispatch(ctx, getPost); err != nil {
+		return validate.Error(err)
+	}
+	input.Post = getPost.Result
+
+	if input.Model.OriginalNumber == input.Model.Number {
+		return validate.Failed("Cannot merge a post into itself.")
+	}
+
+	getOriginal := &query.GetPostByNumber{Number: input.Model.OriginalNumber}
+	if err := bus.Dispatch(ctx, getOriginal); err != nil {
+		if errors.Cause(err) == app.ErrNotFound {
+			result := validate.Success()
+			result.AddFieldFailure("originalNumber", "Original post not found")
+			return result
+		}
+		return validate.Error(err)
+	}
+	input.Original = getOriginal.Result
+
+	if input.Original.Status == enum.PostDuplicate {
+		return validate.Failed("Cannot merge into a post that is a duplicate.")
+	}
+
+	getMerge := &query.GetActivePostMerge{PostID: input.Post.ID}
+	err := bus.Dispatch(ctx, getMerge)
+	if err == nil {
+		return validate.Failed("This post has already been merged into another post.")
+	} else if errors.Cause(err) != app.ErrNotFound {
+		return validate.Error(err)
+	}
+
+	return validate.Success()
+}
+
+// UnmergePost represents the action of an administrator reverting a previous merge
+type UnmergePost struct {
+	Model *models.UnmergePost
+	Post  *models.Post
+	Merge *models.PostMerge
+}
+
+// Initialize the model
+func (input *UnmergePost) Initialize() interface{} {
+	input.Model = new(models.UnmergePost)
+	return input.Model
+}
+
+// IsAuthorized returns true if current user is authorized to perform this action
+func (input *UnmergePost) IsAuthorized(ctx context.Context, user *models.User) bool {
+	return user != nil && user.IsAdministrator()
+}
+
+// Validate if current model is valid
+func (input *UnmergePost) Validate(ctx context.Context, user *models.User) *validate.Result {
+	getPost := &query.GetPostByNumber{Number: input.Model.Number}
+	if err := bus.Dispatch(ctx, getPost); err != nil {
+		return validate.Error(err)
+	}
+	input.Post = getPost.Result
+
+	getMerge := &query.GetActivePostMerge{PostID: input.Post.ID}
+	if err := bus.Dispatch(ctx, getMerge); err != nil {
+		if errors.Cause(err) == app.ErrNotFound {
+			return validate.Failed("This post has not been merged into another post.")
+		}
+		return validate.Error(err)
+	}
+	input.Merge = getMerge.Result
+
+	if time.Since(input.Merge.MergedAt) > env.Config.Posts.UnmergeWindow {
+		return validate.Failed("This post was merged too long ago and can no longer be unmerged.")
+	}
+
+	getOriginalMerge := &query.GetActivePostMerge{PostID: input.Merge.OriginalID}
+	err := bus.Dispatch(ctx, getOriginalMerge)
+	if err == nil {
+		return validate.Failed("The original post has since been merged into another post, unmerge it first.")
+	} else if errors.Cause(err) != app.ErrNotFound {
+		return validate.Error(err)
+	}
+
+	return validate.Success()
+}
+
 // EditComment represents the action to update an existing comment
 type EditComment struct {
 	Model   *models.EditComment
@@ -304,6 +491,19 @@ func (input *EditComment) Validate(ctx context.Context, user *models.User) *vali
 		result.AddFieldFailure("attachments", messages...)
 	}
 
+	if len(input.Model.Files) > 0 {
+		getFiles := &query.GetFileAttachments{Post: input.Post, Comment: input.Comment}
+		if err := bus.Dispatch(ctx, getFiles); err != nil {
+			return validate.Error(err)
+		}
+
+		messages, err := validate.MultiFileUpload(ctx, getFiles.Result, input.Model.Files, fileUploadOpts(ctx, 2))
+		if err != nil {
+			return validate.Error(err)
+		}
+		result.AddFieldFailure("files", messages...)
+	}
+
 	return result
 }
 
@@ -332,3 +532,16 @@ func (input *DeleteComment) IsAuthorized(ctx context.Context, user *models.User)
 func (input *DeleteComment) Validate(ctx context.Context, user *models.User) *validate.Result {
 	return validate.Success()
 }
+
+//fileUploadOpts returns the limits of file attachments on current tenant
+func fileUploadOpts(ctx context.Context, maxUploads int) validate.MultiFileUploadOpts {
+	opts := validate.MultiFileUploadOpts{
+		MaxUploads:     maxUploads,
+		MaxKilobytes:   env.Config.Attachments.MaxKilobytes,
+		QuotaKilobytes: env.Config.Attachments.TenantQuotaMegabytes * 1024,
+	}
+	if tenant, ok := ctx.Value(app.TenantCtxKey).(*models.Tenant); ok {
+		opts.AllowedTypes = tenant.AllowedFileTypes
+	}
+	return opts
+}
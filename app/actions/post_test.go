@@ -135,3 +135,74 @@ func TestDeleteComment(t *testing.T) {
 	authorized = action.IsAuthorized(context.Background(), administrator)
 	Expect(authorized).IsTrue()
 }
+
+func TestAddVote_SimpleVoting(t *testing.T) {
+	RegisterT(t)
+
+	post := &models.Post{ID: 1, Number: 1, Title: "Post 1"}
+	bus.AddHandler(func(ctx context.Context, q *query.GetPostByNumber) error {
+		q.Result = post
+		return nil
+	})
+
+	ctx := context.WithValue(context.Background(), app.TenantCtxKey, &models.Tenant{ID: 1, VotingMode: enum.VotingModeSimple})
+	action := &actions.AddVote{Model: &models.AddVote{Number: post.Number, Credits: 3}}
+	ExpectSuccess(action.Validate(ctx, nil))
+	Expect(action.Post).Equals(post)
+	Expect(action.Model.Credits).Equals(0)
+}
+
+func TestAddVote_BudgetedVoting(t *testing.T) {
+	RegisterT(t)
+
+	post := &models.Post{ID: 1, Number: 1, Title: "Post 1"}
+	bus.AddHandler(func(ctx context.Context, q *query.GetPostByNumber) error {
+		q.Result = post
+		return nil
+	})
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetUsedVoteCredits) error {
+		Expect(q.ExcludePostID).Equals(post.ID)
+		q.Result = 7
+		return nil
+	})
+
+	ctx := context.WithValue(context.Background(), app.TenantCtxKey, &models.Tenant{ID: 1, VotingMode: enum.VotingModeBudgeted, VoteBudget: 10})
+
+	action := &actions.AddVote{Model: &models.AddVote{Number: post.Number}}
+	ExpectSuccess(action.Validate(ctx, nil))
+	Expect(action.Model.Credits).Equals(1)
+
+	action = &actions.AddVote{Model: &models.AddVote{Number: post.Number, Credits: 3}}
+	ExpectSuccess(action.Validate(ctx, nil))
+	Expect(action.Model.Credits).Equals(3)
+
+	action = &actions.AddVote{Model: &models.AddVote{Number: post.Number, Credits: 4}}
+	ExpectFailed(action.Validate(ctx, nil), "credits")
+
+	action = &actions.AddVote{Model: &models.AddVote{Number: post.Number, Credits: -1}}
+	ExpectFailed(action.Validate(ctx, nil), "credits")
+}
+
+func TestAddVote_BudgetExceeded(t *testing.T) {
+	RegisterT(t)
+
+	post := &models.Post{ID: 1, Number: 1, Title: "Post 1"}
+	bus.AddHandler(func(ctx context.Context, q *query.GetPostByNumber) error {
+		q.Result = post
+		return nil
+	})
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetUsedVoteCredits) error {
+		q.Result = 9
+		return nil
+	})
+
+	ctx := context.WithValue(context.Background(), app.TenantCtxKey, &models.Tenant{ID: 1, VotingMode: enum.VotingModeBudgeted, VoteBudget: 10})
+
+	action := &actions.AddVote{Model: &models.AddVote{Number: post.Number, Credits: 1}}
+	ExpectSuccess(action.Validate(ctx, nil))
+
+	action = &actions.AddVote{Model: &models.AddVote{Number: post.Number, Credits: 2}}
+	ExpectFailed(action.Validate(ctx, nil))
+}
@@ -0,0 +1,80 @@
+package actions
+
+import (
+	"context"
+	"strings"
+
+	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/pkg/saml"
+	"github.com/getfider/fider/app/pkg/validate"
+)
+
+// CreateEditSAMLConfig is used to create/edit the SAML configuration
+type CreateEditSAMLConfig struct {
+	Model *models.CreateEditSAMLConfig
+}
+
+// Initialize the model
+func (input *CreateEditSAMLConfig) Initialize() interface{} {
+	input.Model = new(models.CreateEditSAMLConfig)
+	return input.Model
+}
+
+// IsAuthorized returns true if current user is authorized to perform this action
+func (input *CreateEditSAMLConfig) IsAuthorized(ctx context.Context, user *models.User) bool {
+	return user != nil && user.IsAdministrator()
+}
+
+// Validate if current model is valid
+func (input *CreateEditSAMLConfig) Validate(ctx context.Context, user *models.User) *validate.Result {
+	result := validate.Success()
+
+	input.Model.DisplayName = strings.TrimSpace(input.Model.DisplayName)
+	if input.Model.DisplayName == "" {
+		result.AddFieldFailure("displayName", "Display Name is required.")
+	} else if len(input.Model.DisplayName) > 50 {
+		result.AddFieldFailure("displayName", "Display Name must have less than 50 characters.")
+	}
+
+	input.Model.IdPMetadata = strings.TrimSpace(input.Model.IdPMetadata)
+	if input.Model.IdPMetadata == "" {
+		result.AddFieldFailure("idpMetadata", "Identity Provider metadata is required.")
+	} else {
+		idp, err := saml.ParseMetadata([]byte(input.Model.IdPMetadata))
+		if err != nil {
+			result.AddFieldFailure("idpMetadata", "Identity Provider metadata is invalid.")
+		} else if len(idp.EntityID) > 300 || len(idp.SSOURL) > 300 {
+			result.AddFieldFailure("idpMetadata", "Identity Provider Entity ID and Single Sign-On URL must have less than 300 characters.")
+		} else {
+			input.Model.IdPEntityID = idp.EntityID
+			input.Model.IdPSSOURL = idp.SSOURL
+		}
+	}
+
+	if len(input.Model.NameAttribute) > 100 {
+		result.AddFieldFailure("nameAttribute", "Name attribute must have less than 100 characters.")
+	}
+
+	if len(input.Model.EmailAttribute) > 100 {
+		result.AddFieldFailure("emailAttribute", "Email attribute must have less than 100 characters.")
+	}
+
+	if len(input.Model.RoleAttribute) > 100 {
+		result.AddFieldFailure("roleAttribute", "Role attribute must have less than 100 characters.")
+	}
+
+	if input.Model.RoleAttribute == "" {
+		input.Model.AdministratorRoles = ""
+		input.Model.CollaboratorRoles = ""
+	}
+
+	if len(input.Model.AdministratorRoles) > 300 {
+		result.AddFieldFailure("administratorRoles", "Administrator roles must have less than 300 characters.")
+	}
+
+	if len(input.Model.CollaboratorRoles) > 300 {
+		result.AddFieldFailure("collaboratorRoles", "Collaborator roles must have less than 300 characters.")
+	}
+
+	return result
+}
@@ -0,0 +1,99 @@
+package actions_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/getfider/fider/app/actions"
+	"github.com/getfider/fider/app/models"
+	. "github.com/getfider/fider/app/pkg/assert"
+	fiderrand "github.com/getfider/fider/app/pkg/rand"
+)
+
+func samlTestMetadata(t *testing.T) string {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "idp.example.org"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	cert, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return `<md:EntityDescriptor xmlns:md="urn:oasis:names:tc:SAML:2.0:metadata" entityID="https://idp.example.org">
+  <md:IDPSSODescriptor protocolSupportEnumeration="urn:oasis:names:tc:SAML:2.0:protocol">
+    <md:KeyDescriptor use="signing">
+      <ds:KeyInfo xmlns:ds="http://www.w3.org/2000/09/xmldsig#">
+        <ds:X509Data><ds:X509Certificate>` + base64.StdEncoding.EncodeToString(cert) + `</ds:X509Certificate></ds:X509Data>
+      </ds:KeyInfo>
+    </md:KeyDescriptor>
+    <md:SingleSignOnService Binding="urn:oasis:names:tc:SAML:2.0:bindings:HTTP-Redirect" Location="https://idp.example.org/sso"/>
+  </md:IDPSSODescriptor>
+</md:EntityDescriptor>`
+}
+
+func TestCreateEditSAMLConfig_InvalidInput(t *testing.T) {
+	RegisterT(t)
+
+	testCases := []struct {
+		expected []string
+		input    *models.CreateEditSAMLConfig
+	}{
+		{
+			expected: []string{"displayName", "idpMetadata"},
+			input:    &models.CreateEditSAMLConfig{},
+		},
+		{
+			expected: []string{"displayName", "idpMetadata", "nameAttribute", "emailAttribute", "roleAttribute", "administratorRoles", "collaboratorRoles"},
+			input: &models.CreateEditSAMLConfig{
+				DisplayName:        fiderrand.String(51),
+				IdPMetadata:        "<md:EntityDescriptor />",
+				NameAttribute:      fiderrand.String(101),
+				EmailAttribute:     fiderrand.String(101),
+				RoleAttribute:      fiderrand.String(101),
+				AdministratorRoles: fiderrand.String(301),
+				CollaboratorRoles:  fiderrand.String(301),
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		action := &actions.CreateEditSAMLConfig{
+			Model: testCase.input,
+		}
+		result := action.Validate(context.Background(), nil)
+		ExpectFailed(result, testCase.expected...)
+	}
+}
+
+func TestCreateEditSAMLConfig_ValidInput(t *testing.T) {
+	RegisterT(t)
+
+	action := &actions.CreateEditSAMLConfig{
+		Model: &models.CreateEditSAMLConfig{
+			IsEnabled:          true,
+			DisplayName:        " Okta ",
+			IdPMetadata:        samlTestMetadata(t),
+			EmailAttribute:     "email",
+			AdministratorRoles: "admins",
+		},
+	}
+	result := action.Validate(context.Background(), nil)
+	ExpectSuccess(result)
+	Expect(action.Model.DisplayName).Equals("Okta")
+	Expect(action.Model.IdPEntityID).Equals("https://idp.example.org")
+	Expect(action.Model.IdPSSOURL).Equals("https://idp.example.org/sso")
+	Expect(action.Model.AdministratorRoles).Equals("")
+}
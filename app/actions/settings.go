@@ -3,9 +3,11 @@ package actions
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/getfider/fider/app/models"
 	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/pkg/i18n"
 	"github.com/getfider/fider/app/pkg/validate"
 )
 
@@ -55,6 +57,10 @@ func (input *UpdateUserSettings) Validate(ctx context.Context, user *models.User
 	}
 	result.AddFieldFailure("avatar", messages...)
 
+	if input.Model.Locale != "" && !i18n.IsSupported(input.Model.Locale) {
+		result.AddFieldFailure("locale", fmt.Sprintf("Language %s is not supported.", input.Model.Locale))
+	}
+
 	if input.Model.Settings != nil {
 		for k, v := range input.Model.Settings {
 			ok := false
@@ -64,6 +70,17 @@ func (input *UpdateUserSettings) Validate(ctx context.Context, user *models.User
 					if !e.Validate(v) {
 						result.AddFieldFailure("settings", fmt.Sprintf("Settings %s has an invalid value %s.", k, v))
 					}
+				} else if e.DigestSettingsKeyName == k {
+					ok = true
+					if !enum.NotificationDigest(v).IsValid() {
+						result.AddFieldFailure("settings", fmt.Sprintf("Settings %s has an invalid value %s.", k, v))
+					}
+				}
+			}
+			if k == enum.TimezoneSettingsKeyName {
+				ok = true
+				if _, err := time.LoadLocation(v); err != nil {
+					result.AddFieldFailure("settings", fmt.Sprintf("Timezone %s is unknown.", v))
 				}
 			}
 			if !ok {
@@ -53,6 +53,12 @@ func TestInvalidSettings(t *testing.T) {
 		map[string]string{
 			enum.NotificationEventNewComment.UserSettingsKeyName: "4",
 		},
+		map[string]string{
+			enum.NotificationEventNewComment.DigestSettingsKeyName: "monthly",
+		},
+		map[string]string{
+			enum.TimezoneSettingsKeyName: "Mars/Olympus_Mons",
+		},
 	} {
 		action := actions.UpdateUserSettings{}
 		action.Initialize()
@@ -76,6 +82,12 @@ func TestValidSettings(t *testing.T) {
 		map[string]string{
 			enum.NotificationEventNewComment.UserSettingsKeyName: enum.NotificationEventNewComment.DefaultSettingValue,
 		},
+		map[string]string{
+			enum.NotificationEventNewPost.DigestSettingsKeyName:      string(enum.NotificationDigestWeekly),
+			enum.NotificationEventNewComment.DigestSettingsKeyName:   string(enum.NotificationDigestDaily),
+			enum.NotificationEventChangeStatus.DigestSettingsKeyName: string(enum.NotificationDigestImmediate),
+			enum.TimezoneSettingsKeyName:                             "America/Sao_Paulo",
+		},
 	} {
 		action := actions.UpdateUserSettings{}
 		action.Initialize()
@@ -91,3 +103,26 @@ func TestValidSettings(t *testing.T) {
 		Expect(action.Model.Avatar.BlobKey).Equals("jon.png")
 	}
 }
+
+func TestUserSettings_Locale(t *testing.T) {
+	RegisterT(t)
+
+	for locale, isValid := range map[string]bool{
+		"":   true,
+		"fr": true,
+		"de": true,
+		"es": false,
+	} {
+		action := actions.UpdateUserSettings{}
+		action.Initialize()
+		action.Model.Name = "John Snow"
+		action.Model.AvatarType = enum.AvatarTypeGravatar
+		action.Model.Locale = locale
+		result := action.Validate(context.Background(), &models.User{})
+		if isValid {
+			ExpectSuccess(result)
+		} else {
+			ExpectFailed(result, "locale")
+		}
+	}
+}
@@ -2,11 +2,15 @@ package actions
 
 import (
 	"context"
+	"fmt"
+	"strings"
+	"unicode"
 
 	"github.com/getfider/fider/app"
 	"github.com/getfider/fider/app/models"
 	"github.com/getfider/fider/app/models/enum"
 	"github.com/getfider/fider/app/pkg/env"
+	"github.com/getfider/fider/app/pkg/i18n"
 	"github.com/getfider/fider/app/pkg/jwt"
 	"github.com/getfider/fider/app/pkg/validate"
 )
@@ -134,6 +138,12 @@ func (input *UpdateTenantSettings) Validate(ctx context.Context, user *models.Us
 		result.AddFieldFailure("cname", messages...)
 	}
 
+	if input.Model.Locale == "" {
+		input.Model.Locale = i18n.TenantLocale(tenant)
+	} else if !i18n.IsSupported(input.Model.Locale) {
+		result.AddFieldFailure("locale", fmt.Sprintf("Language %s is not supported.", input.Model.Locale))
+	}
+
 	return result
 }
 
@@ -155,7 +165,19 @@ func (input *UpdateTenantAdvancedSettings) IsAuthorized(ctx context.Context, use
 
 // Validate if current model is valid
 func (input *UpdateTenantAdvancedSettings) Validate(ctx context.Context, user *models.User) *validate.Result {
-	return validate.Success()
+	result := validate.Success()
+
+	types := strings.FieldsFunc(strings.ToLower(input.Model.AllowedFileTypes), func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	})
+	input.Model.AllowedFileTypes = strings.Join(types, ", ")
+	if len(input.Model.AllowedFileTypes) > 2000 {
+		result.AddFieldFailure("allowedFileTypes", "Allowed file types must have less than 2000 characters.")
+	} else {
+		result.AddFieldFailure("allowedFileTypes", validate.FileTypes(input.Model.AllowedFileTypes)...)
+	}
+
+	return result
 }
 
 //UpdateTenantPrivacy is the input model used to update tenant privacy settings
@@ -178,3 +200,38 @@ func (input *UpdateTenantPrivacy) IsAuthorized(ctx context.Context, user *models
 func (input *UpdateTenantPrivacy) Validate(ctx context.Context, user *models.User) *validate.Result {
 	return validate.Success()
 }
+
+//UpdateTenantVoting is the input model used to update tenant voting settings
+type UpdateTenantVoting struct {
+	Model *models.UpdateTenantVoting
+}
+
+// Initialize the model
+func (input *UpdateTenantVoting) Initialize() interface{} {
+	input.Model = new(models.UpdateTenantVoting)
+	return input.Model
+}
+
+// IsAuthorized returns true if current user is authorized to perform this action
+func (input *UpdateTenantVoting) IsAuthorized(ctx context.Context, user *models.User) bool {
+	return user != nil && user.Role == enum.RoleAdministrator
+}
+
+// Validate if current model is valid
+func (input *UpdateTenantVoting) Validate(ctx context.Context, user *models.User) *validate.Result {
+	result := validate.Success()
+
+	if input.Model.Mode != enum.VotingModeSimple && input.Model.Mode != enum.VotingModeBudgeted {
+		result.AddFieldFailure("mode", "Voting mode is invalid.")
+	}
+
+	if input.Model.Mode == enum.VotingModeBudgeted && (input.Model.Budget < 1 || input.Model.Budget > 100) {
+		result.AddFieldFailure("budget", "Budget must be between 1 and 100 credits.")
+	}
+
+	if input.Model.Mode == enum.VotingModeSimple && input.Model.Budget == 0 {
+		input.Model.Budget = 10
+	}
+
+	return result
+}
@@ -175,6 +175,30 @@ func TestUpdateTenantSettings_LargeInvitation(t *testing.T) {
 	ExpectFailed(result, "invitation")
 }
 
+func TestUpdateTenantSettings_InvalidLocale(t *testing.T) {
+	RegisterT(t)
+
+	action := actions.UpdateTenantSettings{Model: &models.UpdateTenantSettings{Title: "Ok", Locale: "es"}}
+	result := action.Validate(context.Background(), nil)
+	ExpectFailed(result, "locale")
+}
+
+func TestUpdateTenantSettings_DefaultLocale(t *testing.T) {
+	RegisterT(t)
+
+	ctx := context.WithValue(context.Background(), app.TenantCtxKey, &models.Tenant{
+		ID:     1,
+		Locale: "de",
+	})
+
+	action := actions.UpdateTenantSettings{}
+	action.Initialize()
+	action.Model.Title = "OK"
+	result := action.Validate(ctx, nil)
+	ExpectSuccess(result)
+	Expect(action.Model.Locale).Equals("de")
+}
+
 func TestUpdateTenantSettings_ExistingTenant_WithLogo(t *testing.T) {
 	RegisterT(t)
 
@@ -191,3 +215,51 @@ func TestUpdateTenantSettings_ExistingTenant_WithLogo(t *testing.T) {
 	ExpectSuccess(result)
 	Expect(action.Model.Logo.BlobKey).Equals("hello-world.png")
 }
+
+func TestUpdateTenantVoting_Unauthorized(t *testing.T) {
+	RegisterT(t)
+
+	admin := &models.User{ID: 1, Role: enum.RoleAdministrator}
+	collaborator := &models.User{ID: 2, Role: enum.RoleCollaborator}
+
+	action := actions.UpdateTenantVoting{}
+	action.Initialize()
+
+	Expect(action.IsAuthorized(context.Background(), admin)).IsTrue()
+	Expect(action.IsAuthorized(context.Background(), collaborator)).IsFalse()
+	Expect(action.IsAuthorized(context.Background(), nil)).IsFalse()
+}
+
+func TestUpdateTenantVoting_Validate(t *testing.T) {
+	RegisterT(t)
+
+	action := actions.UpdateTenantVoting{Model: &models.UpdateTenantVoting{}}
+	ExpectFailed(action.Validate(context.Background(), nil), "mode")
+
+	action = actions.UpdateTenantVoting{Model: &models.UpdateTenantVoting{Mode: enum.VotingModeBudgeted, Budget: 0}}
+	ExpectFailed(action.Validate(context.Background(), nil), "budget")
+
+	action = actions.UpdateTenantVoting{Model: &models.UpdateTenantVoting{Mode: enum.VotingModeBudgeted, Budget: 101}}
+	ExpectFailed(action.Validate(context.Background(), nil), "budget")
+
+	action = actions.UpdateTenantVoting{Model: &models.UpdateTenantVoting{Mode: enum.VotingModeBudgeted, Budget: 15}}
+	ExpectSuccess(action.Validate(context.Background(), nil))
+
+	action = actions.UpdateTenantVoting{Model: &models.UpdateTenantVoting{Mode: enum.VotingModeSimple}}
+	ExpectSuccess(action.Validate(context.Background(), nil))
+	Expect(action.Model.Budget).Equals(10)
+}
+
+func TestUpdateTenantAdvancedSettings_AllowedFileTypes(t *testing.T) {
+	RegisterT(t)
+
+	action := actions.UpdateTenantAdvancedSettings{Model: &models.UpdateTenantAdvancedSettings{AllowedFileTypes: "Application/PDF,text/*\n image/png "}}
+	ExpectSuccess(action.Validate(context.Background(), nil))
+	Expect(action.Model.AllowedFileTypes).Equals("application/pdf, text/*, image/png")
+
+	action = actions.UpdateTenantAdvancedSettings{Model: &models.UpdateTenantAdvancedSettings{AllowedFileTypes: ""}}
+	ExpectSuccess(action.Validate(context.Background(), nil))
+
+	action = actions.UpdateTenantAdvancedSettings{Model: &models.UpdateTenantAdvancedSettings{AllowedFileTypes: "pdf, */*"}}
+	ExpectFailed(action.Validate(context.Background(), nil), "allowedFileTypes")
+}
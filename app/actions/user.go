@@ -59,6 +59,7 @@ func (input *CreateUser) Validate(ctx context.Context, user *models.User) *valid
 //ChangeUserRole is the input model change role of an user
 type ChangeUserRole struct {
 	Model *models.ChangeUserRole
+	User  *models.User
 }
 
 // Initialize the model
@@ -96,6 +97,8 @@ func (input *ChangeUserRole) Validate(ctx context.Context, user *models.User) *v
 		}
 	} else if userByID.Result.Tenant.ID != user.Tenant.ID {
 		result.AddFieldFailure("userID", "User not found.")
+	} else {
+		input.User = userByID.Result
 	}
 	return result
 }
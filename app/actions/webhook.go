@@ -0,0 +1,97 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/models/query"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/validate"
+)
+
+// CreateEditWebhook is used to create a new webhook or edit existing
+type CreateEditWebhook struct {
+	Webhook *models.Webhook
+	Model   *models.CreateEditWebhook
+}
+
+// Initialize the model
+func (input *CreateEditWebhook) Initialize() interface{} {
+	input.Model = new(models.CreateEditWebhook)
+	return input.Model
+}
+
+// IsAuthorized returns true if current user is authorized to perform this action
+func (input *CreateEditWebhook) IsAuthorized(ctx context.Context, user *models.User) bool {
+	return user != nil && user.IsAdministrator()
+}
+
+// Validate if current model is valid
+func (input *CreateEditWebhook) Validate(ctx context.Context, user *models.User) *validate.Result {
+	result := validate.Success()
+
+	if input.Model.ID != 0 {
+		getWebhook := &query.GetWebhookByID{WebhookID: input.Model.ID}
+		if err := bus.Dispatch(ctx, getWebhook); err != nil {
+			return validate.Error(err)
+		}
+		input.Webhook = getWebhook.Result
+	}
+
+	if input.Model.Name == "" {
+		result.AddFieldFailure("name", "Name is required.")
+	} else if len(input.Model.Name) > 60 {
+		result.AddFieldFailure("name", "Name must have less than 60 characters.")
+	}
+
+	if input.Model.URL == "" {
+		result.AddFieldFailure("url", "URL is required.")
+	} else if len(input.Model.URL) > 300 {
+		result.AddFieldFailure("url", "URL must have less than 300 characters.")
+	} else if messages := validate.URL(input.Model.URL); len(messages) > 0 {
+		result.AddFieldFailure("url", messages...)
+	} else if messages := validate.PublicURL(ctx, input.Model.URL); len(messages) > 0 {
+		result.AddFieldFailure("url", messages...)
+	}
+
+	if len(input.Model.Events) == 0 {
+		result.AddFieldFailure("events", "At least one event is required.")
+	} else {
+		for _, event := range input.Model.Events {
+			if !event.IsValid() {
+				result.AddFieldFailure("events", fmt.Sprintf("Event '%s' is invalid.", event))
+			}
+		}
+	}
+
+	return result
+}
+
+// DeleteWebhook is used to delete an existing webhook
+type DeleteWebhook struct {
+	Webhook *models.Webhook
+	Model   *models.DeleteWebhook
+}
+
+// Initialize the model
+func (input *DeleteWebhook) Initialize() interface{} {
+	input.Model = new(models.DeleteWebhook)
+	return input.Model
+}
+
+// IsAuthorized returns true if current user is authorized to perform this action
+func (input *DeleteWebhook) IsAuthorized(ctx context.Context, user *models.User) bool {
+	return user != nil && user.IsAdministrator()
+}
+
+// Validate if current model is valid
+func (input *DeleteWebhook) Validate(ctx context.Context, user *models.User) *validate.Result {
+	getWebhook := &query.GetWebhookByID{WebhookID: input.Model.ID}
+	if err := bus.Dispatch(ctx, getWebhook); err != nil {
+		return validate.Error(err)
+	}
+
+	input.Webhook = getWebhook.Result
+	return validate.Success()
+}
@@ -0,0 +1,80 @@
+package actions_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/getfider/fider/app/actions"
+	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/models/query"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/rand"
+)
+
+func TestCreateEditWebhook_InvalidInput(t *testing.T) {
+	RegisterT(t)
+
+	testCases := []struct {
+		expected []string
+		input    *models.CreateEditWebhook
+	}{
+		{
+			expected: []string{"name", "url", "events"},
+			input:    &models.CreateEditWebhook{},
+		},
+		{
+			expected: []string{"name", "url"},
+			input: &models.CreateEditWebhook{
+				Name:   rand.String(61),
+				URL:    "not-an-url",
+				Events: []enum.WebhookEvent{enum.WebhookEventPostCreated},
+			},
+		},
+		{
+			expected: []string{"events"},
+			input: &models.CreateEditWebhook{
+				Name:   "Slack",
+				URL:    "https://hooks.example.com",
+				Events: []enum.WebhookEvent{enum.WebhookEventPostCreated, "post.exploded"},
+			},
+		},
+		{
+			expected: []string{"url"},
+			input: &models.CreateEditWebhook{
+				Name:   "Internal",
+				URL:    "http://169.254.169.254/latest/meta-data",
+				Events: []enum.WebhookEvent{enum.WebhookEventPostCreated},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		action := &actions.CreateEditWebhook{Model: testCase.input}
+		result := action.Validate(context.Background(), nil)
+		ExpectFailed(result, testCase.expected...)
+	}
+}
+
+func TestCreateEditWebhook_ValidInput(t *testing.T) {
+	RegisterT(t)
+
+	webhook := &models.Webhook{ID: 4, Name: "Slack", URL: "https://hooks.example.com"}
+	bus.AddHandler(func(ctx context.Context, q *query.GetWebhookByID) error {
+		if q.WebhookID == webhook.ID {
+			q.Result = webhook
+		}
+		return nil
+	})
+
+	action := &actions.CreateEditWebhook{Model: &models.CreateEditWebhook{
+		ID:     4,
+		Name:   "Slack Bot",
+		URL:    "https://hooks.example.com/fider",
+		Events: []enum.WebhookEvent{enum.WebhookEventCommentCreated, enum.WebhookEventVoteAdded},
+	}}
+	result := action.Validate(context.Background(), nil)
+	ExpectSuccess(result)
+	Expect(action.Webhook).Equals(webhook)
+}
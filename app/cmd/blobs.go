@@ -0,0 +1,202 @@
+package cmd
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/models/dto"
+	"github.com/getfider/fider/app/models/query"
+	"github.com/getfider/fider/app/pkg/blobgc"
+	"github.com/getfider/fider/app/pkg/blobmigrate"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/dbx"
+	"github.com/getfider/fider/app/pkg/env"
+	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/getfider/fider/app/pkg/log"
+	"github.com/getfider/fider/app/pkg/rand"
+	"github.com/getfider/fider/app/services/blob/fs"
+	"github.com/getfider/fider/app/services/blob/s3"
+	"github.com/getfider/fider/app/services/blob/sql"
+)
+
+// RunBlobs runs maintenance commands on the blob storage, like "gc" and "migrate"
+// Returns an exitcode, 0 for OK and 1 for ERROR
+func RunBlobs(args []string) int {
+	if len(args) > 0 && args[0] == "gc" {
+		return runBlobsGC(args[1:])
+	} else if len(args) > 0 && args[0] == "migrate" {
+		return runBlobsMigrate(args[1:])
+	}
+
+	fmt.Println("Usage: fider blobs gc [options]")
+	fmt.Println("       fider blobs migrate -from <storage> -to <storage> [options]")
+	return 1
+}
+
+var blobStorages = map[string]bus.Service{
+	"fs":  fs.Service{},
+	"s3":  s3.Service{},
+	"sql": sql.Service{},
+}
+
+func runBlobsGC(args []string) int {
+	flags := flag.NewFlagSet("blobs gc", flag.ContinueOnError)
+	subdomain := flags.String("tenant", "", "subdomain of the site to collect (default: all sites)")
+	gracePeriod := flags.Duration("grace-period", env.Config.BlobStorage.GC.GracePeriod, "keep unreferenced blobs modified within this period")
+	dryRun := flags.Bool("dry-run", false, "report what would be deleted, without deleting it")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: fider blobs gc [options]")
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	bus.Init()
+
+	ctx := log.WithProperties(context.Background(), dto.Props{
+		log.PropertyKeyTag:       "BLOBS",
+		log.PropertyKeyContextID: rand.String(32),
+	})
+
+	trx, err := dbx.BeginTx(ctx)
+	if err != nil {
+		log.Error(ctx, err)
+		return 1
+	}
+	defer trx.MustRollback()
+	ctx = context.WithValue(ctx, app.TransactionCtxKey, trx)
+
+	tenants, err := getBlobsTenants(ctx, *subdomain)
+	if err != nil {
+		log.Error(ctx, err)
+		return 1
+	}
+
+	exitCode := 0
+	for _, tenant := range tenants {
+		report, err := blobgc.Collect(context.WithValue(ctx, app.TenantCtxKey, tenant), *gracePeriod, *dryRun)
+		if err != nil {
+			log.Error(ctx, err)
+			return 1
+		}
+
+		printBlobsGCReport(tenant, report)
+		if len(report.Missing) > 0 {
+			exitCode = 1
+		}
+	}
+
+	return exitCode
+}
+
+func runBlobsMigrate(args []string) int {
+	flags := flag.NewFlagSet("blobs migrate", flag.ContinueOnError)
+	from := flags.String("from", "", "blob storage to copy from: fs, s3 or sql")
+	to := flags.String("to", "", "blob storage to copy to: fs, s3 or sql")
+	subdomain := flags.String("tenant", "", "subdomain of the site to migrate (default: all sites)")
+	overwrite := flags.Bool("overwrite", false, "replace blobs that already exist on the target with a different content")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: fider blobs migrate -from <storage> -to <storage> [options]")
+		fmt.Fprintln(flags.Output(), "Blobs are copied and never deleted from the source. It can be run again to resume or to")
+		fmt.Fprintln(flags.Output(), "copy blobs uploaded in the meantime, including after BLOB_STORAGE has been changed.")
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	source, ok := blobStorages[*from]
+	target, ok2 := blobStorages[*to]
+	if !ok || !ok2 || *from == *to {
+		flags.Usage()
+		return 1
+	}
+
+	bus.Init()
+
+	ctx := log.WithProperties(context.Background(), dto.Props{
+		log.PropertyKeyTag:       "BLOBS",
+		log.PropertyKeyContextID: rand.String(32),
+	})
+
+	trx, err := dbx.BeginTx(ctx)
+	if err != nil {
+		log.Error(ctx, err)
+		return 1
+	}
+	defer trx.MustRollback()
+	ctx = context.WithValue(ctx, app.TransactionCtxKey, trx)
+
+	tenants, err := getBlobsTenants(ctx, *subdomain)
+	if err != nil {
+		log.Error(ctx, err)
+		return 1
+	}
+
+	exitCode := 0
+	for _, tenant := range tenants {
+		report, err := blobmigrate.Migrate(context.WithValue(ctx, app.TenantCtxKey, tenant), source, target, *overwrite)
+		if report != nil {
+			printBlobsMigrateReport(tenant, report)
+		}
+		if err != nil {
+			log.Error(ctx, err)
+			return 1
+		}
+		if len(report.Conflicts) > 0 {
+			exitCode = 1
+		}
+	}
+
+	return exitCode
+}
+
+func getBlobsTenants(ctx context.Context, subdomain string) ([]*models.Tenant, error) {
+	if subdomain != "" {
+		byDomain := &query.GetTenantByDomain{Domain: subdomain}
+		if err := bus.Dispatch(ctx, byDomain); err != nil {
+			return nil, errors.Wrap(err, "failed to get tenant '%s'", subdomain)
+		}
+		return []*models.Tenant{byDomain.Result}, nil
+	}
+
+	allTenants := &query.GetAllTenants{}
+	if err := bus.Dispatch(ctx, allTenants); err != nil {
+		return nil, errors.Wrap(err, "failed to get tenants")
+	}
+	return allTenants.Result, nil
+}
+
+func printBlobsGCReport(tenant *models.Tenant, report *blobgc.Report) {
+	action := "deleted"
+	if report.DryRun {
+		action = "to delete"
+	}
+
+	fmt.Printf("%s (#%d): %d blobs, %d %s, %d within grace period, %d missing\n",
+		tenant.Name, tenant.ID, report.Scanned, len(report.Deleted), action, len(report.Recent), len(report.Missing))
+
+	for _, key := range report.Deleted {
+		fmt.Printf("  DELETE: %s\n", key)
+	}
+
+	for _, key := range report.Missing {
+		fmt.Fprintf(os.Stderr, "  MISSING: %s\n", key)
+	}
+}
+
+func printBlobsMigrateReport(tenant *models.Tenant, report *blobmigrate.Report) {
+	fmt.Printf("%s (#%d): %d copied, %d already migrated, %d conflicts\n",
+		tenant.Name, tenant.ID, len(report.Copied), len(report.Skipped), len(report.Conflicts))
+
+	for _, key := range report.Conflicts {
+		fmt.Fprintf(os.Stderr, "  CONFLICT: %s\n", key)
+	}
+}
@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/models/dto"
+	"github.com/getfider/fider/app/models/query"
+	"github.com/getfider/fider/app/pkg/backup"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/csv"
+	"github.com/getfider/fider/app/pkg/dbx"
+	"github.com/getfider/fider/app/pkg/env"
+	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/getfider/fider/app/pkg/log"
+	"github.com/getfider/fider/app/pkg/rand"
+)
+
+// RunImport imports a backup.zip or a CSV file of posts, comments or votes into a tenant
+// Returns an exitcode, 0 for OK and 1 for ERROR
+func RunImport(args []string) int {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	kind := flags.String("kind", "", "what to import: backup, posts, comments or votes (default: backup for .zip files)")
+	subdomain := flags.String("tenant", "", "subdomain of the site to import into (required on multi host mode)")
+	dryRun := flags.Bool("dry-run", false, "validate the file and report what would be imported, without importing it")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: fider import [options] <file>")
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return 1
+	}
+
+	fileName := flags.Arg(0)
+	if *kind == "" && strings.ToLower(filepath.Ext(fileName)) == ".zip" {
+		*kind = "backup"
+	}
+
+	if *kind == "" {
+		fmt.Println("Option -kind is required for CSV files.")
+		return 1
+	}
+
+	bus.Init()
+
+	ctx := log.WithProperties(context.Background(), dto.Props{
+		log.PropertyKeyTag:       "IMPORT",
+		log.PropertyKeyContextID: rand.String(32),
+	})
+
+	report, err := runImport(ctx, *subdomain, *kind, fileName, *dryRun)
+	if err != nil {
+		log.Error(ctx, err)
+		return 1
+	}
+
+	printImportReport(report)
+	if report.HasErrors() {
+		return 1
+	}
+	return 0
+}
+
+func runImport(ctx context.Context, subdomain, kind, fileName string, dryRun bool) (*dto.ImportReport, error) {
+	content, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read file '%s'", fileName)
+	}
+
+	trx, err := dbx.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer trx.MustRollback()
+
+	ctx = context.WithValue(ctx, app.TransactionCtxKey, trx)
+
+	tenant, err := getImportTenant(ctx, subdomain)
+	if err != nil {
+		return nil, err
+	}
+	ctx = context.WithValue(ctx, app.TenantCtxKey, tenant)
+
+	var report *dto.ImportReport
+	if kind == "backup" {
+		report, err = backup.Restore(ctx, content, dryRun)
+	} else {
+		report, err = csv.Import(ctx, kind, content, dryRun)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !dryRun && !report.HasErrors() {
+		if err := trx.Commit(); err != nil {
+			return nil, errors.Wrap(err, "failed to commit import")
+		}
+
+		if kind == "backup" {
+			if err := backup.RestoreBlobs(ctx, content); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return report, nil
+}
+
+func getImportTenant(ctx context.Context, subdomain string) (*models.Tenant, error) {
+	if env.IsSingleHostMode() {
+		firstTenant := &query.GetFirstTenant{}
+		if err := bus.Dispatch(ctx, firstTenant); err != nil {
+			return nil, errors.Wrap(err, "failed to get tenant")
+		}
+		return firstTenant.Result, nil
+	}
+
+	if subdomain == "" {
+		return nil, errors.New("option -tenant is required on multi host mode")
+	}
+
+	byDomain := &query.GetTenantByDomain{Domain: subdomain}
+	if err := bus.Dispatch(ctx, byDomain); err != nil {
+		return nil, errors.Wrap(err, "failed to get tenant '%s'", subdomain)
+	}
+	return byDomain.Result, nil
+}
+
+func printImportReport(report *dto.ImportReport) {
+	if report.DryRun {
+		fmt.Println("Dry-run: nothing has been imported.")
+	}
+
+	kinds := make([]string, 0, len(report.Imported))
+	for kind := range report.Imported {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+
+	for _, kind := range kinds {
+		fmt.Printf("%s: %d\n", kind, report.Imported[kind])
+	}
+
+	for _, warning := range report.Warnings {
+		fmt.Printf("WARNING: %s\n", warning)
+	}
+
+	for _, err := range report.Errors {
+		fmt.Fprintf(os.Stderr, "ERROR: %s\n", err)
+	}
+}
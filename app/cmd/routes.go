@@ -40,11 +40,17 @@ func routes(r *web.Engine) *web.Engine {
 	r.Use(middlewares.Session())
 
 	r.Get("/-/health", handlers.Health())
+	r.Get("/metrics", handlers.Metrics())
 	r.Get("/robots.txt", handlers.RobotsTXT())
 	r.Post("/_api/log-error", handlers.LogError())
 
 	r.Use(middlewares.Maintenance())
 	r.Use(middlewares.WebSetup())
+
+	r.Post("/_inbound/mailgun", handlers.ReceiveMailgunReply())
+	r.Post("/_events/mailgun", handlers.ReceiveMailgunEvent())
+	r.Post("/_events/ses", handlers.ReceiveSESNotification())
+
 	r.Use(middlewares.Tenant())
 	r.Use(middlewares.User())
 
@@ -92,6 +98,10 @@ func routes(r *web.Engine) *web.Engine {
 	r.Get("/invite/verify", handlers.VerifySignInKey(enum.EmailVerificationKindUserInvitation))
 	r.Post("/_api/signin/complete", handlers.CompleteSignInProfile())
 	r.Post("/_api/signin", handlers.SignInByEmail())
+	r.Get("/saml/metadata", handlers.SAMLMetadata())
+	r.Get("/saml/login", handlers.SignInBySAML())
+	r.Post("/saml/acs", handlers.SAMLAssertionConsumer())
+	r.Get("/saml/token", handlers.SAMLToken())
 
 	//Block if it's a locked tenant with a non-administrator user
 	r.Use(middlewares.BlockLockedTenants())
@@ -102,6 +112,8 @@ func routes(r *web.Engine) *web.Engine {
 	r.Get("/", handlers.Index())
 	r.Get("/posts/:number", handlers.PostDetails())
 	r.Get("/posts/:number/:slug", handlers.PostDetails())
+	r.Get("/roadmap", middlewares.CanViewRoadmap()(handlers.Roadmap()))
+	r.Get("/files/*bkey", handlers.DownloadFile())
 
 	/*
 	** This is a temporary redirect and should be removed in the future
@@ -137,13 +149,19 @@ func routes(r *web.Engine) *web.Engine {
 		//From this step, only Collaborators and Administrators are allowed
 		ui.Use(middlewares.IsAuthorized(enum.RoleCollaborator, enum.RoleAdministrator))
 
+		ui.Get("/_api/user/tokens", handlers.ListMyAPITokens())
+		ui.Post("/_api/user/tokens", handlers.CreateAPIToken())
+		ui.Delete("/_api/user/tokens/:id", handlers.RevokeAPIToken())
+
 		ui.Get("/admin", handlers.GeneralSettingsPage())
 		ui.Get("/admin/advanced", handlers.AdvancedSettingsPage())
 		ui.Get("/admin/privacy", handlers.Page("Privacy · Site Settings", "", "PrivacySettings.page"))
+		ui.Get("/admin/voting", handlers.Page("Voting · Site Settings", "", "VotingSettings.page"))
 		ui.Get("/admin/invitations", handlers.Page("Invitations · Site Settings", "", "Invitations.page"))
 		ui.Get("/admin/members", handlers.ManageMembers())
 		ui.Get("/admin/tags", handlers.ManageTags())
 		ui.Get("/admin/authentication", handlers.ManageAuthentication())
+		ui.Get("/admin/analytics", handlers.AnalyticsPage())
 		ui.Get("/_api/admin/oauth/:provider", handlers.GetOAuthConfig())
 
 		//From this step, only Administrators are allowed
@@ -152,13 +170,32 @@ func routes(r *web.Engine) *web.Engine {
 		ui.Get("/admin/export", handlers.Page("Export · Site Settings", "", "Export.page"))
 		ui.Get("/admin/export/posts.csv", handlers.ExportPostsToCSV())
 		ui.Get("/admin/export/backup.zip", handlers.ExportBackupZip())
+		ui.Get("/admin/import", handlers.Page("Import · Site Settings", "", "Import.page"))
+		ui.Get("/admin/audit", handlers.AuditLogPage())
+		ui.Post("/_api/admin/import", handlers.ImportData())
 		ui.Post("/_api/admin/settings/general", handlers.UpdateSettings())
 		ui.Post("/_api/admin/settings/advanced", handlers.UpdateAdvancedSettings())
 		ui.Post("/_api/admin/settings/privacy", handlers.UpdatePrivacy())
+		ui.Post("/_api/admin/settings/voting", handlers.UpdateVoting())
+		ui.Get("/admin/saml", handlers.SAMLSettingsPage())
+		ui.Get("/admin/emails", handlers.EmailTemplatesPage())
+		ui.Put("/_api/admin/email-templates/:name", handlers.SaveEmailTemplate())
+		ui.Delete("/_api/admin/email-templates/:name", handlers.ResetEmailTemplate())
+		ui.Post("/_api/admin/email-templates/:name/preview", handlers.PreviewEmailTemplate())
+		ui.Post("/_api/admin/email-templates/:name/sample", handlers.SendSampleEmailTemplate())
 		ui.Post("/_api/admin/oauth", handlers.SaveOAuthConfig())
+		ui.Post("/_api/admin/saml", handlers.SaveSAMLConfig())
 		ui.Post("/_api/admin/roles/:role/users", handlers.ChangeUserRole())
 		ui.Put("/_api/admin/users/:userID/block", handlers.BlockUser())
 		ui.Delete("/_api/admin/users/:userID/block", handlers.UnblockUser())
+		ui.Delete("/_api/admin/email-suppressions/:address", handlers.ClearEmailSuppression())
+		ui.Get("/_api/admin/tokens", handlers.ListAllAPITokens())
+		ui.Delete("/_api/admin/tokens/:id", handlers.RevokeAPIToken())
+		ui.Get("/_api/admin/webhooks", handlers.ListWebhooks())
+		ui.Post("/_api/admin/webhooks", handlers.CreateEditWebhook())
+		ui.Put("/_api/admin/webhooks/:id", handlers.CreateEditWebhook())
+		ui.Delete("/_api/admin/webhooks/:id", handlers.DeleteWebhook())
+		ui.Get("/_api/admin/webhooks/:id/deliveries", handlers.ListWebhookDeliveries())
 
 		ui.Use(middlewares.RequireBillingEnabled())
 
@@ -171,44 +208,50 @@ func routes(r *web.Engine) *web.Engine {
 
 	api := r.Group()
 	{
-		api.Get("/api/v1/posts", apiv1.SearchPosts())
-		api.Get("/api/v1/tags", apiv1.ListTags())
-		api.Get("/api/v1/posts/:number", apiv1.GetPost())
-		api.Get("/api/v1/posts/:number/comments", apiv1.ListComments())
-		api.Get("/api/v1/posts/:number/comments/:id", apiv1.GetComment())
+		api.Get("/api/v1/posts", middlewares.RequireScope(enum.APIScopePostsRead)(apiv1.SearchPosts()))
+		api.Get("/api/v1/tags", middlewares.RequireScope(enum.APIScopePostsRead)(apiv1.ListTags()))
+		api.Get("/api/v1/roadmap", middlewares.RequireScope(enum.APIScopePostsRead)(middlewares.CanViewRoadmap()(apiv1.GetRoadmap())))
+		api.Get("/api/v1/posts/:number", middlewares.RequireScope(enum.APIScopePostsRead)(apiv1.GetPost()))
+		api.Get("/api/v1/posts/:number/comments", middlewares.RequireScope(enum.APIScopePostsRead)(apiv1.ListComments()))
+		api.Get("/api/v1/posts/:number/comments/:id", middlewares.RequireScope(enum.APIScopePostsRead)(apiv1.GetComment()))
 
 		//From this step, a User is required
 		api.Use(middlewares.IsAuthenticated())
 
-		api.Post("/api/v1/posts", apiv1.CreatePost())
-		api.Post("/api/v1/posts/:number/comments", apiv1.PostComment())
-		api.Put("/api/v1/posts/:number/comments/:id", apiv1.UpdateComment())
-		api.Delete("/api/v1/posts/:number/comments/:id", apiv1.DeleteComment())
-		api.Post("/api/v1/posts/:number/votes", apiv1.AddVote())
-		api.Delete("/api/v1/posts/:number/votes", apiv1.RemoveVote())
-		api.Post("/api/v1/posts/:number/subscription", apiv1.Subscribe())
-		api.Delete("/api/v1/posts/:number/subscription", apiv1.Unsubscribe())
+		api.Post("/api/v1/posts", middlewares.RequireScope(enum.APIScopePostsWrite)(apiv1.CreatePost()))
+		api.Post("/api/v1/posts/:number/comments", middlewares.RequireScope(enum.APIScopeCommentsWrite)(apiv1.PostComment()))
+		api.Put("/api/v1/posts/:number/comments/:id", middlewares.RequireScope(enum.APIScopeCommentsWrite)(apiv1.UpdateComment()))
+		api.Delete("/api/v1/posts/:number/comments/:id", middlewares.RequireScope(enum.APIScopeCommentsWrite)(apiv1.DeleteComment()))
+		api.Post("/api/v1/posts/:number/votes", middlewares.RequireScope(enum.APIScopeVotesWrite)(apiv1.AddVote()))
+		api.Delete("/api/v1/posts/:number/votes", middlewares.RequireScope(enum.APIScopeVotesWrite)(apiv1.RemoveVote()))
+		api.Post("/api/v1/posts/:number/subscription", middlewares.RequireScope(enum.APIScopeVotesWrite)(apiv1.Subscribe()))
+		api.Delete("/api/v1/posts/:number/subscription", middlewares.RequireScope(enum.APIScopeVotesWrite)(apiv1.Unsubscribe()))
 
 		//From this step, only Collaborators and Administrators are allowed
 		api.Use(middlewares.IsAuthorized(enum.RoleCollaborator, enum.RoleAdministrator))
 
-		api.Get("/api/v1/users", apiv1.ListUsers())
-		api.Put("/api/v1/posts/:number", apiv1.UpdatePost())
-		api.Get("/api/v1/posts/:number/votes", apiv1.ListVotes())
-		api.Post("/api/v1/invitations/send", apiv1.SendInvites())
-		api.Post("/api/v1/invitations/sample", apiv1.SendSampleInvite())
-		api.Put("/api/v1/posts/:number/status", apiv1.SetResponse())
-		api.Post("/api/v1/posts/:number/tags/:slug", apiv1.AssignTag())
-		api.Delete("/api/v1/posts/:number/tags/:slug", apiv1.UnassignTag())
+		api.Get("/api/v1/users", middlewares.RequireScope(enum.APIScopeUsersRead)(apiv1.ListUsers()))
+		api.Put("/api/v1/posts/:number", middlewares.RequireScope(enum.APIScopePostsWrite)(apiv1.UpdatePost()))
+		api.Get("/api/v1/posts/:number/votes", middlewares.RequireScope(enum.APIScopePostsRead)(apiv1.ListVotes()))
+		api.Get("/api/v1/analytics", middlewares.RequireScope(enum.APIScopeAnalyticsRead)(apiv1.GetAnalytics()))
+		api.Post("/api/v1/invitations/send", middlewares.RequireScope(enum.APIScopeUsersAdmin)(apiv1.SendInvites()))
+		api.Post("/api/v1/invitations/sample", middlewares.RequireScope(enum.APIScopeUsersAdmin)(apiv1.SendSampleInvite()))
+		api.Put("/api/v1/posts/:number/status", middlewares.RequireScope(enum.APIScopePostsWrite)(apiv1.SetResponse()))
+		api.Post("/api/v1/posts/:number/tags/:slug", middlewares.RequireScope(enum.APIScopePostsWrite)(apiv1.AssignTag()))
+		api.Delete("/api/v1/posts/:number/tags/:slug", middlewares.RequireScope(enum.APIScopePostsWrite)(apiv1.UnassignTag()))
 
 		//From this step, only Administrators are allowed
 		api.Use(middlewares.IsAuthorized(enum.RoleAdministrator))
 
-		api.Post("/api/v1/users", apiv1.CreateUser())
-		api.Delete("/api/v1/posts/:number", apiv1.DeletePost())
-		api.Post("/api/v1/tags", apiv1.CreateEditTag())
-		api.Put("/api/v1/tags/:slug", apiv1.CreateEditTag())
-		api.Delete("/api/v1/tags/:slug", apiv1.DeleteTag())
+		api.Post("/api/v1/users", middlewares.RequireScope(enum.APIScopeUsersAdmin)(apiv1.CreateUser()))
+		api.Delete("/api/v1/posts/:number", middlewares.RequireScope(enum.APIScopePostsWrite)(apiv1.DeletePost()))
+		api.Post("/api/v1/posts/:number/merge", middlewares.RequireScope(enum.APIScopePostsWrite)(apiv1.MergePost()))
+		api.Delete("/api/v1/posts/:number/merge", middlewares.RequireScope(enum.APIScopePostsWrite)(apiv1.UnmergePost()))
+		api.Post("/api/v1/tags", middlewares.RequireScope(enum.APIScopeTagsWrite)(apiv1.CreateEditTag()))
+		api.Put("/api/v1/tags/:slug", middlewares.RequireScope(enum.APIScopeTagsWrite)(apiv1.CreateEditTag()))
+		api.Delete("/api/v1/tags/:slug", middlewares.RequireScope(enum.APIScopeTagsWrite)(apiv1.DeleteTag()))
+		api.Get("/api/v1/audit", middlewares.RequireScope(enum.APIScopeAuditRead)(apiv1.ListAuditLogs()))
+		api.Get("/api/v1/audit.csv", middlewares.RequireScope(enum.APIScopeAuditRead)(apiv1.ExportAuditLogsToCSV()))
 	}
 
 	return r
@@ -1,32 +1,51 @@
 package cmd
 
 import (
+	"bytes"
 	"context"
+	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
+	"time"
 
+	"github.com/getfider/fider/app"
 	"github.com/getfider/fider/app/models"
 	"github.com/getfider/fider/app/models/cmd"
 	"github.com/getfider/fider/app/models/dto"
 	"github.com/getfider/fider/app/pkg/bus"
 	"github.com/getfider/fider/app/pkg/env"
 	"github.com/getfider/fider/app/pkg/log"
+	"github.com/getfider/fider/app/pkg/smtpd"
+	"github.com/getfider/fider/app/pkg/trace"
 	"github.com/getfider/fider/app/pkg/web"
+	"github.com/getfider/fider/app/services/email"
+	"github.com/getfider/fider/app/tasks"
 
+	_ "github.com/getfider/fider/app/services/antivirus/command"
+	_ "github.com/getfider/fider/app/services/antivirus/icap"
+	_ "github.com/getfider/fider/app/services/antivirus/local"
 	_ "github.com/getfider/fider/app/services/billing"
 	_ "github.com/getfider/fider/app/services/blob/fs"
 	_ "github.com/getfider/fider/app/services/blob/s3"
 	_ "github.com/getfider/fider/app/services/blob/sql"
+	_ "github.com/getfider/fider/app/services/email/maildir"
 	_ "github.com/getfider/fider/app/services/email/mailgun"
+	_ "github.com/getfider/fider/app/services/email/ses"
 	_ "github.com/getfider/fider/app/services/email/smtp"
 	_ "github.com/getfider/fider/app/services/httpclient"
 	_ "github.com/getfider/fider/app/services/log/console"
+	_ "github.com/getfider/fider/app/services/log/file"
 	_ "github.com/getfider/fider/app/services/log/sql"
+	_ "github.com/getfider/fider/app/services/log/syslog"
 	_ "github.com/getfider/fider/app/services/oauth"
 	_ "github.com/getfider/fider/app/services/sqlstore/postgres"
 )
 
+//digestInterval is how often pending digests are checked, which is also the maximum delay of a digest
+const digestInterval = 15 * time.Minute
+
 //RunServer starts the Fider Server
 //Returns an exitcode, 0 for OK and 1 for ERROR
 func RunServer(settings *models.SystemSettings) int {
@@ -39,14 +58,109 @@ func RunServer(settings *models.SystemSettings) int {
 		})
 	}
 
+	if err := trace.Init(); err != nil {
+		log.Error(ctx, err)
+	}
+
 	bus.Publish(ctx, &cmd.PurgeExpiredNotifications{})
 
 	e := routes(web.New(settings))
 
 	go e.Start(":" + env.Config.Port)
+	go scheduleDigests(e)
+	if env.Config.BlobStorage.GC.Interval > 0 {
+		go scheduleBlobGarbageCollection(e)
+	}
+	if env.Config.Email.Inbound.Listen != "" {
+		go listenInboundEmails(ctx, e)
+	}
 	return listenSignals(e, settings)
 }
 
+//listenInboundEmails receives replies to notification emails over SMTP or LMTP
+func listenInboundEmails(ctx context.Context, e *web.Engine) {
+	inbound := env.Config.Email.Inbound
+	server := &smtpd.Server{
+		Addr:            inbound.Listen,
+		Domain:          inbound.Domain,
+		LMTP:            strings.EqualFold(inbound.Protocol, "lmtp"),
+		MaxMessageBytes: 10 << 20,
+		AcceptRecipient: func(address string) bool {
+			_, err := email.ParseReplyAddress(address)
+			return err == nil
+		},
+		Handler: func(from string, to []string, data []byte) error {
+			message, err := email.ParseMessage(bytes.NewReader(data))
+			if err != nil {
+				return err
+			}
+
+			//Only the envelope recipients are trusted, as headers can list any address
+			message.To = to
+			task := tasks.ReceiveReply(message)
+			task.OriginContext = inboundContext()
+			e.Worker().Enqueue(task)
+			return nil
+		},
+	}
+
+	log.Infof(ctx, "Receiving emails on @{Address} using @{Protocol}.", dto.Props{
+		"Address":  inbound.Listen,
+		"Protocol": strings.ToUpper(inbound.Protocol),
+	})
+
+	if err := server.ListenAndServe(); err != nil {
+		log.Error(ctx, err)
+	}
+}
+
+//inboundContext is the origin of tasks started by an inbound email, which are not started by a request
+func inboundContext() context.Context {
+	scheme := "https"
+	if env.IsDevelopment() {
+		scheme = "http"
+	}
+
+	ctx := context.Background()
+	if req, err := http.NewRequest("POST", scheme+"://"+env.Config.HostDomain, nil); err == nil {
+		request := web.WrapRequest(req)
+		request.URL = req.URL
+		request.IsSecure = scheme == "https"
+		ctx = context.WithValue(ctx, app.RequestCtxKey, request)
+	}
+	return ctx
+}
+
+//scheduleDigests periodically enqueues the task that delivers daily and weekly digests that are due
+func scheduleDigests(e *web.Engine) {
+	ticker := time.NewTicker(digestInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		e.Worker().Enqueue(tasks.SendDigests())
+	}
+}
+
+//scheduleBlobGarbageCollection periodically enqueues the task that deletes blobs that are no longer referenced
+func scheduleBlobGarbageCollection(e *web.Engine) {
+	ticker := time.NewTicker(env.Config.BlobStorage.GC.Interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		e.Worker().Enqueue(tasks.CollectBlobGarbage())
+	}
+}
+
+//shutdownTracing exports the spans of the last requests and tasks before exiting
+func shutdownTracing(e *web.Engine) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := trace.Shutdown(ctx); err != nil {
+		log.Error(e, err)
+	}
+}
+
 func listenSignals(e *web.Engine, settings *models.SystemSettings) int {
 	signals := make(chan os.Signal, 1)
 	signal.Notify(signals, append([]os.Signal{syscall.SIGTERM, syscall.SIGINT}, extraSignals...)...)
@@ -55,6 +169,7 @@ func listenSignals(e *web.Engine, settings *models.SystemSettings) int {
 		switch s {
 		case syscall.SIGINT, syscall.SIGTERM:
 			err := e.Stop()
+			shutdownTracing(e)
 			if err != nil {
 				return 1
 			}
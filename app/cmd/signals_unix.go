@@ -11,10 +11,11 @@ import (
 	"syscall"
 
 	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/pkg/log"
 	"github.com/getfider/fider/app/pkg/web"
 )
 
-var extraSignals = []os.Signal{syscall.SIGUSR1}
+var extraSignals = []os.Signal{syscall.SIGUSR1, syscall.SIGHUP}
 
 func handleExtraSignal(s os.Signal, e *web.Engine, settings *models.SystemSettings) int {
 	switch s {
@@ -32,6 +33,12 @@ func handleExtraSignal(s os.Signal, e *web.Engine, settings *models.SystemSettin
 		buf.WriteString(fmt.Sprintf("# Worker Queue: %d\n", e.Worker().Length()))
 		buf.WriteString(fmt.Sprintf("# Num Goroutines: %d\n", runtime.NumGoroutine()))
 		println(buf.String())
+	case syscall.SIGHUP:
+		println("SIGHUP received")
+		println("Reloading log levels")
+		if err := log.ReloadLevels(); err != nil {
+			println(err.Error())
+		}
 	}
 	return -1
 }
@@ -8,6 +8,9 @@ var ErrNotFound = errors.New("Object not found")
 // InvitePlaceholder represents the placeholder used by members to invite other users
 var InvitePlaceholder = "%invite%"
 
+//ErrVoteBudgetExceeded is used when a vote would place more credits than what's left of the user's vote budget
+var ErrVoteBudgetExceeded = errors.New("Vote budget exceeded")
+
 //ErrUserIDRequired is used when OAuth integration returns an empty user ID
 var ErrUserIDRequired = errors.New("UserID is required during OAuth integration")
 
@@ -31,5 +34,7 @@ var (
 	TransactionCtxKey = createKey("TRANSACTION")
 	TenantCtxKey      = createKey("TENANT")
 	UserCtxKey        = createKey("USER")
+	APITokenCtxKey    = createKey("API_TOKEN")
 	LogPropsCtxKey    = createKey("LOG_PROPS")
+	LocaleCtxKey      = createKey("LOCALE")
 )
@@ -2,7 +2,10 @@ package handlers
 
 import (
 	"github.com/getfider/fider/app/actions"
+	"github.com/getfider/fider/app/models"
 	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/dto"
+	"github.com/getfider/fider/app/models/enum"
 	"github.com/getfider/fider/app/models/query"
 	"github.com/getfider/fider/app/pkg/bus"
 	"github.com/getfider/fider/app/pkg/web"
@@ -25,7 +28,8 @@ func AdvancedSettingsPage() web.HandlerFunc {
 			Title:     "Advanced · Site Settings",
 			ChunkName: "AdvancedSettings.page",
 			Data: web.Map{
-				"customCSS": c.Tenant().CustomCSS,
+				"customCSS":        c.Tenant().CustomCSS,
+				"allowedFileTypes": c.Tenant().AllowedFileTypes,
 			},
 		})
 	}
@@ -39,15 +43,39 @@ func UpdateSettings() web.HandlerFunc {
 			return c.HandleValidation(result)
 		}
 
-		if err := bus.Dispatch(c,
-			&cmd.UploadImage{
-				Image:  input.Model.Logo,
-				Folder: "logos",
-			},
-			&cmd.UpdateTenantSettings{
-				Settings: input.Model,
+		tenant := c.Tenant()
+		before := dto.Props{
+			"title":          tenant.Name,
+			"invitation":     tenant.Invitation,
+			"welcomeMessage": tenant.WelcomeMessage,
+			"cname":          tenant.CNAME,
+			"locale":         tenant.Locale,
+			"logoBlobKey":    tenant.LogoBlobKey,
+		}
+
+		if err := bus.Dispatch(c, &cmd.UploadImage{
+			Image:  input.Model.Logo,
+			Folder: "logos",
+		}); err != nil {
+			return c.Failure(err)
+		}
+
+		if err := bus.Dispatch(c, &cmd.UpdateTenantSettings{Settings: input.Model}, &cmd.AddAuditLog{
+			Action:     enum.AuditActionSettingsUpdated,
+			TargetType: "tenant",
+			TargetID:   tenant.ID,
+			TargetName: tenant.Name,
+			Before:     before,
+			After: dto.Props{
+				"title":          input.Model.Title,
+				"invitation":     input.Model.Invitation,
+				"welcomeMessage": input.Model.WelcomeMessage,
+				"cname":          input.Model.CNAME,
+				"locale":         input.Model.Locale,
+				"logoBlobKey":    input.Model.Logo.BlobKey,
 			},
-		); err != nil {
+			ClientIP: c.Request.ClientIP,
+		}); err != nil {
 			return c.Failure(err)
 		}
 
@@ -63,7 +91,16 @@ func UpdateAdvancedSettings() web.HandlerFunc {
 			return c.HandleValidation(result)
 		}
 
-		if err := bus.Dispatch(c, &cmd.UpdateTenantAdvancedSettings{Settings: input.Model}); err != nil {
+		tenant := c.Tenant()
+		if err := bus.Dispatch(c, &cmd.UpdateTenantAdvancedSettings{Settings: input.Model}, &cmd.AddAuditLog{
+			Action:     enum.AuditActionAdvancedSettingsUpdated,
+			TargetType: "tenant",
+			TargetID:   tenant.ID,
+			TargetName: tenant.Name,
+			Before:     dto.Props{"customCSS": tenant.CustomCSS, "allowedFileTypes": tenant.AllowedFileTypes},
+			After:      dto.Props{"customCSS": input.Model.CustomCSS, "allowedFileTypes": input.Model.AllowedFileTypes},
+			ClientIP:   c.Request.ClientIP,
+		}); err != nil {
 			return c.Failure(err)
 		}
 
@@ -79,8 +116,41 @@ func UpdatePrivacy() web.HandlerFunc {
 			return c.HandleValidation(result)
 		}
 
-		updateSettings := &cmd.UpdateTenantPrivacySettings{Settings: input.Model}
-		if err := bus.Dispatch(c, updateSettings); err != nil {
+		tenant := c.Tenant()
+		if err := bus.Dispatch(c, &cmd.UpdateTenantPrivacySettings{Settings: input.Model}, &cmd.AddAuditLog{
+			Action:     enum.AuditActionPrivacySettingsUpdated,
+			TargetType: "tenant",
+			TargetID:   tenant.ID,
+			TargetName: tenant.Name,
+			Before:     dto.Props{"isPrivate": tenant.IsPrivate, "isRoadmapPublic": tenant.IsRoadmapPublic},
+			After:      dto.Props{"isPrivate": input.Model.IsPrivate, "isRoadmapPublic": input.Model.IsRoadmapPublic},
+			ClientIP:   c.Request.ClientIP,
+		}); err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(web.Map{})
+	}
+}
+
+// UpdateVoting update current tenant's voting settings
+func UpdateVoting() web.HandlerFunc {
+	return func(c *web.Context) error {
+		input := new(actions.UpdateTenantVoting)
+		if result := c.BindTo(input); !result.Ok {
+			return c.HandleValidation(result)
+		}
+
+		tenant := c.Tenant()
+		if err := bus.Dispatch(c, &cmd.UpdateTenantVotingSettings{Settings: input.Model}, &cmd.AddAuditLog{
+			Action:     enum.AuditActionVotingSettingsUpdated,
+			TargetType: "tenant",
+			TargetID:   tenant.ID,
+			TargetName: tenant.Name,
+			Before:     dto.Props{"mode": tenant.VotingMode, "budget": tenant.VoteBudget},
+			After:      dto.Props{"mode": input.Model.Mode, "budget": input.Model.Budget},
+			ClientIP:   c.Request.ClientIP,
+		}); err != nil {
 			return c.Failure(err)
 		}
 
@@ -96,11 +166,79 @@ func ManageMembers() web.HandlerFunc {
 			return c.Failure(err)
 		}
 
+		//Email addresses are only visible to administrators
+		suppressions := make([]*models.EmailSuppression, 0)
+		if c.IsAuthenticated() && c.User().IsAdministrator() {
+			listSuppressions := &query.ListEmailSuppressions{}
+			if err := bus.Dispatch(c, listSuppressions); err != nil {
+				return c.Failure(err)
+			}
+			suppressions = listSuppressions.Result
+		}
+
 		return c.Page(web.Props{
 			Title:     "Manage Members · Site Settings",
 			ChunkName: "ManageMembers.page",
 			Data: web.Map{
-				"users": allUsers.Result,
+				"users":        allUsers.Result,
+				"suppressions": suppressions,
+			},
+		})
+	}
+}
+
+// ClearEmailSuppression removes an address from the suppression list so that it can receive emails again
+func ClearEmailSuppression() web.HandlerFunc {
+	return func(c *web.Context) error {
+		address := c.Param("address")
+		if err := bus.Dispatch(c, &cmd.DeleteEmailSuppression{Address: address}, &cmd.AddAuditLog{
+			Action:     enum.AuditActionEmailSuppressionCleared,
+			TargetType: "email_suppression",
+			TargetName: address,
+			ClientIP:   c.Request.ClientIP,
+		}); err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(web.Map{})
+	}
+}
+
+// AuditLogPage is the page used by administrators to review administrative and moderation actions
+func AuditLogPage() web.HandlerFunc {
+	return func(c *web.Context) error {
+		listAuditLogs := &query.ListAuditLogs{Limit: 100}
+		allUsers := &query.GetAllUsers{}
+		if err := bus.Dispatch(c, listAuditLogs, allUsers); err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Page(web.Props{
+			Title:     "Audit Log · Site Settings",
+			ChunkName: "AuditLog.page",
+			Data: web.Map{
+				"logs":    listAuditLogs.Result,
+				"users":   allUsers.Result,
+				"actions": enum.AllAuditActions,
+			},
+		})
+	}
+}
+
+// AnalyticsPage is the page used by staff to follow the activity of the site over time
+func AnalyticsPage() web.HandlerFunc {
+	return func(c *web.Context) error {
+		getAnalytics := &query.GetAnalytics{}
+		if err := bus.Dispatch(c, getAnalytics); err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Page(web.Props{
+			Title:     "Analytics · Site Settings",
+			ChunkName: "Analytics.page",
+			Data: web.Map{
+				"analytics": getAnalytics.Result,
+				"intervals": models.AnalyticsIntervals,
 			},
 		})
 	}
@@ -146,15 +284,66 @@ func SaveOAuthConfig() web.HandlerFunc {
 			return c.HandleValidation(result)
 		}
 
-		if err := bus.Dispatch(c,
-			&cmd.UploadImage{
-				Image:  input.Model.Logo,
-				Folder: "logos",
-			},
-			&cmd.SaveCustomOAuthConfig{
-				Config: input.Model,
-			},
-		); err != nil {
+		if err := bus.Dispatch(c, &cmd.UploadImage{
+			Image:  input.Model.Logo,
+			Folder: "logos",
+		}); err != nil {
+			return c.Failure(err)
+		}
+
+		var before dto.Props
+		after := dto.Props{
+			"provider":          input.Model.Provider,
+			"protocol":          input.Model.Protocol,
+			"status":            input.Model.Status,
+			"displayName":       input.Model.DisplayName,
+			"logoBlobKey":       input.Model.Logo.BlobKey,
+			"clientID":          input.Model.ClientID,
+			"issuerURL":         input.Model.IssuerURL,
+			"authorizeURL":      input.Model.AuthorizeURL,
+			"tokenURL":          input.Model.TokenURL,
+			"profileURL":        input.Model.ProfileURL,
+			"scope":             input.Model.Scope,
+			"jsonUserIDPath":    input.Model.JSONUserIDPath,
+			"jsonUserNamePath":  input.Model.JSONUserNamePath,
+			"jsonUserEmailPath": input.Model.JSONUserEmailPath,
+		}
+
+		//Secrets are never recorded, only whether they have been changed
+		if input.Config != nil {
+			before = dto.Props{
+				"provider":          input.Config.Provider,
+				"protocol":          input.Config.Protocol,
+				"status":            input.Config.Status,
+				"displayName":       input.Config.DisplayName,
+				"logoBlobKey":       input.Config.LogoBlobKey,
+				"clientID":          input.Config.ClientID,
+				"issuerURL":         input.Config.IssuerURL,
+				"authorizeURL":      input.Config.AuthorizeURL,
+				"tokenURL":          input.Config.TokenURL,
+				"profileURL":        input.Config.ProfileURL,
+				"scope":             input.Config.Scope,
+				"jsonUserIDPath":    input.Config.JSONUserIDPath,
+				"jsonUserNamePath":  input.Config.JSONUserNamePath,
+				"jsonUserEmailPath": input.Config.JSONUserEmailPath,
+			}
+			after["clientSecretChanged"] = input.Model.ClientSecret != input.Config.ClientSecret
+		}
+
+		if err := bus.Dispatch(c, &cmd.SaveCustomOAuthConfig{Config: input.Model}); err != nil {
+			return c.Failure(err)
+		}
+
+		//New providers only have an ID once they've been saved
+		if err := bus.Dispatch(c, &cmd.AddAuditLog{
+			Action:     enum.AuditActionOAuthConfigSaved,
+			TargetType: "oauth_provider",
+			TargetID:   input.Model.ID,
+			TargetName: input.Model.DisplayName,
+			Before:     before,
+			After:      after,
+			ClientIP:   c.Request.ClientIP,
+		}); err != nil {
 			return c.Failure(err)
 		}
 
@@ -7,7 +7,9 @@ import (
 	"net/http"
 	"testing"
 
+	"github.com/getfider/fider/app/models"
 	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/enum"
 
 	"github.com/getfider/fider/app/models/query"
 	. "github.com/getfider/fider/app/pkg/assert"
@@ -22,6 +24,12 @@ import (
 func TestUpdateSettingsHandler(t *testing.T) {
 	RegisterT(t)
 
+	var addAuditLog *cmd.AddAuditLog
+	bus.AddHandler(func(ctx context.Context, c *cmd.AddAuditLog) error {
+		addAuditLog = c
+		return nil
+	})
+
 	var updateCmd *cmd.UpdateTenantSettings
 	bus.AddHandler(func(ctx context.Context, c *cmd.UpdateTenantSettings) error {
 		updateCmd = c
@@ -48,10 +56,18 @@ func TestUpdateSettingsHandler(t *testing.T) {
 	Expect(updateCmd.Settings.Invitation).Equals("Join us!")
 	Expect(updateCmd.Settings.WelcomeMessage).Equals("Welcome to GoT Feedback Forum")
 	Expect(updateCmd.Settings.Logo.BlobKey).Equals("logos/hello-world.png")
+	Expect(addAuditLog.Action).Equals(enum.AuditActionSettingsUpdated)
+	Expect(addAuditLog.TargetID).Equals(mock.DemoTenant.ID)
+	Expect(addAuditLog.After["title"]).Equals("GoT")
+	Expect(addAuditLog.After["logoBlobKey"]).Equals("logos/hello-world.png")
 }
 
 func TestUpdateSettingsHandler_NewLogo(t *testing.T) {
 	RegisterT(t)
+
+	bus.AddHandler(func(ctx context.Context, c *cmd.AddAuditLog) error {
+		return nil
+	})
 	bus.Init(fs.Service{})
 
 	var updateCmd *cmd.UpdateTenantSettings
@@ -96,6 +112,12 @@ func TestUpdateSettingsHandler_NewLogo(t *testing.T) {
 func TestUpdateSettingsHandler_RemoveLogo(t *testing.T) {
 	RegisterT(t)
 
+	var addAuditLog *cmd.AddAuditLog
+	bus.AddHandler(func(ctx context.Context, c *cmd.AddAuditLog) error {
+		addAuditLog = c
+		return nil
+	})
+
 	var updateCmd *cmd.UpdateTenantSettings
 	bus.AddHandler(func(ctx context.Context, c *cmd.UpdateTenantSettings) error {
 		updateCmd = c
@@ -127,11 +149,18 @@ func TestUpdateSettingsHandler_RemoveLogo(t *testing.T) {
 	Expect(updateCmd.Settings.Invitation).Equals("Join us!")
 	Expect(updateCmd.Settings.WelcomeMessage).Equals("Welcome to GoT Feedback Forum")
 	Expect(updateCmd.Settings.Logo.Remove).IsTrue()
+	Expect(addAuditLog.Before["logoBlobKey"]).Equals("logos/hello-world.png")
 }
 
 func TestUpdatePrivacyHandler(t *testing.T) {
 	RegisterT(t)
 
+	var addAuditLog *cmd.AddAuditLog
+	bus.AddHandler(func(ctx context.Context, c *cmd.AddAuditLog) error {
+		addAuditLog = c
+		return nil
+	})
+
 	var updateCmd *cmd.UpdateTenantPrivacySettings
 	bus.AddHandler(func(ctx context.Context, c *cmd.UpdateTenantPrivacySettings) error {
 		updateCmd = c
@@ -144,11 +173,15 @@ func TestUpdatePrivacyHandler(t *testing.T) {
 		AsUser(mock.JonSnow).
 		ExecutePost(
 			handlers.UpdatePrivacy(),
-			`{ "isPrivate": true }`,
+			`{ "isPrivate": true, "isRoadmapPublic": true }`,
 		)
 
 	Expect(code).Equals(http.StatusOK)
 	Expect(updateCmd.Settings.IsPrivate).IsTrue()
+	Expect(updateCmd.Settings.IsRoadmapPublic).IsTrue()
+	Expect(addAuditLog.Action).Equals(enum.AuditActionPrivacySettingsUpdated)
+	Expect(addAuditLog.Before["isPrivate"]).IsFalse()
+	Expect(addAuditLog.After["isPrivate"]).IsTrue()
 }
 
 func TestManageMembersHandler(t *testing.T) {
@@ -167,3 +200,77 @@ func TestManageMembersHandler(t *testing.T) {
 
 	Expect(code).Equals(http.StatusOK)
 }
+
+func TestManageMembersHandler_Suppressions(t *testing.T) {
+	RegisterT(t)
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetAllUsers) error {
+		return nil
+	})
+
+	listed := false
+	bus.AddHandler(func(ctx context.Context, q *query.ListEmailSuppressions) error {
+		listed = true
+		return nil
+	})
+
+	code, _ := mock.NewServer().
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.AryaStark).
+		Execute(handlers.ManageMembers())
+	Expect(code).Equals(http.StatusOK)
+	Expect(listed).IsFalse()
+
+	code, _ = mock.NewServer().
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		Execute(handlers.ManageMembers())
+	Expect(code).Equals(http.StatusOK)
+	Expect(listed).IsTrue()
+}
+
+func TestAnalyticsPageHandler(t *testing.T) {
+	RegisterT(t)
+
+	var getAnalytics *query.GetAnalytics
+	bus.AddHandler(func(ctx context.Context, q *query.GetAnalytics) error {
+		getAnalytics = q
+		q.Result = &models.Analytics{Interval: "day"}
+		return nil
+	})
+
+	code, _ := mock.NewServer().
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		Execute(handlers.AnalyticsPage())
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(getAnalytics).IsNotNil()
+}
+
+func TestClearEmailSuppressionHandler(t *testing.T) {
+	RegisterT(t)
+
+	var addAuditLog *cmd.AddAuditLog
+	bus.AddHandler(func(ctx context.Context, c *cmd.AddAuditLog) error {
+		addAuditLog = c
+		return nil
+	})
+
+	var deleteCmd *cmd.DeleteEmailSuppression
+	bus.AddHandler(func(ctx context.Context, c *cmd.DeleteEmailSuppression) error {
+		deleteCmd = c
+		return nil
+	})
+
+	code, _ := mock.NewServer().
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		AddParam("address", "jon.snow@got.com").
+		Execute(handlers.ClearEmailSuppression())
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(deleteCmd.Address).Equals("jon.snow@got.com")
+	Expect(addAuditLog.Action).Equals(enum.AuditActionEmailSuppressionCleared)
+	Expect(addAuditLog.TargetName).Equals("jon.snow@got.com")
+}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/getfider/fider/app/actions"
+	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/dto"
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/models/query"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/web"
+)
+
+// ListMyAPITokens returns all active API Tokens of current user
+func ListMyAPITokens() web.HandlerFunc {
+	return func(c *web.Context) error {
+		listTokens := &query.ListCurrentUserAPITokens{}
+		if err := bus.Dispatch(c, listTokens); err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(listTokens.Result)
+	}
+}
+
+// ListAllAPITokens returns all active API Tokens of current tenant
+func ListAllAPITokens() web.HandlerFunc {
+	return func(c *web.Context) error {
+		listTokens := &query.ListAllAPITokens{}
+		if err := bus.Dispatch(c, listTokens); err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(listTokens.Result)
+	}
+}
+
+// CreateAPIToken creates a new API Token for current user
+// The key is only returned once, as only its hash is stored
+func CreateAPIToken() web.HandlerFunc {
+	return func(c *web.Context) error {
+		input := new(actions.CreateAPIToken)
+		if result := c.BindTo(input); !result.Ok {
+			return c.HandleValidation(result)
+		}
+
+		createToken := &cmd.CreateAPIToken{
+			Name:   input.Model.Name,
+			Key:    models.GenerateAPITokenKey(),
+			Scopes: input.Model.Scopes,
+		}
+		if input.Model.ExpiresInDays > 0 {
+			expiresAt := time.Now().AddDate(0, 0, input.Model.ExpiresInDays)
+			createToken.ExpiresAt = &expiresAt
+		}
+
+		if err := bus.Dispatch(c, createToken); err != nil {
+			return c.Failure(err)
+		}
+
+		if err := bus.Dispatch(c, &cmd.AddAuditLog{
+			Action:     enum.AuditActionAPITokenCreated,
+			TargetType: "api_token",
+			TargetID:   createToken.Result.ID,
+			TargetName: createToken.Result.Name,
+			After:      apiTokenProps(createToken.Result),
+			ClientIP:   c.Request.ClientIP,
+		}); err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(web.Map{
+			"token": createToken.Result,
+			"key":   createToken.Key,
+		})
+	}
+}
+
+// RevokeAPIToken revokes an existing API Token
+func RevokeAPIToken() web.HandlerFunc {
+	return func(c *web.Context) error {
+		input := new(actions.RevokeAPIToken)
+		if result := c.BindTo(input); !result.Ok {
+			return c.HandleValidation(result)
+		}
+
+		if err := bus.Dispatch(c, &cmd.RevokeAPIToken{TokenID: input.Token.ID}, &cmd.AddAuditLog{
+			Action:     enum.AuditActionAPITokenRevoked,
+			TargetType: "api_token",
+			TargetID:   input.Token.ID,
+			TargetName: input.Token.Name,
+			Before:     apiTokenProps(input.Token),
+			ClientIP:   c.Request.ClientIP,
+		}); err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(web.Map{})
+	}
+}
+
+//apiTokenProps returns the values of an API Token as they are recorded on the audit log, the key is never recorded
+func apiTokenProps(token *models.APIToken) dto.Props {
+	props := dto.Props{"scopes": token.Scopes}
+	if token.User != nil {
+		props["userID"] = token.User.ID
+	}
+	if token.ExpiresAt != nil {
+		props["expiresAt"] = token.ExpiresAt
+	}
+	return props
+}
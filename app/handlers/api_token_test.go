@@ -0,0 +1,97 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/handlers"
+	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/models/query"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/mock"
+)
+
+func TestCreateAPITokenHandler(t *testing.T) {
+	RegisterT(t)
+
+	var addAuditLog *cmd.AddAuditLog
+	bus.AddHandler(func(ctx context.Context, c *cmd.AddAuditLog) error {
+		addAuditLog = c
+		return nil
+	})
+
+	var createCmd *cmd.CreateAPIToken
+	bus.AddHandler(func(ctx context.Context, c *cmd.CreateAPIToken) error {
+		createCmd = c
+		c.Result = &models.APIToken{ID: 3, Name: c.Name, User: mock.JonSnow, Scopes: c.Scopes}
+		return nil
+	})
+
+	code, _ := mock.NewServer().
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		ExecutePost(handlers.CreateAPIToken(), `{ "name": "CI", "scopes": ["posts:read"] }`)
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(createCmd.Name).Equals("CI")
+	Expect(createCmd.Key).IsNotEmpty()
+	Expect(addAuditLog.Action).Equals(enum.AuditActionAPITokenCreated)
+	Expect(addAuditLog.TargetID).Equals(3)
+	Expect(addAuditLog.After["key"]).IsNil()
+}
+
+func TestRevokeAPITokenHandler(t *testing.T) {
+	RegisterT(t)
+
+	token := &models.APIToken{ID: 3, Name: "CI", User: mock.AryaStark, Scopes: []enum.APIScope{enum.APIScopePostsRead}}
+	bus.AddHandler(func(ctx context.Context, q *query.GetAPITokenByID) error {
+		q.Result = token
+		return nil
+	})
+
+	var addAuditLog *cmd.AddAuditLog
+	bus.AddHandler(func(ctx context.Context, c *cmd.AddAuditLog) error {
+		addAuditLog = c
+		return nil
+	})
+
+	var revokeCmd *cmd.RevokeAPIToken
+	bus.AddHandler(func(ctx context.Context, c *cmd.RevokeAPIToken) error {
+		revokeCmd = c
+		return nil
+	})
+
+	code, _ := mock.NewServer().
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		AddParam("id", 3).
+		Execute(handlers.RevokeAPIToken())
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(revokeCmd.TokenID).Equals(3)
+	Expect(addAuditLog.Action).Equals(enum.AuditActionAPITokenRevoked)
+	Expect(addAuditLog.TargetName).Equals("CI")
+	Expect(addAuditLog.Before["userID"]).Equals(mock.AryaStark.ID)
+}
+
+func TestRevokeAPITokenHandler_NotFound(t *testing.T) {
+	RegisterT(t)
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetAPITokenByID) error {
+		return app.ErrNotFound
+	})
+
+	code, response := mock.NewServer().
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		AddParam("id", 999).
+		Execute(handlers.RevokeAPIToken())
+
+	Expect(code).Equals(http.StatusBadRequest)
+	Expect(response.Body.String()).ContainsSubstring("API Token not found.")
+}
@@ -0,0 +1,48 @@
+package apiv1
+
+import (
+	"time"
+
+	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/models/query"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/validate"
+	"github.com/getfider/fider/app/pkg/web"
+)
+
+//maxAnalyticsPeriodDays is the longest period covered by a single analytics report
+const maxAnalyticsPeriodDays = 731
+
+// GetAnalytics returns the activity report of current tenant for given period, which defaults to the last 30 days
+func GetAnalytics() web.HandlerFunc {
+	return func(c *web.Context) error {
+		result := validate.Success()
+		getAnalytics := &query.GetAnalytics{
+			Since:    parseDateParam(c, "since", result),
+			Until:    parseDateParam(c, "until", result),
+			Interval: parseOptionParam(c, "interval", models.AnalyticsIntervals, result),
+		}
+
+		until := getAnalytics.Until
+		if until.IsZero() {
+			until = time.Now()
+		}
+		if !getAnalytics.Since.IsZero() {
+			if !getAnalytics.Since.Before(until) {
+				result.AddFieldFailure("until", "'until' must be after 'since'.")
+			} else if until.Sub(getAnalytics.Since) > maxAnalyticsPeriodDays*24*time.Hour {
+				result.AddFieldFailure("since", "Period must not be longer than 2 years.")
+			}
+		}
+
+		if !result.Ok {
+			return c.HandleValidation(result)
+		}
+
+		if err := bus.Dispatch(c, getAnalytics); err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(getAnalytics.Result)
+	}
+}
@@ -0,0 +1,93 @@
+package apiv1_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/getfider/fider/app/handlers/apiv1"
+	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/models/query"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/mock"
+)
+
+func TestGetAnalyticsHandler(t *testing.T) {
+	RegisterT(t)
+
+	var getAnalytics *query.GetAnalytics
+	bus.AddHandler(func(ctx context.Context, q *query.GetAnalytics) error {
+		getAnalytics = q
+		q.Result = &models.Analytics{
+			Since:    q.Since,
+			Until:    q.Until,
+			Interval: q.Interval,
+			TopVoted: []*models.AnalyticsPost{{Number: 1, Title: "Add dark mode", Votes: 4}},
+		}
+		return nil
+	})
+
+	code, response := mock.NewServer().
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		WithURL("http://demo.test.fider.io/api/v1/analytics?since=2019-06-01&until=2019-09-01&interval=week").
+		Execute(apiv1.GetAnalytics())
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(getAnalytics.Since).Equals(time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC))
+	Expect(getAnalytics.Until).Equals(time.Date(2019, 9, 1, 0, 0, 0, 0, time.UTC))
+	Expect(getAnalytics.Interval).Equals("week")
+	Expect(response.Body.String()).ContainsSubstring(`"title":"Add dark mode"`)
+}
+
+func TestGetAnalyticsHandler_Defaults(t *testing.T) {
+	RegisterT(t)
+
+	var getAnalytics *query.GetAnalytics
+	bus.AddHandler(func(ctx context.Context, q *query.GetAnalytics) error {
+		getAnalytics = q
+		q.Result = &models.Analytics{}
+		return nil
+	})
+
+	code, _ := mock.NewServer().
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		WithURL("http://demo.test.fider.io/api/v1/analytics").
+		Execute(apiv1.GetAnalytics())
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(getAnalytics.Since.IsZero()).IsTrue()
+	Expect(getAnalytics.Until.IsZero()).IsTrue()
+	Expect(getAnalytics.Interval).Equals("")
+}
+
+func TestGetAnalyticsHandler_InvalidParams(t *testing.T) {
+	RegisterT(t)
+
+	code, response := mock.NewServer().
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		WithURL("http://demo.test.fider.io/api/v1/analytics?since=2019-09-01&until=2019-06-01&interval=hour").
+		Execute(apiv1.GetAnalytics())
+
+	Expect(code).Equals(http.StatusBadRequest)
+	body := response.Body.String()
+	Expect(body).ContainsSubstring(`"field":"until"`)
+	Expect(body).ContainsSubstring(`"field":"interval"`)
+}
+
+func TestGetAnalyticsHandler_PeriodTooLong(t *testing.T) {
+	RegisterT(t)
+
+	code, response := mock.NewServer().
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		WithURL("http://demo.test.fider.io/api/v1/analytics?since=2015-01-01&until=2019-01-01").
+		Execute(apiv1.GetAnalytics())
+
+	Expect(code).Equals(http.StatusBadRequest)
+	Expect(response.Body.String()).ContainsSubstring(`"field":"since"`)
+}
@@ -0,0 +1,70 @@
+package apiv1
+
+import (
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/models/query"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/csv"
+	"github.com/getfider/fider/app/pkg/validate"
+	"github.com/getfider/fider/app/pkg/web"
+)
+
+// ListAuditLogs returns the most recent entries of the audit log, optionally filtered by actor, action and date
+func ListAuditLogs() web.HandlerFunc {
+	return func(c *web.Context) error {
+		result := validate.Success()
+		listAuditLogs := parseAuditLogFilters(c, result)
+		listAuditLogs.Limit = parseIntParam(c, "limit", result)
+		if listAuditLogs.Limit == 0 {
+			listAuditLogs.Limit = 100
+		} else if listAuditLogs.Limit > 1000 {
+			result.AddFieldFailure("limit", "'limit' must be less than or equal to 1000.")
+		}
+
+		if !result.Ok {
+			return c.HandleValidation(result)
+		}
+
+		if err := bus.Dispatch(c, listAuditLogs); err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(listAuditLogs.Result)
+	}
+}
+
+// ExportAuditLogsToCSV returns a CSV with all entries of the audit log that match given filters
+func ExportAuditLogsToCSV() web.HandlerFunc {
+	return func(c *web.Context) error {
+		result := validate.Success()
+		listAuditLogs := parseAuditLogFilters(c, result)
+		if !result.Ok {
+			return c.HandleValidation(result)
+		}
+
+		if err := bus.Dispatch(c, listAuditLogs); err != nil {
+			return c.Failure(err)
+		}
+
+		bytes, err := csv.FromAuditLogs(listAuditLogs.Result)
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Attachment("audit.csv", "text/csv", bytes)
+	}
+}
+
+func parseAuditLogFilters(c *web.Context, result *validate.Result) *query.ListAuditLogs {
+	actions := make([]string, len(enum.AllAuditActions))
+	for i, action := range enum.AllAuditActions {
+		actions[i] = string(action)
+	}
+
+	return &query.ListAuditLogs{
+		ActorID:       parseIntParam(c, "actor", result),
+		Action:        enum.AuditAction(parseOptionParam(c, "action", actions, result)),
+		CreatedAfter:  parseDateParam(c, "createdAfter", result),
+		CreatedBefore: parseDateParam(c, "createdBefore", result),
+	}
+}
@@ -0,0 +1,81 @@
+package apiv1_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/getfider/fider/app/handlers/apiv1"
+	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/models/query"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/mock"
+)
+
+func TestListAuditLogsHandler(t *testing.T) {
+	RegisterT(t)
+
+	var listAuditLogs *query.ListAuditLogs
+	bus.AddHandler(func(ctx context.Context, q *query.ListAuditLogs) error {
+		listAuditLogs = q
+		q.Result = []*models.AuditLog{
+			{ID: 1, Action: enum.AuditActionUserBlocked, Actor: mock.JonSnow, TargetType: "user", TargetID: 2},
+		}
+		return nil
+	})
+
+	code, response := mock.NewServer().
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		WithURL("http://demo.test.fider.io/api/v1/audit?actor=1&action=user.blocked&createdAfter=2019-08-01&createdBefore=2019-09-01").
+		ExecuteAsJSON(apiv1.ListAuditLogs())
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(listAuditLogs.ActorID).Equals(1)
+	Expect(listAuditLogs.Action).Equals(enum.AuditActionUserBlocked)
+	Expect(listAuditLogs.CreatedAfter).Equals(time.Date(2019, 8, 1, 0, 0, 0, 0, time.UTC))
+	Expect(listAuditLogs.CreatedBefore).Equals(time.Date(2019, 9, 1, 0, 0, 0, 0, time.UTC))
+	Expect(listAuditLogs.Limit).Equals(100)
+	Expect(response.ArrayLength()).Equals(1)
+}
+
+func TestListAuditLogsHandler_InvalidParams(t *testing.T) {
+	RegisterT(t)
+
+	code, response := mock.NewServer().
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		WithURL("http://demo.test.fider.io/api/v1/audit?actor=abc&action=unknown&createdAfter=yesterday&limit=5000").
+		Execute(apiv1.ListAuditLogs())
+
+	Expect(code).Equals(http.StatusBadRequest)
+	body := response.Body.String()
+	Expect(body).ContainsSubstring(`"field":"actor"`)
+	Expect(body).ContainsSubstring(`"field":"action"`)
+	Expect(body).ContainsSubstring(`"field":"createdAfter"`)
+	Expect(body).ContainsSubstring(`"field":"limit"`)
+}
+
+func TestExportAuditLogsToCSVHandler(t *testing.T) {
+	RegisterT(t)
+
+	bus.AddHandler(func(ctx context.Context, q *query.ListAuditLogs) error {
+		q.Result = []*models.AuditLog{
+			{ID: 1, Action: enum.AuditActionTagDeleted, TargetType: "tag", TargetID: 5, TargetName: "Bug"},
+		}
+		return nil
+	})
+
+	code, response := mock.NewServer().
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		WithURL("http://demo.test.fider.io/api/v1/audit.csv?action=tag.deleted").
+		Execute(apiv1.ExportAuditLogsToCSV())
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(response.Header().Get("Content-Type")).Equals("text/csv")
+	Expect(response.Body.String()).ContainsSubstring("tag.deleted,,,tag,5,Bug")
+}
@@ -0,0 +1,87 @@
+package apiv1
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/pkg/cursor"
+	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/getfider/fider/app/pkg/validate"
+	"github.com/getfider/fider/app/pkg/web"
+)
+
+var dateLayouts = []string{time.RFC3339, "2006-01-02"}
+
+func parseIntParam(c *web.Context, key string, result *validate.Result) int {
+	value, err := c.QueryParamAsInt(key)
+	if err != nil || value < 0 {
+		result.AddFieldFailure(key, fmt.Sprintf("'%s' must be a positive number.", key))
+		return 0
+	}
+	return value
+}
+
+func parseDateParam(c *web.Context, key string, result *validate.Result) time.Time {
+	value := c.QueryParam(key)
+	if value == "" {
+		return time.Time{}
+	}
+	for _, layout := range dateLayouts {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date
+		}
+	}
+	result.AddFieldFailure(key, fmt.Sprintf("'%s' must be a date formatted as YYYY-MM-DD or RFC 3339.", key))
+	return time.Time{}
+}
+
+func parseOptionParam(c *web.Context, key string, options []string, result *validate.Result) string {
+	value := c.QueryParam(key)
+	if value == "" {
+		return ""
+	}
+	for _, option := range options {
+		if value == option {
+			return value
+		}
+	}
+	result.AddFieldFailure(key, fmt.Sprintf("'%s' must be one of: %s.", key, strings.Join(options, ", ")))
+	return ""
+}
+
+//parseStatusParam parses a list of statuses, deleted posts are never visible and can't be requested
+func parseStatusParam(c *web.Context, key string, result *validate.Result) []enum.PostStatus {
+	statuses := make([]enum.PostStatus, 0)
+	for _, name := range c.QueryParamAsArray(key) {
+		var status enum.PostStatus
+		if err := status.UnmarshalText([]byte(name)); err != nil || status.Name() != name || status == enum.PostDeleted {
+			result.AddFieldFailure(key, fmt.Sprintf("'%s' is not a valid status.", name))
+			continue
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+//handleCursorFailure returns 400 for cursors that are malformed or don't match the request
+func handleCursorFailure(c *web.Context, err error) error {
+	if errors.Cause(err) == cursor.ErrInvalid {
+		result := validate.Success()
+		result.AddFieldFailure("cursor", "Cursor is invalid.")
+		return c.HandleValidation(result)
+	}
+	return c.Failure(err)
+}
+
+//setNextLink adds a Link header pointing to the next page, as described in RFC 8288
+func setNextLink(c *web.Context, nextCursor string) {
+	if nextCursor == "" {
+		return
+	}
+	params := c.Request.URL.Query()
+	params.Set("cursor", nextCursor)
+	next := fmt.Sprintf("%s%s?%s", c.BaseURL(), c.Request.URL.Path, params.Encode())
+	c.Response.Header().Set("Link", fmt.Sprintf(`<%s>; rel="next"`, next))
+}
@@ -1,29 +1,67 @@
 package apiv1
 
 import (
+	"strconv"
+
+	"github.com/getfider/fider/app"
 	"github.com/getfider/fider/app/actions"
 	"github.com/getfider/fider/app/models"
 	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/dto"
 	"github.com/getfider/fider/app/models/enum"
 	"github.com/getfider/fider/app/models/query"
 	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/getfider/fider/app/pkg/validate"
 	"github.com/getfider/fider/app/pkg/web"
 	"github.com/getfider/fider/app/tasks"
 )
 
+var postSortFields = []string{"created", "updated", "votes", "score", "comments"}
+
 // SearchPosts return existing posts based on search criteria
+// Posts are sorted by newest first unless a sort, view or query is given
+// When sorted by a field and there are more posts, a Link header points to the next page
 func SearchPosts() web.HandlerFunc {
 	return func(c *web.Context) error {
+		result := validate.Success()
 		searchPosts := &query.SearchPosts{
-			Query: c.QueryParam("query"),
-			View:  c.QueryParam("view"),
-			Limit: c.QueryParam("limit"),
-			Tags:  c.QueryParamAsArray("tags"),
+			Query:         c.QueryParam("query"),
+			View:          c.QueryParam("view"),
+			Limit:         c.QueryParam("limit"),
+			Tags:          c.QueryParamAsArray("tags"),
+			Statuses:      parseStatusParam(c, "status", result),
+			AuthorID:      parseIntParam(c, "author", result),
+			CreatedAfter:  parseDateParam(c, "createdAfter", result),
+			CreatedBefore: parseDateParam(c, "createdBefore", result),
+			UpdatedAfter:  parseDateParam(c, "updatedAfter", result),
+			UpdatedBefore: parseDateParam(c, "updatedBefore", result),
+			Sort:          parseOptionParam(c, "sort", postSortFields, result),
+			Order:         parseOptionParam(c, "order", []string{"asc", "desc"}, result),
+			Cursor:        c.QueryParam("cursor"),
+		}
+		searchPosts.MatchAnyTag = parseOptionParam(c, "tagsMode", []string{"all", "any"}, result) == "any"
+
+		if limit := searchPosts.Limit; limit != "" {
+			if value, err := strconv.Atoi(limit); err != nil || value < 0 {
+				result.AddFieldFailure("limit", "Limit must be a positive number.")
+			}
+		}
+
+		//Newest first is the only default that can be paginated with a cursor
+		if searchPosts.Sort == "" && searchPosts.View == "" && searchPosts.Query == "" && searchPosts.Cursor == "" {
+			searchPosts.Sort = "id"
+		}
+
+		if !result.Ok {
+			return c.HandleValidation(result)
 		}
+
 		if err := bus.Dispatch(c, searchPosts); err != nil {
-			return c.Failure(err)
+			return handleCursorFailure(c, err)
 		}
 
+		setNextLink(c, searchPosts.NextCursor)
 		return c.Ok(searchPosts.Result)
 	}
 }
@@ -36,7 +74,10 @@ func CreatePost() web.HandlerFunc {
 			return c.HandleValidation(result)
 		}
 
-		if err := bus.Dispatch(c, &cmd.UploadImages{Images: input.Model.Attachments, Folder: "attachments"}); err != nil {
+		if err := bus.Dispatch(c,
+			&cmd.UploadImages{Images: input.Model.Attachments, Folder: "attachments"},
+			&cmd.UploadFiles{Files: input.Model.Files, Folder: "files"},
+		); err != nil {
 			return c.Failure(err)
 		}
 
@@ -50,12 +91,21 @@ func CreatePost() web.HandlerFunc {
 		}
 
 		setAttachments := &cmd.SetAttachments{Post: newPost.Result, Attachments: input.Model.Attachments}
-		addVote := &cmd.AddVote{Post: newPost.Result, User: c.User()}
-		if err = bus.Dispatch(c, setAttachments, addVote); err != nil {
+		setFiles := &cmd.SetFileAttachments{Post: newPost.Result, Files: input.Model.Files}
+		if err = bus.Dispatch(c, setAttachments, setFiles); err != nil {
+			return c.Failure(err)
+		}
+
+		//Author votes on their own post with a single credit, which is skipped when their budget is already spent
+		addVote := &cmd.AddVote{Post: newPost.Result, User: c.User(), Credits: 1}
+		if err = bus.Dispatch(c, addVote); err != nil && errors.Cause(err) != app.ErrVoteBudgetExceeded {
 			return c.Failure(err)
 		}
 
 		c.Enqueue(tasks.NotifyAboutNewPost(newPost.Result))
+		c.Enqueue(tasks.TriggerWebhooks(enum.WebhookEventPostCreated, web.Map{
+			"post": newPost.Result,
+		}))
 
 		return c.Ok(web.Map{
 			"id":     newPost.Result.ID,
@@ -96,6 +146,10 @@ func UpdatePost() web.HandlerFunc {
 				Images: input.Model.Attachments,
 				Folder: "attachments",
 			},
+			&cmd.UploadFiles{
+				Files:  input.Model.Files,
+				Folder: "files",
+			},
 			&cmd.UpdatePost{
 				Post:        input.Post,
 				Title:       input.Model.Title,
@@ -105,6 +159,10 @@ func UpdatePost() web.HandlerFunc {
 				Post:        input.Post,
 				Attachments: input.Model.Attachments,
 			},
+			&cmd.SetFileAttachments{
+				Post:  input.Post,
+				Files: input.Model.Files,
+			},
 		)
 		if err != nil {
 			return c.Failure(err)
@@ -128,10 +186,13 @@ func SetResponse() web.HandlerFunc {
 		}
 
 		prevStatus := getPost.Result.Status
+		before := postResponseProps(getPost.Result)
+		after := dto.Props{"status": input.Model.Status, "response": input.Model.Text}
 
 		var command bus.Msg
 		if input.Model.Status == enum.PostDuplicate {
 			command = &cmd.MarkPostAsDuplicate{Post: getPost.Result, Original: input.Original}
+			after["originalNumber"] = input.Original.Number
 		} else {
 			command = &cmd.SetPostResponse{
 				Post:   getPost.Result,
@@ -140,16 +201,94 @@ func SetResponse() web.HandlerFunc {
 			}
 		}
 
-		if err := bus.Dispatch(c, command); err != nil {
+		if err := bus.Dispatch(c, command, &cmd.AddAuditLog{
+			Action:     enum.AuditActionPostStatusChanged,
+			TargetType: "post",
+			TargetID:   getPost.Result.ID,
+			TargetName: getPost.Result.Title,
+			Before:     before,
+			After:      after,
+			ClientIP:   c.Request.ClientIP,
+		}); err != nil {
 			return c.Failure(err)
 		}
 
 		c.Enqueue(tasks.NotifyAboutStatusChange(getPost.Result, prevStatus))
+		c.Enqueue(tasks.TriggerWebhooks(enum.WebhookEventPostStatusChanged, web.Map{
+			"post":           getPost.Result,
+			"previousStatus": prevStatus,
+		}))
 
 		return c.Ok(web.Map{})
 	}
 }
 
+// MergePost merges a post into another, moving its votes, comments and subscribers to the original post
+func MergePost() web.HandlerFunc {
+	return func(c *web.Context) error {
+		input := new(actions.MergePost)
+		if result := c.BindTo(input); !result.Ok {
+			return c.HandleValidation(result)
+		}
+
+		prevStatus := input.Post.Status
+		merge := &cmd.MergePost{Post: input.Post, Original: input.Original}
+		if err := bus.Dispatch(c, merge, &cmd.AddAuditLog{
+			Action:     enum.AuditActionPostMerged,
+			TargetType: "post",
+			TargetID:   input.Post.ID,
+			TargetName: input.Post.Title,
+			Before:     postResponseProps(input.Post),
+			After:      dto.Props{"status": enum.PostDuplicate, "originalNumber": input.Original.Number},
+			ClientIP:   c.Request.ClientIP,
+		}); err != nil {
+			return c.Failure(err)
+		}
+
+		c.Enqueue(tasks.NotifyAboutStatusChange(input.Post, prevStatus))
+		c.Enqueue(tasks.TriggerWebhooks(enum.WebhookEventPostStatusChanged, web.Map{
+			"post":           input.Post,
+			"previousStatus": prevStatus,
+		}))
+
+		return c.Ok(merge.Result)
+	}
+}
+
+// UnmergePost reverts a previous merge, moving votes, comments and subscribers back to the merged post
+func UnmergePost() web.HandlerFunc {
+	return func(c *web.Context) error {
+		input := new(actions.UnmergePost)
+		if result := c.BindTo(input); !result.Ok {
+			return c.HandleValidation(result)
+		}
+
+		unmerge := &cmd.UnmergePost{Merge: input.Merge}
+		if err := bus.Dispatch(c, unmerge); err != nil {
+			return c.Failure(err)
+		}
+
+		if err := bus.Dispatch(c, &cmd.AddAuditLog{
+			Action:     enum.AuditActionPostUnmerged,
+			TargetType: "post",
+			TargetID:   input.Post.ID,
+			TargetName: input.Post.Title,
+			Before:     postResponseProps(input.Post),
+			After:      postResponseProps(unmerge.Result),
+			ClientIP:   c.Request.ClientIP,
+		}); err != nil {
+			return c.Failure(err)
+		}
+
+		c.Enqueue(tasks.TriggerWebhooks(enum.WebhookEventPostStatusChanged, web.Map{
+			"post":           unmerge.Result,
+			"previousStatus": input.Post.Status,
+		}))
+
+		return c.Ok(unmerge.Result)
+	}
+}
+
 // DeletePost deletes an existing post of current tenant
 func DeletePost() web.HandlerFunc {
 	return func(c *web.Context) error {
@@ -158,12 +297,19 @@ func DeletePost() web.HandlerFunc {
 			return c.HandleValidation(result)
 		}
 
-		err := bus.Dispatch(c, &cmd.SetPostResponse{
+		if err := bus.Dispatch(c, &cmd.SetPostResponse{
 			Post:   input.Post,
 			Text:   input.Model.Text,
 			Status: enum.PostDeleted,
-		})
-		if err != nil {
+		}, &cmd.AddAuditLog{
+			Action:     enum.AuditActionPostDeleted,
+			TargetType: "post",
+			TargetID:   input.Post.ID,
+			TargetName: input.Post.Title,
+			Before:     postResponseProps(input.Post),
+			After:      dto.Props{"status": enum.PostDeleted, "response": input.Model.Text},
+			ClientIP:   c.Request.ClientIP,
+		}); err != nil {
 			return c.Failure(err)
 		}
 
@@ -176,7 +322,19 @@ func DeletePost() web.HandlerFunc {
 	}
 }
 
-// ListComments returns a list of all comments of a post
+//postResponseProps returns the status and response of a post as they are recorded on the audit log
+func postResponseProps(post *models.Post) dto.Props {
+	props := dto.Props{"status": post.Status, "response": ""}
+	if post.Response != nil {
+		props["response"] = post.Response.Text
+		if post.Response.Original != nil {
+			props["originalNumber"] = post.Response.Original.Number
+		}
+	}
+	return props
+}
+
+// ListComments returns the comments of a post, optionally filtered and paginated
 func ListComments() web.HandlerFunc {
 	return func(c *web.Context) error {
 		number, err := c.ParamAsInt("number")
@@ -189,11 +347,27 @@ func ListComments() web.HandlerFunc {
 			return c.Failure(err)
 		}
 
-		getComments := &query.GetCommentsByPost{Post: getPost.Result}
+		result := validate.Success()
+		getComments := &query.GetCommentsByPost{
+			Post:          getPost.Result,
+			AuthorID:      parseIntParam(c, "author", result),
+			CreatedAfter:  parseDateParam(c, "createdAfter", result),
+			CreatedBefore: parseDateParam(c, "createdBefore", result),
+			Order:         parseOptionParam(c, "order", []string{"asc", "desc"}, result),
+			Limit:         parseIntParam(c, "limit", result),
+			Cursor:        c.QueryParam("cursor"),
+		}
+		parseOptionParam(c, "sort", []string{"created"}, result)
+
+		if !result.Ok {
+			return c.HandleValidation(result)
+		}
+
 		if err := bus.Dispatch(c, getComments); err != nil {
-			return c.Failure(err)
+			return handleCursorFailure(c, err)
 		}
 
+		setNextLink(c, getComments.NextCursor)
 		return c.Ok(getComments.Result)
 	}
 }
@@ -228,7 +402,10 @@ func PostComment() web.HandlerFunc {
 			return c.Failure(err)
 		}
 
-		if err := bus.Dispatch(c, &cmd.UploadImages{Images: input.Model.Attachments, Folder: "attachments"}); err != nil {
+		if err := bus.Dispatch(c,
+			&cmd.UploadImages{Images: input.Model.Attachments, Folder: "attachments"},
+			&cmd.UploadFiles{Files: input.Model.Files, Folder: "files"},
+		); err != nil {
 			return c.Failure(err)
 		}
 
@@ -240,15 +417,26 @@ func PostComment() web.HandlerFunc {
 			return c.Failure(err)
 		}
 
-		if err := bus.Dispatch(c, &cmd.SetAttachments{
-			Post:        getPost.Result,
-			Comment:     addNewComment.Result,
-			Attachments: input.Model.Attachments,
-		}); err != nil {
+		if err := bus.Dispatch(c,
+			&cmd.SetAttachments{
+				Post:        getPost.Result,
+				Comment:     addNewComment.Result,
+				Attachments: input.Model.Attachments,
+			},
+			&cmd.SetFileAttachments{
+				Post:    getPost.Result,
+				Comment: addNewComment.Result,
+				Files:   input.Model.Files,
+			},
+		); err != nil {
 			return c.Failure(err)
 		}
 
 		c.Enqueue(tasks.NotifyAboutNewComment(getPost.Result, input.Model))
+		c.Enqueue(tasks.TriggerWebhooks(enum.WebhookEventCommentCreated, web.Map{
+			"post":    getPost.Result,
+			"comment": addNewComment.Result,
+		}))
 
 		return c.Ok(web.Map{
 			"id": addNewComment.Result.ID,
@@ -269,6 +457,10 @@ func UpdateComment() web.HandlerFunc {
 				Images: input.Model.Attachments,
 				Folder: "attachments",
 			},
+			&cmd.UploadFiles{
+				Files:  input.Model.Files,
+				Folder: "files",
+			},
 			&cmd.UpdateComment{
 				CommentID: input.Model.ID,
 				Content:   input.Model.Content,
@@ -278,11 +470,24 @@ func UpdateComment() web.HandlerFunc {
 				Comment:     input.Comment,
 				Attachments: input.Model.Attachments,
 			},
+			&cmd.SetFileAttachments{
+				Post:    input.Post,
+				Comment: input.Comment,
+				Files:   input.Model.Files,
+			},
 		)
 		if err != nil {
 			return c.Failure(err)
 		}
 
+		c.Enqueue(tasks.TriggerWebhooks(enum.WebhookEventCommentUpdated, web.Map{
+			"post": input.Post,
+			"comment": web.Map{
+				"id":      input.Comment.ID,
+				"content": input.Model.Content,
+			},
+		}))
+
 		return c.Ok(web.Map{})
 	}
 }
@@ -302,16 +507,52 @@ func DeleteComment() web.HandlerFunc {
 			return c.Failure(err)
 		}
 
+		c.Enqueue(tasks.TriggerWebhooks(enum.WebhookEventCommentDeleted, web.Map{
+			"post": web.Map{
+				"number": input.Model.PostNumber,
+			},
+			"comment": web.Map{
+				"id": input.Model.CommentID,
+			},
+		}))
+
 		return c.Ok(web.Map{})
 	}
 }
 
 // AddVote adds current user to given post list of votes
+// When budgeted voting is enabled, it also sets how many credits are placed on the post
 func AddVote() web.HandlerFunc {
 	return func(c *web.Context) error {
-		return addOrRemove(c, func(post *models.Post, user *models.User) bus.Msg {
-			return &cmd.AddVote{Post: post, User: user}
+		input := new(actions.AddVote)
+		if result := c.BindTo(input); !result.Ok {
+			return c.HandleValidation(result)
+		}
+
+		err := bus.Dispatch(c, &cmd.AddVote{
+			Post:    input.Post,
+			User:    c.User(),
+			Credits: input.Model.Credits,
 		})
+		if err != nil {
+			//Another vote may have spent the credits since they were validated
+			if errors.Cause(err) == app.ErrVoteBudgetExceeded {
+				return c.HandleValidation(validate.Failed("You don't have enough credits left."))
+			}
+			return c.Failure(err)
+		}
+
+		//Post is loaded again so that webhooks receive its votes after the change
+		getPost := &query.GetPostByNumber{Number: input.Post.Number}
+		if err := bus.Dispatch(c, getPost); err != nil {
+			return c.Failure(err)
+		}
+
+		c.Enqueue(tasks.TriggerWebhooks(enum.WebhookEventVoteAdded, web.Map{
+			"post": getPost.Result,
+		}))
+
+		return c.Ok(web.Map{})
 	}
 }
 
@@ -320,7 +561,7 @@ func RemoveVote() web.HandlerFunc {
 	return func(c *web.Context) error {
 		return addOrRemove(c, func(post *models.Post, user *models.User) bus.Msg {
 			return &cmd.RemoveVote{Post: post, User: user}
-		})
+		}, enum.WebhookEventVoteRemoved)
 	}
 }
 
@@ -329,7 +570,7 @@ func Subscribe() web.HandlerFunc {
 	return func(c *web.Context) error {
 		return addOrRemove(c, func(post *models.Post, user *models.User) bus.Msg {
 			return &cmd.AddSubscriber{Post: post, User: user}
-		})
+		}, "")
 	}
 }
 
@@ -338,7 +579,7 @@ func Unsubscribe() web.HandlerFunc {
 	return func(c *web.Context) error {
 		return addOrRemove(c, func(post *models.Post, user *models.User) bus.Msg {
 			return &cmd.RemoveSubscriber{Post: post, User: user}
-		})
+		}, "")
 	}
 }
 
@@ -365,7 +606,7 @@ func ListVotes() web.HandlerFunc {
 	}
 }
 
-func addOrRemove(c *web.Context, getCommand func(post *models.Post, user *models.User) bus.Msg) error {
+func addOrRemove(c *web.Context, getCommand func(post *models.Post, user *models.User) bus.Msg, webhookEvent enum.WebhookEvent) error {
 	number, err := c.ParamAsInt("number")
 	if err != nil {
 		return c.NotFound()
@@ -382,5 +623,17 @@ func addOrRemove(c *web.Context, getCommand func(post *models.Post, user *models
 		return c.Failure(err)
 	}
 
+	if webhookEvent != "" {
+		//Post is loaded again so that webhooks receive its votes after the change
+		getPost = &query.GetPostByNumber{Number: number}
+		if err := bus.Dispatch(c, getPost); err != nil {
+			return c.Failure(err)
+		}
+
+		c.Enqueue(tasks.TriggerWebhooks(webhookEvent, web.Map{
+			"post": getPost.Result,
+		}))
+	}
+
 	return c.Ok(web.Map{})
 }
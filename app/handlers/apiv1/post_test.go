@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/getfider/fider/app/models"
 
@@ -17,6 +18,7 @@ import (
 	"github.com/getfider/fider/app/handlers/apiv1"
 	. "github.com/getfider/fider/app/pkg/assert"
 	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/cursor"
 	"github.com/getfider/fider/app/pkg/mock"
 )
 
@@ -41,6 +43,8 @@ func TestCreatePostHandler(t *testing.T) {
 	bus.AddHandler(func(ctx context.Context, c *cmd.SetAttachments) error { return nil })
 	bus.AddHandler(func(ctx context.Context, c *cmd.AddVote) error { return nil })
 	bus.AddHandler(func(ctx context.Context, c *cmd.UploadImages) error { return nil })
+	bus.AddHandler(func(ctx context.Context, c *cmd.SetFileAttachments) error { return nil })
+	bus.AddHandler(func(ctx context.Context, c *cmd.UploadFiles) error { return nil })
 
 	code, _ := mock.NewServer().
 		OnTenant(mock.DemoTenant).
@@ -52,6 +56,36 @@ func TestCreatePostHandler(t *testing.T) {
 	Expect(newPost.Description).Equals("")
 }
 
+func TestCreatePostHandler_VoteBudgetExceeded(t *testing.T) {
+	RegisterT(t)
+
+	bus.AddHandler(func(ctx context.Context, c *cmd.AddNewPost) error {
+		c.Result = &models.Post{ID: 1, Number: 1, Title: c.Title}
+		return nil
+	})
+
+	var addVote *cmd.AddVote
+	bus.AddHandler(func(ctx context.Context, c *cmd.AddVote) error {
+		addVote = c
+		return app.ErrVoteBudgetExceeded
+	})
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetPostBySlug) error { return app.ErrNotFound })
+	bus.AddHandler(func(ctx context.Context, c *cmd.SetAttachments) error { return nil })
+	bus.AddHandler(func(ctx context.Context, c *cmd.UploadImages) error { return nil })
+	bus.AddHandler(func(ctx context.Context, c *cmd.SetFileAttachments) error { return nil })
+	bus.AddHandler(func(ctx context.Context, c *cmd.UploadFiles) error { return nil })
+
+	tenant := &models.Tenant{ID: 1, Name: "Demonstration", Subdomain: "demo", VotingMode: enum.VotingModeBudgeted, VoteBudget: 6}
+	code, _ := mock.NewServer().
+		OnTenant(tenant).
+		AsUser(mock.JonSnow).
+		ExecutePost(apiv1.CreatePost(), `{ "title": "My newest post :)" }`)
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(addVote.Credits).Equals(1)
+}
+
 func TestCreatePostHandler_WithoutTitle(t *testing.T) {
 	RegisterT(t)
 
@@ -101,6 +135,8 @@ func TestUpdatePostHandler_TenantStaff(t *testing.T) {
 	bus.AddHandler(func(ctx context.Context, q *query.GetPostBySlug) error { return app.ErrNotFound })
 	bus.AddHandler(func(ctx context.Context, c *cmd.SetAttachments) error { return nil })
 	bus.AddHandler(func(ctx context.Context, c *cmd.UploadImages) error { return nil })
+	bus.AddHandler(func(ctx context.Context, c *cmd.SetFileAttachments) error { return nil })
+	bus.AddHandler(func(ctx context.Context, c *cmd.UploadFiles) error { return nil })
 
 	var updatePost *cmd.UpdatePost
 	bus.AddHandler(func(ctx context.Context, c *cmd.UpdatePost) error {
@@ -222,6 +258,12 @@ func TestUpdatePostHandler_DuplicateTitle(t *testing.T) {
 func TestSetResponseHandler(t *testing.T) {
 	RegisterT(t)
 
+	var addAuditLog *cmd.AddAuditLog
+	bus.AddHandler(func(ctx context.Context, c *cmd.AddAuditLog) error {
+		addAuditLog = c
+		return nil
+	})
+
 	post := &models.Post{ID: 1, Number: 1, Title: "My First Post", Slug: "my-first-post"}
 	bus.AddHandler(func(ctx context.Context, q *query.GetPostByNumber) error {
 		if q.Number == post.Number {
@@ -247,6 +289,11 @@ func TestSetResponseHandler(t *testing.T) {
 	Expect(setResponse.Post).Equals(post)
 	Expect(setResponse.Status).Equals(enum.PostCompleted)
 	Expect(setResponse.Text).Equals("Done!")
+	Expect(addAuditLog.Action).Equals(enum.AuditActionPostStatusChanged)
+	Expect(addAuditLog.TargetID).Equals(post.ID)
+	Expect(addAuditLog.Before["status"]).Equals(enum.PostOpen)
+	Expect(addAuditLog.After["status"]).Equals(enum.PostCompleted)
+	Expect(addAuditLog.After["response"]).Equals("Done!")
 }
 
 func TestSetResponseHandler_Unauthorized(t *testing.T) {
@@ -264,6 +311,12 @@ func TestSetResponseHandler_Unauthorized(t *testing.T) {
 func TestSetResponseHandler_Duplicate(t *testing.T) {
 	RegisterT(t)
 
+	var addAuditLog *cmd.AddAuditLog
+	bus.AddHandler(func(ctx context.Context, c *cmd.AddAuditLog) error {
+		addAuditLog = c
+		return nil
+	})
+
 	var markAsDuplicate *cmd.MarkPostAsDuplicate
 	bus.AddHandler(func(ctx context.Context, c *cmd.MarkPostAsDuplicate) error {
 		markAsDuplicate = c
@@ -294,6 +347,7 @@ func TestSetResponseHandler_Duplicate(t *testing.T) {
 	Expect(code).Equals(http.StatusOK)
 	Expect(markAsDuplicate.Post).Equals(post1)
 	Expect(markAsDuplicate.Original).Equals(post2)
+	Expect(addAuditLog.After["originalNumber"]).Equals(post2.Number)
 }
 
 func TestSetResponseHandler_Duplicate_NotFound(t *testing.T) {
@@ -366,6 +420,77 @@ func TestAddVoteHandler(t *testing.T) {
 	Expect(addVote.User).Equals(mock.AryaStark)
 }
 
+func TestAddVoteHandler_BudgetedVoting(t *testing.T) {
+	RegisterT(t)
+
+	post := &models.Post{ID: 1, Number: 1, Title: "The Post #1", Description: "The Description #1"}
+	bus.AddHandler(func(ctx context.Context, q *query.GetPostByNumber) error {
+		q.Result = post
+		return nil
+	})
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetUsedVoteCredits) error {
+		q.Result = 4
+		return nil
+	})
+
+	var addVote *cmd.AddVote
+	bus.AddHandler(func(ctx context.Context, c *cmd.AddVote) error {
+		addVote = c
+		return nil
+	})
+
+	tenant := &models.Tenant{ID: 1, Name: "Demonstration", Subdomain: "demo", VotingMode: enum.VotingModeBudgeted, VoteBudget: 6}
+	code, _ := mock.NewServer().
+		OnTenant(tenant).
+		AsUser(mock.AryaStark).
+		AddParam("number", post.Number).
+		ExecutePost(apiv1.AddVote(), `{ "credits": 2 }`)
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(addVote.Post).Equals(post)
+	Expect(addVote.User).Equals(mock.AryaStark)
+	Expect(addVote.Credits).Equals(2)
+
+	addVote = nil
+	code, _ = mock.NewServer().
+		OnTenant(tenant).
+		AsUser(mock.AryaStark).
+		AddParam("number", post.Number).
+		ExecutePost(apiv1.AddVote(), `{ "credits": 3 }`)
+
+	Expect(code).Equals(http.StatusBadRequest)
+	Expect(addVote).IsNil()
+}
+
+func TestAddVoteHandler_BudgetExceededConcurrently(t *testing.T) {
+	RegisterT(t)
+
+	post := &models.Post{ID: 1, Number: 1, Title: "The Post #1", Description: "The Description #1"}
+	bus.AddHandler(func(ctx context.Context, q *query.GetPostByNumber) error {
+		q.Result = post
+		return nil
+	})
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetUsedVoteCredits) error {
+		q.Result = 0
+		return nil
+	})
+
+	bus.AddHandler(func(ctx context.Context, c *cmd.AddVote) error {
+		return app.ErrVoteBudgetExceeded
+	})
+
+	tenant := &models.Tenant{ID: 1, Name: "Demonstration", Subdomain: "demo", VotingMode: enum.VotingModeBudgeted, VoteBudget: 6}
+	code, _ := mock.NewServer().
+		OnTenant(tenant).
+		AsUser(mock.AryaStark).
+		AddParam("number", post.Number).
+		ExecutePost(apiv1.AddVote(), `{ "credits": 2 }`)
+
+	Expect(code).Equals(http.StatusBadRequest)
+}
+
 func TestAddVoteHandler_InvalidPost(t *testing.T) {
 	RegisterT(t)
 
@@ -411,6 +536,12 @@ func TestRemoveVoteHandler(t *testing.T) {
 func TestDeletePostHandler_Authorized(t *testing.T) {
 	RegisterT(t)
 
+	var addAuditLog *cmd.AddAuditLog
+	bus.AddHandler(func(ctx context.Context, c *cmd.AddAuditLog) error {
+		addAuditLog = c
+		return nil
+	})
+
 	post := &models.Post{ID: 1, Number: 1, Title: "The Post #1", Description: "The Description #1"}
 	bus.AddHandler(func(ctx context.Context, q *query.GetPostByNumber) error {
 		q.Result = post
@@ -438,6 +569,205 @@ func TestDeletePostHandler_Authorized(t *testing.T) {
 	Expect(deletePost.Post).Equals(post)
 	Expect(deletePost.Status).Equals(enum.PostDeleted)
 	Expect(deletePost.Text).Equals("")
+	Expect(addAuditLog.Action).Equals(enum.AuditActionPostDeleted)
+	Expect(addAuditLog.TargetName).Equals(post.Title)
+}
+
+func TestMergePostHandler(t *testing.T) {
+	RegisterT(t)
+
+	post1 := &models.Post{ID: 1, Number: 1, Title: "The Post #1", Status: enum.PostOpen}
+	post2 := &models.Post{ID: 2, Number: 2, Title: "The Post #2", Status: enum.PostOpen}
+	bus.AddHandler(func(ctx context.Context, q *query.GetPostByNumber) error {
+		if q.Number == post1.Number {
+			q.Result = post1
+			return nil
+		}
+		if q.Number == post2.Number {
+			q.Result = post2
+			return nil
+		}
+		return app.ErrNotFound
+	})
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetActivePostMerge) error {
+		return app.ErrNotFound
+	})
+
+	var addAuditLog *cmd.AddAuditLog
+	bus.AddHandler(func(ctx context.Context, c *cmd.AddAuditLog) error {
+		addAuditLog = c
+		return nil
+	})
+
+	var mergePost *cmd.MergePost
+	bus.AddHandler(func(ctx context.Context, c *cmd.MergePost) error {
+		mergePost = c
+		c.Result = c.Original
+		return nil
+	})
+
+	code, _ := mock.NewServer().
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		AddParam("number", post1.Number).
+		ExecutePost(apiv1.MergePost(), fmt.Sprintf(`{ "originalNumber": %d }`, post2.Number))
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(mergePost.Post).Equals(post1)
+	Expect(mergePost.Original).Equals(post2)
+	Expect(addAuditLog.Action).Equals(enum.AuditActionPostMerged)
+	Expect(addAuditLog.TargetID).Equals(post1.ID)
+	Expect(addAuditLog.After["originalNumber"]).Equals(post2.Number)
+}
+
+func TestMergePostHandler_Itself(t *testing.T) {
+	RegisterT(t)
+
+	post := &models.Post{ID: 1, Number: 1, Title: "The Post #1", Status: enum.PostOpen}
+	bus.AddHandler(func(ctx context.Context, q *query.GetPostByNumber) error {
+		q.Result = post
+		return nil
+	})
+
+	code, _ := mock.NewServer().
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		AddParam("number", post.Number).
+		ExecutePost(apiv1.MergePost(), fmt.Sprintf(`{ "originalNumber": %d }`, post.Number))
+
+	Expect(code).Equals(http.StatusBadRequest)
+}
+
+func TestMergePostHandler_AlreadyMerged(t *testing.T) {
+	RegisterT(t)
+
+	post1 := &models.Post{ID: 1, Number: 1, Title: "The Post #1", Status: enum.PostDuplicate}
+	post2 := &models.Post{ID: 2, Number: 2, Title: "The Post #2", Status: enum.PostOpen}
+	bus.AddHandler(func(ctx context.Context, q *query.GetPostByNumber) error {
+		if q.Number == post1.Number {
+			q.Result = post1
+		} else {
+			q.Result = post2
+		}
+		return nil
+	})
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetActivePostMerge) error {
+		q.Result = &models.PostMerge{ID: 1, PostID: post1.ID, OriginalID: 3, MergedAt: time.Now()}
+		return nil
+	})
+
+	code, _ := mock.NewServer().
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		AddParam("number", post1.Number).
+		ExecutePost(apiv1.MergePost(), fmt.Sprintf(`{ "originalNumber": %d }`, post2.Number))
+
+	Expect(code).Equals(http.StatusBadRequest)
+}
+
+func TestMergePostHandler_Unauthorized(t *testing.T) {
+	RegisterT(t)
+
+	code, _ := mock.NewServer().
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.AryaStark).
+		AddParam("number", 1).
+		ExecutePost(apiv1.MergePost(), `{ "originalNumber": 2 }`)
+
+	Expect(code).Equals(http.StatusForbidden)
+}
+
+func TestUnmergePostHandler(t *testing.T) {
+	RegisterT(t)
+
+	post := &models.Post{ID: 1, Number: 1, Title: "The Post #1", Status: enum.PostDuplicate}
+	bus.AddHandler(func(ctx context.Context, q *query.GetPostByNumber) error {
+		q.Result = post
+		return nil
+	})
+
+	merge := &models.PostMerge{ID: 4, PostID: post.ID, OriginalID: 2, MergedAt: time.Now().Add(-1 * time.Hour)}
+	bus.AddHandler(func(ctx context.Context, q *query.GetActivePostMerge) error {
+		if q.PostID == post.ID {
+			q.Result = merge
+			return nil
+		}
+		return app.ErrNotFound
+	})
+
+	var addAuditLog *cmd.AddAuditLog
+	bus.AddHandler(func(ctx context.Context, c *cmd.AddAuditLog) error {
+		addAuditLog = c
+		return nil
+	})
+
+	var unmergePost *cmd.UnmergePost
+	bus.AddHandler(func(ctx context.Context, c *cmd.UnmergePost) error {
+		unmergePost = c
+		c.Result = post
+		return nil
+	})
+
+	code, _ := mock.NewServer().
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		AddParam("number", post.Number).
+		ExecutePost(apiv1.UnmergePost(), `{ }`)
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(unmergePost.Merge).Equals(merge)
+	Expect(addAuditLog.Action).Equals(enum.AuditActionPostUnmerged)
+	Expect(addAuditLog.TargetID).Equals(post.ID)
+}
+
+func TestUnmergePostHandler_WindowExpired(t *testing.T) {
+	RegisterT(t)
+
+	post := &models.Post{ID: 1, Number: 1, Title: "The Post #1", Status: enum.PostDuplicate}
+	bus.AddHandler(func(ctx context.Context, q *query.GetPostByNumber) error {
+		q.Result = post
+		return nil
+	})
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetActivePostMerge) error {
+		if q.PostID == post.ID {
+			q.Result = &models.PostMerge{ID: 4, PostID: post.ID, OriginalID: 2, MergedAt: time.Now().Add(-30 * 24 * time.Hour)}
+			return nil
+		}
+		return app.ErrNotFound
+	})
+
+	code, _ := mock.NewServer().
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		AddParam("number", post.Number).
+		ExecutePost(apiv1.UnmergePost(), `{ }`)
+
+	Expect(code).Equals(http.StatusBadRequest)
+}
+
+func TestUnmergePostHandler_NotMerged(t *testing.T) {
+	RegisterT(t)
+
+	post := &models.Post{ID: 1, Number: 1, Title: "The Post #1", Status: enum.PostOpen}
+	bus.AddHandler(func(ctx context.Context, q *query.GetPostByNumber) error {
+		q.Result = post
+		return nil
+	})
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetActivePostMerge) error {
+		return app.ErrNotFound
+	})
+
+	code, _ := mock.NewServer().
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		AddParam("number", post.Number).
+		ExecutePost(apiv1.UnmergePost(), `{ }`)
+
+	Expect(code).Equals(http.StatusBadRequest)
 }
 
 func TestPostCommentHandler(t *testing.T) {
@@ -458,6 +788,8 @@ func TestPostCommentHandler(t *testing.T) {
 
 	bus.AddHandler(func(ctx context.Context, c *cmd.SetAttachments) error { return nil })
 	bus.AddHandler(func(ctx context.Context, c *cmd.UploadImages) error { return nil })
+	bus.AddHandler(func(ctx context.Context, c *cmd.SetFileAttachments) error { return nil })
+	bus.AddHandler(func(ctx context.Context, c *cmd.UploadFiles) error { return nil })
 
 	code, _ := mock.NewServer().
 		OnTenant(mock.DemoTenant).
@@ -508,6 +840,8 @@ func TestUpdateCommentHandler_Authorized(t *testing.T) {
 	bus.AddHandler(func(ctx context.Context, q *query.GetAttachments) error { return nil })
 	bus.AddHandler(func(ctx context.Context, c *cmd.SetAttachments) error { return nil })
 	bus.AddHandler(func(ctx context.Context, c *cmd.UploadImages) error { return nil })
+	bus.AddHandler(func(ctx context.Context, c *cmd.SetFileAttachments) error { return nil })
+	bus.AddHandler(func(ctx context.Context, c *cmd.UploadFiles) error { return nil })
 
 	var updateComment *cmd.UpdateComment
 	bus.AddHandler(func(ctx context.Context, c *cmd.UpdateComment) error {
@@ -580,3 +914,149 @@ func TestListCommentHandler(t *testing.T) {
 	Expect(query.IsArray()).IsTrue()
 	Expect(query.ArrayLength()).Equals(2)
 }
+
+func TestSearchPostsHandler_WithFilters(t *testing.T) {
+	RegisterT(t)
+
+	var searchPosts *query.SearchPosts
+	bus.AddHandler(func(ctx context.Context, q *query.SearchPosts) error {
+		searchPosts = q
+		q.Result = []*models.Post{&models.Post{ID: 1, Number: 1, Title: "My First Post"}}
+		q.NextCursor = "abc"
+		return nil
+	})
+
+	code, response := mock.NewServer().
+		OnTenant(mock.DemoTenant).
+		WithURL("http://demo.test.fider.io/api/v1/posts?status=open,planned&author=2&createdAfter=2019-01-01&tags=bug,ux&tagsMode=any&sort=votes&order=asc&limit=1").
+		Execute(apiv1.SearchPosts())
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(searchPosts.Statuses).Equals([]enum.PostStatus{enum.PostOpen, enum.PostPlanned})
+	Expect(searchPosts.AuthorID).Equals(2)
+	Expect(searchPosts.CreatedAfter).Equals(time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC))
+	Expect(searchPosts.Tags).Equals([]string{"bug", "ux"})
+	Expect(searchPosts.MatchAnyTag).IsTrue()
+	Expect(searchPosts.Sort).Equals("votes")
+	Expect(searchPosts.Order).Equals("asc")
+	Expect(searchPosts.Limit).Equals("1")
+	Expect(response.Header().Get("Link")).ContainsSubstring("cursor=abc")
+	Expect(response.Header().Get("Link")).ContainsSubstring(`rel="next"`)
+}
+
+func TestSearchPostsHandler_InvalidParams(t *testing.T) {
+	RegisterT(t)
+
+	code, response := mock.NewServer().
+		OnTenant(mock.DemoTenant).
+		WithURL("http://demo.test.fider.io/api/v1/posts?status=unknown&author=abc&updatedBefore=yesterday&sort=random&limit=-1").
+		Execute(apiv1.SearchPosts())
+
+	Expect(code).Equals(http.StatusBadRequest)
+	body := response.Body.String()
+	Expect(body).ContainsSubstring(`"field":"status"`)
+	Expect(body).ContainsSubstring(`"field":"author"`)
+	Expect(body).ContainsSubstring(`"field":"updatedBefore"`)
+	Expect(body).ContainsSubstring(`"field":"sort"`)
+	Expect(body).ContainsSubstring(`"field":"limit"`)
+}
+
+func TestSearchPostsHandler_DefaultSort(t *testing.T) {
+	RegisterT(t)
+
+	var searchPosts *query.SearchPosts
+	bus.AddHandler(func(ctx context.Context, q *query.SearchPosts) error {
+		searchPosts = q
+		q.Result = []*models.Post{}
+		return nil
+	})
+
+	code, _ := mock.NewServer().
+		OnTenant(mock.DemoTenant).
+		WithURL("http://demo.test.fider.io/api/v1/posts?limit=10").
+		Execute(apiv1.SearchPosts())
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(searchPosts.Sort).Equals("id")
+
+	code, _ = mock.NewServer().
+		OnTenant(mock.DemoTenant).
+		WithURL("http://demo.test.fider.io/api/v1/posts?view=trending").
+		Execute(apiv1.SearchPosts())
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(searchPosts.Sort).Equals("")
+	Expect(searchPosts.View).Equals("trending")
+}
+
+func TestSearchPostsHandler_LimitAll(t *testing.T) {
+	RegisterT(t)
+
+	code, response := mock.NewServer().
+		OnTenant(mock.DemoTenant).
+		WithURL("http://demo.test.fider.io/api/v1/posts?limit=all").
+		Execute(apiv1.SearchPosts())
+
+	Expect(code).Equals(http.StatusBadRequest)
+	Expect(response.Body.String()).ContainsSubstring(`"field":"limit"`)
+}
+
+func TestSearchPostsHandler_DeletedStatus(t *testing.T) {
+	RegisterT(t)
+
+	code, response := mock.NewServer().
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		WithURL("http://demo.test.fider.io/api/v1/posts?status=open,deleted").
+		Execute(apiv1.SearchPosts())
+
+	Expect(code).Equals(http.StatusBadRequest)
+	Expect(response.Body.String()).ContainsSubstring(`'deleted' is not a valid status.`)
+}
+
+func TestSearchPostsHandler_InvalidCursor(t *testing.T) {
+	RegisterT(t)
+
+	bus.AddHandler(func(ctx context.Context, q *query.SearchPosts) error {
+		return cursor.ErrInvalid
+	})
+
+	code, response := mock.NewServer().
+		OnTenant(mock.DemoTenant).
+		WithURL("http://demo.test.fider.io/api/v1/posts?cursor=abc").
+		Execute(apiv1.SearchPosts())
+
+	Expect(code).Equals(http.StatusBadRequest)
+	Expect(response.Body.String()).ContainsSubstring(`"field":"cursor"`)
+}
+
+func TestListCommentHandler_WithCursor(t *testing.T) {
+	RegisterT(t)
+
+	post := &models.Post{ID: 1, Number: 1, Title: "The Post #1", Description: "The Description #1"}
+	bus.AddHandler(func(ctx context.Context, q *query.GetPostByNumber) error {
+		q.Result = post
+		return nil
+	})
+
+	var getComments *query.GetCommentsByPost
+	bus.AddHandler(func(ctx context.Context, q *query.GetCommentsByPost) error {
+		getComments = q
+		q.Result = []*models.Comment{&models.Comment{ID: 3, Content: "Third Comment"}}
+		q.NextCursor = "xyz"
+		return nil
+	})
+
+	code, response := mock.NewServer().
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		AddParam("number", post.Number).
+		WithURL("http://demo.test.fider.io/api/v1/posts/1/comments?limit=1&cursor=abc&order=desc").
+		Execute(apiv1.ListComments())
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(getComments.Limit).Equals(1)
+	Expect(getComments.Cursor).Equals("abc")
+	Expect(getComments.Order).Equals("desc")
+	Expect(response.Header().Get("Link")).ContainsSubstring("cursor=xyz")
+}
@@ -0,0 +1,29 @@
+package apiv1
+
+import (
+	"github.com/getfider/fider/app/models/query"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/validate"
+	"github.com/getfider/fider/app/pkg/web"
+)
+
+// GetRoadmap returns planned, started and completed posts grouped by status
+func GetRoadmap() web.HandlerFunc {
+	return func(c *web.Context) error {
+		result := validate.Success()
+		getRoadmap := &query.GetRoadmap{
+			Tags:        c.QueryParamAsArray("tags"),
+			MatchAnyTag: parseOptionParam(c, "tagsMode", []string{"all", "any"}, result) == "any",
+		}
+
+		if !result.Ok {
+			return c.HandleValidation(result)
+		}
+
+		if err := bus.Dispatch(c, getRoadmap); err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(getRoadmap.Result)
+	}
+}
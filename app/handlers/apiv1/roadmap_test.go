@@ -0,0 +1,59 @@
+package apiv1_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/getfider/fider/app/handlers/apiv1"
+	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/models/query"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/mock"
+)
+
+func TestGetRoadmapHandler(t *testing.T) {
+	RegisterT(t)
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetRoadmap) error {
+		q.Result = []*models.RoadmapColumn{
+			{
+				Status: enum.PostPlanned,
+				Posts: []*models.RoadmapPost{
+					{
+						Post: &models.Post{ID: 1, Number: 1, Title: "Dark mode", Status: enum.PostPlanned},
+						History: []*models.PostStatusChange{
+							{Status: enum.PostOpen, ChangedAt: time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC)},
+							{Status: enum.PostPlanned, ChangedAt: time.Date(2019, 6, 20, 0, 0, 0, 0, time.UTC)},
+						},
+					},
+				},
+			},
+		}
+		return nil
+	})
+
+	code, response := mock.NewServer().
+		OnTenant(mock.DemoTenant).
+		Execute(apiv1.GetRoadmap())
+
+	Expect(code).Equals(http.StatusOK)
+	body := response.Body.String()
+	Expect(body).ContainsSubstring(`"status":"planned"`)
+	Expect(body).ContainsSubstring(`"title":"Dark mode"`)
+	Expect(body).ContainsSubstring(`"changedAt":"2019-06-20T00:00:00Z"`)
+}
+
+func TestGetRoadmapHandler_InvalidTagsMode(t *testing.T) {
+	RegisterT(t)
+
+	code, _ := mock.NewServer().
+		OnTenant(mock.DemoTenant).
+		WithURL("http://demo.test.fider.io/api/v1/roadmap?tagsMode=some").
+		Execute(apiv1.GetRoadmap())
+
+	Expect(code).Equals(http.StatusBadRequest)
+}
@@ -3,6 +3,8 @@ package apiv1
 import (
 	"github.com/getfider/fider/app/actions"
 	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/dto"
+	"github.com/getfider/fider/app/models/enum"
 	"github.com/getfider/fider/app/models/query"
 	"github.com/getfider/fider/app/pkg/bus"
 	"github.com/getfider/fider/app/pkg/web"
@@ -69,7 +71,15 @@ func CreateEditTag() web.HandlerFunc {
 				Color:    input.Model.Color,
 				IsPublic: input.Model.IsPublic,
 			}
-			if err := bus.Dispatch(c, updateTag); err != nil {
+			if err := bus.Dispatch(c, updateTag, &cmd.AddAuditLog{
+				Action:     enum.AuditActionTagUpdated,
+				TargetType: "tag",
+				TargetID:   input.Tag.ID,
+				TargetName: input.Model.Name,
+				Before:     tagProps(input.Tag.Name, input.Tag.Color, input.Tag.IsPublic),
+				After:      tagProps(input.Model.Name, input.Model.Color, input.Model.IsPublic),
+				ClientIP:   c.Request.ClientIP,
+			}); err != nil {
 				return c.Failure(err)
 			}
 			return c.Ok(updateTag.Result)
@@ -83,6 +93,17 @@ func CreateEditTag() web.HandlerFunc {
 		if err := bus.Dispatch(c, addNewTag); err != nil {
 			return c.Failure(err)
 		}
+
+		if err := bus.Dispatch(c, &cmd.AddAuditLog{
+			Action:     enum.AuditActionTagCreated,
+			TargetType: "tag",
+			TargetID:   addNewTag.Result.ID,
+			TargetName: addNewTag.Result.Name,
+			After:      tagProps(addNewTag.Result.Name, addNewTag.Result.Color, addNewTag.Result.IsPublic),
+			ClientIP:   c.Request.ClientIP,
+		}); err != nil {
+			return c.Failure(err)
+		}
 		return c.Ok(addNewTag.Result)
 	}
 }
@@ -95,11 +116,22 @@ func DeleteTag() web.HandlerFunc {
 			return c.HandleValidation(result)
 		}
 
-		err := bus.Dispatch(c, &cmd.DeleteTag{Tag: input.Tag})
-		if err != nil {
+		if err := bus.Dispatch(c, &cmd.DeleteTag{Tag: input.Tag}, &cmd.AddAuditLog{
+			Action:     enum.AuditActionTagDeleted,
+			TargetType: "tag",
+			TargetID:   input.Tag.ID,
+			TargetName: input.Tag.Name,
+			Before:     tagProps(input.Tag.Name, input.Tag.Color, input.Tag.IsPublic),
+			ClientIP:   c.Request.ClientIP,
+		}); err != nil {
 			return c.Failure(err)
 		}
 
 		return c.Ok(web.Map{})
 	}
 }
+
+//tagProps returns the values of a tag as they are recorded on the audit log
+func tagProps(name, color string, isPublic bool) dto.Props {
+	return dto.Props{"name": name, "color": color, "isPublic": isPublic}
+}
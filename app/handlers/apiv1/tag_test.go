@@ -9,6 +9,7 @@ import (
 	"github.com/getfider/fider/app/handlers/apiv1"
 	"github.com/getfider/fider/app/models"
 	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/enum"
 	"github.com/getfider/fider/app/models/query"
 	. "github.com/getfider/fider/app/pkg/assert"
 	"github.com/getfider/fider/app/pkg/bus"
@@ -19,6 +20,12 @@ import (
 func TestCreateTagHandler_ValidRequests(t *testing.T) {
 	RegisterT(t)
 
+	var addAuditLog *cmd.AddAuditLog
+	bus.AddHandler(func(ctx context.Context, c *cmd.AddAuditLog) error {
+		addAuditLog = c
+		return nil
+	})
+
 	bus.AddHandler(func(ctx context.Context, q *query.GetTagBySlug) error {
 		return app.ErrNotFound
 	})
@@ -26,6 +33,7 @@ func TestCreateTagHandler_ValidRequests(t *testing.T) {
 	var addNewTag *cmd.AddNewTag
 	bus.AddHandler(func(ctx context.Context, c *cmd.AddNewTag) error {
 		addNewTag = c
+		c.Result = &models.Tag{ID: 8, Name: c.Name, Slug: "feature-request", Color: c.Color, IsPublic: c.IsPublic}
 		return nil
 	})
 
@@ -42,6 +50,9 @@ func TestCreateTagHandler_ValidRequests(t *testing.T) {
 	Expect(addNewTag.Name).Equals("Feature Request")
 	Expect(addNewTag.Color).Equals("00FF00")
 	Expect(addNewTag.IsPublic).IsTrue()
+	Expect(addAuditLog.Action).Equals(enum.AuditActionTagCreated)
+	Expect(addAuditLog.TargetID).Equals(8)
+	Expect(addAuditLog.After["name"]).Equals("Feature Request")
 }
 
 func TestCreateTagHandler_InvalidRequests(t *testing.T) {
@@ -127,6 +138,12 @@ func TestEditInvalidTagHandler(t *testing.T) {
 func TestEditExistingTagHandler(t *testing.T) {
 	RegisterT(t)
 
+	var addAuditLog *cmd.AddAuditLog
+	bus.AddHandler(func(ctx context.Context, c *cmd.AddAuditLog) error {
+		addAuditLog = c
+		return nil
+	})
+
 	tag := &models.Tag{ID: 5, Name: "Bug", Slug: "bug", Color: "0000FF", IsPublic: true}
 	bus.AddHandler(func(ctx context.Context, q *query.GetTagBySlug) error {
 		q.Result = tag
@@ -154,6 +171,9 @@ func TestEditExistingTagHandler(t *testing.T) {
 	Expect(updateTag.Name).Equals("Feature Request")
 	Expect(updateTag.Color).Equals("000000")
 	Expect(updateTag.IsPublic).IsTrue()
+	Expect(addAuditLog.Action).Equals(enum.AuditActionTagUpdated)
+	Expect(addAuditLog.Before["name"]).Equals("Bug")
+	Expect(addAuditLog.After["name"]).Equals("Feature Request")
 }
 
 func TestDeleteInvalidTagHandler(t *testing.T) {
@@ -174,6 +194,12 @@ func TestDeleteInvalidTagHandler(t *testing.T) {
 func TestDeleteExistingTagHandler(t *testing.T) {
 	RegisterT(t)
 
+	var addAuditLog *cmd.AddAuditLog
+	bus.AddHandler(func(ctx context.Context, c *cmd.AddAuditLog) error {
+		addAuditLog = c
+		return nil
+	})
+
 	tag := &models.Tag{ID: 5, Name: "Bug", Slug: "bug", Color: "0000FF", IsPublic: true}
 	bus.AddHandler(func(ctx context.Context, q *query.GetTagBySlug) error {
 		q.Result = tag
@@ -193,6 +219,9 @@ func TestDeleteExistingTagHandler(t *testing.T) {
 
 	Expect(status).Equals(http.StatusOK)
 	Expect(deleteTag.Tag).Equals(tag)
+	Expect(addAuditLog.Action).Equals(enum.AuditActionTagDeleted)
+	Expect(addAuditLog.TargetID).Equals(5)
+	Expect(addAuditLog.After).IsNil()
 }
 
 func TestDeleteExistingTagHandler_Collaborator(t *testing.T) {
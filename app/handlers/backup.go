@@ -1,7 +1,13 @@
 package handlers
 
 import (
+	"github.com/getfider/fider/app/actions"
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/dto"
+	"github.com/getfider/fider/app/models/enum"
 	"github.com/getfider/fider/app/pkg/backup"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/csv"
 	"github.com/getfider/fider/app/pkg/web"
 )
 
@@ -17,3 +23,56 @@ func ExportBackupZip() web.HandlerFunc {
 		return c.Attachment("backup.zip", "application/zip", file.Bytes())
 	}
 }
+
+// ImportData restores a backup.zip or imports a CSV file into current tenant
+func ImportData() web.HandlerFunc {
+	return func(c *web.Context) error {
+		input := new(actions.ImportData)
+		if result := c.BindTo(input); !result.Ok {
+			return c.HandleValidation(result)
+		}
+
+		var (
+			report *dto.ImportReport
+			err    error
+		)
+
+		action := enum.AuditActionDataImported
+		if input.Model.Kind == "backup" {
+			action = enum.AuditActionBackupRestored
+			report, err = backup.Restore(c, input.Model.Content, input.Model.DryRun)
+		} else {
+			report, err = csv.Import(c, input.Model.Kind, input.Model.Content, input.Model.DryRun)
+		}
+
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		if !report.DryRun && !report.HasErrors() {
+			tenant := c.Tenant()
+			if err := bus.Dispatch(c, &cmd.AddAuditLog{
+				Action:     action,
+				TargetType: "tenant",
+				TargetID:   tenant.ID,
+				TargetName: tenant.Name,
+				After:      dto.Props{"kind": input.Model.Kind, "imported": report.Imported},
+				ClientIP:   c.Request.ClientIP,
+			}); err != nil {
+				return c.Failure(err)
+			}
+
+			//Blobs are stored once the restored records are committed, so that a failed restore leaves none behind
+			if input.Model.Kind == "backup" {
+				if err := c.Commit(); err != nil {
+					return c.Failure(err)
+				}
+				if err := backup.RestoreBlobs(c, input.Model.Content); err != nil {
+					return c.Failure(err)
+				}
+			}
+		}
+
+		return c.Ok(report)
+	}
+}
@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	"bytes"
+	"crypto/subtle"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -20,6 +22,7 @@ import (
 	"github.com/getfider/fider/app/pkg/env"
 	"github.com/getfider/fider/app/pkg/errors"
 	"github.com/getfider/fider/app/pkg/log"
+	"github.com/getfider/fider/app/pkg/metrics"
 	"github.com/getfider/fider/app/pkg/web"
 )
 
@@ -34,6 +37,28 @@ func Health() web.HandlerFunc {
 	}
 }
 
+//Metrics returns server, worker and database metrics in Prometheus text format
+//It's only available when METRICS_TOKEN is set, which must be sent as a Bearer token
+func Metrics() web.HandlerFunc {
+	return func(c *web.Context) error {
+		token := env.Config.Metrics.Token
+		if token == "" {
+			return c.NotFound()
+		}
+
+		authorization := c.Request.GetHeader("Authorization")
+		if subtle.ConstantTimeCompare([]byte(authorization), []byte("Bearer "+token)) != 1 {
+			return c.Unauthorized()
+		}
+
+		buffer := new(bytes.Buffer)
+		if err := metrics.WriteText(buffer); err != nil {
+			return c.Failure(err)
+		}
+		return c.Blob(http.StatusOK, "text/plain; version=0.0.4; charset=utf-8", buffer.Bytes())
+	}
+}
+
 //LegalPage returns a legal page with content from a file
 func LegalPage(title, file string) web.HandlerFunc {
 	return func(c *web.Context) error {
@@ -9,6 +9,7 @@ import (
 	"github.com/getfider/fider/app/models"
 	"github.com/getfider/fider/app/models/query"
 	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/env"
 
 	"github.com/getfider/fider/app/handlers"
 	. "github.com/getfider/fider/app/pkg/assert"
@@ -25,6 +26,32 @@ func TestHealthHandler(t *testing.T) {
 	Expect(code).Equals(http.StatusOK)
 }
 
+func TestMetricsHandler(t *testing.T) {
+	RegisterT(t)
+
+	code, _ := mock.NewServer().Execute(handlers.Metrics())
+	Expect(code).Equals(http.StatusNotFound)
+
+	env.Config.Metrics.Token = "my-metrics-token"
+
+	code, _ = mock.NewServer().
+		AddHeader("Authorization", "Bearer wrong-token").
+		Execute(handlers.Metrics())
+	Expect(code).Equals(http.StatusForbidden)
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetTenantByDomain) error {
+		return nil
+	})
+	bus.MustDispatch(context.Background(), &query.GetTenantByDomain{})
+
+	code, response := mock.NewServer().
+		AddHeader("Authorization", "Bearer my-metrics-token").
+		Execute(handlers.Metrics())
+	Expect(code).Equals(http.StatusOK)
+	Expect(response.Header().Get("Content-Type")).ContainsSubstring("text/plain")
+	Expect(response.Body.String()).ContainsSubstring(`fider_bus_dispatch_duration_seconds_count{message="query.GetTenantByDomain"}`)
+}
+
 func TestPageHandler(t *testing.T) {
 	RegisterT(t)
 
@@ -0,0 +1,326 @@
+package handlers
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/env"
+	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/getfider/fider/app/pkg/web"
+	"github.com/getfider/fider/app/services/email"
+)
+
+// ReceiveMailgunEvent handles delivery, bounce and complaint events sent by Mailgun webhooks
+func ReceiveMailgunEvent() web.HandlerFunc {
+	return func(c *web.Context) error {
+		payload := struct {
+			Signature struct {
+				Timestamp string `json:"timestamp"`
+				Token     string `json:"token"`
+				Signature string `json:"signature"`
+			} `json:"signature"`
+			Event struct {
+				Event     string `json:"event"`
+				Severity  string `json:"severity"`
+				Recipient string `json:"recipient"`
+				Reason    string `json:"reason"`
+				Message   struct {
+					Headers struct {
+						MessageID string `json:"message-id"`
+					} `json:"headers"`
+				} `json:"message"`
+				DeliveryStatus struct {
+					Description string `json:"description"`
+					Message     string `json:"message"`
+				} `json:"delivery-status"`
+			} `json:"event-data"`
+		}{}
+
+		if err := json.Unmarshal([]byte(c.Request.Body), &payload); err != nil {
+			return c.BadRequest(web.Map{})
+		}
+
+		if !isValidMailgunSignature(payload.Signature.Timestamp, payload.Signature.Token, payload.Signature.Signature) {
+			return c.Unauthorized()
+		}
+
+		event := payload.Event
+		var status enum.EmailDeliveryStatus
+		switch event.Event {
+		case "delivered":
+			status = enum.EmailDeliveryStatusDelivered
+		case "complained":
+			status = enum.EmailDeliveryStatusComplained
+		case "failed":
+			status = enum.EmailDeliveryStatusFailed
+			if event.Severity == "permanent" {
+				status = enum.EmailDeliveryStatusBounced
+			}
+		default:
+			return c.Ok(web.Map{})
+		}
+
+		reason := event.DeliveryStatus.Description
+		if reason == "" {
+			reason = event.DeliveryStatus.Message
+		}
+		if reason == "" {
+			reason = event.Reason
+		}
+
+		err := bus.Dispatch(c, &cmd.SetEmailDeliveryStatus{
+			MessageID: email.NormalizeMessageID(event.Message.Headers.MessageID),
+			Address:   event.Recipient,
+			Status:    status,
+			Reason:    reason,
+		})
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(web.Map{})
+	}
+}
+
+// ReceiveSESNotification handles delivery, bounce and complaint notifications published by SES to an SNS topic
+// SNS subscriptions to the configured topic are confirmed automatically
+func ReceiveSESNotification() web.HandlerFunc {
+	return func(c *web.Context) error {
+		topicARN := env.Config.Email.SES.TopicARN
+		if topicARN == "" {
+			return c.NotFound()
+		}
+
+		message := &snsMessage{}
+		if err := json.Unmarshal([]byte(c.Request.Body), message); err != nil {
+			return c.BadRequest(web.Map{})
+		}
+
+		if message.TopicArn != topicARN {
+			return c.Unauthorized()
+		}
+
+		if err := verifySNSMessage(c, message); err != nil {
+			return c.Unauthorized()
+		}
+
+		switch message.Type {
+		case "SubscriptionConfirmation":
+			if !isAWSURL(message.SubscribeURL) {
+				return c.BadRequest(web.Map{})
+			}
+			if err := bus.Dispatch(c, &cmd.HTTPRequest{Method: "GET", URL: message.SubscribeURL}); err != nil {
+				return c.Failure(err)
+			}
+		case "Notification":
+			for _, status := range parseSESNotification(message.Message) {
+				if err := bus.Dispatch(c, status); err != nil {
+					return c.Failure(err)
+				}
+			}
+		}
+
+		return c.Ok(web.Map{})
+	}
+}
+
+//parseSESNotification returns the delivery status of each recipient of an SES notification
+//Both notifications of SES identities (notificationType) and events of configuration sets (eventType) are supported
+func parseSESNotification(content string) []*cmd.SetEmailDeliveryStatus {
+	notification := struct {
+		NotificationType string `json:"notificationType"`
+		EventType        string `json:"eventType"`
+		Mail             struct {
+			MessageID string `json:"messageId"`
+		} `json:"mail"`
+		Bounce struct {
+			BounceType        string `json:"bounceType"`
+			BounceSubType     string `json:"bounceSubType"`
+			BouncedRecipients []struct {
+				EmailAddress   string `json:"emailAddress"`
+				DiagnosticCode string `json:"diagnosticCode"`
+			} `json:"bouncedRecipients"`
+		} `json:"bounce"`
+		Complaint struct {
+			ComplaintFeedbackType string `json:"complaintFeedbackType"`
+			ComplainedRecipients  []struct {
+				EmailAddress string `json:"emailAddress"`
+			} `json:"complainedRecipients"`
+		} `json:"complaint"`
+		Delivery struct {
+			Recipients []string `json:"recipients"`
+		} `json:"delivery"`
+	}{}
+
+	if err := json.Unmarshal([]byte(content), &notification); err != nil {
+		return nil
+	}
+
+	kind := notification.NotificationType
+	if kind == "" {
+		kind = notification.EventType
+	}
+
+	messageID := email.NormalizeMessageID(notification.Mail.MessageID)
+	result := make([]*cmd.SetEmailDeliveryStatus, 0)
+	add := func(address string, status enum.EmailDeliveryStatus, reason string) {
+		if parsed, err := mail.ParseAddress(address); err == nil {
+			address = parsed.Address
+		}
+		result = append(result, &cmd.SetEmailDeliveryStatus{
+			MessageID: messageID,
+			Address:   address,
+			Status:    status,
+			Reason:    reason,
+		})
+	}
+
+	switch kind {
+	case "Delivery":
+		for _, address := range notification.Delivery.Recipients {
+			add(address, enum.EmailDeliveryStatusDelivered, "")
+		}
+	case "Bounce":
+		//Transient and undetermined bounces might succeed later, so only permanent ones stop future emails
+		status := enum.EmailDeliveryStatusFailed
+		if notification.Bounce.BounceType == "Permanent" {
+			status = enum.EmailDeliveryStatusBounced
+		}
+		for _, r := range notification.Bounce.BouncedRecipients {
+			reason := r.DiagnosticCode
+			if reason == "" {
+				reason = fmt.Sprintf("%s bounce (%s)", notification.Bounce.BounceType, notification.Bounce.BounceSubType)
+			}
+			add(r.EmailAddress, status, reason)
+		}
+	case "Complaint":
+		for _, r := range notification.Complaint.ComplainedRecipients {
+			add(r.EmailAddress, enum.EmailDeliveryStatusComplained, notification.Complaint.ComplaintFeedbackType)
+		}
+	}
+
+	return result
+}
+
+//snsMessage is an HTTP(S) notification sent by Amazon SNS
+//https://docs.aws.amazon.com/sns/latest/dg/sns-message-and-json-formats.html
+type snsMessage struct {
+	Type             string `json:"Type"`
+	MessageID        string `json:"MessageId"`
+	Token            string `json:"Token"`
+	TopicArn         string `json:"TopicArn"`
+	Subject          string `json:"Subject"`
+	Message          string `json:"Message"`
+	SubscribeURL     string `json:"SubscribeURL"`
+	Timestamp        string `json:"Timestamp"`
+	SignatureVersion string `json:"SignatureVersion"`
+	Signature        string `json:"Signature"`
+	SigningCertURL   string `json:"SigningCertURL"`
+}
+
+//stringToSign returns the content that is signed by SNS, which depends on the type of message
+func (m *snsMessage) stringToSign() string {
+	fields := [][]string{
+		{"Message", m.Message},
+		{"MessageId", m.MessageID},
+	}
+	if m.Type == "Notification" {
+		if m.Subject != "" {
+			fields = append(fields, []string{"Subject", m.Subject})
+		}
+	} else {
+		fields = append(fields, []string{"SubscribeURL", m.SubscribeURL})
+	}
+	fields = append(fields, []string{"Timestamp", m.Timestamp})
+	if m.Type != "Notification" {
+		fields = append(fields, []string{"Token", m.Token})
+	}
+	fields = append(fields, []string{"TopicArn", m.TopicArn}, []string{"Type", m.Type})
+
+	var b strings.Builder
+	for _, field := range fields {
+		b.WriteString(field[0] + "\n" + field[1] + "\n")
+	}
+	return b.String()
+}
+
+var awsHostRegex = regexp.MustCompile(`^sns\.[a-z0-9\-]+\.amazonaws\.com(\.cn)?$`)
+
+//isAWSURL returns true if given URL is an HTTPS endpoint of SNS
+func isAWSURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	return err == nil && u.Scheme == "https" && awsHostRegex.MatchString(u.Host)
+}
+
+var (
+	snsCertsMutex sync.RWMutex
+	snsCerts      = make(map[string]*x509.Certificate)
+)
+
+//getSNSCertificate downloads the certificate used by SNS to sign messages, which is then cached by its URL
+func getSNSCertificate(c *web.Context, certURL string) (*x509.Certificate, error) {
+	snsCertsMutex.RLock()
+	cert, ok := snsCerts[certURL]
+	snsCertsMutex.RUnlock()
+	if ok {
+		return cert, nil
+	}
+
+	req := &cmd.HTTPRequest{Method: "GET", URL: certURL}
+	if err := bus.Dispatch(c, req); err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(req.ResponseBody)
+	if block == nil {
+		return nil, errors.New("failed to decode SNS certificate")
+	}
+
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse SNS certificate")
+	}
+
+	snsCertsMutex.Lock()
+	snsCerts[certURL] = cert
+	snsCertsMutex.Unlock()
+	return cert, nil
+}
+
+//verifySNSMessage returns an error if given message was not signed by SNS
+//https://docs.aws.amazon.com/sns/latest/dg/sns-verify-signature-of-message.html
+func verifySNSMessage(c *web.Context, m *snsMessage) error {
+	if !isAWSURL(m.SigningCertURL) {
+		return errors.New("invalid SNS certificate URL '%s'", m.SigningCertURL)
+	}
+
+	algorithm := x509.SHA1WithRSA
+	if m.SignatureVersion == "2" {
+		algorithm = x509.SHA256WithRSA
+	} else if m.SignatureVersion != "1" {
+		return errors.New("unknown SNS signature version '%s'", m.SignatureVersion)
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(m.Signature)
+	if err != nil {
+		return errors.Wrap(err, "failed to decode SNS signature")
+	}
+
+	cert, err := getSNSCertificate(c, m.SigningCertURL)
+	if err != nil {
+		return err
+	}
+
+	return cert.CheckSignature(algorithm, []byte(m.stringToSign()), signature)
+}
@@ -0,0 +1,290 @@
+package handlers_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/getfider/fider/app/handlers"
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/enum"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/bus"
+	fidercrypto "github.com/getfider/fider/app/pkg/crypto"
+	"github.com/getfider/fider/app/pkg/env"
+	"github.com/getfider/fider/app/pkg/mock"
+)
+
+func mailgunEvent(key, event, severity string) string {
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	token := "a8ce0edb2dd8301dee6c2405235584e45aa91d1e9f979f3de0"
+	payload, _ := json.Marshal(map[string]interface{}{
+		"signature": map[string]string{
+			"timestamp": ts,
+			"token":     token,
+			"signature": fidercrypto.HMACSHA256(key, []byte(ts+token)),
+		},
+		"event-data": map[string]interface{}{
+			"event":     event,
+			"severity":  severity,
+			"recipient": "jon.snow@got.com",
+			"message": map[string]interface{}{
+				"headers": map[string]string{"message-id": "20190727.1234@mydomain.com"},
+			},
+			"delivery-status": map[string]string{
+				"description": "No such mailbox",
+			},
+		},
+	})
+	return string(payload)
+}
+
+func captureDeliveryStatus() *[]*cmd.SetEmailDeliveryStatus {
+	statuses := make([]*cmd.SetEmailDeliveryStatus, 0)
+	bus.AddHandler(func(ctx context.Context, c *cmd.SetEmailDeliveryStatus) error {
+		statuses = append(statuses, c)
+		return nil
+	})
+	return &statuses
+}
+
+func TestReceiveMailgunEventHandler_Bounce(t *testing.T) {
+	RegisterT(t)
+	env.Config.Email.Mailgun.WebhookSigningKey = "my-signing-key"
+	statuses := captureDeliveryStatus()
+
+	code, _ := mock.NewServer().ExecutePost(handlers.ReceiveMailgunEvent(), mailgunEvent("my-signing-key", "failed", "permanent"))
+	Expect(code).Equals(http.StatusOK)
+	Expect(*statuses).HasLen(1)
+	Expect((*statuses)[0]).Equals(&cmd.SetEmailDeliveryStatus{
+		MessageID: "20190727.1234@mydomain.com",
+		Address:   "jon.snow@got.com",
+		Status:    enum.EmailDeliveryStatusBounced,
+		Reason:    "No such mailbox",
+	})
+}
+
+func TestReceiveMailgunEventHandler_TemporaryFailure(t *testing.T) {
+	RegisterT(t)
+	env.Config.Email.Mailgun.WebhookSigningKey = "my-signing-key"
+	statuses := captureDeliveryStatus()
+
+	code, _ := mock.NewServer().ExecutePost(handlers.ReceiveMailgunEvent(), mailgunEvent("my-signing-key", "failed", "temporary"))
+	Expect(code).Equals(http.StatusOK)
+	Expect(*statuses).HasLen(1)
+	Expect((*statuses)[0].Status).Equals(enum.EmailDeliveryStatusFailed)
+}
+
+func TestReceiveMailgunEventHandler_IgnoredEvent(t *testing.T) {
+	RegisterT(t)
+	env.Config.Email.Mailgun.WebhookSigningKey = "my-signing-key"
+	statuses := captureDeliveryStatus()
+
+	code, _ := mock.NewServer().ExecutePost(handlers.ReceiveMailgunEvent(), mailgunEvent("my-signing-key", "opened", ""))
+	Expect(code).Equals(http.StatusOK)
+	Expect(*statuses).HasLen(0)
+}
+
+func TestReceiveMailgunEventHandler_InvalidSignature(t *testing.T) {
+	RegisterT(t)
+	env.Config.Email.Mailgun.WebhookSigningKey = "my-signing-key"
+	statuses := captureDeliveryStatus()
+
+	code, _ := mock.NewServer().ExecutePost(handlers.ReceiveMailgunEvent(), mailgunEvent("wrong-key", "complained", ""))
+	Expect(code).Equals(http.StatusForbidden)
+	Expect(*statuses).HasLen(0)
+}
+
+const snsTopicARN = "arn:aws:sns:us-east-1:123456789012:fider-events"
+
+var snsCertURL string
+
+//mockSNS creates a certificate that is served on snsCertURL and returns its key to sign messages
+//Each test uses its own URL because certificates are cached by it
+func mockSNS() (*rsa.PrivateKey, *[]*cmd.HTTPRequest) {
+	snsCertURL = fmt.Sprintf("https://sns.us-east-1.amazonaws.com/SimpleNotificationService-%d.pem", time.Now().UnixNano())
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	Expect(err).IsNil()
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "sns.amazonaws.com"},
+		NotBefore:    time.Now().Add(-1 * time.Hour),
+		NotAfter:     time.Now().Add(1 * time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	Expect(err).IsNil()
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+
+	requests := make([]*cmd.HTTPRequest, 0)
+	bus.AddHandler(func(ctx context.Context, c *cmd.HTTPRequest) error {
+		requests = append(requests, c)
+		if c.URL == snsCertURL {
+			c.ResponseBody = certPEM
+		}
+		c.ResponseStatusCode = http.StatusOK
+		return nil
+	})
+	return key, &requests
+}
+
+func signedSNSMessage(key *rsa.PrivateKey, fields map[string]string) string {
+	fields["TopicArn"] = snsTopicARN
+	fields["MessageId"] = "22b80b92-fdea-4c2c-8f9d-bdfb0c7bf324"
+	fields["Timestamp"] = "2019-07-27T20:30:00.000Z"
+	fields["SignatureVersion"] = "2"
+	fields["SigningCertURL"] = snsCertURL
+
+	keys := []string{"Message", "MessageId", "Subject", "SubscribeURL", "Timestamp", "Token", "TopicArn", "Type"}
+	if fields["Type"] == "Notification" {
+		keys = []string{"Message", "MessageId", "Subject", "Timestamp", "TopicArn", "Type"}
+	}
+	content := ""
+	for _, k := range keys {
+		if v, ok := fields[k]; ok {
+			content += k + "\n" + v + "\n"
+		}
+	}
+
+	hash := sha256.Sum256([]byte(content))
+	signature, _ := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, hash[:])
+	fields["Signature"] = base64.StdEncoding.EncodeToString(signature)
+
+	payload, _ := json.Marshal(fields)
+	return string(payload)
+}
+
+func TestReceiveSESNotificationHandler_Bounce(t *testing.T) {
+	RegisterT(t)
+	env.Config.Email.SES.TopicARN = snsTopicARN
+	key, _ := mockSNS()
+	statuses := captureDeliveryStatus()
+
+	body := signedSNSMessage(key, map[string]string{
+		"Type": "Notification",
+		"Message": `{
+			"notificationType": "Bounce",
+			"mail": { "messageId": "0100016c-message-id" },
+			"bounce": {
+				"bounceType": "Permanent",
+				"bounceSubType": "General",
+				"bouncedRecipients": [
+					{ "emailAddress": "jon.snow@got.com", "diagnosticCode": "smtp; 550 5.1.1 user unknown" },
+					{ "emailAddress": "arya.stark@got.com" }
+				]
+			}
+		}`,
+	})
+
+	code, _ := mock.NewServer().ExecutePost(handlers.ReceiveSESNotification(), body)
+	Expect(code).Equals(http.StatusOK)
+	Expect(*statuses).HasLen(2)
+	Expect((*statuses)[0]).Equals(&cmd.SetEmailDeliveryStatus{
+		MessageID: "0100016c-message-id",
+		Address:   "jon.snow@got.com",
+		Status:    enum.EmailDeliveryStatusBounced,
+		Reason:    "smtp; 550 5.1.1 user unknown",
+	})
+	Expect((*statuses)[1].Address).Equals("arya.stark@got.com")
+	Expect((*statuses)[1].Reason).Equals("Permanent bounce (General)")
+}
+
+func TestReceiveSESNotificationHandler_ComplaintEvent(t *testing.T) {
+	RegisterT(t)
+	env.Config.Email.SES.TopicARN = snsTopicARN
+	key, _ := mockSNS()
+	statuses := captureDeliveryStatus()
+
+	body := signedSNSMessage(key, map[string]string{
+		"Type": "Notification",
+		"Message": `{
+			"eventType": "Complaint",
+			"mail": { "messageId": "0100016c-message-id" },
+			"complaint": {
+				"complaintFeedbackType": "abuse",
+				"complainedRecipients": [ { "emailAddress": "jon.snow@got.com" } ]
+			}
+		}`,
+	})
+
+	code, _ := mock.NewServer().ExecutePost(handlers.ReceiveSESNotification(), body)
+	Expect(code).Equals(http.StatusOK)
+	Expect(*statuses).HasLen(1)
+	Expect((*statuses)[0].Status).Equals(enum.EmailDeliveryStatusComplained)
+	Expect((*statuses)[0].Reason).Equals("abuse")
+}
+
+func TestReceiveSESNotificationHandler_SubscriptionConfirmation(t *testing.T) {
+	RegisterT(t)
+	env.Config.Email.SES.TopicARN = snsTopicARN
+	key, requests := mockSNS()
+
+	subscribeURL := "https://sns.us-east-1.amazonaws.com/?Action=ConfirmSubscription&TopicArn=" + snsTopicARN + "&Token=abc"
+	body := signedSNSMessage(key, map[string]string{
+		"Type":         "SubscriptionConfirmation",
+		"Message":      "You have chosen to subscribe to the topic.",
+		"SubscribeURL": subscribeURL,
+		"Token":        "abc",
+	})
+
+	code, _ := mock.NewServer().ExecutePost(handlers.ReceiveSESNotification(), body)
+	Expect(code).Equals(http.StatusOK)
+	Expect((*requests)[len(*requests)-1].URL).Equals(subscribeURL)
+}
+
+func TestReceiveSESNotificationHandler_InvalidSignature(t *testing.T) {
+	RegisterT(t)
+	env.Config.Email.SES.TopicARN = snsTopicARN
+	mockSNS()
+	statuses := captureDeliveryStatus()
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	Expect(err).IsNil()
+
+	body := signedSNSMessage(otherKey, map[string]string{
+		"Type":    "Notification",
+		"Message": `{ "notificationType": "Complaint", "mail": { "messageId": "1" }, "complaint": { "complainedRecipients": [ { "emailAddress": "jon.snow@got.com" } ] } }`,
+	})
+
+	code, _ := mock.NewServer().ExecutePost(handlers.ReceiveSESNotification(), body)
+	Expect(code).Equals(http.StatusForbidden)
+	Expect(*statuses).HasLen(0)
+}
+
+func TestReceiveSESNotificationHandler_OtherTopic(t *testing.T) {
+	RegisterT(t)
+	env.Config.Email.SES.TopicARN = "arn:aws:sns:us-east-1:123456789012:other-topic"
+	key, _ := mockSNS()
+	statuses := captureDeliveryStatus()
+
+	body := signedSNSMessage(key, map[string]string{
+		"Type":    "Notification",
+		"Message": `{ "notificationType": "Complaint", "mail": { "messageId": "1" }, "complaint": { "complainedRecipients": [ { "emailAddress": "jon.snow@got.com" } ] } }`,
+	})
+
+	code, _ := mock.NewServer().ExecutePost(handlers.ReceiveSESNotification(), body)
+	Expect(code).Equals(http.StatusForbidden)
+	Expect(*statuses).HasLen(0)
+}
+
+func TestReceiveSESNotificationHandler_Disabled(t *testing.T) {
+	RegisterT(t)
+	env.Config.Email.SES.TopicARN = ""
+
+	code, _ := mock.NewServer().ExecutePost(handlers.ReceiveSESNotification(), "{}")
+	Expect(code).Equals(http.StatusNotFound)
+}
@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"github.com/getfider/fider/app/actions"
+	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/dto"
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/models/query"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/i18n"
+	"github.com/getfider/fider/app/pkg/web"
+	"github.com/getfider/fider/app/services/email"
+)
+
+// EmailTemplatesPage is the page used to customize the emails sent by current tenant
+func EmailTemplatesPage() web.HandlerFunc {
+	return func(c *web.Context) error {
+		listCustom := &query.ListCustomEmailTemplates{}
+		if err := bus.Dispatch(c, listCustom); err != nil {
+			return c.Failure(err)
+		}
+
+		custom := make(map[string]*models.EmailTemplate, len(listCustom.Result))
+		for _, template := range listCustom.Result {
+			custom[template.Name] = template
+		}
+
+		locale := i18n.TenantLocale(c.Tenant())
+		templates := make([]*models.EmailTemplate, 0)
+		defaults := make(map[string]*models.EmailTemplate)
+		for _, name := range email.EditableTemplates() {
+			template, err := email.DefaultTemplate(locale, name)
+			if err != nil {
+				return c.Failure(err)
+			}
+			defaults[name] = template
+
+			if customized, ok := custom[name]; ok {
+				customized.Variables = template.Variables
+				template = customized
+			}
+			templates = append(templates, template)
+		}
+
+		return c.Page(web.Props{
+			Title:     "Emails · Site Settings",
+			ChunkName: "EmailTemplates.page",
+			Data: web.Map{
+				"templates": templates,
+				"defaults":  defaults,
+			},
+		})
+	}
+}
+
+// SaveEmailTemplate customizes the subject and body of an email template
+func SaveEmailTemplate() web.HandlerFunc {
+	return func(c *web.Context) error {
+		input := new(actions.SaveEmailTemplate)
+		if result := c.BindTo(input); !result.Ok {
+			return c.HandleValidation(result)
+		}
+
+		if err := bus.Dispatch(c, &cmd.SaveEmailTemplate{
+			Name:    input.Model.Name,
+			Subject: input.Model.Subject,
+			Body:    input.Model.Body,
+		}, &cmd.AddAuditLog{
+			Action:     enum.AuditActionEmailTemplateSaved,
+			TargetType: "email_template",
+			TargetName: input.Model.Name,
+			After:      dto.Props{"subject": input.Model.Subject, "body": input.Model.Body},
+			ClientIP:   c.Request.ClientIP,
+		}); err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(web.Map{})
+	}
+}
+
+// ResetEmailTemplate removes the customization of an email template, so that the default one is used
+func ResetEmailTemplate() web.HandlerFunc {
+	return func(c *web.Context) error {
+		name := c.Param("name")
+		if !email.IsEditable(name) {
+			return c.NotFound()
+		}
+
+		if err := bus.Dispatch(c, &cmd.ResetEmailTemplate{Name: name}, &cmd.AddAuditLog{
+			Action:     enum.AuditActionEmailTemplateReset,
+			TargetType: "email_template",
+			TargetName: name,
+			ClientIP:   c.Request.ClientIP,
+		}); err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(web.Map{})
+	}
+}
+
+// PreviewEmailTemplate renders an email template with sample data, without saving it
+func PreviewEmailTemplate() web.HandlerFunc {
+	return func(c *web.Context) error {
+		input := new(actions.SaveEmailTemplate)
+		if result := c.BindTo(input); !result.Ok {
+			return c.HandleValidation(result)
+		}
+
+		message, err := email.RenderTemplate(i18n.TenantLocale(c.Tenant()), &models.EmailTemplate{
+			Name:    input.Model.Name,
+			Subject: input.Model.Subject,
+			Body:    input.Model.Body,
+		}, sampleEmailProps(c, input.Model.Name))
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(web.Map{
+			"subject": message.Subject,
+			"body":    message.Body,
+		})
+	}
+}
+
+// SendSampleEmailTemplate sends an email template with sample data to current user, without saving it
+func SendSampleEmailTemplate() web.HandlerFunc {
+	return func(c *web.Context) error {
+		input := new(actions.SaveEmailTemplate)
+		if result := c.BindTo(input); !result.Ok {
+			return c.HandleValidation(result)
+		}
+
+		if c.User().Email != "" {
+			ctx := email.WithTemplate(c, &models.EmailTemplate{
+				Name:    input.Model.Name,
+				Subject: input.Model.Subject,
+				Body:    input.Model.Body,
+			})
+
+			bus.Publish(ctx, &cmd.SendMail{
+				From:         c.Tenant().Name,
+				To:           []dto.Recipient{dto.NewRecipient(c.User().Name, c.User().Email, dto.Props{})},
+				TemplateName: input.Model.Name,
+				Props:        sampleEmailProps(c, input.Model.Name),
+			})
+		}
+
+		return c.Ok(web.Map{})
+	}
+}
+
+func sampleEmailProps(c *web.Context, name string) dto.Props {
+	props := email.SampleProps(i18n.TenantLocale(c.Tenant()), name, c.BaseURL(), c.Tenant().Name)
+	props["logo"] = web.LogoURL(c)
+	return props
+}
@@ -0,0 +1,113 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/getfider/fider/app/handlers"
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/enum"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/mock"
+)
+
+func TestSaveEmailTemplateHandler(t *testing.T) {
+	RegisterT(t)
+
+	var addAuditLog *cmd.AddAuditLog
+	bus.AddHandler(func(ctx context.Context, c *cmd.AddAuditLog) error {
+		addAuditLog = c
+		return nil
+	})
+
+	var saveCmd *cmd.SaveEmailTemplate
+	bus.AddHandler(func(ctx context.Context, c *cmd.SaveEmailTemplate) error {
+		saveCmd = c
+		return nil
+	})
+
+	code, _ := mock.NewServer().
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		AddParam("name", "invite_email").
+		ExecutePost(handlers.SaveEmailTemplate(), `{ "subject": "Join {{ .tenantName }}", "body": "<tr><td>{{ .message }}</td></tr>" }`)
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(saveCmd.Name).Equals("invite_email")
+	Expect(saveCmd.Subject).Equals("Join {{ .tenantName }}")
+	Expect(saveCmd.Body).Equals("<tr><td>{{ .message }}</td></tr>")
+	Expect(addAuditLog.Action).Equals(enum.AuditActionEmailTemplateSaved)
+	Expect(addAuditLog.TargetName).Equals("invite_email")
+}
+
+func TestSaveEmailTemplateHandler_Invalid(t *testing.T) {
+	RegisterT(t)
+
+	code, _ := mock.NewServer().
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		AddParam("name", "invite_email").
+		ExecutePost(handlers.SaveEmailTemplate(), `{ "subject": "Join us", "body": "{{ template \"other\" }}" }`)
+
+	Expect(code).Equals(http.StatusBadRequest)
+}
+
+func TestResetEmailTemplateHandler(t *testing.T) {
+	RegisterT(t)
+
+	var addAuditLog *cmd.AddAuditLog
+	bus.AddHandler(func(ctx context.Context, c *cmd.AddAuditLog) error {
+		addAuditLog = c
+		return nil
+	})
+
+	var resetCmd *cmd.ResetEmailTemplate
+	bus.AddHandler(func(ctx context.Context, c *cmd.ResetEmailTemplate) error {
+		resetCmd = c
+		return nil
+	})
+
+	code, _ := mock.NewServer().
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		AddParam("name", "new_comment").
+		Execute(handlers.ResetEmailTemplate())
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(resetCmd.Name).Equals("new_comment")
+	Expect(addAuditLog.Action).Equals(enum.AuditActionEmailTemplateReset)
+	Expect(addAuditLog.TargetName).Equals("new_comment")
+}
+
+func TestResetEmailTemplateHandler_NotEditable(t *testing.T) {
+	RegisterT(t)
+
+	code, _ := mock.NewServer().
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		AddParam("name", "signup_email").
+		Execute(handlers.ResetEmailTemplate())
+
+	Expect(code).Equals(http.StatusNotFound)
+}
+
+func TestPreviewEmailTemplateHandler(t *testing.T) {
+	RegisterT(t)
+
+	code, response := mock.NewServer().
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		AddParam("name", "new_post").
+		ExecutePost(handlers.PreviewEmailTemplate(), `{ "subject": "[{{ .tenantName }}] {{ .title }}", "body": "<tr><td>{{ .userName }} said {{ .content }}</td></tr>" }`)
+
+	Expect(code).Equals(http.StatusOK)
+
+	preview := make(map[string]string)
+	Expect(json.NewDecoder(response.Body).Decode(&preview)).IsNil()
+	Expect(preview["subject"]).Equals("[Demonstration] Add support for TypeScript")
+	Expect(strings.Contains(preview["body"], "Jon Snow said <p>TypeScript is great, please add support for it.</p>")).IsTrue()
+}
@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"github.com/getfider/fider/app/models/query"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/web"
+)
+
+//DownloadFile returns a file attached to a post or comment
+//Files are always downloaded instead of displayed, so that browsers never render uploaded content
+func DownloadFile() web.HandlerFunc {
+	return func(c *web.Context) error {
+		bkey := c.Param("bkey")
+
+		getFile := &query.GetFileAttachmentByKey{BlobKey: bkey}
+		getBlob := &query.GetBlobByKey{Key: bkey}
+		if err := bus.Dispatch(c, getFile, getBlob); err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Attachment(getFile.Result.FileName, getFile.Result.ContentType, getBlob.Result.Content)
+	}
+}
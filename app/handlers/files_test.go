@@ -0,0 +1,101 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/handlers"
+	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/models/dto"
+	"github.com/getfider/fider/app/models/query"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/mock"
+)
+
+func TestDownloadFileHandler(t *testing.T) {
+	RegisterT(t)
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetFileAttachmentByKey) error {
+		if q.BlobKey == "files/abc-report.pdf" {
+			q.Result = &models.FileAttachment{
+				BlobKey:     q.BlobKey,
+				FileName:    "report.pdf",
+				ContentType: "application/pdf",
+				Size:        8,
+			}
+			return nil
+		}
+		return app.ErrNotFound
+	})
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetBlobByKey) error {
+		q.Result = &dto.Blob{
+			Content:     []byte("%PDF-1.4"),
+			ContentType: "application/pdf",
+			Size:        8,
+		}
+		return nil
+	})
+
+	server := mock.NewServer()
+	code, response := server.
+		OnTenant(mock.DemoTenant).
+		AddParam("bkey", "files/abc-report.pdf").
+		Execute(handlers.DownloadFile())
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(response.Header().Get("Content-Type")).Equals("application/pdf")
+	Expect(response.Header().Get("Content-Disposition")).Equals(`attachment; filename="report.pdf"`)
+	Expect(response.Body.String()).Equals("%PDF-1.4")
+}
+
+func TestDownloadFileHandler_UnicodeFileName(t *testing.T) {
+	RegisterT(t)
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetFileAttachmentByKey) error {
+		q.Result = &models.FileAttachment{
+			BlobKey:     q.BlobKey,
+			FileName:    "relatório \"final\".pdf",
+			ContentType: "application/pdf",
+		}
+		return nil
+	})
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetBlobByKey) error {
+		q.Result = &dto.Blob{Content: []byte("%PDF-1.4")}
+		return nil
+	})
+
+	server := mock.NewServer()
+	code, response := server.
+		OnTenant(mock.DemoTenant).
+		AddParam("bkey", "files/abc-relatorio.pdf").
+		Execute(handlers.DownloadFile())
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(response.Header().Get("Content-Disposition")).Equals(`attachment; filename="relat_rio _final_.pdf"; filename*=UTF-8''relat%C3%B3rio%20%22final%22.pdf`)
+}
+
+func TestDownloadFileHandler_NotFound(t *testing.T) {
+	RegisterT(t)
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetFileAttachmentByKey) error {
+		return app.ErrNotFound
+	})
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetBlobByKey) error {
+		q.Result = &dto.Blob{Content: []byte("%PDF-1.4")}
+		return nil
+	})
+
+	server := mock.NewServer()
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AddParam("bkey", "files/abc-deleted.pdf").
+		Execute(handlers.DownloadFile())
+
+	Expect(code).Equals(http.StatusNotFound)
+}
@@ -17,8 +17,11 @@ import (
 	"github.com/getfider/fider/app/pkg/bus"
 	"github.com/getfider/fider/app/pkg/crypto"
 	"github.com/getfider/fider/app/pkg/env"
+	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/getfider/fider/app/pkg/images"
 	"github.com/getfider/fider/app/pkg/log"
 	"github.com/getfider/fider/app/pkg/web"
+	"github.com/getfider/fider/app/services/blob"
 	"github.com/goenning/imagic"
 	"github.com/goenning/letteravatar"
 )
@@ -173,6 +176,17 @@ func ViewUploadedImage() web.HandlerFunc {
 
 		size = between(size, 0, 2000)
 
+		if images.IsThumbnailSize(size) {
+			q := &query.GetBlobByKey{Key: images.ThumbnailKey(bkey, size)}
+			err = bus.Dispatch(c, q)
+			if err == nil {
+				return c.Image(q.Result.ContentType, q.Result.Content)
+			}
+			if errors.Cause(err) != blob.ErrNotFound {
+				return c.Failure(err)
+			}
+		}
+
 		q := &query.GetBlobByKey{Key: bkey}
 		err = bus.Dispatch(c, q)
 		if err != nil {
@@ -7,8 +7,11 @@ import (
 	"testing"
 
 	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/models/dto"
 	"github.com/getfider/fider/app/models/query"
 	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/env"
+	"github.com/getfider/fider/app/services/blob"
 	"github.com/getfider/fider/app/services/httpclient"
 
 	"github.com/getfider/fider/app/models"
@@ -102,3 +105,64 @@ func TestUnknownUser_LetterAvatarHandler(t *testing.T) {
 	bytes, _ := ioutil.ReadAll(response.Body)
 	Expect(bytes).Equals(expectedAvatar)
 }
+
+func TestViewUploadedImage_Thumbnail(t *testing.T) {
+	RegisterT(t)
+
+	requested := []string{}
+	bus.AddHandler(func(ctx context.Context, q *query.GetBlobByKey) error {
+		requested = append(requested, q.Key)
+		if q.Key == "attachments/image.png@200" {
+			q.Result = &dto.Blob{Content: []byte("thumbnail"), ContentType: "image/png"}
+			return nil
+		}
+		return blob.ErrNotFound
+	})
+
+	server := mock.NewServer()
+	code, response := server.
+		OnTenant(mock.DemoTenant).
+		WithURL("https://demo.test.fider.io/?size=200").
+		AddParam("bkey", "attachments/image.png").
+		Execute(handlers.ViewUploadedImage())
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(response.Body.String()).Equals("thumbnail")
+	Expect(response.Header().Get("Content-Type")).Equals("image/png")
+	Expect(requested).Equals([]string{"attachments/image.png@200"})
+}
+
+func TestViewUploadedImage_WithoutThumbnail(t *testing.T) {
+	RegisterT(t)
+
+	logo, _ := ioutil.ReadFile(env.Path("/app/pkg/web/testdata/logo1.png"))
+	requested := []string{}
+	bus.AddHandler(func(ctx context.Context, q *query.GetBlobByKey) error {
+		requested = append(requested, q.Key)
+		if q.Key == "logos/logo1.png" {
+			q.Result = &dto.Blob{Content: logo, ContentType: "image/png"}
+			return nil
+		}
+		return blob.ErrNotFound
+	})
+
+	server := mock.NewServer()
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		WithURL("https://demo.test.fider.io/?size=100").
+		AddParam("bkey", "logos/logo1.png").
+		Execute(handlers.ViewUploadedImage())
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(requested).Equals([]string{"logos/logo1.png@100", "logos/logo1.png"})
+
+	requested = []string{}
+	code, _ = mock.NewServer().
+		OnTenant(mock.DemoTenant).
+		WithURL("https://demo.test.fider.io/?size=1500").
+		AddParam("bkey", "logos/logo1.png").
+		Execute(handlers.ViewUploadedImage())
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(requested).Equals([]string{"logos/logo1.png"})
+}
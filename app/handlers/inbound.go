@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"crypto/hmac"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/pkg/crypto"
+	"github.com/getfider/fider/app/pkg/env"
+	"github.com/getfider/fider/app/pkg/web"
+	"github.com/getfider/fider/app/tasks"
+	cache "github.com/patrickmn/go-cache"
+)
+
+//mailgunMaxSignatureAge is how old a signed Mailgun request can be before it's considered a replay
+var mailgunMaxSignatureAge = 15 * time.Minute
+
+//usedMailgunTokens keeps the tokens of accepted replies for as long as their signature is valid, so that each reply is only posted once
+var usedMailgunTokens = cache.New(2*mailgunMaxSignatureAge, 2*mailgunMaxSignatureAge)
+
+// ReceiveMailgunReply handles emails forwarded by a Mailgun route and posts them as comments
+func ReceiveMailgunReply() web.HandlerFunc {
+	return func(c *web.Context) error {
+		if !env.IsReplyByEmailEnabled() {
+			return c.NotFound()
+		}
+
+		req, err := http.NewRequest("POST", "/", strings.NewReader(c.Request.Body))
+		if err != nil {
+			return c.Failure(err)
+		}
+		req.Header.Set("Content-Type", c.Request.GetHeader("Content-Type"))
+		if err := req.ParseMultipartForm(32 << 20); err != nil && err != http.ErrNotMultipart {
+			return c.BadRequest(web.Map{})
+		}
+
+		if !isValidMailgunSignature(req.FormValue("timestamp"), req.FormValue("token"), req.FormValue("signature")) {
+			return c.Unauthorized()
+		}
+
+		if err := usedMailgunTokens.Add(req.FormValue("token"), true, cache.DefaultExpiration); err != nil {
+			return c.Unauthorized()
+		}
+
+		message := &models.InboundEmail{
+			From:    req.FormValue("from"),
+			To:      []string{req.FormValue("recipient")},
+			Subject: req.FormValue("subject"),
+			Text:    req.FormValue("body-plain"),
+		}
+
+		if message.From == "" {
+			message.From = req.FormValue("sender")
+		}
+
+		//stripped-text is Mailgun's own version of the reply, without quoted text and signatures
+		if stripped := req.FormValue("stripped-text"); stripped != "" {
+			message.Text = stripped
+		}
+
+		var headers [][]string
+		if err := json.Unmarshal([]byte(req.FormValue("message-headers")), &headers); err == nil {
+			for _, header := range headers {
+				if len(header) == 2 && strings.EqualFold(header[0], "Auto-Submitted") {
+					value := strings.ToLower(strings.TrimSpace(header[1]))
+					message.AutoSubmitted = value != "" && value != "no"
+				}
+			}
+		}
+
+		c.Enqueue(tasks.ReceiveReply(message))
+
+		return c.Ok(web.Map{})
+	}
+}
+
+//isValidMailgunSignature verifies that a request was sent by Mailgun
+//https://documentation.mailgun.com/en/latest/user_manual.html#securing-webhooks
+func isValidMailgunSignature(timestamp, token, signature string) bool {
+	key := env.Config.Email.Mailgun.WebhookSigningKey
+	if key == "" {
+		key = env.Config.Email.Mailgun.APIKey
+	}
+
+	if key == "" || token == "" || signature == "" {
+		return false
+	}
+
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+
+	age := time.Since(time.Unix(seconds, 0))
+	if age > mailgunMaxSignatureAge || age < -mailgunMaxSignatureAge {
+		return false
+	}
+
+	expected := crypto.HMACSHA256(key, []byte(timestamp+token))
+	return hmac.Equal([]byte(expected), []byte(strings.ToLower(signature)))
+}
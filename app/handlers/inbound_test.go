@@ -0,0 +1,86 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/getfider/fider/app/handlers"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/crypto"
+	"github.com/getfider/fider/app/pkg/env"
+	"github.com/getfider/fider/app/pkg/mock"
+	"github.com/getfider/fider/app/pkg/rand"
+)
+
+func mailgunForm(timestamp time.Time, key string) url.Values {
+	ts := strconv.FormatInt(timestamp.Unix(), 10)
+	token := rand.String(50)
+	return url.Values{
+		"timestamp":       {ts},
+		"token":           {token},
+		"signature":       {crypto.HMACSHA256(key, []byte(ts+token))},
+		"recipient":       {"reply+1.1.1.abc@inbound.test.fider.io"},
+		"from":            {"Jon Snow <jon.snow@got.com>"},
+		"subject":         {"Re: [Demonstration] Add support for TypeScript"},
+		"body-plain":      {"I agree!\n\n> TypeScript is great"},
+		"stripped-text":   {"I agree!"},
+		"message-headers": {`[["Auto-Submitted", "no"]]`},
+	}
+}
+
+func TestReceiveMailgunReplyHandler(t *testing.T) {
+	RegisterT(t)
+	env.Config.Email.Inbound.Domain = "inbound.test.fider.io"
+	env.Config.Email.Mailgun.WebhookSigningKey = "my-signing-key"
+
+	code, _ := mock.NewServer().ExecutePostForm(handlers.ReceiveMailgunReply(), mailgunForm(time.Now(), "my-signing-key"))
+	Expect(code).Equals(http.StatusOK)
+}
+
+func TestReceiveMailgunReplyHandler_InvalidSignature(t *testing.T) {
+	RegisterT(t)
+	env.Config.Email.Inbound.Domain = "inbound.test.fider.io"
+	env.Config.Email.Mailgun.WebhookSigningKey = "my-signing-key"
+
+	code, _ := mock.NewServer().ExecutePostForm(handlers.ReceiveMailgunReply(), mailgunForm(time.Now(), "wrong-key"))
+	Expect(code).Equals(http.StatusForbidden)
+
+	form := mailgunForm(time.Now(), "my-signing-key")
+	form.Del("signature")
+	code, _ = mock.NewServer().ExecutePostForm(handlers.ReceiveMailgunReply(), form)
+	Expect(code).Equals(http.StatusForbidden)
+}
+
+func TestReceiveMailgunReplyHandler_Replay(t *testing.T) {
+	RegisterT(t)
+	env.Config.Email.Inbound.Domain = "inbound.test.fider.io"
+	env.Config.Email.Mailgun.WebhookSigningKey = "my-signing-key"
+
+	form := mailgunForm(time.Now(), "my-signing-key")
+	code, _ := mock.NewServer().ExecutePostForm(handlers.ReceiveMailgunReply(), form)
+	Expect(code).Equals(http.StatusOK)
+
+	code, _ = mock.NewServer().ExecutePostForm(handlers.ReceiveMailgunReply(), form)
+	Expect(code).Equals(http.StatusForbidden)
+}
+
+func TestReceiveMailgunReplyHandler_ExpiredSignature(t *testing.T) {
+	RegisterT(t)
+	env.Config.Email.Inbound.Domain = "inbound.test.fider.io"
+	env.Config.Email.Mailgun.WebhookSigningKey = "my-signing-key"
+
+	code, _ := mock.NewServer().ExecutePostForm(handlers.ReceiveMailgunReply(), mailgunForm(time.Now().Add(-1*time.Hour), "my-signing-key"))
+	Expect(code).Equals(http.StatusForbidden)
+}
+
+func TestReceiveMailgunReplyHandler_Disabled(t *testing.T) {
+	RegisterT(t)
+	env.Config.Email.Inbound.Domain = ""
+	env.Config.Email.Mailgun.WebhookSigningKey = "my-signing-key"
+
+	code, _ := mock.NewServer().ExecutePostForm(handlers.ReceiveMailgunReply(), mailgunForm(time.Now(), "my-signing-key"))
+	Expect(code).Equals(http.StatusNotFound)
+}
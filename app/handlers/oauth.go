@@ -37,7 +37,7 @@ func OAuthEcho() web.HandlerFunc {
 			return c.Redirect("/")
 		}
 
-		rawProfile := &query.GetOAuthRawProfile{Provider: provider, Code: code}
+		rawProfile := &query.GetOAuthRawProfile{Provider: provider, Code: code, Identifier: identifier}
 		err := bus.Dispatch(c, rawProfile)
 		if err != nil {
 			return c.Page(web.Props{
@@ -83,7 +83,7 @@ func OAuthToken() web.HandlerFunc {
 			return c.Redirect(redirectURL.String())
 		}
 
-		oauthUser := &query.GetOAuthProfile{Provider: provider, Code: code}
+		oauthUser := &query.GetOAuthProfile{Provider: provider, Code: code, Identifier: identifier}
 		if err := bus.Dispatch(c, oauthUser); err != nil {
 			return c.Failure(err)
 		}
@@ -173,7 +173,12 @@ func OAuthCallback() web.HandlerFunc {
 
 		//Sign up process
 		if redirectURL.Path == "/signup" {
-			oauthUser := &query.GetOAuthProfile{Provider: provider, Code: code}
+			identifier := ""
+			if len(parts) > 1 {
+				identifier = parts[1]
+			}
+
+			oauthUser := &query.GetOAuthProfile{Provider: provider, Code: code, Identifier: identifier}
 			if err := bus.Dispatch(c, oauthUser); err != nil {
 				return c.Failure(err)
 			}
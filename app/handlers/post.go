@@ -3,6 +3,8 @@ package handlers
 import (
 	"fmt"
 
+	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/models/enum"
 	"github.com/getfider/fider/app/models/query"
 	"github.com/getfider/fider/app/pkg/bus"
 	"github.com/getfider/fider/app/pkg/csv"
@@ -69,10 +71,31 @@ func PostDetails() web.HandlerFunc {
 		getAllTags := &query.GetAllTags{}
 		listVotes := &query.ListPostVotes{PostID: getPost.Result.ID, Limit: 6}
 		getAttachments := &query.GetAttachments{Post: getPost.Result}
-		if err := bus.Dispatch(c, getAllTags, getComments, listVotes, isSubscribed, getAttachments); err != nil {
+		listFiles := &query.ListPostFileAttachments{Post: getPost.Result}
+		if err := bus.Dispatch(c, getAllTags, getComments, listVotes, isSubscribed, getAttachments, listFiles); err != nil {
 			return c.Failure(err)
 		}
 
+		files := make([]*models.FileAttachment, 0)
+		commentFiles := make(map[int][]*models.FileAttachment)
+		for _, file := range listFiles.Result {
+			if file.CommentID == 0 {
+				files = append(files, file)
+			} else {
+				commentFiles[file.CommentID] = append(commentFiles[file.CommentID], file)
+			}
+		}
+		for _, comment := range getComments.Result {
+			comment.Files = commentFiles[comment.ID]
+		}
+
+		usedCredits := &query.GetUsedVoteCredits{}
+		if c.IsAuthenticated() && c.Tenant().VotingMode == enum.VotingModeBudgeted {
+			if err := bus.Dispatch(c, usedCredits); err != nil {
+				return c.Failure(err)
+			}
+		}
+
 		return c.Page(web.Props{
 			Title:       getPost.Result.Title,
 			Description: markdown.PlainText(getPost.Result.Description),
@@ -84,6 +107,8 @@ func PostDetails() web.HandlerFunc {
 				"tags":        getAllTags.Result,
 				"votes":       listVotes.Result,
 				"attachments": getAttachments.Result,
+				"files":       files,
+				"usedCredits": usedCredits.Result,
 			},
 		})
 	}
@@ -57,6 +57,10 @@ func TestDetailsHandler(t *testing.T) {
 		return nil
 	})
 
+	bus.AddHandler(func(ctx context.Context, q *query.ListPostFileAttachments) error {
+		return nil
+	})
+
 	bus.AddHandler(func(ctx context.Context, q *query.ListPostVotes) error {
 		return nil
 	})
@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"github.com/getfider/fider/app/models/query"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/web"
+)
+
+// Roadmap shows planned, started and completed posts grouped by status
+func Roadmap() web.HandlerFunc {
+	return func(c *web.Context) error {
+		getRoadmap := &query.GetRoadmap{
+			Tags:        c.QueryParamAsArray("tags"),
+			MatchAnyTag: c.QueryParam("tagsMode") == "any",
+		}
+		getAllTags := &query.GetAllTags{}
+
+		if err := bus.Dispatch(c, getRoadmap, getAllTags); err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Page(web.Props{
+			Title:       "Roadmap",
+			Description: "See what has been planned, what is being worked on and what has been completed recently.",
+			ChunkName:   "Roadmap.page",
+			Data: web.Map{
+				"columns": getRoadmap.Result,
+				"tags":    getAllTags.Result,
+			},
+		})
+	}
+}
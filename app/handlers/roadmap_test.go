@@ -0,0 +1,36 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/getfider/fider/app/handlers"
+	"github.com/getfider/fider/app/models/query"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/mock"
+)
+
+func TestRoadmapHandler(t *testing.T) {
+	RegisterT(t)
+
+	var getRoadmap *query.GetRoadmap
+	bus.AddHandler(func(ctx context.Context, q *query.GetRoadmap) error {
+		getRoadmap = q
+		return nil
+	})
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetAllTags) error {
+		return nil
+	})
+
+	server := mock.NewServer()
+	code, _ := server.OnTenant(mock.DemoTenant).
+		WithURL("http://demo.test.fider.io/roadmap?tags=bug,ux&tagsMode=any").
+		Execute(handlers.Roadmap())
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(getRoadmap.Tags).Equals([]string{"bug", "ux"})
+	Expect(getRoadmap.MatchAnyTag).IsTrue()
+}
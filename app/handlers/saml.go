@@ -0,0 +1,385 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/actions"
+	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/dto"
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/models/query"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/crypto"
+	"github.com/getfider/fider/app/pkg/env"
+	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/getfider/fider/app/pkg/jwt"
+	"github.com/getfider/fider/app/pkg/log"
+	"github.com/getfider/fider/app/pkg/saml"
+	"github.com/getfider/fider/app/pkg/validate"
+	"github.com/getfider/fider/app/pkg/web"
+	webutil "github.com/getfider/fider/app/pkg/web/util"
+)
+
+const samlProvider = "_saml"
+
+func samlServiceProvider(c *web.Context) *saml.ServiceProvider {
+	return &saml.ServiceProvider{
+		EntityID: c.BaseURL() + "/saml/metadata",
+		ACSURL:   c.BaseURL() + "/saml/acs",
+	}
+}
+
+//getSAMLConfig returns the SAML configuration of current tenant, or nil if it's not enabled
+func getSAMLConfig(c *web.Context) (*models.SAMLConfig, error) {
+	getConfig := &query.GetSAMLConfig{}
+	err := bus.Dispatch(c, getConfig)
+	if errors.Cause(err) == app.ErrNotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if !getConfig.Result.IsEnabled {
+		return nil, nil
+	}
+	return getConfig.Result, nil
+}
+
+//getSAMLServiceProvider returns the SAML configuration of current tenant, or nil if it's not enabled
+func getSAMLServiceProvider(c *web.Context) (*models.SAMLConfig, *saml.ServiceProvider, error) {
+	config, err := getSAMLConfig(c)
+	if err != nil || config == nil {
+		return nil, nil, err
+	}
+
+	idp, err := saml.ParseMetadata([]byte(config.IdPMetadata))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sp := samlServiceProvider(c)
+	sp.IdP = idp
+	return config, sp, nil
+}
+
+//samlRequestID is the AuthnRequest ID for given session, so that it doesn't need to be stored
+func samlRequestID(identifier string) string {
+	return "id-" + crypto.HMACSHA256(env.Config.JWTSecret, []byte("saml|"+identifier))
+}
+
+// SAMLMetadata returns the Service Provider metadata that is used to configure the Identity Provider
+func SAMLMetadata() web.HandlerFunc {
+	return func(c *web.Context) error {
+		metadata, err := samlServiceProvider(c).Metadata()
+		if err != nil {
+			return c.Failure(err)
+		}
+		return c.Blob(http.StatusOK, "application/samlmetadata+xml", metadata)
+	}
+}
+
+// SignInBySAML redirects the user to the Identity Provider with a new authentication request
+func SignInBySAML() web.HandlerFunc {
+	return func(c *web.Context) error {
+		c.Response.Header().Add("X-Robots-Tag", "noindex")
+
+		redirect := c.QueryParam("redirect")
+		redirectURL, err := url.ParseRequestURI(redirect)
+		if err != nil || (redirectURL.Host != "" && redirectURL.Host != c.Request.URL.Host) {
+			redirect = "/"
+		} else {
+			redirect = redirectURL.RequestURI()
+		}
+
+		if c.IsAuthenticated() {
+			return c.Redirect(redirect)
+		}
+
+		_, sp, err := getSAMLServiceProvider(c)
+		if err != nil {
+			return c.Failure(err)
+		}
+		if sp == nil {
+			return c.NotFound()
+		}
+
+		authURL, err := sp.AuthnRequestURL(samlRequestID(c.SessionID()), redirect)
+		if err != nil {
+			return c.Failure(err)
+		}
+		return c.Redirect(authURL)
+	}
+}
+
+// SAMLAssertionConsumer receives the SAML Response posted by the Identity Provider
+// The browser usually doesn't send cookies on this cross-site request, so the validated profile
+// is redirected to SAMLToken, where it's matched with user session before signing in
+func SAMLAssertionConsumer() web.HandlerFunc {
+	return func(c *web.Context) error {
+		c.Response.Header().Add("X-Robots-Tag", "noindex")
+
+		form, err := url.ParseQuery(c.Request.Body)
+		if err != nil {
+			return c.BadRequest(web.Map{})
+		}
+
+		config, sp, err := getSAMLServiceProvider(c)
+		if err != nil {
+			return c.Failure(err)
+		}
+		if sp == nil {
+			return c.NotFound()
+		}
+
+		assertion, err := sp.ParseResponse(form.Get("SAMLResponse"))
+		if err != nil {
+			log.Warnf(c, "Invalid SAML Response: @{Error}", dto.Props{
+				"Error": errors.Cause(err).Error(),
+			})
+			return c.Unauthorized()
+		}
+
+		useAssertion := &cmd.UseSAMLAssertion{
+			AssertionID: assertion.ID,
+			ExpiresAt:   assertion.ExpiresAt,
+		}
+		if err := bus.Dispatch(c, useAssertion); err != nil {
+			return c.Failure(err)
+		}
+		if useAssertion.AlreadyUsed {
+			log.Warn(c, "SAML Assertion has already been used. Aborting sign in process.")
+			return c.Unauthorized()
+		}
+
+		claims := samlClaims(c.Tenant(), config, assertion)
+		token, err := jwt.Encode(claims)
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		redirect := form.Get("RelayState")
+		if !strings.HasPrefix(redirect, "/") || strings.HasPrefix(redirect, "//") {
+			redirect = "/"
+		}
+
+		var query = url.Values{}
+		query.Set("token", token)
+		query.Set("redirect", redirect)
+		return c.Redirect("/saml/token?" + query.Encode())
+	}
+}
+
+func samlClaims(tenant *models.Tenant, config *models.SAMLConfig, assertion *saml.Assertion) *jwt.SAMLClaims {
+	claims := &jwt.SAMLClaims{
+		SAMLNameID:    assertion.NameID,
+		SAMLRequestID: assertion.InResponseTo,
+		SAMLTenantID:  tenant.ID,
+		SAMLIssuer:    config.IdPEntityID,
+		Metadata: jwt.Metadata{
+			ExpiresAt: time.Now().Add(5 * time.Minute).Unix(),
+		},
+	}
+
+	if config.EmailAttribute != "" {
+		claims.SAMLEmail = strings.ToLower(assertion.Attribute(config.EmailAttribute))
+	}
+	if len(validate.Email(claims.SAMLEmail)) > 0 {
+		claims.SAMLEmail = ""
+	}
+
+	if config.NameAttribute != "" {
+		claims.SAMLName = assertion.Attribute(config.NameAttribute)
+	}
+	if claims.SAMLName == "" && claims.SAMLEmail != "" {
+		claims.SAMLName = strings.Split(claims.SAMLEmail, "@")[0]
+	}
+	if claims.SAMLName == "" {
+		claims.SAMLName = assertion.NameID
+	}
+	if len(claims.SAMLName) > 100 {
+		claims.SAMLName = claims.SAMLName[:100]
+	}
+
+	if role, ok := config.MapRole(assertion.Attributes[config.RoleAttribute]); ok {
+		claims.SAMLRole = int(role)
+	}
+
+	return claims
+}
+
+// SAMLToken signs in the user of a validated SAML Assertion
+// Users are matched by NameID or email and new users are registered, even on private sites,
+// because the Identity Provider is trusted by the site administrators.
+// Sign in started by the Identity Provider is refused, as its assertion can't be bound to the user session
+func SAMLToken() web.HandlerFunc {
+	return func(c *web.Context) error {
+		redirect := c.QueryParam("redirect")
+		if !strings.HasPrefix(redirect, "/") || strings.HasPrefix(redirect, "//") {
+			redirect = "/"
+		}
+
+		config, err := getSAMLConfig(c)
+		if err != nil {
+			return c.Failure(err)
+		}
+		if config == nil {
+			return c.NotFound()
+		}
+
+		claims, err := jwt.DecodeSAMLClaims(c.QueryParam("token"))
+		if err != nil {
+			return c.Redirect(redirect)
+		}
+
+		if claims.SAMLTenantID != c.Tenant().ID || claims.SAMLIssuer != config.IdPEntityID {
+			log.Warn(c, "SAML token was issued for another site or Identity Provider. Aborting sign in process.")
+			return c.Redirect(redirect)
+		}
+
+		if claims.SAMLRequestID == "" || claims.SAMLRequestID != samlRequestID(c.SessionID()) {
+			log.Warn(c, "SAML request ID doesn't match with user session ID. Aborting sign in process.")
+			return c.Redirect(redirect)
+		}
+
+		var user *models.User
+
+		userByProvider := &query.GetUserByProvider{Provider: samlProvider, UID: claims.SAMLNameID}
+		err = bus.Dispatch(c, userByProvider)
+		user = userByProvider.Result
+
+		if errors.Cause(err) == app.ErrNotFound && claims.SAMLEmail != "" {
+			userByEmail := &query.GetUserByEmail{Email: claims.SAMLEmail}
+			err = bus.Dispatch(c, userByEmail)
+			user = userByEmail.Result
+		}
+
+		if err != nil {
+			if errors.Cause(err) != app.ErrNotFound {
+				return c.Failure(err)
+			}
+
+			user = &models.User{
+				Name:   claims.SAMLName,
+				Tenant: c.Tenant(),
+				Email:  claims.SAMLEmail,
+				Role:   enum.RoleVisitor,
+				Providers: []*models.UserProvider{
+					&models.UserProvider{
+						UID:  claims.SAMLNameID,
+						Name: samlProvider,
+					},
+				},
+			}
+			if claims.SAMLRole > 0 {
+				user.Role = enum.Role(claims.SAMLRole)
+			}
+
+			if err = bus.Dispatch(c, &cmd.RegisterUser{User: user}); err != nil {
+				return c.Failure(err)
+			}
+		} else {
+			if !user.HasProvider(samlProvider) {
+				if err = bus.Dispatch(c, &cmd.RegisterUserProvider{
+					UserID:       user.ID,
+					ProviderName: samlProvider,
+					ProviderUID:  claims.SAMLNameID,
+				}); err != nil {
+					return c.Failure(err)
+				}
+			}
+
+			if claims.SAMLRole > 0 && user.Role != enum.Role(claims.SAMLRole) {
+				user.Role = enum.Role(claims.SAMLRole)
+				if err = bus.Dispatch(c, &cmd.ChangeUserRole{UserID: user.ID, Role: user.Role}); err != nil {
+					return c.Failure(err)
+				}
+			}
+		}
+
+		webutil.AddAuthUserCookie(c, user)
+
+		return c.Redirect(redirect)
+	}
+}
+
+// SAMLSettingsPage is the page used by administrators to configure SAML single sign-on
+func SAMLSettingsPage() web.HandlerFunc {
+	return func(c *web.Context) error {
+		getConfig := &query.GetSAMLConfig{}
+		err := bus.Dispatch(c, getConfig)
+		if err != nil && errors.Cause(err) != app.ErrNotFound {
+			return c.Failure(err)
+		}
+
+		sp := samlServiceProvider(c)
+		return c.Page(web.Props{
+			Title:     "SAML · Site Settings",
+			ChunkName: "SAMLSettings.page",
+			Data: web.Map{
+				"config":      getConfig.Result,
+				"entityID":    sp.EntityID,
+				"acsURL":      sp.ACSURL,
+				"metadataURL": sp.EntityID,
+			},
+		})
+	}
+}
+
+// SaveSAMLConfig is used to create/edit the SAML configuration
+func SaveSAMLConfig() web.HandlerFunc {
+	return func(c *web.Context) error {
+		input := new(actions.CreateEditSAMLConfig)
+		if result := c.BindTo(input); !result.Ok {
+			return c.HandleValidation(result)
+		}
+
+		getConfig := &query.GetSAMLConfig{}
+		err := bus.Dispatch(c, getConfig)
+		if err != nil && errors.Cause(err) != app.ErrNotFound {
+			return c.Failure(err)
+		}
+
+		var before dto.Props
+		if config := getConfig.Result; config != nil {
+			before = dto.Props{
+				"isEnabled":          config.IsEnabled,
+				"displayName":        config.DisplayName,
+				"idpEntityID":        config.IdPEntityID,
+				"idpSSOURL":          config.IdPSSOURL,
+				"nameAttribute":      config.NameAttribute,
+				"emailAttribute":     config.EmailAttribute,
+				"roleAttribute":      config.RoleAttribute,
+				"administratorRoles": config.AdministratorRoles,
+				"collaboratorRoles":  config.CollaboratorRoles,
+			}
+		}
+
+		if err := bus.Dispatch(c, &cmd.SaveSAMLConfig{Config: input.Model}, &cmd.AddAuditLog{
+			Action:     enum.AuditActionSAMLConfigSaved,
+			TargetType: "saml_config",
+			TargetName: input.Model.DisplayName,
+			Before:     before,
+			After: dto.Props{
+				"isEnabled":          input.Model.IsEnabled,
+				"displayName":        input.Model.DisplayName,
+				"idpEntityID":        input.Model.IdPEntityID,
+				"idpSSOURL":          input.Model.IdPSSOURL,
+				"nameAttribute":      input.Model.NameAttribute,
+				"emailAttribute":     input.Model.EmailAttribute,
+				"roleAttribute":      input.Model.RoleAttribute,
+				"administratorRoles": input.Model.AdministratorRoles,
+				"collaboratorRoles":  input.Model.CollaboratorRoles,
+			},
+			ClientIP: c.Request.ClientIP,
+		}); err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(web.Map{})
+	}
+}
@@ -0,0 +1,281 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/handlers"
+	"github.com/getfider/fider/app/middlewares"
+	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/models/query"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/crypto"
+	"github.com/getfider/fider/app/pkg/env"
+	"github.com/getfider/fider/app/pkg/jwt"
+	"github.com/getfider/fider/app/pkg/mock"
+	"github.com/getfider/fider/app/pkg/web"
+)
+
+var enabledSAMLConfig = &models.SAMLConfig{IsEnabled: true, DisplayName: "Okta", IdPEntityID: "http://www.okta.com/exk1"}
+
+func onEnabledSAMLConfig() {
+	bus.AddHandler(func(ctx context.Context, q *query.GetSAMLConfig) error {
+		q.Result = enabledSAMLConfig
+		return nil
+	})
+}
+
+//sessionSAMLClaims binds given claims to a sign in started on demo tenant by the session MY_SESSION_ID
+func sessionSAMLClaims(claims *jwt.SAMLClaims) *jwt.SAMLClaims {
+	claims.SAMLTenantID = mock.DemoTenant.ID
+	claims.SAMLIssuer = enabledSAMLConfig.IdPEntityID
+	claims.SAMLRequestID = "id-" + crypto.HMACSHA256(env.Config.JWTSecret, []byte("saml|MY_SESSION_ID"))
+	return claims
+}
+
+func samlToken(claims *jwt.SAMLClaims) string {
+	claims.Metadata = jwt.Metadata{
+		ExpiresAt: time.Now().Add(5 * time.Minute).Unix(),
+	}
+	token, _ := jwt.Encode(claims)
+	return url.QueryEscape(token)
+}
+
+func TestSAMLMetadataHandler(t *testing.T) {
+	RegisterT(t)
+
+	server := mock.NewServer()
+	code, response := server.
+		WithURL("http://demo.test.fider.io/saml/metadata").
+		OnTenant(mock.DemoTenant).
+		Execute(handlers.SAMLMetadata())
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(response.Header().Get("Content-Type")).Equals("application/samlmetadata+xml")
+	body := response.Body.String()
+	Expect(strings.Contains(body, `entityID="http://demo.test.fider.io/saml/metadata"`)).IsTrue()
+	Expect(strings.Contains(body, `Location="http://demo.test.fider.io/saml/acs"`)).IsTrue()
+}
+
+func TestSignInBySAMLHandler_NotConfigured(t *testing.T) {
+	RegisterT(t)
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetSAMLConfig) error {
+		return app.ErrNotFound
+	})
+
+	server := mock.NewServer()
+	code, _ := server.
+		WithURL("http://demo.test.fider.io/saml/login").
+		OnTenant(mock.DemoTenant).
+		Execute(handlers.SignInBySAML())
+
+	Expect(code).Equals(http.StatusNotFound)
+}
+
+func TestSAMLTokenHandler_NewUser(t *testing.T) {
+	RegisterT(t)
+	onEnabledSAMLConfig()
+
+	var registeredUser *models.User
+	bus.AddHandler(func(ctx context.Context, c *cmd.RegisterUser) error {
+		registeredUser = c.User
+		return nil
+	})
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetUserByProvider) error {
+		return app.ErrNotFound
+	})
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetUserByEmail) error {
+		return app.ErrNotFound
+	})
+
+	token := samlToken(sessionSAMLClaims(&jwt.SAMLClaims{
+		SAMLNameID: "arya@got.com",
+		SAMLName:   "Arya Stark",
+		SAMLEmail:  "arya@got.com",
+		SAMLRole:   int(enum.RoleCollaborator),
+	}))
+
+	server := mock.NewServer()
+	code, response := server.
+		WithURL("http://demo.test.fider.io/saml/token?token="+token+"&redirect=/hello").
+		OnTenant(mock.DemoTenant).
+		AddCookie(web.CookieSessionName, "MY_SESSION_ID").
+		Use(middlewares.Session()).
+		Execute(handlers.SAMLToken())
+
+	Expect(code).Equals(http.StatusTemporaryRedirect)
+	Expect(response.Header().Get("Location")).Equals("/hello")
+
+	Expect(registeredUser.Name).Equals("Arya Stark")
+	Expect(registeredUser.Email).Equals("arya@got.com")
+	Expect(registeredUser.Role).Equals(enum.RoleCollaborator)
+	Expect(registeredUser.Providers[0].Name).Equals("_saml")
+	Expect(registeredUser.Providers[0].UID).Equals("arya@got.com")
+
+	ExpectFiderAuthCookie(response, registeredUser)
+}
+
+func TestSAMLTokenHandler_ExistingUser_NewProviderAndRole(t *testing.T) {
+	RegisterT(t)
+	onEnabledSAMLConfig()
+
+	var newProvider *models.UserProvider
+	bus.AddHandler(func(ctx context.Context, c *cmd.RegisterUserProvider) error {
+		newProvider = &models.UserProvider{
+			Name: c.ProviderName,
+			UID:  c.ProviderUID,
+		}
+		return nil
+	})
+
+	var changedRole enum.Role
+	bus.AddHandler(func(ctx context.Context, c *cmd.ChangeUserRole) error {
+		changedRole = c.Role
+		return nil
+	})
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetUserByProvider) error {
+		return app.ErrNotFound
+	})
+
+	user := &models.User{ID: 5, Name: "Sansa Stark", Email: "sansa@got.com", Role: enum.RoleVisitor, Tenant: mock.DemoTenant}
+	bus.AddHandler(func(ctx context.Context, q *query.GetUserByEmail) error {
+		if q.Email == "sansa@got.com" {
+			q.Result = user
+			return nil
+		}
+		return app.ErrNotFound
+	})
+
+	token := samlToken(sessionSAMLClaims(&jwt.SAMLClaims{
+		SAMLNameID: "S123",
+		SAMLName:   "Sansa Stark",
+		SAMLEmail:  "sansa@got.com",
+		SAMLRole:   int(enum.RoleAdministrator),
+	}))
+
+	server := mock.NewServer()
+	code, response := server.
+		WithURL("http://demo.test.fider.io/saml/token?token="+token+"&redirect=/").
+		OnTenant(mock.DemoTenant).
+		AddCookie(web.CookieSessionName, "MY_SESSION_ID").
+		Use(middlewares.Session()).
+		Execute(handlers.SAMLToken())
+
+	Expect(code).Equals(http.StatusTemporaryRedirect)
+	Expect(response.Header().Get("Location")).Equals("/")
+
+	Expect(newProvider.Name).Equals("_saml")
+	Expect(newProvider.UID).Equals("S123")
+	Expect(changedRole).Equals(enum.RoleAdministrator)
+
+	ExpectFiderAuthCookie(response, user)
+}
+
+func TestSAMLTokenHandler_DifferentSession(t *testing.T) {
+	RegisterT(t)
+	onEnabledSAMLConfig()
+
+	claims := sessionSAMLClaims(&jwt.SAMLClaims{
+		SAMLNameID: "arya@got.com",
+		SAMLName:   "Arya Stark",
+	})
+	claims.SAMLRequestID = "id-of-another-session"
+	token := samlToken(claims)
+
+	server := mock.NewServer()
+	code, response := server.
+		WithURL("http://demo.test.fider.io/saml/token?token="+token+"&redirect=/").
+		OnTenant(mock.DemoTenant).
+		AddCookie(web.CookieSessionName, "MY_SESSION_ID").
+		Use(middlewares.Session()).
+		Execute(handlers.SAMLToken())
+
+	Expect(code).Equals(http.StatusTemporaryRedirect)
+	Expect(response.Header().Get("Location")).Equals("/")
+	ExpectFiderAuthCookie(response, nil)
+}
+
+func TestSAMLTokenHandler_IdPInitiated(t *testing.T) {
+	RegisterT(t)
+	onEnabledSAMLConfig()
+
+	token := samlToken(&jwt.SAMLClaims{
+		SAMLNameID:   "arya@got.com",
+		SAMLName:     "Arya Stark",
+		SAMLTenantID: mock.DemoTenant.ID,
+		SAMLIssuer:   enabledSAMLConfig.IdPEntityID,
+	})
+
+	server := mock.NewServer()
+	code, response := server.
+		WithURL("http://demo.test.fider.io/saml/token?token="+token+"&redirect=/").
+		OnTenant(mock.DemoTenant).
+		AddCookie(web.CookieSessionName, "MY_SESSION_ID").
+		Use(middlewares.Session()).
+		Execute(handlers.SAMLToken())
+
+	Expect(code).Equals(http.StatusTemporaryRedirect)
+	Expect(response.Header().Get("Location")).Equals("/")
+	ExpectFiderAuthCookie(response, nil)
+}
+
+func TestSAMLTokenHandler_AnotherTenant(t *testing.T) {
+	RegisterT(t)
+	onEnabledSAMLConfig()
+
+	claims := sessionSAMLClaims(&jwt.SAMLClaims{
+		SAMLNameID: "arya@got.com",
+		SAMLName:   "Arya Stark",
+		SAMLRole:   int(enum.RoleAdministrator),
+	})
+	claims.SAMLTenantID = mock.AvengersTenant.ID
+
+	server := mock.NewServer()
+	code, response := server.
+		WithURL("http://demo.test.fider.io/saml/token?token="+samlToken(claims)+"&redirect=/").
+		OnTenant(mock.DemoTenant).
+		AddCookie(web.CookieSessionName, "MY_SESSION_ID").
+		Use(middlewares.Session()).
+		Execute(handlers.SAMLToken())
+
+	Expect(code).Equals(http.StatusTemporaryRedirect)
+	Expect(response.Header().Get("Location")).Equals("/")
+	ExpectFiderAuthCookie(response, nil)
+}
+
+func TestSAMLTokenHandler_NotEnabled(t *testing.T) {
+	RegisterT(t)
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetSAMLConfig) error {
+		q.Result = &models.SAMLConfig{IsEnabled: false, IdPEntityID: enabledSAMLConfig.IdPEntityID}
+		return nil
+	})
+
+	token := samlToken(sessionSAMLClaims(&jwt.SAMLClaims{
+		SAMLNameID: "arya@got.com",
+		SAMLName:   "Arya Stark",
+	}))
+
+	server := mock.NewServer()
+	code, response := server.
+		WithURL("http://demo.test.fider.io/saml/token?token="+token+"&redirect=/").
+		OnTenant(mock.DemoTenant).
+		AddCookie(web.CookieSessionName, "MY_SESSION_ID").
+		Use(middlewares.Session()).
+		Execute(handlers.SAMLToken())
+
+	Expect(code).Equals(http.StatusNotFound)
+	ExpectFiderAuthCookie(response, nil)
+}
@@ -3,6 +3,7 @@ package handlers
 import (
 	"time"
 
+	"github.com/getfider/fider/app/models/dto"
 	"github.com/getfider/fider/app/models/enum"
 	"github.com/getfider/fider/app/models/query"
 
@@ -101,6 +102,7 @@ func UpdateUserSettings() web.HandlerFunc {
 				Name:       input.Model.Name,
 				Avatar:     input.Model.Avatar,
 				AvatarType: input.Model.AvatarType,
+				Locale:     input.Model.Locale,
 			},
 			&cmd.UpdateCurrentUserSettings{
 				Settings: input.Model.Settings,
@@ -126,7 +128,15 @@ func ChangeUserRole() web.HandlerFunc {
 			Role:   input.Model.Role,
 		}
 
-		if err := bus.Dispatch(c, changeRole); err != nil {
+		if err := bus.Dispatch(c, changeRole, &cmd.AddAuditLog{
+			Action:     enum.AuditActionUserRoleChanged,
+			TargetType: "user",
+			TargetID:   input.User.ID,
+			TargetName: input.User.Name,
+			Before:     dto.Props{"role": input.User.Role},
+			After:      dto.Props{"role": input.Model.Role},
+			ClientIP:   c.Request.ClientIP,
+		}); err != nil {
 			return c.Failure(err)
 		}
 
@@ -150,7 +160,13 @@ func DeleteUser() web.HandlerFunc {
 func RegenerateAPIKey() web.HandlerFunc {
 	return func(c *web.Context) error {
 		regenerateAPIKey := &cmd.RegenerateAPIKey{}
-		if err := bus.Dispatch(c, regenerateAPIKey); err != nil {
+		if err := bus.Dispatch(c, regenerateAPIKey, &cmd.AddAuditLog{
+			Action:     enum.AuditActionAPIKeyRegenerated,
+			TargetType: "user",
+			TargetID:   c.User().ID,
+			TargetName: c.User().Name,
+			ClientIP:   c.Request.ClientIP,
+		}); err != nil {
 			return c.Failure(err)
 		}
 
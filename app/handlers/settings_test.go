@@ -125,6 +125,12 @@ func TestUpdateUserSettingsHandler_NewSettings(t *testing.T) {
 func TestChangeRoleHandler_Valid(t *testing.T) {
 	RegisterT(t)
 
+	var addAuditLog *cmd.AddAuditLog
+	bus.AddHandler(func(ctx context.Context, c *cmd.AddAuditLog) error {
+		addAuditLog = c
+		return nil
+	})
+
 	bus.AddHandler(func(ctx context.Context, q *query.GetUserByID) error {
 		if q.UserID == mock.AryaStark.ID {
 			q.Result = mock.AryaStark
@@ -149,6 +155,10 @@ func TestChangeRoleHandler_Valid(t *testing.T) {
 	Expect(code).Equals(http.StatusOK)
 	Expect(changeRole.UserID).Equals(mock.AryaStark.ID)
 	Expect(changeRole.Role).Equals(enum.RoleAdministrator)
+	Expect(addAuditLog.Action).Equals(enum.AuditActionUserRoleChanged)
+	Expect(addAuditLog.TargetID).Equals(mock.AryaStark.ID)
+	Expect(addAuditLog.Before["role"]).Equals(mock.AryaStark.Role)
+	Expect(addAuditLog.After["role"]).Equals(enum.RoleAdministrator)
 }
 
 func TestChangeUserEmailHandler_Valid(t *testing.T) {
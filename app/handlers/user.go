@@ -2,6 +2,9 @@ package handlers
 
 import (
 	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/dto"
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/models/query"
 	"github.com/getfider/fider/app/pkg/bus"
 	"github.com/getfider/fider/app/pkg/web"
 )
@@ -14,7 +17,23 @@ func BlockUser() web.HandlerFunc {
 			return c.NotFound()
 		}
 
-		err = bus.Dispatch(c, &cmd.BlockUser{UserID: userID})
+		getUser := &query.GetUserByID{UserID: userID}
+		if err := bus.Dispatch(c, getUser); err != nil {
+			return c.Failure(err)
+		}
+
+		err = bus.Dispatch(c,
+			&cmd.BlockUser{UserID: userID},
+			&cmd.AddAuditLog{
+				Action:     enum.AuditActionUserBlocked,
+				TargetType: "user",
+				TargetID:   userID,
+				TargetName: getUser.Result.Name,
+				Before:     dto.Props{"status": getUser.Result.Status},
+				After:      dto.Props{"status": enum.UserBlocked},
+				ClientIP:   c.Request.ClientIP,
+			},
+		)
 		if err != nil {
 			return c.Failure(err)
 		}
@@ -31,7 +50,23 @@ func UnblockUser() web.HandlerFunc {
 			return c.NotFound()
 		}
 
-		err = bus.Dispatch(c, &cmd.UnblockUser{UserID: userID})
+		getUser := &query.GetUserByID{UserID: userID}
+		if err := bus.Dispatch(c, getUser); err != nil {
+			return c.Failure(err)
+		}
+
+		err = bus.Dispatch(c,
+			&cmd.UnblockUser{UserID: userID},
+			&cmd.AddAuditLog{
+				Action:     enum.AuditActionUserUnblocked,
+				TargetType: "user",
+				TargetID:   userID,
+				TargetName: getUser.Result.Name,
+				Before:     dto.Props{"status": getUser.Result.Status},
+				After:      dto.Props{"status": enum.UserActive},
+				ClientIP:   c.Request.ClientIP,
+			},
+		)
 		if err != nil {
 			return c.Failure(err)
 		}
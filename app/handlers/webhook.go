@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"github.com/getfider/fider/app/actions"
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/dto"
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/models/query"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/rand"
+	"github.com/getfider/fider/app/pkg/web"
+)
+
+// ListWebhooks returns all webhooks of current tenant
+func ListWebhooks() web.HandlerFunc {
+	return func(c *web.Context) error {
+		listWebhooks := &query.ListWebhooks{}
+		if err := bus.Dispatch(c, listWebhooks); err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(listWebhooks.Result)
+	}
+}
+
+// CreateEditWebhook creates a new webhook or updates an existing one
+func CreateEditWebhook() web.HandlerFunc {
+	return func(c *web.Context) error {
+		input := new(actions.CreateEditWebhook)
+		if result := c.BindTo(input); !result.Ok {
+			return c.HandleValidation(result)
+		}
+
+		if input.Webhook != nil {
+			updateWebhook := &cmd.UpdateWebhook{
+				WebhookID: input.Webhook.ID,
+				Name:      input.Model.Name,
+				URL:       input.Model.URL,
+				Events:    input.Model.Events,
+				IsActive:  input.Model.IsActive,
+			}
+			if err := bus.Dispatch(c, updateWebhook, &cmd.AddAuditLog{
+				Action:     enum.AuditActionWebhookUpdated,
+				TargetType: "webhook",
+				TargetID:   input.Webhook.ID,
+				TargetName: input.Model.Name,
+				Before:     webhookProps(input.Webhook.URL, input.Webhook.Events, input.Webhook.IsActive),
+				After:      webhookProps(input.Model.URL, input.Model.Events, input.Model.IsActive),
+				ClientIP:   c.Request.ClientIP,
+			}); err != nil {
+				return c.Failure(err)
+			}
+			return c.Ok(updateWebhook.Result)
+		}
+
+		createWebhook := &cmd.CreateWebhook{
+			Name:     input.Model.Name,
+			URL:      input.Model.URL,
+			Secret:   rand.String(40),
+			Events:   input.Model.Events,
+			IsActive: input.Model.IsActive,
+		}
+		if err := bus.Dispatch(c, createWebhook); err != nil {
+			return c.Failure(err)
+		}
+
+		if err := bus.Dispatch(c, &cmd.AddAuditLog{
+			Action:     enum.AuditActionWebhookCreated,
+			TargetType: "webhook",
+			TargetID:   createWebhook.Result.ID,
+			TargetName: createWebhook.Result.Name,
+			After:      webhookProps(createWebhook.Result.URL, createWebhook.Result.Events, createWebhook.Result.IsActive),
+			ClientIP:   c.Request.ClientIP,
+		}); err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(createWebhook.Result)
+	}
+}
+
+// DeleteWebhook deletes an existing webhook and its delivery log
+func DeleteWebhook() web.HandlerFunc {
+	return func(c *web.Context) error {
+		input := new(actions.DeleteWebhook)
+		if result := c.BindTo(input); !result.Ok {
+			return c.HandleValidation(result)
+		}
+
+		if err := bus.Dispatch(c, &cmd.DeleteWebhook{WebhookID: input.Webhook.ID}, &cmd.AddAuditLog{
+			Action:     enum.AuditActionWebhookDeleted,
+			TargetType: "webhook",
+			TargetID:   input.Webhook.ID,
+			TargetName: input.Webhook.Name,
+			Before:     webhookProps(input.Webhook.URL, input.Webhook.Events, input.Webhook.IsActive),
+			ClientIP:   c.Request.ClientIP,
+		}); err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(web.Map{})
+	}
+}
+
+// ListWebhookDeliveries returns the most recent delivery attempts of a webhook
+func ListWebhookDeliveries() web.HandlerFunc {
+	return func(c *web.Context) error {
+		id, err := c.ParamAsInt("id")
+		if err != nil {
+			return c.NotFound()
+		}
+
+		getWebhook := &query.GetWebhookByID{WebhookID: id}
+		if err := bus.Dispatch(c, getWebhook); err != nil {
+			return c.Failure(err)
+		}
+
+		limit, _ := c.QueryParamAsInt("limit")
+		listDeliveries := &query.ListWebhookDeliveries{WebhookID: getWebhook.Result.ID, Limit: limit}
+		if err := bus.Dispatch(c, listDeliveries); err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(listDeliveries.Result)
+	}
+}
+
+//webhookProps returns the values of a webhook as they are recorded on the audit log, the secret is never recorded
+func webhookProps(url string, events []enum.WebhookEvent, isActive bool) dto.Props {
+	return dto.Props{"url": url, "events": events, "isActive": isActive}
+}
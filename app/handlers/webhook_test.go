@@ -0,0 +1,51 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/getfider/fider/app/handlers"
+	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/enum"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/mock"
+)
+
+func TestCreateEditWebhookHandler_NewWebhook(t *testing.T) {
+	RegisterT(t)
+
+	var addAuditLog *cmd.AddAuditLog
+	bus.AddHandler(func(ctx context.Context, c *cmd.AddAuditLog) error {
+		addAuditLog = c
+		return nil
+	})
+
+	var createCmd *cmd.CreateWebhook
+	bus.AddHandler(func(ctx context.Context, c *cmd.CreateWebhook) error {
+		createCmd = c
+		c.Result = &models.Webhook{ID: 1, Name: c.Name, URL: c.URL, Secret: c.Secret, Events: c.Events, IsActive: c.IsActive}
+		return nil
+	})
+
+	code, _ := mock.NewServer().
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		ExecutePost(
+			handlers.CreateEditWebhook(),
+			`{ "name": "Slack Bot", "url": "https://hooks.example.com/fider", "events": ["post.created", "vote.added"], "isActive": true }`,
+		)
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(createCmd.Name).Equals("Slack Bot")
+	Expect(createCmd.URL).Equals("https://hooks.example.com/fider")
+	Expect(createCmd.Events).Equals([]enum.WebhookEvent{enum.WebhookEventPostCreated, enum.WebhookEventVoteAdded})
+	Expect(createCmd.IsActive).IsTrue()
+	Expect(createCmd.Secret).HasLen(40)
+	Expect(addAuditLog.Action).Equals(enum.AuditActionWebhookCreated)
+	Expect(addAuditLog.TargetID).Equals(1)
+	Expect(addAuditLog.After["url"]).Equals("https://hooks.example.com/fider")
+	Expect(addAuditLog.After["secret"]).IsNil()
+}
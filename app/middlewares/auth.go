@@ -1,7 +1,11 @@
 package middlewares
 
 import (
+	"fmt"
+	"net/http"
+
 	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/pkg/validate"
 	"github.com/getfider/fider/app/pkg/web"
 )
 
@@ -31,3 +35,32 @@ func IsAuthorized(roles ...enum.Role) web.MiddlewareFunc {
 		}
 	}
 }
+
+// RequireScope blocks requests authenticated by an API Token that hasn't been granted given scope
+// Legacy API Keys are limited to enum.LegacyAPIKeyScopes and requests authenticated by cookie are not affected
+func RequireScope(scope enum.APIScope) web.MiddlewareFunc {
+	return func(next web.HandlerFunc) web.HandlerFunc {
+		return func(c *web.Context) error {
+			if token := c.APIToken(); token != nil && !token.HasScope(scope) {
+				return c.JSON(http.StatusForbidden, web.Map{
+					"errors": []validate.ErrorItem{
+						{Message: fmt.Sprintf("API Token requires scope '%s'", scope)},
+					},
+				})
+			}
+			return next(c)
+		}
+	}
+}
+
+// CanViewRoadmap blocks requests to the roadmap of tenants that made it private, unless current user is a collaborator
+func CanViewRoadmap() web.MiddlewareFunc {
+	return func(next web.HandlerFunc) web.HandlerFunc {
+		return func(c *web.Context) error {
+			if !c.Tenant().IsRoadmapPublic && (!c.IsAuthenticated() || !c.User().IsCollaborator()) {
+				return c.NotFound()
+			}
+			return next(c)
+		}
+	}
+}
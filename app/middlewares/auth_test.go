@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/getfider/fider/app/middlewares"
+	"github.com/getfider/fider/app/models"
 	"github.com/getfider/fider/app/models/enum"
 	. "github.com/getfider/fider/app/pkg/assert"
 	"github.com/getfider/fider/app/pkg/mock"
@@ -59,3 +60,84 @@ func TestIsAuthenticated_WithoutUser(t *testing.T) {
 
 	Expect(status).Equals(http.StatusForbidden)
 }
+
+func TestRequireScope_WithoutAPIToken(t *testing.T) {
+	RegisterT(t)
+
+	server := mock.NewServer()
+	server.Use(middlewares.RequireScope(enum.APIScopePostsWrite))
+	status, _ := server.AsUser(mock.JonSnow).Execute(func(c *web.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	Expect(status).Equals(http.StatusOK)
+}
+
+func TestRequireScope_WithGrantedScope(t *testing.T) {
+	RegisterT(t)
+
+	server := mock.NewServer()
+	server.Use(func(next web.HandlerFunc) web.HandlerFunc {
+		return func(c *web.Context) error {
+			c.SetAPIToken(&models.APIToken{Scopes: []enum.APIScope{enum.APIScopePostsRead, enum.APIScopePostsWrite}})
+			return next(c)
+		}
+	})
+	server.Use(middlewares.RequireScope(enum.APIScopePostsWrite))
+	status, _ := server.AsUser(mock.JonSnow).Execute(func(c *web.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	Expect(status).Equals(http.StatusOK)
+}
+
+func TestRequireScope_WithMissingScope(t *testing.T) {
+	RegisterT(t)
+
+	server := mock.NewServer()
+	server.Use(func(next web.HandlerFunc) web.HandlerFunc {
+		return func(c *web.Context) error {
+			c.SetAPIToken(&models.APIToken{Scopes: []enum.APIScope{enum.APIScopePostsRead}})
+			return next(c)
+		}
+	})
+	server.Use(middlewares.RequireScope(enum.APIScopePostsWrite))
+	status, response := server.AsUser(mock.JonSnow).Execute(func(c *web.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	Expect(status).Equals(http.StatusForbidden)
+	Expect(response.Body.String()).ContainsSubstring("API Token requires scope 'posts:write'")
+}
+
+func TestCanViewRoadmap_PublicRoadmap(t *testing.T) {
+	RegisterT(t)
+
+	server := mock.NewServer()
+	server.Use(middlewares.CanViewRoadmap())
+	status, _ := server.OnTenant(mock.DemoTenant).Execute(func(c *web.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	Expect(status).Equals(http.StatusOK)
+}
+
+func TestCanViewRoadmap_PrivateRoadmap(t *testing.T) {
+	RegisterT(t)
+
+	server := mock.NewServer()
+	mock.DemoTenant.IsRoadmapPublic = false
+	server.Use(middlewares.CanViewRoadmap())
+	handler := func(c *web.Context) error {
+		return c.NoContent(http.StatusOK)
+	}
+
+	status, _ := server.OnTenant(mock.DemoTenant).AsUser(mock.AryaStark).Execute(handler)
+	Expect(status).Equals(http.StatusNotFound)
+
+	server = mock.NewServer()
+	mock.DemoTenant.IsRoadmapPublic = false
+	server.Use(middlewares.CanViewRoadmap())
+	status, _ = server.OnTenant(mock.DemoTenant).AsUser(mock.JonSnow).Execute(handler)
+	Expect(status).Equals(http.StatusOK)
+}
@@ -5,6 +5,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/getfider/fider/app/models/cmd"
 	"github.com/getfider/fider/app/models/enum"
 	"github.com/getfider/fider/app/models/query"
 	"github.com/getfider/fider/app/pkg/bus"
@@ -14,6 +15,7 @@ import (
 	"github.com/getfider/fider/app/models"
 
 	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/pkg/env"
 	"github.com/getfider/fider/app/pkg/errors"
 	"github.com/getfider/fider/app/pkg/jwt"
 	"github.com/getfider/fider/app/pkg/web"
@@ -61,15 +63,50 @@ func User() web.MiddlewareFunc {
 				parts := strings.Split(authHeader, "Bearer")
 				if len(parts) == 2 {
 					apiKey := strings.TrimSpace(parts[1])
-					getUserByAPIKey := &query.GetUserByAPIKey{APIKey: apiKey}
-					err = bus.Dispatch(c, getUserByAPIKey)
-					if err != nil {
-						if errors.Cause(err) == app.ErrNotFound {
-							return c.HandleValidation(validate.Failed("API Key is invalid"))
+					if strings.HasPrefix(apiKey, models.APITokenPrefix) {
+						getAPIToken := &query.GetAPITokenByKey{Key: apiKey}
+						err = bus.Dispatch(c, getAPIToken)
+						if err != nil {
+							if errors.Cause(err) == app.ErrNotFound {
+								return c.HandleValidation(validate.Failed("API Token is invalid"))
+							}
+							return err
+						}
+
+						apiToken := getAPIToken.Result
+						if apiToken.IsExpired() {
+							return c.HandleValidation(validate.Failed("API Token has expired"))
+						}
+
+						if err = bus.Dispatch(c, &cmd.MarkAPITokenAsUsed{TokenID: apiToken.ID}); err != nil {
+							return err
 						}
-						return err
+
+						c.SetAPIToken(apiToken)
+						user = apiToken.User
+					} else {
+						if !env.Config.API.LegacyKeys {
+							return c.HandleValidation(validate.Failed("API Keys are disabled, use an API Token instead"))
+						}
+
+						getUserByAPIKey := &query.GetUserByAPIKey{APIKey: apiKey}
+						err = bus.Dispatch(c, getUserByAPIKey)
+						if err != nil {
+							if errors.Cause(err) == app.ErrNotFound {
+								return c.HandleValidation(validate.Failed("API Key is invalid"))
+							}
+							return err
+						}
+						user = getUserByAPIKey.Result
+
+						// Legacy API Keys are deprecated and act as a token with a fixed set of scopes
+						c.Response.Header().Set("Deprecation", "true")
+						c.SetAPIToken(&models.APIToken{
+							Name:   "Legacy API Key",
+							User:   user,
+							Scopes: enum.LegacyAPIKeyScopes,
+						})
 					}
-					user = getUserByAPIKey.Result
 
 					if !user.IsCollaborator() {
 						return c.HandleValidation(validate.Failed("API Key is invalid"))
@@ -79,6 +116,9 @@ func User() web.MiddlewareFunc {
 						if !user.IsAdministrator() {
 							return c.HandleValidation(validate.Failed("Only Administrators are allowed to impersonate another user"))
 						}
+						if apiToken := c.APIToken(); apiToken != nil && !apiToken.HasScope(enum.APIScopeUsersAdmin) {
+							return c.HandleValidation(validate.Failed(fmt.Sprintf("API Token requires scope '%s' to impersonate another user", enum.APIScopeUsersAdmin)))
+						}
 						impersonateUserID, err := strconv.Atoi(impersonateUserIDStr)
 						if err != nil {
 							return c.HandleValidation(validate.Failed(fmt.Sprintf("User not found for given impersonate UserID '%s'", impersonateUserIDStr)))
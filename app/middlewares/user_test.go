@@ -11,10 +11,12 @@ import (
 
 	"github.com/getfider/fider/app/middlewares"
 	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/models/cmd"
 	"github.com/getfider/fider/app/models/enum"
 	"github.com/getfider/fider/app/models/query"
 	. "github.com/getfider/fider/app/pkg/assert"
 	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/env"
 	"github.com/getfider/fider/app/pkg/jwt"
 	"github.com/getfider/fider/app/pkg/mock"
 	"github.com/getfider/fider/app/pkg/web"
@@ -290,6 +292,52 @@ func TestUser_ValidAPIKey(t *testing.T) {
 	Expect(response.Body.String()).Equals("Jon Snow")
 }
 
+func TestUser_ValidAPIKey_LimitedScopes(t *testing.T) {
+	RegisterT(t)
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetUserByAPIKey) error {
+		q.Result = mock.JonSnow
+		return nil
+	})
+
+	server := mock.NewServer()
+
+	server.Use(middlewares.User())
+	status, response := server.
+		OnTenant(mock.DemoTenant).
+		WithURL("http://example.com/api/v1/audit").
+		AddHeader("Authorization", "Bearer 1234567890").
+		Execute(middlewares.RequireScope(enum.APIScopeAuditRead)(func(c *web.Context) error {
+			return c.NoContent(http.StatusOK)
+		}))
+
+	Expect(status).Equals(http.StatusForbidden)
+	Expect(response.Header().Get("Deprecation")).Equals("true")
+}
+
+func TestUser_APIKey_Disabled(t *testing.T) {
+	RegisterT(t)
+	env.Config.API.LegacyKeys = false
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetUserByAPIKey) error {
+		q.Result = mock.JonSnow
+		return nil
+	})
+
+	server := mock.NewServer()
+
+	server.Use(middlewares.User())
+	status, _ := server.
+		OnTenant(mock.DemoTenant).
+		WithURL("http://example.com/api/v1").
+		AddHeader("Authorization", "Bearer 1234567890").
+		Execute(func(c *web.Context) error {
+			return c.NoContent(http.StatusOK)
+		})
+
+	Expect(status).Equals(http.StatusBadRequest)
+}
+
 func TestUser_InvalidAPIKey(t *testing.T) {
 	RegisterT(t)
 
@@ -458,3 +506,124 @@ func TestUser_Impersonation_ValidUser(t *testing.T) {
 	Expect(status).Equals(http.StatusOK)
 	Expect(response.Body.String()).Equals("Arya Stark")
 }
+
+func TestUser_ValidAPIToken(t *testing.T) {
+	RegisterT(t)
+
+	var markedTokenID int
+	bus.AddHandler(func(ctx context.Context, c *cmd.MarkAPITokenAsUsed) error {
+		markedTokenID = c.TokenID
+		return nil
+	})
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetAPITokenByKey) error {
+		if q.Key == "fider_1234567890" {
+			q.Result = &models.APIToken{
+				ID:     3,
+				Name:   "CI Bot",
+				User:   mock.JonSnow,
+				Scopes: []enum.APIScope{enum.APIScopePostsRead},
+			}
+			return nil
+		}
+		return app.ErrNotFound
+	})
+
+	server := mock.NewServer()
+
+	server.Use(middlewares.User())
+	status, response := server.
+		OnTenant(mock.DemoTenant).
+		WithURL("http://example.com/api/v1").
+		AddHeader("Authorization", "Bearer fider_1234567890").
+		Execute(func(c *web.Context) error {
+			return c.String(http.StatusOK, c.User().Name+" using "+c.APIToken().Name)
+		})
+
+	Expect(status).Equals(http.StatusOK)
+	Expect(response.Body.String()).Equals("Jon Snow using CI Bot")
+	Expect(markedTokenID).Equals(3)
+}
+
+func TestUser_InvalidAPIToken(t *testing.T) {
+	RegisterT(t)
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetAPITokenByKey) error {
+		return app.ErrNotFound
+	})
+
+	server := mock.NewServer()
+
+	server.Use(middlewares.User())
+	status, response := server.
+		OnTenant(mock.DemoTenant).
+		WithURL("http://example.com/api/v1").
+		AddHeader("Authorization", "Bearer fider_1234567890").
+		Execute(func(c *web.Context) error {
+			return c.NoContent(http.StatusOK)
+		})
+
+	Expect(status).Equals(http.StatusBadRequest)
+	Expect(response.Body.String()).ContainsSubstring("API Token is invalid")
+}
+
+func TestUser_ExpiredAPIToken(t *testing.T) {
+	RegisterT(t)
+
+	expiresAt := time.Now().Add(-1 * time.Hour)
+	bus.AddHandler(func(ctx context.Context, q *query.GetAPITokenByKey) error {
+		q.Result = &models.APIToken{
+			ID:        3,
+			User:      mock.JonSnow,
+			Scopes:    []enum.APIScope{enum.APIScopePostsRead},
+			ExpiresAt: &expiresAt,
+		}
+		return nil
+	})
+
+	server := mock.NewServer()
+
+	server.Use(middlewares.User())
+	status, response := server.
+		OnTenant(mock.DemoTenant).
+		WithURL("http://example.com/api/v1").
+		AddHeader("Authorization", "Bearer fider_1234567890").
+		Execute(func(c *web.Context) error {
+			return c.NoContent(http.StatusOK)
+		})
+
+	Expect(status).Equals(http.StatusBadRequest)
+	Expect(response.Body.String()).ContainsSubstring("API Token has expired")
+}
+
+func TestUser_Impersonation_APITokenWithoutScope(t *testing.T) {
+	RegisterT(t)
+
+	bus.AddHandler(func(ctx context.Context, c *cmd.MarkAPITokenAsUsed) error {
+		return nil
+	})
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetAPITokenByKey) error {
+		q.Result = &models.APIToken{
+			ID:     3,
+			User:   mock.JonSnow,
+			Scopes: []enum.APIScope{enum.APIScopePostsWrite},
+		}
+		return nil
+	})
+
+	server := mock.NewServer()
+
+	server.Use(middlewares.User())
+	status, response := server.
+		OnTenant(mock.DemoTenant).
+		WithURL("http://example.com/api/v1").
+		AddHeader("Authorization", "Bearer fider_1234567890").
+		AddHeader("X-Fider-UserID", strconv.Itoa(mock.AryaStark.ID)).
+		Execute(func(c *web.Context) error {
+			return c.NoContent(http.StatusOK)
+		})
+
+	Expect(status).Equals(http.StatusBadRequest)
+	Expect(response.Body.String()).ContainsSubstring("API Token requires scope 'users:admin' to impersonate another user")
+}
@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/models/enum"
+)
+
+type CreateAPIToken struct {
+	Name      string
+	Key       string
+	Scopes    []enum.APIScope
+	ExpiresAt *time.Time
+
+	Result *models.APIToken
+}
+
+type RevokeAPIToken struct {
+	TokenID int
+}
+
+type MarkAPITokenAsUsed struct {
+	TokenID int
+}
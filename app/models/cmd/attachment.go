@@ -17,3 +17,22 @@ type UploadImages struct {
 	Images []*models.ImageUpload
 	Folder string
 }
+
+type SetFileAttachments struct {
+	Post    *models.Post
+	Comment *models.Comment
+	Files   []*models.FileUpload
+}
+
+type UploadFiles struct {
+	Files  []*models.FileUpload
+	Folder string
+}
+
+type ScanFile struct {
+	FileName string
+	Content  []byte
+
+	Infected bool
+	Threat   string
+}
@@ -0,0 +1,16 @@
+package cmd
+
+import (
+	"github.com/getfider/fider/app/models/dto"
+	"github.com/getfider/fider/app/models/enum"
+)
+
+type AddAuditLog struct {
+	Action     enum.AuditAction
+	TargetType string
+	TargetID   int
+	TargetName string
+	Before     dto.Props
+	After      dto.Props
+	ClientIP   string
+}
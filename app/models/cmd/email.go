@@ -1,6 +1,9 @@
 package cmd
 
-import "github.com/getfider/fider/app/models/dto"
+import (
+	"github.com/getfider/fider/app/models/dto"
+	"github.com/getfider/fider/app/models/enum"
+)
 
 type SendMail struct {
 	From         string
@@ -8,3 +11,21 @@ type SendMail struct {
 	TemplateName string
 	Props        dto.Props
 }
+
+type LogEmailDelivery struct {
+	MessageID    string
+	Provider     string
+	TemplateName string
+	Addresses    []string
+}
+
+type SetEmailDeliveryStatus struct {
+	MessageID string
+	Address   string
+	Status    enum.EmailDeliveryStatus
+	Reason    string
+}
+
+type DeleteEmailSuppression struct {
+	Address string
+}
@@ -0,0 +1,11 @@
+package cmd
+
+type SaveEmailTemplate struct {
+	Name    string
+	Subject string
+	Body    string
+}
+
+type ResetEmailTemplate struct {
+	Name string
+}
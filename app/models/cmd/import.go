@@ -0,0 +1,11 @@
+package cmd
+
+import "github.com/getfider/fider/app/models/dto"
+
+type ImportRecords struct {
+	Posts    []*dto.ImportPost
+	Comments []*dto.ImportComment
+	Votes    []*dto.ImportVote
+
+	Report *dto.ImportReport
+}
@@ -1,6 +1,9 @@
 package cmd
 
-import "github.com/getfider/fider/app/models"
+import (
+	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/models/enum"
+)
 
 type MarkAllNotificationsAsRead struct{}
 
@@ -21,6 +24,27 @@ type AddNewNotification struct {
 	Result *models.Notification
 }
 
+type AddToDigest struct {
+	Users   []*models.User
+	Event   enum.NotificationEvent
+	Title   string
+	Link    string
+	BaseURL string
+	PostID  int
+
+	Result []*models.User
+}
+
+type MarkDigestItemsAsSent struct {
+	ItemIDs []int
+
+	Result []int
+}
+
+type MarkDigestItemsAsPending struct {
+	ItemIDs []int
+}
+
 type AddSubscriber struct {
 	Post *models.Post
 	User *models.User
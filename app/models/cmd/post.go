@@ -25,3 +25,16 @@ type SetPostResponse struct {
 	Text   string
 	Status enum.PostStatus
 }
+
+type MergePost struct {
+	Post     *models.Post
+	Original *models.Post
+
+	Result *models.Post
+}
+
+type UnmergePost struct {
+	Merge *models.PostMerge
+
+	Result *models.Post
+}
@@ -0,0 +1,18 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/getfider/fider/app/models"
+)
+
+type SaveSAMLConfig struct {
+	Config *models.CreateEditSAMLConfig
+}
+
+type UseSAMLAssertion struct {
+	AssertionID string
+	ExpiresAt   time.Time
+
+	AlreadyUsed bool
+}
@@ -0,0 +1,10 @@
+package cmd
+
+import "time"
+
+type ClaimScheduledTask struct {
+	Name     string
+	Interval time.Duration
+
+	Result bool
+}
@@ -18,6 +18,10 @@ type UpdateTenantPrivacySettings struct {
 	Settings *models.UpdateTenantPrivacy
 }
 
+type UpdateTenantVotingSettings struct {
+	Settings *models.UpdateTenantVoting
+}
+
 type UpdateTenantSettings struct {
 	Settings *models.UpdateTenantSettings
 }
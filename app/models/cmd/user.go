@@ -48,4 +48,5 @@ type UpdateCurrentUser struct {
 	Name       string
 	AvatarType enum.AvatarType
 	Avatar     *models.ImageUpload
+	Locale     string
 }
@@ -3,8 +3,9 @@ package cmd
 import "github.com/getfider/fider/app/models"
 
 type AddVote struct {
-	Post *models.Post
-	User *models.User
+	Post    *models.Post
+	User    *models.User
+	Credits int
 }
 
 type RemoveVote struct {
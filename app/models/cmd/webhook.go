@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/models/enum"
+)
+
+type CreateWebhook struct {
+	Name     string
+	URL      string
+	Secret   string
+	Events   []enum.WebhookEvent
+	IsActive bool
+
+	Result *models.Webhook
+}
+
+type UpdateWebhook struct {
+	WebhookID int
+	Name      string
+	URL       string
+	Events    []enum.WebhookEvent
+	IsActive  bool
+
+	Result *models.Webhook
+}
+
+type DeleteWebhook struct {
+	WebhookID int
+}
+
+type LogWebhookDelivery struct {
+	Webhook    *models.Webhook
+	Event      enum.WebhookEvent
+	Attempt    int
+	StatusCode int
+	Success    bool
+	Error      string
+	Payload    string
+	DurationMs int
+}
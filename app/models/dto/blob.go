@@ -1,7 +1,15 @@
 package dto
 
+import "time"
+
 type Blob struct {
 	Size        int64
 	Content     []byte
 	ContentType string
 }
+
+type BlobMetadata struct {
+	Key        string
+	Size       int64
+	ModifiedAt time.Time
+}
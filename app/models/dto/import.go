@@ -0,0 +1,86 @@
+package dto
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/getfider/fider/app/models/enum"
+)
+
+// ImportReport is the outcome of a data import, or what would be imported when running as a dry-run
+type ImportReport struct {
+	DryRun   bool           `json:"dryRun"`
+	Imported map[string]int `json:"imported"`
+	Warnings []string       `json:"warnings"`
+	Errors   []string       `json:"errors"`
+}
+
+// NewImportReport creates a new empty ImportReport
+func NewImportReport(dryRun bool) *ImportReport {
+	return &ImportReport{
+		DryRun:   dryRun,
+		Imported: make(map[string]int),
+		Warnings: make([]string, 0),
+		Errors:   make([]string, 0),
+	}
+}
+
+// Count increments the number of imported records of given kind
+func (r *ImportReport) Count(kind string) {
+	r.Imported[kind]++
+}
+
+// Warn adds a message for records that were skipped, but do not prevent the import
+func (r *ImportReport) Warn(format string, a ...interface{}) {
+	r.Warnings = append(r.Warnings, fmt.Sprintf(format, a...))
+}
+
+// Error adds a message for problems that prevent the import
+func (r *ImportReport) Error(format string, a ...interface{}) {
+	r.Errors = append(r.Errors, fmt.Sprintf(format, a...))
+}
+
+// HasErrors returns true if there are any problems that prevent the import
+func (r *ImportReport) HasErrors() bool {
+	return len(r.Errors) > 0
+}
+
+// ImportUser identifies the author of an imported record
+type ImportUser struct {
+	Name  string
+	Email string
+}
+
+// ImportPost is a post read from an external source
+type ImportPost struct {
+	Line           int
+	Number         int
+	Title          string
+	Description    string
+	CreatedAt      time.Time
+	Author         ImportUser
+	Status         enum.PostStatus
+	Response       string
+	RespondedAt    time.Time
+	RespondedBy    ImportUser
+	OriginalNumber int
+	Tags           []string
+}
+
+// ImportComment is a comment read from an external source
+type ImportComment struct {
+	Line       int
+	PostNumber int
+	Content    string
+	CreatedAt  time.Time
+	Author     ImportUser
+}
+
+// ImportVote is a vote read from an external source
+type ImportVote struct {
+	Line       int
+	PostNumber int
+	CreatedAt  time.Time
+	Author     ImportUser
+	Credits    int
+}
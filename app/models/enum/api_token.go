@@ -0,0 +1,65 @@
+package enum
+
+//APIScope is a permission that can be granted to an API Token
+type APIScope string
+
+var (
+	//APIScopePostsRead allows reading posts, comments, votes and tags
+	APIScopePostsRead APIScope = "posts:read"
+	//APIScopePostsWrite allows creating, editing, responding, tagging, merging and deleting posts
+	APIScopePostsWrite APIScope = "posts:write"
+	//APIScopeCommentsWrite allows adding, editing and deleting comments
+	APIScopeCommentsWrite APIScope = "comments:write"
+	//APIScopeVotesWrite allows voting and subscribing to posts
+	APIScopeVotesWrite APIScope = "votes:write"
+	//APIScopeTagsWrite allows creating, editing and deleting tags
+	APIScopeTagsWrite APIScope = "tags:write"
+	//APIScopeUsersRead allows listing users
+	APIScopeUsersRead APIScope = "users:read"
+	//APIScopeUsersAdmin allows creating and inviting users and impersonating them with X-Fider-UserID
+	APIScopeUsersAdmin APIScope = "users:admin"
+	//APIScopeAuditRead allows reading the audit log of administrative and moderation actions
+	APIScopeAuditRead APIScope = "audit:read"
+	//APIScopeAnalyticsRead allows reading activity reports
+	APIScopeAnalyticsRead APIScope = "analytics:read"
+)
+
+//AllAPIScopes contains all possible API scopes
+var AllAPIScopes = []APIScope{
+	APIScopePostsRead,
+	APIScopePostsWrite,
+	APIScopeCommentsWrite,
+	APIScopeVotesWrite,
+	APIScopeTagsWrite,
+	APIScopeUsersRead,
+	APIScopeUsersAdmin,
+	APIScopeAuditRead,
+	APIScopeAnalyticsRead,
+}
+
+//LegacyAPIKeyScopes are granted to legacy API Keys, which have no scopes of their own.
+//They cover the API that existed before API Tokens, but not the endpoints that were added since
+var LegacyAPIKeyScopes = []APIScope{
+	APIScopePostsRead,
+	APIScopePostsWrite,
+	APIScopeCommentsWrite,
+	APIScopeVotesWrite,
+	APIScopeTagsWrite,
+	APIScopeUsersRead,
+	APIScopeUsersAdmin,
+}
+
+//IsValid returns true if given scope is a known API scope
+func (s APIScope) IsValid() bool {
+	for _, scope := range AllAPIScopes {
+		if scope == s {
+			return true
+		}
+	}
+	return false
+}
+
+//IsAdministratorOnly returns true if given scope can only be granted by administrators
+func (s APIScope) IsAdministratorOnly() bool {
+	return s == APIScopeUsersAdmin || s == APIScopeAuditRead
+}
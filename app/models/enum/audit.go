@@ -0,0 +1,102 @@
+package enum
+
+//AuditAction is the name of an administrative or moderation action recorded on the audit log
+type AuditAction string
+
+var (
+	//AuditActionUserRoleChanged is recorded when the role of a user is changed
+	AuditActionUserRoleChanged AuditAction = "user.role_changed"
+	//AuditActionUserBlocked is recorded when a user is blocked
+	AuditActionUserBlocked AuditAction = "user.blocked"
+	//AuditActionUserUnblocked is recorded when a user is unblocked
+	AuditActionUserUnblocked AuditAction = "user.unblocked"
+	//AuditActionAPIKeyRegenerated is recorded when a user regenerates their API Key
+	AuditActionAPIKeyRegenerated AuditAction = "user.apikey_regenerated"
+	//AuditActionPostStatusChanged is recorded when the status or response of a post is changed
+	AuditActionPostStatusChanged AuditAction = "post.status_changed"
+	//AuditActionPostDeleted is recorded when a post is deleted
+	AuditActionPostDeleted AuditAction = "post.deleted"
+	//AuditActionTagCreated is recorded when a new tag is created
+	AuditActionTagCreated AuditAction = "tag.created"
+	//AuditActionTagUpdated is recorded when a tag is edited
+	AuditActionTagUpdated AuditAction = "tag.updated"
+	//AuditActionTagDeleted is recorded when a tag is deleted
+	AuditActionTagDeleted AuditAction = "tag.deleted"
+	//AuditActionOAuthConfigSaved is recorded when a custom OAuth provider is created or edited
+	AuditActionOAuthConfigSaved AuditAction = "oauth.config_saved"
+	//AuditActionSAMLConfigSaved is recorded when the SAML configuration is saved
+	AuditActionSAMLConfigSaved AuditAction = "saml.config_saved"
+	//AuditActionSettingsUpdated is recorded when the general settings are changed
+	AuditActionSettingsUpdated AuditAction = "settings.general_updated"
+	//AuditActionAdvancedSettingsUpdated is recorded when the advanced settings are changed
+	AuditActionAdvancedSettingsUpdated AuditAction = "settings.advanced_updated"
+	//AuditActionPrivacySettingsUpdated is recorded when the privacy settings are changed
+	AuditActionPrivacySettingsUpdated AuditAction = "settings.privacy_updated"
+	//AuditActionVotingSettingsUpdated is recorded when the voting settings are changed
+	AuditActionVotingSettingsUpdated AuditAction = "settings.voting_updated"
+	//AuditActionAPITokenCreated is recorded when an API token is created
+	AuditActionAPITokenCreated AuditAction = "api_token.created"
+	//AuditActionAPITokenRevoked is recorded when an API token is revoked
+	AuditActionAPITokenRevoked AuditAction = "api_token.revoked"
+	//AuditActionWebhookCreated is recorded when a webhook is created
+	AuditActionWebhookCreated AuditAction = "webhook.created"
+	//AuditActionWebhookUpdated is recorded when a webhook is edited
+	AuditActionWebhookUpdated AuditAction = "webhook.updated"
+	//AuditActionWebhookDeleted is recorded when a webhook is deleted
+	AuditActionWebhookDeleted AuditAction = "webhook.deleted"
+	//AuditActionPostMerged is recorded when a post is merged into another one
+	AuditActionPostMerged AuditAction = "post.merged"
+	//AuditActionPostUnmerged is recorded when a merged post is split back from its original
+	AuditActionPostUnmerged AuditAction = "post.unmerged"
+	//AuditActionEmailTemplateSaved is recorded when a custom email template is saved
+	AuditActionEmailTemplateSaved AuditAction = "email_template.saved"
+	//AuditActionEmailTemplateReset is recorded when an email template is reset to its default
+	AuditActionEmailTemplateReset AuditAction = "email_template.reset"
+	//AuditActionDataImported is recorded when a CSV file is imported
+	AuditActionDataImported AuditAction = "data.imported"
+	//AuditActionBackupRestored is recorded when a backup is restored
+	AuditActionBackupRestored AuditAction = "data.backup_restored"
+	//AuditActionEmailSuppressionCleared is recorded when an address is removed from the email suppression list
+	AuditActionEmailSuppressionCleared AuditAction = "email_suppression.cleared"
+)
+
+//AllAuditActions contains all possible audit actions
+var AllAuditActions = []AuditAction{
+	AuditActionUserRoleChanged,
+	AuditActionUserBlocked,
+	AuditActionUserUnblocked,
+	AuditActionAPIKeyRegenerated,
+	AuditActionPostStatusChanged,
+	AuditActionPostDeleted,
+	AuditActionTagCreated,
+	AuditActionTagUpdated,
+	AuditActionTagDeleted,
+	AuditActionOAuthConfigSaved,
+	AuditActionSAMLConfigSaved,
+	AuditActionSettingsUpdated,
+	AuditActionAdvancedSettingsUpdated,
+	AuditActionPrivacySettingsUpdated,
+	AuditActionVotingSettingsUpdated,
+	AuditActionAPITokenCreated,
+	AuditActionAPITokenRevoked,
+	AuditActionWebhookCreated,
+	AuditActionWebhookUpdated,
+	AuditActionWebhookDeleted,
+	AuditActionPostMerged,
+	AuditActionPostUnmerged,
+	AuditActionEmailTemplateSaved,
+	AuditActionEmailTemplateReset,
+	AuditActionDataImported,
+	AuditActionBackupRestored,
+	AuditActionEmailSuppressionCleared,
+}
+
+//IsValid returns true if given action is a known audit action
+func (a AuditAction) IsValid() bool {
+	for _, action := range AllAuditActions {
+		if action == a {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,22 @@
+package enum
+
+//EmailDeliveryStatus is the last known status of an email sent to a recipient
+type EmailDeliveryStatus string
+
+var (
+	//EmailDeliveryStatusSent is used when the email was accepted by the provider
+	EmailDeliveryStatusSent EmailDeliveryStatus = "sent"
+	//EmailDeliveryStatusDelivered is used when the provider delivered the email to the recipient's server
+	EmailDeliveryStatusDelivered EmailDeliveryStatus = "delivered"
+	//EmailDeliveryStatusFailed is used when the email could not be delivered for a temporary reason
+	EmailDeliveryStatusFailed EmailDeliveryStatus = "failed"
+	//EmailDeliveryStatusBounced is used when the email was permanently rejected by the recipient's server
+	EmailDeliveryStatusBounced EmailDeliveryStatus = "bounced"
+	//EmailDeliveryStatusComplained is used when the recipient marked the email as spam
+	EmailDeliveryStatusComplained EmailDeliveryStatus = "complained"
+)
+
+//IsUndeliverable returns true if no other email should be sent to an address with this status
+func (s EmailDeliveryStatus) IsUndeliverable() bool {
+	return s == EmailDeliveryStatusBounced || s == EmailDeliveryStatusComplained
+}
@@ -14,9 +14,30 @@ var (
 	NotificationChannelEmail NotificationChannel = 2
 )
 
+//NotificationDigest represents how often email notifications of an event are delivered
+type NotificationDigest string
+
+var (
+	//NotificationDigestImmediate sends one email per event, as soon as it happens
+	NotificationDigestImmediate NotificationDigest = "immediate"
+	//NotificationDigestDaily sends a single email per day with all events
+	NotificationDigestDaily NotificationDigest = "daily"
+	//NotificationDigestWeekly sends a single email per week with all events
+	NotificationDigestWeekly NotificationDigest = "weekly"
+)
+
+//IsValid returns true if given value is a known digest frequency
+func (d NotificationDigest) IsValid() bool {
+	return d == NotificationDigestImmediate || d == NotificationDigestDaily || d == NotificationDigestWeekly
+}
+
+//TimezoneSettingsKeyName is the user settings key of the timezone used to schedule digests
+const TimezoneSettingsKeyName = "timezone"
+
 //NotificationEvent represents all possible notification events
 type NotificationEvent struct {
 	UserSettingsKeyName           string
+	DigestSettingsKeyName         string
 	DefaultSettingValue           string
 	RequiresSubscriptionUserRoles []Role
 	DefaultEnabledUserRoles       []Role
@@ -31,6 +52,7 @@ var (
 	//NotificationEventNewPost is triggered when a new post is posted
 	NotificationEventNewPost = NotificationEvent{
 		UserSettingsKeyName:           "event_notification_new_post",
+		DigestSettingsKeyName:         "event_digest_new_post",
 		DefaultSettingValue:           strconv.Itoa(int(NotificationChannelWeb | NotificationChannelEmail)),
 		RequiresSubscriptionUserRoles: []Role{},
 		DefaultEnabledUserRoles: []Role{
@@ -41,8 +63,9 @@ var (
 	}
 	//NotificationEventNewComment is triggered when a new comment is posted
 	NotificationEventNewComment = NotificationEvent{
-		UserSettingsKeyName: "event_notification_new_comment",
-		DefaultSettingValue: strconv.Itoa(int(NotificationChannelWeb | NotificationChannelEmail)),
+		UserSettingsKeyName:   "event_notification_new_comment",
+		DigestSettingsKeyName: "event_digest_new_comment",
+		DefaultSettingValue:   strconv.Itoa(int(NotificationChannelWeb | NotificationChannelEmail)),
 		RequiresSubscriptionUserRoles: []Role{
 			RoleVisitor,
 		},
@@ -54,8 +77,9 @@ var (
 	}
 	//NotificationEventChangeStatus is triggered when a new post has its status changed
 	NotificationEventChangeStatus = NotificationEvent{
-		UserSettingsKeyName: "event_notification_change_status",
-		DefaultSettingValue: strconv.Itoa(int(NotificationChannelWeb | NotificationChannelEmail)),
+		UserSettingsKeyName:   "event_notification_change_status",
+		DigestSettingsKeyName: "event_digest_change_status",
+		DefaultSettingValue:   strconv.Itoa(int(NotificationChannelWeb | NotificationChannelEmail)),
 		RequiresSubscriptionUserRoles: []Role{
 			RoleVisitor,
 		},
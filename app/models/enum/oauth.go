@@ -6,3 +6,10 @@ var (
 	//OAuthConfigEnabled is used to enable an OAuthConfig for public use
 	OAuthConfigEnabled = 2
 )
+
+var (
+	//OAuthProtocolOAuth2 is used for providers configured with explicit endpoints and JSON paths
+	OAuthProtocolOAuth2 = 1
+	//OAuthProtocolOIDC is used for OpenID Connect providers configured by their issuer URL
+	OAuthProtocolOIDC = 2
+)
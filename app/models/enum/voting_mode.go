@@ -0,0 +1,32 @@
+package enum
+
+//VotingMode is the way users vote on posts of a tenant
+type VotingMode int
+
+var (
+	//VotingModeSimple allows users to give a single vote to any number of posts
+	VotingModeSimple VotingMode = 1
+	//VotingModeBudgeted gives users a limited budget of credits to distribute across posts
+	VotingModeBudgeted VotingMode = 2
+)
+
+var votingModeIDs = map[VotingMode]string{
+	VotingModeSimple:   "simple",
+	VotingModeBudgeted: "budgeted",
+}
+
+var votingModeName = map[string]VotingMode{
+	"simple":   VotingModeSimple,
+	"budgeted": VotingModeBudgeted,
+}
+
+// MarshalText returns the Text version of the voting mode
+func (mode VotingMode) MarshalText() ([]byte, error) {
+	return []byte(votingModeIDs[mode]), nil
+}
+
+// UnmarshalText parse string into a voting mode
+func (mode *VotingMode) UnmarshalText(text []byte) error {
+	*mode = votingModeName[string(text)]
+	return nil
+}
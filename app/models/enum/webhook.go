@@ -0,0 +1,42 @@
+package enum
+
+//WebhookEvent is the name of an event that can be delivered to a webhook
+type WebhookEvent string
+
+var (
+	//WebhookEventPostCreated is triggered when a new post is created
+	WebhookEventPostCreated WebhookEvent = "post.created"
+	//WebhookEventPostStatusChanged is triggered when a post response or status is changed
+	WebhookEventPostStatusChanged WebhookEvent = "post.status_changed"
+	//WebhookEventCommentCreated is triggered when a new comment is added to a post
+	WebhookEventCommentCreated WebhookEvent = "comment.created"
+	//WebhookEventCommentUpdated is triggered when a comment is edited
+	WebhookEventCommentUpdated WebhookEvent = "comment.updated"
+	//WebhookEventCommentDeleted is triggered when a comment is deleted
+	WebhookEventCommentDeleted WebhookEvent = "comment.deleted"
+	//WebhookEventVoteAdded is triggered when a user votes on a post
+	WebhookEventVoteAdded WebhookEvent = "vote.added"
+	//WebhookEventVoteRemoved is triggered when a user removes a vote from a post
+	WebhookEventVoteRemoved WebhookEvent = "vote.removed"
+)
+
+//AllWebhookEvents contains all possible webhook events
+var AllWebhookEvents = []WebhookEvent{
+	WebhookEventPostCreated,
+	WebhookEventPostStatusChanged,
+	WebhookEventCommentCreated,
+	WebhookEventCommentUpdated,
+	WebhookEventCommentDeleted,
+	WebhookEventVoteAdded,
+	WebhookEventVoteRemoved,
+}
+
+//IsValid returns true if given event is a known webhook event
+func (e WebhookEvent) IsValid() bool {
+	for _, event := range AllWebhookEvents {
+		if event == e {
+			return true
+		}
+	}
+	return false
+}
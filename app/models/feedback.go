@@ -14,9 +14,12 @@ type Post struct {
 	Slug          string          `json:"slug"`
 	Description   string          `json:"description"`
 	CreatedAt     time.Time       `json:"createdAt"`
+	UpdatedAt     time.Time       `json:"updatedAt"`
 	User          *User           `json:"user"`
 	HasVoted      bool            `json:"hasVoted"`
 	VotesCount    int             `json:"votesCount"`
+	Score         int             `json:"score"`
+	VotedCredits  int             `json:"votedCredits"`
 	CommentsCount int             `json:"commentsCount"`
 	Status        enum.PostStatus `json:"status"`
 	Response      *PostResponse   `json:"response,omitempty"`
@@ -34,19 +37,48 @@ type VoteUser struct {
 //Vote represents a vote given by a user on a post
 type Vote struct {
 	User      *VoteUser `json:"user"`
+	Credits   int       `json:"credits"`
 	CreatedAt time.Time `json:"createdAt"`
 }
 
+//PostStatusChange represents the moment a post has moved to a new status
+type PostStatusChange struct {
+	Status    enum.PostStatus `json:"status"`
+	ChangedAt time.Time       `json:"changedAt"`
+}
+
+//RoadmapPost is a post shown on the roadmap along with its status timeline
+type RoadmapPost struct {
+	Post    *Post               `json:"post"`
+	History []*PostStatusChange `json:"history"`
+}
+
+//RoadmapColumn groups all roadmap posts that share the same status
+type RoadmapColumn struct {
+	Status enum.PostStatus `json:"status"`
+	Posts  []*RoadmapPost  `json:"posts"`
+}
+
 // CanBeVoted returns true if this post can have its vote changed
 func (i *Post) CanBeVoted() bool {
 	return i.Status != enum.PostCompleted && i.Status != enum.PostDeclined && i.Status != enum.PostDuplicate
 }
 
+//MaxVoteCredits is the maximum number of credits a user can place on a single post when budgeted voting is enabled
+const MaxVoteCredits = 3
+
+// AddVote represents a request to vote on a post
+type AddVote struct {
+	Number  int `route:"number"`
+	Credits int `json:"credits"`
+}
+
 // NewPost represents a new post
 type NewPost struct {
 	Title       string         `json:"title"`
 	Description string         `json:"description"`
 	Attachments []*ImageUpload `json:"attachments"`
+	Files       []*FileUpload  `json:"files"`
 }
 
 // UpdatePost represents a request to edit an existing post
@@ -55,6 +87,7 @@ type UpdatePost struct {
 	Title       string         `json:"title"`
 	Description string         `json:"description"`
 	Attachments []*ImageUpload `json:"attachments"`
+	Files       []*FileUpload  `json:"files"`
 }
 
 // DeletePost represents a request to delete an existing post
@@ -68,6 +101,7 @@ type NewComment struct {
 	Number      int            `route:"number"`
 	Content     string         `json:"content"`
 	Attachments []*ImageUpload `json:"attachments"`
+	Files       []*FileUpload  `json:"files"`
 }
 
 // EditComment represents a request to edit existing comment
@@ -76,6 +110,7 @@ type EditComment struct {
 	ID          int            `route:"id"`
 	Content     string         `json:"content"`
 	Attachments []*ImageUpload `json:"attachments"`
+	Files       []*FileUpload  `json:"files"`
 }
 
 // DeleteComment represents a request to delete an existing comment
@@ -92,6 +127,25 @@ type SetResponse struct {
 	OriginalNumber int             `json:"originalNumber"`
 }
 
+// MergePost represents a request to merge a post into another
+type MergePost struct {
+	Number         int `route:"number"`
+	OriginalNumber int `json:"originalNumber"`
+}
+
+// UnmergePost represents a request to revert a previous merge
+type UnmergePost struct {
+	Number int `route:"number"`
+}
+
+//PostMerge records a post that has been merged into another, so that it can be reverted
+type PostMerge struct {
+	ID         int       `json:"id"`
+	PostID     int       `json:"postId"`
+	OriginalID int       `json:"originalId"`
+	MergedAt   time.Time `json:"mergedAt"`
+}
+
 //PostResponse is a staff response to a given post
 type PostResponse struct {
 	Text        string        `json:"text"`
@@ -110,13 +164,24 @@ type OriginalPost struct {
 
 //Comment represents an user comment on an post
 type Comment struct {
-	ID          int        `json:"id"`
-	Content     string     `json:"content"`
-	CreatedAt   time.Time  `json:"createdAt"`
-	User        *User      `json:"user"`
-	Attachments []string   `json:"attachments,omitempty"`
-	EditedAt    *time.Time `json:"editedAt,omitempty"`
-	EditedBy    *User      `json:"editedBy,omitempty"`
+	ID          int               `json:"id"`
+	Content     string            `json:"content"`
+	CreatedAt   time.Time         `json:"createdAt"`
+	User        *User             `json:"user"`
+	Attachments []string          `json:"attachments,omitempty"`
+	Files       []*FileAttachment `json:"files,omitempty"`
+	EditedAt    *time.Time        `json:"editedAt,omitempty"`
+	EditedBy    *User             `json:"editedBy,omitempty"`
+	MergedFrom  int               `json:"mergedFrom,omitempty"`
+}
+
+//FileAttachment is a file (other than an image) attached to a post or comment
+type FileAttachment struct {
+	BlobKey     string `json:"bkey"`
+	FileName    string `json:"fileName"`
+	ContentType string `json:"contentType"`
+	Size        int    `json:"size"`
+	CommentID   int    `json:"commentID,omitempty"`
 }
 
 //Tag represents a simple tag
@@ -10,17 +10,22 @@ import (
 
 //Tenant represents a tenant
 type Tenant struct {
-	ID             int            `json:"id"`
-	Name           string         `json:"name"`
-	Subdomain      string         `json:"subdomain"`
-	Invitation     string         `json:"invitation"`
-	WelcomeMessage string         `json:"welcomeMessage"`
-	CNAME          string         `json:"cname"`
-	Status         int            `json:"status"`
-	IsPrivate      bool           `json:"isPrivate"`
-	LogoBlobKey    string         `json:"logoBlobKey"`
-	Billing        *TenantBilling `json:"billing,omitempty"`
-	CustomCSS      string         `json:"-"`
+	ID               int             `json:"id"`
+	Name             string          `json:"name"`
+	Subdomain        string          `json:"subdomain"`
+	Invitation       string          `json:"invitation"`
+	WelcomeMessage   string          `json:"welcomeMessage"`
+	CNAME            string          `json:"cname"`
+	Status           int             `json:"status"`
+	IsPrivate        bool            `json:"isPrivate"`
+	IsRoadmapPublic  bool            `json:"isRoadmapPublic"`
+	VotingMode       enum.VotingMode `json:"votingMode"`
+	VoteBudget       int             `json:"voteBudget"`
+	LogoBlobKey      string          `json:"logoBlobKey"`
+	Billing          *TenantBilling  `json:"billing,omitempty"`
+	CustomCSS        string          `json:"-"`
+	Locale           string          `json:"locale"`
+	AllowedFileTypes string          `json:"allowedFileTypes"`
 }
 
 //TenantBilling has all the billing information of given tenant
@@ -51,6 +56,7 @@ type User struct {
 	AvatarType    enum.AvatarType `json:"-"`
 	AvatarURL     string          `json:"avatarURL,omitempty"`
 	Status        enum.UserStatus `json:"status"`
+	Locale        string          `json:"-"`
 }
 
 //HasProvider returns true if current user has registered with given provider
@@ -79,6 +85,47 @@ type UserProvider struct {
 	UID  string
 }
 
+//APITokenPrefix is the prefix of all API Tokens, which sets them apart from legacy API Keys
+const APITokenPrefix = "fider_"
+
+//APIToken is a named credential that gives scoped access to the API on behalf of an user
+type APIToken struct {
+	ID         int             `json:"id"`
+	Name       string          `json:"name"`
+	User       *User           `json:"user"`
+	Scopes     []enum.APIScope `json:"scopes"`
+	ExpiresAt  *time.Time      `json:"expiresAt,omitempty"`
+	LastUsedAt *time.Time      `json:"lastUsedAt,omitempty"`
+	CreatedAt  time.Time       `json:"createdAt"`
+}
+
+//HasScope returns true if token has been granted given scope
+func (t *APIToken) HasScope(scope enum.APIScope) bool {
+	for _, s := range t.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+//IsExpired returns true if token can no longer be used
+func (t *APIToken) IsExpired() bool {
+	return t.ExpiresAt != nil && !time.Now().Before(*t.ExpiresAt)
+}
+
+//CreateAPIToken is the input model used to create an API Token
+type CreateAPIToken struct {
+	Name          string          `json:"name"`
+	Scopes        []enum.APIScope `json:"scopes"`
+	ExpiresInDays int             `json:"expiresInDays"`
+}
+
+//RevokeAPIToken is the input model used to revoke an API Token
+type RevokeAPIToken struct {
+	ID int `route:"id"`
+}
+
 //CreateTenant is the input model used to create a tenant
 type CreateTenant struct {
 	Token           string `json:"token"`
@@ -118,11 +165,13 @@ type UpdateTenantSettings struct {
 	Invitation     string       `json:"invitation"`
 	WelcomeMessage string       `json:"welcomeMessage"`
 	CNAME          string       `json:"cname" format:"lower"`
+	Locale         string       `json:"locale"`
 }
 
 //UpdateTenantAdvancedSettings is the input model used to update tenant advanced settings
 type UpdateTenantAdvancedSettings struct {
-	CustomCSS string `json:"customCSS"`
+	CustomCSS        string `json:"customCSS"`
+	AllowedFileTypes string `json:"allowedFileTypes"`
 }
 
 //ImageUpload is the input model used to upload/remove an image
@@ -139,9 +188,37 @@ type ImageUploadData struct {
 	Content     []byte `json:"content"`
 }
 
+//FileUpload is the input model used to upload/remove a file attachment
+type FileUpload struct {
+	BlobKey string          `json:"bkey"`
+	Upload  *FileUploadData `json:"upload"`
+	Remove  bool            `json:"remove"`
+}
+
+//FileUploadData is the input model used to upload a new file
+type FileUploadData struct {
+	FileName    string `json:"fileName"`
+	ContentType string `json:"contentType"`
+	Content     []byte `json:"content"`
+}
+
 //UpdateTenantPrivacy is the input model used to update tenant privacy settings
 type UpdateTenantPrivacy struct {
-	IsPrivate bool `json:"isPrivate"`
+	IsPrivate       bool `json:"isPrivate"`
+	IsRoadmapPublic bool `json:"isRoadmapPublic"`
+}
+
+//ImportData is the input model used to import a backup.zip or CSV file into current tenant
+type ImportData struct {
+	Kind    string `json:"kind"`
+	DryRun  bool   `json:"dryRun"`
+	Content []byte `json:"content"`
+}
+
+//UpdateTenantVoting is the input model used to update tenant voting settings
+type UpdateTenantVoting struct {
+	Mode   enum.VotingMode `json:"mode"`
+	Budget int             `json:"budget"`
 }
 
 //SignInByEmail is the input model when user request to sign in by email
@@ -255,6 +332,7 @@ type UpdateUserSettings struct {
 	Name       string            `json:"name"`
 	AvatarType enum.AvatarType   `json:"avatarType"`
 	Avatar     *ImageUpload      `json:"avatar"`
+	Locale     string            `json:"locale"`
 	Settings   map[string]string `json:"settings"`
 }
 
@@ -282,3 +360,8 @@ type InviteUsers struct {
 func GenerateSecretKey() string {
 	return rand.String(64)
 }
+
+// GenerateAPITokenKey returns a new key for an API Token
+func GenerateAPITokenKey() string {
+	return APITokenPrefix + rand.String(48)
+}
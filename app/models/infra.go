@@ -2,7 +2,11 @@ package models
 
 import (
 	"encoding/json"
+	"strings"
 	"time"
+
+	"github.com/getfider/fider/app/models/dto"
+	"github.com/getfider/fider/app/models/enum"
 )
 
 // SystemSettings is the system-wide settings
@@ -26,15 +30,65 @@ type Notification struct {
 	CreatedAt time.Time `json:"createdAt" db:"created_at"`
 }
 
+// InboundEmail is an email received by Fider, usually a reply to a notification
+type InboundEmail struct {
+	From          string
+	To            []string
+	Subject       string
+	Text          string
+	AutoSubmitted bool
+}
+
+// DigestItem is an email notification waiting to be delivered on the next digest of an user
+type DigestItem struct {
+	ID        int
+	User      *User
+	Event     string
+	Frequency enum.NotificationDigest
+	Timezone  string
+	Title     string
+	Link      string
+	BaseURL   string
+	CreatedAt time.Time
+}
+
+// digestHour is the local hour of the day when digests are delivered
+const digestHour = 8
+
+// DueAt returns when this item should be delivered, based on the frequency and timezone chosen by its user
+// Daily digests are sent every morning and weekly digests every monday morning
+func (i *DigestItem) DueAt() time.Time {
+	loc, err := time.LoadLocation(i.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	created := i.CreatedAt.In(loc)
+	due := time.Date(created.Year(), created.Month(), created.Day(), digestHour, 0, 0, 0, loc)
+	if !due.After(created) {
+		due = due.AddDate(0, 0, 1)
+	}
+
+	if i.Frequency == enum.NotificationDigestWeekly {
+		for due.Weekday() != time.Monday {
+			due = due.AddDate(0, 0, 1)
+		}
+	}
+
+	return due
+}
+
 // CreateEditOAuthConfig is used to create/edit an OAuth Configuration
 type CreateEditOAuthConfig struct {
 	ID                int
 	Logo              *ImageUpload `json:"logo"`
 	Provider          string       `json:"provider"`
+	Protocol          int          `json:"protocol"`
 	Status            int          `json:"status"`
 	DisplayName       string       `json:"displayName"`
 	ClientID          string       `json:"clientID"`
 	ClientSecret      string       `json:"clientSecret"`
+	IssuerURL         string       `json:"issuerURL"`
 	AuthorizeURL      string       `json:"authorizeURL" format:"lower"`
 	TokenURL          string       `json:"tokenURL" format:"lower"`
 	Scope             string       `json:"scope"`
@@ -48,11 +102,13 @@ type CreateEditOAuthConfig struct {
 type OAuthConfig struct {
 	ID                int
 	Provider          string
+	Protocol          int
 	DisplayName       string
 	LogoBlobKey       string
 	Status            int
 	ClientID          string
 	ClientSecret      string
+	IssuerURL         string
 	AuthorizeURL      string
 	TokenURL          string
 	ProfileURL        string
@@ -71,11 +127,13 @@ func (o OAuthConfig) MarshalJSON() ([]byte, error) {
 	return json.Marshal(map[string]interface{}{
 		"id":                o.ID,
 		"provider":          o.Provider,
+		"protocol":          o.Protocol,
 		"displayName":       o.DisplayName,
 		"logoBlobKey":       o.LogoBlobKey,
 		"status":            o.Status,
 		"clientID":          o.ClientID,
 		"clientSecret":      secret,
+		"issuerURL":         o.IssuerURL,
 		"authorizeURL":      o.AuthorizeURL,
 		"tokenURL":          o.TokenURL,
 		"profileURL":        o.ProfileURL,
@@ -98,3 +156,208 @@ type Event struct {
 	Name      string    `json:"name"`
 	CreatedAt time.Time `json:"createdAt"`
 }
+
+// AuditLog is an administrative or moderation action performed on a tenant
+type AuditLog struct {
+	ID         int              `json:"id"`
+	Action     enum.AuditAction `json:"action"`
+	Actor      *User            `json:"actor,omitempty"`
+	TargetType string           `json:"targetType"`
+	TargetID   int              `json:"targetID,omitempty"`
+	TargetName string           `json:"targetName,omitempty"`
+	Before     dto.Props        `json:"before,omitempty"`
+	After      dto.Props        `json:"after,omitempty"`
+	ClientIP   string           `json:"clientIP"`
+	CreatedAt  time.Time        `json:"createdAt"`
+}
+
+// Webhook is an URL that receives signed event payloads from Fider
+type Webhook struct {
+	ID        int                 `json:"id"`
+	Name      string              `json:"name"`
+	URL       string              `json:"url"`
+	Secret    string              `json:"secret"`
+	Events    []enum.WebhookEvent `json:"events"`
+	IsActive  bool                `json:"isActive"`
+	CreatedAt time.Time           `json:"createdAt"`
+}
+
+// CreateEditWebhook is used to create/edit a webhook
+type CreateEditWebhook struct {
+	ID       int                 `route:"id"`
+	Name     string              `json:"name"`
+	URL      string              `json:"url"`
+	Events   []enum.WebhookEvent `json:"events"`
+	IsActive bool                `json:"isActive"`
+}
+
+// DeleteWebhook is used to delete an existing webhook
+type DeleteWebhook struct {
+	ID int `route:"id"`
+}
+
+// WebhookDelivery is a single attempt to deliver an event to a webhook
+type WebhookDelivery struct {
+	ID          int               `json:"id"`
+	Event       enum.WebhookEvent `json:"event"`
+	Attempt     int               `json:"attempt"`
+	StatusCode  int               `json:"statusCode"`
+	Success     bool              `json:"success"`
+	Error       string            `json:"error,omitempty"`
+	Payload     string            `json:"payload"`
+	DurationMs  int               `json:"durationMs"`
+	DeliveredAt time.Time         `json:"deliveredAt"`
+}
+
+// SAMLConfig is the SAML single sign-on configuration of a tenant
+type SAMLConfig struct {
+	IsEnabled          bool   `json:"isEnabled"`
+	DisplayName        string `json:"displayName"`
+	IdPMetadata        string `json:"idpMetadata"`
+	IdPEntityID        string `json:"idpEntityID"`
+	IdPSSOURL          string `json:"idpSSOURL"`
+	NameAttribute      string `json:"nameAttribute"`
+	EmailAttribute     string `json:"emailAttribute"`
+	RoleAttribute      string `json:"roleAttribute"`
+	AdministratorRoles string `json:"administratorRoles"`
+	CollaboratorRoles  string `json:"collaboratorRoles"`
+}
+
+// MapRole returns the role of users with given values on the role attribute
+// It returns false when role mapping is not configured
+func (c *SAMLConfig) MapRole(values []string) (enum.Role, bool) {
+	if c.RoleAttribute == "" {
+		return enum.RoleVisitor, false
+	}
+
+	has := func(roles string) bool {
+		for _, role := range strings.Split(roles, ",") {
+			role = strings.TrimSpace(role)
+			for _, value := range values {
+				if role != "" && strings.EqualFold(role, value) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	if has(c.AdministratorRoles) {
+		return enum.RoleAdministrator, true
+	}
+	if has(c.CollaboratorRoles) {
+		return enum.RoleCollaborator, true
+	}
+	return enum.RoleVisitor, true
+}
+
+// CreateEditSAMLConfig is used to create/edit the SAML configuration of a tenant
+type CreateEditSAMLConfig struct {
+	IsEnabled          bool   `json:"isEnabled"`
+	DisplayName        string `json:"displayName"`
+	IdPMetadata        string `json:"idpMetadata"`
+	IdPEntityID        string `json:"-"`
+	IdPSSOURL          string `json:"-"`
+	NameAttribute      string `json:"nameAttribute"`
+	EmailAttribute     string `json:"emailAttribute"`
+	RoleAttribute      string `json:"roleAttribute"`
+	AdministratorRoles string `json:"administratorRoles"`
+	CollaboratorRoles  string `json:"collaboratorRoles"`
+}
+
+// EmailTemplate is the subject and body used to render an email
+type EmailTemplate struct {
+	Name         string   `json:"name"`
+	Subject      string   `json:"subject"`
+	Body         string   `json:"body"`
+	Variables    []string `json:"variables"`
+	IsCustomized bool     `json:"isCustomized"`
+}
+
+// SaveEmailTemplate is used to customize the subject and body of an email template
+type SaveEmailTemplate struct {
+	Name    string `route:"name"`
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+}
+
+// EmailDelivery is the delivery status of an email sent to a single recipient
+type EmailDelivery struct {
+	ID           int                      `json:"id"`
+	MessageID    string                   `json:"messageID"`
+	Provider     string                   `json:"provider"`
+	TemplateName string                   `json:"templateName"`
+	Address      string                   `json:"address"`
+	Status       enum.EmailDeliveryStatus `json:"status"`
+	Reason       string                   `json:"reason,omitempty"`
+	CreatedAt    time.Time                `json:"createdAt"`
+	UpdatedAt    time.Time                `json:"updatedAt"`
+}
+
+// EmailSuppression is an address that no longer receives emails from a tenant because of a bounce or spam complaint
+type EmailSuppression struct {
+	Address   string                   `json:"address"`
+	Reason    enum.EmailDeliveryStatus `json:"reason"`
+	Detail    string                   `json:"detail,omitempty"`
+	CreatedAt time.Time                `json:"createdAt"`
+}
+
+// AnalyticsPoint is the value of a metric within a single interval of a time-series
+type AnalyticsPoint struct {
+	Date  time.Time `json:"date"`
+	Count int       `json:"count"`
+}
+
+// AnalyticsSeries contains the time-series of activity metrics of a tenant
+type AnalyticsSeries struct {
+	Posts         []*AnalyticsPoint                     `json:"posts"`
+	Votes         []*AnalyticsPoint                     `json:"votes"`
+	Comments      []*AnalyticsPoint                     `json:"comments"`
+	ActiveUsers   []*AnalyticsPoint                     `json:"activeUsers"`
+	SignUps       []*AnalyticsPoint                     `json:"signUps"`
+	StatusChanges map[enum.PostStatus][]*AnalyticsPoint `json:"statusChanges"`
+	Events        map[string][]*AnalyticsPoint          `json:"events"`
+}
+
+// AnalyticsPost is a post ranked by the activity it received within a period
+type AnalyticsPost struct {
+	Number   int             `json:"number"`
+	Title    string          `json:"title"`
+	Slug     string          `json:"slug"`
+	Status   enum.PostStatus `json:"status"`
+	Votes    int             `json:"votes"`
+	Comments int             `json:"comments"`
+}
+
+// AnalyticsResponseTime measures how long it took for staff to first respond to posts created within a period
+type AnalyticsResponseTime struct {
+	Responded      int     `json:"responded"`
+	Unresponded    int     `json:"unresponded"`
+	AverageSeconds float64 `json:"averageSeconds"`
+	MedianSeconds  float64 `json:"medianSeconds"`
+}
+
+// AnalyticsTag is the activity of posts with a given tag within a period
+type AnalyticsTag struct {
+	Name     string `json:"name"`
+	Slug     string `json:"slug"`
+	Color    string `json:"color"`
+	Posts    int    `json:"posts"`
+	Votes    int    `json:"votes"`
+	Comments int    `json:"comments"`
+}
+
+// Analytics is the activity report of a tenant within a period
+type Analytics struct {
+	Since        time.Time              `json:"since"`
+	Until        time.Time              `json:"until"`
+	Interval     string                 `json:"interval"`
+	Series       *AnalyticsSeries       `json:"series"`
+	TopVoted     []*AnalyticsPost       `json:"topVoted"`
+	Trending     []*AnalyticsPost       `json:"trending"`
+	ResponseTime *AnalyticsResponseTime `json:"responseTime"`
+	Tags         []*AnalyticsTag        `json:"tags"`
+}
+
+// AnalyticsIntervals are the possible intervals of analytics time-series
+var AnalyticsIntervals = []string{"day", "week", "month"}
@@ -0,0 +1,40 @@
+package models_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/models/enum"
+	. "github.com/getfider/fider/app/pkg/assert"
+)
+
+func TestDigestItem_DueAt(t *testing.T) {
+	RegisterT(t)
+
+	saoPaulo, _ := time.LoadLocation("America/Sao_Paulo")
+
+	testCases := []struct {
+		frequency enum.NotificationDigest
+		timezone  string
+		createdAt time.Time
+		dueAt     time.Time
+	}{
+		{enum.NotificationDigestDaily, "", time.Date(2019, 7, 3, 7, 0, 0, 0, time.UTC), time.Date(2019, 7, 3, 8, 0, 0, 0, time.UTC)},
+		{enum.NotificationDigestDaily, "", time.Date(2019, 7, 3, 8, 0, 0, 0, time.UTC), time.Date(2019, 7, 4, 8, 0, 0, 0, time.UTC)},
+		{enum.NotificationDigestDaily, "invalid", time.Date(2019, 7, 3, 9, 0, 0, 0, time.UTC), time.Date(2019, 7, 4, 8, 0, 0, 0, time.UTC)},
+		{enum.NotificationDigestDaily, "America/Sao_Paulo", time.Date(2019, 7, 3, 10, 0, 0, 0, time.UTC), time.Date(2019, 7, 3, 8, 0, 0, 0, saoPaulo)},
+		{enum.NotificationDigestWeekly, "", time.Date(2019, 7, 3, 9, 0, 0, 0, time.UTC), time.Date(2019, 7, 8, 8, 0, 0, 0, time.UTC)},
+		{enum.NotificationDigestWeekly, "", time.Date(2019, 7, 8, 7, 0, 0, 0, time.UTC), time.Date(2019, 7, 8, 8, 0, 0, 0, time.UTC)},
+		{enum.NotificationDigestWeekly, "America/Sao_Paulo", time.Date(2019, 7, 8, 12, 0, 0, 0, time.UTC), time.Date(2019, 7, 15, 8, 0, 0, 0, saoPaulo)},
+	}
+
+	for _, testCase := range testCases {
+		item := &models.DigestItem{
+			Frequency: testCase.frequency,
+			Timezone:  testCase.timezone,
+			CreatedAt: testCase.createdAt,
+		}
+		Expect(item.DueAt().Equal(testCase.dueAt)).IsTrue()
+	}
+}
@@ -0,0 +1,15 @@
+package query
+
+import (
+	"time"
+
+	"github.com/getfider/fider/app/models"
+)
+
+type GetAnalytics struct {
+	Since    time.Time
+	Until    time.Time
+	Interval string
+
+	Result *models.Analytics
+}
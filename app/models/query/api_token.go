@@ -0,0 +1,23 @@
+package query
+
+import "github.com/getfider/fider/app/models"
+
+type GetAPITokenByKey struct {
+	Key string
+
+	Result *models.APIToken
+}
+
+type GetAPITokenByID struct {
+	TokenID int
+
+	Result *models.APIToken
+}
+
+type ListCurrentUserAPITokens struct {
+	Result []*models.APIToken
+}
+
+type ListAllAPITokens struct {
+	Result []*models.APIToken
+}
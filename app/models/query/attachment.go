@@ -8,3 +8,26 @@ type GetAttachments struct {
 
 	Result []string
 }
+
+type GetFileAttachments struct {
+	Post    *models.Post
+	Comment *models.Comment
+
+	Result []*models.FileAttachment
+}
+
+type ListPostFileAttachments struct {
+	Post *models.Post
+
+	Result []*models.FileAttachment
+}
+
+type GetFileAttachmentByKey struct {
+	BlobKey string
+
+	Result *models.FileAttachment
+}
+
+type GetFileAttachmentsSize struct {
+	Result int
+}
@@ -0,0 +1,18 @@
+package query
+
+import (
+	"time"
+
+	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/models/enum"
+)
+
+type ListAuditLogs struct {
+	ActorID       int
+	Action        enum.AuditAction
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+	Limit         int
+
+	Result []*models.AuditLog
+}
@@ -6,8 +6,16 @@ type ListBlobs struct {
 	Result []string
 }
 
+type ListBlobsMetadata struct {
+	Result []*dto.BlobMetadata
+}
+
 type GetBlobByKey struct {
 	Key string
 
 	Result *dto.Blob
 }
+
+type GetReferencedBlobKeys struct {
+	Result []string
+}
@@ -1,6 +1,10 @@
 package query
 
-import "github.com/getfider/fider/app/models"
+import (
+	"time"
+
+	"github.com/getfider/fider/app/models"
+)
 
 type GetCommentByID struct {
 	CommentID int
@@ -9,7 +13,14 @@ type GetCommentByID struct {
 }
 
 type GetCommentsByPost struct {
-	Post *models.Post
+	Post          *models.Post
+	AuthorID      int
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+	Order         string
+	Limit         int
+	Cursor        string
 
-	Result []*models.Comment
+	Result     []*models.Comment
+	NextCursor string
 }
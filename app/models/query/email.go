@@ -0,0 +1,13 @@
+package query
+
+import "github.com/getfider/fider/app/models"
+
+type IsEmailSuppressed struct {
+	Address string
+
+	Result bool
+}
+
+type ListEmailSuppressions struct {
+	Result []*models.EmailSuppression
+}
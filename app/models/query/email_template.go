@@ -0,0 +1,13 @@
+package query
+
+import "github.com/getfider/fider/app/models"
+
+type GetCustomEmailTemplate struct {
+	Name string
+
+	Result *models.EmailTemplate
+}
+
+type ListCustomEmailTemplates struct {
+	Result []*models.EmailTemplate
+}
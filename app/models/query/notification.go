@@ -25,3 +25,11 @@ type GetActiveSubscribers struct {
 
 	Result []*models.User
 }
+
+type GetTenantsWithPendingDigest struct {
+	Result []*models.Tenant
+}
+
+type GetPendingDigestItems struct {
+	Result []*models.DigestItem
+}
@@ -24,15 +24,17 @@ type GetOAuthAuthorizationURL struct {
 }
 
 type GetOAuthProfile struct {
-	Provider string
-	Code     string
+	Provider   string
+	Code       string
+	Identifier string
 
 	Result *dto.OAuthUserProfile
 }
 
 type GetOAuthRawProfile struct {
-	Provider string
-	Code     string
+	Provider   string
+	Code       string
+	Identifier string
 
 	Result string
 }
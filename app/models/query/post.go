@@ -1,6 +1,8 @@
 package query
 
 import (
+	"time"
+
 	"github.com/getfider/fider/app/models"
 	"github.com/getfider/fider/app/models/enum"
 )
@@ -34,14 +36,38 @@ type GetPostByNumber struct {
 }
 
 type SearchPosts struct {
-	Query string
-	View  string
-	Limit string
-	Tags  []string
+	Query         string
+	View          string
+	Limit         string
+	Tags          []string
+	MatchAnyTag   bool
+	Statuses      []enum.PostStatus
+	AuthorID      int
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+	UpdatedAfter  time.Time
+	UpdatedBefore time.Time
+	Sort          string
+	Order         string
+	Cursor        string
 
-	Result []*models.Post
+	Result     []*models.Post
+	NextCursor string
+}
+
+type GetRoadmap struct {
+	Tags        []string
+	MatchAnyTag bool
+
+	Result []*models.RoadmapColumn
 }
 
 type GetAllPosts struct {
 	Result []*models.Post
 }
+
+type GetActivePostMerge struct {
+	PostID int
+
+	Result *models.PostMerge
+}
@@ -0,0 +1,7 @@
+package query
+
+import "github.com/getfider/fider/app/models"
+
+type GetSAMLConfig struct {
+	Result *models.SAMLConfig
+}
@@ -33,3 +33,13 @@ type GetTenantByDomain struct {
 
 	Result *models.Tenant
 }
+
+type GetTenantByID struct {
+	TenantID int
+
+	Result *models.Tenant
+}
+
+type GetAllTenants struct {
+	Result []*models.Tenant
+}
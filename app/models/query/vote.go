@@ -8,3 +8,9 @@ type ListPostVotes struct {
 
 	Result []*models.Vote
 }
+
+type GetUsedVoteCredits struct {
+	ExcludePostID int
+
+	Result int
+}
@@ -0,0 +1,29 @@
+package query
+
+import (
+	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/models/enum"
+)
+
+type ListWebhooks struct {
+	Result []*models.Webhook
+}
+
+type ListActiveWebhooksByEvent struct {
+	Event enum.WebhookEvent
+
+	Result []*models.Webhook
+}
+
+type GetWebhookByID struct {
+	WebhookID int
+
+	Result *models.Webhook
+}
+
+type ListWebhookDeliveries struct {
+	WebhookID int
+	Limit     int
+
+	Result []*models.WebhookDelivery
+}
@@ -20,13 +20,18 @@ func Create(ctx context.Context) (*bytes.Buffer, error) {
 	for _, tableName := range []string{
 		"attachments",
 		"comments",
+		"email_suppressions",
+		"email_templates",
 		"email_verifications",
+		"file_attachments",
 		"notifications",
 		"oauth_providers",
 		"posts",
+		"post_status_history",
 		"post_subscribers",
 		"post_tags",
 		"post_votes",
+		"saml_configs",
 		"tags",
 		"tenants",
 		"user_providers",
@@ -0,0 +1,520 @@
+package backup
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/dto"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/dbx"
+	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/getfider/fider/app/services/blob"
+)
+
+type row map[string]interface{}
+
+type tableSpec struct {
+	name     string
+	columns  []string
+	refs     map[string]string
+	returnID bool
+}
+
+//Tables are restored in this order, so that references are always known when needed
+//notifications and email_verifications are transient and not restored
+var restoreOrder = []tableSpec{
+	{
+		name:    "user_providers",
+		columns: []string{"provider", "provider_uid", "created_at"},
+		refs:    map[string]string{"user_id": "users"},
+	},
+	{
+		name:    "user_settings",
+		columns: []string{"key", "value"},
+		refs:    map[string]string{"user_id": "users"},
+	},
+	{
+		name: "oauth_providers",
+		columns: []string{
+			"provider", "display_name", "status", "client_id", "client_secret",
+			"authorize_url", "token_url", "profile_url", "scope", "json_user_id_path",
+			"json_user_name_path", "json_user_email_path", "logo_bkey", "protocol", "issuer_url",
+		},
+	},
+	{
+		name: "saml_configs",
+		columns: []string{
+			"is_enabled", "display_name", "idp_metadata", "idp_entity_id", "idp_sso_url",
+			"name_attribute", "email_attribute", "role_attribute", "administrator_roles", "collaborator_roles",
+		},
+	},
+	{
+		name:    "email_templates",
+		columns: []string{"name", "subject", "body", "updated_at"},
+	},
+	{
+		name:    "email_suppressions",
+		columns: []string{"address", "reason", "detail", "created_at"},
+	},
+	{
+		name:     "tags",
+		columns:  []string{"name", "slug", "color", "is_public", "created_at"},
+		returnID: true,
+	},
+	{
+		name:     "posts",
+		columns:  []string{"title", "slug", "number", "description", "created_at", "updated_at", "status", "response", "response_date"},
+		refs:     map[string]string{"user_id": "users", "response_user_id": "users"},
+		returnID: true,
+	},
+	{
+		name:    "post_status_history",
+		columns: []string{"status", "changed_at"},
+		refs:    map[string]string{"post_id": "posts", "changed_by_id": "users"},
+	},
+	{
+		name:    "post_tags",
+		columns: []string{"created_at"},
+		refs:    map[string]string{"tag_id": "tags", "post_id": "posts", "created_by_id": "users"},
+	},
+	{
+		name:    "post_votes",
+		columns: []string{"created_at", "credits"},
+		refs:    map[string]string{"user_id": "users", "post_id": "posts"},
+	},
+	{
+		name:    "post_subscribers",
+		columns: []string{"created_at", "updated_at", "status"},
+		refs:    map[string]string{"user_id": "users", "post_id": "posts"},
+	},
+	{
+		name:     "comments",
+		columns:  []string{"content", "created_at", "edited_at", "deleted_at"},
+		refs:     map[string]string{"post_id": "posts", "user_id": "users", "edited_by_id": "users", "deleted_by_id": "users"},
+		returnID: true,
+	},
+	{
+		name:    "attachments",
+		columns: []string{"attachment_bkey"},
+		refs:    map[string]string{"post_id": "posts", "comment_id": "comments", "user_id": "users"},
+	},
+	{
+		name:    "file_attachments",
+		columns: []string{"file_bkey", "file_name", "content_type", "size", "created_at"},
+		refs:    map[string]string{"post_id": "posts", "comment_id": "comments", "user_id": "users"},
+	},
+}
+
+var tenantColumns = []string{
+	"name", "invitation", "welcome_message", "is_private", "is_roadmap_public", "custom_css", "logo_bkey", "voting_mode", "vote_budget", "locale", "allowed_file_types",
+}
+
+var userColumns = []string{
+	"name", "email", "created_at", "role", "status", "avatar_type", "avatar_bkey", "locale",
+}
+
+//Limits of a backup.zip being restored, so that a crafted file can't exhaust memory or disk
+var (
+	maxRestoreEntries        = 100000
+	maxRestoreSize    uint64 = 2 << 30
+)
+
+type restorer struct {
+	trx    *dbx.Trx
+	tenant *models.Tenant
+	report *dto.ImportReport
+	tables map[string][]row
+	ids    map[string]map[int]int
+}
+
+//Restore loads a backup.zip created by Create into current tenant, which must not have any post yet.
+//Records get new IDs and users are matched by email with existing users.
+//Blobs are only counted, callers store them with RestoreBlobs once the transaction is committed.
+//On dry-run nothing is persisted and the report describes what would have been imported.
+func Restore(ctx context.Context, content []byte, dryRun bool) (*dto.ImportReport, error) {
+	trx := ctx.Value(app.TransactionCtxKey).(*dbx.Trx)
+	tenant := ctx.Value(app.TenantCtxKey).(*models.Tenant)
+	report := dto.NewImportReport(dryRun)
+
+	zipReader, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		report.Error("File is not a valid backup.zip.")
+		return report, nil
+	}
+
+	r := &restorer{
+		trx:    trx,
+		tenant: tenant,
+		report: report,
+		tables: make(map[string][]row),
+		ids:    make(map[string]map[int]int),
+	}
+
+	if len(zipReader.File) > maxRestoreEntries {
+		report.Error("Backup has %d files, but at most %d are allowed.", len(zipReader.File), maxRestoreEntries)
+		return report, nil
+	}
+
+	//archive/zip fails to read entries that are larger than their declared size, so declared sizes can be trusted
+	var size uint64
+	for _, file := range zipReader.File {
+		size += file.UncompressedSize64
+	}
+	if size > maxRestoreSize {
+		report.Error("Backup is larger than %d MB when uncompressed.", maxRestoreSize>>20)
+		return report, nil
+	}
+
+	for _, file := range zipReader.File {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+
+		if strings.HasPrefix(file.Name, "blobs/") {
+			if _, ok := blobKey(file.Name); !ok {
+				report.Warn("Skipped blob '%s' because its name is not a valid blob key.", file.Name)
+				continue
+			}
+			report.Count("blobs")
+		} else if strings.HasSuffix(file.Name, ".json") {
+			data, err := readZipFile(file)
+			if err != nil {
+				return nil, err
+			}
+
+			rows := make([]row, 0)
+			decoder := json.NewDecoder(bytes.NewReader(data))
+			decoder.UseNumber()
+			if err := decoder.Decode(&rows); err != nil {
+				report.Error("File '%s' is not a valid table export.", file.Name)
+				continue
+			}
+			r.tables[strings.TrimSuffix(file.Name, ".json")] = rows
+		}
+	}
+
+	for _, name := range []string{"tenants", "users", "posts"} {
+		if _, ok := r.tables[name]; !ok {
+			report.Error("File '%s.json' is missing from backup.", name)
+		}
+	}
+
+	hasPosts, err := trx.Exists("SELECT 1 FROM posts WHERE tenant_id = $1", tenant.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to check if tenant has posts")
+	}
+	if hasPosts {
+		report.Error("Backups can only be restored into a site without any post.")
+	}
+
+	if report.HasErrors() {
+		return report, nil
+	}
+
+	if _, err := trx.Execute("SAVEPOINT restore_backup"); err != nil {
+		return nil, errors.Wrap(err, "failed to create savepoint")
+	}
+
+	if err := r.restore(); err != nil {
+		return nil, err
+	}
+
+	if dryRun || report.HasErrors() {
+		if _, err := trx.Execute("ROLLBACK TO SAVEPOINT restore_backup"); err != nil {
+			return nil, errors.Wrap(err, "failed to rollback to savepoint")
+		}
+		return report, nil
+	}
+
+	if _, err := trx.Execute("RELEASE SAVEPOINT restore_backup"); err != nil {
+		return nil, errors.Wrap(err, "failed to release savepoint")
+	}
+
+	return report, nil
+}
+
+//RestoreBlobs stores the blobs of a backup.zip previously loaded by Restore into current tenant.
+//Blob storage is not transactional, so this must only be called after the restored records are committed.
+//Blobs are read one at a time, so that only one of them is in memory.
+func RestoreBlobs(ctx context.Context, content []byte) error {
+	zipReader, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return errors.Wrap(err, "failed to open backup.zip")
+	}
+
+	blobs := make(map[string]*zip.File)
+	for _, file := range zipReader.File {
+		if file.FileInfo().IsDir() || !strings.HasPrefix(file.Name, "blobs/") {
+			continue
+		}
+		if key, ok := blobKey(file.Name); ok {
+			blobs[key] = file
+		}
+	}
+
+	keys := make([]string, 0, len(blobs))
+	for key := range blobs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		content, err := readZipFile(blobs[key])
+		if err != nil {
+			return err
+		}
+
+		err = bus.Dispatch(ctx, &cmd.StoreBlob{
+			Key:         key,
+			Content:     content,
+			ContentType: http.DetectContentType(content),
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to store blob '%s'", key)
+		}
+	}
+
+	return nil
+}
+
+//blobKey returns the key of the blob stored on given zip entry, which must be a clean relative path
+func blobKey(name string) (string, bool) {
+	key := strings.TrimPrefix(name, "blobs/")
+	if blob.ValidateKey(key) != nil || strings.Contains(key, "\\") || path.Clean(key) != key {
+		return "", false
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "." || segment == ".." {
+			return "", false
+		}
+	}
+	return key, true
+}
+
+func readZipFile(file *zip.File) ([]byte, error) {
+	reader, err := file.Open()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open %s from zip file", file.Name)
+	}
+	defer reader.Close()
+
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read %s from zip file", file.Name)
+	}
+	return data, nil
+}
+
+func (r *restorer) restore() error {
+	if err := r.restoreTenant(); err != nil {
+		return err
+	}
+
+	if err := r.restoreUsers(); err != nil {
+		return err
+	}
+
+	for _, spec := range restoreOrder {
+		if err := r.restoreTable(spec); err != nil {
+			return err
+		}
+	}
+
+	return r.restoreOriginalPosts()
+}
+
+func (r *restorer) restoreTenant() error {
+	tenants := r.tables["tenants"]
+	if len(tenants) != 1 {
+		r.report.Error("File 'tenants.json' should have exactly one site, but has %d.", len(tenants))
+		return nil
+	}
+
+	sets := make([]string, 0)
+	args := []interface{}{r.tenant.ID}
+	for _, column := range tenantColumns {
+		if value, ok := tenants[0][column]; ok {
+			args = append(args, value)
+			sets = append(sets, fmt.Sprintf("%s = $%d", column, len(args)))
+		}
+	}
+
+	if len(sets) > 0 {
+		query := fmt.Sprintf("UPDATE tenants SET %s WHERE id = $1", strings.Join(sets, ", "))
+		if _, err := r.trx.Execute(query, args...); err != nil {
+			return errors.Wrap(err, "failed to restore tenant settings")
+		}
+	}
+
+	r.report.Count("tenants")
+	return nil
+}
+
+func (r *restorer) restoreUsers() error {
+	r.ids["users"] = make(map[int]int)
+	for _, user := range r.tables["users"] {
+		oldID, ok := toInt(user["id"])
+		if !ok {
+			r.report.Warn("Skipped user without id.")
+			continue
+		}
+
+		email := strings.ToLower(toString(user["email"]))
+		if email != "" {
+			var newID int
+			err := r.trx.Scalar(&newID, "SELECT id FROM users WHERE tenant_id = $1 AND email = $2", r.tenant.ID, email)
+			if err == nil {
+				r.ids["users"][oldID] = newID
+				r.report.Count("users")
+				continue
+			} else if err != app.ErrNotFound {
+				return errors.Wrap(err, "failed to find user by email")
+			}
+		}
+
+		newID, err := r.insert("users", user, userColumns, nil, true)
+		if err != nil {
+			return err
+		}
+		r.ids["users"][oldID] = newID
+		r.report.Count("users")
+	}
+	return nil
+}
+
+func (r *restorer) restoreTable(spec tableSpec) error {
+	r.ids[spec.name] = make(map[int]int)
+
+	refs := make([]string, 0, len(spec.refs))
+	for column := range spec.refs {
+		refs = append(refs, column)
+	}
+	sort.Strings(refs)
+
+	for _, record := range r.tables[spec.name] {
+		values := make(row, len(record))
+		for key, value := range record {
+			values[key] = value
+		}
+
+		valid := true
+		for _, column := range refs {
+			table := spec.refs[column]
+			oldID, ok := toInt(record[column])
+			if !ok {
+				continue
+			}
+
+			newID, ok := r.ids[table][oldID]
+			if !ok {
+				r.report.Warn("Skipped a record of '%s' because %s %d was not found.", spec.name, column, oldID)
+				valid = false
+				break
+			}
+			values[column] = newID
+		}
+
+		if !valid {
+			continue
+		}
+
+		newID, err := r.insert(spec.name, values, spec.columns, refs, spec.returnID)
+		if err != nil {
+			return err
+		}
+
+		if spec.returnID {
+			if oldID, ok := toInt(record["id"]); ok {
+				r.ids[spec.name][oldID] = newID
+			}
+		}
+		r.report.Count(spec.name)
+	}
+	return nil
+}
+
+func (r *restorer) restoreOriginalPosts() error {
+	for _, post := range r.tables["posts"] {
+		oldID, _ := toInt(post["id"])
+		originalID, ok := toInt(post["original_id"])
+		if !ok {
+			continue
+		}
+
+		newOriginalID, ok := r.ids["posts"][originalID]
+		if !ok {
+			r.report.Warn("Original of post %d was not found.", oldID)
+			continue
+		}
+
+		_, err := r.trx.Execute(
+			"UPDATE posts SET original_id = $1 WHERE id = $2 AND tenant_id = $3",
+			newOriginalID, r.ids["posts"][oldID], r.tenant.ID,
+		)
+		if err != nil {
+			return errors.Wrap(err, "failed to restore original of post")
+		}
+	}
+	return nil
+}
+
+func (r *restorer) insert(table string, record row, columns, refs []string, returnID bool) (int, error) {
+	names := []string{"tenant_id"}
+	params := []string{"$1"}
+	args := []interface{}{r.tenant.ID}
+	for _, column := range append(append([]string{}, columns...), refs...) {
+		if value, ok := record[column]; ok {
+			args = append(args, value)
+			names = append(names, column)
+			params = append(params, fmt.Sprintf("$%d", len(args)))
+		}
+	}
+
+	query := fmt.Sprintf(
+		"INSERT INTO %s (%s) VALUES (%s)",
+		table, strings.Join(names, ", "), strings.Join(params, ", "),
+	)
+
+	if !returnID {
+		if _, err := r.trx.Execute(query+" ON CONFLICT DO NOTHING", args...); err != nil {
+			return 0, errors.Wrap(err, "failed to restore %s", table)
+		}
+		return 0, nil
+	}
+
+	var id int
+	if err := r.trx.Scalar(&id, query+" RETURNING id", args...); err != nil {
+		return 0, errors.Wrap(err, "failed to restore %s", table)
+	}
+	return id, nil
+}
+
+func toInt(value interface{}) (int, bool) {
+	switch v := value.(type) {
+	case json.Number:
+		i, err := strconv.Atoi(v.String())
+		return i, err == nil
+	case int:
+		return v, true
+	}
+	return 0, false
+}
+
+func toString(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	return fmt.Sprintf("%v", value)
+}
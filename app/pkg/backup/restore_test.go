@@ -0,0 +1,48 @@
+package backup_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/getfider/fider/app/models/cmd"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/backup"
+	"github.com/getfider/fider/app/pkg/bus"
+)
+
+func newBackupZip(files map[string]string) []byte {
+	buf := new(bytes.Buffer)
+	writer := zip.NewWriter(buf)
+	for name, content := range files {
+		file, _ := writer.Create(name)
+		file.Write([]byte(content))
+	}
+	writer.Close()
+	return buf.Bytes()
+}
+
+func TestRestoreBlobs(t *testing.T) {
+	RegisterT(t)
+
+	stored := make(map[string]string)
+	bus.AddHandler(func(ctx context.Context, c *cmd.StoreBlob) error {
+		stored[c.Key] = string(c.Content)
+		return nil
+	})
+
+	content := newBackupZip(map[string]string{
+		"posts.json":              "[]",
+		"blobs/logos/fider.png":   "logo",
+		"blobs/attachments/a.txt": "attachment",
+		"blobs/../secret.txt":     "escape",
+	})
+
+	err := backup.RestoreBlobs(context.Background(), content)
+	Expect(err).IsNil()
+	Expect(stored).Equals(map[string]string{
+		"logos/fider.png":   "logo",
+		"attachments/a.txt": "attachment",
+	})
+}
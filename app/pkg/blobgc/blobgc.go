@@ -0,0 +1,83 @@
+package blobgc
+
+import (
+	"context"
+	"time"
+
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/query"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/getfider/fider/app/pkg/images"
+)
+
+// Report is the result of a garbage collection on the blobs of a tenant
+type Report struct {
+	DryRun  bool
+	Scanned int
+	Deleted []string
+	Recent  []string
+	Missing []string
+}
+
+// Collect deletes the blobs of current tenant that are no longer referenced by any record
+// Unreferenced blobs modified within the grace period are kept, as their record might not have been stored yet
+// Referenced blobs that can't be found on the blob storage are reported as missing
+func Collect(ctx context.Context, gracePeriod time.Duration, dryRun bool) (*Report, error) {
+	listBlobs := &query.ListBlobsMetadata{}
+	getReferenced := &query.GetReferencedBlobKeys{}
+	if err := bus.Dispatch(ctx, listBlobs, getReferenced); err != nil {
+		return nil, errors.Wrap(err, "failed to list blobs")
+	}
+
+	referenced := make(map[string]bool, len(getReferenced.Result))
+	for _, key := range getReferenced.Result {
+		referenced[key] = true
+	}
+
+	report := &Report{
+		DryRun:  dryRun,
+		Scanned: len(listBlobs.Result),
+		Deleted: make([]string, 0),
+		Recent:  make([]string, 0),
+		Missing: make([]string, 0),
+	}
+
+	existing := make(map[string]bool, len(listBlobs.Result))
+	threshold := time.Now().Add(-gracePeriod)
+	for _, blob := range listBlobs.Result {
+		existing[blob.Key] = true
+		if isReferenced(blob.Key, referenced) {
+			continue
+		}
+
+		if blob.ModifiedAt.After(threshold) {
+			report.Recent = append(report.Recent, blob.Key)
+			continue
+		}
+
+		if !dryRun {
+			if err := bus.Dispatch(ctx, &cmd.DeleteBlob{Key: blob.Key}); err != nil {
+				return nil, errors.Wrap(err, "failed to delete blob '%s'", blob.Key)
+			}
+		}
+		report.Deleted = append(report.Deleted, blob.Key)
+	}
+
+	for _, key := range getReferenced.Result {
+		if !existing[key] {
+			report.Missing = append(report.Missing, key)
+		}
+	}
+
+	return report, nil
+}
+
+//isReferenced returns true if given key, or the image of which it's a thumbnail, is referenced
+func isReferenced(key string, referenced map[string]bool) bool {
+	if referenced[key] {
+		return true
+	}
+	original, ok := images.ParseThumbnailKey(key)
+	return ok && referenced[original]
+}
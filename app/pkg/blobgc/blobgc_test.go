@@ -0,0 +1,84 @@
+package blobgc_test
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/dto"
+	"github.com/getfider/fider/app/models/query"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/blobgc"
+	"github.com/getfider/fider/app/pkg/bus"
+)
+
+func setupBlobs(blobs map[string]time.Duration, referenced []string) *[]string {
+	deleted := make([]string, 0)
+
+	bus.AddHandler(func(ctx context.Context, q *query.ListBlobsMetadata) error {
+		q.Result = make([]*dto.BlobMetadata, 0)
+		for key, age := range blobs {
+			q.Result = append(q.Result, &dto.BlobMetadata{Key: key, ModifiedAt: time.Now().Add(-age)})
+		}
+		sort.Slice(q.Result, func(i, j int) bool {
+			return q.Result[i].Key < q.Result[j].Key
+		})
+		return nil
+	})
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetReferencedBlobKeys) error {
+		q.Result = referenced
+		return nil
+	})
+
+	bus.AddHandler(func(ctx context.Context, c *cmd.DeleteBlob) error {
+		deleted = append(deleted, c.Key)
+		return nil
+	})
+
+	return &deleted
+}
+
+func TestCollect(t *testing.T) {
+	RegisterT(t)
+
+	deleted := setupBlobs(map[string]time.Duration{
+		"attachments/used.png":        48 * time.Hour,
+		"attachments/used.png@50":     48 * time.Hour,
+		"attachments/removed.png":     48 * time.Hour,
+		"attachments/removed.png@200": 48 * time.Hour,
+		"attachments/uploading.png":   time.Minute,
+		"avatars/user.png":            48 * time.Hour,
+		"files/report.pdf@123":        48 * time.Hour,
+	}, []string{
+		"attachments/used.png",
+		"avatars/user.png",
+		"logos/gone.png",
+	})
+
+	report, err := blobgc.Collect(context.Background(), 24*time.Hour, false)
+	Expect(err).IsNil()
+	Expect(report.DryRun).IsFalse()
+	Expect(report.Scanned).Equals(7)
+	Expect(report.Deleted).Equals([]string{"attachments/removed.png", "attachments/removed.png@200", "files/report.pdf@123"})
+	Expect(report.Recent).Equals([]string{"attachments/uploading.png"})
+	Expect(report.Missing).Equals([]string{"logos/gone.png"})
+	Expect(*deleted).Equals(report.Deleted)
+}
+
+func TestCollect_DryRun(t *testing.T) {
+	RegisterT(t)
+
+	deleted := setupBlobs(map[string]time.Duration{
+		"attachments/removed.png": 48 * time.Hour,
+	}, []string{})
+
+	report, err := blobgc.Collect(context.Background(), 24*time.Hour, true)
+	Expect(err).IsNil()
+	Expect(report.DryRun).IsTrue()
+	Expect(report.Deleted).Equals([]string{"attachments/removed.png"})
+	Expect(report.Missing).HasLen(0)
+	Expect(*deleted).HasLen(0)
+}
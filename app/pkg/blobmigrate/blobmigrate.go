@@ -0,0 +1,104 @@
+package blobmigrate
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/query"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/getfider/fider/app/services/blob"
+)
+
+// Report is the result of a migration of the blobs of a tenant
+type Report struct {
+	Copied    []string
+	Skipped   []string
+	Conflicts []string
+}
+
+// Migrate copies every blob of current tenant from one blob storage to another, one at a time
+// Blobs that already exist on the target with the same checksum are skipped, so that a migration can be resumed
+// or repeated while the site is live. Blobs that exist with a different content are only replaced when overwrite is true
+// Nothing is ever deleted from the source
+func Migrate(ctx context.Context, from, to bus.Service, overwrite bool) (*Report, error) {
+	report := &Report{
+		Copied:    make([]string, 0),
+		Skipped:   make([]string, 0),
+		Conflicts: make([]string, 0),
+	}
+
+	use(from)
+	listBlobs := &query.ListBlobsMetadata{}
+	if err := bus.Dispatch(ctx, listBlobs); err != nil {
+		return nil, errors.Wrap(err, "failed to list blobs from %s", from.Name())
+	}
+
+	for _, b := range listBlobs.Result {
+		if err := migrateBlob(ctx, from, to, b.Key, overwrite, report); err != nil {
+			return report, err
+		}
+	}
+
+	return report, nil
+}
+
+func migrateBlob(ctx context.Context, from, to bus.Service, key string, overwrite bool, report *Report) error {
+	use(from)
+	source := &query.GetBlobByKey{Key: key}
+	if err := bus.Dispatch(ctx, source); err != nil {
+		if errors.Cause(err) == blob.ErrNotFound {
+			//it has been deleted after the list was read
+			report.Skipped = append(report.Skipped, key)
+			return nil
+		}
+		return errors.Wrap(err, "failed to read blob '%s' from %s", key, from.Name())
+	}
+	checksum := sha256.Sum256(source.Result.Content)
+
+	use(to)
+	target := &query.GetBlobByKey{Key: key}
+	err := bus.Dispatch(ctx, target)
+	if err == nil {
+		targetChecksum := sha256.Sum256(target.Result.Content)
+		if bytes.Equal(checksum[:], targetChecksum[:]) {
+			report.Skipped = append(report.Skipped, key)
+			return nil
+		}
+		if !overwrite {
+			report.Conflicts = append(report.Conflicts, key)
+			return nil
+		}
+	} else if errors.Cause(err) != blob.ErrNotFound {
+		return errors.Wrap(err, "failed to read blob '%s' from %s", key, to.Name())
+	}
+
+	err = bus.Dispatch(ctx, &cmd.StoreBlob{
+		Key:         key,
+		Content:     source.Result.Content,
+		ContentType: source.Result.ContentType,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to store blob '%s' on %s", key, to.Name())
+	}
+
+	stored := &query.GetBlobByKey{Key: key}
+	if err := bus.Dispatch(ctx, stored); err != nil {
+		return errors.Wrap(err, "failed to read stored blob '%s' from %s", key, to.Name())
+	}
+
+	storedChecksum := sha256.Sum256(stored.Result.Content)
+	if !bytes.Equal(checksum[:], storedChecksum[:]) {
+		return errors.New("checksum of blob '%s' on %s doesn't match the one on %s", key, to.Name(), from.Name())
+	}
+
+	report.Copied = append(report.Copied, key)
+	return nil
+}
+
+//use makes given blob storage the one that handles blob messages, as each service replaces the handlers of the previous one
+func use(svc bus.Service) {
+	svc.Init()
+}
@@ -0,0 +1,126 @@
+package blobmigrate_test
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/dto"
+	"github.com/getfider/fider/app/models/query"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/blobmigrate"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/services/blob"
+)
+
+type memoryStorage struct {
+	name  string
+	blobs map[string]string
+}
+
+func (s memoryStorage) Name() string {
+	return s.name
+}
+
+func (s memoryStorage) Category() string {
+	return "blobstorage"
+}
+
+func (s memoryStorage) Enabled() bool {
+	return true
+}
+
+func (s memoryStorage) Init() {
+	bus.AddHandler(func(ctx context.Context, q *query.ListBlobsMetadata) error {
+		q.Result = make([]*dto.BlobMetadata, 0)
+		for key, content := range s.blobs {
+			q.Result = append(q.Result, &dto.BlobMetadata{Key: key, Size: int64(len(content))})
+		}
+		sort.Slice(q.Result, func(i, j int) bool {
+			return q.Result[i].Key < q.Result[j].Key
+		})
+		return nil
+	})
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetBlobByKey) error {
+		content, ok := s.blobs[q.Key]
+		if !ok {
+			return blob.ErrNotFound
+		}
+		q.Result = &dto.Blob{Content: []byte(content), ContentType: "text/plain", Size: int64(len(content))}
+		return nil
+	})
+
+	bus.AddHandler(func(ctx context.Context, c *cmd.StoreBlob) error {
+		s.blobs[c.Key] = string(c.Content)
+		return nil
+	})
+}
+
+func newStorage(name string, blobs map[string]string) memoryStorage {
+	return memoryStorage{name: name, blobs: blobs}
+}
+
+func TestMigrate(t *testing.T) {
+	RegisterT(t)
+
+	from := newStorage("from", map[string]string{
+		"attachments/a.png": "A",
+		"logos/b.png":       "B",
+	})
+	to := newStorage("to", map[string]string{})
+
+	report, err := blobmigrate.Migrate(context.Background(), from, to, false)
+	Expect(err).IsNil()
+	Expect(report.Copied).Equals([]string{"attachments/a.png", "logos/b.png"})
+	Expect(report.Skipped).HasLen(0)
+	Expect(report.Conflicts).HasLen(0)
+	Expect(to.blobs).Equals(from.blobs)
+}
+
+func TestMigrate_Resume(t *testing.T) {
+	RegisterT(t)
+
+	from := newStorage("from", map[string]string{
+		"attachments/a.png": "A",
+		"logos/b.png":       "B",
+	})
+	to := newStorage("to", map[string]string{
+		"attachments/a.png": "A",
+	})
+
+	report, err := blobmigrate.Migrate(context.Background(), from, to, false)
+	Expect(err).IsNil()
+	Expect(report.Copied).Equals([]string{"logos/b.png"})
+	Expect(report.Skipped).Equals([]string{"attachments/a.png"})
+	Expect(report.Conflicts).HasLen(0)
+
+	report, err = blobmigrate.Migrate(context.Background(), from, to, false)
+	Expect(err).IsNil()
+	Expect(report.Copied).HasLen(0)
+	Expect(report.Skipped).Equals([]string{"attachments/a.png", "logos/b.png"})
+}
+
+func TestMigrate_Conflict(t *testing.T) {
+	RegisterT(t)
+
+	from := newStorage("from", map[string]string{
+		"attachments/a.png": "A",
+	})
+	to := newStorage("to", map[string]string{
+		"attachments/a.png": "Changed",
+	})
+
+	report, err := blobmigrate.Migrate(context.Background(), from, to, false)
+	Expect(err).IsNil()
+	Expect(report.Copied).HasLen(0)
+	Expect(report.Conflicts).Equals([]string{"attachments/a.png"})
+	Expect(to.blobs["attachments/a.png"]).Equals("Changed")
+
+	report, err = blobmigrate.Migrate(context.Background(), from, to, true)
+	Expect(err).IsNil()
+	Expect(report.Copied).Equals([]string{"attachments/a.png"})
+	Expect(report.Conflicts).HasLen(0)
+	Expect(to.blobs["attachments/a.png"]).Equals("A")
+}
@@ -5,9 +5,12 @@ import (
 	"fmt"
 	"reflect"
 	"sync"
+	"time"
 
 	"github.com/getfider/fider/app/models/cmd"
 	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/getfider/fider/app/pkg/metrics"
+	"github.com/getfider/fider/app/pkg/trace"
 )
 
 type HandlerFunc interface{}
@@ -24,6 +27,7 @@ var handlers = make(map[string]HandlerFunc)
 var listeners = make(map[string][]HandlerFunc)
 var services = make([]Service, 0)
 var busLock = &sync.RWMutex{}
+var dispatchDuration = metrics.NewHistogram("fider_bus_dispatch_duration_seconds", "Duration of bus dispatches in seconds, by message type.", metrics.DefaultBuckets, "message")
 
 func Register(svc Service) {
 	busLock.Lock()
@@ -111,15 +115,20 @@ func Dispatch(ctx context.Context, msgs ...Msg) error {
 			panic(fmt.Errorf("could not find handler for '%s'.", key))
 		}
 
+		spanCtx, span := trace.Start(ctx, elem.String(), trace.KindInternal)
 		var params = []reflect.Value{
-			reflect.ValueOf(ctx),
+			reflect.ValueOf(spanCtx),
 			reflect.ValueOf(msg),
 		}
 
+		start := time.Now()
 		ret := reflect.ValueOf(handler).Call(params)
+		dispatchDuration.ObserveSince(start, elem.String())
 		if err := ret[0].Interface(); err != nil {
+			span.End(err.(error))
 			return err.(error)
 		}
+		span.End(nil)
 	}
 
 	return nil
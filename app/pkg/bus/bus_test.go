@@ -2,12 +2,14 @@ package bus_test
 
 import (
 	"context"
+	"sync"
 	"testing"
 
 	"github.com/getfider/fider/app/models/cmd"
 
 	"github.com/getfider/fider/app/pkg/bus"
 	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/getfider/fider/app/pkg/trace"
 
 	. "github.com/getfider/fider/app/pkg/assert"
 )
@@ -117,3 +119,38 @@ func TestBus_PublishError(t *testing.T) {
 	bus.Publish(context.Background(), &SayHelloCommand{Name: "123"})
 	Expect(errors.Cause(err)).Equals(boom)
 }
+
+type spanRecorder struct {
+	lock  sync.Mutex
+	spans []*trace.Span
+}
+
+func (r *spanRecorder) Export(spans []*trace.Span) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.spans = append(r.spans, spans...)
+	return nil
+}
+
+func TestBus_DispatchSpan(t *testing.T) {
+	RegisterT(t)
+	exporter := &spanRecorder{}
+	trace.Use(exporter)
+
+	bus.Register(GreeterService{})
+	bus.Init()
+	ctx := context.WithValue(context.Background(), GreetingKey, "Good Morning")
+	ctx, root := trace.Start(ctx, "GET /", trace.KindServer)
+	cmd := &SayHelloCommand{Name: "Fider"}
+	err := bus.Dispatch(ctx, cmd)
+	Expect(err).IsNil()
+	Expect(cmd.Result).Equals("Good Morning Fider")
+	root.End(nil)
+
+	err = trace.Shutdown(context.Background())
+	Expect(err).IsNil()
+	Expect(exporter.spans).HasLen(2)
+	Expect(exporter.spans[0].Name).Equals("bus_test.SayHelloCommand")
+	Expect(exporter.spans[0].Context.TraceID).Equals(root.Context.TraceID)
+	Expect(exporter.spans[0].ParentID).Equals(root.Context.SpanID)
+}
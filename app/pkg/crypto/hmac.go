@@ -0,0 +1,14 @@
+package crypto
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"fmt"
+)
+
+//HMACSHA256 returns the hex encoded HMAC-SHA256 of a given payload signed with key
+func HMACSHA256(key string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write(payload)
+	return fmt.Sprintf("%x", mac.Sum(nil))
+}
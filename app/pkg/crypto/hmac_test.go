@@ -0,0 +1,16 @@
+package crypto_test
+
+import (
+	"testing"
+
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/crypto"
+)
+
+func TestHMACSHA256(t *testing.T) {
+	RegisterT(t)
+
+	signature := crypto.HMACSHA256("s3cr3t", []byte("Fider"))
+
+	Expect(signature).Equals("50b5f0381cdc296cc5901692b5bb41e3cb27344a3131f57ebbc83154611ba1fb")
+}
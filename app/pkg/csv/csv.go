@@ -3,11 +3,13 @@ package csv
 import (
 	"bytes"
 	gocsv "encoding/csv"
+	"encoding/json"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/models/dto"
 )
 
 //FromPosts return a byte array of CSV file containing all posts
@@ -83,3 +85,82 @@ func FromPosts(posts []*models.Post) ([]byte, error) {
 
 	return buffer.Bytes(), nil
 }
+
+//FromAuditLogs return a byte array of CSV file containing given audit log entries
+func FromAuditLogs(logs []*models.AuditLog) ([]byte, error) {
+	buffer := &bytes.Buffer{}
+	writer := gocsv.NewWriter(buffer)
+
+	header := []string{
+		"created_at",
+		"action",
+		"actor_id",
+		"actor_name",
+		"target_type",
+		"target_id",
+		"target_name",
+		"before",
+		"after",
+		"client_ip",
+	}
+	if err := writer.Write(header); err != nil {
+		return nil, err
+	}
+
+	for _, log := range logs {
+		var (
+			actorID   string
+			actorName string
+			targetID  string
+		)
+
+		if log.Actor != nil {
+			actorID = strconv.Itoa(log.Actor.ID)
+			actorName = log.Actor.Name
+		}
+		if log.TargetID > 0 {
+			targetID = strconv.Itoa(log.TargetID)
+		}
+
+		before, err := propsToJSON(log.Before)
+		if err != nil {
+			return nil, err
+		}
+		after, err := propsToJSON(log.After)
+		if err != nil {
+			return nil, err
+		}
+
+		record := []string{
+			log.CreatedAt.Format(time.RFC3339),
+			string(log.Action),
+			actorID,
+			actorName,
+			log.TargetType,
+			targetID,
+			log.TargetName,
+			before,
+			after,
+			log.ClientIP,
+		}
+		if err := writer.Write(record); err != nil {
+			return nil, err
+		}
+	}
+
+	writer.Flush()
+
+	if err := writer.Error(); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+func propsToJSON(props dto.Props) (string, error) {
+	if props == nil {
+		return "", nil
+	}
+	bytes, err := json.Marshal(props)
+	return string(bytes), err
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/models/dto"
 	"github.com/getfider/fider/app/models/enum"
 	. "github.com/getfider/fider/app/pkg/assert"
 	"github.com/getfider/fider/app/pkg/csv"
@@ -52,6 +53,38 @@ func TestExportPostsToCSV_MorePosts(t *testing.T) {
 	Expect(actual).Equals(expected)
 }
 
+func TestExportAuditLogsToCSV(t *testing.T) {
+	RegisterT(t)
+
+	logs := []*models.AuditLog{
+		{
+			Action:     enum.AuditActionUserRoleChanged,
+			Actor:      &models.User{ID: 1, Name: "Faceless Man"},
+			TargetType: "user",
+			TargetID:   2,
+			TargetName: "Arya Stark",
+			Before:     dto.Props{"role": enum.RoleVisitor},
+			After:      dto.Props{"role": enum.RoleCollaborator},
+			ClientIP:   "127.0.0.1",
+			CreatedAt:  time.Date(2019, 8, 17, 20, 30, 0, 0, time.UTC),
+		},
+		{
+			Action:     enum.AuditActionTagDeleted,
+			TargetType: "tag",
+			TargetID:   5,
+			TargetName: "Bug",
+			Before:     dto.Props{"name": "Bug", "color": "FF0000", "isPublic": true},
+			CreatedAt:  time.Date(2019, 8, 18, 10, 0, 0, 0, time.UTC),
+		},
+	}
+
+	expected, err := ioutil.ReadFile("./testdata/audit-logs.csv")
+	Expect(err).IsNil()
+	actual, err := csv.FromAuditLogs(logs)
+	Expect(err).IsNil()
+	Expect(actual).Equals(expected)
+}
+
 var declinedPost = &models.Post{
 	Number:      10,
 	Title:       "Go is fast",
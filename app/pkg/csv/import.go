@@ -0,0 +1,248 @@
+package csv
+
+import (
+	"bytes"
+	"context"
+	gocsv "encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/dto"
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/errors"
+)
+
+//Import parses a CSV file of given kind (posts, comments or votes) and imports it into current tenant.
+//Nothing is imported if any problem is found, or on dry-run.
+func Import(ctx context.Context, kind string, content []byte, dryRun bool) (*dto.ImportReport, error) {
+	report := dto.NewImportReport(dryRun)
+	importRecords := &cmd.ImportRecords{Report: report}
+
+	switch kind {
+	case "posts":
+		importRecords.Posts = ToPosts(content, report)
+	case "comments":
+		importRecords.Comments = ToComments(content, report)
+	case "votes":
+		importRecords.Votes = ToVotes(content, report)
+	default:
+		return nil, errors.New("unknown import kind '%s'", kind)
+	}
+
+	if report.HasErrors() {
+		return report, nil
+	}
+
+	if err := bus.Dispatch(ctx, importRecords); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+type record struct {
+	line    int
+	values  []string
+	columns map[string]int
+}
+
+func (r *record) get(column string) string {
+	if idx, ok := r.columns[column]; ok && idx < len(r.values) {
+		return strings.TrimSpace(r.values[idx])
+	}
+	return ""
+}
+
+func (r *record) author(prefix string, report *dto.ImportReport) dto.ImportUser {
+	user := dto.ImportUser{
+		Name:  r.get(prefix),
+		Email: strings.ToLower(r.get(prefix + "_email")),
+	}
+	if user.Name == "" && user.Email == "" {
+		report.Error("Line %d: '%s' or '%s_email' is required.", r.line, prefix, prefix)
+	}
+	return user
+}
+
+func (r *record) date(column string, report *dto.ImportReport) time.Time {
+	value := r.get(column)
+	if value == "" {
+		return time.Time{}
+	}
+
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02"} {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date
+		}
+	}
+
+	report.Error("Line %d: '%s' is not a valid date for '%s'.", r.line, value, column)
+	return time.Time{}
+}
+
+func (r *record) number(column string, report *dto.ImportReport) int {
+	value := r.get(column)
+	if value == "" {
+		return 0
+	}
+
+	number, err := strconv.Atoi(value)
+	if err != nil || number <= 0 {
+		report.Error("Line %d: '%s' is not a valid number for '%s'.", r.line, value, column)
+		return 0
+	}
+	return number
+}
+
+func (r *record) required(column string, report *dto.ImportReport) string {
+	value := r.get(column)
+	if value == "" {
+		report.Error("Line %d: '%s' is required.", r.line, column)
+	}
+	return value
+}
+
+func readRecords(content []byte, required []string, report *dto.ImportReport) []*record {
+	reader := gocsv.NewReader(bytes.NewReader(content))
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		report.Error("File is empty.")
+		return nil
+	} else if err != nil {
+		report.Error("File is not a valid CSV: %s.", err.Error())
+		return nil
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	for _, name := range required {
+		if _, ok := columns[name]; !ok {
+			report.Error("Column '%s' is missing.", name)
+		}
+	}
+
+	if report.HasErrors() {
+		return nil
+	}
+
+	records := make([]*record, 0)
+	for line := 2; ; line++ {
+		values, err := reader.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			report.Error("Line %d: %s.", line, err.Error())
+			break
+		}
+		records = append(records, &record{line: line, values: values, columns: columns})
+	}
+	return records
+}
+
+//ToPosts parses a CSV file with one post per row. Files created by FromPosts can be imported back.
+//Problems found on each row are added to report.
+func ToPosts(content []byte, report *dto.ImportReport) []*dto.ImportPost {
+	posts := make([]*dto.ImportPost, 0)
+	numbers := make(map[int]int)
+	for _, r := range readRecords(content, []string{"title"}, report) {
+		post := &dto.ImportPost{
+			Line:           r.line,
+			Number:         r.number("number", report),
+			Title:          r.required("title", report),
+			Description:    r.get("description"),
+			CreatedAt:      r.date("created_at", report),
+			Author:         r.author("created_by", report),
+			Status:         enum.PostOpen,
+			Response:       r.get("response"),
+			RespondedAt:    r.date("responded_at", report),
+			OriginalNumber: r.number("original_number", report),
+			Tags:           make([]string, 0),
+		}
+
+		if r.get("responded_by") != "" || r.get("responded_by_email") != "" {
+			post.RespondedBy = r.author("responded_by", report)
+		}
+
+		if status := strings.ToLower(r.get("status")); status != "" {
+			_ = post.Status.UnmarshalText([]byte(status))
+			if post.Status.Name() != status {
+				report.Error("Line %d: '%s' is not a valid status.", r.line, status)
+			} else if post.Status == enum.PostDeleted {
+				report.Error("Line %d: deleted posts cannot be imported.", r.line)
+			}
+		}
+
+		if post.Status == enum.PostDuplicate && post.OriginalNumber == 0 {
+			report.Error("Line %d: 'original_number' is required for duplicate posts.", r.line)
+		}
+
+		if post.Number > 0 {
+			if line, ok := numbers[post.Number]; ok {
+				report.Error("Line %d: number %d is already used on line %d.", r.line, post.Number, line)
+			}
+			numbers[post.Number] = r.line
+		}
+
+		for _, tag := range strings.Split(r.get("tags"), ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				post.Tags = append(post.Tags, tag)
+			}
+		}
+
+		posts = append(posts, post)
+	}
+	return posts
+}
+
+//ToComments parses a CSV file with one comment per row
+//Problems found on each row are added to report.
+func ToComments(content []byte, report *dto.ImportReport) []*dto.ImportComment {
+	comments := make([]*dto.ImportComment, 0)
+	for _, r := range readRecords(content, []string{"post_number", "content"}, report) {
+		comment := &dto.ImportComment{
+			Line:       r.line,
+			PostNumber: r.number("post_number", report),
+			Content:    r.required("content", report),
+			CreatedAt:  r.date("created_at", report),
+			Author:     r.author("created_by", report),
+		}
+		if comment.PostNumber == 0 && r.get("post_number") == "" {
+			report.Error("Line %d: 'post_number' is required.", r.line)
+		}
+		comments = append(comments, comment)
+	}
+	return comments
+}
+
+//ToVotes parses a CSV file with one vote per row
+//Problems found on each row are added to report.
+func ToVotes(content []byte, report *dto.ImportReport) []*dto.ImportVote {
+	votes := make([]*dto.ImportVote, 0)
+	for _, r := range readRecords(content, []string{"post_number"}, report) {
+		vote := &dto.ImportVote{
+			Line:       r.line,
+			PostNumber: r.number("post_number", report),
+			CreatedAt:  r.date("created_at", report),
+			Author:     r.author("created_by", report),
+			Credits:    r.number("credits", report),
+		}
+		if vote.PostNumber == 0 && r.get("post_number") == "" {
+			report.Error("Line %d: 'post_number' is required.", r.line)
+		}
+		if vote.Credits > models.MaxVoteCredits {
+			report.Error("Line %d: a vote can have at most %d credits.", r.line, models.MaxVoteCredits)
+		}
+		votes = append(votes, vote)
+	}
+	return votes
+}
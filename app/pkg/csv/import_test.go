@@ -0,0 +1,133 @@
+package csv_test
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/dto"
+	"github.com/getfider/fider/app/models/enum"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/csv"
+)
+
+func TestImportPostsFromCSV_ExportedFile(t *testing.T) {
+	RegisterT(t)
+
+	content, err := ioutil.ReadFile("./testdata/more-posts.csv")
+	Expect(err).IsNil()
+
+	report := dto.NewImportReport(false)
+	posts := csv.ToPosts(content, report)
+	Expect(report.Errors).HasLen(0)
+	Expect(posts).HasLen(3)
+
+	Expect(posts[0].Line).Equals(2)
+	Expect(posts[0].Number).Equals(10)
+	Expect(posts[0].Title).Equals("Go is fast")
+	Expect(posts[0].Description).Equals("Very tiny description")
+	Expect(posts[0].CreatedAt).Equals(time.Date(2018, 3, 23, 19, 33, 22, 0, time.UTC))
+	Expect(posts[0].Author.Name).Equals("Faceless")
+	Expect(posts[0].Status).Equals(enum.PostDeclined)
+	Expect(posts[0].Response).Equals("Nothing we need to do")
+	Expect(posts[0].RespondedBy.Name).Equals("John Snow")
+	Expect(posts[0].Tags).Equals([]string{"easy", "ignored"})
+
+	Expect(posts[1].Status).Equals(enum.PostOpen)
+	Expect(posts[1].Tags).HasLen(0)
+
+	Expect(posts[2].Status).Equals(enum.PostDuplicate)
+	Expect(posts[2].OriginalNumber).Equals(99)
+}
+
+func TestImportPostsFromCSV_InvalidRows(t *testing.T) {
+	RegisterT(t)
+
+	content := []byte(`number,title,created_by_email,created_at,status
+1,Add dark mode,jon.snow@got.com,2019-05-01,open
+1,Add light mode,jon.snow@got.com,yesterday,open
+2,,,,exploded
+3,Merged,arya.stark@got.com,,duplicate
+`)
+
+	report := dto.NewImportReport(true)
+	posts := csv.ToPosts(content, report)
+	Expect(posts).HasLen(4)
+	Expect(posts[0].Author.Email).Equals("jon.snow@got.com")
+	Expect(report.Errors).Equals([]string{
+		"Line 3: 'yesterday' is not a valid date for 'created_at'.",
+		"Line 3: number 1 is already used on line 2.",
+		"Line 4: 'title' is required.",
+		"Line 4: 'created_by' or 'created_by_email' is required.",
+		"Line 4: 'exploded' is not a valid status.",
+		"Line 5: 'original_number' is required for duplicate posts.",
+	})
+}
+
+func TestImportPostsFromCSV_MissingColumns(t *testing.T) {
+	RegisterT(t)
+
+	report := dto.NewImportReport(false)
+	posts := csv.ToPosts([]byte("number,description\n1,Hello\n"), report)
+	Expect(posts).HasLen(0)
+	Expect(report.Errors).Equals([]string{"Column 'title' is missing."})
+
+	report = dto.NewImportReport(false)
+	posts = csv.ToPosts([]byte(""), report)
+	Expect(posts).HasLen(0)
+	Expect(report.Errors).Equals([]string{"File is empty."})
+}
+
+func TestImportCommentsAndVotesFromCSV(t *testing.T) {
+	RegisterT(t)
+
+	report := dto.NewImportReport(false)
+	comments := csv.ToComments([]byte(`post_number,content,created_by,created_by_email
+1,Yes please!,Jon Snow,JON.SNOW@got.com
+abc,No,Arya Stark,
+`), report)
+	Expect(comments).HasLen(2)
+	Expect(comments[0].PostNumber).Equals(1)
+	Expect(comments[0].Content).Equals("Yes please!")
+	Expect(comments[0].Author).Equals(dto.ImportUser{Name: "Jon Snow", Email: "jon.snow@got.com"})
+	Expect(report.Errors).Equals([]string{"Line 3: 'abc' is not a valid number for 'post_number'."})
+
+	report = dto.NewImportReport(false)
+	votes := csv.ToVotes([]byte(`post_number,created_by_email,credits
+1,jon.snow@got.com,
+2,arya.stark@got.com,5
+`), report)
+	Expect(votes).HasLen(2)
+	Expect(votes[0].Credits).Equals(0)
+	Expect(report.Errors).Equals([]string{"Line 3: a vote can have at most 3 credits."})
+}
+
+func TestImportFromCSV_DispatchesOnlyWhenValid(t *testing.T) {
+	RegisterT(t)
+
+	var imported *cmd.ImportRecords
+	bus.AddHandler(func(ctx context.Context, c *cmd.ImportRecords) error {
+		imported = c
+		c.Report.Count("votes")
+		return nil
+	})
+
+	report, err := csv.Import(context.Background(), "votes", []byte("post_number,created_by_email\n1,\n"), false)
+	Expect(err).IsNil()
+	Expect(report.HasErrors()).IsTrue()
+	Expect(imported).IsNil()
+
+	report, err = csv.Import(context.Background(), "votes", []byte("post_number,created_by_email\n1,jon.snow@got.com\n"), true)
+	Expect(err).IsNil()
+	Expect(report.HasErrors()).IsFalse()
+	Expect(report.DryRun).IsTrue()
+	Expect(report.Imported["votes"]).Equals(1)
+	Expect(imported.Votes).HasLen(1)
+
+	report, err = csv.Import(context.Background(), "tags", []byte("name\nbug\n"), false)
+	Expect(err).IsNotNil()
+	Expect(report).IsNil()
+}
@@ -0,0 +1,49 @@
+package cursor
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+//ErrInvalid is returned when a cursor is malformed or doesn't match the requested sort
+var ErrInvalid = errors.New("Cursor is invalid")
+
+//Cursor points to the last row of a page, so that the next page can start right after it
+type Cursor struct {
+	Sort  string
+	Value string
+	ID    int
+}
+
+//New returns a cursor for the row with given id and sort value
+func New(sort, value string, id int) *Cursor {
+	return &Cursor{Sort: sort, Value: value, ID: id}
+}
+
+//String returns an opaque representation of the cursor that is safe to use on URLs
+func (c *Cursor) String() string {
+	raw := c.Sort + "|" + strconv.Itoa(c.ID) + "|" + c.Value
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+//Parse decodes a cursor previously generated by String
+func Parse(input string) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(input)
+	if err != nil {
+		return nil, ErrInvalid
+	}
+
+	parts := strings.SplitN(string(raw), "|", 3)
+	if len(parts) != 3 || parts[0] == "" {
+		return nil, ErrInvalid
+	}
+
+	id, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return nil, ErrInvalid
+	}
+
+	return New(parts[0], parts[2], id), nil
+}
@@ -0,0 +1,34 @@
+package cursor_test
+
+import (
+	"testing"
+
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/cursor"
+)
+
+func TestCursor_StringAndParse(t *testing.T) {
+	RegisterT(t)
+
+	c := cursor.New("created", "2019-06-22T21:10:00.123456Z", 42)
+	parsed, err := cursor.Parse(c.String())
+	Expect(err).IsNil()
+	Expect(parsed.Sort).Equals("created")
+	Expect(parsed.Value).Equals("2019-06-22T21:10:00.123456Z")
+	Expect(parsed.ID).Equals(42)
+}
+
+func TestCursor_ParseInvalid(t *testing.T) {
+	RegisterT(t)
+
+	for _, input := range []string{
+		"",
+		"not base64!",
+		"dm90ZXM",
+		"dm90ZXN8YWJjfDEw",
+	} {
+		c, err := cursor.Parse(input)
+		Expect(c).IsNil()
+		Expect(err).Equals(cursor.ErrInvalid)
+	}
+}
@@ -31,6 +31,7 @@ func init() {
 	conn.SetMaxIdleConns(env.Config.Database.MaxIdleConns)
 	conn.SetMaxOpenConns(env.Config.Database.MaxOpenConns)
 	rowMapper = NewRowMapper()
+	registerMetrics(conn)
 }
 
 func Connection() *sql.DB {
@@ -52,6 +53,11 @@ func BeginTx(ctx context.Context) (*Trx, error) {
 	return &Trx{tx: tx, ctx: ctx}, nil
 }
 
+// WithContext returns a copy of the transaction that executes commands with given context, which must be derived from the original one
+func (trx *Trx) WithContext(ctx context.Context) *Trx {
+	return &Trx{tx: trx.tx, ctx: ctx}
+}
+
 func load(path string) {
 	content, err := ioutil.ReadFile(env.Path(path))
 	if err != nil {
@@ -81,7 +87,7 @@ var formatter = strings.NewReplacer("\t", "", "\n", " ")
 
 // Execute given SQL command
 func (trx *Trx) Execute(command string, args ...interface{}) (int64, error) {
-	if log.IsEnabled(log.DEBUG) {
+	if log.IsEnabledFor(trx.ctx, log.DEBUG) {
 		command = formatter.Replace(command)
 		start := time.Now()
 		defer func() {
@@ -93,7 +99,9 @@ func (trx *Trx) Execute(command string, args ...interface{}) (int64, error) {
 		}()
 	}
 
+	span := trx.startSpan(command)
 	result, err := trx.tx.ExecContext(trx.ctx, command, args...)
+	span.End(err)
 	if err != nil {
 		return 0, wrap(err, "failed to execute trx.Execute")
 	}
@@ -104,7 +112,7 @@ func (trx *Trx) Execute(command string, args ...interface{}) (int64, error) {
 
 // Scalar returns first row and first column
 func (trx *Trx) Scalar(data interface{}, command string, args ...interface{}) error {
-	if log.IsEnabled(log.DEBUG) {
+	if log.IsEnabledFor(trx.ctx, log.DEBUG) {
 		command = formatter.Replace(command)
 		start := time.Now()
 		defer func() {
@@ -116,8 +124,14 @@ func (trx *Trx) Scalar(data interface{}, command string, args ...interface{}) er
 		}()
 	}
 
+	span := trx.startSpan(command)
 	row := trx.tx.QueryRowContext(trx.ctx, command, args...)
 	err := row.Scan(data)
+	if err == sql.ErrNoRows {
+		span.End(nil)
+	} else {
+		span.End(err)
+	}
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return app.ErrNotFound
@@ -129,7 +143,7 @@ func (trx *Trx) Scalar(data interface{}, command string, args ...interface{}) er
 
 // Get first row and bind to given data
 func (trx *Trx) Get(data interface{}, command string, args ...interface{}) error {
-	if log.IsEnabled(log.DEBUG) {
+	if log.IsEnabledFor(trx.ctx, log.DEBUG) {
 		command = formatter.Replace(command)
 		start := time.Now()
 		defer func() {
@@ -141,7 +155,9 @@ func (trx *Trx) Get(data interface{}, command string, args ...interface{}) error
 		}()
 	}
 
+	span := trx.startSpan(command)
 	rows, err := trx.tx.QueryContext(trx.ctx, command, args...)
+	span.End(err)
 	if err != nil {
 		return wrap(err, "failed to execute trx.Get")
 	}
@@ -161,7 +177,7 @@ func (trx *Trx) Get(data interface{}, command string, args ...interface{}) error
 
 // Exists returns true if at least one record is found
 func (trx *Trx) Exists(command string, args ...interface{}) (bool, error) {
-	if log.IsEnabled(log.DEBUG) {
+	if log.IsEnabledFor(trx.ctx, log.DEBUG) {
 		command = formatter.Replace(command)
 		start := time.Now()
 		defer func() {
@@ -173,7 +189,9 @@ func (trx *Trx) Exists(command string, args ...interface{}) (bool, error) {
 		}()
 	}
 
+	span := trx.startSpan(command)
 	rows, err := trx.tx.QueryContext(trx.ctx, command, args...)
+	span.End(err)
 	if err != nil {
 		return false, wrap(err, "failed to execute trx.Exists")
 	}
@@ -184,7 +202,7 @@ func (trx *Trx) Exists(command string, args ...interface{}) (bool, error) {
 
 // Count returns number of rows
 func (trx *Trx) Count(command string, args ...interface{}) (int, error) {
-	if log.IsEnabled(log.DEBUG) {
+	if log.IsEnabledFor(trx.ctx, log.DEBUG) {
 		command = formatter.Replace(command)
 		start := time.Now()
 		defer func() {
@@ -196,7 +214,9 @@ func (trx *Trx) Count(command string, args ...interface{}) (int, error) {
 		}()
 	}
 
+	span := trx.startSpan(command)
 	rows, err := trx.tx.QueryContext(trx.ctx, command, args...)
+	span.End(err)
 	if err != nil {
 		return 0, wrap(err, "failed to execute trx.Count")
 	}
@@ -211,7 +231,7 @@ func (trx *Trx) Count(command string, args ...interface{}) (int, error) {
 
 //Select all matched rows bind to given data
 func (trx *Trx) Select(data interface{}, command string, args ...interface{}) error {
-	if log.IsEnabled(log.DEBUG) {
+	if log.IsEnabledFor(trx.ctx, log.DEBUG) {
 		command = formatter.Replace(command)
 		start := time.Now()
 		defer func() {
@@ -223,7 +243,9 @@ func (trx *Trx) Select(data interface{}, command string, args ...interface{}) er
 		}()
 	}
 
+	span := trx.startSpan(command)
 	rows, err := trx.tx.QueryContext(trx.ctx, command, args...)
+	span.End(err)
 	if err != nil {
 		return wrap(err, "failed to execute trx.Select")
 	}
@@ -252,7 +274,7 @@ func (trx *Trx) Select(data interface{}, command string, args ...interface{}) er
 
 //Query all matched rows and return raw sql.Rows
 func (trx *Trx) Query(command string, args ...interface{}) (*sql.Rows, error) {
-	if log.IsEnabled(log.DEBUG) {
+	if log.IsEnabledFor(trx.ctx, log.DEBUG) {
 		command = formatter.Replace(command)
 		start := time.Now()
 		defer func() {
@@ -264,7 +286,9 @@ func (trx *Trx) Query(command string, args ...interface{}) (*sql.Rows, error) {
 		}()
 	}
 
+	span := trx.startSpan(command)
 	rows, err := trx.tx.QueryContext(trx.ctx, command, args...)
+	span.End(err)
 	if err != nil {
 		return nil, wrap(err, "failed to execute trx.Select")
 	}
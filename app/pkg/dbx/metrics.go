@@ -0,0 +1,35 @@
+package dbx
+
+import (
+	"database/sql"
+
+	"github.com/getfider/fider/app/pkg/metrics"
+)
+
+//registerMetrics exposes the statistics of the connection pool, which are read whenever metrics are collected
+func registerMetrics(db *sql.DB) {
+	stat := func(fn func(stats sql.DBStats) float64) func() float64 {
+		return func() float64 {
+			return fn(db.Stats())
+		}
+	}
+
+	metrics.NewGaugeFunc("fider_db_max_open_connections", "Maximum number of open connections to the database.", stat(func(s sql.DBStats) float64 {
+		return float64(s.MaxOpenConnections)
+	}))
+	metrics.NewGaugeFunc("fider_db_open_connections", "Number of established connections to the database, both in use and idle.", stat(func(s sql.DBStats) float64 {
+		return float64(s.OpenConnections)
+	}))
+	metrics.NewGaugeFunc("fider_db_in_use_connections", "Number of connections currently in use.", stat(func(s sql.DBStats) float64 {
+		return float64(s.InUse)
+	}))
+	metrics.NewGaugeFunc("fider_db_idle_connections", "Number of idle connections.", stat(func(s sql.DBStats) float64 {
+		return float64(s.Idle)
+	}))
+	metrics.NewCounterFunc("fider_db_wait_count_total", "Number of connections waited for.", stat(func(s sql.DBStats) float64 {
+		return float64(s.WaitCount)
+	}))
+	metrics.NewCounterFunc("fider_db_wait_duration_seconds_total", "Time blocked waiting for a new connection in seconds.", stat(func(s sql.DBStats) float64 {
+		return s.WaitDuration.Seconds()
+	}))
+}
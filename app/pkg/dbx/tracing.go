@@ -0,0 +1,25 @@
+package dbx
+
+import (
+	"strings"
+
+	"github.com/getfider/fider/app/pkg/trace"
+)
+
+//startSpan starts the span of a single SQL command as a child of the current span of the transaction
+func (trx *Trx) startSpan(command string) *trace.Span {
+	if !trace.Enabled() {
+		return nil
+	}
+
+	statement := strings.TrimSpace(formatter.Replace(command))
+	operation := statement
+	if i := strings.IndexAny(statement, " ("); i > 0 {
+		operation = statement[:i]
+	}
+
+	_, span := trace.Start(trx.ctx, "SQL "+strings.ToUpper(operation), trace.KindClient)
+	span.SetAttribute("db.system", "postgresql")
+	span.SetAttribute("db.statement", statement)
+	return span
+}
@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
+	"time"
 
 	"path"
 
@@ -39,7 +40,20 @@ type config struct {
 		Host string `env:"CDN_HOST"`
 	}
 	Log struct {
-		Level string `env:"LOG_LEVEL,default=INFO"`
+		Level      string `env:"LOG_LEVEL,default=INFO"`
+		Levels     string `env:"LOG_LEVELS"`
+		LevelsFile string `env:"LOG_LEVELS_FILE"`
+		Sinks      string `env:"LOG_SINKS"`
+		Format     string `env:"LOG_FORMAT,default=text"`
+		File       struct {
+			Path       string `env:"LOG_FILE_PATH"`
+			MaxSizeMB  int    `env:"LOG_FILE_MAX_SIZE_MB,default=100,strict"`
+			MaxBackups int    `env:"LOG_FILE_MAX_BACKUPS,default=5,strict"`
+		}
+		Syslog struct {
+			Address string `env:"LOG_SYSLOG_ADDRESS"`
+			Tag     string `env:"LOG_SYSLOG_TAG,default=fider"`
+		}
 	}
 	OAuth struct {
 		Google struct {
@@ -60,9 +74,10 @@ type config struct {
 		Whitelist string `env:"EMAIL_WHITELIST"`
 		Blacklist string `env:"EMAIL_BLACKLIST"`
 		Mailgun   struct {
-			APIKey string `env:"EMAIL_MAILGUN_API"`
-			Domain string `env:"EMAIL_MAILGUN_DOMAIN"`
-			Region string `env:"EMAIL_MAILGUN_REGION,default=US"`
+			APIKey            string `env:"EMAIL_MAILGUN_API"`
+			Domain            string `env:"EMAIL_MAILGUN_DOMAIN"`
+			Region            string `env:"EMAIL_MAILGUN_REGION,default=US"`
+			WebhookSigningKey string `env:"EMAIL_MAILGUN_WEBHOOK_SIGNING_KEY"`
 		}
 		SMTP struct {
 			Host     string `env:"EMAIL_SMTP_HOST"`
@@ -70,6 +85,21 @@ type config struct {
 			Username string `env:"EMAIL_SMTP_USERNAME"`
 			Password string `env:"EMAIL_SMTP_PASSWORD"`
 		}
+		SES struct {
+			Region           string `env:"EMAIL_SES_REGION"`
+			AccessKeyID      string `env:"EMAIL_SES_ACCESS_KEY_ID"`
+			SecretAccessKey  string `env:"EMAIL_SES_SECRET_ACCESS_KEY"`
+			ConfigurationSet string `env:"EMAIL_SES_CONFIGURATION_SET"`
+			TopicARN         string `env:"EMAIL_SES_TOPIC_ARN"`
+		}
+		Maildir struct {
+			Path string `env:"EMAIL_MAILDIR_PATH"`
+		}
+		Inbound struct {
+			Domain   string `env:"EMAIL_INBOUND_DOMAIN"`
+			Listen   string `env:"EMAIL_INBOUND_LISTEN"`
+			Protocol string `env:"EMAIL_INBOUND_PROTOCOL,default=smtp"`
+		}
 	}
 	BlobStorage struct {
 		Type string `env:"BLOB_STORAGE,default=sql"`
@@ -83,12 +113,55 @@ type config struct {
 		FS struct {
 			Path string `env:"BLOB_STORAGE_FS_PATH"`
 		}
+		GC struct {
+			Interval    time.Duration `env:"BLOB_STORAGE_GC_INTERVAL,default=0,strict"`
+			GracePeriod time.Duration `env:"BLOB_STORAGE_GC_GRACE_PERIOD,default=24h,strict"`
+		}
+	}
+	Attachments struct {
+		MaxKilobytes         int `env:"ATTACHMENTS_MAX_KB,default=10240,strict"`
+		TenantQuotaMegabytes int `env:"ATTACHMENTS_TENANT_QUOTA_MB,default=1024,strict"`
+	}
+	Antivirus struct {
+		Command string        `env:"ANTIVIRUS_COMMAND"`
+		ICAPURL string        `env:"ANTIVIRUS_ICAP_URL"`
+		Timeout time.Duration `env:"ANTIVIRUS_TIMEOUT,default=30s,strict"`
 	}
 	Maintenance struct {
 		Enabled bool   `env:"MAINTENANCE,default=false,strict"`
 		Message string `env:"MAINTENANCE_MESSAGE"`
 		Until   string `env:"MAINTENANCE_UNTIL"`
 	}
+	Worker struct {
+		Queue        string        `env:"WORKER_QUEUE,default=memory"`
+		PollInterval time.Duration `env:"WORKER_POLL_INTERVAL,default=1s,strict"`
+		MaxAttempts  int           `env:"WORKER_MAX_ATTEMPTS,default=5,strict"`
+		RetryDelay   time.Duration `env:"WORKER_RETRY_DELAY,default=30s,strict"`
+		LockTimeout  time.Duration `env:"WORKER_LOCK_TIMEOUT,default=10m,strict"`
+	}
+	Posts struct {
+		UnmergeWindow time.Duration `env:"POST_UNMERGE_WINDOW,default=168h,strict"`
+	}
+	Webhooks struct {
+		MaxAttempts int           `env:"WEBHOOK_MAX_ATTEMPTS,default=5,strict"`
+		RetryDelay  time.Duration `env:"WEBHOOK_RETRY_DELAY,default=10s,strict"`
+	}
+	API struct {
+		LegacyKeys bool `env:"API_LEGACY_KEYS,default=true,strict"`
+	}
+	Metrics struct {
+		Token string `env:"METRICS_TOKEN"`
+	}
+	Tracing struct {
+		Exporter    string  `env:"TRACING_EXPORTER"`
+		ServiceName string  `env:"TRACING_SERVICE_NAME,default=fider"`
+		SampleRatio float64 `env:"TRACING_SAMPLE_RATIO,default=1,strict"`
+		FilePath    string  `env:"TRACING_FILE_PATH"`
+		OTLP        struct {
+			Endpoint string `env:"TRACING_OTLP_ENDPOINT,default=http://localhost:4318/v1/traces"`
+			Headers  string `env:"TRACING_OTLP_HEADERS"`
+		}
+	}
 	GoogleAnalytics string `env:"GOOGLE_ANALYTICS"`
 }
 
@@ -107,19 +180,50 @@ func Reload() {
 		panic(errors.Wrap(err, "failed to parse environment variables"))
 	}
 
-	if Config.Email.Mailgun.APIKey != "" {
+	switch EmailProvider() {
+	case "mailgun":
 		mustBeSet("EMAIL_MAILGUN_DOMAIN")
-	} else {
+	case "smtp":
 		mustBeSet("EMAIL_SMTP_HOST")
 		mustBeSet("EMAIL_SMTP_PORT")
 	}
 
+	if Config.Email.Inbound.Listen != "" {
+		mustBeSet("EMAIL_INBOUND_DOMAIN")
+	}
+
 	bsType := strings.ToLower(Config.BlobStorage.Type)
 	if bsType == "s3" {
 		mustBeSet("BLOB_STORAGE_S3_BUCKET")
 	} else if bsType == "fs" {
 		mustBeSet("BLOB_STORAGE_FS_PATH")
 	}
+
+	for _, sink := range LogSinks() {
+		switch sink {
+		case "console", "sql", "syslog":
+		case "file":
+			mustBeSet("LOG_FILE_PATH")
+		default:
+			panic(fmt.Errorf("Unknown log sink '%s', must be one of console, sql, file or syslog", sink))
+		}
+	}
+
+	if format := strings.ToLower(Config.Log.Format); format != "text" && format != "json" {
+		panic(fmt.Errorf("Unknown log format '%s', must be one of text or json", Config.Log.Format))
+	}
+
+	if Config.Antivirus.Command != "" && strings.TrimSpace(Config.Antivirus.Command) == "" {
+		panic(fmt.Errorf("ANTIVIRUS_COMMAND must be the command used to scan files, but it only has whitespace"))
+	}
+
+	switch strings.ToLower(Config.Tracing.Exporter) {
+	case "", "otlp", "stdout":
+	case "file":
+		mustBeSet("TRACING_FILE_PATH")
+	default:
+		panic(fmt.Errorf("Unknown tracing exporter '%s', must be one of otlp, stdout or file", Config.Tracing.Exporter))
+	}
 }
 
 func mustBeSet(name string) {
@@ -134,6 +238,60 @@ func IsBillingEnabled() bool {
 	return Config.Stripe.SecretKey != ""
 }
 
+// EmailProvider returns the name of the service used to send emails: mailgun, ses, maildir or smtp
+func EmailProvider() string {
+	if Config.Email.Mailgun.APIKey != "" {
+		return "mailgun"
+	}
+	if Config.Email.SES.Region != "" {
+		return "ses"
+	}
+	if Config.Email.Maildir.Path != "" {
+		return "maildir"
+	}
+	return "smtp"
+}
+
+// LogSinks returns the names of the services that write logs, which are console and sql by default
+func LogSinks() []string {
+	sinks := make([]string, 0)
+	for _, sink := range strings.Split(strings.ToLower(Config.Log.Sinks), ",") {
+		if sink = strings.TrimSpace(sink); sink != "" {
+			sinks = append(sinks, sink)
+		}
+	}
+	if len(sinks) == 0 {
+		return []string{"console", "sql"}
+	}
+	return sinks
+}
+
+// IsLogSinkEnabled returns true if logs are written by given sink
+func IsLogSinkEnabled(name string) bool {
+	for _, sink := range LogSinks() {
+		if sink == name {
+			return true
+		}
+	}
+	return false
+}
+
+// AntivirusProvider returns the name of the service used to scan uploaded files: command, icap or local
+func AntivirusProvider() string {
+	if Config.Antivirus.Command != "" {
+		return "command"
+	}
+	if Config.Antivirus.ICAPURL != "" {
+		return "icap"
+	}
+	return "local"
+}
+
+// IsReplyByEmailEnabled returns true if users can reply to notifications by email
+func IsReplyByEmailEnabled() bool {
+	return Config.Email.Inbound.Domain != ""
+}
+
 // IsSingleHostMode returns true if host mode is set to single tenant
 func IsSingleHostMode() bool {
 	return Config.HostMode == "single"
@@ -1,6 +1,7 @@
 package env_test
 
 import (
+	"os"
 	"testing"
 
 	. "github.com/getfider/fider/app/pkg/assert"
@@ -81,3 +82,30 @@ func TestSubdomain(t *testing.T) {
 	Expect(env.Subdomain("test.assets-fider.io")).Equals("")
 	Expect(env.Subdomain("helloworld.com")).Equals("")
 }
+
+func TestLogSinks(t *testing.T) {
+	RegisterT(t)
+
+	env.Config.Log.Sinks = ""
+	Expect(env.LogSinks()).Equals([]string{"console", "sql"})
+	Expect(env.IsLogSinkEnabled("sql")).IsTrue()
+	Expect(env.IsLogSinkEnabled("file")).IsFalse()
+
+	env.Config.Log.Sinks = "Console, file,"
+	Expect(env.LogSinks()).Equals([]string{"console", "file"})
+	Expect(env.IsLogSinkEnabled("sql")).IsFalse()
+	Expect(env.IsLogSinkEnabled("file")).IsTrue()
+}
+
+func TestReload_BlankAntivirusCommand(t *testing.T) {
+	RegisterT(t)
+	defer env.Reload()
+	defer os.Unsetenv("ANTIVIRUS_COMMAND")
+
+	os.Setenv("ANTIVIRUS_COMMAND", "  ")
+	Expect(env.Reload).Panics()
+
+	os.Setenv("ANTIVIRUS_COMMAND", "clamdscan --no-summary")
+	env.Reload()
+	Expect(env.AntivirusProvider()).Equals("command")
+}
@@ -0,0 +1,119 @@
+package i18n
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/pkg/env"
+	"github.com/getfider/fider/app/pkg/errors"
+)
+
+// DefaultLocale is the locale in which messages are written in the source code
+const DefaultLocale = "en"
+
+// Language is a locale that Fider can be translated to
+type Language struct {
+	Locale string `json:"locale"`
+	Name   string `json:"name"`
+}
+
+// Languages is the list of all supported languages
+var Languages = []Language{
+	{Locale: "en", Name: "English"},
+	{Locale: "fr", Name: "Français"},
+	{Locale: "de", Name: "Deutsch"},
+}
+
+// IsSupported returns true if given locale is one of the supported languages
+func IsSupported(locale string) bool {
+	for _, lang := range Languages {
+		if lang.Locale == locale {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	mutex    sync.RWMutex
+	catalogs = make(map[string]map[string]string)
+)
+
+//catalog returns the messages of given locale, keyed by their original (english) text
+//Catalogs are files named after the locale on the /locale folder
+func catalog(locale string) map[string]string {
+	mutex.RLock()
+	messages, ok := catalogs[locale]
+	mutex.RUnlock()
+	if ok && !env.IsDevelopment() {
+		return messages
+	}
+
+	messages = make(map[string]string)
+	content, err := ioutil.ReadFile(env.Path("/locale", locale+".json"))
+	if err == nil {
+		if err := json.Unmarshal(content, &messages); err != nil {
+			panic(errors.Wrap(err, "failed to parse message catalog '%s'", locale))
+		}
+	} else if !os.IsNotExist(err) {
+		panic(errors.Wrap(err, "failed to read message catalog '%s'", locale))
+	}
+
+	mutex.Lock()
+	catalogs[locale] = messages
+	mutex.Unlock()
+	return messages
+}
+
+// T translates given message to the locale and formats it with args, like fmt.Sprintf
+// Messages without a translation are kept in english
+// Translations can use explicit argument indexes (like %[2]s) to change the order of the arguments
+func T(locale, message string, args ...interface{}) string {
+	if locale != DefaultLocale && IsSupported(locale) {
+		if translated, ok := catalog(locale)[message]; ok && translated != "" {
+			message = translated
+		}
+	}
+
+	if len(args) == 0 {
+		return message
+	}
+	return fmt.Sprintf(message, args...)
+}
+
+// WithLocale returns a context in which given locale is used to translate messages
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, app.LocaleCtxKey, locale)
+}
+
+// Locale returns the locale that should be used on given context
+// It's the one set by WithLocale, otherwise the default locale of current tenant
+func Locale(ctx context.Context) string {
+	if locale, ok := ctx.Value(app.LocaleCtxKey).(string); ok && IsSupported(locale) {
+		return locale
+	}
+	tenant, _ := ctx.Value(app.TenantCtxKey).(*models.Tenant)
+	return TenantLocale(tenant)
+}
+
+// TenantLocale returns the default locale of given tenant
+func TenantLocale(tenant *models.Tenant) string {
+	if tenant != nil && IsSupported(tenant.Locale) {
+		return tenant.Locale
+	}
+	return DefaultLocale
+}
+
+// UserLocale returns the locale preferred by given user, falling back to the one of the tenant
+func UserLocale(user *models.User, tenant *models.Tenant) string {
+	if user != nil && IsSupported(user.Locale) {
+		return user.Locale
+	}
+	return TenantLocale(tenant)
+}
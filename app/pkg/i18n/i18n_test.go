@@ -0,0 +1,70 @@
+package i18n_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/models"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/i18n"
+)
+
+func TestT(t *testing.T) {
+	RegisterT(t)
+
+	Expect(i18n.T("en", "New post: **%s**", "Hello")).Equals("New post: **Hello**")
+	Expect(i18n.T("fr", "New post: **%s**", "Hello")).Equals("Nouvelle suggestion : **Hello**")
+	Expect(i18n.T("de", "**%s** changed status of **%s** to **%s**", "Jon", "Hello", "planned")).Equals("**Jon** hat den Status von **Hello** auf **planned** geändert")
+}
+
+func TestT_Fallback(t *testing.T) {
+	RegisterT(t)
+
+	Expect(i18n.T("fr", "This message is not translated")).Equals("This message is not translated")
+	Expect(i18n.T("es", "New post: **%s**", "Hello")).Equals("New post: **Hello**")
+	Expect(i18n.T("", "New post: **%s**", "Hello")).Equals("New post: **Hello**")
+}
+
+func TestT_WithoutArgs(t *testing.T) {
+	RegisterT(t)
+
+	Expect(i18n.T("en", "100% done")).Equals("100% done")
+}
+
+func TestIsSupported(t *testing.T) {
+	RegisterT(t)
+
+	Expect(i18n.IsSupported("en")).IsTrue()
+	Expect(i18n.IsSupported("fr")).IsTrue()
+	Expect(i18n.IsSupported("de")).IsTrue()
+	Expect(i18n.IsSupported("es")).IsFalse()
+	Expect(i18n.IsSupported("")).IsFalse()
+}
+
+func TestUserLocale(t *testing.T) {
+	RegisterT(t)
+
+	tenant := &models.Tenant{Locale: "de"}
+	Expect(i18n.UserLocale(&models.User{Locale: "fr"}, tenant)).Equals("fr")
+	Expect(i18n.UserLocale(&models.User{Locale: ""}, tenant)).Equals("de")
+	Expect(i18n.UserLocale(nil, tenant)).Equals("de")
+	Expect(i18n.UserLocale(nil, nil)).Equals("en")
+	Expect(i18n.TenantLocale(&models.Tenant{Locale: "xx"})).Equals("en")
+}
+
+func TestLocale(t *testing.T) {
+	RegisterT(t)
+
+	ctx := context.Background()
+	Expect(i18n.Locale(ctx)).Equals("en")
+
+	ctx = context.WithValue(ctx, app.TenantCtxKey, &models.Tenant{Locale: "de"})
+	Expect(i18n.Locale(ctx)).Equals("de")
+
+	ctx = i18n.WithLocale(ctx, "fr")
+	Expect(i18n.Locale(ctx)).Equals("fr")
+
+	ctx = i18n.WithLocale(ctx, "xx")
+	Expect(i18n.Locale(ctx)).Equals("de")
+}
@@ -0,0 +1,167 @@
+package images
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/gif"
+	"image/png"
+	"strconv"
+	"strings"
+
+	"github.com/disintegration/imaging"
+	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/goenning/imagic"
+)
+
+// MaxDimensionSize is the max width/height of a stored image. If image is bigger than this, it'll be downscaled on upload.
+const MaxDimensionSize = 1500
+
+// MaxPixels is the max number of pixels (width x height) of an image. Bigger images are rejected before being decoded,
+// as a small file can declare dimensions that would take gigabytes of memory to decode
+const MaxPixels = 40 * 1000 * 1000
+
+// ThumbnailSizes are the fixed sizes (longest side, in pixels) of the thumbnails generated for each uploaded image
+var ThumbnailSizes = []int{50, 100, 200}
+
+// ErrNotSupported is returned when given content is not an image in a supported format
+var ErrNotSupported = imagic.ErrNotSupported
+
+// ErrTooLarge is returned when given image has more than MaxPixels
+var ErrTooLarge = errors.New("Image has too many pixels")
+
+// Image is the result of processing an uploaded image
+type Image struct {
+	Content     []byte
+	ContentType string
+	Width       int
+	Height      int
+}
+
+// Normalize prepares an uploaded image to be stored
+// The image is rotated based on its EXIF orientation, downscaled if bigger than MaxDimensionSize and then encoded again,
+// which removes all of its metadata (EXIF, GPS location, camera details, etc.)
+// Animated GIFs are kept as they are because encoding them again would drop all frames except the first
+// Other GIFs are converted to PNG, which is lossless and usually smaller
+func Normalize(content []byte) (*Image, error) {
+	config, format, err := decodeConfig(content)
+	if err != nil {
+		return nil, err
+	}
+
+	if format == "gif" && isAnimated(content) {
+		return &Image{
+			Content:     content,
+			ContentType: "image/gif",
+			Width:       config.Width,
+			Height:      config.Height,
+		}, nil
+	}
+
+	img, err := imaging.Decode(bytes.NewReader(content), imaging.AutoOrientation(true))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode image")
+	}
+
+	return encode(fit(img, MaxDimensionSize), convert(format))
+}
+
+// Thumbnail returns a copy of given image that fits into a square of given size
+// GIF thumbnails are converted to PNG, so only the first frame of animated images is used
+func Thumbnail(content []byte, size int) (*Image, error) {
+	_, format, err := decodeConfig(content)
+	if err != nil {
+		return nil, err
+	}
+
+	img, err := imaging.Decode(bytes.NewReader(content), imaging.AutoOrientation(true))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode image")
+	}
+
+	return encode(fit(img, size), convert(format))
+}
+
+// ThumbnailKey returns the blob key in which the thumbnail of given size is stored
+func ThumbnailKey(bkey string, size int) string {
+	return fmt.Sprintf("%s@%d", bkey, size)
+}
+
+// ParseThumbnailKey returns the key of the original image when given key is the one of a thumbnail
+func ParseThumbnailKey(key string) (string, bool) {
+	i := strings.LastIndex(key, "@")
+	if i <= 0 {
+		return "", false
+	}
+	size, err := strconv.Atoi(key[i+1:])
+	if err != nil || !IsThumbnailSize(size) {
+		return "", false
+	}
+	return key[:i], true
+}
+
+// IsThumbnailSize returns true if thumbnails of given size are generated on upload
+func IsThumbnailSize(size int) bool {
+	for _, s := range ThumbnailSizes {
+		if s == size {
+			return true
+		}
+	}
+	return false
+}
+
+func decodeConfig(content []byte) (image.Config, string, error) {
+	config, format, err := image.DecodeConfig(bytes.NewReader(content))
+	if err != nil || (format != "png" && format != "gif" && format != "jpeg") {
+		return image.Config{}, "", ErrNotSupported
+	}
+	if int64(config.Width)*int64(config.Height) > MaxPixels {
+		return image.Config{}, "", ErrTooLarge
+	}
+	return config, format, nil
+}
+
+func convert(format string) string {
+	if format == "gif" {
+		return "png"
+	}
+	return format
+}
+
+func isAnimated(content []byte) bool {
+	g, err := gif.DecodeAll(bytes.NewReader(content))
+	return err == nil && len(g.Image) > 1
+}
+
+func fit(img image.Image, size int) image.Image {
+	b := img.Bounds()
+	if b.Dx() <= size && b.Dy() <= size {
+		return img
+	}
+	return imaging.Fit(img, size, size, imaging.Lanczos)
+}
+
+func encode(img image.Image, format string) (*Image, error) {
+	f, err := imaging.FormatFromExtension(format)
+	if err != nil {
+		return nil, ErrNotSupported
+	}
+
+	buf := new(bytes.Buffer)
+	err = imaging.Encode(
+		buf, img, f,
+		imaging.PNGCompressionLevel(png.BestCompression),
+		imaging.JPEGQuality(90),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode image")
+	}
+
+	b := img.Bounds()
+	return &Image{
+		Content:     buf.Bytes(),
+		ContentType: "image/" + format,
+		Width:       b.Dx(),
+		Height:      b.Dy(),
+	}, nil
+}
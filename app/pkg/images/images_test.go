@@ -0,0 +1,205 @@
+package images_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io/ioutil"
+	"testing"
+
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/env"
+	"github.com/getfider/fider/app/pkg/images"
+)
+
+func newImage(width, height int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			img.Set(x, y, color.RGBA{uint8(x), uint8(y), 100, 255})
+		}
+	}
+	return img
+}
+
+func newPNG(width, height int) []byte {
+	buf := new(bytes.Buffer)
+	_ = png.Encode(buf, newImage(width, height))
+	return buf.Bytes()
+}
+
+//newGIFWithScreenSize returns a tiny GIF that declares a logical screen of given size
+func newGIFWithScreenSize(width, height uint16) []byte {
+	buf := new(bytes.Buffer)
+	_ = gif.Encode(buf, newImage(1, 1), nil)
+	content := buf.Bytes()
+	binary.LittleEndian.PutUint16(content[6:], width)
+	binary.LittleEndian.PutUint16(content[8:], height)
+	return content
+}
+
+//newJPEGWithExif returns a JPEG with an APP1 segment that has given EXIF orientation and a camera model
+func newJPEGWithExif(width, height int, orientation uint16) []byte {
+	buf := new(bytes.Buffer)
+	_ = jpeg.Encode(buf, newImage(width, height), nil)
+	content := buf.Bytes()
+
+	model := []byte("SecretPhone\x00")
+	tiff := new(bytes.Buffer)
+	tiff.WriteString("II*\x00")
+	_ = binary.Write(tiff, binary.LittleEndian, uint32(8))
+	_ = binary.Write(tiff, binary.LittleEndian, uint16(2))
+	_ = binary.Write(tiff, binary.LittleEndian, []uint16{0x0110, 2})
+	_ = binary.Write(tiff, binary.LittleEndian, uint32(len(model)))
+	_ = binary.Write(tiff, binary.LittleEndian, uint32(8+2+2*12+4))
+	_ = binary.Write(tiff, binary.LittleEndian, []uint16{0x0112, 3})
+	_ = binary.Write(tiff, binary.LittleEndian, uint32(1))
+	_ = binary.Write(tiff, binary.LittleEndian, []uint16{orientation, 0})
+	_ = binary.Write(tiff, binary.LittleEndian, uint32(0))
+	tiff.Write(model)
+
+	app1 := new(bytes.Buffer)
+	app1.Write([]byte{0xFF, 0xE1})
+	_ = binary.Write(app1, binary.BigEndian, uint16(2+6+tiff.Len()))
+	app1.WriteString("Exif\x00\x00")
+	app1.Write(tiff.Bytes())
+
+	result := append([]byte{}, content[:2]...)
+	result = append(result, app1.Bytes()...)
+	return append(result, content[2:]...)
+}
+
+func TestNormalize_StripExifAndRotate(t *testing.T) {
+	RegisterT(t)
+
+	content := newJPEGWithExif(40, 20, 6)
+	Expect(bytes.Contains(content, []byte("SecretPhone"))).IsTrue()
+
+	img, err := images.Normalize(content)
+	Expect(err).IsNil()
+	Expect(img.ContentType).Equals("image/jpeg")
+	Expect(img.Width).Equals(20)
+	Expect(img.Height).Equals(40)
+	Expect(bytes.Contains(img.Content, []byte("Exif"))).IsFalse()
+	Expect(bytes.Contains(img.Content, []byte("SecretPhone"))).IsFalse()
+
+	config, format, err := image.DecodeConfig(bytes.NewReader(img.Content))
+	Expect(err).IsNil()
+	Expect(format).Equals("jpeg")
+	Expect(config.Width).Equals(20)
+	Expect(config.Height).Equals(40)
+}
+
+func TestNormalize_Downscale(t *testing.T) {
+	RegisterT(t)
+
+	img, err := images.Normalize(newPNG(3000, 1000))
+	Expect(err).IsNil()
+	Expect(img.ContentType).Equals("image/png")
+	Expect(img.Width).Equals(images.MaxDimensionSize)
+	Expect(img.Height).Equals(500)
+
+	img, err = images.Normalize(newPNG(200, 100))
+	Expect(err).IsNil()
+	Expect(img.Width).Equals(200)
+	Expect(img.Height).Equals(100)
+}
+
+func TestNormalize_GIF(t *testing.T) {
+	RegisterT(t)
+
+	content, _ := ioutil.ReadFile(env.Path("/app/pkg/web/testdata/logo3.gif"))
+	img, err := images.Normalize(content)
+	Expect(err).IsNil()
+	Expect(img.ContentType).Equals("image/png")
+
+	palette := color.Palette{color.Black, color.White}
+	animated := &gif.GIF{
+		Image: []*image.Paletted{
+			image.NewPaletted(image.Rect(0, 0, 10, 10), palette),
+			image.NewPaletted(image.Rect(0, 0, 10, 10), palette),
+		},
+		Delay: []int{10, 10},
+	}
+	buf := new(bytes.Buffer)
+	_ = gif.EncodeAll(buf, animated)
+
+	img, err = images.Normalize(buf.Bytes())
+	Expect(err).IsNil()
+	Expect(img.ContentType).Equals("image/gif")
+	Expect(img.Content).Equals(buf.Bytes())
+}
+
+func TestNormalize_NotSupported(t *testing.T) {
+	RegisterT(t)
+
+	img, err := images.Normalize([]byte("Hello World"))
+	Expect(err).Equals(images.ErrNotSupported)
+	Expect(img).IsNil()
+
+	content, _ := ioutil.ReadFile(env.Path("/app/pkg/web/testdata/favicon.ico"))
+	img, err = images.Normalize(content)
+	Expect(err).Equals(images.ErrNotSupported)
+	Expect(img).IsNil()
+}
+
+func TestNormalize_TooLarge(t *testing.T) {
+	RegisterT(t)
+
+	content := newGIFWithScreenSize(65535, 65535)
+	Expect(len(content) < 1024).IsTrue()
+
+	img, err := images.Normalize(content)
+	Expect(err).Equals(images.ErrTooLarge)
+	Expect(img).IsNil()
+
+	img, err = images.Thumbnail(content, 50)
+	Expect(err).Equals(images.ErrTooLarge)
+	Expect(img).IsNil()
+}
+
+func TestThumbnail(t *testing.T) {
+	RegisterT(t)
+
+	img, err := images.Thumbnail(newPNG(400, 300), 100)
+	Expect(err).IsNil()
+	Expect(img.ContentType).Equals("image/png")
+	Expect(img.Width).Equals(100)
+	Expect(img.Height).Equals(75)
+
+	content, _ := ioutil.ReadFile(env.Path("/app/pkg/web/testdata/logo3.gif"))
+	img, err = images.Thumbnail(content, 50)
+	Expect(err).IsNil()
+	Expect(img.ContentType).Equals("image/png")
+	Expect(img.Width <= 50 && img.Height <= 50).IsTrue()
+}
+
+func TestThumbnailKey(t *testing.T) {
+	RegisterT(t)
+
+	Expect(images.ThumbnailKey("attachments/abc-photo.jpg", 200)).Equals("attachments/abc-photo.jpg@200")
+	Expect(images.IsThumbnailSize(200)).IsTrue()
+	Expect(images.IsThumbnailSize(0)).IsFalse()
+	Expect(images.IsThumbnailSize(1500)).IsFalse()
+}
+
+func TestParseThumbnailKey(t *testing.T) {
+	RegisterT(t)
+
+	original, ok := images.ParseThumbnailKey("attachments/abc-photo.jpg@200")
+	Expect(ok).IsTrue()
+	Expect(original).Equals("attachments/abc-photo.jpg")
+
+	_, ok = images.ParseThumbnailKey("attachments/abc-photo.jpg")
+	Expect(ok).IsFalse()
+
+	_, ok = images.ParseThumbnailKey("attachments/abc-photo.jpg@123")
+	Expect(ok).IsFalse()
+
+	_, ok = images.ParseThumbnailKey("@50")
+	Expect(ok).IsFalse()
+}
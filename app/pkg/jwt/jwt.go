@@ -39,6 +39,18 @@ type OAuthClaims struct {
 	Metadata
 }
 
+// SAMLClaims represents what goes into temporary SAML JWT tokens
+type SAMLClaims struct {
+	SAMLNameID    string `json:"saml/nameid"`
+	SAMLName      string `json:"saml/name"`
+	SAMLEmail     string `json:"saml/email"`
+	SAMLRole      int    `json:"saml/role,omitempty"`
+	SAMLRequestID string `json:"saml/requestid,omitempty"`
+	SAMLTenantID  int    `json:"saml/tenant"`
+	SAMLIssuer    string `json:"saml/issuer"`
+	Metadata
+}
+
 // Encode creates new JWT token with given claims
 func Encode(claims jwtgo.Claims) (string, error) {
 	jwtToken := jwtgo.NewWithClaims(jwtgo.GetSigningMethod("HS256"), claims)
@@ -69,6 +81,16 @@ func DecodeOAuthClaims(token string) (*OAuthClaims, error) {
 	return claims, nil
 }
 
+// DecodeSAMLClaims extract SAMLClaims from given JWT token
+func DecodeSAMLClaims(token string) (*SAMLClaims, error) {
+	claims := &SAMLClaims{}
+	err := decode(token, claims)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode SAML claims")
+	}
+	return claims, nil
+}
+
 func decode(token string, claims jwtgo.Claims) error {
 	jwtToken, err := jwtgo.ParseWithClaims(token, claims, func(t *jwtgo.Token) (interface{}, error) {
 		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
@@ -135,3 +135,36 @@ func TestJWT_DecodeOAuthClaimsExpired(t *testing.T) {
 	Expect(err).IsNotNil()
 	Expect(decoded).IsNil()
 }
+
+func TestJWT_DecodeSAMLClaims(t *testing.T) {
+	RegisterT(t)
+
+	claims := &jwt.SAMLClaims{
+		SAMLNameID:    "jon.snow",
+		SAMLEmail:     "jon.snow@got.com",
+		SAMLName:      "Jon Snow",
+		SAMLRole:      3,
+		SAMLRequestID: "id-123",
+		SAMLTenantID:  2,
+		SAMLIssuer:    "http://www.okta.com/exk1",
+		Metadata: jwt.Metadata{
+			ExpiresAt: time.Now().Add(5 * time.Minute).Unix(),
+		},
+	}
+
+	token, _ := jwt.Encode(claims)
+
+	decoded, err := jwt.DecodeSAMLClaims(token)
+	Expect(err).IsNil()
+	Expect(decoded.SAMLNameID).Equals(claims.SAMLNameID)
+	Expect(decoded.SAMLEmail).Equals(claims.SAMLEmail)
+	Expect(decoded.SAMLName).Equals(claims.SAMLName)
+	Expect(decoded.SAMLRole).Equals(claims.SAMLRole)
+	Expect(decoded.SAMLRequestID).Equals(claims.SAMLRequestID)
+	Expect(decoded.SAMLTenantID).Equals(claims.SAMLTenantID)
+	Expect(decoded.SAMLIssuer).Equals(claims.SAMLIssuer)
+
+	decoded, err = jwt.DecodeSAMLClaims("not a token")
+	Expect(err).IsNotNil()
+	Expect(decoded).IsNil()
+}
@@ -0,0 +1,108 @@
+package log
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/getfider/fider/app/models/dto"
+	"github.com/getfider/fider/app/pkg/env"
+	"github.com/getfider/fider/app/pkg/trace"
+)
+
+// Entry is a single log message along with the properties of its context, as written by log sinks
+type Entry struct {
+	Time      time.Time `json:"time"`
+	Level     string    `json:"level"`
+	Tag       string    `json:"tag"`
+	ContextID string    `json:"contextId,omitempty"`
+	TraceID   string    `json:"traceId,omitempty"`
+	Tenant    int       `json:"tenant,omitempty"`
+	User      int       `json:"user,omitempty"`
+	Message   string    `json:"message"`
+	Props     dto.Props `json:"props,omitempty"`
+}
+
+// NewEntry creates the entry of a message logged on given context, replacing its placeholders with given props
+func NewEntry(ctx context.Context, level Level, message string, props dto.Props) *Entry {
+	props = GetProperties(ctx).Merge(props)
+
+	entry := &Entry{
+		Time:    time.Now(),
+		Level:   level.String(),
+		Tag:     "???",
+		Message: Parse(message, props, false),
+	}
+
+	if tag, ok := props[PropertyKeyTag].(string); ok && tag != "" {
+		entry.Tag = tag
+	}
+	if contextID := props[PropertyKeyContextID]; contextID != nil {
+		entry.ContextID = fmt.Sprint(contextID)
+	}
+	if span := trace.FromContext(ctx); span.IsValid() {
+		entry.TraceID = span.TraceID.String()
+	}
+	entry.Tenant = toInt(props[PropertyKeyTenantID])
+	entry.User = toInt(props[PropertyKeyUserID])
+
+	for _, key := range []string{PropertyKeyTag, PropertyKeyContextID, PropertyKeyTenantID, PropertyKeyUserID} {
+		delete(props, key)
+	}
+	if len(props) > 0 {
+		entry.Props = props
+	}
+
+	return entry
+}
+
+// JSON returns the entry encoded as a single line of JSON
+func (e *Entry) JSON() string {
+	data, err := json.Marshal(e)
+	if err != nil {
+		// Props that can't be encoded, such as channels, are replaced by their text representation
+		fallback := *e
+		fallback.Props = make(dto.Props, len(e.Props))
+		for key, value := range e.Props {
+			fallback.Props[key] = fmt.Sprint(value)
+		}
+		data, _ = json.Marshal(fallback)
+	}
+	return string(data)
+}
+
+// String returns the entry formatted as a single line of text
+func (e *Entry) String() string {
+	if e.ContextID == "" {
+		return fmt.Sprintf("%s [%s] [%s] %s", e.Level, e.Time.Format(time.RFC3339), e.Tag, e.Message)
+	}
+	return fmt.Sprintf("%s [%s] [%s] [%s] %s", e.Level, e.Time.Format(time.RFC3339), e.Tag, e.ContextID, e.Message)
+}
+
+// Format returns the entry as a single line of JSON or text, based on LOG_FORMAT
+func (e *Entry) Format() string {
+	if IsJSONFormat() {
+		return e.JSON()
+	}
+	return e.String()
+}
+
+// IsJSONFormat returns true if logs should be written as JSON lines
+func IsJSONFormat() bool {
+	return strings.ToLower(env.Config.Log.Format) == "json"
+}
+
+func toInt(value interface{}) int {
+	switch v := value.(type) {
+	case int:
+		return v
+	case int64:
+		return int(v)
+	case float64:
+		return int(v)
+	default:
+		return 0
+	}
+}
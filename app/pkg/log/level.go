@@ -1,5 +1,15 @@
 package log
 
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"sync"
+
+	"github.com/getfider/fider/app/pkg/env"
+)
+
 // Level defines all possible log levels
 type Level uint8
 
@@ -30,6 +40,16 @@ func parseLevel(level string) Level {
 	}
 }
 
+// lookupLevel returns the log.Level of given name, which must be one of DEBUG, INFO, WARN, ERROR or NONE
+func lookupLevel(name string) (Level, bool) {
+	for _, level := range []Level{DEBUG, INFO, WARN, ERROR, NONE} {
+		if level.String() == name {
+			return level, true
+		}
+	}
+	return 0, false
+}
+
 func (l Level) String() string {
 	switch l {
 	case DEBUG:
@@ -46,3 +66,113 @@ func (l Level) String() string {
 		return "???"
 	}
 }
+
+var (
+	levelsLock   sync.RWMutex
+	defaultLevel = INFO
+	tagLevels    = make(map[string]Level)
+)
+
+func init() {
+	if err := ReloadLevels(); err != nil {
+		panic(err)
+	}
+}
+
+// SetLevels changes the minimum level of all logs, except for the tags that have their own level on overrides
+func SetLevels(level Level, overrides map[string]Level) {
+	levelsLock.Lock()
+	defer levelsLock.Unlock()
+	defaultLevel = level
+	tagLevels = make(map[string]Level, len(overrides))
+	for tag, tagLevel := range overrides {
+		tagLevels[strings.ToUpper(tag)] = tagLevel
+	}
+}
+
+// LevelOf returns the minimum level of logs with given tag
+func LevelOf(tag string) Level {
+	levelsLock.RLock()
+	defer levelsLock.RUnlock()
+	if level, ok := tagLevels[strings.ToUpper(tag)]; ok {
+		return level
+	}
+	return defaultLevel
+}
+
+// ParseLevels parses per tag levels formatted as TAG=LEVEL, separated by commas or new lines
+func ParseLevels(value string) (map[string]Level, error) {
+	levels := make(map[string]Level)
+	for _, line := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == '\n' }) {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return nil, fmt.Errorf("invalid log level '%s', expected TAG=LEVEL", line)
+		}
+
+		level, ok := lookupLevel(strings.ToUpper(strings.TrimSpace(parts[1])))
+		if !ok {
+			return nil, fmt.Errorf("unknown log level '%s' for tag '%s'", parts[1], parts[0])
+		}
+		levels[strings.ToUpper(strings.TrimSpace(parts[0]))] = level
+	}
+	return levels, nil
+}
+
+// ReloadLevels sets log levels from LOG_LEVEL, LOG_LEVELS and the file on LOG_LEVELS_FILE, which can be changed without restarting.
+// Levels are kept as they are when any of them is invalid
+func ReloadLevels() error {
+	overrides, err := ParseLevels(env.Config.Log.Levels)
+	if err != nil {
+		return err
+	}
+
+	if path := env.Config.Log.LevelsFile; path != "" {
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read log levels file '%s': %v", path, err)
+		}
+
+		fileOverrides, err := ParseLevels(string(content))
+		if err != nil {
+			return fmt.Errorf("failed to parse log levels file '%s': %v", path, err)
+		}
+		for tag, level := range fileOverrides {
+			overrides[tag] = level
+		}
+	}
+
+	level := parseLevel(strings.ToUpper(env.Config.Log.Level))
+	if all, ok := overrides["*"]; ok {
+		level = all
+		delete(overrides, "*")
+	}
+
+	SetLevels(level, overrides)
+	return nil
+}
+
+// IsEnabled returns true if given level is logged for at least one tag
+func IsEnabled(level Level) bool {
+	levelsLock.RLock()
+	defer levelsLock.RUnlock()
+	if defaultLevel <= level {
+		return true
+	}
+	for _, tagLevel := range tagLevels {
+		if tagLevel <= level {
+			return true
+		}
+	}
+	return false
+}
+
+// IsEnabledFor returns true if given level is logged for the tag of given context
+func IsEnabledFor(ctx context.Context, level Level) bool {
+	tag, _ := GetProperty(ctx, PropertyKeyTag).(string)
+	return LevelOf(tag) <= level
+}
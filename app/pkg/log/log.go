@@ -2,20 +2,12 @@ package log
 
 import (
 	"context"
-	"strings"
 
 	"github.com/getfider/fider/app/models/cmd"
 	"github.com/getfider/fider/app/models/dto"
 	"github.com/getfider/fider/app/pkg/bus"
-	"github.com/getfider/fider/app/pkg/env"
 )
 
-var CurrentLevel = parseLevel(strings.ToUpper(env.Config.Log.Level))
-
-func IsEnabled(level Level) bool {
-	return CurrentLevel <= level
-}
-
 func Debug(ctx context.Context, message string) {
 	bus.Publish(ctx, &cmd.LogDebug{Message: message})
 }
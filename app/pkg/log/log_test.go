@@ -2,11 +2,17 @@ package log_test
 
 import (
 	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"strings"
 	"testing"
 
 	"github.com/getfider/fider/app/models/dto"
 	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/env"
 	"github.com/getfider/fider/app/pkg/log"
+	"github.com/getfider/fider/app/pkg/trace"
 )
 
 func TestLog_WithProperty(t *testing.T) {
@@ -33,3 +39,114 @@ func TestLog_WithProperties(t *testing.T) {
 		"Age":  15,
 	})
 }
+
+func TestParseLevels(t *testing.T) {
+	RegisterT(t)
+
+	levels, err := log.ParseLevels("BGW=DEBUG, web=warn\n# comment\nIMPORT=NONE")
+	Expect(err).IsNil()
+	Expect(levels).Equals(map[string]log.Level{
+		"BGW":    log.DEBUG,
+		"WEB":    log.WARN,
+		"IMPORT": log.NONE,
+	})
+
+	_, err = log.ParseLevels("BGW")
+	Expect(err).IsNotNil()
+
+	_, err = log.ParseLevels("BGW=VERBOSE")
+	Expect(err).IsNotNil()
+}
+
+func TestSetLevels(t *testing.T) {
+	RegisterT(t)
+	defer log.ReloadLevels()
+
+	log.SetLevels(log.WARN, map[string]log.Level{"bgw": log.DEBUG})
+	Expect(log.LevelOf("BGW")).Equals(log.DEBUG)
+	Expect(log.LevelOf("WEB")).Equals(log.WARN)
+	Expect(log.IsEnabled(log.DEBUG)).IsTrue()
+
+	web := log.WithProperty(context.Background(), log.PropertyKeyTag, "WEB")
+	bgw := log.WithProperty(context.Background(), log.PropertyKeyTag, "BGW")
+	Expect(log.IsEnabledFor(web, log.INFO)).IsFalse()
+	Expect(log.IsEnabledFor(web, log.ERROR)).IsTrue()
+	Expect(log.IsEnabledFor(bgw, log.DEBUG)).IsTrue()
+
+	log.SetLevels(log.ERROR, nil)
+	Expect(log.IsEnabled(log.WARN)).IsFalse()
+	Expect(log.IsEnabledFor(bgw, log.DEBUG)).IsFalse()
+}
+
+func TestReloadLevels(t *testing.T) {
+	RegisterT(t)
+	defer log.ReloadLevels()
+
+	file, err := ioutil.TempFile("", "log-levels")
+	Expect(err).IsNil()
+	defer os.Remove(file.Name())
+
+	env.Config.Log.Level = "INFO"
+	env.Config.Log.Levels = "BGW=DEBUG,WEB=ERROR"
+	env.Config.Log.LevelsFile = file.Name()
+
+	_ = ioutil.WriteFile(file.Name(), []byte("WEB=WARN\n*=ERROR\n"), 0644)
+	err = log.ReloadLevels()
+	Expect(err).IsNil()
+	Expect(log.LevelOf("BGW")).Equals(log.DEBUG)
+	Expect(log.LevelOf("WEB")).Equals(log.WARN)
+	Expect(log.LevelOf("IMPORT")).Equals(log.ERROR)
+
+	_ = ioutil.WriteFile(file.Name(), []byte("WEB=LOUD\n"), 0644)
+	err = log.ReloadLevels()
+	Expect(err).IsNotNil()
+	Expect(log.LevelOf("WEB")).Equals(log.WARN)
+}
+
+func TestNewEntry(t *testing.T) {
+	RegisterT(t)
+
+	ctx := log.WithProperties(context.Background(), dto.Props{
+		log.PropertyKeyTag:       "WEB",
+		log.PropertyKeyContextID: "ABC123",
+		log.PropertyKeyTenantID:  2,
+		log.PropertyKeyUserID:    5,
+		log.PropertyKeySessionID: "S1",
+	})
+	parent, _ := trace.ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	ctx = trace.WithParent(ctx, parent)
+
+	entry := log.NewEntry(ctx, log.WARN, "Hello @{Name:yellow}", dto.Props{"Name": "Jon"})
+	Expect(entry.Level).Equals("WARN")
+	Expect(entry.Tag).Equals("WEB")
+	Expect(entry.ContextID).Equals("ABC123")
+	Expect(entry.TraceID).Equals("4bf92f3577b34da6a3ce929d0e0e4736")
+	Expect(entry.Tenant).Equals(2)
+	Expect(entry.User).Equals(5)
+	Expect(entry.Message).Equals("Hello Jon")
+	Expect(entry.Props).Equals(dto.Props{"SessionID": "S1", "Name": "Jon"})
+
+	var decoded map[string]interface{}
+	err := json.Unmarshal([]byte(entry.JSON()), &decoded)
+	Expect(err).IsNil()
+	Expect(decoded["level"]).Equals("WARN")
+	Expect(decoded["tag"]).Equals("WEB")
+	Expect(decoded["contextId"]).Equals("ABC123")
+	Expect(decoded["tenant"]).Equals(float64(2))
+	Expect(decoded["user"]).Equals(float64(5))
+	Expect(decoded["message"]).Equals("Hello Jon")
+	Expect(decoded["props"]).Equals(map[string]interface{}{"SessionID": "S1", "Name": "Jon"})
+
+	Expect(entry.String()).ContainsSubstring("WARN [")
+	Expect(entry.String()).ContainsSubstring("] [WEB] [ABC123] Hello Jon")
+}
+
+func TestNewEntry_WithoutContext(t *testing.T) {
+	RegisterT(t)
+
+	entry := log.NewEntry(context.Background(), log.INFO, "Starting", dto.Props{"Channel": make(chan int)})
+	Expect(entry.Tag).Equals("???")
+	Expect(entry.ContextID).Equals("")
+	Expect(entry.JSON()).ContainsSubstring(`"props":{"Channel":"0x`)
+	Expect(strings.Contains(entry.JSON(), `"tenant"`)).IsFalse()
+}
@@ -0,0 +1,280 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+//DefaultBuckets are the upper bounds in seconds used by latency histograms
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+type collector interface {
+	write(w *bufio.Writer)
+}
+
+var (
+	registryLock sync.RWMutex
+	registry     = make(map[string]collector)
+)
+
+//register adds a collector to the registry, replacing any previous collector with the same name
+func register(name string, c collector) {
+	registryLock.Lock()
+	defer registryLock.Unlock()
+	registry[name] = c
+}
+
+//WriteText writes all registered metrics using Prometheus text exposition format
+func WriteText(w io.Writer) error {
+	registryLock.RLock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	collectors := make([]collector, len(names))
+	for i, name := range names {
+		collectors[i] = registry[name]
+	}
+	registryLock.RUnlock()
+
+	buffer := bufio.NewWriter(w)
+	for _, c := range collectors {
+		c.write(buffer)
+	}
+	return buffer.Flush()
+}
+
+type desc struct {
+	name   string
+	help   string
+	kind   string
+	labels []string
+}
+
+func (d desc) writeHeader(w *bufio.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n", d.name, escapeHelp(d.help))
+	fmt.Fprintf(w, "# TYPE %s %s\n", d.name, d.kind)
+}
+
+//key returns the map key of given label values, panicking if they don't match the labels of the metric
+func (d desc) key(labelValues []string) string {
+	if len(labelValues) != len(d.labels) {
+		panic(fmt.Sprintf("metric '%s' expects %d label values, got %d", d.name, len(d.labels), len(labelValues)))
+	}
+	return strings.Join(labelValues, "\xff")
+}
+
+//Counter is a metric that only goes up, split by label values
+type Counter struct {
+	desc
+	lock   sync.Mutex
+	values map[string]*counterValue
+}
+
+type counterValue struct {
+	labelValues []string
+	value       float64
+}
+
+//NewCounter creates and registers a new counter
+func NewCounter(name, help string, labels ...string) *Counter {
+	c := &Counter{
+		desc:   desc{name: name, help: help, kind: "counter", labels: labels},
+		values: make(map[string]*counterValue),
+	}
+	register(name, c)
+	return c
+}
+
+//Inc increments the counter of given label values by one
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+//Add increments the counter of given label values by given value
+func (c *Counter) Add(value float64, labelValues ...string) {
+	key := c.key(labelValues)
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	v, ok := c.values[key]
+	if !ok {
+		v = &counterValue{labelValues: labelValues}
+		c.values[key] = v
+	}
+	v.value += value
+}
+
+//Value returns current value of the counter for given label values
+func (c *Counter) Value(labelValues ...string) float64 {
+	key := c.key(labelValues)
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if v, ok := c.values[key]; ok {
+		return v.value
+	}
+	return 0
+}
+
+func (c *Counter) write(w *bufio.Writer) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.writeHeader(w)
+	keys := make([]string, 0, len(c.values))
+	for key := range c.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		v := c.values[key]
+		fmt.Fprintf(w, "%s%s %s\n", c.name, formatLabels(c.labels, v.labelValues), formatValue(v.value))
+	}
+}
+
+//Histogram is a metric that samples observations into buckets, split by label values
+type Histogram struct {
+	desc
+	buckets []float64
+	lock    sync.Mutex
+	values  map[string]*histogramValue
+}
+
+type histogramValue struct {
+	labelValues []string
+	counts      []uint64
+	count       uint64
+	sum         float64
+}
+
+//NewHistogram creates and registers a new histogram with given bucket upper bounds
+func NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	h := &Histogram{
+		desc:    desc{name: name, help: help, kind: "histogram", labels: labels},
+		buckets: buckets,
+		values:  make(map[string]*histogramValue),
+	}
+	register(name, h)
+	return h
+}
+
+//Observe adds a single observation to the histogram of given label values
+func (h *Histogram) Observe(value float64, labelValues ...string) {
+	key := h.key(labelValues)
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	v, ok := h.values[key]
+	if !ok {
+		v = &histogramValue{labelValues: labelValues, counts: make([]uint64, len(h.buckets))}
+		h.values[key] = v
+	}
+	for i, bound := range h.buckets {
+		if value <= bound {
+			v.counts[i]++
+		}
+	}
+	v.count++
+	v.sum += value
+}
+
+//ObserveSince adds the seconds elapsed since given time to the histogram of given label values
+func (h *Histogram) ObserveSince(start time.Time, labelValues ...string) {
+	h.Observe(time.Since(start).Seconds(), labelValues...)
+}
+
+//Count returns how many observations were made for given label values
+func (h *Histogram) Count(labelValues ...string) uint64 {
+	key := h.key(labelValues)
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	if v, ok := h.values[key]; ok {
+		return v.count
+	}
+	return 0
+}
+
+func (h *Histogram) write(w *bufio.Writer) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.writeHeader(w)
+	labels := withLabel(h.labels, "le")
+	keys := make([]string, 0, len(h.values))
+	for key := range h.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		v := h.values[key]
+		for i, bound := range h.buckets {
+			bucketLabels := formatLabels(labels, withLabel(v.labelValues, formatValue(bound)))
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, bucketLabels, v.counts[i])
+		}
+		infLabels := formatLabels(labels, withLabel(v.labelValues, "+Inf"))
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, infLabels, v.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.name, formatLabels(h.labels, v.labelValues), formatValue(v.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.name, formatLabels(h.labels, v.labelValues), v.count)
+	}
+}
+
+//funcMetric is a metric without labels whose value is read when metrics are collected
+type funcMetric struct {
+	desc
+	fn func() float64
+}
+
+//NewGaugeFunc registers a gauge whose value is given by fn, which can go up and down
+func NewGaugeFunc(name, help string, fn func() float64) {
+	register(name, &funcMetric{desc: desc{name: name, help: help, kind: "gauge"}, fn: fn})
+}
+
+//NewCounterFunc registers a counter whose value is given by fn, which must only go up
+func NewCounterFunc(name, help string, fn func() float64) {
+	register(name, &funcMetric{desc: desc{name: name, help: help, kind: "counter"}, fn: fn})
+}
+
+func (m *funcMetric) write(w *bufio.Writer) {
+	m.writeHeader(w)
+	fmt.Fprintf(w, "%s %s\n", m.name, formatValue(m.fn()))
+}
+
+//withLabel returns a copy of given labels with one more label at the end
+func withLabel(labels []string, label string) []string {
+	result := make([]string, len(labels), len(labels)+1)
+	copy(result, labels)
+	return append(result, label)
+}
+
+func formatLabels(labels, values []string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	pairs := make([]string, len(labels))
+	for i, label := range labels {
+		pairs[i] = fmt.Sprintf(`%s="%s"`, label, escapeLabelValue(values[i]))
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func formatValue(value float64) string {
+	if math.IsInf(value, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+var labelValueReplacer = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+var helpReplacer = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+
+func escapeLabelValue(value string) string {
+	return labelValueReplacer.Replace(value)
+}
+
+func escapeHelp(help string) string {
+	return helpReplacer.Replace(help)
+}
@@ -0,0 +1,78 @@
+package metrics_test
+
+import (
+	"bytes"
+	"testing"
+
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/metrics"
+)
+
+func TestCounter(t *testing.T) {
+	RegisterT(t)
+
+	counter := metrics.NewCounter("test_requests_total", "Number of requests.", "method", "code")
+	counter.Inc("GET", "200")
+	counter.Inc("GET", "200")
+	counter.Add(3, "POST", "400")
+
+	Expect(counter.Value("GET", "200")).Equals(float64(2))
+	Expect(counter.Value("POST", "400")).Equals(float64(3))
+	Expect(counter.Value("PUT", "200")).Equals(float64(0))
+
+	buffer := new(bytes.Buffer)
+	err := metrics.WriteText(buffer)
+	Expect(err).IsNil()
+	Expect(buffer.String()).ContainsSubstring(`# HELP test_requests_total Number of requests.
+# TYPE test_requests_total counter
+test_requests_total{method="GET",code="200"} 2
+test_requests_total{method="POST",code="400"} 3
+`)
+}
+
+func TestCounter_WrongLabels(t *testing.T) {
+	RegisterT(t)
+
+	counter := metrics.NewCounter("test_wrong_labels_total", "Number of things.", "name")
+	Expect(func() {
+		counter.Inc()
+	}).Panics()
+}
+
+func TestHistogram(t *testing.T) {
+	RegisterT(t)
+
+	histogram := metrics.NewHistogram("test_duration_seconds", "Duration of things.", []float64{0.1, 1}, "name")
+	histogram.Observe(0.05, "a\"b")
+	histogram.Observe(0.5, "a\"b")
+	histogram.Observe(2, "a\"b")
+
+	Expect(histogram.Count("a\"b")).Equals(uint64(3))
+
+	buffer := new(bytes.Buffer)
+	err := metrics.WriteText(buffer)
+	Expect(err).IsNil()
+	Expect(buffer.String()).ContainsSubstring(`# TYPE test_duration_seconds histogram
+test_duration_seconds_bucket{name="a\"b",le="0.1"} 1
+test_duration_seconds_bucket{name="a\"b",le="1"} 2
+test_duration_seconds_bucket{name="a\"b",le="+Inf"} 3
+test_duration_seconds_sum{name="a\"b"} 2.55
+test_duration_seconds_count{name="a\"b"} 3
+`)
+}
+
+func TestGaugeFunc(t *testing.T) {
+	RegisterT(t)
+
+	metrics.NewGaugeFunc("test_queue_length", "Length of the queue.", func() float64 {
+		return 1
+	})
+	metrics.NewGaugeFunc("test_queue_length", "Length of the queue.", func() float64 {
+		return 42
+	})
+
+	buffer := new(bytes.Buffer)
+	err := metrics.WriteText(buffer)
+	Expect(err).IsNil()
+	Expect(buffer.String()).ContainsSubstring("# TYPE test_queue_length gauge\ntest_queue_length 42\n")
+}
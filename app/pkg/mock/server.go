@@ -127,6 +127,17 @@ func (s *Server) ExecutePost(handler web.HandlerFunc, body string) (int, *httpte
 	return s.Execute(handler)
 }
 
+// ExecutePostForm executes given handler as a form POST and return response
+func (s *Server) ExecutePostForm(handler web.HandlerFunc, form url.Values) (int, *httptest.ResponseRecorder) {
+	body := form.Encode()
+	s.context.Request.Method = "POST"
+	s.context.Request.Body = body
+	s.context.Request.ContentLength = int64(len(body))
+	s.context.Request.SetHeader("Content-Type", "application/x-www-form-urlencoded")
+
+	return s.Execute(handler)
+}
+
 // ExecutePostAsJSON executes given handler as POST and return json response
 func (s *Server) ExecutePostAsJSON(handler web.HandlerFunc, body string) (int, *jsonq.Query) {
 	code, response := s.ExecutePost(handler, body)
@@ -43,17 +43,19 @@ func NewWorker() *Worker {
 
 func seed() {
 	DemoTenant = &models.Tenant{
-		ID:        1,
-		Name:      "Demonstration",
-		Subdomain: "demo",
-		Status:    enum.TenantActive,
+		ID:              1,
+		Name:            "Demonstration",
+		Subdomain:       "demo",
+		Status:          enum.TenantActive,
+		IsRoadmapPublic: true,
 	}
 	AvengersTenant = &models.Tenant{
-		ID:        2,
-		Name:      "Avengers",
-		Subdomain: "avengers",
-		Status:    enum.TenantActive,
-		CNAME:     "feedback.theavengers.com",
+		ID:              2,
+		Name:            "Avengers",
+		Subdomain:       "avengers",
+		Status:          enum.TenantActive,
+		CNAME:           "feedback.theavengers.com",
+		IsRoadmapPublic: true,
 	}
 
 	JonSnow = &models.User{
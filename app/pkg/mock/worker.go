@@ -56,10 +56,23 @@ func (w *Worker) Execute(task worker.Task) error {
 		task.OriginContext = context.WithValue(task.OriginContext, app.RequestCtxKey, web.Request{URL: u})
 	}
 
-	context := worker.NewContext(context.Background(), "0", task)
+	context := worker.NewContext(inlineWorker{context.Background()}, "0", task)
 	return task.Job(context)
 }
 
+// inlineWorker runs tasks enqueued by other tasks right away, ignoring their delay
+type inlineWorker struct {
+	context.Context
+}
+
+func (w inlineWorker) Run(id string)                        {}
+func (w inlineWorker) Use(middleware worker.MiddlewareFunc) {}
+func (w inlineWorker) Length() int64                        { return 0 }
+func (w inlineWorker) Shutdown(ctx context.Context) error   { return nil }
+func (w inlineWorker) Enqueue(task worker.Task) {
+	_ = task.Job(worker.NewContext(w, "0", task))
+}
+
 // NewNoopTask returns a worker task that does nothing
 func NewNoopTask() worker.Task {
 	return worker.Task{
@@ -0,0 +1,298 @@
+package saml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/getfider/fider/app/pkg/errors"
+)
+
+const (
+	c14nExclusive             = "http://www.w3.org/2001/10/xml-exc-c14n#"
+	c14nExclusiveWithComments = "http://www.w3.org/2001/10/xml-exc-c14n#WithComments"
+	c14nInclusive             = "http://www.w3.org/TR/2001/REC-xml-c14n-20010315"
+	c14nInclusiveWithComments = "http://www.w3.org/TR/2001/REC-xml-c14n-20010315#WithComments"
+	xmlNamespace              = "http://www.w3.org/XML/1998/namespace"
+)
+
+//element is a minimal XML DOM that keeps namespace prefixes as written,
+//which is needed to canonicalize signed elements the same way the IdP did
+type element struct {
+	parent   *element
+	prefix   string
+	local    string
+	ns       map[string]string
+	attrs    []xml.Attr
+	children []interface{}
+}
+
+func parseXML(data []byte) (*element, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	var root, current *element
+
+	for {
+		token, err := decoder.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse XML")
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if root != nil && current == nil {
+				return nil, errors.New("XML document has more than one root element")
+			}
+			el := &element{
+				parent: current,
+				prefix: t.Name.Space,
+				local:  t.Name.Local,
+				ns:     make(map[string]string),
+			}
+			for _, attr := range t.Attr {
+				if attr.Name.Space == "xmlns" {
+					el.ns[attr.Name.Local] = attr.Value
+				} else if attr.Name.Space == "" && attr.Name.Local == "xmlns" {
+					el.ns[""] = attr.Value
+				} else {
+					el.attrs = append(el.attrs, attr)
+				}
+			}
+			if current == nil {
+				root = el
+			} else {
+				current.children = append(current.children, el)
+			}
+			current = el
+		case xml.EndElement:
+			if current == nil || current.prefix != t.Name.Space || current.local != t.Name.Local {
+				return nil, errors.New("XML element '%s' is not properly closed", t.Name.Local)
+			}
+			current = current.parent
+		case xml.CharData:
+			if current != nil {
+				current.children = append(current.children, string(t))
+			}
+		case xml.Directive:
+			return nil, errors.New("XML documents with DTD are not supported")
+		}
+	}
+
+	if root == nil || current != nil {
+		return nil, errors.New("XML document is incomplete")
+	}
+	return root, nil
+}
+
+//lookupNS returns the namespace bound to given prefix in the scope of this element
+func (e *element) lookupNS(prefix string) (string, bool) {
+	if prefix == "xml" {
+		return xmlNamespace, true
+	}
+	for el := e; el != nil; el = el.parent {
+		if uri, ok := el.ns[prefix]; ok {
+			return uri, true
+		}
+	}
+	return "", false
+}
+
+func (e *element) namespace() string {
+	uri, _ := e.lookupNS(e.prefix)
+	return uri
+}
+
+func (e *element) is(namespace, local string) bool {
+	return e.local == local && e.namespace() == namespace
+}
+
+func (e *element) attr(name string) string {
+	for _, attr := range e.attrs {
+		if attr.Name.Space == "" && attr.Name.Local == name {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+func (e *element) text() string {
+	var buf strings.Builder
+	for _, child := range e.children {
+		if s, ok := child.(string); ok {
+			buf.WriteString(s)
+		}
+	}
+	return strings.TrimSpace(buf.String())
+}
+
+func (e *element) elements(namespace, local string) []*element {
+	list := make([]*element, 0)
+	for _, child := range e.children {
+		if el, ok := child.(*element); ok && el.is(namespace, local) {
+			list = append(list, el)
+		}
+	}
+	return list
+}
+
+func (e *element) first(namespace, local string) *element {
+	if list := e.elements(namespace, local); len(list) > 0 {
+		return list[0]
+	}
+	return nil
+}
+
+//walk calls fn for this element and all its descendants
+func (e *element) walk(fn func(*element)) {
+	fn(e)
+	for _, child := range e.children {
+		if el, ok := child.(*element); ok {
+			el.walk(fn)
+		}
+	}
+}
+
+//inScope returns all namespaces visible from this element, nearest declarations win
+func (e *element) inScope() map[string]string {
+	scope := make(map[string]string)
+	for el := e; el != nil; el = el.parent {
+		for prefix, uri := range el.ns {
+			if _, ok := scope[prefix]; !ok {
+				scope[prefix] = uri
+			}
+		}
+	}
+	return scope
+}
+
+type canonicalizer struct {
+	exclusive bool
+	prefixes  []string
+	skip      *element
+	buf       bytes.Buffer
+}
+
+//canonicalize writes given element as Canonical XML (inclusive or exclusive, always without comments)
+//skip is omitted from the output, which is how the enveloped signature transform is applied
+func canonicalize(e *element, algorithm string, inclusivePrefixes []string, skip *element) ([]byte, error) {
+	c := &canonicalizer{skip: skip, prefixes: inclusivePrefixes}
+	switch algorithm {
+	case c14nExclusive, c14nExclusiveWithComments:
+		c.exclusive = true
+	case c14nInclusive, c14nInclusiveWithComments:
+		c.exclusive = false
+	default:
+		return nil, errors.New("canonicalization method '%s' is not supported", algorithm)
+	}
+	c.write(e, map[string]string{})
+	return c.buf.Bytes(), nil
+}
+
+func (c *canonicalizer) write(e *element, rendered map[string]string) {
+	candidates := make(map[string]bool)
+	if c.exclusive {
+		candidates[e.prefix] = true
+		for _, attr := range e.attrs {
+			if attr.Name.Space != "" && attr.Name.Space != "xml" {
+				candidates[attr.Name.Space] = true
+			}
+		}
+		for _, prefix := range c.prefixes {
+			if prefix == "#default" {
+				prefix = ""
+			}
+			if _, ok := e.lookupNS(prefix); ok {
+				candidates[prefix] = true
+			}
+		}
+	} else {
+		for prefix := range e.inScope() {
+			candidates[prefix] = true
+		}
+	}
+
+	declarations := make([]string, 0, len(candidates))
+	scope := make(map[string]string, len(rendered))
+	for prefix, uri := range rendered {
+		scope[prefix] = uri
+	}
+	for prefix := range candidates {
+		uri, _ := e.lookupNS(prefix)
+		if prefix == "xml" || (uri == "" && prefix != "") {
+			continue
+		}
+		if current, ok := rendered[prefix]; (ok && current == uri) || (!ok && uri == "") {
+			continue
+		}
+		scope[prefix] = uri
+		declarations = append(declarations, prefix)
+	}
+	sort.Strings(declarations)
+
+	attrs := make([]xml.Attr, len(e.attrs))
+	copy(attrs, e.attrs)
+	attrNS := func(attr xml.Attr) string {
+		if attr.Name.Space == "" {
+			return ""
+		}
+		uri, _ := e.lookupNS(attr.Name.Space)
+		return uri
+	}
+	sort.SliceStable(attrs, func(i, j int) bool {
+		nsi, nsj := attrNS(attrs[i]), attrNS(attrs[j])
+		if nsi != nsj {
+			return nsi < nsj
+		}
+		return attrs[i].Name.Local < attrs[j].Name.Local
+	})
+
+	name := qualifiedName(e.prefix, e.local)
+	c.buf.WriteString("<" + name)
+	for _, prefix := range declarations {
+		if prefix == "" {
+			c.buf.WriteString(` xmlns="`)
+		} else {
+			c.buf.WriteString(` xmlns:` + prefix + `="`)
+		}
+		c.buf.WriteString(escapeAttr(scope[prefix]) + `"`)
+	}
+	for _, attr := range attrs {
+		c.buf.WriteString(" " + qualifiedName(attr.Name.Space, attr.Name.Local) + `="` + escapeAttr(attr.Value) + `"`)
+	}
+	c.buf.WriteString(">")
+
+	for _, child := range e.children {
+		switch n := child.(type) {
+		case string:
+			c.buf.WriteString(escapeText(n))
+		case *element:
+			if n != c.skip {
+				c.write(n, scope)
+			}
+		}
+	}
+
+	c.buf.WriteString("</" + name + ">")
+}
+
+func qualifiedName(prefix, local string) string {
+	if prefix == "" {
+		return local
+	}
+	return prefix + ":" + local
+}
+
+var textEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "\r", "&#xD;")
+var attrEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", `"`, "&quot;", "\t", "&#x9;", "\n", "&#xA;", "\r", "&#xD;")
+
+func escapeText(s string) string {
+	return textEscaper.Replace(s)
+}
+
+func escapeAttr(s string) string {
+	return attrEscaper.Replace(s)
+}
@@ -0,0 +1,134 @@
+package saml
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/xml"
+	"net/url"
+
+	"github.com/getfider/fider/app/pkg/errors"
+)
+
+const (
+	metadataNamespace = "urn:oasis:names:tc:SAML:2.0:metadata"
+	bindingRedirect   = "urn:oasis:names:tc:SAML:2.0:bindings:HTTP-Redirect"
+	bindingPOST       = "urn:oasis:names:tc:SAML:2.0:bindings:HTTP-POST"
+	nameIDUnspecified = "urn:oasis:names:tc:SAML:1.1:nameid-format:unspecified"
+)
+
+//IdentityProvider is what Fider needs to know about a SAML Identity Provider
+type IdentityProvider struct {
+	EntityID     string
+	SSOURL       string
+	Certificates []*x509.Certificate
+}
+
+//ParseMetadata reads the EntityDescriptor of an Identity Provider.
+//Only the HTTP-Redirect binding is used to send authentication requests
+func ParseMetadata(data []byte) (*IdentityProvider, error) {
+	root, err := parseXML(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if root.is(metadataNamespace, "EntitiesDescriptor") {
+		descriptors := root.elements(metadataNamespace, "EntityDescriptor")
+		if len(descriptors) != 1 {
+			return nil, errors.New("metadata must describe exactly one entity, but has %d", len(descriptors))
+		}
+		root = descriptors[0]
+	}
+
+	if !root.is(metadataNamespace, "EntityDescriptor") {
+		return nil, errors.New("metadata must have an EntityDescriptor")
+	}
+
+	idp := &IdentityProvider{
+		EntityID:     root.attr("entityID"),
+		Certificates: make([]*x509.Certificate, 0),
+	}
+	if idp.EntityID == "" {
+		return nil, errors.New("metadata is missing entityID")
+	}
+
+	descriptor := root.first(metadataNamespace, "IDPSSODescriptor")
+	if descriptor == nil {
+		return nil, errors.New("metadata is missing IDPSSODescriptor")
+	}
+
+	for _, sso := range descriptor.elements(metadataNamespace, "SingleSignOnService") {
+		if sso.attr("Binding") == bindingRedirect {
+			idp.SSOURL = sso.attr("Location")
+		}
+	}
+	if idp.SSOURL == "" {
+		return nil, errors.New("metadata is missing a SingleSignOnService with HTTP-Redirect binding")
+	}
+	if u, err := url.Parse(idp.SSOURL); err != nil || !u.IsAbs() {
+		return nil, errors.New("SingleSignOnService location '%s' is not a valid URL", idp.SSOURL)
+	}
+
+	for _, key := range descriptor.elements(metadataNamespace, "KeyDescriptor") {
+		if use := key.attr("use"); use != "" && use != "signing" {
+			continue
+		}
+		keyInfo := key.first(dsigNamespace, "KeyInfo")
+		if keyInfo == nil {
+			continue
+		}
+		for _, data := range keyInfo.elements(dsigNamespace, "X509Data") {
+			for _, el := range data.elements(dsigNamespace, "X509Certificate") {
+				der, err := decodeBase64(el.text())
+				if err != nil {
+					return nil, errors.Wrap(err, "failed to decode X509Certificate")
+				}
+				cert, err := x509.ParseCertificate(der)
+				if err != nil {
+					return nil, errors.Wrap(err, "failed to parse X509Certificate")
+				}
+				idp.Certificates = append(idp.Certificates, cert)
+			}
+		}
+	}
+	if len(idp.Certificates) == 0 {
+		return nil, errors.New("metadata is missing a signing certificate")
+	}
+
+	return idp, nil
+}
+
+type spMetadata struct {
+	XMLName    xml.Name `xml:"urn:oasis:names:tc:SAML:2.0:metadata EntityDescriptor"`
+	EntityID   string   `xml:"entityID,attr"`
+	Descriptor struct {
+		AuthnRequestsSigned        bool   `xml:"AuthnRequestsSigned,attr"`
+		WantAssertionsSigned       bool   `xml:"WantAssertionsSigned,attr"`
+		ProtocolSupportEnumeration string `xml:"protocolSupportEnumeration,attr"`
+		NameIDFormat               string `xml:"NameIDFormat"`
+		AssertionConsumerService   struct {
+			Binding  string `xml:"Binding,attr"`
+			Location string `xml:"Location,attr"`
+			Index    int    `xml:"index,attr"`
+		} `xml:"AssertionConsumerService"`
+	} `xml:"SPSSODescriptor"`
+}
+
+//Metadata returns the EntityDescriptor of Fider as a Service Provider, which is given to the Identity Provider
+func (sp *ServiceProvider) Metadata() ([]byte, error) {
+	metadata := spMetadata{EntityID: sp.EntityID}
+	metadata.Descriptor.AuthnRequestsSigned = false
+	metadata.Descriptor.WantAssertionsSigned = true
+	metadata.Descriptor.ProtocolSupportEnumeration = protocolNamespace
+	metadata.Descriptor.NameIDFormat = nameIDUnspecified
+	metadata.Descriptor.AssertionConsumerService.Binding = bindingPOST
+	metadata.Descriptor.AssertionConsumerService.Location = sp.ACSURL
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	encoder := xml.NewEncoder(&buf)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(metadata); err != nil {
+		return nil, errors.Wrap(err, "failed to encode Service Provider metadata")
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,315 @@
+package saml
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/base64"
+	"encoding/xml"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/getfider/fider/app/pkg/errors"
+)
+
+const (
+	protocolNamespace  = "urn:oasis:names:tc:SAML:2.0:protocol"
+	assertionNamespace = "urn:oasis:names:tc:SAML:2.0:assertion"
+	statusSuccess      = "urn:oasis:names:tc:SAML:2.0:status:Success"
+	confirmationBearer = "urn:oasis:names:tc:SAML:2.0:cm:bearer"
+)
+
+//MaxClockSkew is how much the clocks of Fider and the Identity Provider are allowed to differ
+var MaxClockSkew = 3 * time.Minute
+
+//ServiceProvider represents Fider as a SAML Service Provider of a tenant
+type ServiceProvider struct {
+	EntityID string
+	ACSURL   string
+	IdP      *IdentityProvider
+}
+
+//Assertion is the validated content of a SAML Assertion
+type Assertion struct {
+	ID           string
+	NameID       string
+	InResponseTo string
+	Attributes   map[string][]string
+	ExpiresAt    time.Time
+}
+
+//Attribute returns the first value of given attribute
+func (a *Assertion) Attribute(name string) string {
+	if values := a.Attributes[name]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+type authnRequest struct {
+	XMLName                     xml.Name `xml:"urn:oasis:names:tc:SAML:2.0:protocol AuthnRequest"`
+	ID                          string   `xml:"ID,attr"`
+	Version                     string   `xml:"Version,attr"`
+	IssueInstant                string   `xml:"IssueInstant,attr"`
+	Destination                 string   `xml:"Destination,attr"`
+	AssertionConsumerServiceURL string   `xml:"AssertionConsumerServiceURL,attr"`
+	ProtocolBinding             string   `xml:"ProtocolBinding,attr"`
+	Issuer                      struct {
+		XMLName xml.Name `xml:"urn:oasis:names:tc:SAML:2.0:assertion Issuer"`
+		Value   string   `xml:",chardata"`
+	}
+	NameIDPolicy struct {
+		AllowCreate bool   `xml:"AllowCreate,attr"`
+		Format      string `xml:"Format,attr"`
+	} `xml:"NameIDPolicy"`
+}
+
+//AuthnRequestURL returns the URL of the Identity Provider that starts the authentication.
+//requestID is expected back on the response and relayState is given back as is
+func (sp *ServiceProvider) AuthnRequestURL(requestID, relayState string) (string, error) {
+	request := authnRequest{
+		ID:                          requestID,
+		Version:                     "2.0",
+		IssueInstant:                time.Now().UTC().Format(time.RFC3339),
+		Destination:                 sp.IdP.SSOURL,
+		AssertionConsumerServiceURL: sp.ACSURL,
+		ProtocolBinding:             bindingPOST,
+	}
+	request.Issuer.Value = sp.EntityID
+	request.NameIDPolicy.AllowCreate = true
+	request.NameIDPolicy.Format = nameIDUnspecified
+
+	data, err := xml.Marshal(request)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to encode AuthnRequest")
+	}
+
+	var buf bytes.Buffer
+	writer, _ := flate.NewWriter(&buf, flate.DefaultCompression)
+	if _, err := writer.Write(data); err != nil {
+		return "", errors.Wrap(err, "failed to compress AuthnRequest")
+	}
+	if err := writer.Close(); err != nil {
+		return "", errors.Wrap(err, "failed to compress AuthnRequest")
+	}
+
+	ssoURL, err := url.Parse(sp.IdP.SSOURL)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to parse SSO URL")
+	}
+
+	query := ssoURL.Query()
+	query.Set("SAMLRequest", base64.StdEncoding.EncodeToString(buf.Bytes()))
+	if relayState != "" {
+		query.Set("RelayState", relayState)
+	}
+	ssoURL.RawQuery = query.Encode()
+	return ssoURL.String(), nil
+}
+
+type xmlAssertion struct {
+	ID           string `xml:"ID,attr"`
+	IssueInstant string `xml:"IssueInstant,attr"`
+	Issuer       string `xml:"urn:oasis:names:tc:SAML:2.0:assertion Issuer"`
+	Subject      struct {
+		NameID       string `xml:"urn:oasis:names:tc:SAML:2.0:assertion NameID"`
+		Confirmation []struct {
+			Method string `xml:"Method,attr"`
+			Data   struct {
+				InResponseTo string `xml:"InResponseTo,attr"`
+				NotOnOrAfter string `xml:"NotOnOrAfter,attr"`
+				Recipient    string `xml:"Recipient,attr"`
+			} `xml:"urn:oasis:names:tc:SAML:2.0:assertion SubjectConfirmationData"`
+		} `xml:"urn:oasis:names:tc:SAML:2.0:assertion SubjectConfirmation"`
+	} `xml:"urn:oasis:names:tc:SAML:2.0:assertion Subject"`
+	Conditions *struct {
+		NotBefore    string `xml:"NotBefore,attr"`
+		NotOnOrAfter string `xml:"NotOnOrAfter,attr"`
+		Audiences    []struct {
+			Audience []string `xml:"urn:oasis:names:tc:SAML:2.0:assertion Audience"`
+		} `xml:"urn:oasis:names:tc:SAML:2.0:assertion AudienceRestriction"`
+	} `xml:"urn:oasis:names:tc:SAML:2.0:assertion Conditions"`
+	Attributes []struct {
+		Name         string   `xml:"Name,attr"`
+		FriendlyName string   `xml:"FriendlyName,attr"`
+		Values       []string `xml:"urn:oasis:names:tc:SAML:2.0:assertion AttributeValue"`
+	} `xml:"urn:oasis:names:tc:SAML:2.0:assertion AttributeStatement>Attribute"`
+}
+
+//ParseResponse validates a base64 encoded SAML Response received on the Assertion Consumer Service.
+//Either the Response or the Assertion must be signed by the Identity Provider.
+//InResponseTo of the result is the ID of the AuthnRequest that started the authentication,
+//or empty for IdP-initiated sign in, and it's up to the caller to check it
+func (sp *ServiceProvider) ParseResponse(encoded string) (*Assertion, error) {
+	data, err := decodeBase64(encoded)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode SAML Response")
+	}
+
+	root, err := parseXML(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if !root.is(protocolNamespace, "Response") {
+		return nil, errors.New("SAML Response is invalid")
+	}
+
+	status := root.first(protocolNamespace, "Status")
+	if status == nil {
+		return nil, errors.New("SAML Response is missing Status")
+	}
+	if code := status.first(protocolNamespace, "StatusCode"); code == nil || code.attr("Value") != statusSuccess {
+		return nil, errors.New("SAML Response status is not success")
+	}
+
+	if len(root.elements(assertionNamespace, "EncryptedAssertion")) > 0 {
+		return nil, errors.New("encrypted assertions are not supported")
+	}
+
+	assertions := root.elements(assertionNamespace, "Assertion")
+	if len(assertions) != 1 {
+		return nil, errors.New("SAML Response must have exactly one Assertion, but has %d", len(assertions))
+	}
+	assertion := assertions[0]
+
+	responseSigned := signatureOf(root) != nil
+	assertionSigned := signatureOf(assertion) != nil
+	if !responseSigned && !assertionSigned {
+		return nil, errors.New("SAML Response is not signed")
+	}
+
+	if responseSigned {
+		if err := verifySignature(root, root, sp.IdP.Certificates); err != nil {
+			return nil, errors.Wrap(err, "failed to verify SAML Response signature")
+		}
+	}
+
+	if assertionSigned {
+		if err := verifySignature(root, assertion, sp.IdP.Certificates); err != nil {
+			return nil, errors.Wrap(err, "failed to verify SAML Assertion signature")
+		}
+	}
+
+	//Only read the assertion from what has been signed, everything else is ignored
+	canonical, err := canonicalize(assertion, c14nExclusive, nil, signatureOf(assertion))
+	if err != nil {
+		return nil, err
+	}
+
+	parsed := &xmlAssertion{}
+	if err := xml.Unmarshal(canonical, parsed); err != nil {
+		return nil, errors.Wrap(err, "failed to parse SAML Assertion")
+	}
+
+	return sp.validate(parsed)
+}
+
+func (sp *ServiceProvider) validate(a *xmlAssertion) (*Assertion, error) {
+	now := time.Now()
+
+	if a.ID == "" {
+		return nil, errors.New("SAML Assertion is missing ID")
+	}
+
+	if strings.TrimSpace(a.Issuer) != sp.IdP.EntityID {
+		return nil, errors.New("SAML Assertion issuer '%s' does not match '%s'", a.Issuer, sp.IdP.EntityID)
+	}
+
+	if a.Conditions == nil {
+		return nil, errors.New("SAML Assertion is missing Conditions")
+	}
+
+	expiresAt, err := parseTime(a.Conditions.NotOnOrAfter)
+	if err != nil {
+		return nil, err
+	}
+	if !expiresAt.IsZero() && !now.Before(expiresAt.Add(MaxClockSkew)) {
+		return nil, errors.New("SAML Assertion has expired")
+	}
+
+	notBefore, err := parseTime(a.Conditions.NotBefore)
+	if err != nil {
+		return nil, err
+	}
+	if !notBefore.IsZero() && now.Add(MaxClockSkew).Before(notBefore) {
+		return nil, errors.New("SAML Assertion is not yet valid")
+	}
+
+	if len(a.Conditions.Audiences) == 0 {
+		return nil, errors.New("SAML Assertion is missing AudienceRestriction")
+	}
+	for _, restriction := range a.Conditions.Audiences {
+		if !contains(restriction.Audience, sp.EntityID) {
+			return nil, errors.New("SAML Assertion is not intended for '%s'", sp.EntityID)
+		}
+	}
+
+	nameID := strings.TrimSpace(a.Subject.NameID)
+	if nameID == "" {
+		return nil, errors.New("SAML Assertion is missing NameID")
+	}
+
+	confirmed := false
+	inResponseTo := ""
+	for _, confirmation := range a.Subject.Confirmation {
+		if confirmation.Method != confirmationBearer {
+			continue
+		}
+		if confirmation.Data.Recipient != sp.ACSURL {
+			continue
+		}
+		notOnOrAfter, err := parseTime(confirmation.Data.NotOnOrAfter)
+		if err != nil || notOnOrAfter.IsZero() || !now.Before(notOnOrAfter.Add(MaxClockSkew)) {
+			continue
+		}
+		if expiresAt.IsZero() || notOnOrAfter.Before(expiresAt) {
+			expiresAt = notOnOrAfter
+		}
+		inResponseTo = confirmation.Data.InResponseTo
+		confirmed = true
+		break
+	}
+	if !confirmed {
+		return nil, errors.New("SAML Assertion has no valid bearer SubjectConfirmation for '%s'", sp.ACSURL)
+	}
+
+	result := &Assertion{
+		ID:           a.ID,
+		NameID:       nameID,
+		InResponseTo: inResponseTo,
+		Attributes:   make(map[string][]string),
+		ExpiresAt:    expiresAt,
+	}
+	for _, attr := range a.Attributes {
+		values := make([]string, 0, len(attr.Values))
+		for _, value := range attr.Values {
+			values = append(values, strings.TrimSpace(value))
+		}
+		result.Attributes[attr.Name] = append(result.Attributes[attr.Name], values...)
+		if attr.FriendlyName != "" && attr.FriendlyName != attr.Name {
+			result.Attributes[attr.FriendlyName] = append(result.Attributes[attr.FriendlyName], values...)
+		}
+	}
+	return result, nil
+}
+
+func parseTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return time.Time{}, errors.Wrap(err, "failed to parse SAML time '%s'", value)
+	}
+	return t, nil
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if strings.TrimSpace(item) == value {
+			return true
+		}
+	}
+	return false
+}
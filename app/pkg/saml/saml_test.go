@@ -0,0 +1,327 @@
+package saml_test
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"io/ioutil"
+	"math/big"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/saml"
+)
+
+const (
+	idpEntityID = "https://idp.example.org"
+	spEntityID  = "https://demo.test.fider.io/saml/metadata"
+	spACSURL    = "https://demo.test.fider.io/saml/acs"
+)
+
+type testIdP struct {
+	key  *rsa.PrivateKey
+	cert []byte
+}
+
+func newTestIdP(t *testing.T) *testIdP {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "idp.example.org"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	cert, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testIdP{key: key, cert: cert}
+}
+
+func (idp *testIdP) metadata() string {
+	return `<?xml version="1.0"?>
+<md:EntityDescriptor xmlns:md="urn:oasis:names:tc:SAML:2.0:metadata" entityID="` + idpEntityID + `">
+  <md:IDPSSODescriptor protocolSupportEnumeration="urn:oasis:names:tc:SAML:2.0:protocol">
+    <md:KeyDescriptor use="signing">
+      <ds:KeyInfo xmlns:ds="http://www.w3.org/2000/09/xmldsig#">
+        <ds:X509Data>
+          <ds:X509Certificate>` + base64.StdEncoding.EncodeToString(idp.cert) + `</ds:X509Certificate>
+        </ds:X509Data>
+      </ds:KeyInfo>
+    </md:KeyDescriptor>
+    <md:SingleSignOnService Binding="urn:oasis:names:tc:SAML:2.0:bindings:HTTP-POST" Location="https://idp.example.org/sso/post"/>
+    <md:SingleSignOnService Binding="urn:oasis:names:tc:SAML:2.0:bindings:HTTP-Redirect" Location="https://idp.example.org/sso/redirect"/>
+  </md:IDPSSODescriptor>
+</md:EntityDescriptor>`
+}
+
+func (idp *testIdP) serviceProvider(t *testing.T) *saml.ServiceProvider {
+	metadata, err := saml.ParseMetadata([]byte(idp.metadata()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &saml.ServiceProvider{
+		EntityID: spEntityID,
+		ACSURL:   spACSURL,
+		IdP:      metadata,
+	}
+}
+
+type assertionOpts struct {
+	ID           string
+	Issuer       string
+	NameID       string
+	InResponseTo string
+	Audience     string
+	Recipient    string
+	NotOnOrAfter time.Time
+}
+
+func defaultAssertion() assertionOpts {
+	return assertionOpts{
+		ID:           "_a1b2c3",
+		Issuer:       idpEntityID,
+		NameID:       "jon.snow",
+		InResponseTo: "id-123",
+		Audience:     spEntityID,
+		Recipient:    spACSURL,
+		NotOnOrAfter: time.Now().Add(5 * time.Minute),
+	}
+}
+
+//canonicalAssertion is written in Exclusive Canonical XML, so its digest can be computed directly
+func canonicalAssertion(opts assertionOpts) string {
+	notBefore := time.Now().Add(-time.Minute).UTC().Format(time.RFC3339)
+	notOnOrAfter := opts.NotOnOrAfter.UTC().Format(time.RFC3339)
+	return `<saml:Assertion xmlns:saml="urn:oasis:names:tc:SAML:2.0:assertion" ID="` + opts.ID + `" IssueInstant="` + notBefore + `" Version="2.0">` +
+		`<saml:Issuer>` + opts.Issuer + `</saml:Issuer>` +
+		`<saml:Subject><saml:NameID>` + opts.NameID + `</saml:NameID>` +
+		`<saml:SubjectConfirmation Method="urn:oasis:names:tc:SAML:2.0:cm:bearer">` +
+		`<saml:SubjectConfirmationData InResponseTo="` + opts.InResponseTo + `" NotOnOrAfter="` + notOnOrAfter + `" Recipient="` + opts.Recipient + `"></saml:SubjectConfirmationData>` +
+		`</saml:SubjectConfirmation></saml:Subject>` +
+		`<saml:Conditions NotBefore="` + notBefore + `" NotOnOrAfter="` + notOnOrAfter + `">` +
+		`<saml:AudienceRestriction><saml:Audience>` + opts.Audience + `</saml:Audience></saml:AudienceRestriction>` +
+		`</saml:Conditions>` +
+		`<saml:AttributeStatement>` +
+		`<saml:Attribute FriendlyName="mail" Name="urn:oid:0.9.2342.19200300.100.1.3"><saml:AttributeValue>jon@got.com</saml:AttributeValue></saml:Attribute>` +
+		`<saml:Attribute Name="displayName"><saml:AttributeValue>Jon Snow</saml:AttributeValue></saml:Attribute>` +
+		`<saml:Attribute Name="groups"><saml:AttributeValue>staff</saml:AttributeValue><saml:AttributeValue>fider-admins</saml:AttributeValue></saml:Attribute>` +
+		`</saml:AttributeStatement>` +
+		`</saml:Assertion>`
+}
+
+func (idp *testIdP) sign(canonical, id string) string {
+	digest := sha256.Sum256([]byte(canonical))
+	signedInfo := `<ds:SignedInfo xmlns:ds="http://www.w3.org/2000/09/xmldsig#">` +
+		`<ds:CanonicalizationMethod Algorithm="http://www.w3.org/2001/10/xml-exc-c14n#"></ds:CanonicalizationMethod>` +
+		`<ds:SignatureMethod Algorithm="http://www.w3.org/2001/04/xmldsig-more#rsa-sha256"></ds:SignatureMethod>` +
+		`<ds:Reference URI="#` + id + `"><ds:Transforms>` +
+		`<ds:Transform Algorithm="http://www.w3.org/2000/09/xmldsig#enveloped-signature"></ds:Transform>` +
+		`<ds:Transform Algorithm="http://www.w3.org/2001/10/xml-exc-c14n#"></ds:Transform>` +
+		`</ds:Transforms>` +
+		`<ds:DigestMethod Algorithm="http://www.w3.org/2001/04/xmlenc#sha256"></ds:DigestMethod>` +
+		`<ds:DigestValue>` + base64.StdEncoding.EncodeToString(digest[:]) + `</ds:DigestValue>` +
+		`</ds:Reference></ds:SignedInfo>`
+
+	hashed := sha256.Sum256([]byte(signedInfo))
+	signature, _ := rsa.SignPKCS1v15(rand.Reader, idp.key, crypto.SHA256, hashed[:])
+
+	return `<ds:Signature xmlns:ds="http://www.w3.org/2000/09/xmldsig#">` + signedInfo +
+		`<ds:SignatureValue>` + base64.StdEncoding.EncodeToString(signature) + `</ds:SignatureValue>` +
+		`</ds:Signature>`
+}
+
+func (idp *testIdP) signedAssertion(opts assertionOpts) string {
+	assertion := canonicalAssertion(opts)
+	signature := idp.sign(assertion, opts.ID)
+	return strings.Replace(assertion, "</saml:Issuer>", "</saml:Issuer>"+signature, 1)
+}
+
+func response(assertions ...string) string {
+	xml := `<samlp:Response xmlns:samlp="urn:oasis:names:tc:SAML:2.0:protocol" ID="_r1" InResponseTo="id-123" Version="2.0">` +
+		`<saml:Issuer xmlns:saml="urn:oasis:names:tc:SAML:2.0:assertion">` + idpEntityID + `</saml:Issuer>` +
+		`<samlp:Status><samlp:StatusCode Value="urn:oasis:names:tc:SAML:2.0:status:Success"/></samlp:Status>` +
+		strings.Join(assertions, "") +
+		`</samlp:Response>`
+	return base64.StdEncoding.EncodeToString([]byte(xml))
+}
+
+func TestParseMetadata(t *testing.T) {
+	RegisterT(t)
+
+	idp := newTestIdP(t)
+	metadata, err := saml.ParseMetadata([]byte(idp.metadata()))
+	Expect(err).IsNil()
+	Expect(metadata.EntityID).Equals(idpEntityID)
+	Expect(metadata.SSOURL).Equals("https://idp.example.org/sso/redirect")
+	Expect(metadata.Certificates).HasLen(1)
+}
+
+func TestParseMetadata_Invalid(t *testing.T) {
+	RegisterT(t)
+
+	idp := newTestIdP(t)
+	for _, metadata := range []string{
+		"",
+		"not xml",
+		`<md:EntityDescriptor xmlns:md="urn:oasis:names:tc:SAML:2.0:metadata" entityID="x"></md:EntityDescriptor>`,
+		strings.Replace(idp.metadata(), "HTTP-Redirect", "SOAP", 1),
+		strings.Replace(idp.metadata(), `use="signing"`, `use="encryption"`, 1),
+		`<!DOCTYPE foo [<!ENTITY xxe SYSTEM "file:///etc/passwd">]>` + idp.metadata()[21:],
+	} {
+		result, err := saml.ParseMetadata([]byte(metadata))
+		Expect(err).IsNotNil()
+		Expect(result).IsNil()
+	}
+}
+
+func TestServiceProvider_Metadata(t *testing.T) {
+	RegisterT(t)
+
+	sp := newTestIdP(t).serviceProvider(t)
+	metadata, err := sp.Metadata()
+	Expect(err).IsNil()
+	Expect(string(metadata)).ContainsSubstring(`entityID="` + spEntityID + `"`)
+	Expect(string(metadata)).ContainsSubstring(`WantAssertionsSigned="true"`)
+	Expect(string(metadata)).ContainsSubstring(`Binding="urn:oasis:names:tc:SAML:2.0:bindings:HTTP-POST" Location="` + spACSURL + `"`)
+}
+
+func TestServiceProvider_AuthnRequestURL(t *testing.T) {
+	RegisterT(t)
+
+	sp := newTestIdP(t).serviceProvider(t)
+	authURL, err := sp.AuthnRequestURL("id-123", "http://demo.test.fider.io|456")
+	Expect(err).IsNil()
+
+	u, _ := url.Parse(authURL)
+	Expect(u.Host).Equals("idp.example.org")
+	Expect(u.Path).Equals("/sso/redirect")
+	Expect(u.Query().Get("RelayState")).Equals("http://demo.test.fider.io|456")
+
+	compressed, err := base64.StdEncoding.DecodeString(u.Query().Get("SAMLRequest"))
+	Expect(err).IsNil()
+	request, err := ioutil.ReadAll(flate.NewReader(bytes.NewReader(compressed)))
+	Expect(err).IsNil()
+	Expect(string(request)).ContainsSubstring(`ID="id-123"`)
+	Expect(string(request)).ContainsSubstring(`AssertionConsumerServiceURL="` + spACSURL + `"`)
+	Expect(string(request)).ContainsSubstring(`>` + spEntityID + `</Issuer>`)
+}
+
+func TestParseResponse_SignedAssertion(t *testing.T) {
+	RegisterT(t)
+
+	idp := newTestIdP(t)
+	sp := idp.serviceProvider(t)
+
+	assertion, err := sp.ParseResponse(response(idp.signedAssertion(defaultAssertion())))
+	Expect(err).IsNil()
+	Expect(assertion.ID).Equals("_a1b2c3")
+	Expect(assertion.NameID).Equals("jon.snow")
+	Expect(assertion.InResponseTo).Equals("id-123")
+	Expect(assertion.Attribute("mail")).Equals("jon@got.com")
+	Expect(assertion.Attribute("urn:oid:0.9.2342.19200300.100.1.3")).Equals("jon@got.com")
+	Expect(assertion.Attribute("displayName")).Equals("Jon Snow")
+	Expect(assertion.Attributes["groups"]).Equals([]string{"staff", "fider-admins"})
+	Expect(assertion.Attribute("unknown")).Equals("")
+	Expect(assertion.ExpiresAt.After(time.Now())).IsTrue()
+}
+
+func TestParseResponse_NonCanonicalDocument(t *testing.T) {
+	RegisterT(t)
+
+	idp := newTestIdP(t)
+	sp := idp.serviceProvider(t)
+
+	//Namespace is declared on the Response, elements are self-closed and there's extra whitespace
+	signed := idp.signedAssertion(defaultAssertion())
+	signed = strings.Replace(signed, `<saml:Assertion xmlns:saml="urn:oasis:names:tc:SAML:2.0:assertion" `, "<saml:Assertion ", 1)
+	signed = strings.Replace(signed, `"></saml:SubjectConfirmationData>`, `" />`, 1)
+	signed = strings.Replace(signed, `<ds:SignatureValue>`, "<ds:SignatureValue>\n  ", 1)
+	xml := `<?xml version="1.0" encoding="UTF-8"?>
+<samlp:Response xmlns:samlp="urn:oasis:names:tc:SAML:2.0:protocol" xmlns:saml="urn:oasis:names:tc:SAML:2.0:assertion" Version="2.0" ID="_r1">
+  <!-- comments are ignored -->
+  <saml:Issuer>` + idpEntityID + `</saml:Issuer>
+  <samlp:Status>
+    <samlp:StatusCode Value="urn:oasis:names:tc:SAML:2.0:status:Success"/>
+  </samlp:Status>
+  ` + signed + `
+</samlp:Response>`
+
+	assertion, err := sp.ParseResponse(base64.StdEncoding.EncodeToString([]byte(xml)))
+	Expect(err).IsNil()
+	Expect(assertion.NameID).Equals("jon.snow")
+}
+
+func TestParseResponse_Invalid(t *testing.T) {
+	RegisterT(t)
+
+	idp := newTestIdP(t)
+	sp := idp.serviceProvider(t)
+
+	expired := defaultAssertion()
+	expired.NotOnOrAfter = time.Now().Add(-10 * time.Minute)
+
+	wrongAudience := defaultAssertion()
+	wrongAudience.Audience = "https://other.test.fider.io/saml/metadata"
+
+	wrongRecipient := defaultAssertion()
+	wrongRecipient.Recipient = "https://other.test.fider.io/saml/acs"
+
+	wrongIssuer := defaultAssertion()
+	wrongIssuer.Issuer = "https://evil.example.org"
+
+	tampered := strings.Replace(idp.signedAssertion(defaultAssertion()), "jon.snow", "arya.stark", 1)
+
+	wrapped := idp.signedAssertion(defaultAssertion())
+	evil := defaultAssertion()
+	evil.NameID = "arya.stark"
+
+	testCases := map[string]string{
+		"not base64":          "%%%",
+		"not xml":             base64.StdEncoding.EncodeToString([]byte("<samlp:Response")),
+		"unsigned":            response(canonicalAssertion(defaultAssertion())),
+		"expired":             response(idp.signedAssertion(expired)),
+		"wrong audience":      response(idp.signedAssertion(wrongAudience)),
+		"wrong recipient":     response(idp.signedAssertion(wrongRecipient)),
+		"wrong issuer":        response(idp.signedAssertion(wrongIssuer)),
+		"tampered":            response(tampered),
+		"other certificate":   response(newTestIdP(t).signedAssertion(defaultAssertion())),
+		"two assertions":      response(wrapped, canonicalAssertion(evil)),
+		"wrapped signature":   response(strings.Replace(canonicalAssertion(evil), "</saml:Issuer>", "</saml:Issuer>"+wrapped, 1)),
+		"encrypted assertion": response(`<saml:EncryptedAssertion xmlns:saml="urn:oasis:names:tc:SAML:2.0:assertion"></saml:EncryptedAssertion>`),
+	}
+
+	for name, encoded := range testCases {
+		assertion, err := sp.ParseResponse(encoded)
+		if err == nil {
+			t.Errorf("expected error for %s", name)
+		}
+		Expect(assertion).IsNil()
+	}
+}
+
+func TestParseResponse_IdPInitiated(t *testing.T) {
+	RegisterT(t)
+
+	idp := newTestIdP(t)
+	sp := idp.serviceProvider(t)
+
+	opts := defaultAssertion()
+	opts.InResponseTo = ""
+	assertion, err := sp.ParseResponse(response(idp.signedAssertion(opts)))
+	Expect(err).IsNil()
+	Expect(assertion.NameID).Equals("jon.snow")
+	Expect(assertion.InResponseTo).Equals("")
+}
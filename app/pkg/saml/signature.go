@@ -0,0 +1,206 @@
+package saml
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/base64"
+	"math/big"
+	"strings"
+
+	//Register hash functions used by XML signatures
+	_ "crypto/sha1"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+
+	"github.com/getfider/fider/app/pkg/errors"
+)
+
+const (
+	dsigNamespace       = "http://www.w3.org/2000/09/xmldsig#"
+	envelopedSignature  = "http://www.w3.org/2000/09/xmldsig#enveloped-signature"
+	exclusiveC14NPrefix = "http://www.w3.org/2001/10/xml-exc-c14n#"
+)
+
+var digestMethods = map[string]crypto.Hash{
+	"http://www.w3.org/2000/09/xmldsig#sha1":        crypto.SHA1,
+	"http://www.w3.org/2001/04/xmlenc#sha256":       crypto.SHA256,
+	"http://www.w3.org/2001/04/xmldsig-more#sha384": crypto.SHA384,
+	"http://www.w3.org/2001/04/xmlenc#sha512":       crypto.SHA512,
+}
+
+var signatureMethods = map[string]crypto.Hash{
+	"http://www.w3.org/2000/09/xmldsig#rsa-sha1":          crypto.SHA1,
+	"http://www.w3.org/2001/04/xmldsig-more#rsa-sha256":   crypto.SHA256,
+	"http://www.w3.org/2001/04/xmldsig-more#rsa-sha384":   crypto.SHA384,
+	"http://www.w3.org/2001/04/xmldsig-more#rsa-sha512":   crypto.SHA512,
+	"http://www.w3.org/2001/04/xmldsig-more#ecdsa-sha1":   crypto.SHA1,
+	"http://www.w3.org/2001/04/xmldsig-more#ecdsa-sha256": crypto.SHA256,
+	"http://www.w3.org/2001/04/xmldsig-more#ecdsa-sha384": crypto.SHA384,
+	"http://www.w3.org/2001/04/xmldsig-more#ecdsa-sha512": crypto.SHA512,
+}
+
+//signatureOf returns the enveloped signature of given element, if any
+func signatureOf(e *element) *element {
+	return e.first(dsigNamespace, "Signature")
+}
+
+//verifySignature checks that the enveloped signature of el is valid and was created by one of the certificates.
+//The signature must reference el itself and el must be the only element of the document with that ID,
+//otherwise an attacker could move signed content around (signature wrapping)
+func verifySignature(root, el *element, certificates []*x509.Certificate) error {
+	signature := signatureOf(el)
+	if signature == nil {
+		return errors.New("element '%s' is not signed", el.local)
+	}
+
+	signedInfo := signature.first(dsigNamespace, "SignedInfo")
+	if signedInfo == nil {
+		return errors.New("signature is missing SignedInfo")
+	}
+
+	references := signedInfo.elements(dsigNamespace, "Reference")
+	if len(references) != 1 {
+		return errors.New("signature must have exactly one reference, but has %d", len(references))
+	}
+	reference := references[0]
+
+	id := el.attr("ID")
+	if id == "" || reference.attr("URI") != "#"+id {
+		return errors.New("signature does not reference element '%s'", el.local)
+	}
+
+	count := 0
+	root.walk(func(e *element) {
+		if e.attr("ID") == id {
+			count++
+		}
+	})
+	if count != 1 {
+		return errors.New("ID '%s' must be unique within the document", id)
+	}
+
+	c14nMethod := c14nInclusive
+	var prefixes []string
+	hasEnvelopedTransform := false
+	if transforms := reference.first(dsigNamespace, "Transforms"); transforms != nil {
+		for _, transform := range transforms.elements(dsigNamespace, "Transform") {
+			algorithm := transform.attr("Algorithm")
+			switch {
+			case algorithm == envelopedSignature:
+				hasEnvelopedTransform = true
+			case strings.HasPrefix(algorithm, "http://www.w3.org/TR/2001/REC-xml-c14n-20010315") ||
+				strings.HasPrefix(algorithm, exclusiveC14NPrefix):
+				c14nMethod = algorithm
+				prefixes = inclusiveNamespaces(transform)
+			default:
+				return errors.New("transform '%s' is not supported", algorithm)
+			}
+		}
+	}
+	if !hasEnvelopedTransform {
+		return errors.New("signature must use the enveloped signature transform")
+	}
+
+	digestMethod := reference.first(dsigNamespace, "DigestMethod")
+	if digestMethod == nil {
+		return errors.New("signature is missing DigestMethod")
+	}
+	digestHash, ok := digestMethods[digestMethod.attr("Algorithm")]
+	if !ok {
+		return errors.New("digest method '%s' is not supported", digestMethod.attr("Algorithm"))
+	}
+
+	digestValue := reference.first(dsigNamespace, "DigestValue")
+	if digestValue == nil {
+		return errors.New("signature is missing DigestValue")
+	}
+	expectedDigest, err := decodeBase64(digestValue.text())
+	if err != nil {
+		return errors.Wrap(err, "failed to decode DigestValue")
+	}
+
+	canonical, err := canonicalize(el, c14nMethod, prefixes, signature)
+	if err != nil {
+		return err
+	}
+	h := digestHash.New()
+	h.Write(canonical)
+	if subtle.ConstantTimeCompare(h.Sum(nil), expectedDigest) != 1 {
+		return errors.New("digest of element '%s' does not match its signature", el.local)
+	}
+
+	canonicalizationMethod := signedInfo.first(dsigNamespace, "CanonicalizationMethod")
+	signatureMethod := signedInfo.first(dsigNamespace, "SignatureMethod")
+	if canonicalizationMethod == nil || signatureMethod == nil {
+		return errors.New("signature is missing CanonicalizationMethod or SignatureMethod")
+	}
+
+	signedInfoBytes, err := canonicalize(
+		signedInfo,
+		canonicalizationMethod.attr("Algorithm"),
+		inclusiveNamespaces(canonicalizationMethod),
+		nil,
+	)
+	if err != nil {
+		return err
+	}
+
+	algorithm := signatureMethod.attr("Algorithm")
+	signatureHash, ok := signatureMethods[algorithm]
+	if !ok {
+		return errors.New("signature method '%s' is not supported", algorithm)
+	}
+
+	signatureValue := signature.first(dsigNamespace, "SignatureValue")
+	if signatureValue == nil {
+		return errors.New("signature is missing SignatureValue")
+	}
+	sig, err := decodeBase64(signatureValue.text())
+	if err != nil {
+		return errors.Wrap(err, "failed to decode SignatureValue")
+	}
+
+	h = signatureHash.New()
+	h.Write(signedInfoBytes)
+	hashed := h.Sum(nil)
+
+	for _, cert := range certificates {
+		if checkSignature(cert, signatureHash, hashed, sig) {
+			return nil
+		}
+	}
+	return errors.New("signature was not created by any of the Identity Provider certificates")
+}
+
+func checkSignature(cert *x509.Certificate, hash crypto.Hash, hashed, sig []byte) bool {
+	switch key := cert.PublicKey.(type) {
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(key, hash, hashed, sig) == nil
+	case *ecdsa.PublicKey:
+		if len(sig)%2 != 0 {
+			return false
+		}
+		r := new(big.Int).SetBytes(sig[:len(sig)/2])
+		s := new(big.Int).SetBytes(sig[len(sig)/2:])
+		return ecdsa.Verify(key, hashed, r, s)
+	}
+	return false
+}
+
+func inclusiveNamespaces(e *element) []string {
+	if e == nil {
+		return nil
+	}
+	if el := e.first(exclusiveC14NPrefix, "InclusiveNamespaces"); el != nil {
+		return strings.Fields(el.attr("PrefixList"))
+	}
+	return nil
+}
+
+//decodeBase64 decodes values that might be split in multiple lines
+func decodeBase64(value string) ([]byte, error) {
+	return base64.StdEncoding.DecodeString(strings.Join(strings.Fields(value), ""))
+}
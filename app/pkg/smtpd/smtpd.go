@@ -0,0 +1,278 @@
+package smtpd
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/textproto"
+	"strings"
+	"sync"
+	"time"
+)
+
+//Handler is called once per received message with its envelope sender, recipients and raw content
+type Handler func(from string, to []string, data []byte) error
+
+//Server is a minimal SMTP (RFC 5321) or LMTP (RFC 2033) server used to receive emails
+//It doesn't relay messages, only recipients approved by AcceptRecipient are accepted
+type Server struct {
+	Addr            string
+	Domain          string
+	LMTP            bool
+	MaxMessageBytes int64
+	Timeout         time.Duration
+	AcceptRecipient func(address string) bool
+	Handler         Handler
+
+	listener net.Listener
+	closed   bool
+	mu       sync.Mutex
+}
+
+//ListenAndServe listens on the TCP address of the server and then handles incoming connections
+func (s *Server) ListenAndServe() error {
+	l, err := net.Listen("tcp", s.Addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(l)
+}
+
+//Serve accepts incoming connections on given listener
+func (s *Server) Serve(l net.Listener) error {
+	s.mu.Lock()
+	s.listener = l
+	s.mu.Unlock()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if s.isClosed() {
+				return nil
+			}
+			return err
+		}
+		go s.handle(conn)
+	}
+}
+
+//Close stops listening for new connections
+func (s *Server) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	if s.listener != nil {
+		return s.listener.Close()
+	}
+	return nil
+}
+
+func (s *Server) isClosed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closed
+}
+
+func (s *Server) protocol() string {
+	if s.LMTP {
+		return "LMTP"
+	}
+	return "ESMTP"
+}
+
+type session struct {
+	server *Server
+	conn   net.Conn
+	text   *textproto.Conn
+	hello  bool
+	mail   bool
+	from   string
+	to     []string
+}
+
+func (s *Server) handle(conn net.Conn) {
+	defer conn.Close()
+
+	sess := &session{
+		server: s,
+		conn:   conn,
+		text:   textproto.NewConn(conn),
+	}
+
+	sess.reply(220, "%s %s Fider ready", s.Domain, s.protocol())
+	for {
+		sess.extendDeadline()
+		line, err := sess.text.ReadLine()
+		if err != nil {
+			return
+		}
+
+		verb, arg := line, ""
+		if i := strings.IndexByte(line, ' '); i >= 0 {
+			verb, arg = line[:i], strings.TrimSpace(line[i+1:])
+		}
+
+		if !sess.command(strings.ToUpper(verb), arg) {
+			return
+		}
+	}
+}
+
+func (sess *session) extendDeadline() {
+	timeout := sess.server.Timeout
+	if timeout == 0 {
+		timeout = 5 * time.Minute
+	}
+	_ = sess.conn.SetDeadline(time.Now().Add(timeout))
+}
+
+func (sess *session) reply(code int, format string, args ...interface{}) {
+	_ = sess.text.PrintfLine("%d %s", code, fmt.Sprintf(format, args...))
+}
+
+func (sess *session) reset() {
+	sess.mail = false
+	sess.from = ""
+	sess.to = nil
+}
+
+//command handles a single command and returns false when the connection must be closed
+func (sess *session) command(verb, arg string) bool {
+	switch verb {
+	case "HELO", "EHLO", "LHLO":
+		if (verb == "LHLO") != sess.server.LMTP {
+			sess.reply(500, "5.5.1 Unrecognized command")
+			return true
+		}
+		sess.hello = true
+		sess.reset()
+		if verb == "HELO" {
+			sess.reply(250, "%s", sess.server.Domain)
+			return true
+		}
+		_ = sess.text.PrintfLine("250-%s", sess.server.Domain)
+		_ = sess.text.PrintfLine("250-8BITMIME")
+		if sess.server.MaxMessageBytes > 0 {
+			_ = sess.text.PrintfLine("250-SIZE %d", sess.server.MaxMessageBytes)
+		}
+		sess.reply(250, "ENHANCEDSTATUSCODES")
+	case "MAIL":
+		if !sess.hello {
+			sess.reply(503, "5.5.1 Send %s first", sess.helloVerb())
+			return true
+		}
+		address, ok := parsePath(arg, "FROM:")
+		if !ok {
+			sess.reply(501, "5.5.4 Syntax: MAIL FROM:<address>")
+			return true
+		}
+		sess.reset()
+		sess.mail = true
+		sess.from = address
+		sess.reply(250, "2.1.0 Ok")
+	case "RCPT":
+		if !sess.mail {
+			sess.reply(503, "5.5.1 Send MAIL first")
+			return true
+		}
+		address, ok := parsePath(arg, "TO:")
+		if !ok || address == "" {
+			sess.reply(501, "5.5.4 Syntax: RCPT TO:<address>")
+			return true
+		}
+		if sess.server.AcceptRecipient != nil && !sess.server.AcceptRecipient(address) {
+			sess.reply(550, "5.1.1 Mailbox unavailable")
+			return true
+		}
+		sess.to = append(sess.to, address)
+		sess.reply(250, "2.1.5 Ok")
+	case "DATA":
+		if len(sess.to) == 0 {
+			sess.reply(503, "5.5.1 Send RCPT first")
+			return true
+		}
+		sess.reply(354, "End data with <CR><LF>.<CR><LF>")
+		sess.data()
+	case "RSET":
+		sess.reset()
+		sess.reply(250, "2.0.0 Ok")
+	case "NOOP":
+		sess.reply(250, "2.0.0 Ok")
+	case "VRFY":
+		sess.reply(252, "2.5.0 Cannot verify user")
+	case "QUIT":
+		sess.reply(221, "2.0.0 Bye")
+		return false
+	default:
+		sess.reply(502, "5.5.2 Command not implemented")
+	}
+	return true
+}
+
+func (sess *session) helloVerb() string {
+	if sess.server.LMTP {
+		return "LHLO"
+	}
+	return "EHLO/HELO"
+}
+
+func (sess *session) data() {
+	dot := sess.text.DotReader()
+	reader := dot
+	limit := sess.server.MaxMessageBytes
+	if limit > 0 {
+		reader = io.LimitReader(dot, limit+1)
+	}
+
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		sess.replyAll(451, "4.3.0 Failed to read message")
+		sess.reset()
+		return
+	}
+
+	if limit > 0 && int64(len(data)) > limit {
+		//Consume the rest of the message, so that the connection can still be used
+		_, _ = io.Copy(ioutil.Discard, dot)
+		sess.replyAll(552, "5.3.4 Message too big")
+		sess.reset()
+		return
+	}
+
+	if err := sess.server.Handler(sess.from, sess.to, data); err != nil {
+		sess.replyAll(451, "4.3.0 Failed to process message")
+	} else {
+		sess.replyAll(250, "2.0.0 Ok")
+	}
+	sess.reset()
+}
+
+//replyAll sends one reply per recipient on LMTP and a single reply on SMTP
+func (sess *session) replyAll(code int, message string) {
+	count := 1
+	if sess.server.LMTP {
+		count = len(sess.to)
+	}
+	for i := 0; i < count; i++ {
+		sess.reply(code, "%s", message)
+	}
+}
+
+//parsePath extracts the address of commands like "FROM:<user@example.com> SIZE=100"
+func parsePath(arg, prefix string) (string, bool) {
+	if len(arg) < len(prefix) || !strings.EqualFold(arg[:len(prefix)], prefix) {
+		return "", false
+	}
+
+	arg = strings.TrimSpace(arg[len(prefix):])
+	if !strings.HasPrefix(arg, "<") {
+		return "", false
+	}
+
+	end := strings.IndexByte(arg, '>')
+	if end < 0 {
+		return "", false
+	}
+	return arg[1:end], true
+}
@@ -0,0 +1,114 @@
+package smtpd_test
+
+import (
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"testing"
+
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/smtpd"
+)
+
+type received struct {
+	from string
+	to   []string
+	data string
+}
+
+func startServer(lmtp bool) (*smtpd.Server, string, chan received) {
+	messages := make(chan received, 10)
+	server := &smtpd.Server{
+		Domain:          "inbound.test.fider.io",
+		LMTP:            lmtp,
+		MaxMessageBytes: 1024,
+		AcceptRecipient: func(address string) bool {
+			return strings.HasPrefix(address, "reply+")
+		},
+		Handler: func(from string, to []string, data []byte) error {
+			messages <- received{from, to, string(data)}
+			return nil
+		},
+	}
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		panic(err)
+	}
+	go server.Serve(l)
+	return server, l.Addr().String(), messages
+}
+
+func TestServer_SMTP(t *testing.T) {
+	RegisterT(t)
+
+	server, addr, messages := startServer(false)
+	defer server.Close()
+
+	err := smtp.SendMail(addr, nil, "jon.snow@got.com", []string{"reply+1.2.3.abc@inbound.test.fider.io"}, []byte("Subject: Hello\r\n\r\nThis is my reply.\r\n.Dot stuffed line\r\n"))
+	Expect(err).IsNil()
+
+	message := <-messages
+	Expect(message.from).Equals("jon.snow@got.com")
+	Expect(message.to).Equals([]string{"reply+1.2.3.abc@inbound.test.fider.io"})
+	Expect(message.data).Equals("Subject: Hello\n\nThis is my reply.\n.Dot stuffed line\n")
+}
+
+func TestServer_SMTP_RejectRecipient(t *testing.T) {
+	RegisterT(t)
+
+	server, addr, _ := startServer(false)
+	defer server.Close()
+
+	err := smtp.SendMail(addr, nil, "jon.snow@got.com", []string{"arya.stark@got.com"}, []byte("Subject: Hello\r\n\r\nHi\r\n"))
+	Expect(err).IsNotNil()
+	Expect(err.Error()).ContainsSubstring("550")
+}
+
+func TestServer_SMTP_MessageTooBig(t *testing.T) {
+	RegisterT(t)
+
+	server, addr, _ := startServer(false)
+	defer server.Close()
+
+	err := smtp.SendMail(addr, nil, "jon.snow@got.com", []string{"reply+1.2.3.abc@inbound.test.fider.io"}, []byte("Subject: Hello\r\n\r\n"+strings.Repeat("a", 2048)+"\r\n"))
+	Expect(err).IsNotNil()
+	Expect(err.Error()).ContainsSubstring("552")
+}
+
+func TestServer_LMTP(t *testing.T) {
+	RegisterT(t)
+
+	server, addr, messages := startServer(true)
+	defer server.Close()
+
+	conn, err := textproto.Dial("tcp", addr)
+	Expect(err).IsNil()
+	defer conn.Close()
+
+	expect := func(code int, command string) {
+		if command != "" {
+			Expect(conn.PrintfLine("%s", command)).IsNil()
+		}
+		_, _, err := conn.ReadResponse(code)
+		Expect(err).IsNil()
+	}
+
+	expect(220, "")
+	expect(500, "EHLO localhost")
+	expect(250, "LHLO localhost")
+	expect(250, "MAIL FROM:<jon.snow@got.com>")
+	expect(250, "RCPT TO:<reply+1.2.3.abc@inbound.test.fider.io>")
+	expect(250, "RCPT TO:<reply+1.2.4.def@inbound.test.fider.io>")
+	expect(550, "RCPT TO:<arya.stark@got.com>")
+	expect(354, "DATA")
+	Expect(conn.PrintfLine("Subject: Hello\r\n\r\nThis is my reply.\r\n.")).IsNil()
+	expect(250, "")
+	expect(250, "")
+	expect(221, "QUIT")
+
+	message := <-messages
+	Expect(message.to).Equals([]string{"reply+1.2.3.abc@inbound.test.fider.io", "reply+1.2.4.def@inbound.test.fider.io"})
+	Expect(message.data).Equals("Subject: Hello\n\nThis is my reply.\n")
+}
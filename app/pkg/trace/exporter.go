@@ -0,0 +1,394 @@
+package trace
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/getfider/fider/app/pkg/env"
+	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/getfider/fider/app/pkg/metrics"
+)
+
+//Exporter sends finished spans to a tracing backend
+type Exporter interface {
+	Export(spans []*Span) error
+}
+
+var (
+	maxQueueSize = 2048
+	maxBatchSize = 512
+	batchTimeout = 5 * time.Second
+)
+
+var droppedSpans = metrics.NewCounter("fider_trace_spans_dropped_total", "Number of finished spans that could not be exported, by reason.", "reason")
+
+//processor exports finished spans in batches on background
+type processor struct {
+	exporter Exporter
+	spans    chan *Span
+	done     chan struct{}
+}
+
+var (
+	processorLock sync.RWMutex
+	current       *processor
+)
+
+//Init starts exporting spans with the exporter set on TRACING_EXPORTER, tracing is disabled when it's empty
+func Init() error {
+	var exporter Exporter
+	switch strings.ToLower(env.Config.Tracing.Exporter) {
+	case "otlp":
+		exporter = NewOTLPExporter(env.Config.Tracing.OTLP.Endpoint, parseHeaders(env.Config.Tracing.OTLP.Headers))
+	case "stdout":
+		exporter = NewWriterExporter(os.Stdout)
+	case "file":
+		file, err := os.OpenFile(env.Config.Tracing.FilePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return errors.Wrap(err, "failed to open trace file '%s'", env.Config.Tracing.FilePath)
+		}
+		exporter = NewWriterExporter(file)
+	}
+	Use(exporter)
+	return nil
+}
+
+//Use exports finished spans with given exporter, replacing the previous one. Tracing is disabled when it's nil
+func Use(exporter Exporter) {
+	_ = Shutdown(context.Background())
+	if exporter == nil {
+		return
+	}
+
+	p := &processor{
+		exporter: exporter,
+		spans:    make(chan *Span, maxQueueSize),
+		done:     make(chan struct{}),
+	}
+	go p.run()
+
+	processorLock.Lock()
+	current = p
+	processorLock.Unlock()
+}
+
+//Enabled returns true if spans are being exported
+func Enabled() bool {
+	processorLock.RLock()
+	defer processorLock.RUnlock()
+	return current != nil
+}
+
+//Shutdown exports all pending spans and disables tracing
+func Shutdown(ctx context.Context) error {
+	processorLock.Lock()
+	p := current
+	current = nil
+	if p != nil {
+		close(p.spans)
+	}
+	processorLock.Unlock()
+
+	if p == nil {
+		return nil
+	}
+
+	select {
+	case <-p.done:
+		if closer, ok := p.exporter.(io.Closer); ok {
+			return closer.Close()
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+//export queues a finished span, dropping it if the queue is full so that tracing never blocks requests
+func export(span *Span) {
+	processorLock.RLock()
+	defer processorLock.RUnlock()
+	if current == nil {
+		return
+	}
+
+	select {
+	case current.spans <- span:
+	default:
+		droppedSpans.Inc("queue_full")
+	}
+}
+
+func (p *processor) run() {
+	defer close(p.done)
+
+	ticker := time.NewTicker(batchTimeout)
+	defer ticker.Stop()
+
+	batch := make([]*Span, 0, maxBatchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := p.exporter.Export(batch); err != nil {
+			droppedSpans.Add(float64(len(batch)), "export_failed")
+		}
+		batch = make([]*Span, 0, maxBatchSize)
+	}
+
+	for {
+		select {
+		case span, ok := <-p.spans:
+			if !ok {
+				flush()
+				return
+			}
+			batch = append(batch, span)
+			if len(batch) >= maxBatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+func sampleRatio() float64 {
+	return env.Config.Tracing.SampleRatio
+}
+
+//parseHeaders parses a list of headers formatted as key1=value1,key2=value2
+func parseHeaders(value string) map[string]string {
+	headers := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) == 2 && strings.TrimSpace(parts[0]) != "" {
+			headers[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+		}
+	}
+	return headers
+}
+
+//OTLPExporter sends spans to an OpenTelemetry collector using OTLP over HTTP with JSON encoding
+type OTLPExporter struct {
+	endpoint string
+	headers  map[string]string
+	client   *http.Client
+}
+
+//NewOTLPExporter creates an exporter that sends spans to given endpoint, such as http://localhost:4318/v1/traces
+func NewOTLPExporter(endpoint string, headers map[string]string) *OTLPExporter {
+	return &OTLPExporter{
+		endpoint: endpoint,
+		headers:  headers,
+		client:   &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+//Export sends given spans to the collector
+func (e *OTLPExporter) Export(spans []*Span) error {
+	body, err := json.Marshal(encode(spans))
+	if err != nil {
+		return errors.Wrap(err, "failed to encode spans")
+	}
+
+	req, err := http.NewRequest("POST", e.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "failed to create OTLP request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range e.headers {
+		req.Header.Set(key, value)
+	}
+
+	res, err := e.client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "failed to send spans to '%s'", e.endpoint)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		return errors.New("failed to send spans to '%s': status code %d", e.endpoint, res.StatusCode)
+	}
+	return nil
+}
+
+//WriterExporter writes each batch of spans as a line of OTLP JSON, which is the format read by the collector's otlpjsonfile receiver
+type WriterExporter struct {
+	lock   sync.Mutex
+	writer io.Writer
+}
+
+//NewWriterExporter creates an exporter that writes spans to given writer, such as os.Stdout or a file
+func NewWriterExporter(w io.Writer) *WriterExporter {
+	return &WriterExporter{writer: w}
+}
+
+//Export writes given spans to the writer
+func (e *WriterExporter) Export(spans []*Span) error {
+	line, err := json.Marshal(encode(spans))
+	if err != nil {
+		return errors.Wrap(err, "failed to encode spans")
+	}
+
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	_, err = e.writer.Write(append(line, '\n'))
+	return err
+}
+
+//Close closes the writer, unless it's one of the standard outputs
+func (e *WriterExporter) Close() error {
+	if closer, ok := e.writer.(io.Closer); ok && e.writer != os.Stdout && e.writer != os.Stderr {
+		return closer.Close()
+	}
+	return nil
+}
+
+type otlpTraces struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpAttribute `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           string          `json:"traceId"`
+	SpanID            string          `json:"spanId"`
+	ParentSpanID      string          `json:"parentSpanId,omitempty"`
+	Name              string          `json:"name"`
+	Kind              Kind            `json:"kind"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Attributes        []otlpAttribute `json:"attributes,omitempty"`
+	Links             []otlpLink      `json:"links,omitempty"`
+	Status            otlpStatus      `json:"status"`
+}
+
+type otlpLink struct {
+	TraceID string `json:"traceId"`
+	SpanID  string `json:"spanId"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpAttribute struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+}
+
+//otlpStatusError is the OTLP status code of failed spans
+const otlpStatusError = 2
+
+//encode converts given spans to the OTLP JSON format
+func encode(spans []*Span) otlpTraces {
+	encoded := make([]otlpSpan, len(spans))
+	for i, span := range spans {
+		span.lock.Lock()
+		s := otlpSpan{
+			TraceID:           span.Context.TraceID.String(),
+			SpanID:            span.Context.SpanID.String(),
+			Name:              span.Name,
+			Kind:              span.Kind,
+			StartTimeUnixNano: strconv.FormatInt(span.StartTime.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(span.EndTime.UnixNano(), 10),
+			Attributes:        encodeAttributes(span.Attributes),
+		}
+		if span.ParentID.IsValid() {
+			s.ParentSpanID = span.ParentID.String()
+		}
+		for _, link := range span.Links {
+			s.Links = append(s.Links, otlpLink{TraceID: link.TraceID.String(), SpanID: link.SpanID.String()})
+		}
+		if span.Failed {
+			s.Status = otlpStatus{Code: otlpStatusError, Message: span.Error}
+		}
+		span.lock.Unlock()
+		encoded[i] = s
+	}
+
+	return otlpTraces{
+		ResourceSpans: []otlpResourceSpans{
+			{
+				Resource: otlpResource{
+					Attributes: encodeAttributes(map[string]interface{}{
+						"service.name": env.Config.Tracing.ServiceName,
+					}),
+				},
+				ScopeSpans: []otlpScopeSpans{
+					{
+						Scope: otlpScope{Name: "github.com/getfider/fider"},
+						Spans: encoded,
+					},
+				},
+			},
+		},
+	}
+}
+
+func encodeAttributes(attributes map[string]interface{}) []otlpAttribute {
+	keys := make([]string, 0, len(attributes))
+	for key := range attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	encoded := make([]otlpAttribute, 0, len(attributes))
+	for _, key := range keys {
+		var v otlpValue
+		switch typed := attributes[key].(type) {
+		case string:
+			v.StringValue = &typed
+		case bool:
+			v.BoolValue = &typed
+		case int:
+			s := strconv.Itoa(typed)
+			v.IntValue = &s
+		case int64:
+			s := strconv.FormatInt(typed, 10)
+			v.IntValue = &s
+		case float64:
+			v.DoubleValue = &typed
+		default:
+			s := fmt.Sprint(typed)
+			v.StringValue = &s
+		}
+		encoded = append(encoded, otlpAttribute{Key: key, Value: v})
+	}
+	return encoded
+}
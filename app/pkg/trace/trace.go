@@ -0,0 +1,254 @@
+package trace
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math"
+	mathrand "math/rand"
+	"strings"
+	"sync"
+	"time"
+)
+
+//HeaderName is the W3C Trace Context header used to propagate spans between processes
+const HeaderName = "traceparent"
+
+//Kind describes the relationship between a span and its caller, using same values as OTLP
+type Kind int
+
+const (
+	//KindInternal is an operation within the application
+	KindInternal Kind = 1
+	//KindServer is the handling of a request from a remote client
+	KindServer Kind = 2
+	//KindClient is a request to a remote service
+	KindClient Kind = 3
+	//KindConsumer is the processing of a message that was enqueued earlier
+	KindConsumer Kind = 5
+)
+
+//TraceID identifies all spans of a single operation
+type TraceID [16]byte
+
+//IsValid returns true if trace ID is not all zeros
+func (t TraceID) IsValid() bool {
+	return t != TraceID{}
+}
+
+func (t TraceID) String() string {
+	return hex.EncodeToString(t[:])
+}
+
+//SpanID identifies a single span within a trace
+type SpanID [8]byte
+
+//IsValid returns true if span ID is not all zeros
+func (s SpanID) IsValid() bool {
+	return s != SpanID{}
+}
+
+func (s SpanID) String() string {
+	return hex.EncodeToString(s[:])
+}
+
+//SpanContext is the part of a span that is propagated to its children, tasks and remote services
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Sampled bool
+}
+
+//IsValid returns true if both trace and span IDs are set
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID.IsValid() && sc.SpanID.IsValid()
+}
+
+//TraceParent formats span context as a W3C traceparent header value
+func (sc SpanContext) TraceParent() string {
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+	return fmt.Sprintf("00-%s-%s-%s", sc.TraceID, sc.SpanID, flags)
+}
+
+//ParseTraceParent parses a W3C traceparent header value, returning false if it's not valid
+func ParseTraceParent(value string) (SpanContext, bool) {
+	value = strings.TrimSpace(value)
+	if len(value) < 55 || (len(value) > 55 && value[55] != '-') {
+		return SpanContext{}, false
+	}
+
+	parts := strings.Split(value[:55], "-")
+	if len(parts) != 4 || len(parts[0]) != 2 || parts[0] == "ff" || (parts[0] == "00" && len(value) != 55) {
+		return SpanContext{}, false
+	}
+
+	var sc SpanContext
+	version, err1 := hex.DecodeString(parts[0])
+	_, err2 := hex.Decode(sc.TraceID[:], []byte(parts[1]))
+	_, err3 := hex.Decode(sc.SpanID[:], []byte(parts[2]))
+	flags, err4 := hex.DecodeString(parts[3])
+	if err1 != nil || err2 != nil || err3 != nil || err4 != nil || len(version) != 1 || len(flags) != 1 {
+		return SpanContext{}, false
+	}
+	if strings.ToLower(value[:55]) != value[:55] || !sc.IsValid() {
+		return SpanContext{}, false
+	}
+
+	sc.Sampled = flags[0]&1 == 1
+	return sc, true
+}
+
+type spanContextKey struct{}
+
+//WithParent returns a copy of ctx in which new spans are children of given span context
+func WithParent(ctx context.Context, parent SpanContext) context.Context {
+	return context.WithValue(ctx, spanContextKey{}, parent)
+}
+
+//FromContext returns the context of the current span in ctx, which is invalid if there's none
+func FromContext(ctx context.Context) SpanContext {
+	sc, _ := ctx.Value(spanContextKey{}).(SpanContext)
+	return sc
+}
+
+//Span is a single timed operation within a trace
+type Span struct {
+	Name       string
+	Kind       Kind
+	Context    SpanContext
+	ParentID   SpanID
+	Links      []SpanContext
+	StartTime  time.Time
+	EndTime    time.Time
+	Attributes map[string]interface{}
+	Error      string
+	Failed     bool
+	lock       sync.Mutex
+	ended      bool
+}
+
+//Start creates a span that is a child of the current span in ctx, or the root of a new trace if there's none.
+//Returned context carries the new span. Span is nil when tracing is disabled, which is safe to use
+func Start(ctx context.Context, name string, kind Kind, links ...SpanContext) (context.Context, *Span) {
+	if !Enabled() {
+		return ctx, nil
+	}
+
+	span := &Span{
+		Name:       name,
+		Kind:       kind,
+		StartTime:  time.Now(),
+		Attributes: make(map[string]interface{}),
+	}
+
+	for _, link := range links {
+		if link.IsValid() {
+			span.Links = append(span.Links, link)
+		}
+	}
+
+	parent := FromContext(ctx)
+	if parent.IsValid() {
+		span.Context.TraceID = parent.TraceID
+		span.Context.Sampled = parent.Sampled
+		span.ParentID = parent.SpanID
+	} else {
+		span.Context.TraceID = newTraceID()
+		span.Context.Sampled = shouldSample(span.Context.TraceID)
+	}
+	span.Context.SpanID = newSpanID()
+
+	return WithParent(ctx, span.Context), span
+}
+
+//SpanContext returns the propagated part of the span
+func (s *Span) SpanContext() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+	return s.Context
+}
+
+//SetAttribute adds a key/value pair that describes the span
+func (s *Span) SetAttribute(key string, value interface{}) {
+	if s == nil {
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.Attributes[key] = value
+}
+
+//End finishes the span, marking it as failed if err is not nil. Only the first call has any effect
+func (s *Span) End(err error) {
+	if s == nil {
+		return
+	}
+
+	s.lock.Lock()
+	if s.ended {
+		s.lock.Unlock()
+		return
+	}
+	s.ended = true
+	s.EndTime = time.Now()
+	if err != nil {
+		s.Failed = true
+		s.Error = err.Error()
+	}
+	s.lock.Unlock()
+
+	if s.Context.Sampled {
+		export(s)
+	}
+}
+
+var (
+	idLock sync.Mutex
+	ids    *mathrand.Rand
+)
+
+func init() {
+	var seed int64
+	if err := binary.Read(rand.Reader, binary.LittleEndian, &seed); err != nil {
+		seed = time.Now().UnixNano()
+	}
+	ids = mathrand.New(mathrand.NewSource(seed))
+}
+
+func newTraceID() TraceID {
+	idLock.Lock()
+	defer idLock.Unlock()
+	var id TraceID
+	for !id.IsValid() {
+		_, _ = ids.Read(id[:])
+	}
+	return id
+}
+
+func newSpanID() SpanID {
+	idLock.Lock()
+	defer idLock.Unlock()
+	var id SpanID
+	for !id.IsValid() {
+		_, _ = ids.Read(id[:])
+	}
+	return id
+}
+
+//shouldSample decides if a new trace is exported based on its ID, so that the decision is consistent for the same trace
+func shouldSample(id TraceID) bool {
+	ratio := sampleRatio()
+	if ratio >= 1 {
+		return true
+	}
+	if ratio <= 0 {
+		return false
+	}
+	return binary.BigEndian.Uint64(id[8:])>>1 < uint64(ratio*math.MaxInt64)
+}
@@ -0,0 +1,213 @@
+package trace_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/env"
+	"github.com/getfider/fider/app/pkg/trace"
+)
+
+type recorder struct {
+	lock  sync.Mutex
+	spans []*trace.Span
+}
+
+func (r *recorder) Export(spans []*trace.Span) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.spans = append(r.spans, spans...)
+	return nil
+}
+
+func TestParseTraceParent(t *testing.T) {
+	RegisterT(t)
+
+	sc, ok := trace.ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	Expect(ok).IsTrue()
+	Expect(sc.TraceID.String()).Equals("4bf92f3577b34da6a3ce929d0e0e4736")
+	Expect(sc.SpanID.String()).Equals("00f067aa0ba902b7")
+	Expect(sc.Sampled).IsTrue()
+	Expect(sc.TraceParent()).Equals("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	sc, ok = trace.ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+	Expect(ok).IsTrue()
+	Expect(sc.Sampled).IsFalse()
+
+	sc, ok = trace.ParseTraceParent("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-future")
+	Expect(ok).IsTrue()
+	Expect(sc.SpanID.String()).Equals("00f067aa0ba902b7")
+
+	for _, value := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+		"00-4bf92f3577b34da6a3ce929d0e0e473x-00f067aa0ba902b7-01",
+	} {
+		_, ok := trace.ParseTraceParent(value)
+		Expect(ok).IsFalse()
+	}
+}
+
+func TestStart_Disabled(t *testing.T) {
+	RegisterT(t)
+	trace.Use(nil)
+
+	ctx, span := trace.Start(context.Background(), "noop", trace.KindInternal)
+	Expect(span).IsNil()
+	Expect(trace.FromContext(ctx).IsValid()).IsFalse()
+
+	span.SetAttribute("key", "value")
+	span.End(errors.New("ignored"))
+}
+
+func TestStart_ChildSpans(t *testing.T) {
+	RegisterT(t)
+	exporter := &recorder{}
+	trace.Use(exporter)
+
+	parent, _ := trace.ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	ctx := trace.WithParent(context.Background(), parent)
+
+	ctx, server := trace.Start(ctx, "GET /", trace.KindServer)
+	_, query := trace.Start(ctx, "query.GetPostByNumber", trace.KindInternal)
+	query.SetAttribute("number", 1)
+	query.End(errors.New("not found"))
+	server.End(nil)
+	server.End(errors.New("only first call is recorded"))
+
+	err := trace.Shutdown(context.Background())
+	Expect(err).IsNil()
+	Expect(exporter.spans).HasLen(2)
+
+	Expect(server.Context.TraceID).Equals(parent.TraceID)
+	Expect(server.ParentID).Equals(parent.SpanID)
+	Expect(server.Failed).IsFalse()
+	Expect(query.Context.TraceID).Equals(parent.TraceID)
+	Expect(query.ParentID).Equals(server.Context.SpanID)
+	Expect(query.Failed).IsTrue()
+	Expect(query.Error).Equals("not found")
+	Expect(query.Attributes["number"]).Equals(1)
+}
+
+func TestStart_RootSpanWithLink(t *testing.T) {
+	RegisterT(t)
+	exporter := &recorder{}
+	trace.Use(exporter)
+
+	origin, _ := trace.ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	_, span := trace.Start(context.Background(), "task", trace.KindConsumer, origin, trace.SpanContext{})
+	span.End(nil)
+
+	Expect(trace.Shutdown(context.Background())).IsNil()
+	Expect(exporter.spans).HasLen(1)
+	Expect(span.Context.IsValid()).IsTrue()
+	Expect(span.Context.TraceID == origin.TraceID).IsFalse()
+	Expect(span.ParentID.IsValid()).IsFalse()
+	Expect(span.Links).Equals([]trace.SpanContext{origin})
+}
+
+func TestStart_NotSampled(t *testing.T) {
+	RegisterT(t)
+	exporter := &recorder{}
+	trace.Use(exporter)
+
+	parent, _ := trace.ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+	ctx := trace.WithParent(context.Background(), parent)
+	ctx, span := trace.Start(ctx, "GET /", trace.KindServer)
+	span.End(nil)
+
+	env.Config.Tracing.SampleRatio = 0
+	_, root := trace.Start(context.Background(), "GET /", trace.KindServer)
+	root.End(nil)
+
+	Expect(trace.Shutdown(context.Background())).IsNil()
+	Expect(exporter.spans).HasLen(0)
+	Expect(trace.FromContext(ctx).Sampled).IsFalse()
+	Expect(root.Context.Sampled).IsFalse()
+}
+
+func TestWriterExporter(t *testing.T) {
+	RegisterT(t)
+	buffer := new(bytes.Buffer)
+	trace.Use(trace.NewWriterExporter(buffer))
+
+	parent, _ := trace.ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	ctx := trace.WithParent(context.Background(), parent)
+	_, span := trace.Start(ctx, "SQL SELECT", trace.KindClient)
+	span.SetAttribute("db.statement", "SELECT 1")
+	span.SetAttribute("rows", 1)
+	span.End(errors.New("canceled"))
+
+	Expect(trace.Shutdown(context.Background())).IsNil()
+
+	var result map[string]interface{}
+	err := json.Unmarshal(buffer.Bytes(), &result)
+	Expect(err).IsNil()
+
+	encoded := result["resourceSpans"].([]interface{})[0].(map[string]interface{})
+	resource := encoded["resource"].(map[string]interface{})["attributes"].([]interface{})[0].(map[string]interface{})
+	Expect(resource["key"]).Equals("service.name")
+	Expect(resource["value"]).Equals(map[string]interface{}{"stringValue": "fider"})
+
+	s := encoded["scopeSpans"].([]interface{})[0].(map[string]interface{})["spans"].([]interface{})[0].(map[string]interface{})
+	Expect(s["traceId"]).Equals("4bf92f3577b34da6a3ce929d0e0e4736")
+	Expect(s["spanId"]).Equals(span.Context.SpanID.String())
+	Expect(s["parentSpanId"]).Equals("00f067aa0ba902b7")
+	Expect(s["name"]).Equals("SQL SELECT")
+	Expect(s["kind"]).Equals(float64(3))
+	Expect(s["status"]).Equals(map[string]interface{}{"code": float64(2), "message": "canceled"})
+	Expect(s["attributes"]).Equals([]interface{}{
+		map[string]interface{}{"key": "db.statement", "value": map[string]interface{}{"stringValue": "SELECT 1"}},
+		map[string]interface{}{"key": "rows", "value": map[string]interface{}{"intValue": "1"}},
+	})
+}
+
+func TestOTLPExporter(t *testing.T) {
+	RegisterT(t)
+
+	var body []byte
+	var headers http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = r.Header
+		body, _ = ioutil.ReadAll(r.Body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	trace.Use(trace.NewOTLPExporter(server.URL+"/v1/traces", map[string]string{"Authorization": "Bearer secret"}))
+
+	_, span := trace.Start(context.Background(), "GET /", trace.KindServer)
+	span.End(nil)
+	Expect(trace.Shutdown(context.Background())).IsNil()
+
+	Expect(headers.Get("Content-Type")).Equals("application/json")
+	Expect(headers.Get("Authorization")).Equals("Bearer secret")
+	Expect(string(body)).ContainsSubstring(`"traceId":"` + span.Context.TraceID.String() + `"`)
+	Expect(string(body)).ContainsSubstring(`"name":"GET /"`)
+}
+
+func TestOTLPExporter_Failure(t *testing.T) {
+	RegisterT(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	exporter := trace.NewOTLPExporter(server.URL, nil)
+	err := exporter.Export([]*trace.Span{})
+	Expect(err).IsNotNil()
+}
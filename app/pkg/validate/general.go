@@ -3,6 +3,7 @@ package validate
 import (
 	"context"
 	"fmt"
+	"net"
 	"net/url"
 	"regexp"
 	"strings"
@@ -47,6 +48,56 @@ func URL(rawurl string) []string {
 	return []string{}
 }
 
+var internalNetworks = parseCIDRs("0.0.0.0/8", "10.0.0.0/8", "100.64.0.0/10", "172.16.0.0/12", "192.168.0.0/16", "fc00::/7")
+
+func parseCIDRs(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, len(cidrs))
+	for i, cidr := range cidrs {
+		_, networks[i], _ = net.ParseCIDR(cidr)
+	}
+	return networks
+}
+
+//IsPublicIP returns false for loopback, link-local and private addresses
+func IsPublicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsUnspecified() {
+		return false
+	}
+	for _, network := range internalNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+//PublicURL validates that given URL doesn't point to a loopback, link-local or private address
+//Hosts that can't be resolved yet are accepted, the address is checked again when requests are sent
+func PublicURL(ctx context.Context, rawurl string) []string {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return []string{fmt.Sprintf("'%s' is not a valid URL address.", rawurl)}
+	}
+
+	host := u.Hostname()
+	ips := make([]net.IP, 0)
+	if ip := net.ParseIP(host); ip != nil {
+		ips = append(ips, ip)
+	} else if addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host); err == nil {
+		for _, addr := range addrs {
+			ips = append(ips, addr.IP)
+		}
+	}
+
+	for _, ip := range ips {
+		if !IsPublicIP(ip) {
+			return []string{fmt.Sprintf("'%s' points to an internal address.", host)}
+		}
+	}
+
+	return []string{}
+}
+
 //CNAME validates given cname
 func CNAME(ctx context.Context, cname string) []string {
 	cname = strings.ToLower(cname)
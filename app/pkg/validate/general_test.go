@@ -72,6 +72,40 @@ func TestValidURL(t *testing.T) {
 	}
 }
 
+func TestInternalURL(t *testing.T) {
+	RegisterT(t)
+
+	for _, rawurl := range []string{
+		"http://localhost:3000/hook",
+		"http://127.0.0.1/hook",
+		"http://10.0.0.8/hook",
+		"http://172.20.1.1/hook",
+		"http://192.168.1.1/hook",
+		"http://169.254.169.254/latest/meta-data",
+		"http://0.0.0.0/hook",
+		"http://[::1]/hook",
+		"http://[fd00::1]/hook",
+		"http://[::ffff:127.0.0.1]/hook",
+	} {
+		messages := validate.PublicURL(context.Background(), rawurl)
+		Expect(len(messages) > 0).IsTrue()
+	}
+}
+
+func TestPublicURL(t *testing.T) {
+	RegisterT(t)
+
+	for _, rawurl := range []string{
+		"http://8.8.8.8/hook",
+		"https://172.32.0.1/hook",
+		"https://[2001:4860:4860::8888]/hook",
+		"https://unresolvable.invalid/hook",
+	} {
+		messages := validate.PublicURL(context.Background(), rawurl)
+		Expect(messages).HasLen(0)
+	}
+}
+
 func TestInvalidCNAME(t *testing.T) {
 	RegisterT(t)
 
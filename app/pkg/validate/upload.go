@@ -1,15 +1,20 @@
 package validate
 
 import (
+	"context"
 	"fmt"
+	"mime"
+	"net/http"
+	"strings"
 
 	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/query"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/images"
 	"github.com/goenning/imagic"
 )
 
-// MaxDimensionSize is the max width/height of an image. If image is bigger than this, it'll be resized.
-const MaxDimensionSize = 1500
-
 // MultiImageUploadOpts arguments to validate mulitple image upload process
 type MultiImageUploadOpts struct {
 	MaxUploads   int
@@ -94,6 +99,10 @@ func ImageUpload(upload *models.ImageUpload, opts ImageUploadOpts) ([]string, er
 				messages = append(messages, fmt.Sprintf("The image must have minimum dimensions of %dx%d pixels.", opts.MinWidth, opts.MinHeight))
 			}
 
+			if int64(logo.Width)*int64(logo.Height) > images.MaxPixels {
+				messages = append(messages, fmt.Sprintf("The image must have less than %d megapixels.", images.MaxPixels/1000000))
+			}
+
 			if opts.ExactRatio && logo.Width != logo.Height {
 				messages = append(messages, "The image must have an aspect ratio of 1:1.")
 			}
@@ -101,16 +110,197 @@ func ImageUpload(upload *models.ImageUpload, opts ImageUploadOpts) ([]string, er
 			if logo.Size > (opts.MaxKilobytes * 1024) {
 				messages = append(messages, fmt.Sprintf("The image size must be smaller than %dKB.", opts.MaxKilobytes))
 			}
+		}
+	}
+
+	return messages, nil
+}
+
+// MultiFileUploadOpts arguments to validate multiple file upload process
+type MultiFileUploadOpts struct {
+	MaxUploads     int
+	MaxKilobytes   int
+	AllowedTypes   string
+	QuotaKilobytes int
+}
 
-			if logo.Height > MaxDimensionSize && logo.Width > MaxDimensionSize {
-				newImageBytes, err := imagic.Apply(upload.Upload.Content, imagic.Resize(MaxDimensionSize))
-				if err != nil {
-					return nil, err
+//MultiFileUpload validates multiple file uploads, including their MIME type, size and a virus scan
+//Content type of each new upload is normalized to the one that should be stored
+func MultiFileUpload(ctx context.Context, currentFiles []*models.FileAttachment, uploads []*models.FileUpload, opts MultiFileUploadOpts) ([]string, error) {
+	messages := []string{}
+	totalCount := len(currentFiles)
+	totalSize := 0
+
+	for _, upload := range uploads {
+		if upload.Remove {
+			for _, file := range currentFiles {
+				if file.BlobKey == upload.BlobKey {
+					totalCount--
 				}
-				upload.Upload.Content = newImageBytes
 			}
+			continue
+		}
+
+		if upload.Upload == nil {
+			continue
+		}
+
+		if opts.AllowedTypes == "" {
+			return []string{"File attachments are not enabled on this site."}, nil
 		}
+
+		totalCount++
+		data := upload.Upload
+		if strings.TrimSpace(data.FileName) == "" {
+			messages = append(messages, "File name is required.")
+			continue
+		}
+		if len(data.FileName) > 200 {
+			messages = append(messages, "File name must have less than 200 characters.")
+			continue
+		}
+		if len(data.Content) == 0 {
+			messages = append(messages, fmt.Sprintf("The file '%s' is empty.", data.FileName))
+			continue
+		}
+
+		contentType, ok := fileContentType(data.ContentType, data.Content)
+		data.ContentType = contentType
+		if !ok {
+			messages = append(messages, fmt.Sprintf("The content of file '%s' doesn't match its type (%s).", data.FileName, data.ContentType))
+			continue
+		}
+		if !IsFileTypeAllowed(data.ContentType, opts.AllowedTypes) {
+			messages = append(messages, fmt.Sprintf("The file '%s' has a type (%s) that is not allowed on this site.", data.FileName, data.ContentType))
+			continue
+		}
+
+		if len(data.Content) > opts.MaxKilobytes*1024 {
+			messages = append(messages, fmt.Sprintf("The file '%s' must be smaller than %dKB.", data.FileName, opts.MaxKilobytes))
+			continue
+		}
+
+		scan := &cmd.ScanFile{FileName: data.FileName, Content: data.Content}
+		if err := bus.Dispatch(ctx, scan); err != nil {
+			return nil, err
+		}
+		if scan.Infected {
+			messages = append(messages, fmt.Sprintf("The file '%s' was rejected by the virus scanner.", data.FileName))
+			continue
+		}
+
+		totalSize += len(data.Content)
 	}
 
-	return messages, nil
+	if len(messages) > 0 {
+		return messages, nil
+	}
+
+	if totalCount > opts.MaxUploads {
+		return []string{fmt.Sprintf("A maximum of %d files are allowed.", opts.MaxUploads)}, nil
+	}
+
+	if totalSize > 0 && opts.QuotaKilobytes > 0 {
+		usage := &query.GetFileAttachmentsSize{}
+		if err := bus.Dispatch(ctx, usage); err != nil {
+			return nil, err
+		}
+		if usage.Result+totalSize > opts.QuotaKilobytes*1024 {
+			return []string{"This site has reached its storage limit for file attachments."}, nil
+		}
+	}
+
+	return []string{}, nil
+}
+
+//FileTypes validates a list of MIME types separated by commas or new lines, like "application/pdf, text/*"
+func FileTypes(allowedTypes string) []string {
+	messages := []string{}
+	for _, allowed := range splitFileTypes(allowedTypes) {
+		parts := strings.Split(allowed, "/")
+		if len(parts) != 2 || parts[0] == "" || parts[0] == "*" || parts[1] == "" || strings.Contains(allowed, ";") {
+			messages = append(messages, fmt.Sprintf("'%s' is not a valid MIME type.", allowed))
+		}
+	}
+	return messages
+}
+
+//IsFileTypeAllowed returns true if given content type matches one of the allowed MIME types
+//A wildcard subtype (text/*) matches all types of the same family
+func IsFileTypeAllowed(contentType, allowedTypes string) bool {
+	contentType = strings.ToLower(contentType)
+	for _, allowed := range splitFileTypes(allowedTypes) {
+		if allowed == contentType {
+			return true
+		}
+		if strings.HasSuffix(allowed, "/*") && strings.HasPrefix(contentType, strings.TrimSuffix(allowed, "*")) {
+			return true
+		}
+	}
+	return false
+}
+
+func splitFileTypes(allowedTypes string) []string {
+	return strings.FieldsFunc(strings.ToLower(allowedTypes), func(r rune) bool {
+		return r == ',' || r == '\n' || r == '\r' || r == ' ' || r == '\t'
+	})
+}
+
+//detectableTypes are the MIME types that http.DetectContentType recognizes from the content
+var detectableTypes = []string{
+	"text/html", "text/xml", "text/plain",
+	"application/pdf", "application/postscript", "application/ogg", "application/wasm",
+	"application/zip", "application/x-gzip", "application/x-rar-compressed",
+	"image/gif", "image/png", "image/jpeg", "image/bmp", "image/webp", "image/x-icon",
+	"audio/basic", "audio/aiff", "audio/mpeg", "audio/midi", "audio/wave",
+	"video/avi", "video/mp4", "video/webm",
+	"font/ttf", "font/otf", "font/collection", "font/woff", "font/woff2",
+}
+
+//fileContentType returns the MIME type (without parameters) declared by the client or detected from the content
+//It returns false when the declared type doesn't agree with the content, such as an HTML page declared as an image
+func fileContentType(declared string, content []byte) (string, bool) {
+	detected, _, _ := mime.ParseMediaType(http.DetectContentType(content))
+	contentType, _, err := mime.ParseMediaType(declared)
+	contentType = strings.ToLower(contentType)
+	if err != nil || contentType == "" || contentType == "application/octet-stream" {
+		return detected, true
+	}
+
+	if contentType == detected {
+		return contentType, true
+	}
+
+	switch detected {
+	case "application/octet-stream":
+		//Content is not recognized, which is expected for types that can't be detected
+		for _, detectable := range detectableTypes {
+			if contentType == detectable {
+				return contentType, false
+			}
+		}
+		return contentType, true
+	case "text/plain":
+		return contentType, isTextType(contentType)
+	case "text/xml":
+		return contentType, strings.HasSuffix(contentType, "/xml") || strings.HasSuffix(contentType, "+xml")
+	case "application/zip":
+		//Office documents, EPUB and JAR files are ZIP archives
+		return contentType, strings.HasPrefix(contentType, "application/vnd.") || strings.HasSuffix(contentType, "+zip") ||
+			contentType == "application/java-archive" || contentType == "application/x-zip-compressed"
+	}
+	return contentType, false
+}
+
+//isTextType returns true if given MIME type is of a plain text format, like CSV, Markdown or JSON
+func isTextType(contentType string) bool {
+	if strings.HasPrefix(contentType, "text/") {
+		return true
+	}
+	for _, suffix := range []string{"/json", "+json", "/xml", "+xml", "/javascript", "/x-yaml", "/yaml"} {
+		if strings.HasSuffix(contentType, suffix) {
+			return true
+		}
+	}
+	return false
 }
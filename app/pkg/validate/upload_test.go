@@ -1,13 +1,19 @@
 package validate_test
 
 import (
+	"context"
 	"io/ioutil"
+	"strings"
 	"testing"
 
 	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/query"
 	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/bus"
 	"github.com/getfider/fider/app/pkg/env"
 	"github.com/getfider/fider/app/pkg/validate"
+	"github.com/getfider/fider/app/services/antivirus"
 )
 
 func TestValidateImageUpload(t *testing.T) {
@@ -70,6 +76,23 @@ func TestValidateImageUpload_ExactRatio(t *testing.T) {
 	Expect(err).IsNil()
 }
 
+func TestValidateImageUpload_TooManyPixels(t *testing.T) {
+	RegisterT(t)
+
+	//GIF of 1x1 pixel that declares a logical screen of 65535x65535 pixels
+	content := []byte("GIF89a\xff\xff\xff\xff\x80\x00\x00\x00\x00\x00\xff\xff\xff,\x00\x00\x00\x00\x01\x00\x01\x00\x00\x02\x02D\x01\x00;")
+	upload := &models.ImageUpload{
+		Upload: &models.ImageUploadData{
+			Content: content,
+		},
+	}
+	messages, err := validate.ImageUpload(upload, validate.ImageUploadOpts{
+		MaxKilobytes: 100,
+	})
+	Expect(messages).Equals([]string{"The image must have less than 40 megapixels."})
+	Expect(err).IsNil()
+}
+
 func TestValidateImageUpload_Nil(t *testing.T) {
 	RegisterT(t)
 
@@ -187,3 +210,158 @@ func TestValidateMultiImageUpload_Existing(t *testing.T) {
 	Expect(messages).HasLen(0)
 	Expect(err).IsNil()
 }
+
+func TestValidateMultiFileUpload(t *testing.T) {
+	RegisterT(t)
+
+	bus.AddHandler(func(ctx context.Context, c *cmd.ScanFile) error {
+		if string(c.Content) == antivirus.EICAR {
+			c.Infected = true
+			c.Threat = "Eicar-Test-Signature"
+		}
+		return nil
+	})
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetFileAttachmentsSize) error {
+		q.Result = 1000
+		return nil
+	})
+
+	opts := validate.MultiFileUploadOpts{
+		MaxUploads:     2,
+		MaxKilobytes:   1,
+		AllowedTypes:   "application/pdf, text/*",
+		QuotaKilobytes: 10,
+	}
+
+	var testCases = []struct {
+		upload   *models.FileUploadData
+		count    int
+		expected string
+	}{
+		{&models.FileUploadData{FileName: "doc.pdf", ContentType: "application/pdf", Content: []byte("%PDF-1.4")}, 0, "application/pdf"},
+		{&models.FileUploadData{FileName: "notes.txt", ContentType: "", Content: []byte("Hello World")}, 0, "text/plain"},
+		{&models.FileUploadData{FileName: "notes.md", ContentType: "text/markdown; charset=utf-8", Content: []byte("# Hello")}, 0, "text/markdown"},
+		{&models.FileUploadData{FileName: "app.exe", ContentType: "application/x-msdownload", Content: []byte("MZ")}, 1, "application/x-msdownload"},
+		{&models.FileUploadData{FileName: "", ContentType: "application/pdf", Content: []byte("%PDF-1.4")}, 1, "application/pdf"},
+		{&models.FileUploadData{FileName: "empty.pdf", ContentType: "application/pdf", Content: []byte{}}, 1, "application/pdf"},
+		{&models.FileUploadData{FileName: "big.txt", ContentType: "text/plain", Content: []byte(strings.Repeat("a", 2048))}, 1, "text/plain"},
+		{&models.FileUploadData{FileName: "page.txt", ContentType: "text/plain", Content: []byte("<html><script>alert(1)</script></html>")}, 1, "text/plain"},
+		{&models.FileUploadData{FileName: "doc.pdf", ContentType: "application/pdf", Content: []byte("<html><script>alert(1)</script></html>")}, 1, "application/pdf"},
+		{&models.FileUploadData{FileName: "data.txt", ContentType: "text/plain", Content: make([]byte, 100)}, 1, "text/plain"},
+		{&models.FileUploadData{FileName: "data.csv", ContentType: "text/csv", Content: []byte("a,b\n1,2")}, 0, "text/csv"},
+		{&models.FileUploadData{FileName: "eicar.txt", ContentType: "text/plain", Content: []byte(antivirus.EICAR)}, 1, "text/plain"},
+	}
+
+	for _, testCase := range testCases {
+		uploads := []*models.FileUpload{
+			&models.FileUpload{Upload: testCase.upload},
+		}
+		messages, err := validate.MultiFileUpload(context.Background(), nil, uploads, opts)
+		Expect(messages).HasLen(testCase.count)
+		Expect(err).IsNil()
+		Expect(testCase.upload.ContentType).Equals(testCase.expected)
+	}
+}
+
+func TestValidateMultiFileUpload_NotEnabled(t *testing.T) {
+	RegisterT(t)
+
+	uploads := []*models.FileUpload{
+		&models.FileUpload{
+			Upload: &models.FileUploadData{FileName: "doc.pdf", ContentType: "application/pdf", Content: []byte("%PDF-1.4")},
+		},
+	}
+
+	messages, err := validate.MultiFileUpload(context.Background(), nil, uploads, validate.MultiFileUploadOpts{
+		MaxUploads:   2,
+		MaxKilobytes: 100,
+	})
+	Expect(messages).Equals([]string{"File attachments are not enabled on this site."})
+	Expect(err).IsNil()
+}
+
+func TestValidateMultiFileUpload_MaxUploads(t *testing.T) {
+	RegisterT(t)
+
+	bus.AddHandler(func(ctx context.Context, c *cmd.ScanFile) error {
+		return nil
+	})
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetFileAttachmentsSize) error {
+		return nil
+	})
+
+	currentFiles := []*models.FileAttachment{
+		&models.FileAttachment{BlobKey: "files/file1.pdf"},
+		&models.FileAttachment{BlobKey: "files/file2.pdf"},
+	}
+	newFile := &models.FileUploadData{FileName: "doc.pdf", ContentType: "application/pdf", Content: []byte("%PDF-1.4")}
+
+	opts := validate.MultiFileUploadOpts{
+		MaxUploads:     2,
+		MaxKilobytes:   100,
+		AllowedTypes:   "application/pdf",
+		QuotaKilobytes: 100,
+	}
+
+	messages, err := validate.MultiFileUpload(context.Background(), currentFiles, []*models.FileUpload{
+		&models.FileUpload{Upload: newFile},
+	}, opts)
+	Expect(messages).Equals([]string{"A maximum of 2 files are allowed."})
+	Expect(err).IsNil()
+
+	messages, err = validate.MultiFileUpload(context.Background(), currentFiles, []*models.FileUpload{
+		&models.FileUpload{BlobKey: "files/file1.pdf", Remove: true},
+		&models.FileUpload{Upload: newFile},
+	}, opts)
+	Expect(messages).HasLen(0)
+	Expect(err).IsNil()
+}
+
+func TestValidateMultiFileUpload_Quota(t *testing.T) {
+	RegisterT(t)
+
+	bus.AddHandler(func(ctx context.Context, c *cmd.ScanFile) error {
+		return nil
+	})
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetFileAttachmentsSize) error {
+		q.Result = 10 * 1024
+		return nil
+	})
+
+	uploads := []*models.FileUpload{
+		&models.FileUpload{
+			Upload: &models.FileUploadData{FileName: "doc.pdf", ContentType: "application/pdf", Content: []byte("%PDF-1.4")},
+		},
+	}
+
+	messages, err := validate.MultiFileUpload(context.Background(), nil, uploads, validate.MultiFileUploadOpts{
+		MaxUploads:     2,
+		MaxKilobytes:   100,
+		AllowedTypes:   "application/pdf",
+		QuotaKilobytes: 10,
+	})
+	Expect(messages).Equals([]string{"This site has reached its storage limit for file attachments."})
+	Expect(err).IsNil()
+}
+
+func TestValidateFileTypes(t *testing.T) {
+	RegisterT(t)
+
+	Expect(validate.FileTypes("")).HasLen(0)
+	Expect(validate.FileTypes("application/pdf, text/*\nimage/png")).HasLen(0)
+	Expect(validate.FileTypes("pdf, */*, text/plain;charset=utf-8")).HasLen(3)
+}
+
+func TestIsFileTypeAllowed(t *testing.T) {
+	RegisterT(t)
+
+	Expect(validate.IsFileTypeAllowed("application/pdf", "application/pdf, text/*")).IsTrue()
+	Expect(validate.IsFileTypeAllowed("Application/PDF", "application/pdf")).IsTrue()
+	Expect(validate.IsFileTypeAllowed("text/csv", "application/pdf, text/*")).IsTrue()
+	Expect(validate.IsFileTypeAllowed("application/zip", "application/pdf, text/*")).IsFalse()
+	Expect(validate.IsFileTypeAllowed("textual/plain", "text/*")).IsFalse()
+	Expect(validate.IsFileTypeAllowed("application/pdf", "")).IsFalse()
+}
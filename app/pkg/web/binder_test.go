@@ -124,6 +124,26 @@ func TestDefaultBinder_Array_TrimSpaces(t *testing.T) {
 	})
 }
 
+type Topping string
+
+func TestDefaultBinder_CustomStringTypes_TrimSpaces(t *testing.T) {
+	RegisterT(t)
+
+	type pizza struct {
+		Main     Topping   `json:"main" format:"lower"`
+		Toppings []Topping `json:"toppings" format:"lower"`
+	}
+
+	params := make(web.StringMap)
+	body := `{ "main": " Cheese ", "toppings": [ " Ham", " OLIVES " ] }`
+	ctx := newBodyContext("POST", params, body, "application/json")
+	u := new(pizza)
+	err := binder.Bind(u, ctx)
+	Expect(err).IsNil()
+	Expect(u.Main).Equals(Topping("cheese"))
+	Expect(u.Toppings).Equals([]Topping{"ham", "olives"})
+}
+
 func TestDefaultBinder_DELETE(t *testing.T) {
 	RegisterT(t)
 
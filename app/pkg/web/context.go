@@ -23,6 +23,7 @@ import (
 	"github.com/getfider/fider/app/pkg/bus"
 	"github.com/getfider/fider/app/pkg/env"
 	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/getfider/fider/app/pkg/i18n"
 	"github.com/getfider/fider/app/pkg/log"
 	"github.com/getfider/fider/app/pkg/rand"
 	"github.com/getfider/fider/app/pkg/validate"
@@ -124,6 +125,7 @@ func (c *Context) ContextID() string {
 }
 
 //Commit everything that is pending on current context
+//Handlers that need to act once their changes are committed can call it, the transaction can't be used afterwards
 func (c *Context) Commit() error {
 	trx, ok := c.Value(app.TransactionCtxKey).(*dbx.Trx)
 	if ok && trx != nil {
@@ -135,6 +137,7 @@ func (c *Context) Commit() error {
 	for _, task := range c.tasks {
 		c.engine.worker.Enqueue(task)
 	}
+	c.tasks = nil
 
 	return nil
 }
@@ -275,11 +278,36 @@ func (c *Context) HandleValidation(result *validate.Result) error {
 
 //Attachment returns an attached file
 func (c *Context) Attachment(fileName, contentType string, file []byte) error {
-	c.Response.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", fileName))
+	c.Response.Header().Set("Content-Disposition", contentDisposition(fileName))
 
 	return c.Blob(http.StatusOK, contentType, file)
 }
 
+//contentDisposition returns the Content-Disposition of a file download
+//Names with special characters also have an ASCII fallback and are encoded as per RFC 6266
+func contentDisposition(fileName string) string {
+	fallback := strings.Map(func(r rune) rune {
+		if r < 0x20 || r > 0x7e || r == '"' || r == '\\' {
+			return '_'
+		}
+		return r
+	}, fileName)
+
+	if fallback == fileName {
+		return fmt.Sprintf("attachment; filename=\"%s\"", fileName)
+	}
+
+	encoded := new(strings.Builder)
+	for _, b := range []byte(fileName) {
+		if ('a' <= b && b <= 'z') || ('A' <= b && b <= 'Z') || ('0' <= b && b <= '9') || strings.IndexByte("!#$&+-.^_`|~", b) >= 0 {
+			encoded.WriteByte(b)
+		} else {
+			fmt.Fprintf(encoded, "%%%02X", b)
+		}
+	}
+	return fmt.Sprintf("attachment; filename=\"%s\"; filename*=UTF-8''%s", fallback, encoded.String())
+}
+
 //Ok returns 200 OK with JSON result
 func (c *Context) Ok(data interface{}) error {
 	return c.JSON(http.StatusOK, data)
@@ -355,6 +383,11 @@ func (c *Context) User() *models.User {
 	return nil
 }
 
+//Locale returns the language preferred by current user, or the default language of current tenant
+func (c *Context) Locale() string {
+	return i18n.UserLocale(c.User(), c.Tenant())
+}
+
 //SetUser update HTTP context with current user
 func (c *Context) SetUser(user *models.User) {
 	if user != nil {
@@ -363,6 +396,20 @@ func (c *Context) SetUser(user *models.User) {
 	c.Set(app.UserCtxKey, user)
 }
 
+//APIToken returns the API Token used to authenticate current request, if any
+func (c *Context) APIToken() *models.APIToken {
+	token, ok := c.Value(app.APITokenCtxKey).(*models.APIToken)
+	if ok {
+		return token
+	}
+	return nil
+}
+
+//SetAPIToken update HTTP context with the API Token used to authenticate current request
+func (c *Context) SetAPIToken(token *models.APIToken) {
+	c.Set(app.APITokenCtxKey, token)
+}
+
 //AddCookie adds a cookie
 func (c *Context) AddCookie(name, value string, expires time.Time) *http.Cookie {
 	cookie := &http.Cookie{
@@ -17,6 +17,7 @@ import (
 	"github.com/getfider/fider/app/pkg/env"
 	"github.com/getfider/fider/app/pkg/errors"
 	"github.com/getfider/fider/app/pkg/log"
+	"github.com/getfider/fider/app/pkg/metrics"
 	"github.com/getfider/fider/app/pkg/rand"
 	"github.com/getfider/fider/app/pkg/worker"
 	"github.com/julienschmidt/httprouter"
@@ -46,7 +47,10 @@ type notFoundHandler struct {
 
 func (h *notFoundHandler) ServeHTTP(res http.ResponseWriter, req *http.Request) {
 	ctx := NewContext(h.engine, req, res, nil)
+	span := startRequestSpan(ctx, req.Method, "NotFound")
 	_ = h.handler(ctx)
+	endRequestSpan(ctx, span)
+	observeRequest(ctx, req.Method, "NotFound")
 }
 
 //HandlerFunc represents an HTTP handler
@@ -81,15 +85,27 @@ func New(settings *models.SystemSettings) *Engine {
 		renderer:    NewRenderer(settings),
 		binder:      NewDefaultBinder(),
 		middlewares: make([]MiddlewareFunc, 0),
-		worker:      worker.New(),
+		worker:      newWorker(),
 		cache:       cache.New(5*time.Minute, 10*time.Minute),
 	}
 
 	return router
 }
 
+//newWorker keeps tasks in memory, unless WORKER_QUEUE=database opts in to the durable queue
+func newWorker() worker.Worker {
+	if env.Config.Worker.Queue == "database" {
+		return worker.NewDatabaseWorker()
+	}
+	return worker.New()
+}
+
 //Start the server.
 func (e *Engine) Start(address string) {
+	metrics.NewGaugeFunc("fider_worker_queue_length", "Number of tasks waiting to be processed or being processed by the worker.", func() float64 {
+		return float64(e.worker.Length())
+	})
+
 	log.Info(e, "Application is starting")
 	log.Infof(e, "GO_ENV: @{Env}", dto.Props{
 		"Env": env.Config.Environment,
@@ -192,22 +208,22 @@ func (e *Engine) Use(middleware MiddlewareFunc) {
 
 //Get handles HTTP GET requests
 func (e *Engine) Get(path string, handler HandlerFunc) {
-	e.mux.Handle("GET", path, e.handle(e.middlewares, handler))
+	e.mux.Handle("GET", path, e.handle("GET", path, e.middlewares, handler))
 }
 
 //Post handles HTTP POST requests
 func (e *Engine) Post(path string, handler HandlerFunc) {
-	e.mux.Handle("POST", path, e.handle(e.middlewares, handler))
+	e.mux.Handle("POST", path, e.handle("POST", path, e.middlewares, handler))
 }
 
 //Put handles HTTP PUT requests
 func (e *Engine) Put(path string, handler HandlerFunc) {
-	e.mux.Handle("PUT", path, e.handle(e.middlewares, handler))
+	e.mux.Handle("PUT", path, e.handle("PUT", path, e.middlewares, handler))
 }
 
 //Delete handles HTTP DELETE requests
 func (e *Engine) Delete(path string, handler HandlerFunc) {
-	e.mux.Handle("DELETE", path, e.handle(e.middlewares, handler))
+	e.mux.Handle("DELETE", path, e.handle("DELETE", path, e.middlewares, handler))
 }
 
 //NotFound register how to handle routes that are not found
@@ -218,7 +234,7 @@ func (e *Engine) NotFound(handler HandlerFunc) {
 	}
 }
 
-func (e *Engine) handle(middlewares []MiddlewareFunc, handler HandlerFunc) httprouter.Handle {
+func (e *Engine) handle(method, route string, middlewares []MiddlewareFunc, handler HandlerFunc) httprouter.Handle {
 	next := handler
 	for i := len(middlewares) - 1; i >= 0; i-- {
 		next = middlewares[i](next)
@@ -229,7 +245,10 @@ func (e *Engine) handle(middlewares []MiddlewareFunc, handler HandlerFunc) httpr
 			params[p.Key] = p.Value
 		}
 		ctx := NewContext(e, req, res, params)
+		span := startRequestSpan(ctx, method, route)
 		_ = next(ctx)
+		endRequestSpan(ctx, span)
+		observeRequest(ctx, method, route)
 	}
 	return h
 }
@@ -256,22 +275,22 @@ func (g *Group) Use(middleware MiddlewareFunc) {
 
 //Get handles HTTP GET requests
 func (g *Group) Get(path string, handler HandlerFunc) {
-	g.engine.mux.Handle("GET", path, g.engine.handle(g.middlewares, handler))
+	g.engine.mux.Handle("GET", path, g.engine.handle("GET", path, g.middlewares, handler))
 }
 
 //Post handles HTTP POST requests
 func (g *Group) Post(path string, handler HandlerFunc) {
-	g.engine.mux.Handle("POST", path, g.engine.handle(g.middlewares, handler))
+	g.engine.mux.Handle("POST", path, g.engine.handle("POST", path, g.middlewares, handler))
 }
 
 //Put handles HTTP PUT requests
 func (g *Group) Put(path string, handler HandlerFunc) {
-	g.engine.mux.Handle("PUT", path, g.engine.handle(g.middlewares, handler))
+	g.engine.mux.Handle("PUT", path, g.engine.handle("PUT", path, g.middlewares, handler))
 }
 
 //Delete handles HTTP DELETE requests
 func (g *Group) Delete(path string, handler HandlerFunc) {
-	g.engine.mux.Handle("DELETE", path, g.engine.handle(g.middlewares, handler))
+	g.engine.mux.Handle("DELETE", path, g.engine.handle("DELETE", path, g.middlewares, handler))
 }
 
 // Static return files from given folder
@@ -298,7 +317,7 @@ func (g *Group) Static(prefix, root string) {
 			return nil
 		}
 	}
-	g.engine.mux.Handle("GET", prefix, g.engine.handle(g.middlewares, h))
+	g.engine.mux.Handle("GET", prefix, g.engine.handle("GET", prefix, g.middlewares, h))
 }
 
 // ParseCookie return a list of cookie parsed from raw Set-Cookie
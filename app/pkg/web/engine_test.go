@@ -1,12 +1,18 @@
 package web_test
 
 import (
+	"bytes"
+	"context"
 	"io/ioutil"
 	"net/http"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/getfider/fider/app/models"
 
+	"github.com/getfider/fider/app/pkg/metrics"
+	"github.com/getfider/fider/app/pkg/trace"
 	"github.com/getfider/fider/app/pkg/web"
 
 	. "github.com/getfider/fider/app/pkg/assert"
@@ -98,3 +104,68 @@ func TestEngine_MiddlewareAfterHandler(t *testing.T) {
 
 	StopServer()
 }
+
+func TestEngine_RequestMetrics(t *testing.T) {
+	RegisterT(t)
+	StartServer()
+
+	resp, err := http.Get("http://127.0.0.1:8080/api/echo?name=John")
+	Expect(err).IsNil()
+	Expect(resp.StatusCode).Equals(http.StatusOK)
+	resp.Body.Close()
+
+	buffer := new(bytes.Buffer)
+	err = metrics.WriteText(buffer)
+	Expect(err).IsNil()
+	Expect(buffer.String()).ContainsSubstring(`fider_http_requests_total{method="GET",route="/api/echo",code="200"}`)
+	Expect(buffer.String()).ContainsSubstring(`fider_http_request_duration_seconds_count{method="GET",route="/api/echo"}`)
+	Expect(buffer.String()).ContainsSubstring("fider_worker_queue_length 0")
+
+	StopServer()
+}
+
+type spanRecorder struct {
+	lock  sync.Mutex
+	spans []*trace.Span
+}
+
+func (r *spanRecorder) Export(spans []*trace.Span) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.spans = append(r.spans, spans...)
+	return nil
+}
+
+func TestEngine_RequestSpan(t *testing.T) {
+	RegisterT(t)
+	exporter := &spanRecorder{}
+	trace.Use(exporter)
+	StartServer()
+
+	req, _ := http.NewRequest("GET", "http://127.0.0.1:8080/api/echo?name=John", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	resp, err := http.DefaultClient.Do(req)
+	Expect(err).IsNil()
+	Expect(resp.StatusCode).Equals(http.StatusOK)
+	Expect(strings.HasPrefix(resp.Header.Get("traceresponse"), "00-4bf92f3577b34da6a3ce929d0e0e4736-")).IsTrue()
+	resp.Body.Close()
+
+	StopServer()
+	err = trace.Shutdown(context.Background())
+	Expect(err).IsNil()
+
+	var span *trace.Span
+	for _, s := range exporter.spans {
+		if s.Name == "GET /api/echo" {
+			span = s
+		}
+	}
+	Expect(span).IsNotNil()
+	Expect(span.Kind).Equals(trace.KindServer)
+	Expect(span.Context.TraceID.String()).Equals("4bf92f3577b34da6a3ce929d0e0e4736")
+	Expect(span.ParentID.String()).Equals("00f067aa0ba902b7")
+	Expect(resp.Header.Get("traceresponse")).Equals(span.Context.TraceParent())
+	Expect(span.Attributes["http.route"]).Equals("/api/echo")
+	Expect(span.Attributes["http.status_code"]).Equals(http.StatusOK)
+	Expect(span.Failed).IsFalse()
+}
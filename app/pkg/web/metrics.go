@@ -0,0 +1,24 @@
+package web
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/getfider/fider/app/pkg/metrics"
+)
+
+var (
+	httpRequests        = metrics.NewCounter("fider_http_requests_total", "Number of HTTP requests, by method, route and status code.", "method", "route", "code")
+	httpRequestDuration = metrics.NewHistogram("fider_http_request_duration_seconds", "Duration of HTTP requests in seconds, by method and route.", metrics.DefaultBuckets, "method", "route")
+)
+
+//observeRequest records a finished request using the route pattern instead of the URL, so that path parameters don't explode the number of series
+func observeRequest(ctx *Context, method, route string) {
+	code := ctx.ResponseStatusCode
+	if code == 0 {
+		//Responses written directly to the writer, such as static files, don't go through the context
+		code = http.StatusOK
+	}
+	httpRequests.Inc(method, route, strconv.Itoa(code))
+	httpRequestDuration.ObserveSince(ctx.Request.StartTime, method, route)
+}
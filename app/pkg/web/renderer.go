@@ -20,6 +20,7 @@ import (
 	"github.com/getfider/fider/app/pkg/crypto"
 	"github.com/getfider/fider/app/pkg/env"
 	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/getfider/fider/app/pkg/i18n"
 	"github.com/getfider/fider/app/pkg/markdown"
 )
 
@@ -168,9 +169,10 @@ func (r *Renderer) Render(w io.Writer, statusCode int, name string, props Props,
 		tenantName = tenant.Name
 	}
 
+	locale := ctx.Locale()
 	title := tenantName
 	if props.Title != "" {
-		title = fmt.Sprintf("%s · %s", props.Title, tenantName)
+		title = fmt.Sprintf("%s · %s", i18n.T(locale, props.Title), tenantName)
 	}
 
 	public["title"] = title
@@ -225,6 +227,8 @@ func (r *Renderer) Render(w io.Writer, statusCode int, name string, props Props,
 		"tenantAssetsURL": TenantAssetsURL(ctx, ""),
 		"globalAssetsURL": GlobalAssetsURL(ctx, ""),
 		"oauth":           oauthProviders.Result,
+		"locale":          locale,
+		"languages":       i18n.Languages,
 	}
 
 	if ctx.IsAuthenticated() {
@@ -240,6 +244,7 @@ func (r *Renderer) Render(w io.Writer, statusCode int, name string, props Props,
 			"avatarBlobKey":   u.AvatarBlobKey,
 			"isAdministrator": u.IsAdministrator(),
 			"isCollaborator":  u.IsCollaborator(),
+			"locale":          u.Locale,
 		}
 	}
 
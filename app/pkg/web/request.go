@@ -1,6 +1,8 @@
 package web
 
 import (
+	"bytes"
+	"encoding/gob"
 	"io/ioutil"
 	"net"
 	"net/http"
@@ -12,6 +14,11 @@ import (
 	"github.com/getfider/fider/app/pkg/errors"
 )
 
+func init() {
+	//Request is carried by tasks that are persisted by durable workers
+	gob.Register(Request{})
+}
+
 //Request wraps the http request object
 type Request struct {
 	instance      *http.Request
@@ -64,6 +71,48 @@ func WrapRequest(request *http.Request) Request {
 	}
 }
 
+//persistedRequest is what durable workers store of the request that enqueued a task.
+//Body, headers, path and query are left out as they can hold passwords and tokens
+type persistedRequest struct {
+	BaseURL  string
+	ClientIP string
+	IsSecure bool
+}
+
+//GobEncode encodes the parts of the request that tasks need to build links and logs
+func (r Request) GobEncode() ([]byte, error) {
+	persisted := persistedRequest{ClientIP: r.ClientIP, IsSecure: r.IsSecure}
+	if r.URL != nil {
+		persisted.BaseURL = r.BaseURL()
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(persisted); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+//GobDecode rebuilds a request from the parts stored by GobEncode
+func (r *Request) GobDecode(data []byte) error {
+	var persisted persistedRequest
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&persisted); err != nil {
+		return err
+	}
+
+	u, err := url.Parse(persisted.BaseURL)
+	if err != nil {
+		return err
+	}
+
+	*r = Request{
+		ClientIP: persisted.ClientIP,
+		IsSecure: persisted.IsSecure,
+		URL:      u,
+	}
+	return nil
+}
+
 // getClientIP returns the IP of the original requestor.
 func getClientIP(request *http.Request) (clientIP string) {
 	if forwardedHosts := request.Header.Get("X-Forwarded-For"); forwardedHosts != "" {
@@ -1,8 +1,11 @@
 package web_test
 
 import (
+	"bytes"
 	"crypto/tls"
+	"encoding/gob"
 	"net/http"
+	"strings"
 	"testing"
 
 	. "github.com/getfider/fider/app/pkg/assert"
@@ -160,3 +163,29 @@ func TestRequest_IsCrawler(t *testing.T) {
 		Expect(req.IsCrawler()).Equals(tt.isCrawler)
 	}
 }
+
+func TestRequest_GobEncodeOnlyOrigin(t *testing.T) {
+	RegisterT(t)
+
+	body := `{ "password": "s3cr3t" }`
+	req, _ := http.NewRequest("POST", "https://demo.test.fider.io:3000/api/v1/posts?token=abc", strings.NewReader(body))
+	req.RemoteAddr = "172.10.10.10:5555"
+	wrapped := web.WrapRequest(req)
+	Expect(wrapped.Body).Equals(body)
+
+	var buf bytes.Buffer
+	var origin interface{} = wrapped
+	err := gob.NewEncoder(&buf).Encode(&origin)
+	Expect(err).IsNil()
+	Expect(strings.Contains(buf.String(), "s3cr3t")).IsFalse()
+	Expect(strings.Contains(buf.String(), "token=abc")).IsFalse()
+
+	var decoded interface{}
+	err = gob.NewDecoder(&buf).Decode(&decoded)
+	Expect(err).IsNil()
+
+	rebuilt := decoded.(web.Request)
+	Expect(rebuilt.Body).Equals("")
+	Expect(rebuilt.ClientIP).Equals("172.10.10.10")
+	Expect(rebuilt.BaseURL()).Equals("http://demo.test.fider.io:3000")
+}
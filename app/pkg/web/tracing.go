@@ -0,0 +1,46 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/getfider/fider/app/pkg/trace"
+)
+
+//startRequestSpan starts the span of a request on given route, continuing the trace of its traceparent header, if any
+func startRequestSpan(ctx *Context, method, route string) *trace.Span {
+	if !trace.Enabled() {
+		return nil
+	}
+
+	if parent, ok := trace.ParseTraceParent(ctx.Request.GetHeader(trace.HeaderName)); ok {
+		ctx.Context = trace.WithParent(ctx.Context, parent)
+	}
+
+	var span *trace.Span
+	ctx.Context, span = trace.Start(ctx.Context, method+" "+route, trace.KindServer)
+	span.SetAttribute("http.method", method)
+	span.SetAttribute("http.route", route)
+	span.SetAttribute("http.target", ctx.Request.URL.Path)
+	span.SetAttribute("http.host", ctx.Request.URL.Hostname())
+	span.SetAttribute("fider.context_id", ctx.ContextID())
+
+	//The traceresponse header lets clients find the trace of a slow response
+	ctx.Response.Header().Set("traceresponse", span.SpanContext().TraceParent())
+	return span
+}
+
+//endRequestSpan ends the span of a request, which is failed when the response is a server error
+func endRequestSpan(ctx *Context, span *trace.Span) {
+	code := ctx.ResponseStatusCode
+	if code == 0 {
+		code = http.StatusOK
+	}
+	span.SetAttribute("http.status_code", code)
+
+	var err error
+	if code >= http.StatusInternalServerError {
+		err = fmt.Errorf("%d %s", code, http.StatusText(code))
+	}
+	span.End(err)
+}
@@ -16,10 +16,13 @@ type Context struct {
 	context.Context
 	workerID string
 	taskName string
+	worker   Worker
 }
 
 //NewContext creates a new context
 func NewContext(ctx context.Context, workerID string, task Task) *Context {
+	//Workers create contexts from themselves, which is where follow-up tasks are enqueued
+	w, _ := ctx.(Worker)
 	ctx = log.WithProperty(ctx, log.PropertyKeyContextID, rand.String(32))
 
 	if task.OriginContext != nil {
@@ -38,6 +41,7 @@ func NewContext(ctx context.Context, workerID string, task Task) *Context {
 		Context:  ctx,
 		workerID: workerID,
 		taskName: task.Name,
+		worker:   w,
 	}
 }
 
@@ -51,6 +55,19 @@ func (c *Context) TaskName() string {
 	return c.taskName
 }
 
+//Enqueue given task on the worker running current context, keeping the same tenant, user and request
+func (c *Context) Enqueue(task Task) {
+	if c.worker == nil {
+		log.Warnf(c, "Task '@{TaskName}' was not enqueued as there is no worker on current context", dto.Props{
+			"TaskName": task.Name,
+		})
+		return
+	}
+
+	task.OriginContext = c
+	c.worker.Enqueue(task)
+}
+
 // Set saves data in the context.
 func (c *Context) Set(key interface{}, val interface{}) {
 	c.Context = context.WithValue(c.Context, key, val)
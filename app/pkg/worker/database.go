@@ -0,0 +1,326 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/getfider/fider/app/models/dto"
+	"github.com/getfider/fider/app/pkg/dbx"
+	"github.com/getfider/fider/app/pkg/env"
+	"github.com/getfider/fider/app/pkg/log"
+	"github.com/getfider/fider/app/pkg/rand"
+)
+
+//Job statuses of the jobs table
+const (
+	JobPending = 1
+	JobRunning = 2
+	JobDead    = 3
+)
+
+//DatabaseWorker is a worker that keeps its tasks on the jobs table,
+//so that pending tasks survive restarts and are shared by all running instances
+type DatabaseWorker struct {
+	context.Context
+	instanceID string
+	local      chan Task
+	wake       chan struct{}
+	running    int64
+	stopped    bool
+	middleware MiddlewareFunc
+	sync.RWMutex
+}
+
+type dbJob struct {
+	ID          int64
+	Name        string
+	Args        []byte
+	Origin      []byte
+	Attempts    int
+	MaxAttempts int
+}
+
+//NewDatabaseWorker creates a new DatabaseWorker
+func NewDatabaseWorker() *DatabaseWorker {
+	ctx := context.Background()
+
+	ctx = log.WithProperties(ctx, dto.Props{
+		log.PropertyKeyContextID: rand.String(32),
+		log.PropertyKeyTag:       "BGW",
+	})
+
+	hostname, _ := os.Hostname()
+	return &DatabaseWorker{
+		Context:    ctx,
+		instanceID: hostname + "-" + rand.String(8),
+		local:      make(chan Task, maxQueueSize),
+		wake:       make(chan struct{}, 1),
+		middleware: func(next Job) Job {
+			return next
+		},
+	}
+}
+
+//Run initializes the worker loop
+func (w *DatabaseWorker) Run(workerID string) {
+	log.Infof(w, "Starting database worker @{WorkerID:magenta}.", dto.Props{
+		"WorkerID": workerID,
+	})
+
+	for !w.isStopped() {
+		job, err := w.claim(workerID)
+		if err != nil {
+			log.Error(w, err)
+			w.wait(workerID)
+			continue
+		}
+
+		if job == nil {
+			w.wait(workerID)
+			continue
+		}
+
+		w.process(workerID, job)
+	}
+
+	//Tasks that could not be persisted only exist in memory, so they need to run before stopping
+	for {
+		select {
+		case task := <-w.local:
+			_ = w.execute(workerID, task)
+			w.Lock()
+			w.running = w.running - 1
+			w.Unlock()
+		default:
+			return
+		}
+	}
+}
+
+//Shutdown current worker
+func (w *DatabaseWorker) Shutdown(ctx context.Context) error {
+	w.Lock()
+	w.stopped = true
+	w.Unlock()
+
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		count := w.inFlight()
+		if count == 0 {
+			return nil
+		}
+
+		log.Infof(w, "Waiting for running tasks: @{Count}", dto.Props{
+			"Count": count,
+		})
+
+		select {
+		case <-ctx.Done():
+			return errors.New("timeout waiting for running tasks")
+		case <-ticker.C:
+		}
+	}
+}
+
+//Enqueue a task on current worker
+func (w *DatabaseWorker) Enqueue(task Task) {
+	payload, err := Marshal(task)
+	if err == nil {
+		_, err = dbx.Connection().ExecContext(w, `
+			INSERT INTO jobs (name, args, origin, status, attempts, max_attempts, run_at, created_at)
+			VALUES ($1, $2, $3, $4, 0, $5, $6, $7)
+		`, payload.Name, payload.Args, payload.Origin, JobPending, maxAttempts(task), runAt(task), time.Now())
+	}
+
+	if err != nil {
+		log.Warnf(w, "Task '@{TaskName}' could not be persisted and will run in memory: @{Error}", dto.Props{
+			"TaskName": task.Name,
+			"Error":    err.Error(),
+		})
+		w.Lock()
+		w.running = w.running + 1
+		w.Unlock()
+		w.local <- task
+	}
+
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+}
+
+//Length returns the number of tasks waiting to be processed or being processed
+func (w *DatabaseWorker) Length() int64 {
+	var count int64
+	err := dbx.Connection().QueryRowContext(w,
+		"SELECT COUNT(*) FROM jobs WHERE status IN ($1, $2)", JobPending, JobRunning,
+	).Scan(&count)
+	if err != nil {
+		log.Error(w, err)
+	}
+
+	w.RLock()
+	defer w.RUnlock()
+	return count + int64(len(w.local))
+}
+
+//Use this to inject worker dependencies
+func (w *DatabaseWorker) Use(middleware MiddlewareFunc) {
+	w.middleware = middleware
+}
+
+func (w *DatabaseWorker) isStopped() bool {
+	w.RLock()
+	defer w.RUnlock()
+	return w.stopped
+}
+
+func (w *DatabaseWorker) inFlight() int64 {
+	w.RLock()
+	defer w.RUnlock()
+	return w.running
+}
+
+func (w *DatabaseWorker) wait(workerID string) {
+	select {
+	case <-w.wake:
+	case task := <-w.local:
+		_ = w.execute(workerID, task)
+		w.Lock()
+		w.running = w.running - 1
+		w.Unlock()
+	case <-time.After(env.Config.Worker.PollInterval):
+	}
+}
+
+// claim locks the next available job for this worker.
+// Jobs that are running for longer than the lock timeout are considered abandoned (e.g. instance crashed) and are claimed again.
+func (w *DatabaseWorker) claim(workerID string) (*dbJob, error) {
+	w.Lock()
+	if w.stopped {
+		w.Unlock()
+		return nil, nil
+	}
+	w.running = w.running + 1
+	w.Unlock()
+
+	now := time.Now()
+	job := &dbJob{}
+	err := dbx.Connection().QueryRowContext(w, `
+		UPDATE jobs
+		SET status = $1, attempts = attempts + 1, locked_by = $2, locked_until = $3
+		WHERE id = (
+			SELECT id FROM jobs
+			WHERE (status = $4 AND run_at <= $5) OR (status = $1 AND locked_until < $5)
+			ORDER BY run_at, id
+			FOR UPDATE SKIP LOCKED
+			LIMIT 1
+		)
+		RETURNING id, name, args, origin, attempts, max_attempts
+	`, JobRunning, w.instanceID+"/"+workerID, now.Add(env.Config.Worker.LockTimeout), JobPending, now,
+	).Scan(&job.ID, &job.Name, &job.Args, &job.Origin, &job.Attempts, &job.MaxAttempts)
+
+	if err != nil {
+		w.Lock()
+		w.running = w.running - 1
+		w.Unlock()
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return job, nil
+}
+
+func (w *DatabaseWorker) process(workerID string, job *dbJob) {
+	defer func() {
+		w.Lock()
+		w.running = w.running - 1
+		w.Unlock()
+	}()
+
+	task, err := Unmarshal(&Payload{Name: job.Name, Args: job.Args, Origin: job.Origin})
+	if err != nil {
+		w.bury(job, err)
+		return
+	}
+
+	if err = w.execute(workerID, task); err == nil {
+		_, err = dbx.Connection().ExecContext(w, "DELETE FROM jobs WHERE id = $1", job.ID)
+		if err != nil {
+			log.Error(w, err)
+		}
+		return
+	}
+
+	if job.Attempts >= job.MaxAttempts {
+		w.bury(job, err)
+		return
+	}
+
+	delay := env.Config.Worker.RetryDelay * time.Duration(1<<uint(job.Attempts-1))
+	_, dbErr := dbx.Connection().ExecContext(w, `
+		UPDATE jobs SET status = $1, run_at = $2, last_error = $3, locked_by = NULL, locked_until = NULL
+		WHERE id = $4
+	`, JobPending, time.Now().Add(delay), err.Error(), job.ID)
+	if dbErr != nil {
+		log.Error(w, dbErr)
+	}
+}
+
+func (w *DatabaseWorker) execute(workerID string, task Task) (err error) {
+	start := time.Now()
+	c := NewContext(w, workerID, task)
+	span := startTaskSpan(c, task)
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("task panicked: %v", r)
+		}
+		span.End(err)
+		observeTask(task, start, err)
+	}()
+
+	return w.middleware(task.Job)(c)
+}
+
+//maxAttempts returns how many times given task can run before it's buried
+func maxAttempts(task Task) int {
+	if task.MaxAttempts > 0 {
+		return task.MaxAttempts
+	}
+	return env.Config.Worker.MaxAttempts
+}
+
+//runAt returns when given task should run, which is now unless it's delayed
+func runAt(task Task) time.Time {
+	now := time.Now()
+	if task.RunAt.After(now) {
+		return task.RunAt
+	}
+	return now
+}
+
+// bury moves given job to dead state, where it stays until it's manually inspected
+func (w *DatabaseWorker) bury(job *dbJob, reason error) {
+	log.Errorf(w, "Task '@{TaskName}' (job @{JobID}) failed after @{Attempts} attempts: @{Error}", dto.Props{
+		"TaskName": job.Name,
+		"JobID":    job.ID,
+		"Attempts": job.Attempts,
+		"Error":    reason.Error(),
+	})
+
+	_, err := dbx.Connection().ExecContext(w, `
+		UPDATE jobs SET status = $1, last_error = $2, locked_by = NULL, locked_until = NULL
+		WHERE id = $3
+	`, JobDead, reason.Error(), job.ID)
+	if err != nil {
+		log.Error(w, err)
+	}
+}
@@ -0,0 +1,20 @@
+package worker
+
+import (
+	"time"
+
+	"github.com/getfider/fider/app/pkg/metrics"
+)
+
+var (
+	taskDuration = metrics.NewHistogram("fider_worker_task_duration_seconds", "Duration of background tasks in seconds, by task name.", metrics.DefaultBuckets, "task")
+	taskFailures = metrics.NewCounter("fider_worker_task_failures_total", "Number of background tasks that failed, by task name.", "task")
+)
+
+//observeTask records a finished task along with its failure, if any
+func observeTask(task Task, start time.Time, err error) {
+	taskDuration.ObserveSince(start, task.Name)
+	if err != nil {
+		taskFailures.Inc(task.Name)
+	}
+}
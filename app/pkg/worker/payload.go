@@ -0,0 +1,178 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/models/dto"
+	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/getfider/fider/app/pkg/log"
+	"github.com/getfider/fider/app/pkg/trace"
+)
+
+var (
+	constructors = make(map[string]reflect.Value)
+	taskType     = reflect.TypeOf(Task{})
+	registryLock sync.RWMutex
+)
+
+//Register makes a task constructor available to durable workers under given task name.
+//The constructor must be a function that returns a Task built with the same name
+func Register(name string, constructor interface{}) {
+	fn := reflect.ValueOf(constructor)
+	if fn.Kind() != reflect.Func || fn.Type().NumOut() != 1 || fn.Type().Out(0) != taskType {
+		panic(fmt.Sprintf("task constructor for '%s' must be a function that returns a worker.Task", name))
+	}
+
+	registryLock.Lock()
+	defer registryLock.Unlock()
+	constructors[name] = fn
+}
+
+//IsRegistered returns true if given task can be persisted and rebuilt by durable workers
+func IsRegistered(task Task) bool {
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+	fn, ok := constructors[task.Name]
+	return ok && fn.Type().NumIn() == len(task.Args)
+}
+
+//Payload is the serialized form of a Task
+type Payload struct {
+	Name   string
+	Args   []byte
+	Origin []byte
+}
+
+type taskOrigin struct {
+	Request  interface{}
+	Tenant   *models.Tenant
+	User     *models.User
+	LogProps dto.Props
+	//TraceParent is the span that enqueued the task, formatted as a W3C traceparent header
+	TraceParent string
+}
+
+//Marshal converts given task into a Payload that can be stored and rebuilt later
+func Marshal(task Task) (*Payload, error) {
+	if !IsRegistered(task) {
+		return nil, fmt.Errorf("task '%s' is not registered", task.Name)
+	}
+
+	args := make([][]byte, len(task.Args))
+	for i, arg := range task.Args {
+		if arg == nil || (reflect.ValueOf(arg).Kind() == reflect.Ptr && reflect.ValueOf(arg).IsNil()) {
+			continue
+		}
+
+		encoded, err := gobEncode(arg)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to encode argument %d of task '%s'", i, task.Name)
+		}
+		args[i] = encoded
+	}
+
+	encodedArgs, err := gobEncode(args)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode arguments of task '%s'", task.Name)
+	}
+
+	origin := taskOrigin{LogProps: dto.Props{}}
+	if task.OriginContext != nil {
+		origin.Request = task.OriginContext.Value(app.RequestCtxKey)
+		origin.Tenant, _ = task.OriginContext.Value(app.TenantCtxKey).(*models.Tenant)
+		origin.User, _ = task.OriginContext.Value(app.UserCtxKey).(*models.User)
+		for _, key := range []string{log.PropertyKeySessionID, log.PropertyKeyUserID, log.PropertyKeyTenantID} {
+			if value := log.GetProperty(task.OriginContext, key); value != nil {
+				origin.LogProps[key] = value
+			}
+		}
+		if span := trace.FromContext(task.OriginContext); span.IsValid() {
+			origin.TraceParent = span.TraceParent()
+		}
+	}
+
+	encodedOrigin, err := gobEncode(origin)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode origin of task '%s'", task.Name)
+	}
+
+	return &Payload{
+		Name:   task.Name,
+		Args:   encodedArgs,
+		Origin: encodedOrigin,
+	}, nil
+}
+
+//Unmarshal rebuilds a Task from given Payload using its registered constructor
+func Unmarshal(payload *Payload) (Task, error) {
+	registryLock.RLock()
+	fn, ok := constructors[payload.Name]
+	registryLock.RUnlock()
+	if !ok {
+		return Task{}, fmt.Errorf("task '%s' is not registered", payload.Name)
+	}
+
+	var args [][]byte
+	if err := gobDecode(payload.Args, &args); err != nil {
+		return Task{}, errors.Wrap(err, "failed to decode arguments of task '%s'", payload.Name)
+	}
+
+	fnType := fn.Type()
+	if fnType.NumIn() != len(args) {
+		return Task{}, fmt.Errorf("task '%s' expects %d arguments, got %d", payload.Name, fnType.NumIn(), len(args))
+	}
+
+	in := make([]reflect.Value, len(args))
+	for i, arg := range args {
+		value := reflect.New(fnType.In(i))
+		if len(arg) > 0 {
+			if err := gobDecode(arg, value.Interface()); err != nil {
+				return Task{}, errors.Wrap(err, "failed to decode argument %d of task '%s'", i, payload.Name)
+			}
+		}
+		in[i] = value.Elem()
+	}
+
+	var origin taskOrigin
+	if err := gobDecode(payload.Origin, &origin); err != nil {
+		return Task{}, errors.Wrap(err, "failed to decode origin of task '%s'", payload.Name)
+	}
+
+	ctx := context.Background()
+	if origin.Request != nil {
+		ctx = context.WithValue(ctx, app.RequestCtxKey, origin.Request)
+	}
+	if origin.Tenant != nil {
+		ctx = context.WithValue(ctx, app.TenantCtxKey, origin.Tenant)
+	}
+	if origin.User != nil {
+		ctx = context.WithValue(ctx, app.UserCtxKey, origin.User)
+	}
+	ctx = log.WithProperties(ctx, origin.LogProps)
+	if span, ok := trace.ParseTraceParent(origin.TraceParent); ok {
+		ctx = trace.WithParent(ctx, span)
+	}
+
+	task := fn.Call(in)[0].Interface().(Task)
+	task.OriginContext = ctx
+	return task, nil
+}
+
+func gobEncode(value interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(value); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func gobDecode(data []byte, target interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(target)
+}
@@ -0,0 +1,23 @@
+package worker
+
+import (
+	"github.com/getfider/fider/app/pkg/trace"
+)
+
+//startTaskSpan starts the span of a task on a new trace, linked to the span that enqueued it as tasks usually outlive their origin
+func startTaskSpan(c *Context, task Task) *trace.Span {
+	if !trace.Enabled() {
+		return nil
+	}
+
+	var origin trace.SpanContext
+	if task.OriginContext != nil {
+		origin = trace.FromContext(task.OriginContext)
+	}
+
+	var span *trace.Span
+	c.Context, span = trace.Start(trace.WithParent(c.Context, trace.SpanContext{}), "task "+task.Name, trace.KindConsumer, origin)
+	span.SetAttribute("worker.id", c.WorkerID())
+	span.SetAttribute("worker.task", task.Name)
+	return span
+}
@@ -22,6 +22,12 @@ type Task struct {
 	OriginContext context.Context
 	Name          string
 	Job           Job
+	//Args used to build this task, required to persist it on durable workers
+	Args []interface{}
+	//RunAt delays the task until given time, it runs as soon as possible when it's zero
+	RunAt time.Time
+	//MaxAttempts limits how many times durable workers run the task when it fails, WORKER_MAX_ATTEMPTS is used when it's zero
+	MaxAttempts int
 }
 
 //Worker is a process that runs tasks
@@ -69,8 +75,12 @@ func (w *BackgroundWorker) Run(workerID string) {
 	})
 	for task := range w.queue {
 		c := NewContext(w, workerID, task)
+		span := startTaskSpan(c, task)
 
-		_ = w.middleware(task.Job)(c)
+		start := time.Now()
+		err := w.middleware(task.Job)(c)
+		span.End(err)
+		observeTask(task, start, err)
 		w.Lock()
 		w.len = w.len - 1
 		w.Unlock()
@@ -104,6 +114,13 @@ func (w *BackgroundWorker) Shutdown(ctx context.Context) error {
 
 //Enqueue a task on current worker
 func (w *BackgroundWorker) Enqueue(task Task) {
+	if delay := time.Until(task.RunAt); delay > 0 {
+		time.AfterFunc(delay, func() {
+			w.Enqueue(task)
+		})
+		return
+	}
+
 	w.Lock()
 	w.len = w.len + 1
 	w.Unlock()
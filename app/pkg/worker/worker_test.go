@@ -1,11 +1,18 @@
 package worker_test
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/pkg/metrics"
+	"github.com/getfider/fider/app/pkg/trace"
 	"github.com/getfider/fider/app/pkg/worker"
 
 	. "github.com/getfider/fider/app/pkg/assert"
@@ -44,6 +51,40 @@ func TestBackgroundWorker(t *testing.T) {
 	}).EventuallyEquals(true)
 }
 
+func TestBackgroundWorker_DelayedFollowUpTask(t *testing.T) {
+	RegisterT(t)
+
+	var finishedAt time.Time
+	mu := &sync.RWMutex{}
+
+	start := time.Now()
+	w := worker.New()
+	w.Enqueue(worker.Task{
+		Name: "Do Something",
+		Job: func(c *worker.Context) error {
+			c.Enqueue(worker.Task{
+				Name:  "Do Something Later",
+				RunAt: time.Now().Add(200 * time.Millisecond),
+				Job: func(c *worker.Context) error {
+					mu.Lock()
+					defer mu.Unlock()
+					finishedAt = time.Now()
+					return nil
+				},
+			})
+			return nil
+		},
+	})
+
+	go w.Run("worker-1")
+	Expect(func() bool {
+		mu.RLock()
+		defer mu.RUnlock()
+		return !finishedAt.IsZero()
+	}).EventuallyEquals(true)
+	Expect(finishedAt.Sub(start) >= 200*time.Millisecond).IsTrue()
+}
+
 func TestBackgroundWorker_ShutdownWhenEmpty(t *testing.T) {
 	RegisterT(t)
 
@@ -76,3 +117,157 @@ func TestBackgroundWorker_ShutdownWithRunningTasks(t *testing.T) {
 	go w.Run("worker-1")
 	Expect(w.Shutdown(ctx)).IsNil()
 }
+
+type greeting struct {
+	Name string
+}
+
+func greet(message string, to *greeting, times int) worker.Task {
+	return worker.Task{
+		Name: "Greet",
+		Job: func(c *worker.Context) error {
+			return nil
+		},
+		Args: []interface{}{message, to, times},
+	}
+}
+
+func TestPayload_MarshalUnmarshal(t *testing.T) {
+	RegisterT(t)
+	worker.Register("Greet", greet)
+
+	tenant := &models.Tenant{ID: 2, Name: "Demonstration"}
+	user := &models.User{ID: 5, Name: "Jon Snow"}
+	ctx := context.WithValue(context.Background(), app.TenantCtxKey, tenant)
+	ctx = context.WithValue(ctx, app.UserCtxKey, user)
+
+	task := greet("Hello", &greeting{Name: "World"}, 3)
+	task.OriginContext = ctx
+
+	payload, err := worker.Marshal(task)
+	Expect(err).IsNil()
+	Expect(payload.Name).Equals("Greet")
+
+	rebuilt, err := worker.Unmarshal(payload)
+	Expect(err).IsNil()
+	Expect(rebuilt.Name).Equals("Greet")
+	Expect(rebuilt.Args).HasLen(3)
+	Expect(rebuilt.Args[0]).Equals("Hello")
+	Expect(rebuilt.Args[1]).Equals(&greeting{Name: "World"})
+	Expect(rebuilt.Args[2]).Equals(3)
+	Expect(rebuilt.OriginContext.Value(app.TenantCtxKey)).Equals(tenant)
+	Expect(rebuilt.OriginContext.Value(app.UserCtxKey)).Equals(user)
+}
+
+func TestPayload_NilArguments(t *testing.T) {
+	RegisterT(t)
+	worker.Register("Greet", greet)
+
+	payload, err := worker.Marshal(greet("Hello", nil, 1))
+	Expect(err).IsNil()
+
+	rebuilt, err := worker.Unmarshal(payload)
+	Expect(err).IsNil()
+	Expect(rebuilt.Args[1]).Equals((*greeting)(nil))
+}
+
+func TestPayload_UnregisteredTask(t *testing.T) {
+	RegisterT(t)
+
+	payload, err := worker.Marshal(dummyTask)
+	Expect(err).IsNotNil()
+	Expect(payload).IsNil()
+
+	_, err = worker.Unmarshal(&worker.Payload{Name: "Unknown Task"})
+	Expect(err).IsNotNil()
+}
+
+func TestBackgroundWorker_TaskMetrics(t *testing.T) {
+	RegisterT(t)
+
+	w := worker.New()
+	w.Enqueue(worker.Task{
+		Name: "Fail Something",
+		Job: func(ctx *worker.Context) error {
+			return errors.New("something went wrong")
+		},
+	})
+
+	go w.Run("worker-1")
+	Expect(func() bool {
+		buffer := new(bytes.Buffer)
+		_ = metrics.WriteText(buffer)
+		return strings.Contains(buffer.String(), `fider_worker_task_failures_total{task="Fail Something"} 1`)
+	}).EventuallyEquals(true)
+
+	buffer := new(bytes.Buffer)
+	err := metrics.WriteText(buffer)
+	Expect(err).IsNil()
+	Expect(buffer.String()).ContainsSubstring(`fider_worker_task_duration_seconds_count{task="Fail Something"} 1`)
+}
+
+func TestPayload_TraceParent(t *testing.T) {
+	RegisterT(t)
+	worker.Register("Greet", greet)
+
+	origin, _ := trace.ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	task := greet("Hello", nil, 1)
+	task.OriginContext = trace.WithParent(context.Background(), origin)
+
+	payload, err := worker.Marshal(task)
+	Expect(err).IsNil()
+
+	rebuilt, err := worker.Unmarshal(payload)
+	Expect(err).IsNil()
+	Expect(trace.FromContext(rebuilt.OriginContext)).Equals(origin)
+}
+
+type spanRecorder struct {
+	lock  sync.Mutex
+	spans []*trace.Span
+}
+
+func (r *spanRecorder) Export(spans []*trace.Span) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.spans = append(r.spans, spans...)
+	return nil
+}
+
+func TestBackgroundWorker_TaskSpan(t *testing.T) {
+	RegisterT(t)
+	exporter := &spanRecorder{}
+	trace.Use(exporter)
+
+	origin, _ := trace.ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	var current trace.SpanContext
+
+	w := worker.New()
+	w.Enqueue(worker.Task{
+		OriginContext: trace.WithParent(context.Background(), origin),
+		Name:          "Trace Something",
+		Job: func(c *worker.Context) error {
+			current = trace.FromContext(c)
+			return errors.New("something went wrong")
+		},
+	})
+
+	go w.Run("worker-1")
+	Expect(func() int64 {
+		return w.Length()
+	}).EventuallyEquals(int64(0))
+
+	err := trace.Shutdown(context.Background())
+	Expect(err).IsNil()
+	Expect(exporter.spans).HasLen(1)
+
+	span := exporter.spans[0]
+	Expect(span.Name).Equals("task Trace Something")
+	Expect(span.Kind).Equals(trace.KindConsumer)
+	Expect(span.Context).Equals(current)
+	Expect(span.Context.TraceID == origin.TraceID).IsFalse()
+	Expect(span.ParentID.IsValid()).IsFalse()
+	Expect(span.Links).Equals([]trace.SpanContext{origin})
+	Expect(span.Failed).IsTrue()
+	Expect(span.Attributes["worker.id"]).Equals("worker-1")
+}
@@ -0,0 +1,23 @@
+package antivirus
+
+import (
+	"context"
+
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/dto"
+	"github.com/getfider/fider/app/pkg/log"
+)
+
+// EICAR is the standard antivirus test file, which all scanners report as infected
+// https://www.eicar.org/?page_id=3950
+const EICAR = `X5O!P%@AP[4\PZX54(P^)7CC)7}$EICAR-STANDARD-ANTIVIRUS-TEST-FILE!$H+H*`
+
+// Infected flags given file as infected by given threat
+func Infected(ctx context.Context, c *cmd.ScanFile, threat string) {
+	c.Infected = true
+	c.Threat = threat
+	log.Warnf(ctx, "File '@{FileName}' was rejected by the virus scanner: @{Threat}", dto.Props{
+		"FileName": c.FileName,
+		"Threat":   threat,
+	})
+}
@@ -0,0 +1,95 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/env"
+	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/getfider/fider/app/services/antivirus"
+)
+
+func init() {
+	bus.Register(Service{})
+}
+
+type Service struct{}
+
+func (s Service) Name() string {
+	return "Command"
+}
+
+func (s Service) Category() string {
+	return "antivirus"
+}
+
+func (s Service) Enabled() bool {
+	return env.AntivirusProvider() == "command"
+}
+
+func (s Service) Init() {
+	bus.AddHandler(scanFile)
+}
+
+//scanFile writes the file to a temporary location and runs the configured command with its path as the last argument
+//Exit codes follow the convention of clamscan/clamdscan: 0 means clean, 1 means infected and anything else is an error
+func scanFile(ctx context.Context, c *cmd.ScanFile) error {
+	args := strings.Fields(env.Config.Antivirus.Command)
+	if len(args) == 0 {
+		return errors.New("ANTIVIRUS_COMMAND is empty")
+	}
+
+	tmp, err := ioutil.TempFile("", "fider-scan-")
+	if err != nil {
+		return errors.Wrap(err, "failed to create temporary file to scan")
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(c.Content)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to write temporary file to scan")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, env.Config.Antivirus.Timeout)
+	defer cancel()
+
+	var output bytes.Buffer
+	command := exec.CommandContext(ctx, args[0], append(args[1:], tmp.Name())...)
+	command.Stdout = &output
+	command.Stderr = &output
+
+	err = command.Run()
+	if err == nil {
+		return nil
+	}
+
+	if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 && ctx.Err() == nil {
+		antivirus.Infected(ctx, c, threat(output.String(), tmp.Name()))
+		return nil
+	}
+
+	return errors.Wrap(err, "failed to scan file '%s': %s", c.FileName, strings.TrimSpace(output.String()))
+}
+
+//threat returns the name of the threat from the output of the scanner, like "/tmp/file: Eicar-Signature FOUND"
+func threat(output, path string) string {
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, path+":") {
+			return strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(line, path+":"), "FOUND"))
+		}
+	}
+	if output = strings.TrimSpace(output); output != "" {
+		return output
+	}
+	return "unknown"
+}
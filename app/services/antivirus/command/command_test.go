@@ -0,0 +1,51 @@
+package command_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/getfider/fider/app/models/cmd"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/env"
+	"github.com/getfider/fider/app/services/antivirus"
+	"github.com/getfider/fider/app/services/antivirus/command"
+)
+
+func reset() {
+	env.Config.Antivirus.Command = "sh " + env.Path("app/services/antivirus/command/testdata/scan.sh")
+	bus.Init(command.Service{})
+}
+
+func TestScanFile_Clean(t *testing.T) {
+	RegisterT(t)
+	reset()
+
+	scan := &cmd.ScanFile{FileName: "server.log", Content: []byte("Hello World")}
+	err := bus.Dispatch(context.Background(), scan)
+	Expect(err).IsNil()
+	Expect(scan.Infected).IsFalse()
+	Expect(scan.Threat).Equals("")
+}
+
+func TestScanFile_Infected(t *testing.T) {
+	RegisterT(t)
+	reset()
+
+	scan := &cmd.ScanFile{FileName: "eicar.txt", Content: []byte(antivirus.EICAR)}
+	err := bus.Dispatch(context.Background(), scan)
+	Expect(err).IsNil()
+	Expect(scan.Infected).IsTrue()
+	Expect(scan.Threat).Equals("Eicar-Signature")
+}
+
+func TestScanFile_Error(t *testing.T) {
+	RegisterT(t)
+	reset()
+
+	scan := &cmd.ScanFile{FileName: "broken.txt", Content: []byte("BROKEN")}
+	err := bus.Dispatch(context.Background(), scan)
+	Expect(err).IsNotNil()
+	Expect(err.Error()).ContainsSubstring("Can't access file")
+	Expect(scan.Infected).IsFalse()
+}
@@ -0,0 +1,12 @@
+#!/bin/sh
+# Mimics clamscan: exit code 0 when clean, 1 when infected and 2 on errors
+if grep -q "BROKEN" "$1"; then
+  echo "ERROR: Can't access file $1"
+  exit 2
+fi
+if grep -q "EICAR-STANDARD-ANTIVIRUS-TEST-FILE" "$1"; then
+  echo "$1: Eicar-Signature FOUND"
+  exit 1
+fi
+echo "$1: OK"
+exit 0
@@ -0,0 +1,129 @@
+package icap
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"net/textproto"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/env"
+	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/getfider/fider/app/services/antivirus"
+)
+
+func init() {
+	bus.Register(Service{})
+}
+
+type Service struct{}
+
+func (s Service) Name() string {
+	return "ICAP"
+}
+
+func (s Service) Category() string {
+	return "antivirus"
+}
+
+func (s Service) Enabled() bool {
+	return env.AntivirusProvider() == "icap"
+}
+
+func (s Service) Init() {
+	bus.AddHandler(scanFile)
+}
+
+//scanFile sends the file to an ICAP server (like c-icap with ClamAV) as the body of an HTTP response
+//The server answers with 204 when the file is clean or with 200 and a replaced response when it's infected
+//https://tools.ietf.org/html/rfc3507
+func scanFile(ctx context.Context, c *cmd.ScanFile) error {
+	u, err := url.Parse(env.Config.Antivirus.ICAPURL)
+	if err != nil || u.Scheme != "icap" || u.Hostname() == "" {
+		return errors.New("invalid ICAP URL '%s'", env.Config.Antivirus.ICAPURL)
+	}
+
+	host := u.Host
+	if u.Port() == "" {
+		host = net.JoinHostPort(u.Hostname(), "1344")
+	}
+
+	timeout := env.Config.Antivirus.Timeout
+	conn, err := net.DialTimeout("tcp", host, timeout)
+	if err != nil {
+		return errors.Wrap(err, "failed to connect to ICAP server")
+	}
+	defer conn.Close()
+
+	if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return errors.Wrap(err, "failed to set ICAP connection deadline")
+	}
+
+	httpHeader := fmt.Sprintf("HTTP/1.1 200 OK\r\nContent-Type: application/octet-stream\r\nContent-Length: %d\r\n\r\n", len(c.Content))
+
+	w := bufio.NewWriter(conn)
+	fmt.Fprintf(w, "RESPMOD %s ICAP/1.0\r\n", u.String())
+	fmt.Fprintf(w, "Host: %s\r\n", u.Host)
+	fmt.Fprintf(w, "Allow: 204\r\n")
+	fmt.Fprintf(w, "Encapsulated: res-hdr=0, res-body=%d\r\n", len(httpHeader))
+	fmt.Fprintf(w, "Connection: close\r\n\r\n")
+	w.WriteString(httpHeader)
+	if len(c.Content) > 0 {
+		fmt.Fprintf(w, "%x\r\n", len(c.Content))
+		w.Write(c.Content)
+		w.WriteString("\r\n")
+	}
+	w.WriteString("0\r\n\r\n")
+	if err := w.Flush(); err != nil {
+		return errors.Wrap(err, "failed to send file to ICAP server")
+	}
+
+	r := textproto.NewReader(bufio.NewReader(conn))
+	statusLine, err := r.ReadLine()
+	if err != nil {
+		return errors.Wrap(err, "failed to read ICAP response")
+	}
+	header, err := r.ReadMIMEHeader()
+	if err != nil {
+		return errors.Wrap(err, "failed to read ICAP response headers")
+	}
+
+	status := strings.SplitN(statusLine, " ", 3)
+	if len(status) < 2 || !strings.HasPrefix(status[0], "ICAP/") {
+		return errors.New("invalid ICAP response '%s'", statusLine)
+	}
+
+	code, _ := strconv.Atoi(status[1])
+	switch code {
+	case 204:
+		return nil
+	case 200:
+		antivirus.Infected(ctx, c, threat(header))
+		return nil
+	default:
+		return errors.New("ICAP server failed to scan file '%s': %s", c.FileName, statusLine)
+	}
+}
+
+//threat returns the name of the threat from the headers used by the most common ICAP servers
+func threat(header textproto.MIMEHeader) string {
+	if infection := header.Get("X-Infection-Found"); infection != "" {
+		for _, part := range strings.Split(infection, ";") {
+			part = strings.TrimSpace(part)
+			if strings.HasPrefix(part, "Threat=") {
+				return strings.TrimPrefix(part, "Threat=")
+			}
+		}
+		return infection
+	}
+	if virus := header.Get("X-Virus-ID"); virus != "" {
+		return virus
+	}
+	return "unknown"
+}
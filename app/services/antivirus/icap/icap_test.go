@@ -0,0 +1,105 @@
+package icap_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/getfider/fider/app/models/cmd"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/env"
+	"github.com/getfider/fider/app/services/antivirus"
+	"github.com/getfider/fider/app/services/antivirus/icap"
+)
+
+//mockICAP starts an ICAP server that handles a single request with given response
+func mockICAP(response string) (chan string, func()) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	Expect(err).IsNil()
+	env.Config.Antivirus.ICAPURL = fmt.Sprintf("icap://%s/avscan", listener.Addr().String())
+
+	requests := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		var request bytes.Buffer
+		reader := bufio.NewReader(conn)
+		for !strings.HasSuffix(request.String(), "\r\n0\r\n\r\n") {
+			line, err := reader.ReadString('\n')
+			request.WriteString(line)
+			if err != nil {
+				break
+			}
+		}
+		requests <- request.String()
+		conn.Write([]byte(response))
+	}()
+
+	return requests, func() { listener.Close() }
+}
+
+func TestScanFile_Clean(t *testing.T) {
+	RegisterT(t)
+	bus.Init(icap.Service{})
+
+	requests, stop := mockICAP("ICAP/1.0 204 No Content\r\nISTag: \"test\"\r\n\r\n")
+	defer stop()
+
+	scan := &cmd.ScanFile{FileName: "server.log", Content: []byte("Hello World")}
+	err := bus.Dispatch(context.Background(), scan)
+	Expect(err).IsNil()
+	Expect(scan.Infected).IsFalse()
+
+	request := <-requests
+	Expect(request).ContainsSubstring("RESPMOD icap://127.0.0.1:")
+	Expect(request).ContainsSubstring("/avscan ICAP/1.0\r\n")
+	Expect(request).ContainsSubstring("Allow: 204\r\n")
+	Expect(request).ContainsSubstring("Encapsulated: res-hdr=0, res-body=79\r\n")
+	Expect(request).ContainsSubstring("Content-Length: 11\r\n\r\nb\r\nHello World\r\n0\r\n\r\n")
+}
+
+func TestScanFile_Infected(t *testing.T) {
+	RegisterT(t)
+	bus.Init(icap.Service{})
+
+	_, stop := mockICAP("ICAP/1.0 200 OK\r\nX-Infection-Found: Type=0; Resolution=2; Threat=Eicar-Test-Signature;\r\nEncapsulated: null-body=0\r\n\r\n")
+	defer stop()
+
+	scan := &cmd.ScanFile{FileName: "eicar.txt", Content: []byte(antivirus.EICAR)}
+	err := bus.Dispatch(context.Background(), scan)
+	Expect(err).IsNil()
+	Expect(scan.Infected).IsTrue()
+	Expect(scan.Threat).Equals("Eicar-Test-Signature")
+}
+
+func TestScanFile_ServerError(t *testing.T) {
+	RegisterT(t)
+	bus.Init(icap.Service{})
+
+	_, stop := mockICAP("ICAP/1.0 500 Server Error\r\n\r\n")
+	defer stop()
+
+	scan := &cmd.ScanFile{FileName: "server.log", Content: []byte("Hello World")}
+	err := bus.Dispatch(context.Background(), scan)
+	Expect(err).IsNotNil()
+	Expect(scan.Infected).IsFalse()
+}
+
+func TestScanFile_InvalidURL(t *testing.T) {
+	RegisterT(t)
+	bus.Init(icap.Service{})
+
+	env.Config.Antivirus.ICAPURL = "http://localhost/avscan"
+	scan := &cmd.ScanFile{FileName: "server.log", Content: []byte("Hello World")}
+	err := bus.Dispatch(context.Background(), scan)
+	Expect(err).IsNotNil()
+}
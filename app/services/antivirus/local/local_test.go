@@ -0,0 +1,28 @@
+package local_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/getfider/fider/app/models/cmd"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/services/antivirus"
+	"github.com/getfider/fider/app/services/antivirus/local"
+)
+
+func TestScanFile(t *testing.T) {
+	RegisterT(t)
+	bus.Init(local.Service{})
+
+	scan := &cmd.ScanFile{FileName: "report.csv", Content: []byte("id,name\n1,Jon Snow")}
+	err := bus.Dispatch(context.Background(), scan)
+	Expect(err).IsNil()
+	Expect(scan.Infected).IsFalse()
+
+	scan = &cmd.ScanFile{FileName: "eicar.com", Content: []byte(antivirus.EICAR)}
+	err = bus.Dispatch(context.Background(), scan)
+	Expect(err).IsNil()
+	Expect(scan.Infected).IsTrue()
+	Expect(scan.Threat).Equals("Eicar-Test-Signature")
+}
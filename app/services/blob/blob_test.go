@@ -70,6 +70,7 @@ var tests = []struct {
 	{"SameKey_DifferentTenant", SameKey_DifferentTenant},
 	{"SameKey_DifferentTenant_Delete", SameKey_DifferentTenant_Delete},
 	{"ListBlobsFromTenant", ListBlobsFromTenant},
+	{"ListBlobsMetadataFromTenant", ListBlobsMetadataFromTenant},
 }
 
 func TestBlobStorage(t *testing.T) {
@@ -261,6 +262,38 @@ func ListBlobsFromTenant(ctx context.Context) {
 	Expect(tenant2Files.Result).Equals([]string{"texts/hello.txt"})
 }
 
+func ListBlobsMetadataFromTenant(ctx context.Context) {
+	ctxWithTenant1 := context.WithValue(ctx, app.TenantCtxKey, tenant1)
+	ctxWithTenant2 := context.WithValue(ctx, app.TenantCtxKey, tenant2)
+
+	emptyList := &query.ListBlobsMetadata{}
+	err := bus.Dispatch(ctxWithTenant1, emptyList)
+	Expect(err).IsNil()
+	Expect(emptyList.Result).HasLen(0)
+
+	err = bus.Dispatch(ctxWithTenant1, &cmd.StoreBlob{
+		Key:         "texts/hello.txt",
+		Content:     []byte("Hello World"),
+		ContentType: "text/plain; charset=utf-8",
+	})
+	Expect(err).IsNil()
+
+	err = bus.Dispatch(ctxWithTenant2, &cmd.StoreBlob{
+		Key:         "texts/other.txt",
+		Content:     make([]byte, 0),
+		ContentType: "text/plain; charset=utf-8",
+	})
+	Expect(err).IsNil()
+
+	tenant1Files := &query.ListBlobsMetadata{}
+	err = bus.Dispatch(ctxWithTenant1, tenant1Files)
+	Expect(err).IsNil()
+	Expect(tenant1Files.Result).HasLen(1)
+	Expect(tenant1Files.Result[0].Key).Equals("texts/hello.txt")
+	Expect(tenant1Files.Result[0].Size).Equals(int64(11))
+	Expect(tenant1Files.Result[0].ModifiedAt.IsZero()).IsFalse()
+}
+
 func KeyFormats(ctx context.Context) {
 	testCases := []struct {
 		key   string
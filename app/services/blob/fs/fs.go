@@ -45,6 +45,7 @@ func (s Service) Enabled() bool {
 
 func (s Service) Init() {
 	bus.AddHandler(listBlobs)
+	bus.AddHandler(listBlobsMetadata)
 	bus.AddHandler(getBlobByKey)
 	bus.AddHandler(storeBlob)
 	bus.AddHandler(deleteBlob)
@@ -73,6 +74,38 @@ func listBlobs(ctx context.Context, q *query.ListBlobs) error {
 	return nil
 }
 
+func listBlobsMetadata(ctx context.Context, q *query.ListBlobsMetadata) error {
+	basePath := basePath(ctx)
+	blobs := make([]*dto.BlobMetadata, 0)
+
+	err := filepath.Walk(basePath,
+		func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				if path == basePath && os.IsNotExist(err) {
+					return filepath.SkipDir
+				}
+				return err
+			}
+			if !info.IsDir() {
+				blobs = append(blobs, &dto.BlobMetadata{
+					Key:        path[len(basePath)+1:],
+					Size:       info.Size(),
+					ModifiedAt: info.ModTime(),
+				})
+			}
+			return nil
+		})
+	if err != nil {
+		return errors.Wrap(err, "failed to read dir '%s'", basePath)
+	}
+
+	sort.Slice(blobs, func(i, j int) bool {
+		return blobs[i].Key < blobs[j].Key
+	})
+	q.Result = blobs
+	return nil
+}
+
 func getBlobByKey(ctx context.Context, q *query.GetBlobByKey) error {
 	fullPath := keyFullPath(ctx, q.Key)
 	stats, err := os.Stat(fullPath)
@@ -68,6 +68,7 @@ func (s Service) Init() {
 	}
 
 	bus.AddHandler(listBlobs)
+	bus.AddHandler(listBlobsMetadata)
 	bus.AddHandler(getBlobByKey)
 	bus.AddHandler(storeBlob)
 	bus.AddHandler(deleteBlob)
@@ -96,6 +97,35 @@ func listBlobs(ctx context.Context, q *query.ListBlobs) error {
 	return nil
 }
 
+//listBlobsMetadata goes through all pages of objects, as a tenant might have more blobs than a single page can hold
+func listBlobsMetadata(ctx context.Context, q *query.ListBlobsMetadata) error {
+	tenant := ctx.Value(app.TenantCtxKey).(*models.Tenant)
+	basePath := fmt.Sprintf("tenants/%d/", tenant.ID)
+	blobs := make([]*dto.BlobMetadata, 0)
+	err := DefaultClient.ListObjectsPagesWithContext(ctx, &s3.ListObjectsInput{
+		Bucket: aws.String(env.Config.BlobStorage.S3.BucketName),
+		Prefix: aws.String(basePath),
+	}, func(page *s3.ListObjectsOutput, lastPage bool) bool {
+		for _, item := range page.Contents {
+			blobs = append(blobs, &dto.BlobMetadata{
+				Key:        (*item.Key)[len(basePath):],
+				Size:       aws.Int64Value(item.Size),
+				ModifiedAt: aws.TimeValue(item.LastModified),
+			})
+		}
+		return true
+	})
+	if err != nil {
+		return wrap(err, "failed to list blobs metadata from S3")
+	}
+
+	sort.Slice(blobs, func(i, j int) bool {
+		return blobs[i].Key < blobs[j].Key
+	})
+	q.Result = blobs
+	return nil
+}
+
 func getBlobByKey(ctx context.Context, q *query.GetBlobByKey) error {
 	resp, err := DefaultClient.GetObjectWithContext(ctx, &s3.GetObjectInput{
 		Bucket: aws.String(env.Config.BlobStorage.S3.BucketName),
@@ -41,16 +41,18 @@ func (s Service) Enabled() bool {
 
 func (s Service) Init() {
 	bus.AddHandler(listBlobs)
+	bus.AddHandler(listBlobsMetadata)
 	bus.AddHandler(getBlobByKey)
 	bus.AddHandler(storeBlob)
 	bus.AddHandler(deleteBlob)
 }
 
 type dbBlob struct {
-	Key         string `db:"key"`
-	ContentType string `db:"content_type"`
-	Size        int64  `db:"size"`
-	Content     []byte `db:"file"`
+	Key         string    `db:"key"`
+	ContentType string    `db:"content_type"`
+	Size        int64     `db:"size"`
+	Content     []byte    `db:"file"`
+	ModifiedAt  time.Time `db:"modified_at"`
 }
 
 func listBlobs(ctx context.Context, q *query.ListBlobs) error {
@@ -78,6 +80,32 @@ func listBlobs(ctx context.Context, q *query.ListBlobs) error {
 	})
 }
 
+func listBlobsMetadata(ctx context.Context, q *query.ListBlobsMetadata) error {
+	return using(ctx, func(tenant *models.Tenant) error {
+		trx, err := dbx.BeginTx(ctx)
+		if err != nil {
+			return errors.Wrap(err, "failed to open transaction")
+		}
+		defer trx.MustCommit()
+
+		blobs := []*dbBlob{}
+		err = trx.Select(&blobs, "SELECT key, size, modified_at FROM blobs WHERE tenant_id = $1 ORDER BY key", tenant.ID)
+		if err != nil {
+			return errors.Wrap(err, "failed list blobs metadata")
+		}
+
+		q.Result = make([]*dto.BlobMetadata, len(blobs))
+		for i, b := range blobs {
+			q.Result[i] = &dto.BlobMetadata{
+				Key:        b.Key,
+				Size:       b.Size,
+				ModifiedAt: b.ModifiedAt,
+			}
+		}
+		return nil
+	})
+}
+
 func getBlobByKey(ctx context.Context, q *query.GetBlobByKey) error {
 	return using(ctx, func(tenant *models.Tenant) error {
 		var tenantID sql.NullInt64
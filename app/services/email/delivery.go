@@ -0,0 +1,65 @@
+package email
+
+import (
+	"context"
+	"strings"
+
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/query"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/getfider/fider/app/pkg/log"
+	"github.com/getfider/fider/app/pkg/metrics"
+)
+
+var (
+	emailsSent   = metrics.NewCounter("fider_emails_sent_total", "Number of emails sent, by provider.", "provider")
+	emailsFailed = metrics.NewCounter("fider_emails_failed_total", "Number of emails that failed to be sent, by provider.", "provider")
+)
+
+// CanDeliverTo returns true if Fider is allowed to send email to given address
+// and the address is not on the suppression list of current tenant because of a previous bounce or spam complaint
+func CanDeliverTo(ctx context.Context, address string) bool {
+	if !CanSendTo(address) {
+		return false
+	}
+
+	isSuppressed := &query.IsEmailSuppressed{Address: address}
+	if err := bus.Dispatch(ctx, isSuppressed); err != nil {
+		log.Error(ctx, errors.Wrap(err, "failed to check if '%s' is suppressed", address))
+		return true
+	}
+	return !isSuppressed.Result
+}
+
+// LogDelivery records an email sent to given addresses so that its delivery can be tracked by message ID
+// Failing to log a delivery doesn't fail the sending, as the email is already gone
+func LogDelivery(ctx context.Context, provider, templateName, messageID string, addresses ...string) {
+	emailsSent.Add(float64(len(addresses)), provider)
+
+	messageID = NormalizeMessageID(messageID)
+	if messageID == "" || len(addresses) == 0 {
+		return
+	}
+
+	err := bus.Dispatch(ctx, &cmd.LogEmailDelivery{
+		MessageID:    messageID,
+		Provider:     provider,
+		TemplateName: templateName,
+		Addresses:    addresses,
+	})
+	if err != nil {
+		log.Error(ctx, errors.Wrap(err, "failed to log delivery of message '%s'", messageID))
+	}
+}
+
+// LogFailure records emails to given number of recipients that could not be sent by given provider
+func LogFailure(provider string, recipients int) {
+	emailsFailed.Add(float64(recipients), provider)
+}
+
+// NormalizeMessageID removes the angle brackets around a Message-ID
+// Providers are not consistent about them between the sending API and the delivery events
+func NormalizeMessageID(messageID string) string {
+	return strings.Trim(strings.TrimSpace(messageID), "<>")
+}
@@ -1,9 +1,11 @@
 package email_test
 
 import (
+	"context"
 	"testing"
 
 	"github.com/getfider/fider/app/models/dto"
+	"github.com/getfider/fider/app/pkg/i18n"
 	"github.com/getfider/fider/app/services/email"
 
 	. "github.com/getfider/fider/app/pkg/assert"
@@ -12,7 +14,7 @@ import (
 func TestRenderMessage(t *testing.T) {
 	RegisterT(t)
 
-	message := email.RenderMessage("echo_test", dto.Props{
+	message := email.RenderMessage(context.Background(), "echo_test", dto.Props{
 		"name": "Fider",
 	})
 	Expect(message.Subject).Equals("Message to: Fider")
@@ -49,6 +51,30 @@ func TestRenderMessage(t *testing.T) {
 </html>`)
 }
 
+func TestRenderMessage_Localized(t *testing.T) {
+	RegisterT(t)
+
+	ctx := i18n.WithLocale(context.Background(), "fr")
+	message := email.RenderMessage(ctx, "signin_email", dto.Props{
+		"tenantName": "Fider",
+		"link":       "<a href='https://demo.test.fider.io/signin/verify?k=1234'>https://demo.test.fider.io/signin/verify?k=1234</a>",
+	})
+	Expect(message.Subject).Equals("Connexion à Fider")
+	Expect(message.Body).ContainsSubstring("Cliquez sur le lien ci-dessous pour vous connecter à <strong>Fider</strong>.")
+	Expect(message.Body).ContainsSubstring("Cet email a été envoyé depuis une adresse de notification")
+}
+
+func TestRenderMessage_FallbackToDefaultLocale(t *testing.T) {
+	RegisterT(t)
+
+	ctx := i18n.WithLocale(context.Background(), "de")
+	message := email.RenderMessage(ctx, "echo_test", dto.Props{
+		"name": "Fider",
+	})
+	Expect(message.Subject).Equals("Message to: Fider")
+	Expect(message.Body).ContainsSubstring("Hello World Fider!")
+}
+
 func TestCanSendTo(t *testing.T) {
 	RegisterT(t)
 
@@ -9,6 +9,7 @@ import (
 	"github.com/getfider/fider/app/models/cmd"
 	"github.com/getfider/fider/app/models/dto"
 	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/i18n"
 )
 
 var MessageHistory = make([]*HistoryItem, 0)
@@ -19,6 +20,7 @@ type HistoryItem struct {
 	TemplateName string
 	Props        dto.Props
 	Tenant       *models.Tenant
+	Locale       string
 }
 
 func init() {
@@ -53,6 +55,7 @@ func sendMail(ctx context.Context, c *cmd.SendMail) {
 		To:           c.To,
 		TemplateName: c.TemplateName,
 		Props:        c.Props,
+		Locale:       i18n.Locale(ctx),
 	}
 
 	tenant, ok := ctx.Value(app.TenantCtxKey).(*models.Tenant)
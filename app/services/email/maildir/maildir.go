@@ -0,0 +1,142 @@
+package maildir
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/dto"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/env"
+	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/getfider/fider/app/pkg/log"
+	"github.com/getfider/fider/app/pkg/web"
+	"github.com/getfider/fider/app/services/email"
+)
+
+func init() {
+	bus.Register(Service{})
+}
+
+type Service struct{}
+
+func (s Service) Name() string {
+	return "Maildir"
+}
+
+func (s Service) Category() string {
+	return "email"
+}
+
+func (s Service) Enabled() bool {
+	return env.EmailProvider() == "maildir"
+}
+
+func (s Service) Init() {
+	bus.AddListener(sendMail)
+}
+
+//sendMail writes each email to a Maildir folder instead of sending it, which is useful for staging environments
+//Files are written to tmp/ and then moved to new/ so that mail clients never read partial messages
+//https://cr.yp.to/proto/maildir.html
+func sendMail(ctx context.Context, c *cmd.SendMail) {
+	if c.Props == nil {
+		c.Props = dto.Props{}
+	}
+
+	root := env.Config.Email.Maildir.Path
+	for _, folder := range []string{"tmp", "new", "cur"} {
+		if err := os.MkdirAll(filepath.Join(root, folder), 0755); err != nil {
+			panic(errors.Wrap(err, "failed to create maildir folder '%s'", folder))
+		}
+	}
+
+	localname := "localhost"
+	if u, err := url.Parse(web.BaseURL(ctx)); err == nil && u.Hostname() != "" {
+		localname = u.Hostname()
+	}
+
+	for _, to := range c.To {
+		if to.Address == "" {
+			continue
+		}
+
+		if !email.CanDeliverTo(ctx, to.Address) {
+			log.Warnf(ctx, "Skipping email to '@{Name} <@{Address}>'.", dto.Props{
+				"Name":    to.Name,
+				"Address": to.Address,
+			})
+			continue
+		}
+
+		message := email.RenderMessage(ctx, c.TemplateName, c.Props.Merge(to.Props))
+		replyTo := email.NoReply
+		if to.ReplyTo != "" {
+			replyTo = to.ReplyTo
+		}
+
+		id := uniqueID()
+		name := fmt.Sprintf("%s.%s", id, hostname())
+		messageID := fmt.Sprintf("%s@%s", id, localname)
+
+		var b strings.Builder
+		writeHeader(&b, "From", dto.NewRecipient(c.From, email.NoReply, dto.Props{}).String())
+		writeHeader(&b, "Reply-To", replyTo)
+		writeHeader(&b, "To", to.String())
+		writeHeader(&b, "Subject", mime.QEncoding.Encode("utf-8", message.Subject))
+		writeHeader(&b, "Date", time.Now().Format(time.RFC1123Z))
+		writeHeader(&b, "Message-ID", "<"+messageID+">")
+		writeHeader(&b, "X-Fider-Template", c.TemplateName)
+		writeHeader(&b, "MIME-Version", "1.0")
+		writeHeader(&b, "Content-Type", "text/html; charset=\"UTF-8\"")
+		b.WriteString("\r\n")
+		b.WriteString(message.Body)
+
+		tmpFile := filepath.Join(root, "tmp", name)
+		if err := ioutil.WriteFile(tmpFile, []byte(b.String()), 0644); err != nil {
+			email.LogFailure("maildir", 1)
+			panic(errors.Wrap(err, "failed to write email with template %s", c.TemplateName))
+		}
+		if err := os.Rename(tmpFile, filepath.Join(root, "new", name)); err != nil {
+			email.LogFailure("maildir", 1)
+			panic(errors.Wrap(err, "failed to deliver email with template %s", c.TemplateName))
+		}
+
+		log.Debugf(ctx, "Email to @{Address} with template @{TemplateName} written to maildir.", dto.Props{
+			"Address":      to.Address,
+			"TemplateName": c.TemplateName,
+		})
+		email.LogDelivery(ctx, "maildir", c.TemplateName, messageID, to.Address)
+	}
+}
+
+func writeHeader(b *strings.Builder, key, value string) {
+	b.WriteString(fmt.Sprintf("%s: %s\r\n", key, value))
+}
+
+//uniqueID returns an identifier based on current time and random bytes, used on both file name and Message-ID
+func uniqueID() string {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		panic(err)
+	}
+	return fmt.Sprintf("%d.%s", time.Now().UnixNano(), hex.EncodeToString(buf))
+}
+
+//hostname returns the name of current host with the characters that are not allowed on maildir file names encoded
+func hostname() string {
+	name, err := os.Hostname()
+	if err != nil {
+		name = "localhost"
+	}
+	return strings.NewReplacer("/", "\\057", ":", "\\072").Replace(name)
+}
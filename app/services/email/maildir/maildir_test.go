@@ -0,0 +1,152 @@
+package maildir_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/dto"
+	"github.com/getfider/fider/app/models/query"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/env"
+	"github.com/getfider/fider/app/services/email"
+	"github.com/getfider/fider/app/services/email/maildir"
+)
+
+var ctx context.Context
+
+var suppressed map[string]bool
+var deliveries []*cmd.LogEmailDelivery
+
+func reset() string {
+	ctx = context.WithValue(context.Background(), app.TenantCtxKey, &models.Tenant{
+		Subdomain: "got",
+	})
+
+	dir, err := ioutil.TempDir("", "maildir")
+	Expect(err).IsNil()
+	env.Config.Email.Maildir.Path = dir
+	email.SetWhitelist("")
+
+	bus.Init(maildir.Service{})
+	suppressed = make(map[string]bool)
+	deliveries = make([]*cmd.LogEmailDelivery, 0)
+	bus.AddHandler(func(ctx context.Context, q *query.IsEmailSuppressed) error {
+		q.Result = suppressed[q.Address]
+		return nil
+	})
+	bus.AddHandler(func(ctx context.Context, c *cmd.LogEmailDelivery) error {
+		deliveries = append(deliveries, c)
+		return nil
+	})
+	return dir
+}
+
+func readNew(dir string) []string {
+	files, err := ioutil.ReadDir(filepath.Join(dir, "new"))
+	Expect(err).IsNil()
+	contents := make([]string, len(files))
+	for i, file := range files {
+		bytes, err := ioutil.ReadFile(filepath.Join(dir, "new", file.Name()))
+		Expect(err).IsNil()
+		contents[i] = string(bytes)
+	}
+	return contents
+}
+
+func TestSend_Success(t *testing.T) {
+	RegisterT(t)
+	dir := reset()
+	defer os.RemoveAll(dir)
+
+	bus.Publish(ctx, &cmd.SendMail{
+		From: "Fider Test",
+		To: []dto.Recipient{
+			dto.Recipient{
+				Name:    "Jon Sow",
+				Address: "jon.snow@got.com",
+			},
+			dto.Recipient{
+				Name:    "Arya Stark",
+				Address: "arya.stark@got.com",
+			},
+		},
+		TemplateName: "echo_test",
+		Props: dto.Props{
+			"name": "Hello",
+		},
+	})
+
+	messages := readNew(dir)
+	Expect(messages).HasLen(2)
+	for _, message := range messages {
+		Expect(message).ContainsSubstring("From: \"Fider Test\" <noreply@random.org>\r\nReply-To: noreply@random.org\r\n")
+		Expect(message).ContainsSubstring("Subject: Message to: Hello\r\n")
+		Expect(message).ContainsSubstring("X-Fider-Template: echo_test\r\n")
+		Expect(message).ContainsSubstring("Hello World Hello!")
+	}
+
+	tmp, err := ioutil.ReadDir(filepath.Join(dir, "tmp"))
+	Expect(err).IsNil()
+	Expect(tmp).HasLen(0)
+
+	Expect(deliveries).HasLen(2)
+	Expect(deliveries[0].Provider).Equals("maildir")
+	Expect(deliveries[0].TemplateName).Equals("echo_test")
+	Expect(deliveries[0].Addresses).Equals([]string{"jon.snow@got.com"})
+	Expect(deliveries[1].Addresses).Equals([]string{"arya.stark@got.com"})
+}
+
+func TestSend_EncodedSubject(t *testing.T) {
+	RegisterT(t)
+	dir := reset()
+	defer os.RemoveAll(dir)
+
+	bus.Publish(ctx, &cmd.SendMail{
+		From: "Fider Test",
+		To: []dto.Recipient{
+			dto.Recipient{
+				Name:    "Jon Sow",
+				Address: "jon.snow@got.com",
+			},
+		},
+		TemplateName: "echo_test",
+		Props: dto.Props{
+			"name": "Café",
+		},
+	})
+
+	messages := readNew(dir)
+	Expect(messages).HasLen(1)
+	Expect(messages[0]).ContainsSubstring("Subject: =?utf-8?q?Message_to:_Caf=C3=A9?=\r\n")
+}
+
+func TestSend_SkipSuppressedAddress(t *testing.T) {
+	RegisterT(t)
+	dir := reset()
+	defer os.RemoveAll(dir)
+	suppressed["jon.snow@got.com"] = true
+
+	bus.Publish(ctx, &cmd.SendMail{
+		From: "Fider Test",
+		To: []dto.Recipient{
+			dto.Recipient{
+				Name:    "Jon Sow",
+				Address: "jon.snow@got.com",
+			},
+		},
+		TemplateName: "echo_test",
+		Props: dto.Props{
+			"name": "Hello",
+		},
+	})
+
+	Expect(readNew(dir)).HasLen(0)
+	Expect(deliveries).HasLen(0)
+}
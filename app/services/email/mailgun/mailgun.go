@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/url"
+	"sort"
 	"strings"
 
 	"github.com/getfider/fider/app"
@@ -42,7 +43,7 @@ func (s Service) Category() string {
 }
 
 func (s Service) Enabled() bool {
-	return env.Config.Email.Mailgun.APIKey != ""
+	return env.EmailProvider() == "mailgun"
 }
 
 func (s Service) Init() {
@@ -92,14 +93,30 @@ func sendMail(ctx context.Context, c *cmd.SendMail) {
 				c.Props[k] = fmt.Sprintf("%%recipient.%s%%", k)
 			}
 		}
-		message = email.RenderMessage(c.TemplateName, c.Props)
+		message = email.RenderMessage(ctx, c.TemplateName, c.Props)
 	} else {
-		message = email.RenderMessage(c.TemplateName, c.Props.Merge(c.To[0].Props))
+		message = email.RenderMessage(ctx, c.TemplateName, c.Props.Merge(c.To[0].Props))
+	}
+
+	// Recipients that can reply to the email have their own Reply-To address
+	hasReplyTo := false
+	for _, r := range c.To {
+		if r.ReplyTo != "" {
+			hasReplyTo = true
+		}
+	}
+
+	replyTo := email.NoReply
+	if hasReplyTo {
+		replyTo = c.To[0].ReplyTo
+		if isBatch {
+			replyTo = "%recipient.reply_to%"
+		}
 	}
 
 	form := url.Values{}
 	form.Add("from", dto.NewRecipient(c.From, email.NoReply, dto.Props{}).String())
-	form.Add("h:Reply-To", email.NoReply)
+	form.Add("h:Reply-To", replyTo)
 	form.Add("subject", message.Subject)
 	form.Add("html", message.Body)
 	form.Add("o:tag", fmt.Sprintf("template:%s", c.TemplateName))
@@ -113,9 +130,12 @@ func sendMail(ctx context.Context, c *cmd.SendMail) {
 	recipientVariables := make(map[string]dto.Props)
 	for _, r := range c.To {
 		if r.Address != "" {
-			if email.CanSendTo(r.Address) {
+			if email.CanDeliverTo(ctx, r.Address) {
 				form.Add("to", r.String())
 				recipientVariables[r.Address] = r.Props
+				if isBatch && hasReplyTo {
+					recipientVariables[r.Address] = withReplyTo(r)
+				}
 			} else {
 				log.Warnf(ctx, "Skipping email to '@{Name} <@{Address}>'.", dto.Props{
 					"Name":    r.Name,
@@ -165,9 +185,30 @@ func sendMail(ctx context.Context, c *cmd.SendMail) {
 	}
 	err := bus.Dispatch(ctx, req)
 	if err != nil {
+		email.LogFailure("mailgun", len(recipientVariables))
 		panic(errors.Wrap(err, "failed to send email with template %s", c.TemplateName))
 	}
 	log.Debugf(ctx, "Email sent with response code @{StatusCode}.", dto.Props{
 		"StatusCode": req.ResponseStatusCode,
 	})
+
+	var response struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(req.ResponseBody, &response); err == nil {
+		addresses := make([]string, 0, len(recipientVariables))
+		for address := range recipientVariables {
+			addresses = append(addresses, address)
+		}
+		sort.Strings(addresses)
+		email.LogDelivery(ctx, "mailgun", c.TemplateName, response.ID, addresses...)
+	}
+}
+
+func withReplyTo(r dto.Recipient) dto.Props {
+	props := dto.Props{"reply_to": email.NoReply}
+	if r.ReplyTo != "" {
+		props["reply_to"] = r.ReplyTo
+	}
+	return props.Merge(r.Props)
 }
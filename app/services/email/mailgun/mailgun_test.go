@@ -9,6 +9,7 @@ import (
 	"github.com/getfider/fider/app"
 	"github.com/getfider/fider/app/models/cmd"
 	"github.com/getfider/fider/app/models/dto"
+	"github.com/getfider/fider/app/models/query"
 	"github.com/getfider/fider/app/pkg/bus"
 	"github.com/getfider/fider/app/pkg/env"
 	"github.com/getfider/fider/app/services/email/mailgun"
@@ -23,11 +24,25 @@ import (
 
 var ctx context.Context
 
+var suppressed map[string]bool
+var deliveries []*cmd.LogEmailDelivery
+
 func reset() {
 	ctx = context.WithValue(context.Background(), app.TenantCtxKey, &models.Tenant{
 		Subdomain: "got",
 	})
 	bus.Init(mailgun.Service{}, httpclientmock.Service{})
+
+	suppressed = make(map[string]bool)
+	deliveries = make([]*cmd.LogEmailDelivery, 0)
+	bus.AddHandler(func(ctx context.Context, q *query.IsEmailSuppressed) error {
+		q.Result = suppressed[q.Address]
+		return nil
+	})
+	bus.AddHandler(func(ctx context.Context, c *cmd.LogEmailDelivery) error {
+		deliveries = append(deliveries, c)
+		return nil
+	})
 }
 
 func TestSend_Success(t *testing.T) {
@@ -141,6 +156,38 @@ func TestSend_SkipUnlistedAddress(t *testing.T) {
 	Expect(httpclientmock.RequestsHistory).HasLen(0)
 }
 
+func TestSend_SkipSuppressedAddress(t *testing.T) {
+	RegisterT(t)
+	reset()
+	email.SetWhitelist("")
+	suppressed["jon.snow@got.com"] = true
+
+	bus.Publish(ctx, &cmd.SendMail{
+		From: "Fider Test",
+		To: []dto.Recipient{
+			dto.Recipient{
+				Name:    "Jon Sow",
+				Address: "jon.snow@got.com",
+			},
+			dto.Recipient{
+				Name:    "Arya Stark",
+				Address: "arya.stark@got.com",
+			},
+		},
+		TemplateName: "echo_test",
+		Props: dto.Props{
+			"name": "Hello",
+		},
+	})
+
+	Expect(httpclientmock.RequestsHistory).HasLen(1)
+	bytes, err := ioutil.ReadAll(httpclientmock.RequestsHistory[0].Body)
+	Expect(err).IsNil()
+	values, err := url.ParseQuery(string(bytes))
+	Expect(err).IsNil()
+	Expect(values["to"]).Equals([]string{`"Arya Stark" <arya.stark@got.com>`})
+}
+
 func TestBatch_Success(t *testing.T) {
 	RegisterT(t)
 	reset()
@@ -266,3 +313,66 @@ func TestGetBaseURL(t *testing.T) {
 	Expect(httpclientmock.RequestsHistory[5].URL.String()).Equals("https://api.mailgun.net/v3/mydomain.com/messages")
 
 }
+
+func TestSend_WithReplyTo(t *testing.T) {
+	RegisterT(t)
+	reset()
+
+	bus.Publish(ctx, &cmd.SendMail{
+		From: "Fider Test",
+		To: []dto.Recipient{
+			dto.Recipient{
+				Name:    "Jon Sow",
+				Address: "jon.snow@got.com",
+				ReplyTo: "reply+1.2.3.abc@inbound.random.org",
+			},
+		},
+		TemplateName: "echo_test",
+		Props: dto.Props{
+			"name": "Hello",
+		},
+	})
+
+	Expect(httpclientmock.RequestsHistory).HasLen(1)
+	bytes, err := ioutil.ReadAll(httpclientmock.RequestsHistory[0].Body)
+	Expect(err).IsNil()
+	values, err := url.ParseQuery(string(bytes))
+	Expect(err).IsNil()
+	Expect(values.Get("from")).Equals(`"Fider Test" <noreply@random.org>`)
+	Expect(values.Get("h:Reply-To")).Equals("reply+1.2.3.abc@inbound.random.org")
+}
+
+func TestBatch_WithReplyTo(t *testing.T) {
+	RegisterT(t)
+	reset()
+
+	bus.Publish(ctx, &cmd.SendMail{
+		From: "Fider Test",
+		To: []dto.Recipient{
+			dto.Recipient{
+				Name:    "Jon Sow",
+				Address: "jon.snow@got.com",
+				ReplyTo: "reply+1.2.3.abc@inbound.random.org",
+				Props: dto.Props{
+					"name": "Jon",
+				},
+			},
+			dto.Recipient{
+				Name:    "Arya Stark",
+				Address: "arya.start@got.com",
+				Props: dto.Props{
+					"name": "Arya",
+				},
+			},
+		},
+		TemplateName: "echo_test",
+	})
+
+	Expect(httpclientmock.RequestsHistory).HasLen(1)
+	bytes, err := ioutil.ReadAll(httpclientmock.RequestsHistory[0].Body)
+	Expect(err).IsNil()
+	values, err := url.ParseQuery(string(bytes))
+	Expect(err).IsNil()
+	Expect(values.Get("h:Reply-To")).Equals("%recipient.reply_to%")
+	Expect(values.Get("recipient-variables")).Equals("{\"arya.start@got.com\":{\"name\":\"Arya\",\"reply_to\":\"noreply@random.org\"},\"jon.snow@got.com\":{\"name\":\"Jon\",\"reply_to\":\"reply+1.2.3.abc@inbound.random.org\"}}")
+}
@@ -2,11 +2,20 @@ package email
 
 import (
 	"bytes"
+	"context"
 	"html/template"
+	"os"
 	"strings"
 
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/models"
 	"github.com/getfider/fider/app/models/dto"
+	"github.com/getfider/fider/app/models/query"
+	"github.com/getfider/fider/app/pkg/bus"
 	"github.com/getfider/fider/app/pkg/env"
+	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/getfider/fider/app/pkg/i18n"
+	"github.com/getfider/fider/app/pkg/log"
 )
 
 var cache = make(map[string]*template.Template)
@@ -17,39 +26,98 @@ type Message struct {
 	Body    string
 }
 
-var baseTpl, _ = template.ParseFiles(env.Path("/views/templates/base_email.tpl"))
-
 // RenderMessage returns the HTML of an email based on template and params
-func RenderMessage(templateName string, params dto.Props) *Message {
-	tpl, ok := cache[templateName]
+// The template customized by current tenant is used when there is one, otherwise the bundled one is used
+// Bundled templates are translated to the locale of given context when there's a translation for it
+func RenderMessage(ctx context.Context, templateName string, params dto.Props) *Message {
+	locale := i18n.Locale(ctx)
+	if custom := getCustomTemplate(ctx, templateName); custom != nil {
+		message, err := RenderTemplate(locale, custom, params)
+		if err == nil {
+			return message
+		}
+		log.Warnf(ctx, "Failed to render custom email template '@{TemplateName}', using default instead: @{Error}", dto.Props{
+			"TemplateName": templateName,
+			"Error":        err.Error(),
+		})
+	}
+
+	message, err := render(locale, getTemplate(locale, templateName), params)
+	if err != nil {
+		panic(err)
+	}
+	return message
+}
+
+//getTemplate returns the bundled template with given name, translated to given locale if possible
+func getTemplate(locale, templateName string) *template.Template {
+	key := locale + "/" + templateName
+	tpl, ok := cache[key]
 	if !ok || env.IsDevelopment() {
 		var err error
-		file := env.Path("/views/templates", templateName+".tpl")
-		tpl, err = template.ParseFiles(file)
+		tpl, err = template.ParseFiles(templateFile(locale, templateName))
 		if err != nil {
 			panic(err)
 		}
-		cache[templateName] = tpl
+		cache[key] = tpl
+	}
+	return tpl
+}
+
+//templateFile returns the path of the bundled template, which is on a folder named after the locale when translated
+func templateFile(locale, templateName string) string {
+	if locale != i18n.DefaultLocale {
+		file := env.Path("/views/templates", locale, templateName+".tpl")
+		if _, err := os.Stat(file); err == nil {
+			return file
+		}
+	}
+	return env.Path("/views/templates", templateName+".tpl")
+}
+
+//getCustomTemplate returns the template customized by current tenant, or nil if there's none
+func getCustomTemplate(ctx context.Context, templateName string) *models.EmailTemplate {
+	if !IsEditable(templateName) {
+		return nil
+	}
+
+	if custom, ok := ctx.Value(templateCtxKey{}).(*models.EmailTemplate); ok && custom.Name == templateName {
+		return custom
+	}
+
+	if _, ok := ctx.Value(app.TenantCtxKey).(*models.Tenant); !ok {
+		return nil
+	}
+
+	getTemplate := &query.GetCustomEmailTemplate{Name: templateName}
+	if err := bus.Dispatch(ctx, getTemplate); err != nil {
+		if errors.Cause(err) != app.ErrNotFound {
+			log.Error(ctx, errors.Wrap(err, "failed to get custom email template '%s'", templateName))
+		}
+		return nil
 	}
+	return getTemplate.Result
+}
 
+func render(locale string, tpl *template.Template, params dto.Props) (*Message, error) {
 	var bf bytes.Buffer
 	if err := tpl.Execute(&bf, params); err != nil {
-		panic(err)
+		return nil, err
 	}
 
 	lines := strings.Split(bf.String(), "\n")
 	body := strings.TrimLeft(strings.Join(lines[2:], "\n"), " ")
 
 	bf.Reset()
-	if err := baseTpl.Execute(&bf, dto.Props{
+	if err := getTemplate(locale, "base_email").Execute(&bf, dto.Props{
 		"logo": params["logo"],
 		"body": template.HTML(body),
 	}); err != nil {
-		panic(err)
+		return nil, err
 	}
 
 	return &Message{
-		Subject: strings.TrimLeft(lines[0], "subject: "),
+		Subject: strings.TrimPrefix(lines[0], "subject: "),
 		Body:    bf.String(),
-	}
+	}, nil
 }
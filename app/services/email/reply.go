@@ -0,0 +1,240 @@
+package email
+
+import (
+	"crypto/hmac"
+	"encoding/base64"
+	stderrors "errors"
+	"fmt"
+	"html"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/pkg/crypto"
+	"github.com/getfider/fider/app/pkg/env"
+	"github.com/getfider/fider/app/pkg/errors"
+)
+
+// ErrInvalidReplyAddress is returned when an address was not generated by ReplyAddress or has been tampered with
+var ErrInvalidReplyAddress = stderrors.New("invalid reply address")
+
+const replyPrefix = "reply+"
+
+// ReplyToken identifies which user is replying to which post
+type ReplyToken struct {
+	TenantID   int
+	UserID     int
+	PostNumber int
+}
+
+// ReplyAddress returns a signed address that users can reply to in order to comment on a post
+// Returns an empty string if reply by email is disabled
+func ReplyAddress(tenantID, userID, postNumber int) string {
+	if !env.IsReplyByEmailEnabled() {
+		return ""
+	}
+
+	payload := fmt.Sprintf("%d.%d.%d", tenantID, userID, postNumber)
+	return fmt.Sprintf("%s%s.%s@%s", replyPrefix, payload, replySignature(payload), env.Config.Email.Inbound.Domain)
+}
+
+// ParseReplyAddress validates given address and returns the token it was generated with
+func ParseReplyAddress(address string) (*ReplyToken, error) {
+	if !env.IsReplyByEmailEnabled() {
+		return nil, ErrInvalidReplyAddress
+	}
+
+	if parsed, err := mail.ParseAddress(address); err == nil {
+		address = parsed.Address
+	}
+
+	at := strings.LastIndex(address, "@")
+	if at < 0 || !strings.EqualFold(address[at+1:], env.Config.Email.Inbound.Domain) {
+		return nil, ErrInvalidReplyAddress
+	}
+
+	local := strings.ToLower(address[:at])
+	if !strings.HasPrefix(local, replyPrefix) {
+		return nil, ErrInvalidReplyAddress
+	}
+
+	parts := strings.Split(local[len(replyPrefix):], ".")
+	if len(parts) != 4 {
+		return nil, ErrInvalidReplyAddress
+	}
+
+	payload := strings.Join(parts[:3], ".")
+	if !hmac.Equal([]byte(parts[3]), []byte(replySignature(payload))) {
+		return nil, ErrInvalidReplyAddress
+	}
+
+	ids := make([]int, 3)
+	for i := range ids {
+		id, err := strconv.Atoi(parts[i])
+		if err != nil || id <= 0 {
+			return nil, ErrInvalidReplyAddress
+		}
+		ids[i] = id
+	}
+
+	return &ReplyToken{
+		TenantID:   ids[0],
+		UserID:     ids[1],
+		PostNumber: ids[2],
+	}, nil
+}
+
+func replySignature(payload string) string {
+	return crypto.HMACSHA256(env.Config.JWTSecret, []byte("reply|"+payload))[:20]
+}
+
+var (
+	quoteHeaderRegex = regexp.MustCompile(`(?i)^(on\s.+wrote:|-+\s*original message\s*-+|_{10,})$`)
+	quoteStartRegex  = regexp.MustCompile(`(?i)^on\s`)
+	outlookFromRegex = regexp.MustCompile(`(?i)^from:\s`)
+	outlookSentRegex = regexp.MustCompile(`(?i)^(sent|date):\s`)
+	signatureRegex   = regexp.MustCompile(`(?i)^(--|sent from my .+|get outlook for .+)$`)
+)
+
+// StripReply removes quoted text and signatures from the plain text of a reply
+func StripReply(text string) string {
+	lines := strings.Split(strings.Replace(text, "\r\n", "\n", -1), "\n")
+	result := make([]string, 0, len(lines))
+
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		next := ""
+		if i+1 < len(lines) {
+			next = strings.TrimSpace(lines[i+1])
+		}
+
+		//Some clients break the "On <date>, <name> wrote:" header in two lines
+		header := trimmed
+		if quoteStartRegex.MatchString(trimmed) && !strings.HasSuffix(trimmed, ":") {
+			header = trimmed + " " + next
+		}
+
+		if quoteHeaderRegex.MatchString(header) ||
+			(outlookFromRegex.MatchString(trimmed) && outlookSentRegex.MatchString(next)) ||
+			signatureRegex.MatchString(trimmed) {
+			break
+		}
+
+		if strings.HasPrefix(trimmed, ">") {
+			continue
+		}
+
+		result = append(result, line)
+	}
+
+	return strings.TrimSpace(strings.Join(result, "\n"))
+}
+
+var htmlTagRegex = regexp.MustCompile(`(?s)<(br|/p|/div)[^>]*>|<[^>]*>`)
+
+// ParseMessage reads a raw RFC 5322 message and returns its sender, recipients and plain text
+func ParseMessage(r io.Reader) (*models.InboundEmail, error) {
+	msg, err := mail.ReadMessage(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read message")
+	}
+
+	decoder := new(mime.WordDecoder)
+	message := &models.InboundEmail{}
+
+	if from, err := msg.Header.AddressList("From"); err == nil && len(from) > 0 {
+		message.From = from[0].Address
+	}
+
+	for _, key := range []string{"To", "Cc"} {
+		if list, err := msg.Header.AddressList(key); err == nil {
+			for _, address := range list {
+				message.To = append(message.To, address.Address)
+			}
+		}
+	}
+
+	message.Subject, err = decoder.DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil {
+		message.Subject = msg.Header.Get("Subject")
+	}
+
+	autoSubmitted := strings.ToLower(strings.TrimSpace(msg.Header.Get("Auto-Submitted")))
+	message.AutoSubmitted = autoSubmitted != "" && autoSubmitted != "no"
+
+	text, isHTML, err := readTextPart(msg.Header.Get("Content-Type"), msg.Header.Get("Content-Transfer-Encoding"), msg.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if isHTML {
+		text = html.UnescapeString(htmlTagRegex.ReplaceAllStringFunc(text, func(tag string) string {
+			if strings.HasPrefix(tag, "<br") || strings.HasPrefix(tag, "</") {
+				return "\n"
+			}
+			return ""
+		}))
+	}
+
+	message.Text = text
+	return message, nil
+}
+
+//readTextPart returns the plain text of given body, or its HTML when there's no plain text alternative
+func readTextPart(contentType, encoding string, body io.Reader) (string, bool, error) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = "text/plain"
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		var htmlText string
+		reader := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := reader.NextPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return "", false, errors.Wrap(err, "failed to read multipart message")
+			}
+
+			text, isHTML, err := readTextPart(part.Header.Get("Content-Type"), part.Header.Get("Content-Transfer-Encoding"), part)
+			if err != nil {
+				return "", false, err
+			}
+			if !isHTML && text != "" {
+				return text, false, nil
+			}
+			if isHTML && htmlText == "" {
+				htmlText = text
+			}
+		}
+		return htmlText, htmlText != "", nil
+	}
+
+	if mediaType != "text/plain" && mediaType != "text/html" {
+		return "", false, nil
+	}
+
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "quoted-printable":
+		body = quotedprintable.NewReader(body)
+	case "base64":
+		body = base64.NewDecoder(base64.StdEncoding, body)
+	}
+
+	content, err := ioutil.ReadAll(body)
+	if err != nil {
+		return "", false, errors.Wrap(err, "failed to read message body")
+	}
+
+	return string(content), mediaType == "text/html", nil
+}
@@ -0,0 +1,150 @@
+package email_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/getfider/fider/app/pkg/env"
+	"github.com/getfider/fider/app/services/email"
+
+	. "github.com/getfider/fider/app/pkg/assert"
+)
+
+func TestReplyAddress_Disabled(t *testing.T) {
+	RegisterT(t)
+
+	Expect(email.ReplyAddress(1, 2, 3)).Equals("")
+
+	token, err := email.ParseReplyAddress("reply+1.2.3.abc@inbound.test.fider.io")
+	Expect(err).Equals(email.ErrInvalidReplyAddress)
+	Expect(token).IsNil()
+}
+
+func TestReplyAddress_RoundTrip(t *testing.T) {
+	RegisterT(t)
+	env.Config.Email.Inbound.Domain = "inbound.test.fider.io"
+
+	address := email.ReplyAddress(1, 2, 3)
+	Expect(address).ContainsSubstring("reply+1.2.3.")
+	Expect(strings.HasSuffix(address, "@inbound.test.fider.io")).IsTrue()
+
+	for _, input := range []string{
+		address,
+		strings.ToUpper(address),
+		"Jon Snow <" + address + ">",
+	} {
+		token, err := email.ParseReplyAddress(input)
+		Expect(err).IsNil()
+		Expect(token.TenantID).Equals(1)
+		Expect(token.UserID).Equals(2)
+		Expect(token.PostNumber).Equals(3)
+	}
+}
+
+func TestReplyAddress_Invalid(t *testing.T) {
+	RegisterT(t)
+	env.Config.Email.Inbound.Domain = "inbound.test.fider.io"
+
+	local := strings.Split(email.ReplyAddress(1, 2, 3), "@")[0]
+	signature := local[strings.LastIndex(local, ".")+1:]
+
+	for _, input := range []string{
+		"",
+		"jon.snow@got.com",
+		"reply+1.2.3@inbound.test.fider.io",
+		"reply+1.2.4." + signature + "@inbound.test.fider.io",
+		"reply+1.5.3." + signature + "@inbound.test.fider.io",
+		"reply+1.2.3." + signature + "@other.fider.io",
+		"other+1.2.3." + signature + "@inbound.test.fider.io",
+	} {
+		token, err := email.ParseReplyAddress(input)
+		Expect(err).Equals(email.ErrInvalidReplyAddress)
+		Expect(token).IsNil()
+	}
+}
+
+func TestStripReply(t *testing.T) {
+	RegisterT(t)
+
+	testCases := []struct {
+		text     string
+		expected string
+	}{
+		{"Sounds good!", "Sounds good!"},
+		{"Sounds good!\r\n\r\nIt works.\r\n", "Sounds good!\n\nIt works."},
+		{"Sounds good!\n\nOn Mon, 1 Jul 2019 at 10:00, Fider <noreply@fider.io> wrote:\n> Original content", "Sounds good!"},
+		{"Sounds good!\n\nOn Mon, 1 Jul 2019 at 10:00, Fider\n<noreply@fider.io> wrote:\n> Original content", "Sounds good!"},
+		{"Sounds good!\n> quoted\nAnd more", "Sounds good!\nAnd more"},
+		{"Sounds good!\n\n-----Original Message-----\nFrom: Fider", "Sounds good!"},
+		{"Sounds good!\n\nFrom: Fider <noreply@fider.io>\nSent: Monday, July 1, 2019\nTo: Jon", "Sounds good!"},
+		{"Sounds good!\n\n________________________________\nFrom: Fider", "Sounds good!"},
+		{"Sounds good!\n--\nJon Snow\nLord Commander", "Sounds good!"},
+		{"Sounds good!\n\nSent from my iPhone", "Sounds good!"},
+		{"Sounds good!\n\nGet Outlook for Android", "Sounds good!"},
+		{"From: here to there is a long way\nIndeed", "From: here to there is a long way\nIndeed"},
+		{"> only quoted text", ""},
+	}
+
+	for _, testCase := range testCases {
+		Expect(email.StripReply(testCase.text)).Equals(testCase.expected)
+	}
+}
+
+func TestParseMessage_PlainText(t *testing.T) {
+	RegisterT(t)
+
+	message, err := email.ParseMessage(strings.NewReader("From: Jon Snow <jon.snow@got.com>\r\n" +
+		"To: reply+1.2.3.abc@inbound.test.fider.io\r\n" +
+		"Cc: Arya Stark <arya.stark@got.com>\r\n" +
+		"Subject: =?UTF-8?Q?Re:_[Demo]_Add_support_for_TypeScript?=\r\n" +
+		"Content-Type: text/plain; charset=UTF-8\r\n" +
+		"Content-Transfer-Encoding: quoted-printable\r\n" +
+		"\r\n" +
+		"I agree =E2=9C=93\r\n"))
+	Expect(err).IsNil()
+	Expect(message.From).Equals("jon.snow@got.com")
+	Expect(message.To).Equals([]string{"reply+1.2.3.abc@inbound.test.fider.io", "arya.stark@got.com"})
+	Expect(message.Subject).Equals("Re: [Demo] Add support for TypeScript")
+	Expect(message.Text).Equals("I agree ✓\r\n")
+	Expect(message.AutoSubmitted).IsFalse()
+}
+
+func TestParseMessage_Multipart(t *testing.T) {
+	RegisterT(t)
+
+	message, err := email.ParseMessage(strings.NewReader("From: jon.snow@got.com\r\n" +
+		"To: reply+1.2.3.abc@inbound.test.fider.io\r\n" +
+		"Subject: Re: Hello\r\n" +
+		"Auto-Submitted: auto-replied\r\n" +
+		"Content-Type: multipart/alternative; boundary=\"XYZ\"\r\n" +
+		"\r\n" +
+		"--XYZ\r\n" +
+		"Content-Type: text/html; charset=UTF-8\r\n" +
+		"\r\n" +
+		"<p>From <b>HTML</b></p>\r\n" +
+		"--XYZ\r\n" +
+		"Content-Type: text/plain; charset=UTF-8\r\n" +
+		"Content-Transfer-Encoding: base64\r\n" +
+		"\r\n" +
+		"RnJvbSBwbGFpbiB0ZXh0\r\n" +
+		"--XYZ--\r\n"))
+	Expect(err).IsNil()
+	Expect(message.Text).Equals("From plain text")
+	Expect(message.AutoSubmitted).IsTrue()
+}
+
+func TestParseMessage_HTMLOnly(t *testing.T) {
+	RegisterT(t)
+
+	message, err := email.ParseMessage(strings.NewReader("From: jon.snow@got.com\r\n" +
+		"To: reply+1.2.3.abc@inbound.test.fider.io\r\n" +
+		"Content-Type: multipart/alternative; boundary=\"XYZ\"\r\n" +
+		"\r\n" +
+		"--XYZ\r\n" +
+		"Content-Type: text/html; charset=UTF-8\r\n" +
+		"\r\n" +
+		"<div>Hello &amp; welcome</div><div>Second line<br/>Third line</div>\r\n" +
+		"--XYZ--\r\n"))
+	Expect(err).IsNil()
+	Expect(email.StripReply(message.Text)).Equals("Hello & welcome\nSecond line\nThird line")
+}
@@ -0,0 +1,154 @@
+package ses
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ses"
+	"github.com/getfider/fider/app"
+
+	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/dto"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/env"
+	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/getfider/fider/app/pkg/log"
+	"github.com/getfider/fider/app/services/email"
+)
+
+//DefaultClient is an SES Client
+var DefaultClient *ses.SES
+
+func init() {
+	bus.Register(Service{})
+}
+
+type Service struct{}
+
+func (s Service) Name() string {
+	return "SES"
+}
+
+func (s Service) Category() string {
+	return "email"
+}
+
+func (s Service) Enabled() bool {
+	return env.EmailProvider() == "ses"
+}
+
+func (s Service) Init() {
+	sesEnvConfig := env.Config.Email.SES
+	sesConfig := &aws.Config{
+		Region: aws.String(sesEnvConfig.Region),
+	}
+
+	//Without static keys, credentials are read from the environment or the instance role
+	if sesEnvConfig.AccessKeyID != "" {
+		sesConfig.Credentials = credentials.NewStaticCredentials(sesEnvConfig.AccessKeyID, sesEnvConfig.SecretAccessKey, "")
+	}
+
+	awsSession, err := session.NewSession(sesConfig)
+	if err != nil {
+		panic(err)
+	}
+
+	DefaultClient = ses.New(awsSession)
+	bus.AddListener(sendMail)
+}
+
+//Send calls the SendEmail action of SES API, requests are signed with AWS Signature Version 4
+var Send = func(ctx context.Context, input *ses.SendEmailInput) (string, error) {
+	output, err := DefaultClient.SendEmailWithContext(ctx, input)
+	if err != nil {
+		return "", err
+	}
+	return aws.StringValue(output.MessageId), nil
+}
+
+//SES only accepts alphanumeric ASCII characters, '_' and '-' on tag values
+var invalidTagChars = regexp.MustCompile("[^a-zA-Z0-9_-]")
+
+func sendMail(ctx context.Context, c *cmd.SendMail) {
+	if c.Props == nil {
+		c.Props = dto.Props{}
+	}
+
+	tags := []*ses.MessageTag{
+		{Name: aws.String("template"), Value: aws.String(invalidTagChars.ReplaceAllString(c.TemplateName, "_"))},
+	}
+
+	tenant, ok := ctx.Value(app.TenantCtxKey).(*models.Tenant)
+	if ok && !env.IsSingleHostMode() {
+		tags = append(tags, &ses.MessageTag{
+			Name:  aws.String("tenant"),
+			Value: aws.String(invalidTagChars.ReplaceAllString(tenant.Subdomain, "_")),
+		})
+	}
+
+	for _, to := range c.To {
+		if to.Address == "" {
+			continue
+		}
+
+		if !email.CanDeliverTo(ctx, to.Address) {
+			log.Warnf(ctx, "Skipping email to '@{Name} <@{Address}>'.", dto.Props{
+				"Name":    to.Name,
+				"Address": to.Address,
+			})
+			continue
+		}
+
+		log.Debugf(ctx, "Sending email to @{Address} with template @{TemplateName}.", dto.Props{
+			"Address":      to.Address,
+			"TemplateName": c.TemplateName,
+		})
+
+		message := email.RenderMessage(ctx, c.TemplateName, c.Props.Merge(to.Props))
+		replyTo := email.NoReply
+		if to.ReplyTo != "" {
+			replyTo = to.ReplyTo
+		}
+
+		input := &ses.SendEmailInput{
+			Source: aws.String(dto.NewRecipient(c.From, email.NoReply, dto.Props{}).String()),
+			Destination: &ses.Destination{
+				ToAddresses: []*string{aws.String(to.String())},
+			},
+			ReplyToAddresses: []*string{aws.String(replyTo)},
+			Message: &ses.Message{
+				Subject: &ses.Content{
+					Charset: aws.String("UTF-8"),
+					Data:    aws.String(message.Subject),
+				},
+				Body: &ses.Body{
+					Html: &ses.Content{
+						Charset: aws.String("UTF-8"),
+						Data:    aws.String(message.Body),
+					},
+				},
+			},
+			Tags: tags,
+		}
+
+		//Bounces, complaints and deliveries are only published to SNS when a configuration set is used
+		if env.Config.Email.SES.ConfigurationSet != "" {
+			input.ConfigurationSetName = aws.String(env.Config.Email.SES.ConfigurationSet)
+		}
+
+		messageID, err := Send(ctx, input)
+		if err != nil {
+			email.LogFailure("ses", 1)
+			panic(errors.Wrap(err, "failed to send email with template %s", c.TemplateName))
+		}
+
+		log.Debugf(ctx, "Email sent with message id @{MessageID}.", dto.Props{
+			"MessageID": messageID,
+		})
+		email.LogDelivery(ctx, "ses", c.TemplateName, messageID, to.Address)
+	}
+}
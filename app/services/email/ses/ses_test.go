@@ -0,0 +1,146 @@
+package ses_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awsses "github.com/aws/aws-sdk-go/service/ses"
+	"github.com/getfider/fider/app"
+
+	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/dto"
+	"github.com/getfider/fider/app/models/query"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/env"
+	"github.com/getfider/fider/app/services/email"
+	"github.com/getfider/fider/app/services/email/ses"
+)
+
+var ctx context.Context
+
+var inputs []*awsses.SendEmailInput
+var suppressed map[string]bool
+var deliveries []*cmd.LogEmailDelivery
+
+func mockSend(ctx context.Context, input *awsses.SendEmailInput) (string, error) {
+	inputs = append(inputs, input)
+	return "0100016c-message-id", nil
+}
+
+func reset() {
+	ctx = context.WithValue(context.Background(), app.TenantCtxKey, &models.Tenant{
+		Subdomain: "got",
+	})
+	env.Config.Email.SES.Region = "us-east-1"
+	env.Config.Email.SES.ConfigurationSet = "fider-events"
+	email.SetWhitelist("")
+
+	ses.Send = mockSend
+	inputs = make([]*awsses.SendEmailInput, 0)
+	bus.Init(ses.Service{})
+
+	suppressed = make(map[string]bool)
+	deliveries = make([]*cmd.LogEmailDelivery, 0)
+	bus.AddHandler(func(ctx context.Context, q *query.IsEmailSuppressed) error {
+		q.Result = suppressed[q.Address]
+		return nil
+	})
+	bus.AddHandler(func(ctx context.Context, c *cmd.LogEmailDelivery) error {
+		deliveries = append(deliveries, c)
+		return nil
+	})
+}
+
+func TestSend_Success(t *testing.T) {
+	RegisterT(t)
+	env.Config.HostMode = "multi"
+	reset()
+
+	bus.Publish(ctx, &cmd.SendMail{
+		From: "Fider Test",
+		To: []dto.Recipient{
+			dto.Recipient{
+				Name:    "Jon Sow",
+				Address: "jon.snow@got.com",
+				ReplyTo: "reply+1.2.3.abc@inbound.got.com",
+			},
+		},
+		TemplateName: "echo_test",
+		Props: dto.Props{
+			"name": "Hello",
+		},
+	})
+
+	Expect(inputs).HasLen(1)
+	Expect(aws.StringValue(inputs[0].Source)).Equals(`"Fider Test" <noreply@random.org>`)
+	Expect(aws.StringValueSlice(inputs[0].Destination.ToAddresses)).Equals([]string{`"Jon Sow" <jon.snow@got.com>`})
+	Expect(aws.StringValueSlice(inputs[0].ReplyToAddresses)).Equals([]string{"reply+1.2.3.abc@inbound.got.com"})
+	Expect(aws.StringValue(inputs[0].Message.Subject.Data)).Equals("Message to: Hello")
+	Expect(aws.StringValue(inputs[0].Message.Body.Html.Data)).ContainsSubstring("Hello World Hello!")
+	Expect(aws.StringValue(inputs[0].ConfigurationSetName)).Equals("fider-events")
+	Expect(inputs[0].Tags).HasLen(2)
+	Expect(aws.StringValue(inputs[0].Tags[0].Value)).Equals("echo_test")
+	Expect(aws.StringValue(inputs[0].Tags[1].Value)).Equals("got")
+
+	Expect(deliveries).HasLen(1)
+	Expect(deliveries[0].Provider).Equals("ses")
+	Expect(deliveries[0].MessageID).Equals("0100016c-message-id")
+	Expect(deliveries[0].Addresses).Equals([]string{"jon.snow@got.com"})
+}
+
+func TestSend_OneRequestPerRecipient(t *testing.T) {
+	RegisterT(t)
+	reset()
+
+	bus.Publish(ctx, &cmd.SendMail{
+		From: "Fider Test",
+		To: []dto.Recipient{
+			dto.Recipient{
+				Name:    "Jon Sow",
+				Address: "jon.snow@got.com",
+				Props: dto.Props{
+					"name": "Jon",
+				},
+			},
+			dto.Recipient{
+				Name:    "Arya Stark",
+				Address: "arya.stark@got.com",
+				Props: dto.Props{
+					"name": "Arya",
+				},
+			},
+		},
+		TemplateName: "echo_test",
+	})
+
+	Expect(inputs).HasLen(2)
+	Expect(aws.StringValue(inputs[0].Message.Subject.Data)).Equals("Message to: Jon")
+	Expect(aws.StringValue(inputs[1].Message.Subject.Data)).Equals("Message to: Arya")
+	Expect(aws.StringValueSlice(inputs[0].ReplyToAddresses)).Equals([]string{"noreply@random.org"})
+}
+
+func TestSend_SkipSuppressedAddress(t *testing.T) {
+	RegisterT(t)
+	reset()
+	suppressed["jon.snow@got.com"] = true
+
+	bus.Publish(ctx, &cmd.SendMail{
+		From: "Fider Test",
+		To: []dto.Recipient{
+			dto.Recipient{
+				Name:    "Jon Sow",
+				Address: "jon.snow@got.com",
+			},
+		},
+		TemplateName: "echo_test",
+		Props: dto.Props{
+			"name": "Hello",
+		},
+	})
+
+	Expect(inputs).HasLen(0)
+	Expect(deliveries).HasLen(0)
+}
@@ -37,7 +37,7 @@ func (s Service) Category() string {
 }
 
 func (s Service) Enabled() bool {
-	return env.Config.Email.Mailgun.APIKey == ""
+	return env.EmailProvider() == "smtp"
 }
 
 func (s Service) Init() {
@@ -60,7 +60,7 @@ func sendMail(ctx context.Context, c *cmd.SendMail) {
 			localname = u.Hostname()
 		}
 
-		if !email.CanSendTo(to.Address) {
+		if !email.CanDeliverTo(ctx, to.Address) {
 			log.Warnf(ctx, "Skipping email to '@{Name} <@{Address}>'.", dto.Props{
 				"Name":    to.Name,
 				"Address": to.Address,
@@ -74,16 +74,22 @@ func sendMail(ctx context.Context, c *cmd.SendMail) {
 			"Props":        to.Props,
 		})
 
-		message := email.RenderMessage(c.TemplateName, c.Props.Merge(to.Props))
+		message := email.RenderMessage(ctx, c.TemplateName, c.Props.Merge(to.Props))
+		replyTo := email.NoReply
+		if to.ReplyTo != "" {
+			replyTo = to.ReplyTo
+		}
+
 		b := builder{}
 		b.Set("From", dto.NewRecipient(c.From, email.NoReply, dto.Props{}).String())
-		b.Set("Reply-To", email.NoReply)
+		b.Set("Reply-To", replyTo)
 		b.Set("To", to.String())
 		b.Set("Subject", message.Subject)
 		b.Set("MIME-version", "1.0")
 		b.Set("Content-Type", "text/html; charset=\"UTF-8\"")
 		b.Set("Date", time.Now().Format(time.RFC1123Z))
-		b.Set("Message-ID", generateMessageID(localname))
+		messageID := generateMessageID(localname)
+		b.Set("Message-ID", messageID)
 		b.Body(message.Body)
 
 		smtpConfig := env.Config.Email.SMTP
@@ -91,9 +97,11 @@ func sendMail(ctx context.Context, c *cmd.SendMail) {
 		auth := authenticate(smtpConfig.Username, smtpConfig.Password, smtpConfig.Host)
 		err = Send(localname, servername, auth, email.NoReply, []string{to.Address}, b.Bytes())
 		if err != nil {
+			email.LogFailure("smtp", 1)
 			panic(errors.Wrap(err, "failed to send email with template %s", c.TemplateName))
 		}
 		log.Debug(ctx, "Email sent.")
+		email.LogDelivery(ctx, "smtp", c.TemplateName, messageID, to.Address)
 	}
 }
 
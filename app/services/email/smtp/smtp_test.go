@@ -11,6 +11,7 @@ import (
 	"github.com/getfider/fider/app/models"
 	"github.com/getfider/fider/app/models/cmd"
 	"github.com/getfider/fider/app/models/dto"
+	"github.com/getfider/fider/app/models/query"
 	. "github.com/getfider/fider/app/pkg/assert"
 	"github.com/getfider/fider/app/pkg/bus"
 	"github.com/getfider/fider/app/services/email"
@@ -27,6 +28,9 @@ type request struct {
 
 var ctx context.Context
 
+var suppressed map[string]bool
+var deliveries []*cmd.LogEmailDelivery
+
 var requests = make([]request, 0)
 
 func mockSend(localname, servername string, auth gosmtp.Auth, from string, to []string, body []byte) error {
@@ -41,6 +45,17 @@ func reset() {
 	smtp.Send = mockSend
 	requests = make([]request, 0)
 	bus.Init(smtp.Service{})
+
+	suppressed = make(map[string]bool)
+	deliveries = make([]*cmd.LogEmailDelivery, 0)
+	bus.AddHandler(func(ctx context.Context, q *query.IsEmailSuppressed) error {
+		q.Result = suppressed[q.Address]
+		return nil
+	})
+	bus.AddHandler(func(ctx context.Context, c *cmd.LogEmailDelivery) error {
+		deliveries = append(deliveries, c)
+		return nil
+	})
 }
 
 func TestSend_Success(t *testing.T) {
@@ -72,6 +87,12 @@ func TestSend_Success(t *testing.T) {
 
 	var validID = regexp.MustCompile(`.*Message-ID: <[a-z0-9\-].*\.[0-9].*@.*>.*`)
 	Expect(validID.MatchString(string(requests[0].body))).IsTrue()
+
+	Expect(deliveries).HasLen(1)
+	Expect(deliveries[0].Provider).Equals("smtp")
+	Expect(deliveries[0].TemplateName).Equals("echo_test")
+	Expect(deliveries[0].Addresses).Equals([]string{"jon.snow@got.com"})
+	Expect(string(requests[0].body)).ContainsSubstring("Message-ID: <" + deliveries[0].MessageID + ">")
 }
 func TestSend_SkipEmptyAddress(t *testing.T) {
 	RegisterT(t)
@@ -116,6 +137,30 @@ func TestSend_SkipUnlistedAddress(t *testing.T) {
 	Expect(requests).HasLen(0)
 }
 
+func TestSend_SkipSuppressedAddress(t *testing.T) {
+	RegisterT(t)
+	reset()
+	email.SetWhitelist("")
+	suppressed["jon.snow@got.com"] = true
+
+	bus.Publish(ctx, &cmd.SendMail{
+		From: "Fider Test",
+		To: []dto.Recipient{
+			dto.Recipient{
+				Name:    "Jon Sow",
+				Address: "jon.snow@got.com",
+			},
+		},
+		TemplateName: "echo_test",
+		Props: dto.Props{
+			"name": "Hello",
+		},
+	})
+
+	Expect(requests).HasLen(0)
+	Expect(deliveries).HasLen(0)
+}
+
 func TestBatch_Success(t *testing.T) {
 	RegisterT(t)
 	reset()
@@ -160,3 +205,27 @@ func TestBatch_Success(t *testing.T) {
 	Expect(string(requests[1].body)).ContainsSubstring("Message-ID: ")
 	Expect(string(requests[1].body)).ContainsSubstring("Hello World Arya!")
 }
+
+func TestSend_WithReplyTo(t *testing.T) {
+	RegisterT(t)
+	reset()
+
+	bus.Publish(ctx, &cmd.SendMail{
+		From: "Fider Test",
+		To: []dto.Recipient{
+			dto.Recipient{
+				Name:    "Jon Sow",
+				Address: "jon.snow@got.com",
+				ReplyTo: "reply+1.2.3.abc@inbound.random.org",
+			},
+		},
+		TemplateName: "echo_test",
+		Props: dto.Props{
+			"name": "Hello",
+		},
+	})
+
+	Expect(requests).HasLen(1)
+	Expect(requests[0].from).Equals("noreply@random.org")
+	Expect(string(requests[0].body)).ContainsSubstring("From: \"Fider Test\" <noreply@random.org>\r\nReply-To: reply+1.2.3.abc@inbound.random.org\r\nTo: \"Jon Sow\" <jon.snow@got.com>\r\n")
+}
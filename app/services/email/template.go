@@ -0,0 +1,296 @@
+package email
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"text/template/parse"
+
+	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/models/dto"
+	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/getfider/fider/app/pkg/i18n"
+)
+
+// Maximum length of customized templates
+const (
+	MaxTemplateSubjectLength = 200
+	MaxTemplateBodyLength    = 20000
+)
+
+//sampleProps returns the props used to preview each template that tenants can customize
+//Templates that are sent before a tenant exists (like signup_email) can't be customized
+var sampleProps = map[string]func(locale, baseURL, tenantName string) dto.Props{
+	"new_post": func(locale, baseURL, tenantName string) dto.Props {
+		return dto.Props{
+			"title":      "Add support for TypeScript",
+			"postLink":   sampleLink(locale, "#1", baseURL, "/posts/1/add-support-for-typescript"),
+			"tenantName": tenantName,
+			"userName":   "Jon Snow",
+			"content":    template.HTML("<p>TypeScript is great, please add support for it.</p>"),
+			"view":       sampleLink(locale, "View it on your browser", baseURL, "/posts/1/add-support-for-typescript"),
+			"change":     sampleLink(locale, "change your notification settings", baseURL, "/settings"),
+			"reply":      false,
+		}
+	},
+	"new_comment": func(locale, baseURL, tenantName string) dto.Props {
+		return dto.Props{
+			"title":       "Add support for TypeScript",
+			"postLink":    sampleLink(locale, "#1", baseURL, "/posts/1/add-support-for-typescript"),
+			"tenantName":  tenantName,
+			"userName":    "Jon Snow",
+			"content":     template.HTML("<p>I agree, this would be great!</p>"),
+			"view":        sampleLink(locale, "View it on your browser", baseURL, "/posts/1/add-support-for-typescript"),
+			"unsubscribe": sampleLink(locale, "unsubscribe from it", baseURL, "/posts/1/add-support-for-typescript"),
+			"change":      sampleLink(locale, "change your notification settings", baseURL, "/settings"),
+			"reply":       false,
+		}
+	},
+	"change_status": func(locale, baseURL, tenantName string) dto.Props {
+		return dto.Props{
+			"title":       "Add support for TypeScript",
+			"postLink":    sampleLink(locale, "#1", baseURL, "/posts/1/add-support-for-typescript"),
+			"tenantName":  tenantName,
+			"content":     template.HTML("<p>We're working on it!</p>"),
+			"status":      "Started",
+			"duplicate":   "",
+			"view":        sampleLink(locale, "View it on your browser", baseURL, "/posts/1/add-support-for-typescript"),
+			"unsubscribe": sampleLink(locale, "unsubscribe from it", baseURL, "/posts/1/add-support-for-typescript"),
+			"change":      sampleLink(locale, "change your notification settings", baseURL, "/settings"),
+			"reply":       false,
+		}
+	},
+	"delete_post": func(locale, baseURL, tenantName string) dto.Props {
+		return dto.Props{
+			"title":      "Add support for TypeScript",
+			"tenantName": tenantName,
+			"content":    template.HTML("<p>This post doesn't follow our guidelines.</p>"),
+			"change":     sampleLink(locale, "change your notification settings", baseURL, "/settings"),
+		}
+	},
+	"digest": func(locale, baseURL, tenantName string) dto.Props {
+		return dto.Props{
+			"tenantName": tenantName,
+			"items": []dto.Props{
+				{
+					"title": template.HTML("New post: <strong>Add support for TypeScript</strong>"),
+					"view":  sampleLink(locale, "View it on your browser", baseURL, "/posts/1/add-support-for-typescript"),
+				},
+				{
+					"title": template.HTML("<strong>Jon Snow</strong> left a comment on <strong>Add support for TypeScript</strong>"),
+					"view":  sampleLink(locale, "View it on your browser", baseURL, "/posts/1/add-support-for-typescript"),
+				},
+			},
+			"change": sampleLink(locale, "change your notification settings", baseURL, "/settings"),
+		}
+	},
+	"invite_email": func(locale, baseURL, tenantName string) dto.Props {
+		return dto.Props{
+			"subject": fmt.Sprintf("Share your ideas and thoughts about %s", tenantName),
+			"message": template.HTML("<p>We would like to hear from you!</p>"),
+		}
+	},
+	"signin_email": func(locale, baseURL, tenantName string) dto.Props {
+		return dto.Props{
+			"tenantName": tenantName,
+			"link":       sampleLink(locale, baseURL+"/signin/verify?k=sample", baseURL, "/signin/verify?k=sample"),
+		}
+	},
+	"change_emailaddress_email": func(locale, baseURL, tenantName string) dto.Props {
+		return dto.Props{
+			"name":     "Jon Snow",
+			"oldEmail": "jon.snow@got.com",
+			"newEmail": "jon.snow@nightswatch.com",
+			"link":     sampleLink(locale, baseURL+"/change-email/verify?k=sample", baseURL, "/change-email/verify?k=sample"),
+		}
+	},
+	"reply_bounce": func(locale, baseURL, tenantName string) dto.Props {
+		return dto.Props{
+			"tenantName": tenantName,
+			"subject":    "Re: [" + tenantName + "] Add support for TypeScript",
+			"reason":     "the post you replied to has been deleted",
+		}
+	},
+}
+
+func sampleLink(locale, text, baseURL, path string) template.HTML {
+	return template.HTML(fmt.Sprintf("<a href='%s%s'>%s</a>", baseURL, path, template.HTMLEscapeString(i18n.T(locale, text))))
+}
+
+// EditableTemplates returns the name of all templates that tenants can customize
+func EditableTemplates() []string {
+	names := make([]string, 0, len(sampleProps))
+	for name := range sampleProps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// IsEditable returns true if tenants can customize given template
+func IsEditable(name string) bool {
+	_, ok := sampleProps[name]
+	return ok
+}
+
+// SampleProps returns fake props used to preview given template
+func SampleProps(locale, name, baseURL, tenantName string) dto.Props {
+	if fn, ok := sampleProps[name]; ok {
+		return fn(locale, baseURL, tenantName)
+	}
+	return dto.Props{}
+}
+
+// DefaultTemplate returns the bundled subject and body of given template, translated to given locale if possible
+func DefaultTemplate(locale, name string) (*models.EmailTemplate, error) {
+	content, err := ioutil.ReadFile(templateFile(locale, name))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read email template '%s'", name)
+	}
+
+	parts := strings.SplitN(string(content), "\n", 3)
+	if len(parts) < 3 || !strings.HasPrefix(parts[0], "subject: ") || parts[1] != "body:" {
+		return nil, errors.New("email template '%s' is not in the expected format", name)
+	}
+
+	variables := make([]string, 0)
+	for key := range SampleProps(locale, name, "", "") {
+		variables = append(variables, key)
+	}
+	sort.Strings(variables)
+
+	return &models.EmailTemplate{
+		Name:      name,
+		Subject:   strings.TrimPrefix(parts[0], "subject: "),
+		Body:      parts[2],
+		Variables: variables,
+	}, nil
+}
+
+//allowedFunctions are the only functions that customized templates can call
+var allowedFunctions = map[string]bool{
+	"and": true,
+	"or":  true,
+	"not": true,
+	"eq":  true,
+	"ne":  true,
+	"len": true,
+}
+
+// ValidateTemplate returns an error if given template can't be used as a customized template
+// Templates are only allowed to read props, use conditionals and loops, and call a few harmless functions
+func ValidateTemplate(text string) error {
+	tpl, err := template.New("template").Parse(text)
+	if err != nil {
+		return err
+	}
+
+	for _, t := range tpl.Templates() {
+		if t.Name() != tpl.Name() {
+			return fmt.Errorf("template definitions are not allowed")
+		}
+		if t.Tree != nil {
+			if err := validateNode(t.Tree.Root); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// RenderTemplate validates and renders given customized template, without falling back to the bundled one
+func RenderTemplate(locale string, custom *models.EmailTemplate, params dto.Props) (*Message, error) {
+	if strings.ContainsAny(custom.Subject, "\r\n") {
+		return nil, fmt.Errorf("subject must be a single line")
+	}
+
+	for _, text := range []string{custom.Subject, custom.Body} {
+		if err := ValidateTemplate(text); err != nil {
+			return nil, err
+		}
+	}
+
+	tpl, err := parseTemplate(custom.Subject, custom.Body)
+	if err != nil {
+		return nil, err
+	}
+	return render(locale, tpl, params)
+}
+
+func validateNode(node parse.Node) error {
+	switch n := node.(type) {
+	case nil:
+		return nil
+	case *parse.ListNode:
+		if n == nil {
+			return nil
+		}
+		for _, child := range n.Nodes {
+			if err := validateNode(child); err != nil {
+				return err
+			}
+		}
+	case *parse.TextNode, *parse.CommentNode:
+		return nil
+	case *parse.ActionNode:
+		return validateNode(n.Pipe)
+	case *parse.IfNode:
+		return validateBranch(&n.BranchNode)
+	case *parse.RangeNode:
+		return validateBranch(&n.BranchNode)
+	case *parse.WithNode:
+		return validateBranch(&n.BranchNode)
+	case *parse.PipeNode:
+		if n == nil {
+			return nil
+		}
+		for _, cmd := range n.Cmds {
+			for _, arg := range cmd.Args {
+				if err := validateNode(arg); err != nil {
+					return err
+				}
+			}
+		}
+	case *parse.IdentifierNode:
+		if !allowedFunctions[n.Ident] {
+			return fmt.Errorf("function '%s' is not allowed", n.Ident)
+		}
+	case *parse.ChainNode:
+		return validateNode(n.Node)
+	case *parse.FieldNode, *parse.VariableNode, *parse.DotNode,
+		*parse.StringNode, *parse.NumberNode, *parse.BoolNode, *parse.NilNode:
+		return nil
+	default:
+		return fmt.Errorf("'%s' is not allowed", node.String())
+	}
+	return nil
+}
+
+func validateBranch(n *parse.BranchNode) error {
+	if err := validateNode(n.Pipe); err != nil {
+		return err
+	}
+	if err := validateNode(n.List); err != nil {
+		return err
+	}
+	return validateNode(n.ElseList)
+}
+
+func parseTemplate(subject, body string) (*template.Template, error) {
+	tpl, err := template.New("email").Parse("subject: " + subject + "\nbody:\n" + body)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse email template")
+	}
+	return tpl, nil
+}
+
+type templateCtxKey struct{}
+
+// WithTemplate returns a context in which given template is used instead of the one stored for the tenant
+// It's used to preview and test changes that have not been saved yet
+func WithTemplate(ctx context.Context, tpl *models.EmailTemplate) context.Context {
+	return context.WithValue(ctx, templateCtxKey{}, tpl)
+}
@@ -0,0 +1,176 @@
+package email_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/models/dto"
+	"github.com/getfider/fider/app/models/query"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/mock"
+	"github.com/getfider/fider/app/services/email"
+
+	. "github.com/getfider/fider/app/pkg/assert"
+)
+
+func TestValidateTemplate_Allowed(t *testing.T) {
+	RegisterT(t)
+
+	for _, text := range []string{
+		"Hello World",
+		"Hello {{ .name }}",
+		"{{ if .reply }}Reply to this email{{ else }}Do not reply{{ end }}",
+		"{{ range .items }}<p>{{ .title }}</p>{{ end }}",
+		"{{ with .user }}{{ .name }}{{ end }}",
+		`{{ if eq .status "Started" }}On it!{{ end }}`,
+		"{{ if and .a (not .b) }}{{ len .items }}{{ end }}",
+		"{{/* a comment */}}",
+	} {
+		Expect(email.ValidateTemplate(text)).IsNil()
+	}
+}
+
+func TestValidateTemplate_NotAllowed(t *testing.T) {
+	RegisterT(t)
+
+	for _, text := range []string{
+		"{{ .name",
+		`{{ printf "%s" .name }}`,
+		"{{ call .fn }}",
+		"{{ html .name }}",
+		`{{ define "other" }}Hello{{ end }}`,
+		`{{ template "other" }}`,
+		`{{ block "other" . }}Hello{{ end }}`,
+	} {
+		Expect(email.ValidateTemplate(text)).IsNotNil()
+	}
+}
+
+func TestDefaultTemplate(t *testing.T) {
+	RegisterT(t)
+
+	template, err := email.DefaultTemplate("en", "invite_email")
+	Expect(err).IsNil()
+	Expect(template.Name).Equals("invite_email")
+	Expect(template.Subject).Equals("{{ .subject }}")
+	Expect(template.Body).Equals("<tr>\n  <td>{{ .message }}</td>\n</tr>")
+	Expect(template.Variables).Equals([]string{"message", "subject"})
+	Expect(template.IsCustomized).IsFalse()
+
+	template, err = email.DefaultTemplate("en", "unknown")
+	Expect(err).IsNotNil()
+	Expect(template).IsNil()
+}
+
+func TestEditableTemplates_HaveDefaults(t *testing.T) {
+	RegisterT(t)
+
+	for _, name := range email.EditableTemplates() {
+		template, err := email.DefaultTemplate("en", name)
+		Expect(err).IsNil()
+
+		message, err := email.RenderTemplate("en", template, email.SampleProps("en", name, "https://demo.test.fider.io", "Demonstration"))
+		Expect(err).IsNil()
+		Expect(message.Subject).IsNotEmpty()
+	}
+
+	Expect(email.IsEditable("signup_email")).IsFalse()
+	Expect(email.IsEditable("echo_test")).IsFalse()
+}
+
+func TestRenderTemplate(t *testing.T) {
+	RegisterT(t)
+
+	message, err := email.RenderTemplate("en", &models.EmailTemplate{
+		Name:    "invite_email",
+		Subject: "Welcome to {{ .tenantName }}",
+		Body:    "<tr><td>{{ .message }}</td></tr>",
+	}, dto.Props{
+		"tenantName": "Demonstration",
+		"message":    "<b>Hi!</b>",
+	})
+	Expect(err).IsNil()
+	Expect(message.Subject).Equals("Welcome to Demonstration")
+	Expect(strings.Contains(message.Body, "<tr><td>&lt;b&gt;Hi!&lt;/b&gt;</td></tr>")).IsTrue()
+}
+
+func TestRenderTemplate_Invalid(t *testing.T) {
+	RegisterT(t)
+
+	message, err := email.RenderTemplate("en", &models.EmailTemplate{
+		Name:    "invite_email",
+		Subject: "Hello\nWorld",
+		Body:    "Hello",
+	}, dto.Props{})
+	Expect(err).IsNotNil()
+	Expect(message).IsNil()
+
+	message, err = email.RenderTemplate("en", &models.EmailTemplate{
+		Name:    "invite_email",
+		Subject: "Hello",
+		Body:    `{{ printf "%s" .message }}`,
+	}, dto.Props{})
+	Expect(err).IsNotNil()
+	Expect(message).IsNil()
+}
+
+func TestRenderMessage_WithTemplate(t *testing.T) {
+	RegisterT(t)
+
+	ctx := email.WithTemplate(context.Background(), &models.EmailTemplate{
+		Name:    "invite_email",
+		Subject: "Custom: {{ .subject }}",
+		Body:    "<tr><td>Custom body</td></tr>",
+	})
+
+	message := email.RenderMessage(ctx, "invite_email", dto.Props{"subject": "Hello"})
+	Expect(message.Subject).Equals("Custom: Hello")
+	Expect(strings.Contains(message.Body, "Custom body")).IsTrue()
+
+	message = email.RenderMessage(ctx, "echo_test", dto.Props{"name": "Fider"})
+	Expect(message.Subject).Equals("Message to: Fider")
+}
+
+func TestRenderMessage_CustomizedByTenant(t *testing.T) {
+	RegisterT(t)
+	mock.NewWorker()
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetCustomEmailTemplate) error {
+		if q.Name != "invite_email" {
+			return app.ErrNotFound
+		}
+		q.Result = &models.EmailTemplate{
+			Name:         "invite_email",
+			Subject:      "Tenant: {{ .subject }}",
+			Body:         "<tr><td>Tenant body</td></tr>",
+			IsCustomized: true,
+		}
+		return nil
+	})
+
+	ctx := context.WithValue(context.Background(), app.TenantCtxKey, mock.DemoTenant)
+
+	message := email.RenderMessage(ctx, "invite_email", dto.Props{"subject": "Hello"})
+	Expect(message.Subject).Equals("Tenant: Hello")
+	Expect(strings.Contains(message.Body, "Tenant body")).IsTrue()
+
+	message = email.RenderMessage(ctx, "signin_email", dto.Props{"tenantName": "Demonstration"})
+	Expect(message.Subject).Equals("Sign in to Demonstration")
+}
+
+func TestRenderMessage_FallbackOnError(t *testing.T) {
+	RegisterT(t)
+
+	ctx := email.WithTemplate(context.Background(), &models.EmailTemplate{
+		Name:    "invite_email",
+		Subject: "Custom",
+		Body:    "{{ range .subject }}{{ end }}",
+	})
+
+	message := email.RenderMessage(ctx, "invite_email", dto.Props{"subject": "Hello", "message": "Hi"})
+	Expect(message.Subject).Equals("Hello")
+	Expect(strings.Contains(message.Body, "<td>Hi</td>")).IsTrue()
+}
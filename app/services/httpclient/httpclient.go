@@ -2,20 +2,58 @@ package httpclient
 
 import (
 	"context"
+	"fmt"
 	"io/ioutil"
+	"net"
 	"net/http"
+	"net/url"
+	"syscall"
 	"time"
 
 	"github.com/getfider/fider/app/models/cmd"
 	"github.com/getfider/fider/app/pkg/bus"
 	"github.com/getfider/fider/app/pkg/env"
+	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/getfider/fider/app/pkg/trace"
+	"github.com/getfider/fider/app/pkg/validate"
 )
 
+var client = newClient()
+
 func init() {
 	http.DefaultClient.Timeout = 30 * time.Second
 	bus.Register(Service{})
 }
 
+func newClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   denyInternalAddress,
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	return &http.Client{Timeout: 30 * time.Second, Transport: transport}
+}
+
+//denyInternalAddress blocks connections to loopback, link-local and private addresses on multi host mode
+//It runs after the host is resolved, so hosts that change their DNS records after being validated are also blocked
+func denyInternalAddress(network, address string, conn syscall.RawConn) error {
+	if env.IsSingleHostMode() {
+		return nil
+	}
+
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	if ip := net.ParseIP(host); ip == nil || !validate.IsPublicIP(ip) {
+		return errors.New("connection to internal address '%s' is not allowed", host)
+	}
+	return nil
+}
+
 type Service struct{}
 
 func (s Service) Name() string {
@@ -34,11 +72,27 @@ func (s Service) Init() {
 	bus.AddHandler(requestHandler)
 }
 
-func requestHandler(ctx context.Context, c *cmd.HTTPRequest) error {
+func requestHandler(ctx context.Context, c *cmd.HTTPRequest) (err error) {
 	req, err := http.NewRequest(c.Method, c.URL, c.Body)
 	if err != nil {
 		return err
 	}
+
+	ctx, span := trace.Start(ctx, "HTTP "+c.Method, trace.KindClient)
+	defer func() {
+		span.SetAttribute("http.status_code", c.ResponseStatusCode)
+		if err == nil && c.ResponseStatusCode >= http.StatusBadRequest {
+			span.End(fmt.Errorf("%d %s", c.ResponseStatusCode, http.StatusText(c.ResponseStatusCode)))
+		} else {
+			span.End(err)
+		}
+	}()
+	if span != nil {
+		span.SetAttribute("http.method", c.Method)
+		//Query string is left out as it may contain credentials
+		span.SetAttribute("http.url", (&url.URL{Scheme: req.URL.Scheme, Host: req.URL.Host, Path: req.URL.Path}).String())
+		req.Header.Set(trace.HeaderName, span.SpanContext().TraceParent())
+	}
 	req = req.WithContext(ctx)
 
 	for k, v := range c.Headers {
@@ -48,7 +102,7 @@ func requestHandler(ctx context.Context, c *cmd.HTTPRequest) error {
 		req.SetBasicAuth(c.BasicAuth.User, c.BasicAuth.Password)
 	}
 
-	res, err := http.DefaultClient.Do(req)
+	res, err := client.Do(req)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,33 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/getfider/fider/app/models/cmd"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/env"
+)
+
+func TestRequestHandler_InternalAddress(t *testing.T) {
+	RegisterT(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	env.Config.HostMode = "multi"
+	req := &cmd.HTTPRequest{Method: "GET", URL: server.URL}
+	err := requestHandler(context.Background(), req)
+	Expect(err).IsNotNil()
+	Expect(err.Error()).ContainsSubstring("connection to internal address '127.0.0.1' is not allowed")
+
+	env.Config.HostMode = "single"
+	req = &cmd.HTTPRequest{Method: "GET", URL: server.URL}
+	err = requestHandler(context.Background(), req)
+	Expect(err).IsNil()
+	Expect(req.ResponseStatusCode).Equals(http.StatusNoContent)
+}
@@ -31,7 +31,7 @@ func (s Service) Category() string {
 }
 
 func (s Service) Enabled() bool {
-	return !env.IsTest()
+	return !env.IsTest() && env.IsLogSinkEnabled("console")
 }
 
 func (s Service) Init() {
@@ -64,7 +64,12 @@ func logError(ctx context.Context, c *cmd.LogError) {
 }
 
 func writeLog(ctx context.Context, level log.Level, message string, props dto.Props) {
-	if !log.IsEnabled(level) {
+	if !log.IsEnabledFor(ctx, level) {
+		return
+	}
+
+	if log.IsJSONFormat() {
+		stdOut.Println(log.NewEntry(ctx, level, message, props).JSON())
 		return
 	}
 
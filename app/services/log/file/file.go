@@ -0,0 +1,82 @@
+package file
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/dto"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/env"
+	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/getfider/fider/app/pkg/log"
+)
+
+var output *rotatingFile
+
+func init() {
+	bus.Register(Service{})
+}
+
+type Service struct{}
+
+func (s Service) Name() string {
+	return "File"
+}
+
+func (s Service) Category() string {
+	return "log"
+}
+
+func (s Service) Enabled() bool {
+	return !env.IsTest() && env.IsLogSinkEnabled("file")
+}
+
+func (s Service) Init() {
+	config := env.Config.Log.File
+	file, err := openRotatingFile(config.Path, int64(config.MaxSizeMB)*1024*1024, config.MaxBackups)
+	if err != nil {
+		panic(errors.Wrap(err, "failed to open log file '%s'", config.Path))
+	}
+	output = file
+
+	bus.AddListener(logDebug)
+	bus.AddListener(logWarn)
+	bus.AddListener(logInfo)
+	bus.AddListener(logError)
+}
+
+func logDebug(ctx context.Context, c *cmd.LogDebug) {
+	writeLog(ctx, log.DEBUG, c.Message, c.Props)
+}
+
+func logWarn(ctx context.Context, c *cmd.LogWarn) {
+	writeLog(ctx, log.WARN, c.Message, c.Props)
+}
+
+func logInfo(ctx context.Context, c *cmd.LogInfo) {
+	writeLog(ctx, log.INFO, c.Message, c.Props)
+}
+
+func logError(ctx context.Context, c *cmd.LogError) {
+	if c.Err != nil {
+		writeLog(ctx, log.ERROR, c.Err.Error(), c.Props)
+	} else if c.Message != "" {
+		writeLog(ctx, log.ERROR, c.Message, c.Props)
+	} else {
+		writeLog(ctx, log.ERROR, "nil", c.Props)
+	}
+}
+
+func writeLog(ctx context.Context, level log.Level, message string, props dto.Props) {
+	if !log.IsEnabledFor(ctx, level) {
+		return
+	}
+
+	line := log.NewEntry(ctx, level, message, props).Format() + "\n"
+	if _, err := output.Write([]byte(line)); err != nil {
+		//Logging can't fail, so the entry goes to stderr instead of being lost
+		fmt.Fprintf(os.Stderr, "failed to write log file: %v\n%s", err, line)
+	}
+}
@@ -0,0 +1,112 @@
+package file
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+//rotatingFile is a file that is renamed to path.1 once it reaches its maximum size, shifting older files up to the maximum number of backups
+type rotatingFile struct {
+	lock       sync.Mutex
+	path       string
+	maxSize    int64
+	maxBackups int
+	file       *os.File
+	size       int64
+}
+
+func openRotatingFile(path string, maxSize int64, maxBackups int) (*rotatingFile, error) {
+	r := &rotatingFile{
+		path:       path,
+		maxSize:    maxSize,
+		maxBackups: maxBackups,
+	}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *rotatingFile) open() error {
+	if err := os.MkdirAll(filepath.Dir(r.path), 0755); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(r.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	r.file = file
+	r.size = info.Size()
+	return nil
+}
+
+//Write appends given data to the file, rotating it first if data doesn't fit
+func (r *rotatingFile) Write(data []byte) (int, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.file == nil {
+		if err := r.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	if r.maxSize > 0 && r.size > 0 && r.size+int64(len(data)) > r.maxSize {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := r.file.Write(data)
+	r.size += int64(n)
+	return n, err
+}
+
+//Close closes current file
+func (r *rotatingFile) Close() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file = nil
+	return err
+}
+
+func (r *rotatingFile) rotate() error {
+	if err := r.file.Close(); err != nil {
+		return err
+	}
+	r.file = nil
+
+	if r.maxBackups > 0 {
+		//Missing backups are expected until there are enough rotations, so errors are ignored
+		_ = os.Remove(r.backup(r.maxBackups))
+		for i := r.maxBackups - 1; i >= 1; i-- {
+			_ = os.Rename(r.backup(i), r.backup(i+1))
+		}
+		if err := os.Rename(r.path, r.backup(1)); err != nil {
+			return err
+		}
+	} else if err := os.Remove(r.path); err != nil {
+		return err
+	}
+
+	return r.open()
+}
+
+func (r *rotatingFile) backup(index int) string {
+	return fmt.Sprintf("%s.%d", r.path, index)
+}
@@ -0,0 +1,82 @@
+package file
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/getfider/fider/app/pkg/assert"
+)
+
+func readFile(path string) string {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return string(content)
+}
+
+func TestRotatingFile(t *testing.T) {
+	RegisterT(t)
+
+	dir, err := ioutil.TempDir("", "fider-logs")
+	Expect(err).IsNil()
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "logs", "fider.log")
+	file, err := openRotatingFile(path, 10, 2)
+	Expect(err).IsNil()
+
+	for _, line := range []string{"line 1\n", "line 2\n", "line 3\n", "line 4\n"} {
+		n, err := file.Write([]byte(line))
+		Expect(err).IsNil()
+		Expect(n).Equals(len(line))
+	}
+	Expect(file.Close()).IsNil()
+
+	Expect(readFile(path)).Equals("line 4\n")
+	Expect(readFile(path + ".1")).Equals("line 3\n")
+	Expect(readFile(path + ".2")).Equals("line 2\n")
+	_, err = os.Stat(path + ".3")
+	Expect(os.IsNotExist(err)).IsTrue()
+}
+
+func TestRotatingFile_AppendsToExistingFile(t *testing.T) {
+	RegisterT(t)
+
+	dir, err := ioutil.TempDir("", "fider-logs")
+	Expect(err).IsNil()
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "fider.log")
+	_ = ioutil.WriteFile(path, []byte("old\n"), 0644)
+
+	file, err := openRotatingFile(path, 10, 1)
+	Expect(err).IsNil()
+	_, _ = file.Write([]byte("new\n"))
+	_, _ = file.Write([]byte("newer\n"))
+	Expect(file.Close()).IsNil()
+
+	Expect(readFile(path + ".1")).Equals("old\nnew\n")
+	Expect(readFile(path)).Equals("newer\n")
+}
+
+func TestRotatingFile_WithoutBackups(t *testing.T) {
+	RegisterT(t)
+
+	dir, err := ioutil.TempDir("", "fider-logs")
+	Expect(err).IsNil()
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "fider.log")
+	file, err := openRotatingFile(path, 10, 0)
+	Expect(err).IsNil()
+	_, _ = file.Write([]byte("line 1\n"))
+	_, _ = file.Write([]byte("line 2\n"))
+	Expect(file.Close()).IsNil()
+
+	Expect(readFile(path)).Equals("line 2\n")
+	_, err = os.Stat(path + ".1")
+	Expect(os.IsNotExist(err)).IsTrue()
+}
@@ -27,7 +27,7 @@ func (s Service) Category() string {
 }
 
 func (s Service) Enabled() bool {
-	return !env.IsTest()
+	return !env.IsTest() && env.IsLogSinkEnabled("sql")
 }
 
 func (s Service) Init() {
@@ -60,7 +60,7 @@ func logError(ctx context.Context, c *cmd.LogError) {
 }
 
 func writeLog(ctx context.Context, level log.Level, message string, props dto.Props) {
-	if !log.IsEnabled(level) {
+	if !log.IsEnabledFor(ctx, level) {
 		return
 	}
 
@@ -0,0 +1,17 @@
+// +build !windows
+
+package syslog
+
+import (
+	stdSyslog "log/syslog"
+	"strings"
+)
+
+//dial connects to the syslog daemon on given address, such as udp://localhost:514, or to the local daemon when it's empty
+func dial(address, tag string) (writer, error) {
+	network := ""
+	if parts := strings.SplitN(address, "://", 2); len(parts) == 2 {
+		network, address = parts[0], parts[1]
+	}
+	return stdSyslog.Dial(network, address, stdSyslog.LOG_INFO|stdSyslog.LOG_DAEMON, tag)
+}
@@ -0,0 +1,11 @@
+// +build windows
+
+package syslog
+
+import (
+	"errors"
+)
+
+func dial(address, tag string) (writer, error) {
+	return nil, errors.New("syslog is not supported on Windows")
+}
@@ -0,0 +1,112 @@
+package syslog
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/dto"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/env"
+	"github.com/getfider/fider/app/pkg/log"
+)
+
+//writer is a connection to a syslog daemon, with one method per severity
+type writer interface {
+	Debug(message string) error
+	Info(message string) error
+	Warning(message string) error
+	Err(message string) error
+	Close() error
+}
+
+var (
+	connLock sync.Mutex
+	conn     writer
+)
+
+func init() {
+	bus.Register(Service{})
+}
+
+type Service struct{}
+
+func (s Service) Name() string {
+	return "Syslog"
+}
+
+func (s Service) Category() string {
+	return "log"
+}
+
+func (s Service) Enabled() bool {
+	return !env.IsTest() && env.IsLogSinkEnabled("syslog")
+}
+
+func (s Service) Init() {
+	bus.AddListener(logDebug)
+	bus.AddListener(logWarn)
+	bus.AddListener(logInfo)
+	bus.AddListener(logError)
+}
+
+func logDebug(ctx context.Context, c *cmd.LogDebug) {
+	writeLog(ctx, log.DEBUG, c.Message, c.Props)
+}
+
+func logWarn(ctx context.Context, c *cmd.LogWarn) {
+	writeLog(ctx, log.WARN, c.Message, c.Props)
+}
+
+func logInfo(ctx context.Context, c *cmd.LogInfo) {
+	writeLog(ctx, log.INFO, c.Message, c.Props)
+}
+
+func logError(ctx context.Context, c *cmd.LogError) {
+	if c.Err != nil {
+		writeLog(ctx, log.ERROR, c.Err.Error(), c.Props)
+	} else if c.Message != "" {
+		writeLog(ctx, log.ERROR, c.Message, c.Props)
+	} else {
+		writeLog(ctx, log.ERROR, "nil", c.Props)
+	}
+}
+
+func writeLog(ctx context.Context, level log.Level, message string, props dto.Props) {
+	if !log.IsEnabledFor(ctx, level) {
+		return
+	}
+
+	line := log.NewEntry(ctx, level, message, props).Format()
+	if err := send(level, line); err != nil {
+		//Logging can't fail, so the entry goes to stderr instead of being lost
+		fmt.Fprintf(os.Stderr, "failed to write to syslog: %v\n%s\n", err, line)
+	}
+}
+
+//send writes given line with the severity of given level, connecting to the daemon on first use so that Fider can start while it's unavailable
+func send(level log.Level, line string) error {
+	connLock.Lock()
+	defer connLock.Unlock()
+
+	if conn == nil {
+		w, err := dial(env.Config.Log.Syslog.Address, env.Config.Log.Syslog.Tag)
+		if err != nil {
+			return err
+		}
+		conn = w
+	}
+
+	switch level {
+	case log.DEBUG:
+		return conn.Debug(line)
+	case log.INFO:
+		return conn.Info(line)
+	case log.WARN:
+		return conn.Warning(line)
+	default:
+		return conn.Err(line)
+	}
+}
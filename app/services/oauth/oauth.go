@@ -111,6 +111,15 @@ func parseOAuthRawProfile(ctx context.Context, c *cmd.ParseOAuthRawProfile) erro
 		return err
 	}
 
+	if config.Protocol == enum.OAuthProtocolOIDC {
+		profile, err := parseOIDCProfile(c.Body)
+		if err != nil {
+			return err
+		}
+		c.Result = profile
+		return nil
+	}
+
 	query := jsonq.New(c.Body)
 	profile := &dto.OAuthUserProfile{
 		ID:    strings.TrimSpace(query.String(config.JSONUserIDPath)),
@@ -153,6 +162,23 @@ func getOAuthAuthorizationURL(ctx context.Context, q *query.GetOAuthAuthorizatio
 	parameters.Add("redirect_uri", fmt.Sprintf("%s/oauth/%s/callback", oauthBaseURL, q.Provider))
 	parameters.Add("response_type", "code")
 	parameters.Add("state", q.Redirect+"|"+q.Identifier)
+
+	if config.Protocol == enum.OAuthProtocolOIDC {
+		discovery, err := getOIDCDiscovery(ctx, config.IssuerURL)
+		if err != nil {
+			return err
+		}
+
+		authURL, err = url.Parse(discovery.AuthorizationEndpoint)
+		if err != nil {
+			return errors.Wrap(err, "failed to parse authorization endpoint")
+		}
+		parameters.Set("scope", oidcScope(config.Scope))
+		parameters.Add("nonce", oidcNonce(q.Provider, q.Identifier))
+		parameters.Add("code_challenge", oidcCodeChallenge(oidcCodeVerifier(q.Provider, q.Identifier)))
+		parameters.Add("code_challenge_method", "S256")
+	}
+
 	authURL.RawQuery = parameters.Encode()
 	q.Result = authURL.String()
 	return nil
@@ -168,7 +194,7 @@ func getOAuthProfile(ctx context.Context, q *query.GetOAuthProfile) error {
 		return errors.New("Provider %s is disabled", q.Provider)
 	}
 
-	rawProfile := &query.GetOAuthRawProfile{Provider: q.Provider, Code: q.Code}
+	rawProfile := &query.GetOAuthRawProfile{Provider: q.Provider, Code: q.Code, Identifier: q.Identifier}
 	err = bus.Dispatch(ctx, rawProfile)
 	if err != nil {
 		return err
@@ -190,6 +216,10 @@ func getOAuthRawProfile(ctx context.Context, q *query.GetOAuthRawProfile) error
 		return err
 	}
 
+	if config.Protocol == enum.OAuthProtocolOIDC {
+		return getOIDCRawProfile(ctx, config, q)
+	}
+
 	oauthBaseURL := web.OAuthBaseURL(ctx)
 	exchange := (&oauth2.Config{
 		ClientID:     config.ClientID,
@@ -250,6 +280,21 @@ func listActiveOAuthProviders(ctx context.Context, q *query.ListActiveOAuthProvi
 			list = append(list, p)
 		}
 	}
+
+	samlConfig := &query.GetSAMLConfig{}
+	err = bus.Dispatch(ctx, samlConfig)
+	if err != nil && errors.Cause(err) != app.ErrNotFound {
+		return err
+	}
+	if err == nil && samlConfig.Result.IsEnabled {
+		list = append(list, &dto.OAuthProviderOption{
+			Provider:    "_saml",
+			DisplayName: samlConfig.Result.DisplayName,
+			URL:         "/saml/login",
+			IsEnabled:   true,
+		})
+	}
+
 	q.Result = list
 	return nil
 }
@@ -402,3 +402,98 @@ func TestCustomOAuth_Disabled(t *testing.T) {
 	Expect(err).IsNotNil()
 	Expect(oauthProfile.Result).IsNil()
 }
+
+func TestGetAuthURL_OIDC(t *testing.T) {
+	RegisterT(t)
+	bus.Init(&oauth.Service{})
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetCustomOAuthConfigByProvider) error {
+		if q.Provider == "_oidc" {
+			q.Result = &models.OAuthConfig{
+				Provider:  q.Provider,
+				Protocol:  enum.OAuthProtocolOIDC,
+				ClientID:  "OIDC_CL_ID",
+				Scope:     "profile email",
+				IssuerURL: "https://auth.example.org",
+			}
+		}
+		return nil
+	})
+
+	bus.AddHandler(func(ctx context.Context, c *cmd.HTTPRequest) error {
+		Expect(c.URL).Equals("https://auth.example.org/.well-known/openid-configuration")
+		c.ResponseStatusCode = 200
+		c.ResponseBody = []byte(`{
+			"issuer": "https://auth.example.org",
+			"authorization_endpoint": "https://auth.example.org/authorize",
+			"token_endpoint": "https://auth.example.org/token",
+			"jwks_uri": "https://auth.example.org/jwks"
+		}`)
+		return nil
+	})
+
+	ctx := newGetContext("http://login.test.fider.io:3000")
+	authURL := &query.GetOAuthAuthorizationURL{
+		Provider:   "_oidc",
+		Redirect:   "http://example.org",
+		Identifier: "456",
+	}
+
+	err := bus.Dispatch(ctx, authURL)
+	Expect(err).IsNil()
+
+	u, err := url.Parse(authURL.Result)
+	Expect(err).IsNil()
+	Expect(u.Host).Equals("auth.example.org")
+	Expect(u.Path).Equals("/authorize")
+	Expect(u.Query().Get("client_id")).Equals("OIDC_CL_ID")
+	Expect(u.Query().Get("scope")).Equals("openid profile email")
+	Expect(u.Query().Get("state")).Equals("http://example.org|456")
+	Expect(u.Query().Get("code_challenge_method")).Equals("S256")
+	Expect(u.Query().Get("code_challenge")).IsNotEmpty()
+	Expect(u.Query().Get("nonce")).IsNotEmpty()
+}
+
+func TestParseProfileResponse_OIDC(t *testing.T) {
+	RegisterT(t)
+	bus.Init(&oauth.Service{})
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetCustomOAuthConfigByProvider) error {
+		q.Result = &models.OAuthConfig{
+			Provider: q.Provider,
+			Protocol: enum.OAuthProtocolOIDC,
+		}
+		return nil
+	})
+
+	ctx := newGetContext("http://login.test.fider.io:3000")
+	profile := &cmd.ParseOAuthRawProfile{
+		Provider: "_oidc",
+		Body:     `{"sub":"248289761001","given_name":"Jon","family_name":"Snow","email":"jon@got.com","email_verified":true,"picture":"https://example.org/jon.png"}`,
+	}
+
+	err := bus.Dispatch(ctx, profile)
+	Expect(err).IsNil()
+	Expect(profile.Result.ID).Equals("248289761001")
+	Expect(profile.Result.Name).Equals("Jon Snow")
+	Expect(profile.Result.Email).Equals("jon@got.com")
+	Expect(profile.Result.AvatarURL).Equals("https://example.org/jon.png")
+
+	profile = &cmd.ParseOAuthRawProfile{
+		Provider: "_oidc",
+		Body:     `{"sub":"248289761001","preferred_username":"jon","email":"jon@got.com","email_verified":false}`,
+	}
+
+	err = bus.Dispatch(ctx, profile)
+	Expect(err).IsNil()
+	Expect(profile.Result.Name).Equals("jon")
+	Expect(profile.Result.Email).Equals("")
+
+	profile = &cmd.ParseOAuthRawProfile{
+		Provider: "_oidc",
+		Body:     `{"name":"Jon Snow"}`,
+	}
+
+	err = bus.Dispatch(ctx, profile)
+	Expect(errors.Cause(err)).Equals(app.ErrUserIDRequired)
+}
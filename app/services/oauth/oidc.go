@@ -0,0 +1,409 @@
+package oauth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/dto"
+	"github.com/getfider/fider/app/models/query"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/env"
+	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/getfider/fider/app/pkg/validate"
+	"github.com/getfider/fider/app/pkg/web"
+	"golang.org/x/oauth2"
+)
+
+const oidcCacheDuration = 1 * time.Hour
+
+var oidcSigningMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}
+
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserInfoEndpoint      string `json:"userinfo_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+
+	keys      map[string]interface{}
+	expiresAt time.Time
+}
+
+type oidcClaims struct {
+	Subject           string      `json:"sub"`
+	Name              string      `json:"name"`
+	PreferredUsername string      `json:"preferred_username"`
+	GivenName         string      `json:"given_name"`
+	FamilyName        string      `json:"family_name"`
+	Email             string      `json:"email"`
+	EmailVerified     interface{} `json:"email_verified"`
+	Picture           string      `json:"picture"`
+}
+
+var oidcCache = struct {
+	sync.Mutex
+	discoveries map[string]*oidcDiscovery
+}{
+	discoveries: make(map[string]*oidcDiscovery),
+}
+
+//oidcSecret derives a value that only this server can compute for given sign in attempt,
+//so that PKCE code verifier and nonce don't need to be stored between redirects
+func oidcSecret(kind, provider, identifier string) string {
+	mac := hmac.New(sha256.New, []byte(env.Config.JWTSecret))
+	mac.Write([]byte(kind + "|" + provider + "|" + identifier))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func oidcCodeVerifier(provider, identifier string) string {
+	return oidcSecret("code_verifier", provider, identifier)
+}
+
+func oidcCodeChallenge(verifier string) string {
+	hash := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(hash[:])
+}
+
+func oidcNonce(provider, identifier string) string {
+	return oidcSecret("nonce", provider, identifier)
+}
+
+func oidcScope(scope string) string {
+	if scope == "" {
+		scope = "openid profile email"
+	}
+	for _, s := range strings.Fields(scope) {
+		if s == "openid" {
+			return scope
+		}
+	}
+	return "openid " + scope
+}
+
+func fetchJSON(ctx context.Context, url string, headers map[string]string, target interface{}) error {
+	req := &cmd.HTTPRequest{
+		URL:     url,
+		Method:  "GET",
+		Headers: headers,
+	}
+
+	if err := bus.Dispatch(ctx, req); err != nil {
+		return err
+	}
+
+	if req.ResponseStatusCode != 200 {
+		return errors.New("Failed to request '%s'. Status Code: %d. Body: %s", url, req.ResponseStatusCode, string(req.ResponseBody))
+	}
+
+	if err := json.Unmarshal(req.ResponseBody, target); err != nil {
+		return errors.Wrap(err, "failed to parse response from '%s'", url)
+	}
+	return nil
+}
+
+//getOIDCDiscovery returns the provider metadata published on .well-known/openid-configuration
+func getOIDCDiscovery(ctx context.Context, issuerURL string) (*oidcDiscovery, error) {
+	issuerURL = strings.TrimSuffix(issuerURL, "/")
+
+	oidcCache.Lock()
+	cached, ok := oidcCache.discoveries[issuerURL]
+	oidcCache.Unlock()
+	if ok && time.Now().Before(cached.expiresAt) {
+		return cached, nil
+	}
+
+	discovery := &oidcDiscovery{}
+	if err := fetchJSON(ctx, issuerURL+"/.well-known/openid-configuration", nil, discovery); err != nil {
+		return nil, errors.Wrap(err, "failed to get OpenID Connect discovery document")
+	}
+
+	if strings.TrimSuffix(discovery.Issuer, "/") != issuerURL {
+		return nil, errors.New("OpenID Connect issuer '%s' doesn't match '%s'", discovery.Issuer, issuerURL)
+	}
+
+	if discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" || discovery.JWKSURI == "" {
+		return nil, errors.New("OpenID Connect discovery document of '%s' is incomplete", issuerURL)
+	}
+
+	discovery.expiresAt = time.Now().Add(oidcCacheDuration)
+	oidcCache.Lock()
+	oidcCache.discoveries[issuerURL] = discovery
+	oidcCache.Unlock()
+	return discovery, nil
+}
+
+//getOIDCKey returns the public key used to sign id_tokens with given key id
+//Keys are fetched again when the key id is unknown, so that keys can be rotated by the provider
+func getOIDCKey(ctx context.Context, discovery *oidcDiscovery, kid string) (interface{}, error) {
+	oidcCache.Lock()
+	key, ok := discovery.keys[kid]
+	oidcCache.Unlock()
+	if ok {
+		return key, nil
+	}
+
+	keys, err := fetchJWKS(ctx, discovery.JWKSURI)
+	if err != nil {
+		return nil, err
+	}
+
+	oidcCache.Lock()
+	discovery.keys = keys
+	oidcCache.Unlock()
+
+	if key, ok := keys[kid]; ok {
+		return key, nil
+	}
+
+	//Providers with a single key might not set the key id
+	if kid == "" && len(keys) == 1 {
+		for _, key := range keys {
+			return key, nil
+		}
+	}
+
+	return nil, errors.New("OpenID Connect signing key '%s' not found", kid)
+}
+
+func fetchJWKS(ctx context.Context, url string) (map[string]interface{}, error) {
+	jwks := struct {
+		Keys []struct {
+			Kid string `json:"kid"`
+			Kty string `json:"kty"`
+			Use string `json:"use"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+			Crv string `json:"crv"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+		} `json:"keys"`
+	}{}
+
+	if err := fetchJSON(ctx, url, nil, &jwks); err != nil {
+		return nil, errors.Wrap(err, "failed to get OpenID Connect signing keys")
+	}
+
+	keys := make(map[string]interface{})
+	for _, k := range jwks.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		switch k.Kty {
+		case "RSA":
+			n, errN := base64.RawURLEncoding.DecodeString(k.N)
+			e, errE := base64.RawURLEncoding.DecodeString(k.E)
+			if errN != nil || errE != nil {
+				continue
+			}
+			keys[k.Kid] = &rsa.PublicKey{
+				N: new(big.Int).SetBytes(n),
+				E: int(new(big.Int).SetBytes(e).Int64()),
+			}
+		case "EC":
+			var curve elliptic.Curve
+			switch k.Crv {
+			case "P-256":
+				curve = elliptic.P256()
+			case "P-384":
+				curve = elliptic.P384()
+			case "P-521":
+				curve = elliptic.P521()
+			default:
+				continue
+			}
+			x, errX := base64.RawURLEncoding.DecodeString(k.X)
+			y, errY := base64.RawURLEncoding.DecodeString(k.Y)
+			if errX != nil || errY != nil {
+				continue
+			}
+			keys[k.Kid] = &ecdsa.PublicKey{
+				Curve: curve,
+				X:     new(big.Int).SetBytes(x),
+				Y:     new(big.Int).SetBytes(y),
+			}
+		}
+	}
+
+	return keys, nil
+}
+
+//verifyIDToken checks signature, issuer, audience, expiration and nonce of an id_token
+func verifyIDToken(ctx context.Context, config *models.OAuthConfig, discovery *oidcDiscovery, rawIDToken, nonce string) (jwt.MapClaims, error) {
+	parser := &jwt.Parser{ValidMethods: oidcSigningMethods}
+	token, err := parser.Parse(rawIDToken, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return getOIDCKey(ctx, discovery, kid)
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to verify id_token")
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return nil, errors.New("id_token is invalid")
+	}
+
+	if !claims.VerifyIssuer(discovery.Issuer, true) {
+		return nil, errors.New("id_token was issued by '%v' instead of '%s'", claims["iss"], discovery.Issuer)
+	}
+
+	if _, ok := claims["exp"]; !ok {
+		return nil, errors.New("id_token has no expiration")
+	}
+
+	audiences := make([]string, 0)
+	switch aud := claims["aud"].(type) {
+	case string:
+		audiences = append(audiences, aud)
+	case []interface{}:
+		for _, a := range aud {
+			if s, ok := a.(string); ok {
+				audiences = append(audiences, s)
+			}
+		}
+	}
+
+	hasAudience := false
+	for _, aud := range audiences {
+		hasAudience = hasAudience || aud == config.ClientID
+	}
+	if !hasAudience {
+		return nil, errors.New("id_token audience doesn't include '%s'", config.ClientID)
+	}
+
+	if azp, ok := claims["azp"].(string); ok && len(audiences) > 1 && azp != config.ClientID {
+		return nil, errors.New("id_token was authorized for '%s' instead of '%s'", azp, config.ClientID)
+	}
+
+	if claimedNonce, _ := claims["nonce"].(string); !hmac.Equal([]byte(claimedNonce), []byte(nonce)) {
+		return nil, errors.New("id_token nonce doesn't match")
+	}
+
+	return claims, nil
+}
+
+//parseOIDCProfile maps standard OpenID Connect claims into a user profile
+func parseOIDCProfile(body string) (*dto.OAuthUserProfile, error) {
+	claims := &oidcClaims{}
+	if err := json.Unmarshal([]byte(body), claims); err != nil {
+		return nil, errors.Wrap(err, "failed to parse OpenID Connect claims")
+	}
+
+	profile := &dto.OAuthUserProfile{
+		ID:        strings.TrimSpace(claims.Subject),
+		Name:      strings.TrimSpace(claims.Name),
+		Email:     strings.ToLower(strings.TrimSpace(claims.Email)),
+		AvatarURL: strings.TrimSpace(claims.Picture),
+	}
+
+	if profile.ID == "" {
+		return nil, app.ErrUserIDRequired
+	}
+
+	if profile.Name == "" {
+		profile.Name = strings.TrimSpace(claims.GivenName + " " + claims.FamilyName)
+	}
+
+	if profile.Name == "" {
+		profile.Name = strings.TrimSpace(claims.PreferredUsername)
+	}
+
+	//Unverified emails can't be trusted, as they'd allow anyone to sign in as an existing user
+	if verified, ok := claims.EmailVerified.(bool); ok && !verified {
+		profile.Email = ""
+	} else if s, ok := claims.EmailVerified.(string); ok && s == "false" {
+		profile.Email = ""
+	}
+
+	if profile.Name == "" && profile.Email != "" {
+		parts := strings.Split(profile.Email, "@")
+		profile.Name = parts[0]
+	}
+
+	if profile.Name == "" {
+		profile.Name = "Anonymous"
+	}
+
+	if len(validate.Email(profile.Email)) != 0 {
+		profile.Email = ""
+	}
+
+	return profile, nil
+}
+
+//getOIDCRawProfile exchanges the code using PKCE and returns the claims of the verified id_token as JSON
+//Claims missing from the id_token are completed with the ones from userinfo endpoint
+func getOIDCRawProfile(ctx context.Context, config *models.OAuthConfig, q *query.GetOAuthRawProfile) error {
+	discovery, err := getOIDCDiscovery(ctx, config.IssuerURL)
+	if err != nil {
+		return err
+	}
+
+	oauthBaseURL := web.OAuthBaseURL(ctx)
+	oauthToken, err := (&oauth2.Config{
+		ClientID:     config.ClientID,
+		ClientSecret: config.ClientSecret,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  discovery.AuthorizationEndpoint,
+			TokenURL: discovery.TokenEndpoint,
+		},
+		RedirectURL: fmt.Sprintf("%s/oauth/%s/callback", oauthBaseURL, q.Provider),
+	}).Exchange(ctx, q.Code, oauth2.SetAuthURLParam("code_verifier", oidcCodeVerifier(q.Provider, q.Identifier)))
+	if err != nil {
+		return err
+	}
+
+	rawIDToken, ok := oauthToken.Extra("id_token").(string)
+	if !ok || rawIDToken == "" {
+		return errors.New("OpenID Connect token response has no id_token")
+	}
+
+	claims, err := verifyIDToken(ctx, config, discovery, rawIDToken, oidcNonce(q.Provider, q.Identifier))
+	if err != nil {
+		return err
+	}
+
+	if discovery.UserInfoEndpoint != "" && (claims["email"] == nil || claims["name"] == nil) {
+		userInfo := make(map[string]interface{})
+		err := fetchJSON(ctx, discovery.UserInfoEndpoint, map[string]string{
+			"Authorization": "Bearer " + oauthToken.AccessToken,
+		}, &userInfo)
+		if err != nil {
+			return err
+		}
+
+		if userInfo["sub"] != claims["sub"] {
+			return errors.New("OpenID Connect userinfo subject doesn't match id_token")
+		}
+
+		for key, value := range userInfo {
+			if _, ok := claims[key]; !ok {
+				claims[key] = value
+			}
+		}
+	}
+
+	body, err := json.Marshal(claims)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal OpenID Connect claims")
+	}
+
+	q.Result = string(body)
+	return nil
+}
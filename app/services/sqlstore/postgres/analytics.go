@@ -0,0 +1,372 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/models/query"
+	"github.com/getfider/fider/app/pkg/dbx"
+	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/lib/pq"
+	cache "github.com/patrickmn/go-cache"
+)
+
+//Reports aggregate the whole activity of a tenant, so they are cached instead of being computed on every request
+var analyticsCache = cache.New(10*time.Minute, 30*time.Minute)
+
+//analyticsTopPostsLimit is the number of posts ranked on top voted and trending lists
+const analyticsTopPostsLimit = 10
+
+type dbAnalyticsInterval struct {
+	Date time.Time `db:"date"`
+}
+
+type dbAnalyticsCount struct {
+	Key   string    `db:"key"`
+	Date  time.Time `db:"date"`
+	Count int       `db:"count"`
+}
+
+type dbAnalyticsPost struct {
+	Number   int    `db:"number"`
+	Title    string `db:"title"`
+	Slug     string `db:"slug"`
+	Status   int    `db:"status"`
+	Votes    int    `db:"votes"`
+	Comments int    `db:"comments"`
+}
+
+func (p *dbAnalyticsPost) toModel() *models.AnalyticsPost {
+	return &models.AnalyticsPost{
+		Number:   p.Number,
+		Title:    p.Title,
+		Slug:     p.Slug,
+		Status:   enum.PostStatus(p.Status),
+		Votes:    p.Votes,
+		Comments: p.Comments,
+	}
+}
+
+type dbAnalyticsResponseTime struct {
+	Responded      int     `db:"responded"`
+	Unresponded    int     `db:"unresponded"`
+	AverageSeconds float64 `db:"average_seconds"`
+	MedianSeconds  float64 `db:"median_seconds"`
+}
+
+type dbAnalyticsTag struct {
+	Name     string `db:"name"`
+	Slug     string `db:"slug"`
+	Color    string `db:"color"`
+	Posts    int    `db:"posts"`
+	Votes    int    `db:"votes"`
+	Comments int    `db:"comments"`
+}
+
+func getAnalytics(ctx context.Context, q *query.GetAnalytics) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *models.Tenant, user *models.User) error {
+		if q.Interval == "" {
+			q.Interval = "day"
+		}
+		if q.Until.IsZero() {
+			q.Until = time.Now().UTC().Truncate(24 * time.Hour).Add(24 * time.Hour)
+		}
+		if q.Since.IsZero() {
+			q.Since = q.Until.AddDate(0, 0, -30)
+		}
+
+		key := fmt.Sprintf("%d:%s:%s:%s", tenant.ID, q.Interval, q.Since.UTC().Format(time.RFC3339), q.Until.UTC().Format(time.RFC3339))
+		if cached, found := analyticsCache.Get(key); found {
+			q.Result = cached.(*models.Analytics)
+			return nil
+		}
+
+		series, err := getAnalyticsSeries(trx, tenant, q)
+		if err != nil {
+			return err
+		}
+
+		topVoted, err := getAnalyticsPosts(trx, tenant, q, "", "votes DESC")
+		if err != nil {
+			return errors.Wrap(err, "failed to get top voted posts")
+		}
+
+		trending, err := getAnalyticsPosts(trx, tenant, q, "AND p.created_at >= $2", "votes + comments DESC")
+		if err != nil {
+			return errors.Wrap(err, "failed to get trending posts")
+		}
+
+		responseTime, err := getAnalyticsResponseTime(trx, tenant, q)
+		if err != nil {
+			return err
+		}
+
+		tags, err := getAnalyticsTags(trx, tenant, q)
+		if err != nil {
+			return err
+		}
+
+		q.Result = &models.Analytics{
+			Since:        q.Since,
+			Until:        q.Until,
+			Interval:     q.Interval,
+			Series:       series,
+			TopVoted:     topVoted,
+			Trending:     trending,
+			ResponseTime: responseTime,
+			Tags:         tags,
+		}
+		analyticsCache.SetDefault(key, q.Result)
+		return nil
+	})
+}
+
+func getAnalyticsSeries(trx *dbx.Trx, tenant *models.Tenant, q *query.GetAnalytics) (*models.AnalyticsSeries, error) {
+	intervals := []*dbAnalyticsInterval{}
+	err := trx.Select(&intervals, `
+		SELECT generate_series(date_trunc($1, $2::timestamptz), $3::timestamptz - INTERVAL '1 microsecond', ('1 ' || $1)::interval) AS date
+	`, q.Interval, q.Since, q.Until)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get analytics intervals")
+	}
+
+	dates := make([]time.Time, len(intervals))
+	for i, interval := range intervals {
+		dates[i] = interval.Date
+	}
+
+	//Each source returns one row per activity with its key, the user who performed it and when it happened
+	periodFilter := "tenant_id = $1 AND created_at >= $3 AND created_at < $4"
+	postsSource := "SELECT '' AS key, user_id, created_at AS at FROM posts WHERE status <> " + strconv.Itoa(int(enum.PostDeleted)) + " AND " + periodFilter
+	votesSource := "SELECT '' AS key, user_id, created_at AS at FROM post_votes WHERE " + periodFilter
+	commentsSource := "SELECT '' AS key, user_id, created_at AS at FROM comments WHERE deleted_at IS NULL AND " + periodFilter
+	eventsSource := "SELECT name AS key, user_id, created_at AS at FROM events WHERE " + periodFilter
+
+	series := &models.AnalyticsSeries{
+		StatusChanges: make(map[enum.PostStatus][]*models.AnalyticsPoint),
+		Events:        make(map[string][]*models.AnalyticsPoint),
+	}
+
+	metrics := []struct {
+		name   string
+		count  string
+		source string
+		points *[]*models.AnalyticsPoint
+	}{
+		{"posts", "COUNT(*)", postsSource, &series.Posts},
+		{"votes", "COUNT(*)", votesSource, &series.Votes},
+		{"comments", "COUNT(*)", commentsSource, &series.Comments},
+		{"active users", "COUNT(DISTINCT user_id)", fmt.Sprintf(
+			"%s UNION ALL %s UNION ALL %s UNION ALL SELECT '' AS key, user_id, created_at AS at FROM events WHERE user_id IS NOT NULL AND %s",
+			postsSource, votesSource, commentsSource, periodFilter,
+		), &series.ActiveUsers},
+		{"sign ups", "COUNT(*)", "SELECT '' AS key, id AS user_id, created_at AS at FROM users WHERE " + periodFilter, &series.SignUps},
+	}
+
+	for _, metric := range metrics {
+		counts, err := countPerInterval(trx, tenant, q, metric.count, metric.source)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to count %s per interval", metric.name)
+		}
+		*metric.points = toAnalyticsPoints(dates, counts[""])
+	}
+
+	//The first entry of each post history is its creation, not a transition
+	statusChanges, err := countPerInterval(trx, tenant, q, "COUNT(*)", `
+		SELECT h.status::text AS key, h.changed_by_id AS user_id, h.changed_at AS at
+		FROM post_status_history h
+		WHERE h.tenant_id = $1 AND h.changed_at >= $3 AND h.changed_at < $4
+		AND h.id > (SELECT MIN(f.id) FROM post_status_history f WHERE f.tenant_id = h.tenant_id AND f.post_id = h.post_id)
+	`)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to count status changes per interval")
+	}
+	for key, counts := range statusChanges {
+		status, err := strconv.Atoi(key)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse post status '%s'", key)
+		}
+		series.StatusChanges[enum.PostStatus(status)] = toAnalyticsPoints(dates, counts)
+	}
+
+	events, err := countPerInterval(trx, tenant, q, "COUNT(*)", eventsSource)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to count events per interval")
+	}
+	for name, counts := range events {
+		series.Events[name] = toAnalyticsPoints(dates, counts)
+	}
+
+	return series, nil
+}
+
+//countPerInterval aggregates the rows of given source by key and interval
+func countPerInterval(trx *dbx.Trx, tenant *models.Tenant, q *query.GetAnalytics, count, source string) (map[string]map[int64]int, error) {
+	rows := []*dbAnalyticsCount{}
+	err := trx.Select(&rows, fmt.Sprintf(`
+		SELECT key, date_trunc($2, at) AS date, %s AS count
+		FROM (%s) AS activity
+		GROUP BY key, date
+	`, count, source), tenant.ID, q.Interval, q.Since, q.Until)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]map[int64]int)
+	for _, row := range rows {
+		if _, ok := result[row.Key]; !ok {
+			result[row.Key] = make(map[int64]int)
+		}
+		result[row.Key][row.Date.Unix()] = row.Count
+	}
+	return result, nil
+}
+
+//toAnalyticsPoints fills intervals without activity with zero so that all series have the same length
+func toAnalyticsPoints(dates []time.Time, counts map[int64]int) []*models.AnalyticsPoint {
+	points := make([]*models.AnalyticsPoint, len(dates))
+	for i, date := range dates {
+		points[i] = &models.AnalyticsPoint{Date: date, Count: counts[date.Unix()]}
+	}
+	return points
+}
+
+func getAnalyticsPosts(trx *dbx.Trx, tenant *models.Tenant, q *query.GetAnalytics, condition, order string) ([]*models.AnalyticsPost, error) {
+	posts := []*dbAnalyticsPost{}
+	err := trx.Select(&posts, fmt.Sprintf(`
+		WITH activity AS (
+			SELECT post_id, COUNT(*) AS votes, 0 AS comments
+			FROM post_votes
+			WHERE tenant_id = $1 AND created_at >= $2 AND created_at < $3
+			GROUP BY post_id
+			UNION ALL
+			SELECT post_id, 0 AS votes, COUNT(*) AS comments
+			FROM comments
+			WHERE tenant_id = $1 AND created_at >= $2 AND created_at < $3 AND deleted_at IS NULL
+			GROUP BY post_id
+		)
+		SELECT p.number, p.title, p.slug, p.status, votes, comments
+		FROM (
+			SELECT post_id, SUM(votes)::int AS votes, SUM(comments)::int AS comments
+			FROM activity
+			GROUP BY post_id
+		) AS a
+		INNER JOIN posts p
+		ON p.id = a.post_id
+		AND p.tenant_id = $1
+		WHERE p.status <> %d %s
+		ORDER BY %s, p.id DESC
+		LIMIT %d
+	`, enum.PostDeleted, condition, order, analyticsTopPostsLimit), tenant.ID, q.Since, q.Until)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*models.AnalyticsPost, len(posts))
+	for i, post := range posts {
+		result[i] = post.toModel()
+	}
+	return result, nil
+}
+
+//getAnalyticsResponseTime considers a post responded on the first comment or status change made by a staff member other than its author
+func getAnalyticsResponseTime(trx *dbx.Trx, tenant *models.Tenant, q *query.GetAnalytics) (*models.AnalyticsResponseTime, error) {
+	responseTime := dbAnalyticsResponseTime{}
+	err := trx.Get(&responseTime, `
+		WITH responses AS (
+			SELECT p.created_at, LEAST(
+				(
+					SELECT MIN(c.created_at)
+					FROM comments c
+					INNER JOIN users u
+					ON u.id = c.user_id
+					AND u.tenant_id = c.tenant_id
+					WHERE c.tenant_id = p.tenant_id AND c.post_id = p.id AND c.user_id <> p.user_id AND u.role = ANY($4)
+				),
+				(
+					SELECT MIN(h.changed_at)
+					FROM post_status_history h
+					INNER JOIN users u
+					ON u.id = h.changed_by_id
+					AND u.tenant_id = h.tenant_id
+					WHERE h.tenant_id = p.tenant_id AND h.post_id = p.id AND h.status <> $5 AND u.role = ANY($4)
+				)
+			) AS responded_at
+			FROM posts p
+			WHERE p.tenant_id = $1 AND p.created_at >= $2 AND p.created_at < $3 AND p.status <> $6
+		)
+		SELECT
+			COUNT(responded_at) AS responded,
+			COUNT(*) - COUNT(responded_at) AS unresponded,
+			COALESCE(AVG(EXTRACT(EPOCH FROM responded_at - created_at)), 0) AS average_seconds,
+			COALESCE(percentile_cont(0.5) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM responded_at - created_at)), 0) AS median_seconds
+		FROM responses
+	`, tenant.ID, q.Since, q.Until, pq.Array([]int{int(enum.RoleCollaborator), int(enum.RoleAdministrator)}), enum.PostOpen, enum.PostDeleted)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get response time")
+	}
+
+	return &models.AnalyticsResponseTime{
+		Responded:      responseTime.Responded,
+		Unresponded:    responseTime.Unresponded,
+		AverageSeconds: responseTime.AverageSeconds,
+		MedianSeconds:  responseTime.MedianSeconds,
+	}, nil
+}
+
+func getAnalyticsTags(trx *dbx.Trx, tenant *models.Tenant, q *query.GetAnalytics) ([]*models.AnalyticsTag, error) {
+	tags := []*dbAnalyticsTag{}
+	err := trx.Select(&tags, `
+		SELECT t.name, t.slug, t.color,
+		(
+			SELECT COUNT(*)
+			FROM post_tags pt
+			INNER JOIN posts p
+			ON p.id = pt.post_id
+			AND p.tenant_id = pt.tenant_id
+			WHERE pt.tenant_id = t.tenant_id AND pt.tag_id = t.id AND p.status <> $4
+			AND p.created_at >= $2 AND p.created_at < $3
+		) AS posts,
+		(
+			SELECT COUNT(*)
+			FROM post_tags pt
+			INNER JOIN post_votes v
+			ON v.post_id = pt.post_id
+			AND v.tenant_id = pt.tenant_id
+			WHERE pt.tenant_id = t.tenant_id AND pt.tag_id = t.id
+			AND v.created_at >= $2 AND v.created_at < $3
+		) AS votes,
+		(
+			SELECT COUNT(*)
+			FROM post_tags pt
+			INNER JOIN comments c
+			ON c.post_id = pt.post_id
+			AND c.tenant_id = pt.tenant_id
+			WHERE pt.tenant_id = t.tenant_id AND pt.tag_id = t.id AND c.deleted_at IS NULL
+			AND c.created_at >= $2 AND c.created_at < $3
+		) AS comments
+		FROM tags t
+		WHERE t.tenant_id = $1
+		ORDER BY posts DESC, votes DESC, t.name
+	`, tenant.ID, q.Since, q.Until, enum.PostDeleted)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get tag breakdown")
+	}
+
+	result := make([]*models.AnalyticsTag, len(tags))
+	for i, tag := range tags {
+		result[i] = &models.AnalyticsTag{
+			Name:     tag.Name,
+			Slug:     tag.Slug,
+			Color:    tag.Color,
+			Posts:    tag.Posts,
+			Votes:    tag.Votes,
+			Comments: tag.Comments,
+		}
+	}
+	return result, nil
+}
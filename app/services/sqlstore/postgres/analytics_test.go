@@ -0,0 +1,112 @@
+package postgres_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/models/query"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/bus"
+)
+
+func sumAnalyticsPoints(points []*models.AnalyticsPoint) int {
+	sum := 0
+	for _, point := range points {
+		sum += point.Count
+	}
+	return sum
+}
+
+func TestAnalyticsStorage_GetAnalytics(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	newPost := &cmd.AddNewPost{Title: "Add dark mode", Description: "Please"}
+	err := bus.Dispatch(aryaStarkCtx, newPost)
+	Expect(err).IsNil()
+
+	addNewTag := &cmd.AddNewTag{Name: "Feature Request", Color: "FF0000", IsPublic: true}
+	err = bus.Dispatch(jonSnowCtx, addNewTag)
+	Expect(err).IsNil()
+
+	err = bus.Dispatch(jonSnowCtx,
+		&cmd.AssignTag{Tag: addNewTag.Result, Post: newPost.Result},
+		&cmd.AddVote{Post: newPost.Result, User: aryaStark},
+		&cmd.AddVote{Post: newPost.Result, User: jonSnow},
+		&cmd.AddNewComment{Post: newPost.Result, Content: "Good idea!"},
+		&cmd.SetPostResponse{Post: newPost.Result, Text: "Coming soon", Status: enum.PostPlanned},
+		&cmd.StoreEvent{EventName: "posts.view"},
+	)
+	Expect(err).IsNil()
+
+	now := time.Now()
+	getAnalytics := &query.GetAnalytics{
+		Since:    now.AddDate(0, 0, -1),
+		Until:    now.AddDate(0, 0, 1),
+		Interval: "day",
+	}
+	err = bus.Dispatch(demoTenantCtx, getAnalytics)
+	Expect(err).IsNil()
+
+	analytics := getAnalytics.Result
+	Expect(analytics.Series.Posts).HasLen(3)
+	Expect(sumAnalyticsPoints(analytics.Series.Posts)).Equals(1)
+	Expect(sumAnalyticsPoints(analytics.Series.Votes)).Equals(2)
+	Expect(sumAnalyticsPoints(analytics.Series.Comments)).Equals(1)
+	Expect(sumAnalyticsPoints(analytics.Series.ActiveUsers) >= 2).IsTrue()
+	Expect(sumAnalyticsPoints(analytics.Series.StatusChanges[enum.PostPlanned])).Equals(1)
+	Expect(sumAnalyticsPoints(analytics.Series.Events["posts.view"])).Equals(1)
+
+	Expect(analytics.TopVoted).HasLen(1)
+	Expect(analytics.TopVoted[0].Number).Equals(newPost.Result.Number)
+	Expect(analytics.TopVoted[0].Votes).Equals(2)
+	Expect(analytics.TopVoted[0].Comments).Equals(1)
+	Expect(analytics.Trending).HasLen(1)
+
+	Expect(analytics.ResponseTime.Responded).Equals(1)
+	Expect(analytics.ResponseTime.Unresponded).Equals(0)
+
+	Expect(analytics.Tags[0].Slug).Equals("feature-request")
+	Expect(analytics.Tags[0].Posts).Equals(1)
+	Expect(analytics.Tags[0].Votes).Equals(2)
+	Expect(analytics.Tags[0].Comments).Equals(1)
+}
+
+func TestAnalyticsStorage_GetAnalytics_Cached(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	newPost := &cmd.AddNewPost{Title: "Add dark mode", Description: "Please"}
+	err := bus.Dispatch(aryaStarkCtx, newPost)
+	Expect(err).IsNil()
+
+	until := time.Now().AddDate(0, 0, 2)
+	getAnalytics := &query.GetAnalytics{Since: until.AddDate(0, -1, 0), Until: until, Interval: "week"}
+	err = bus.Dispatch(demoTenantCtx, getAnalytics)
+	Expect(err).IsNil()
+	Expect(sumAnalyticsPoints(getAnalytics.Result.Series.Posts)).Equals(1)
+
+	err = bus.Dispatch(jonSnowCtx, &cmd.AddNewPost{Title: "Add light mode", Description: "Please"})
+	Expect(err).IsNil()
+
+	getAnalytics = &query.GetAnalytics{Since: until.AddDate(0, -1, 0), Until: until, Interval: "week"}
+	err = bus.Dispatch(demoTenantCtx, getAnalytics)
+	Expect(err).IsNil()
+	Expect(sumAnalyticsPoints(getAnalytics.Result.Series.Posts)).Equals(1)
+}
+
+func TestAnalyticsStorage_GetAnalytics_Defaults(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	getAnalytics := &query.GetAnalytics{}
+	err := bus.Dispatch(avengersTenantCtx, getAnalytics)
+	Expect(err).IsNil()
+	Expect(getAnalytics.Result.Interval).Equals("day")
+	Expect(getAnalytics.Result.Series.Posts).HasLen(30)
+	Expect(getAnalytics.Result.TopVoted).HasLen(0)
+	Expect(getAnalytics.Result.ResponseTime.Responded).Equals(0)
+}
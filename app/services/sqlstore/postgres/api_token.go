@@ -0,0 +1,212 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/models/query"
+	"github.com/getfider/fider/app/pkg/crypto"
+	"github.com/getfider/fider/app/pkg/dbx"
+	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/lib/pq"
+)
+
+type dbAPIToken struct {
+	ID         int          `db:"id"`
+	Name       string       `db:"name"`
+	User       *dbUser      `db:"user"`
+	Scopes     []string     `db:"scopes"`
+	ExpiresAt  dbx.NullTime `db:"expires_at"`
+	LastUsedAt dbx.NullTime `db:"last_used_at"`
+	CreatedAt  time.Time    `db:"created_at"`
+}
+
+func (t *dbAPIToken) toModel(ctx context.Context) *models.APIToken {
+	scopes := make([]enum.APIScope, len(t.Scopes))
+	for i, scope := range t.Scopes {
+		scopes[i] = enum.APIScope(scope)
+	}
+
+	token := &models.APIToken{
+		ID:        t.ID,
+		Name:      t.Name,
+		User:      t.User.toModel(ctx),
+		Scopes:    scopes,
+		CreatedAt: t.CreatedAt,
+	}
+	if t.ExpiresAt.Valid {
+		token.ExpiresAt = &t.ExpiresAt.Time
+	}
+	if t.LastUsedAt.Valid {
+		token.LastUsedAt = &t.LastUsedAt.Time
+	}
+	return token
+}
+
+func apiScopesToArray(scopes []enum.APIScope) interface{} {
+	values := make([]string, len(scopes))
+	for i, scope := range scopes {
+		values[i] = string(scope)
+	}
+	return pq.Array(values)
+}
+
+const selectAPITokenSQL = `
+	SELECT t.id, t.name, t.scopes, t.expires_at, t.last_used_at, t.created_at,
+				 u.id AS user_id,
+				 u.name AS user_name,
+				 u.email AS user_email,
+				 u.role AS user_role,
+				 u.status AS user_status,
+				 u.avatar_type AS user_avatar_type,
+				 u.avatar_bkey AS user_avatar_bkey
+	FROM api_tokens t
+	INNER JOIN users u
+	ON u.id = t.user_id
+	AND u.tenant_id = t.tenant_id
+`
+
+func getAPITokenByKey(ctx context.Context, q *query.GetAPITokenByKey) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *models.Tenant, user *models.User) error {
+		token := dbAPIToken{}
+		err := trx.Get(&token, selectAPITokenSQL+`
+			WHERE t.key_hash = $1 AND t.tenant_id = $2 AND t.revoked_at IS NULL
+		`, crypto.SHA256(q.Key), tenant.ID)
+		if err != nil {
+			return errors.Wrap(err, "failed to get API Token by key")
+		}
+
+		q.Result = token.toModel(ctx)
+
+		//Token owner is loaded with tenant and providers, just like any other authenticated user
+		owner, err := queryUser(ctx, trx, "id = $1 AND tenant_id = $2", q.Result.User.ID, tenant.ID)
+		if err != nil {
+			return errors.Wrap(err, "failed to get owner of API Token with id '%d'", q.Result.ID)
+		}
+		q.Result.User = owner
+		return nil
+	})
+}
+
+func getAPITokenByID(ctx context.Context, q *query.GetAPITokenByID) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *models.Tenant, user *models.User) error {
+		token := dbAPIToken{}
+		err := trx.Get(&token, selectAPITokenSQL+`
+			WHERE t.id = $1 AND t.tenant_id = $2 AND t.revoked_at IS NULL
+		`, q.TokenID, tenant.ID)
+		if err != nil {
+			return errors.Wrap(err, "failed to get API Token with id '%d'", q.TokenID)
+		}
+
+		q.Result = token.toModel(ctx)
+		return nil
+	})
+}
+
+func listCurrentUserAPITokens(ctx context.Context, q *query.ListCurrentUserAPITokens) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *models.Tenant, user *models.User) error {
+		if user == nil {
+			q.Result = make([]*models.APIToken, 0)
+			return nil
+		}
+
+		tokens, err := queryAPITokens(ctx, trx, selectAPITokenSQL+`
+			WHERE t.tenant_id = $1 AND t.user_id = $2 AND t.revoked_at IS NULL
+			ORDER BY t.id
+		`, tenant.ID, user.ID)
+		if err != nil {
+			return errors.Wrap(err, "failed to list API Tokens of current user")
+		}
+
+		q.Result = tokens
+		return nil
+	})
+}
+
+func listAllAPITokens(ctx context.Context, q *query.ListAllAPITokens) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *models.Tenant, user *models.User) error {
+		tokens, err := queryAPITokens(ctx, trx, selectAPITokenSQL+`
+			WHERE t.tenant_id = $1 AND t.revoked_at IS NULL
+			ORDER BY t.id
+		`, tenant.ID)
+		if err != nil {
+			return errors.Wrap(err, "failed to list API Tokens")
+		}
+
+		q.Result = tokens
+		return nil
+	})
+}
+
+func createAPIToken(ctx context.Context, c *cmd.CreateAPIToken) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *models.Tenant, user *models.User) error {
+		var expiresAt dbx.NullTime
+		if c.ExpiresAt != nil {
+			expiresAt.Time = *c.ExpiresAt
+			expiresAt.Valid = true
+		}
+
+		var id int
+		err := trx.Scalar(&id, `
+			INSERT INTO api_tokens (tenant_id, user_id, name, key_hash, scopes, expires_at, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			RETURNING id
+		`, tenant.ID, user.ID, c.Name, crypto.SHA256(c.Key), apiScopesToArray(c.Scopes), expiresAt, time.Now())
+		if err != nil {
+			return errors.Wrap(err, "failed to create API Token")
+		}
+
+		getToken := &query.GetAPITokenByID{TokenID: id}
+		if err := getAPITokenByID(ctx, getToken); err != nil {
+			return err
+		}
+		c.Result = getToken.Result
+		return nil
+	})
+}
+
+func revokeAPIToken(ctx context.Context, c *cmd.RevokeAPIToken) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *models.Tenant, user *models.User) error {
+		_, err := trx.Execute(`
+			UPDATE api_tokens SET revoked_at = $1
+			WHERE id = $2 AND tenant_id = $3 AND revoked_at IS NULL
+		`, time.Now(), c.TokenID, tenant.ID)
+		if err != nil {
+			return errors.Wrap(err, "failed to revoke API Token with id '%d'", c.TokenID)
+		}
+		return nil
+	})
+}
+
+func markAPITokenAsUsed(ctx context.Context, c *cmd.MarkAPITokenAsUsed) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *models.Tenant, user *models.User) error {
+		now := time.Now()
+
+		//Only one update per minute, to avoid writing on every single API request
+		_, err := trx.Execute(`
+			UPDATE api_tokens SET last_used_at = $1
+			WHERE id = $2 AND tenant_id = $3 AND (last_used_at IS NULL OR last_used_at < $4)
+		`, now, c.TokenID, tenant.ID, now.Add(-1*time.Minute))
+		if err != nil {
+			return errors.Wrap(err, "failed to mark API Token with id '%d' as used", c.TokenID)
+		}
+		return nil
+	})
+}
+
+func queryAPITokens(ctx context.Context, trx *dbx.Trx, query string, args ...interface{}) ([]*models.APIToken, error) {
+	tokens := []*dbAPIToken{}
+	err := trx.Select(&tokens, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var result = make([]*models.APIToken, len(tokens))
+	for i, token := range tokens {
+		result[i] = token.toModel(ctx)
+	}
+	return result, nil
+}
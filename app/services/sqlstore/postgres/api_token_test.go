@@ -0,0 +1,88 @@
+package postgres_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/models/query"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/errors"
+)
+
+func TestAPITokenStorage_CreateAndGetByKey(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	expiresAt := time.Now().Add(24 * time.Hour)
+	createToken := &cmd.CreateAPIToken{
+		Name:      "CI Bot",
+		Key:       "fider_my-secret-key",
+		Scopes:    []enum.APIScope{enum.APIScopePostsRead, enum.APIScopeCommentsWrite},
+		ExpiresAt: &expiresAt,
+	}
+	err := bus.Dispatch(jonSnowCtx, createToken)
+	Expect(err).IsNil()
+	Expect(createToken.Result.ID).NotEquals(0)
+	Expect(createToken.Result.Name).Equals("CI Bot")
+	Expect(createToken.Result.User.ID).Equals(jonSnow.ID)
+	Expect(createToken.Result.ExpiresAt).IsNotNil()
+	Expect(createToken.Result.LastUsedAt).IsNil()
+
+	getByKey := &query.GetAPITokenByKey{Key: "fider_my-secret-key"}
+	err = bus.Dispatch(demoTenantCtx, getByKey)
+	Expect(err).IsNil()
+	Expect(getByKey.Result.ID).Equals(createToken.Result.ID)
+	Expect(getByKey.Result.User.ID).Equals(jonSnow.ID)
+	Expect(getByKey.Result.User.Tenant.ID).Equals(demoTenant.ID)
+	Expect(getByKey.Result.HasScope(enum.APIScopeCommentsWrite)).IsTrue()
+	Expect(getByKey.Result.HasScope(enum.APIScopePostsWrite)).IsFalse()
+
+	getByKey = &query.GetAPITokenByKey{Key: "fider_my-secret-key"}
+	err = bus.Dispatch(avengersTenantCtx, getByKey)
+	Expect(errors.Cause(err)).Equals(app.ErrNotFound)
+
+	err = bus.Dispatch(demoTenantCtx, &cmd.MarkAPITokenAsUsed{TokenID: createToken.Result.ID})
+	Expect(err).IsNil()
+
+	getByID := &query.GetAPITokenByID{TokenID: createToken.Result.ID}
+	err = bus.Dispatch(demoTenantCtx, getByID)
+	Expect(err).IsNil()
+	Expect(getByID.Result.LastUsedAt).IsNotNil()
+}
+
+func TestAPITokenStorage_ListAndRevoke(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	bus.MustDispatch(jonSnowCtx, &cmd.CreateAPIToken{Name: "Jon's Bot", Key: "fider_jon", Scopes: []enum.APIScope{enum.APIScopePostsRead}})
+	aryaToken := &cmd.CreateAPIToken{Name: "Arya's Bot", Key: "fider_arya", Scopes: []enum.APIScope{enum.APIScopeVotesWrite}}
+	bus.MustDispatch(aryaStarkCtx, aryaToken)
+
+	listMine := &query.ListCurrentUserAPITokens{}
+	err := bus.Dispatch(aryaStarkCtx, listMine)
+	Expect(err).IsNil()
+	Expect(listMine.Result).HasLen(1)
+	Expect(listMine.Result[0].Name).Equals("Arya's Bot")
+
+	listAll := &query.ListAllAPITokens{}
+	err = bus.Dispatch(demoTenantCtx, listAll)
+	Expect(err).IsNil()
+	Expect(listAll.Result).HasLen(2)
+
+	err = bus.Dispatch(demoTenantCtx, &cmd.RevokeAPIToken{TokenID: aryaToken.Result.ID})
+	Expect(err).IsNil()
+
+	listAll = &query.ListAllAPITokens{}
+	err = bus.Dispatch(demoTenantCtx, listAll)
+	Expect(err).IsNil()
+	Expect(listAll.Result).HasLen(1)
+	Expect(listAll.Result[0].Name).Equals("Jon's Bot")
+
+	getByKey := &query.GetAPITokenByKey{Key: "fider_arya"}
+	err = bus.Dispatch(demoTenantCtx, getByKey)
+	Expect(errors.Cause(err)).Equals(app.ErrNotFound)
+}
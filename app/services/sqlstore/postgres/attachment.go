@@ -11,6 +11,7 @@ import (
 	"github.com/getfider/fider/app/pkg/bus"
 	"github.com/getfider/fider/app/pkg/dbx"
 	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/getfider/fider/app/pkg/images"
 	"github.com/getfider/fider/app/pkg/rand"
 	"github.com/getfider/fider/app/services/blob"
 )
@@ -86,15 +87,49 @@ func getAttachments(ctx context.Context, q *query.GetAttachments) error {
 
 func uploadImage(ctx context.Context, c *cmd.UploadImage) error {
 	if c.Image.Upload != nil && len(c.Image.Upload.Content) > 0 {
-		bkey := fmt.Sprintf("%s/%s-%s", c.Folder, rand.String(64), blob.SanitizeFileName(c.Image.Upload.FileName))
-		err := bus.Dispatch(ctx, &cmd.StoreBlob{
+		upload := c.Image.Upload
+		img, err := images.Normalize(upload.Content)
+		if err != nil && err != images.ErrNotSupported {
+			return errors.Wrap(err, "failed to normalize image")
+		}
+
+		if img != nil {
+			upload.Content = img.Content
+			upload.ContentType = img.ContentType
+		}
+
+		bkey := fmt.Sprintf("%s/%s-%s", c.Folder, rand.String(64), blob.SanitizeFileName(upload.FileName))
+		err = bus.Dispatch(ctx, &cmd.StoreBlob{
 			Key:         bkey,
-			Content:     c.Image.Upload.Content,
-			ContentType: c.Image.Upload.ContentType,
+			Content:     upload.Content,
+			ContentType: upload.ContentType,
 		})
 		if err != nil {
 			return errors.Wrap(err, "failed to upload new blob")
 		}
+
+		if img != nil {
+			for _, size := range images.ThumbnailSizes {
+				if size >= img.Width && size >= img.Height {
+					continue
+				}
+
+				thumbnail, err := images.Thumbnail(img.Content, size)
+				if err != nil {
+					return errors.Wrap(err, "failed to generate thumbnail of size %d", size)
+				}
+
+				err = bus.Dispatch(ctx, &cmd.StoreBlob{
+					Key:         images.ThumbnailKey(bkey, size),
+					Content:     thumbnail.Content,
+					ContentType: thumbnail.ContentType,
+				})
+				if err != nil {
+					return errors.Wrap(err, "failed to upload thumbnail blob")
+				}
+			}
+		}
+
 		c.Image.BlobKey = bkey
 	}
 	return nil
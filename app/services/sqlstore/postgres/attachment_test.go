@@ -2,6 +2,7 @@ package postgres_test
 
 import (
 	"context"
+	"io/ioutil"
 	"testing"
 
 	"github.com/getfider/fider/app/models"
@@ -9,6 +10,7 @@ import (
 
 	. "github.com/getfider/fider/app/pkg/assert"
 	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/env"
 )
 
 func TestUploadImage(t *testing.T) {
@@ -34,6 +36,39 @@ func TestUploadImage(t *testing.T) {
 	Expect(uploadImage.Image.BlobKey).HasLen(73)
 }
 
+func TestUploadImage_Thumbnails(t *testing.T) {
+	ctx := SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	stored := make(map[string]string)
+	bus.AddHandler(func(ctx context.Context, c *cmd.StoreBlob) error {
+		stored[c.Key] = c.ContentType
+		return nil
+	})
+
+	logo, _ := ioutil.ReadFile(env.Path("/app/pkg/web/testdata/logo3.gif"))
+	uploadImage := &cmd.UploadImage{
+		Image: &models.ImageUpload{
+			Upload: &models.ImageUploadData{
+				FileName:    "logo3.gif",
+				Content:     logo,
+				ContentType: "image/gif",
+			},
+		},
+		Folder: "logos",
+	}
+	err := bus.Dispatch(ctx, uploadImage)
+	Expect(err).IsNil()
+
+	bkey := uploadImage.Image.BlobKey
+	Expect(uploadImage.Image.Upload.ContentType).Equals("image/png")
+	Expect(stored).HasLen(4)
+	Expect(stored[bkey]).Equals("image/png")
+	Expect(stored[bkey+"@50"]).Equals("image/png")
+	Expect(stored[bkey+"@100"]).Equals("image/png")
+	Expect(stored[bkey+"@200"]).Equals("image/png")
+}
+
 func TestUploadImage_NoContent(t *testing.T) {
 	ctx := SetupDatabaseTest(t)
 	defer TeardownDatabaseTest()
@@ -0,0 +1,157 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/dto"
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/models/query"
+	"github.com/getfider/fider/app/pkg/dbx"
+	"github.com/getfider/fider/app/pkg/errors"
+)
+
+type dbAuditLog struct {
+	ID         int            `db:"id"`
+	Action     string         `db:"action"`
+	Actor      *dbUser        `db:"actor"`
+	TargetType string         `db:"target_type"`
+	TargetID   sql.NullInt64  `db:"target_id"`
+	TargetName string         `db:"target_name"`
+	Before     sql.NullString `db:"before"`
+	After      sql.NullString `db:"after"`
+	ClientIP   sql.NullString `db:"client_ip"`
+	CreatedAt  time.Time      `db:"created_at"`
+}
+
+func (a *dbAuditLog) toModel(ctx context.Context) *models.AuditLog {
+	log := &models.AuditLog{
+		ID:         a.ID,
+		Action:     enum.AuditAction(a.Action),
+		TargetType: a.TargetType,
+		TargetID:   int(a.TargetID.Int64),
+		TargetName: a.TargetName,
+		Before:     jsonToProps(a.Before),
+		After:      jsonToProps(a.After),
+		ClientIP:   a.ClientIP.String,
+		CreatedAt:  a.CreatedAt,
+	}
+	if a.Actor != nil && a.Actor.ID.Valid {
+		log.Actor = a.Actor.toModel(ctx)
+	}
+	return log
+}
+
+func jsonToProps(value sql.NullString) dto.Props {
+	if !value.Valid {
+		return nil
+	}
+	props := dto.Props{}
+	if err := json.Unmarshal([]byte(value.String), &props); err != nil {
+		return nil
+	}
+	return props
+}
+
+func propsToJSON(props dto.Props) (sql.NullString, error) {
+	if props == nil {
+		return sql.NullString{}, nil
+	}
+	bytes, err := json.Marshal(props)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+	return sql.NullString{String: string(bytes), Valid: true}, nil
+}
+
+func addAuditLog(ctx context.Context, c *cmd.AddAuditLog) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *models.Tenant, user *models.User) error {
+		var actorID sql.NullInt64
+		if user != nil {
+			actorID = sql.NullInt64{Int64: int64(user.ID), Valid: true}
+		}
+
+		targetID := sql.NullInt64{Int64: int64(c.TargetID), Valid: c.TargetID > 0}
+		clientIP := sql.NullString{String: c.ClientIP, Valid: len(c.ClientIP) > 0}
+
+		before, err := propsToJSON(c.Before)
+		if err != nil {
+			return errors.Wrap(err, "failed to serialize audit log values before '%s'", c.Action)
+		}
+		after, err := propsToJSON(c.After)
+		if err != nil {
+			return errors.Wrap(err, "failed to serialize audit log values after '%s'", c.Action)
+		}
+
+		_, err = trx.Execute(`
+			INSERT INTO audit_logs (tenant_id, actor_id, action, target_type, target_id, target_name, before, after, client_ip, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		`, tenant.ID, actorID, string(c.Action), c.TargetType, targetID, c.TargetName, before, after, clientIP, time.Now())
+		if err != nil {
+			return errors.Wrap(err, "failed to insert audit log '%s'", c.Action)
+		}
+		return nil
+	})
+}
+
+func listAuditLogs(ctx context.Context, q *query.ListAuditLogs) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *models.Tenant, user *models.User) error {
+		args := []interface{}{tenant.ID}
+		arg := func(value interface{}) string {
+			args = append(args, value)
+			return fmt.Sprintf("$%d", len(args))
+		}
+
+		conditions := ""
+		if q.ActorID > 0 {
+			conditions += " AND a.actor_id = " + arg(q.ActorID)
+		}
+		if q.Action != "" {
+			conditions += " AND a.action = " + arg(string(q.Action))
+		}
+		if !q.CreatedAfter.IsZero() {
+			conditions += " AND a.created_at >= " + arg(q.CreatedAfter)
+		}
+		if !q.CreatedBefore.IsZero() {
+			conditions += " AND a.created_at < " + arg(q.CreatedBefore)
+		}
+
+		limit := "ALL"
+		if q.Limit > 0 {
+			limit = arg(q.Limit)
+		}
+
+		logs := []*dbAuditLog{}
+		err := trx.Select(&logs, fmt.Sprintf(`
+			SELECT a.id, a.action, a.target_type, a.target_id, a.target_name, a.before, a.after, a.client_ip, a.created_at,
+						 u.id AS actor_id,
+						 u.name AS actor_name,
+						 u.email AS actor_email,
+						 u.role AS actor_role,
+						 u.status AS actor_status,
+						 u.avatar_type AS actor_avatar_type,
+						 u.avatar_bkey AS actor_avatar_bkey
+			FROM audit_logs a
+			LEFT JOIN users u
+			ON u.id = a.actor_id
+			AND u.tenant_id = a.tenant_id
+			WHERE a.tenant_id = $1%s
+			ORDER BY a.created_at DESC, a.id DESC
+			LIMIT %s
+		`, conditions, limit), args...)
+		if err != nil {
+			return errors.Wrap(err, "failed to list audit logs")
+		}
+
+		q.Result = make([]*models.AuditLog, len(logs))
+		for i, log := range logs {
+			q.Result[i] = log.toModel(ctx)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,75 @@
+package postgres_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/dto"
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/models/query"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/bus"
+)
+
+func TestAuditLogStorage_AddAndList(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	err := bus.Dispatch(jonSnowCtx, &cmd.AddAuditLog{
+		Action:     enum.AuditActionUserRoleChanged,
+		TargetType: "user",
+		TargetID:   aryaStark.ID,
+		TargetName: aryaStark.Name,
+		Before:     dto.Props{"role": enum.RoleVisitor},
+		After:      dto.Props{"role": enum.RoleCollaborator},
+		ClientIP:   "127.0.0.1",
+	})
+	Expect(err).IsNil()
+
+	err = bus.Dispatch(aryaStarkCtx, &cmd.AddAuditLog{
+		Action:     enum.AuditActionAPIKeyRegenerated,
+		TargetType: "user",
+		TargetID:   aryaStark.ID,
+		TargetName: aryaStark.Name,
+	})
+	Expect(err).IsNil()
+
+	err = bus.Dispatch(tonyStarkCtx, &cmd.AddAuditLog{
+		Action:     enum.AuditActionTagDeleted,
+		TargetType: "tag",
+	})
+	Expect(err).IsNil()
+
+	listAll := &query.ListAuditLogs{}
+	err = bus.Dispatch(demoTenantCtx, listAll)
+	Expect(err).IsNil()
+	Expect(listAll.Result).HasLen(2)
+	Expect(listAll.Result[0].Action).Equals(enum.AuditActionAPIKeyRegenerated)
+	Expect(listAll.Result[0].Actor.ID).Equals(aryaStark.ID)
+	Expect(listAll.Result[0].Before).IsNil()
+	Expect(listAll.Result[0].ClientIP).Equals("")
+	Expect(listAll.Result[1].Action).Equals(enum.AuditActionUserRoleChanged)
+	Expect(listAll.Result[1].Actor.ID).Equals(jonSnow.ID)
+	Expect(listAll.Result[1].TargetID).Equals(aryaStark.ID)
+	Expect(listAll.Result[1].Before).Equals(dto.Props{"role": "visitor"})
+	Expect(listAll.Result[1].After).Equals(dto.Props{"role": "collaborator"})
+	Expect(listAll.Result[1].ClientIP).Equals("127.0.0.1")
+
+	listByActor := &query.ListAuditLogs{ActorID: jonSnow.ID}
+	err = bus.Dispatch(demoTenantCtx, listByActor)
+	Expect(err).IsNil()
+	Expect(listByActor.Result).HasLen(1)
+	Expect(listByActor.Result[0].Action).Equals(enum.AuditActionUserRoleChanged)
+
+	listByAction := &query.ListAuditLogs{Action: enum.AuditActionAPIKeyRegenerated, Limit: 10}
+	err = bus.Dispatch(demoTenantCtx, listByAction)
+	Expect(err).IsNil()
+	Expect(listByAction.Result).HasLen(1)
+	Expect(listByAction.Result[0].Actor.ID).Equals(aryaStark.ID)
+
+	listFuture := &query.ListAuditLogs{CreatedAfter: time.Now().Add(time.Hour)}
+	err = bus.Dispatch(demoTenantCtx, listFuture)
+	Expect(err).IsNil()
+	Expect(listFuture.Result).HasLen(0)
+}
@@ -0,0 +1,60 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/models/query"
+	"github.com/getfider/fider/app/pkg/dbx"
+	"github.com/getfider/fider/app/pkg/errors"
+)
+
+//getReferencedBlobKeys returns the keys of all blobs that are still in use by current tenant
+//Attachments of deleted posts and comments are not considered as they're never shown again
+func getReferencedBlobKeys(ctx context.Context, q *query.GetReferencedBlobKeys) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *models.Tenant, user *models.User) error {
+		type dbKey struct {
+			Key string `db:"bkey"`
+		}
+
+		keys := []*dbKey{}
+		err := trx.Select(&keys, `
+			SELECT logo_bkey AS bkey FROM tenants WHERE id = $1 AND logo_bkey != ''
+			UNION
+			SELECT logo_bkey AS bkey FROM oauth_providers WHERE tenant_id = $1 AND logo_bkey != ''
+			UNION
+			SELECT avatar_bkey AS bkey FROM users WHERE tenant_id = $1 AND avatar_bkey != ''
+			UNION
+			SELECT a.attachment_bkey AS bkey
+			FROM attachments a
+			INNER JOIN posts p
+			ON p.id = a.post_id
+			AND p.tenant_id = a.tenant_id
+			LEFT JOIN comments c
+			ON c.id = a.comment_id
+			AND c.tenant_id = a.tenant_id
+			WHERE a.tenant_id = $1 AND p.status != $2 AND c.deleted_at IS NULL
+			UNION
+			SELECT f.file_bkey AS bkey
+			FROM file_attachments f
+			INNER JOIN posts p
+			ON p.id = f.post_id
+			AND p.tenant_id = f.tenant_id
+			LEFT JOIN comments c
+			ON c.id = f.comment_id
+			AND c.tenant_id = f.tenant_id
+			WHERE f.tenant_id = $1 AND p.status != $2 AND c.deleted_at IS NULL
+			ORDER BY bkey
+		`, tenant.ID, enum.PostDeleted)
+		if err != nil {
+			return errors.Wrap(err, "failed to get referenced blob keys")
+		}
+
+		q.Result = make([]string, len(keys))
+		for i, key := range keys {
+			q.Result[i] = key.Key
+		}
+		return nil
+	})
+}
@@ -0,0 +1,87 @@
+package postgres_test
+
+import (
+	"testing"
+
+	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/query"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/bus"
+)
+
+func TestGetReferencedBlobKeys(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	newPost := &cmd.AddNewPost{Title: "My new post", Description: "with this description"}
+	err := bus.Dispatch(jonSnowCtx, newPost)
+	Expect(err).IsNil()
+
+	newComment := &cmd.AddNewComment{Post: newPost.Result, Content: "Comment #1"}
+	err = bus.Dispatch(jonSnowCtx, newComment)
+	Expect(err).IsNil()
+
+	err = bus.Dispatch(jonSnowCtx, &cmd.SetAttachments{
+		Post: newPost.Result,
+		Attachments: []*models.ImageUpload{
+			&models.ImageUpload{BlobKey: "attachments/post.png"},
+		},
+	}, &cmd.SetAttachments{
+		Post:    newPost.Result,
+		Comment: newComment.Result,
+		Attachments: []*models.ImageUpload{
+			&models.ImageUpload{BlobKey: "attachments/comment.png"},
+		},
+	}, &cmd.SetFileAttachments{
+		Post: newPost.Result,
+		Files: []*models.FileUpload{
+			&models.FileUpload{
+				BlobKey: "files/report.pdf",
+				Upload:  &models.FileUploadData{FileName: "report.pdf", ContentType: "application/pdf", Content: []byte("%PDF-1.4")},
+			},
+		},
+	})
+	Expect(err).IsNil()
+
+	referenced := &query.GetReferencedBlobKeys{}
+	err = bus.Dispatch(jonSnowCtx, referenced)
+	Expect(err).IsNil()
+	Expect(hasKey(referenced.Result, "attachments/post.png")).IsTrue()
+	Expect(hasKey(referenced.Result, "attachments/comment.png")).IsTrue()
+	Expect(hasKey(referenced.Result, "files/report.pdf")).IsTrue()
+
+	err = bus.Dispatch(jonSnowCtx, &cmd.DeleteComment{CommentID: newComment.Result.ID})
+	Expect(err).IsNil()
+
+	referenced = &query.GetReferencedBlobKeys{}
+	err = bus.Dispatch(jonSnowCtx, referenced)
+	Expect(err).IsNil()
+	Expect(hasKey(referenced.Result, "attachments/post.png")).IsTrue()
+	Expect(hasKey(referenced.Result, "attachments/comment.png")).IsFalse()
+
+	otherTenant := &query.GetReferencedBlobKeys{}
+	err = bus.Dispatch(avengersTenantCtx, otherTenant)
+	Expect(err).IsNil()
+	Expect(hasKey(otherTenant.Result, "attachments/post.png")).IsFalse()
+}
+
+func TestGetAllTenants(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	allTenants := &query.GetAllTenants{}
+	err := bus.Dispatch(demoTenantCtx, allTenants)
+	Expect(err).IsNil()
+	Expect(allTenants.Result).HasLen(2)
+	Expect(allTenants.Result[0].Name).Equals("Demonstration")
+}
+
+func hasKey(keys []string, key string) bool {
+	for _, k := range keys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
@@ -2,11 +2,13 @@ package postgres
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/getfider/fider/app/models"
 	"github.com/getfider/fider/app/models/cmd"
 	"github.com/getfider/fider/app/models/query"
+	"github.com/getfider/fider/app/pkg/cursor"
 	"github.com/getfider/fider/app/pkg/dbx"
 	"github.com/getfider/fider/app/pkg/errors"
 )
@@ -19,6 +21,7 @@ type dbComment struct {
 	Attachments []string     `db:"attachment_bkeys"`
 	EditedAt    dbx.NullTime `db:"edited_at"`
 	EditedBy    *dbUser      `db:"edited_by"`
+	MergedFrom  dbx.NullInt  `db:"merged_from_number"`
 }
 
 func (c *dbComment) toModel(ctx context.Context) *models.Comment {
@@ -29,6 +32,9 @@ func (c *dbComment) toModel(ctx context.Context) *models.Comment {
 		User:        c.User.toModel(ctx),
 		Attachments: c.Attachments,
 	}
+	if c.MergedFrom.Valid {
+		comment.MergedFrom = int(c.MergedFrom.Int64)
+	}
 	if c.EditedAt.Valid {
 		comment.EditedBy = c.EditedBy.toModel(ctx)
 		comment.EditedAt = &c.EditedAt.Time
@@ -108,7 +114,8 @@ func getCommentByID(ctx context.Context, q *query.GetCommentByID) error {
 							e.role AS edited_by_role,
 							e.status AS edited_by_status,
 							e.avatar_type AS edited_by_avatar_type,
-							e.avatar_bkey AS edited_by_avatar_bkey
+							e.avatar_bkey AS edited_by_avatar_bkey,
+							m.number AS merged_from_number
 			FROM comments c
 			INNER JOIN users u
 			ON u.id = c.user_id
@@ -116,6 +123,9 @@ func getCommentByID(ctx context.Context, q *query.GetCommentByID) error {
 			LEFT JOIN users e
 			ON e.id = c.edited_by_id
 			AND e.tenant_id = c.tenant_id
+			LEFT JOIN posts m
+			ON m.id = c.merged_from_id
+			AND m.tenant_id = c.tenant_id
 			WHERE c.id = $1
 			AND c.tenant_id = $2
 			AND c.deleted_at IS NULL`, q.CommentID, tenant.ID)
@@ -133,9 +143,51 @@ func getCommentsByPost(ctx context.Context, q *query.GetCommentsByPost) error {
 	return using(ctx, func(trx *dbx.Trx, tenant *models.Tenant, user *models.User) error {
 		q.Result = make([]*models.Comment, 0)
 
-		comments := []*dbComment{}
-		err := trx.Select(&comments,
-			`WITH agg_attachments AS ( 
+		direction := "ASC"
+		if q.Order == "desc" {
+			direction = "DESC"
+		}
+
+		args := []interface{}{q.Post.ID, tenant.ID}
+		arg := func(value interface{}) string {
+			args = append(args, value)
+			return fmt.Sprintf("$%d", len(args))
+		}
+
+		conditions := ""
+		if q.AuthorID > 0 {
+			conditions += " AND c.user_id = " + arg(q.AuthorID)
+		}
+		if !q.CreatedAfter.IsZero() {
+			conditions += " AND c.created_at >= " + arg(q.CreatedAfter)
+		}
+		if !q.CreatedBefore.IsZero() {
+			conditions += " AND c.created_at < " + arg(q.CreatedBefore)
+		}
+		if q.Cursor != "" {
+			after, err := cursor.Parse(q.Cursor)
+			if err != nil {
+				return err
+			}
+			if after.Sort != "created" {
+				return cursor.ErrInvalid
+			}
+			if _, err := time.Parse(time.RFC3339Nano, after.Value); err != nil {
+				return cursor.ErrInvalid
+			}
+			operator := ">"
+			if direction == "DESC" {
+				operator = "<"
+			}
+			conditions += fmt.Sprintf(" AND (c.created_at, c.id) %s (%s::timestamptz, %s)", operator, arg(after.Value), arg(after.ID))
+		}
+
+		var limit interface{}
+		if q.Limit > 0 {
+			limit = q.Limit
+		}
+
+		sql := fmt.Sprintf(`WITH agg_attachments AS ( 
 					SELECT 
 							c.id as comment_id, 
 							ARRAY_REMOVE(ARRAY_AGG(at.attachment_bkey), NULL) as attachment_bkeys
@@ -167,7 +219,8 @@ func getCommentsByPost(ctx context.Context, q *query.GetCommentsByPost) error {
 					e.status AS edited_by_status,
 					e.avatar_type AS edited_by_avatar_type, 
 					e.avatar_bkey AS edited_by_avatar_bkey,
-					at.attachment_bkeys
+					at.attachment_bkeys,
+					m.number AS merged_from_number
 			FROM comments c
 			INNER JOIN posts p
 			ON p.id = c.post_id
@@ -180,10 +233,17 @@ func getCommentsByPost(ctx context.Context, q *query.GetCommentsByPost) error {
 			AND e.tenant_id = c.tenant_id
 			LEFT JOIN agg_attachments at
 			ON at.comment_id = c.id
+			LEFT JOIN posts m
+			ON m.id = c.merged_from_id
+			AND m.tenant_id = c.tenant_id
 			WHERE p.id = $1
 			AND p.tenant_id = $2
-			AND c.deleted_at IS NULL
-			ORDER BY c.created_at ASC`, q.Post.ID, tenant.ID)
+			AND c.deleted_at IS NULL%s
+			ORDER BY c.created_at %s, c.id %s
+			LIMIT %s`, conditions, direction, direction, arg(limit))
+
+		comments := []*dbComment{}
+		err := trx.Select(&comments, sql, args...)
 		if err != nil {
 			return errors.Wrap(err, "failed get comments of post with id '%d'", q.Post.ID)
 		}
@@ -192,6 +252,11 @@ func getCommentsByPost(ctx context.Context, q *query.GetCommentsByPost) error {
 		for i, comment := range comments {
 			q.Result[i] = comment.toModel(ctx)
 		}
+
+		if q.Limit > 0 && len(comments) == q.Limit {
+			last := comments[len(comments)-1]
+			q.NextCursor = cursor.New("created", last.CreatedAt.Format(time.RFC3339Nano), last.ID).String()
+		}
 		return nil
 	})
 }
@@ -19,7 +19,7 @@ func ToTSQuery(input string) string {
 	return strings.Join(strings.Fields(input), "|")
 }
 
-func getViewData(view string) (string, []enum.PostStatus, string) {
+func getViewData(view string, votingMode enum.VotingMode) (string, []enum.PostStatus, string) {
 	var (
 		condition string
 		sort      string
@@ -33,10 +33,13 @@ func getViewData(view string) (string, []enum.PostStatus, string) {
 	case "recent":
 		sort = "id"
 	case "my-votes":
-		condition = "AND has_voted = true"
+		condition = "has_voted = true"
 		sort = "id"
 	case "most-wanted":
 		sort = "votes_count"
+		if votingMode == enum.VotingModeBudgeted {
+			sort = "score"
+		}
 	case "most-discussed":
 		sort = "comments_count"
 	case "planned":
@@ -63,7 +66,11 @@ func getViewData(view string) (string, []enum.PostStatus, string) {
 	case "trending":
 		fallthrough
 	default:
-		sort = "((COALESCE(recent_votes_count, 0)*5 + COALESCE(recent_comments_count, 0) *3)-1) / pow((EXTRACT(EPOCH FROM current_timestamp - created_at)/3600) + 2, 1.4)"
+		votes := "recent_votes_count"
+		if votingMode == enum.VotingModeBudgeted {
+			votes = "recent_score"
+		}
+		sort = "((COALESCE(" + votes + ", 0)*5 + COALESCE(recent_comments_count, 0) *3)-1) / pow((EXTRACT(EPOCH FROM current_timestamp - created_at)/3600) + 2, 1.4)"
 	}
 	return condition, statuses, sort
 }
@@ -0,0 +1,155 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/models/query"
+	"github.com/getfider/fider/app/pkg/dbx"
+	"github.com/getfider/fider/app/pkg/errors"
+)
+
+func logEmailDelivery(ctx context.Context, c *cmd.LogEmailDelivery) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *models.Tenant, user *models.User) error {
+		var tenantID sql.NullInt64
+		if tenant != nil {
+			tenantID = sql.NullInt64{Int64: int64(tenant.ID), Valid: true}
+		}
+
+		now := time.Now()
+		for _, address := range c.Addresses {
+			_, err := trx.Execute(`
+				INSERT INTO email_deliveries (tenant_id, message_id, provider, template_name, address, status, created_at, updated_at)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $7)
+			`, tenantID, c.MessageID, c.Provider, c.TemplateName, strings.ToLower(address), string(enum.EmailDeliveryStatusSent), now)
+			if err != nil {
+				return errors.Wrap(err, "failed to log email delivery")
+			}
+		}
+		return nil
+	})
+}
+
+func setEmailDeliveryStatus(ctx context.Context, c *cmd.SetEmailDeliveryStatus) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *models.Tenant, user *models.User) error {
+		reason := sql.NullString{
+			String: c.Reason,
+			Valid:  len(c.Reason) > 0,
+		}
+
+		//Events can arrive out of order, so a bounce or complaint is never overwritten by other statuses
+		condition := "AND status NOT IN ('bounced', 'complained')"
+		if c.Status.IsUndeliverable() {
+			condition = ""
+		}
+
+		_, err := trx.Execute(`
+			UPDATE email_deliveries
+			SET status = $3, reason = $4, updated_at = $5
+			WHERE message_id = $1 AND address = $2 `+condition,
+			c.MessageID, strings.ToLower(c.Address), string(c.Status), reason, time.Now())
+		if err != nil {
+			return errors.Wrap(err, "failed to set email delivery status")
+		}
+
+		if c.Status.IsUndeliverable() {
+			return addEmailSuppression(trx, c)
+		}
+		return nil
+	})
+}
+
+//addEmailSuppression adds the address of a bounced or complained delivery to the suppression list of the tenant that sent it
+//Deliveries are looked up by message ID because provider events are received without a tenant
+func addEmailSuppression(trx *dbx.Trx, c *cmd.SetEmailDeliveryStatus) error {
+	reason := sql.NullString{
+		String: c.Reason,
+		Valid:  len(c.Reason) > 0,
+	}
+
+	_, err := trx.Execute(`
+		INSERT INTO email_suppressions (tenant_id, address, reason, detail, created_at)
+		SELECT DISTINCT tenant_id, address, $3, $4, $5
+		FROM email_deliveries
+		WHERE message_id = $1 AND address = $2 AND tenant_id IS NOT NULL
+		ON CONFLICT (tenant_id, address) DO UPDATE
+		SET reason = $3, detail = $4, created_at = $5
+	`, c.MessageID, strings.ToLower(c.Address), string(c.Status), reason, time.Now())
+	if err != nil {
+		return errors.Wrap(err, "failed to add email suppression")
+	}
+	return nil
+}
+
+type dbEmailSuppression struct {
+	Address   string         `db:"address"`
+	Reason    string         `db:"reason"`
+	Detail    dbx.NullString `db:"detail"`
+	CreatedAt time.Time      `db:"created_at"`
+}
+
+func (s *dbEmailSuppression) toModel() *models.EmailSuppression {
+	return &models.EmailSuppression{
+		Address:   s.Address,
+		Reason:    enum.EmailDeliveryStatus(s.Reason),
+		Detail:    s.Detail.String,
+		CreatedAt: s.CreatedAt,
+	}
+}
+
+func isEmailSuppressed(ctx context.Context, q *query.IsEmailSuppressed) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *models.Tenant, user *models.User) error {
+		q.Result = false
+		if tenant == nil {
+			return nil
+		}
+
+		exists, err := trx.Exists(`
+			SELECT 1 FROM email_suppressions
+			WHERE tenant_id = $1 AND address = $2
+		`, tenant.ID, strings.ToLower(q.Address))
+		if err != nil {
+			return errors.Wrap(err, "failed to check if email is suppressed")
+		}
+		q.Result = exists
+		return nil
+	})
+}
+
+func listEmailSuppressions(ctx context.Context, q *query.ListEmailSuppressions) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *models.Tenant, user *models.User) error {
+		suppressions := []*dbEmailSuppression{}
+		err := trx.Select(&suppressions, `
+			SELECT address, reason, detail, created_at
+			FROM email_suppressions
+			WHERE tenant_id = $1
+			ORDER BY created_at DESC
+		`, tenant.ID)
+		if err != nil {
+			return errors.Wrap(err, "failed to list email suppressions")
+		}
+
+		q.Result = make([]*models.EmailSuppression, len(suppressions))
+		for i, suppression := range suppressions {
+			q.Result[i] = suppression.toModel()
+		}
+		return nil
+	})
+}
+
+func deleteEmailSuppression(ctx context.Context, c *cmd.DeleteEmailSuppression) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *models.Tenant, user *models.User) error {
+		_, err := trx.Execute(`
+			DELETE FROM email_suppressions WHERE tenant_id = $1 AND address = $2
+		`, tenant.ID, strings.ToLower(c.Address))
+		if err != nil {
+			return errors.Wrap(err, "failed to delete email suppression")
+		}
+		return nil
+	})
+}
@@ -0,0 +1,128 @@
+package postgres_test
+
+import (
+	"testing"
+
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/models/query"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/bus"
+)
+
+func TestEmailDeliveryStorage_BounceIsSuppressed(t *testing.T) {
+	ctx := SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	err := bus.Dispatch(demoTenantCtx, &cmd.LogEmailDelivery{
+		MessageID:    "20190727.1234@mydomain.com",
+		Provider:     "mailgun",
+		TemplateName: "new_post",
+		Addresses:    []string{"Jon.Snow@got.com", "arya.stark@got.com"},
+	})
+	Expect(err).IsNil()
+
+	isSuppressed := &query.IsEmailSuppressed{Address: "jon.snow@got.com"}
+	err = bus.Dispatch(demoTenantCtx, isSuppressed)
+	Expect(err).IsNil()
+	Expect(isSuppressed.Result).IsFalse()
+
+	//Events are received without a tenant
+	err = bus.Dispatch(ctx, &cmd.SetEmailDeliveryStatus{
+		MessageID: "20190727.1234@mydomain.com",
+		Address:   "jon.snow@got.com",
+		Status:    enum.EmailDeliveryStatusBounced,
+		Reason:    "No such mailbox",
+	})
+	Expect(err).IsNil()
+
+	//Late delivery events don't overwrite a bounce
+	err = bus.Dispatch(ctx, &cmd.SetEmailDeliveryStatus{
+		MessageID: "20190727.1234@mydomain.com",
+		Address:   "jon.snow@got.com",
+		Status:    enum.EmailDeliveryStatusDelivered,
+	})
+	Expect(err).IsNil()
+
+	isSuppressed = &query.IsEmailSuppressed{Address: "JON.SNOW@got.com"}
+	err = bus.Dispatch(demoTenantCtx, isSuppressed)
+	Expect(err).IsNil()
+	Expect(isSuppressed.Result).IsTrue()
+
+	isSuppressed = &query.IsEmailSuppressed{Address: "jon.snow@got.com"}
+	err = bus.Dispatch(avengersTenantCtx, isSuppressed)
+	Expect(err).IsNil()
+	Expect(isSuppressed.Result).IsFalse()
+
+	isSuppressed = &query.IsEmailSuppressed{Address: "arya.stark@got.com"}
+	err = bus.Dispatch(demoTenantCtx, isSuppressed)
+	Expect(err).IsNil()
+	Expect(isSuppressed.Result).IsFalse()
+
+	listSuppressions := &query.ListEmailSuppressions{}
+	err = bus.Dispatch(demoTenantCtx, listSuppressions)
+	Expect(err).IsNil()
+	Expect(listSuppressions.Result).HasLen(1)
+	Expect(listSuppressions.Result[0].Address).Equals("jon.snow@got.com")
+	Expect(listSuppressions.Result[0].Reason).Equals(enum.EmailDeliveryStatusBounced)
+	Expect(listSuppressions.Result[0].Detail).Equals("No such mailbox")
+}
+
+func TestEmailDeliveryStorage_DeleteSuppression(t *testing.T) {
+	ctx := SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	err := bus.Dispatch(demoTenantCtx, &cmd.LogEmailDelivery{
+		MessageID:    "0100016c-message-id",
+		Provider:     "ses",
+		TemplateName: "new_comment",
+		Addresses:    []string{"jon.snow@got.com"},
+	})
+	Expect(err).IsNil()
+
+	err = bus.Dispatch(ctx, &cmd.SetEmailDeliveryStatus{
+		MessageID: "0100016c-message-id",
+		Address:   "jon.snow@got.com",
+		Status:    enum.EmailDeliveryStatusComplained,
+		Reason:    "abuse",
+	})
+	Expect(err).IsNil()
+
+	isSuppressed := &query.IsEmailSuppressed{Address: "jon.snow@got.com"}
+	err = bus.Dispatch(demoTenantCtx, isSuppressed)
+	Expect(err).IsNil()
+	Expect(isSuppressed.Result).IsTrue()
+
+	err = bus.Dispatch(demoTenantCtx, &cmd.DeleteEmailSuppression{Address: "Jon.Snow@got.com"})
+	Expect(err).IsNil()
+
+	isSuppressed = &query.IsEmailSuppressed{Address: "jon.snow@got.com"}
+	err = bus.Dispatch(demoTenantCtx, isSuppressed)
+	Expect(err).IsNil()
+	Expect(isSuppressed.Result).IsFalse()
+}
+
+func TestEmailDeliveryStorage_DeliveredIsNotSuppressed(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	err := bus.Dispatch(avengersTenantCtx, &cmd.LogEmailDelivery{
+		MessageID:    "0100016c-message-id",
+		Provider:     "ses",
+		TemplateName: "new_post",
+		Addresses:    []string{"tony.stark@avengers.com"},
+	})
+	Expect(err).IsNil()
+
+	err = bus.Dispatch(avengersTenantCtx, &cmd.SetEmailDeliveryStatus{
+		MessageID: "0100016c-message-id",
+		Address:   "tony.stark@avengers.com",
+		Status:    enum.EmailDeliveryStatusDelivered,
+	})
+	Expect(err).IsNil()
+
+	isSuppressed := &query.IsEmailSuppressed{Address: "tony.stark@avengers.com"}
+	err = bus.Dispatch(avengersTenantCtx, isSuppressed)
+	Expect(err).IsNil()
+	Expect(isSuppressed.Result).IsFalse()
+}
@@ -0,0 +1,95 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/query"
+	"github.com/getfider/fider/app/pkg/dbx"
+	"github.com/getfider/fider/app/pkg/errors"
+)
+
+type dbEmailTemplate struct {
+	Name    string `db:"name"`
+	Subject string `db:"subject"`
+	Body    string `db:"body"`
+}
+
+func (t *dbEmailTemplate) toModel() *models.EmailTemplate {
+	return &models.EmailTemplate{
+		Name:         t.Name,
+		Subject:      t.Subject,
+		Body:         t.Body,
+		IsCustomized: true,
+	}
+}
+
+func getCustomEmailTemplate(ctx context.Context, q *query.GetCustomEmailTemplate) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *models.Tenant, user *models.User) error {
+		if tenant == nil {
+			return app.ErrNotFound
+		}
+
+		template := &dbEmailTemplate{}
+		err := trx.Get(template, `
+			SELECT name, subject, body
+			FROM email_templates
+			WHERE tenant_id = $1 AND name = $2
+		`, tenant.ID, q.Name)
+		if err != nil {
+			return err
+		}
+
+		q.Result = template.toModel()
+		return nil
+	})
+}
+
+func listCustomEmailTemplates(ctx context.Context, q *query.ListCustomEmailTemplates) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *models.Tenant, user *models.User) error {
+		templates := []*dbEmailTemplate{}
+		err := trx.Select(&templates, `
+			SELECT name, subject, body
+			FROM email_templates
+			WHERE tenant_id = $1
+			ORDER BY name
+		`, tenant.ID)
+		if err != nil {
+			return errors.Wrap(err, "failed to get custom email templates")
+		}
+
+		q.Result = make([]*models.EmailTemplate, len(templates))
+		for i, template := range templates {
+			q.Result[i] = template.toModel()
+		}
+		return nil
+	})
+}
+
+func saveEmailTemplate(ctx context.Context, c *cmd.SaveEmailTemplate) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *models.Tenant, user *models.User) error {
+		_, err := trx.Execute(`
+			INSERT INTO email_templates (tenant_id, name, subject, body, updated_at)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (tenant_id, name) DO UPDATE
+			SET subject = $3, body = $4, updated_at = $5
+		`, tenant.ID, c.Name, c.Subject, c.Body, time.Now())
+		if err != nil {
+			return errors.Wrap(err, "failed to save email template")
+		}
+		return nil
+	})
+}
+
+func resetEmailTemplate(ctx context.Context, c *cmd.ResetEmailTemplate) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *models.Tenant, user *models.User) error {
+		_, err := trx.Execute(`DELETE FROM email_templates WHERE tenant_id = $1 AND name = $2`, tenant.ID, c.Name)
+		if err != nil {
+			return errors.Wrap(err, "failed to reset email template")
+		}
+		return nil
+	})
+}
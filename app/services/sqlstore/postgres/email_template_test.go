@@ -0,0 +1,64 @@
+package postgres_test
+
+import (
+	"testing"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/query"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/errors"
+)
+
+func TestEmailTemplateStorage_SaveGetAndReset(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	getTemplate := &query.GetCustomEmailTemplate{Name: "new_post"}
+	err := bus.Dispatch(demoTenantCtx, getTemplate)
+	Expect(errors.Cause(err)).Equals(app.ErrNotFound)
+
+	err = bus.Dispatch(demoTenantCtx, &cmd.SaveEmailTemplate{Name: "new_post", Subject: "New: {{ .title }}", Body: "<tr><td>Hello</td></tr>"})
+	Expect(err).IsNil()
+
+	err = bus.Dispatch(demoTenantCtx, &cmd.SaveEmailTemplate{Name: "new_post", Subject: "Post: {{ .title }}", Body: "<tr><td>World</td></tr>"})
+	Expect(err).IsNil()
+
+	getTemplate = &query.GetCustomEmailTemplate{Name: "new_post"}
+	err = bus.Dispatch(demoTenantCtx, getTemplate)
+	Expect(err).IsNil()
+	Expect(getTemplate.Result.Name).Equals("new_post")
+	Expect(getTemplate.Result.Subject).Equals("Post: {{ .title }}")
+	Expect(getTemplate.Result.Body).Equals("<tr><td>World</td></tr>")
+	Expect(getTemplate.Result.IsCustomized).IsTrue()
+
+	getTemplate = &query.GetCustomEmailTemplate{Name: "new_post"}
+	err = bus.Dispatch(avengersTenantCtx, getTemplate)
+	Expect(errors.Cause(err)).Equals(app.ErrNotFound)
+
+	err = bus.Dispatch(demoTenantCtx, &cmd.ResetEmailTemplate{Name: "new_post"})
+	Expect(err).IsNil()
+
+	getTemplate = &query.GetCustomEmailTemplate{Name: "new_post"}
+	err = bus.Dispatch(demoTenantCtx, getTemplate)
+	Expect(errors.Cause(err)).Equals(app.ErrNotFound)
+}
+
+func TestEmailTemplateStorage_List(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	bus.MustDispatch(demoTenantCtx,
+		&cmd.SaveEmailTemplate{Name: "new_post", Subject: "A", Body: "A"},
+		&cmd.SaveEmailTemplate{Name: "digest", Subject: "B", Body: "B"},
+	)
+	bus.MustDispatch(avengersTenantCtx, &cmd.SaveEmailTemplate{Name: "new_comment", Subject: "C", Body: "C"})
+
+	listTemplates := &query.ListCustomEmailTemplates{}
+	err := bus.Dispatch(demoTenantCtx, listTemplates)
+	Expect(err).IsNil()
+	Expect(listTemplates.Result).HasLen(2)
+	Expect(listTemplates.Result[0].Name).Equals("digest")
+	Expect(listTemplates.Result[1].Name).Equals("new_post")
+}
@@ -18,11 +18,16 @@ func storeEvent(ctx context.Context, c *cmd.StoreEvent) error {
 			Valid:  len(c.ClientIP) > 0,
 		}
 
+		var userID sql.NullInt64
+		if user != nil {
+			userID = sql.NullInt64{Int64: int64(user.ID), Valid: true}
+		}
+
 		_, err := trx.Execute(`
-			INSERT INTO events (tenant_id, client_ip, name, created_at) 
-			VALUES ($1, $2, $3, $4)
+			INSERT INTO events (tenant_id, client_ip, name, user_id, created_at) 
+			VALUES ($1, $2, $3, $4, $5)
 			RETURNING id
-		`, tenant.ID, dbClientIP, c.EventName, time.Now())
+		`, tenant.ID, dbClientIP, c.EventName, userID, time.Now())
 		if err != nil {
 			return errors.Wrap(err, "failed to insert event")
 		}
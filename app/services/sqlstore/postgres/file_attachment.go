@@ -0,0 +1,180 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/models/query"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/dbx"
+	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/getfider/fider/app/pkg/rand"
+	"github.com/getfider/fider/app/services/blob"
+)
+
+type dbFileAttachment struct {
+	BlobKey     string        `db:"file_bkey"`
+	FileName    string        `db:"file_name"`
+	ContentType string        `db:"content_type"`
+	Size        int           `db:"size"`
+	CommentID   sql.NullInt64 `db:"comment_id"`
+}
+
+func (f *dbFileAttachment) toModel() *models.FileAttachment {
+	file := &models.FileAttachment{
+		BlobKey:     f.BlobKey,
+		FileName:    f.FileName,
+		ContentType: f.ContentType,
+		Size:        f.Size,
+	}
+	if f.CommentID.Valid {
+		file.CommentID = int(f.CommentID.Int64)
+	}
+	return file
+}
+
+func setFileAttachments(ctx context.Context, c *cmd.SetFileAttachments) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *models.Tenant, user *models.User) error {
+		postID := c.Post.ID
+		var commentID sql.NullInt64
+		if c.Comment != nil {
+			err := commentID.Scan(c.Comment.ID)
+			if err != nil {
+				return errors.Wrap(err, "failed scan comment id")
+			}
+		}
+
+		for _, file := range c.Files {
+			if file.Remove {
+				if _, err := trx.Execute(
+					"DELETE FROM file_attachments WHERE tenant_id = $1 AND post_id = $2 AND (comment_id = $3 OR ($3 IS NULL AND comment_id IS NULL)) AND file_bkey = $4",
+					tenant.ID, postID, commentID, file.BlobKey,
+				); err != nil {
+					return errors.Wrap(err, "failed to delete file attachment")
+				}
+			} else if file.Upload != nil && file.BlobKey != "" {
+				if _, err := trx.Execute(`
+					INSERT INTO file_attachments (tenant_id, post_id, comment_id, user_id, file_bkey, file_name, content_type, size, created_at)
+					VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+				`, tenant.ID, postID, commentID, user.ID, file.BlobKey,
+					file.Upload.FileName, file.Upload.ContentType, len(file.Upload.Content), time.Now(),
+				); err != nil {
+					return errors.Wrap(err, "failed to insert file attachment")
+				}
+			}
+		}
+
+		return nil
+	})
+}
+
+func getFileAttachments(ctx context.Context, q *query.GetFileAttachments) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *models.Tenant, user *models.User) error {
+		var commentID sql.NullInt64
+		if q.Comment != nil {
+			err := commentID.Scan(q.Comment.ID)
+			if err != nil {
+				return errors.Wrap(err, "failed scan comment id")
+			}
+		}
+
+		files := []*dbFileAttachment{}
+		err := trx.Select(&files, `
+			SELECT file_bkey, file_name, content_type, size, comment_id
+			FROM file_attachments
+			WHERE tenant_id = $1 AND post_id = $2 AND (comment_id = $3 OR ($3 IS NULL AND comment_id IS NULL))
+			ORDER BY id
+		`, tenant.ID, q.Post.ID, commentID)
+		if err != nil {
+			return errors.Wrap(err, "failed to get file attachments")
+		}
+
+		q.Result = make([]*models.FileAttachment, len(files))
+		for i, file := range files {
+			q.Result[i] = file.toModel()
+		}
+		return nil
+	})
+}
+
+func listPostFileAttachments(ctx context.Context, q *query.ListPostFileAttachments) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *models.Tenant, user *models.User) error {
+		files := []*dbFileAttachment{}
+		err := trx.Select(&files, `
+			SELECT f.file_bkey, f.file_name, f.content_type, f.size, f.comment_id
+			FROM file_attachments f
+			LEFT JOIN comments c
+			ON c.id = f.comment_id
+			AND c.tenant_id = f.tenant_id
+			WHERE f.tenant_id = $1 AND f.post_id = $2 AND c.deleted_at IS NULL
+			ORDER BY f.id
+		`, tenant.ID, q.Post.ID)
+		if err != nil {
+			return errors.Wrap(err, "failed to list file attachments of post")
+		}
+
+		q.Result = make([]*models.FileAttachment, len(files))
+		for i, file := range files {
+			q.Result[i] = file.toModel()
+		}
+		return nil
+	})
+}
+
+func getFileAttachmentByKey(ctx context.Context, q *query.GetFileAttachmentByKey) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *models.Tenant, user *models.User) error {
+		file := dbFileAttachment{}
+		err := trx.Get(&file, `
+			SELECT f.file_bkey, f.file_name, f.content_type, f.size, f.comment_id
+			FROM file_attachments f
+			INNER JOIN posts p
+			ON p.id = f.post_id
+			AND p.tenant_id = f.tenant_id
+			LEFT JOIN comments c
+			ON c.id = f.comment_id
+			AND c.tenant_id = f.tenant_id
+			WHERE f.tenant_id = $1 AND f.file_bkey = $2 AND p.status != $3 AND c.deleted_at IS NULL
+		`, tenant.ID, q.BlobKey, enum.PostDeleted)
+		if err != nil {
+			return errors.Wrap(err, "failed to get file attachment with key '%s'", q.BlobKey)
+		}
+
+		q.Result = file.toModel()
+		return nil
+	})
+}
+
+func getFileAttachmentsSize(ctx context.Context, q *query.GetFileAttachmentsSize) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *models.Tenant, user *models.User) error {
+		err := trx.Scalar(&q.Result, "SELECT COALESCE(SUM(size), 0) FROM file_attachments WHERE tenant_id = $1", tenant.ID)
+		if err != nil {
+			return errors.Wrap(err, "failed to get size of file attachments")
+		}
+		return nil
+	})
+}
+
+func uploadFiles(ctx context.Context, c *cmd.UploadFiles) error {
+	for _, file := range c.Files {
+		if file.Remove || file.Upload == nil || len(file.Upload.Content) == 0 {
+			continue
+		}
+
+		bkey := fmt.Sprintf("%s/%s-%s", c.Folder, rand.String(64), blob.SanitizeFileName(file.Upload.FileName))
+		err := bus.Dispatch(ctx, &cmd.StoreBlob{
+			Key:         bkey,
+			Content:     file.Upload.Content,
+			ContentType: file.Upload.ContentType,
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to upload new file")
+		}
+		file.BlobKey = bkey
+	}
+	return nil
+}
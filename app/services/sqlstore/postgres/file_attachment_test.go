@@ -0,0 +1,128 @@
+package postgres_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/query"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/errors"
+)
+
+func TestUploadFiles(t *testing.T) {
+	ctx := SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	stored := make(map[string]string)
+	bus.AddHandler(func(ctx context.Context, c *cmd.StoreBlob) error {
+		stored[c.Key] = c.ContentType
+		return nil
+	})
+
+	files := []*models.FileUpload{
+		&models.FileUpload{
+			Upload: &models.FileUploadData{
+				FileName:    "My Report.pdf",
+				ContentType: "application/pdf",
+				Content:     []byte("%PDF-1.4"),
+			},
+		},
+		&models.FileUpload{
+			BlobKey: "files/existing.pdf",
+			Remove:  true,
+		},
+	}
+
+	err := bus.Dispatch(ctx, &cmd.UploadFiles{Files: files, Folder: "files"})
+	Expect(err).IsNil()
+	Expect(stored).HasLen(1)
+	Expect(files[0].BlobKey).ContainsSubstring("files/")
+	Expect(files[0].BlobKey).ContainsSubstring("-my-report.pdf")
+	Expect(stored[files[0].BlobKey]).Equals("application/pdf")
+	Expect(files[1].BlobKey).Equals("files/existing.pdf")
+}
+
+func TestSetFileAttachments(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	newPost := &cmd.AddNewPost{Title: "My new post", Description: "with this description"}
+	err := bus.Dispatch(jonSnowCtx, newPost)
+	Expect(err).IsNil()
+
+	newComment := &cmd.AddNewComment{Post: newPost.Result, Content: "Comment #1"}
+	err = bus.Dispatch(jonSnowCtx, newComment)
+	Expect(err).IsNil()
+
+	err = bus.Dispatch(jonSnowCtx, &cmd.SetFileAttachments{
+		Post: newPost.Result,
+		Files: []*models.FileUpload{
+			&models.FileUpload{
+				BlobKey: "files/abc-report.pdf",
+				Upload:  &models.FileUploadData{FileName: "report.pdf", ContentType: "application/pdf", Content: []byte("%PDF-1.4")},
+			},
+		},
+	}, &cmd.SetFileAttachments{
+		Post:    newPost.Result,
+		Comment: newComment.Result,
+		Files: []*models.FileUpload{
+			&models.FileUpload{
+				BlobKey: "files/def-notes.txt",
+				Upload:  &models.FileUploadData{FileName: "notes.txt", ContentType: "text/plain", Content: []byte("Hello World")},
+			},
+		},
+	})
+	Expect(err).IsNil()
+
+	postFiles := &query.GetFileAttachments{Post: newPost.Result}
+	commentFiles := &query.GetFileAttachments{Post: newPost.Result, Comment: newComment.Result}
+	allFiles := &query.ListPostFileAttachments{Post: newPost.Result}
+	size := &query.GetFileAttachmentsSize{}
+	err = bus.Dispatch(jonSnowCtx, postFiles, commentFiles, allFiles, size)
+	Expect(err).IsNil()
+
+	Expect(postFiles.Result).HasLen(1)
+	Expect(postFiles.Result[0].FileName).Equals("report.pdf")
+	Expect(postFiles.Result[0].ContentType).Equals("application/pdf")
+	Expect(postFiles.Result[0].Size).Equals(8)
+	Expect(postFiles.Result[0].CommentID).Equals(0)
+	Expect(commentFiles.Result).HasLen(1)
+	Expect(commentFiles.Result[0].FileName).Equals("notes.txt")
+	Expect(commentFiles.Result[0].CommentID).Equals(newComment.Result.ID)
+	Expect(allFiles.Result).HasLen(2)
+	Expect(size.Result).Equals(19)
+
+	byKey := &query.GetFileAttachmentByKey{BlobKey: "files/def-notes.txt"}
+	err = bus.Dispatch(aryaStarkCtx, byKey)
+	Expect(err).IsNil()
+	Expect(byKey.Result.FileName).Equals("notes.txt")
+
+	err = bus.Dispatch(jonSnowCtx, &cmd.DeleteComment{CommentID: newComment.Result.ID})
+	Expect(err).IsNil()
+
+	allFiles = &query.ListPostFileAttachments{Post: newPost.Result}
+	err = bus.Dispatch(jonSnowCtx, allFiles)
+	Expect(err).IsNil()
+	Expect(allFiles.Result).HasLen(1)
+
+	byKey = &query.GetFileAttachmentByKey{BlobKey: "files/def-notes.txt"}
+	err = bus.Dispatch(aryaStarkCtx, byKey)
+	Expect(errors.Cause(err)).Equals(app.ErrNotFound)
+
+	err = bus.Dispatch(jonSnowCtx, &cmd.SetFileAttachments{
+		Post: newPost.Result,
+		Files: []*models.FileUpload{
+			&models.FileUpload{BlobKey: "files/abc-report.pdf", Remove: true},
+		},
+	})
+	Expect(err).IsNil()
+
+	postFiles = &query.GetFileAttachments{Post: newPost.Result}
+	err = bus.Dispatch(jonSnowCtx, postFiles)
+	Expect(err).IsNil()
+	Expect(postFiles.Result).HasLen(0)
+}
@@ -0,0 +1,344 @@
+package postgres
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/dto"
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/pkg/dbx"
+	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/gosimple/slug"
+)
+
+const importedTagColor = "777777"
+
+type recordsImporter struct {
+	trx    *dbx.Trx
+	tenant *models.Tenant
+	report *dto.ImportReport
+	users  map[string]int
+	tags   map[string]int
+	posts  map[int]int
+}
+
+func importRecords(ctx context.Context, c *cmd.ImportRecords) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *models.Tenant, user *models.User) error {
+		if _, err := trx.Execute("SAVEPOINT import_records"); err != nil {
+			return errors.Wrap(err, "failed to create savepoint")
+		}
+
+		imp := &recordsImporter{
+			trx:    trx,
+			tenant: tenant,
+			report: c.Report,
+			users:  make(map[string]int),
+			tags:   make(map[string]int),
+			posts:  make(map[int]int),
+		}
+
+		if err := imp.importPosts(c.Posts); err != nil {
+			return err
+		}
+
+		for _, comment := range c.Comments {
+			if err := imp.importComment(comment); err != nil {
+				return err
+			}
+		}
+
+		for _, vote := range c.Votes {
+			if err := imp.importVote(vote); err != nil {
+				return err
+			}
+		}
+
+		if c.Report.DryRun || c.Report.HasErrors() {
+			if _, err := trx.Execute("ROLLBACK TO SAVEPOINT import_records"); err != nil {
+				return errors.Wrap(err, "failed to rollback to savepoint")
+			}
+			return nil
+		}
+
+		if _, err := trx.Execute("RELEASE SAVEPOINT import_records"); err != nil {
+			return errors.Wrap(err, "failed to release savepoint")
+		}
+		return nil
+	})
+}
+
+func (imp *recordsImporter) importPosts(posts []*dto.ImportPost) error {
+	originals := make(map[*dto.ImportPost]int)
+	for _, post := range posts {
+		id, err := imp.importPost(post)
+		if err != nil {
+			return err
+		}
+		if id > 0 && post.OriginalNumber > 0 {
+			originals[post] = id
+		}
+	}
+
+	for post, id := range originals {
+		originalID, err := imp.postID(post.OriginalNumber)
+		if err != nil {
+			return err
+		}
+
+		if originalID == 0 {
+			imp.report.Error("Line %d: original post %d was not found.", post.Line, post.OriginalNumber)
+			continue
+		}
+
+		_, err = imp.trx.Execute(
+			"UPDATE posts SET original_id = $1 WHERE id = $2 AND tenant_id = $3",
+			originalID, id, imp.tenant.ID,
+		)
+		if err != nil {
+			return errors.Wrap(err, "failed to set original of imported post")
+		}
+	}
+	return nil
+}
+
+func (imp *recordsImporter) importPost(post *dto.ImportPost) (int, error) {
+	if post.Number > 0 {
+		exists, err := imp.trx.Exists("SELECT 1 FROM posts WHERE tenant_id = $1 AND number = $2", imp.tenant.ID, post.Number)
+		if err != nil {
+			return 0, errors.Wrap(err, "failed to check if post number exists")
+		}
+		if exists {
+			imp.report.Error("Line %d: post number %d already exists.", post.Line, post.Number)
+			return 0, nil
+		}
+	}
+
+	userID, err := imp.userID(post.Author)
+	if err != nil {
+		return 0, err
+	}
+
+	createdAt := orNow(post.CreatedAt)
+	var (
+		responseUserID interface{}
+		responseDate   interface{}
+	)
+	if post.Response != "" || post.Status != enum.PostOpen {
+		responder := post.RespondedBy
+		if responder.Name == "" && responder.Email == "" {
+			responder = post.Author
+		}
+		if responseUserID, err = imp.userID(responder); err != nil {
+			return 0, err
+		}
+		responseDate = createdAt
+		if !post.RespondedAt.IsZero() {
+			responseDate = post.RespondedAt
+		}
+	}
+
+	var number interface{}
+	if post.Number > 0 {
+		number = post.Number
+	}
+
+	var id int
+	err = imp.trx.Scalar(&id, `
+		INSERT INTO posts (title, slug, number, description, tenant_id, user_id, created_at, status, response, response_date, response_user_id)
+		VALUES ($1, $2, COALESCE($3, (SELECT COALESCE(MAX(number), 0) + 1 FROM posts p WHERE p.tenant_id = $5)), $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id`,
+		post.Title, slug.Make(post.Title), number, post.Description, imp.tenant.ID, userID, createdAt,
+		post.Status, post.Response, responseDate, responseUserID,
+	)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to import post")
+	}
+	imp.report.Count("posts")
+
+	_, err = imp.trx.Execute(`
+		INSERT INTO post_status_history (tenant_id, post_id, status, changed_by_id, changed_at)
+		SELECT tenant_id, id, $3, user_id, created_at FROM posts WHERE id = $1 AND tenant_id = $2
+		UNION ALL
+		SELECT tenant_id, id, status, response_user_id, response_date FROM posts WHERE id = $1 AND tenant_id = $2 AND status <> $3`,
+		id, imp.tenant.ID, enum.PostOpen,
+	)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to add status history of imported post")
+	}
+
+	for _, name := range post.Tags {
+		tagID, err := imp.tagID(name)
+		if err != nil {
+			return 0, err
+		}
+
+		_, err = imp.trx.Execute(`
+			INSERT INTO post_tags (tag_id, post_id, created_at, created_by_id, tenant_id) VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT DO NOTHING`,
+			tagID, id, createdAt, userID, imp.tenant.ID,
+		)
+		if err != nil {
+			return 0, errors.Wrap(err, "failed to tag imported post")
+		}
+	}
+
+	return id, nil
+}
+
+func (imp *recordsImporter) importComment(comment *dto.ImportComment) error {
+	postID, err := imp.postID(comment.PostNumber)
+	if err != nil {
+		return err
+	}
+	if postID == 0 {
+		imp.report.Error("Line %d: post %d was not found.", comment.Line, comment.PostNumber)
+		return nil
+	}
+
+	userID, err := imp.userID(comment.Author)
+	if err != nil {
+		return err
+	}
+
+	_, err = imp.trx.Execute(
+		"INSERT INTO comments (tenant_id, post_id, content, user_id, created_at) VALUES ($1, $2, $3, $4, $5)",
+		imp.tenant.ID, postID, comment.Content, userID, orNow(comment.CreatedAt),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to import comment")
+	}
+	imp.report.Count("comments")
+	return nil
+}
+
+func (imp *recordsImporter) importVote(vote *dto.ImportVote) error {
+	postID, err := imp.postID(vote.PostNumber)
+	if err != nil {
+		return err
+	}
+	if postID == 0 {
+		imp.report.Error("Line %d: post %d was not found.", vote.Line, vote.PostNumber)
+		return nil
+	}
+
+	userID, err := imp.userID(vote.Author)
+	if err != nil {
+		return err
+	}
+
+	credits := vote.Credits
+	if credits == 0 {
+		credits = 1
+	}
+
+	rows, err := imp.trx.Execute(
+		"INSERT INTO post_votes (tenant_id, user_id, post_id, created_at, credits) VALUES ($1, $2, $3, $4, $5) ON CONFLICT DO NOTHING",
+		imp.tenant.ID, userID, postID, orNow(vote.CreatedAt), credits,
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to import vote")
+	}
+
+	if rows == 0 {
+		imp.report.Warn("Line %d: skipped because user has already voted on post %d.", vote.Line, vote.PostNumber)
+		return nil
+	}
+	imp.report.Count("votes")
+	return nil
+}
+
+//postID returns 0 when there's no post with given number
+func (imp *recordsImporter) postID(number int) (int, error) {
+	if id, ok := imp.posts[number]; ok {
+		return id, nil
+	}
+
+	var id int
+	err := imp.trx.Scalar(&id, "SELECT id FROM posts WHERE tenant_id = $1 AND number = $2", imp.tenant.ID, number)
+	if err != nil && err != app.ErrNotFound {
+		return 0, errors.Wrap(err, "failed to get post by number")
+	}
+	imp.posts[number] = id
+	return id, nil
+}
+
+//userID finds users by email, or creates a new one when not found.
+//Authors without email are created once per name.
+func (imp *recordsImporter) userID(author dto.ImportUser) (int, error) {
+	key := "name:" + author.Name
+	if author.Email != "" {
+		key = "email:" + author.Email
+	}
+
+	if id, ok := imp.users[key]; ok {
+		return id, nil
+	}
+
+	var id int
+	if author.Email != "" {
+		err := imp.trx.Scalar(&id, "SELECT id FROM users WHERE tenant_id = $1 AND email = $2", imp.tenant.ID, author.Email)
+		if err == nil {
+			imp.users[key] = id
+			return id, nil
+		} else if err != app.ErrNotFound {
+			return 0, errors.Wrap(err, "failed to get user by email")
+		}
+	}
+
+	name := author.Name
+	avatarType := enum.AvatarTypeLetter
+	if author.Email != "" {
+		avatarType = enum.AvatarTypeGravatar
+		if name == "" {
+			name = strings.Split(author.Email, "@")[0]
+		}
+	}
+
+	err := imp.trx.Scalar(&id,
+		"INSERT INTO users (name, email, created_at, tenant_id, role, status, avatar_type, avatar_bkey) VALUES ($1, $2, $3, $4, $5, $6, $7, '') RETURNING id",
+		name, author.Email, time.Now(), imp.tenant.ID, enum.RoleVisitor, enum.UserActive, avatarType,
+	)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to create imported user")
+	}
+
+	imp.users[key] = id
+	imp.report.Count("users")
+	return id, nil
+}
+
+func (imp *recordsImporter) tagID(name string) (int, error) {
+	tagSlug := slug.Make(name)
+	if id, ok := imp.tags[tagSlug]; ok {
+		return id, nil
+	}
+
+	var id int
+	err := imp.trx.Scalar(&id, "SELECT id FROM tags WHERE tenant_id = $1 AND slug = $2", imp.tenant.ID, tagSlug)
+	if err == app.ErrNotFound {
+		err = imp.trx.Scalar(&id, `
+			INSERT INTO tags (name, slug, color, is_public, created_at, tenant_id)
+			VALUES ($1, $2, $3, true, $4, $5) RETURNING id`,
+			name, tagSlug, importedTagColor, time.Now(), imp.tenant.ID,
+		)
+		imp.report.Count("tags")
+	}
+
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to get or create tag '%s'", name)
+	}
+
+	imp.tags[tagSlug] = id
+	return id, nil
+}
+
+func orNow(t time.Time) time.Time {
+	if t.IsZero() {
+		return time.Now()
+	}
+	return t
+}
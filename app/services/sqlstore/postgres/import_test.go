@@ -0,0 +1,117 @@
+package postgres_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/dto"
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/models/query"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/bus"
+)
+
+func TestImportStorage_PostsCommentsAndVotes(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	importPosts := &cmd.ImportRecords{
+		Report: dto.NewImportReport(false),
+		Posts: []*dto.ImportPost{
+			{
+				Line:      2,
+				Number:    10,
+				Title:     "Add dark mode",
+				CreatedAt: time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC),
+				Author:    dto.ImportUser{Email: "jon.snow@got.com"},
+				Status:    enum.PostPlanned,
+				Response:  "Coming soon",
+				Tags:      []string{"UI"},
+			},
+			{
+				Line:   3,
+				Title:  "Add light mode",
+				Author: dto.ImportUser{Name: "Bran Stark"},
+				Status: enum.PostOpen,
+			},
+		},
+	}
+	err := bus.Dispatch(jonSnowCtx, importPosts)
+	Expect(err).IsNil()
+	Expect(importPosts.Report.Errors).HasLen(0)
+	Expect(importPosts.Report.Imported).Equals(map[string]int{"posts": 2, "users": 1, "tags": 1})
+
+	getPost := &query.GetPostByNumber{Number: 10}
+	err = bus.Dispatch(jonSnowCtx, getPost)
+	Expect(err).IsNil()
+	Expect(getPost.Result.Title).Equals("Add dark mode")
+	Expect(getPost.Result.User.ID).Equals(jonSnow.ID)
+	Expect(getPost.Result.Status).Equals(enum.PostPlanned)
+	Expect(getPost.Result.Response.Text).Equals("Coming soon")
+	Expect(getPost.Result.Tags).Equals([]string{"ui"})
+
+	getPost = &query.GetPostByNumber{Number: 11}
+	err = bus.Dispatch(jonSnowCtx, getPost)
+	Expect(err).IsNil()
+	Expect(getPost.Result.User.Name).Equals("Bran Stark")
+
+	importOthers := &cmd.ImportRecords{
+		Report: dto.NewImportReport(false),
+		Comments: []*dto.ImportComment{
+			{Line: 2, PostNumber: 10, Content: "Finally!", Author: dto.ImportUser{Email: "arya.stark@got.com"}},
+		},
+		Votes: []*dto.ImportVote{
+			{Line: 2, PostNumber: 10, Author: dto.ImportUser{Email: "arya.stark@got.com"}},
+			{Line: 3, PostNumber: 10, Author: dto.ImportUser{Email: "arya.stark@got.com"}},
+		},
+	}
+	err = bus.Dispatch(jonSnowCtx, importOthers)
+	Expect(err).IsNil()
+	Expect(importOthers.Report.Errors).HasLen(0)
+	Expect(importOthers.Report.Warnings).HasLen(1)
+	Expect(importOthers.Report.Imported).Equals(map[string]int{"comments": 1, "votes": 1})
+
+	getPost = &query.GetPostByNumber{Number: 10}
+	err = bus.Dispatch(jonSnowCtx, getPost)
+	Expect(err).IsNil()
+	Expect(getPost.Result.VotesCount).Equals(1)
+	Expect(getPost.Result.CommentsCount).Equals(1)
+}
+
+func TestImportStorage_DryRunAndErrorsAreRolledBack(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	dryRun := &cmd.ImportRecords{
+		Report: dto.NewImportReport(true),
+		Posts: []*dto.ImportPost{
+			{Line: 2, Title: "Add dark mode", Author: dto.ImportUser{Email: "new.user@got.com"}},
+		},
+	}
+	err := bus.Dispatch(jonSnowCtx, dryRun)
+	Expect(err).IsNil()
+	Expect(dryRun.Report.Imported).Equals(map[string]int{"posts": 1, "users": 1})
+
+	withErrors := &cmd.ImportRecords{
+		Report: dto.NewImportReport(false),
+		Posts: []*dto.ImportPost{
+			{Line: 2, Title: "Add dark mode", Author: dto.ImportUser{Email: "jon.snow@got.com"}},
+		},
+		Comments: []*dto.ImportComment{
+			{Line: 2, PostNumber: 999, Content: "Hello", Author: dto.ImportUser{Email: "jon.snow@got.com"}},
+		},
+	}
+	err = bus.Dispatch(jonSnowCtx, withErrors)
+	Expect(err).IsNil()
+	Expect(withErrors.Report.Errors).Equals([]string{"Line 2: post 999 was not found."})
+
+	countPosts := &query.CountPostPerStatus{}
+	err = bus.Dispatch(jonSnowCtx, countPosts)
+	Expect(err).IsNil()
+	Expect(countPosts.Result[enum.PostOpen]).Equals(0)
+
+	getUser := &query.GetUserByEmail{Email: "new.user@got.com"}
+	err = bus.Dispatch(jonSnowCtx, getUser)
+	Expect(err).IsNotNil()
+}
@@ -2,6 +2,7 @@ package postgres
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"time"
 
@@ -183,7 +184,7 @@ func getActiveSubscribers(ctx context.Context, q *query.GetActiveSubscribers) er
 
 		if len(q.Event.RequiresSubscriptionUserRoles) == 0 {
 			err = trx.Select(&users, `
-				SELECT DISTINCT u.id, u.name, u.email, u.tenant_id, u.role, u.status
+				SELECT DISTINCT u.id, u.name, u.email, u.tenant_id, u.role, u.status, u.locale
 				FROM users u
 				LEFT JOIN user_settings set
 				ON set.user_id = u.id
@@ -204,7 +205,7 @@ func getActiveSubscribers(ctx context.Context, q *query.GetActiveSubscribers) er
 			)
 		} else {
 			err = trx.Select(&users, `
-				SELECT DISTINCT u.id, u.name, u.email, u.tenant_id, u.role, u.status
+				SELECT DISTINCT u.id, u.name, u.email, u.tenant_id, u.role, u.status, u.locale
 				FROM users u
 				LEFT JOIN post_subscribers sub
 				ON sub.user_id = u.id
@@ -245,6 +246,199 @@ func getActiveSubscribers(ctx context.Context, q *query.GetActiveSubscribers) er
 	})
 }
 
+type dbDigestItem struct {
+	ID         int            `db:"id"`
+	UserID     int            `db:"user_id"`
+	UserName   string         `db:"user_name"`
+	UserEmail  string         `db:"user_email"`
+	UserRole   int            `db:"user_role"`
+	UserLocale string         `db:"user_locale"`
+	Event      string         `db:"event"`
+	Frequency  string         `db:"frequency"`
+	Timezone   sql.NullString `db:"timezone"`
+	Title      string         `db:"title"`
+	Link       sql.NullString `db:"link"`
+	BaseURL    string         `db:"base_url"`
+	CreatedAt  time.Time      `db:"created_at"`
+}
+
+func (i *dbDigestItem) toModel() *models.DigestItem {
+	return &models.DigestItem{
+		ID: i.ID,
+		User: &models.User{
+			ID:     i.UserID,
+			Name:   i.UserName,
+			Email:  i.UserEmail,
+			Role:   enum.Role(i.UserRole),
+			Status: enum.UserActive,
+			Locale: i.UserLocale,
+		},
+		Event:     i.Event,
+		Frequency: enum.NotificationDigest(i.Frequency),
+		Timezone:  i.Timezone.String,
+		Title:     i.Title,
+		Link:      i.Link.String,
+		BaseURL:   i.BaseURL,
+		CreatedAt: i.CreatedAt,
+	}
+}
+
+func addToDigest(ctx context.Context, c *cmd.AddToDigest) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *models.Tenant, user *models.User) error {
+		c.Result = make([]*models.User, 0)
+		if len(c.Users) == 0 {
+			return nil
+		}
+
+		ids := make([]int, len(c.Users))
+		for i, u := range c.Users {
+			ids[i] = u.ID
+		}
+
+		var settings []*struct {
+			UserID int    `db:"user_id"`
+			Value  string `db:"value"`
+		}
+		err := trx.Select(&settings, `
+			SELECT user_id, value FROM user_settings
+			WHERE tenant_id = $1 AND key = $2 AND user_id = ANY($3) AND value = ANY($4)
+		`, tenant.ID, c.Event.DigestSettingsKeyName, pq.Array(ids), pq.Array([]string{
+			string(enum.NotificationDigestDaily),
+			string(enum.NotificationDigestWeekly),
+		}))
+		if err != nil {
+			return errors.Wrap(err, "failed to get digest settings")
+		}
+
+		frequencies := make(map[int]string, len(settings))
+		for _, s := range settings {
+			frequencies[s.UserID] = s.Value
+		}
+
+		var postID interface{}
+		if c.PostID > 0 {
+			postID = c.PostID
+		}
+
+		now := time.Now()
+		for _, u := range c.Users {
+			frequency, ok := frequencies[u.ID]
+			if !ok {
+				continue
+			}
+
+			_, err := trx.Execute(`
+				INSERT INTO digest_items (tenant_id, user_id, post_id, event, frequency, title, link, base_url, created_at)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			`, tenant.ID, u.ID, postID, c.Event.UserSettingsKeyName, frequency, c.Title, c.Link, c.BaseURL, now)
+			if err != nil {
+				return errors.Wrap(err, "failed to insert digest item")
+			}
+			c.Result = append(c.Result, u)
+		}
+
+		return nil
+	})
+}
+
+func markDigestItemsAsSent(ctx context.Context, c *cmd.MarkDigestItemsAsSent) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *models.Tenant, user *models.User) error {
+		c.Result = make([]int, 0)
+		if len(c.ItemIDs) == 0 {
+			return nil
+		}
+
+		//Only items that are still pending are returned, so that concurrent runs never send the same item twice
+		var items []*struct {
+			ID int `db:"id"`
+		}
+		err := trx.Select(&items, `
+			UPDATE digest_items SET sent_at = $3
+			WHERE tenant_id = $1 AND id = ANY($2) AND sent_at IS NULL
+			RETURNING id
+		`, tenant.ID, pq.Array(c.ItemIDs), time.Now())
+		if err != nil {
+			return errors.Wrap(err, "failed to mark digest items as sent")
+		}
+
+		for _, item := range items {
+			c.Result = append(c.Result, item.ID)
+		}
+		return nil
+	})
+}
+
+func markDigestItemsAsPending(ctx context.Context, c *cmd.MarkDigestItemsAsPending) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *models.Tenant, user *models.User) error {
+		if len(c.ItemIDs) == 0 {
+			return nil
+		}
+
+		_, err := trx.Execute(`
+			UPDATE digest_items SET sent_at = NULL
+			WHERE tenant_id = $1 AND id = ANY($2)
+		`, tenant.ID, pq.Array(c.ItemIDs))
+		if err != nil {
+			return errors.Wrap(err, "failed to mark digest items as pending")
+		}
+		return nil
+	})
+}
+
+func getTenantsWithPendingDigest(ctx context.Context, q *query.GetTenantsWithPendingDigest) error {
+	return using(ctx, func(trx *dbx.Trx, _ *models.Tenant, _ *models.User) error {
+		var tenants []*dbTenant
+		err := trx.Select(&tenants, `
+			SELECT t.id, t.name, t.subdomain, t.cname, t.invitation, t.welcome_message, t.status, t.is_private, t.is_roadmap_public, t.voting_mode, t.vote_budget, t.logo_bkey, t.custom_css, t.locale, t.allowed_file_types
+			FROM tenants t
+			WHERE t.status = $1
+			AND EXISTS (SELECT 1 FROM digest_items d WHERE d.tenant_id = t.id AND d.sent_at IS NULL)
+			ORDER BY t.id
+		`, enum.TenantActive)
+		if err != nil {
+			return errors.Wrap(err, "failed to get tenants with pending digest")
+		}
+
+		q.Result = make([]*models.Tenant, len(tenants))
+		for i, t := range tenants {
+			q.Result[i] = t.toModel()
+		}
+		return nil
+	})
+}
+
+func getPendingDigestItems(ctx context.Context, q *query.GetPendingDigestItems) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *models.Tenant, _ *models.User) error {
+		var items []*dbDigestItem
+		err := trx.Select(&items, `
+			SELECT d.id, d.event, d.frequency, d.title, d.link, d.base_url, d.created_at,
+						 u.id AS user_id, u.name AS user_name, u.email AS user_email, u.role AS user_role, u.locale AS user_locale,
+						 tz.value AS timezone
+			FROM digest_items d
+			INNER JOIN users u
+			ON u.id = d.user_id
+			AND u.tenant_id = d.tenant_id
+			LEFT JOIN user_settings tz
+			ON tz.user_id = d.user_id
+			AND tz.tenant_id = d.tenant_id
+			AND tz.key = $2
+			WHERE d.tenant_id = $1
+			AND d.sent_at IS NULL
+			AND u.status = $3
+			ORDER BY d.user_id, d.created_at, d.id
+		`, tenant.ID, enum.TimezoneSettingsKeyName, enum.UserActive)
+		if err != nil {
+			return errors.Wrap(err, "failed to get pending digest items")
+		}
+
+		q.Result = make([]*models.DigestItem, len(items))
+		for i, item := range items {
+			q.Result[i] = item.toModel()
+		}
+		return nil
+	})
+}
+
 func internalAddSubscriber(trx *dbx.Trx, post *models.Post, tenant *models.Tenant, user *models.User, force bool) error {
 	conflict := " DO NOTHING"
 	if force {
@@ -5,6 +5,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/models/enum"
 	"github.com/getfider/fider/app/models/query"
 
 	"github.com/getfider/fider/app/models/cmd"
@@ -181,3 +183,75 @@ func TestNotificationStorage_PurgeExpiredNotifications(t *testing.T) {
 	bus.Publish(context.Background(), purgeCommand)
 	Expect(purgeCommand.NumOfDeletedNotifications).Equals(2)
 }
+
+func TestNotificationStorage_Digest(t *testing.T) {
+	ctx := SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	err := bus.Dispatch(aryaStarkCtx, &cmd.UpdateCurrentUserSettings{
+		Settings: map[string]string{
+			enum.NotificationEventNewComment.DigestSettingsKeyName: string(enum.NotificationDigestDaily),
+			enum.TimezoneSettingsKeyName:                           "Europe/Berlin",
+		},
+	})
+	Expect(err).IsNil()
+
+	newPost := &cmd.AddNewPost{Title: "Title", Description: "Description"}
+	err = bus.Dispatch(jonSnowCtx, newPost)
+	Expect(err).IsNil()
+
+	addToDigest := &cmd.AddToDigest{
+		Users:   []*models.User{aryaStark, sansaStark},
+		Event:   enum.NotificationEventNewComment,
+		Title:   "**Jon Snow** left a comment on **Title**",
+		Link:    "/posts/1/title",
+		BaseURL: "http://demo.test.fider.io:3000",
+		PostID:  newPost.Result.ID,
+	}
+	err = bus.Dispatch(jonSnowCtx, addToDigest)
+	Expect(err).IsNil()
+	Expect(addToDigest.Result).HasLen(1)
+	Expect(addToDigest.Result[0].ID).Equals(aryaStark.ID)
+
+	getTenants := &query.GetTenantsWithPendingDigest{}
+	err = bus.Dispatch(ctx, getTenants)
+	Expect(err).IsNil()
+	Expect(getTenants.Result).HasLen(1)
+	Expect(getTenants.Result[0].ID).Equals(demoTenant.ID)
+
+	getItems := &query.GetPendingDigestItems{}
+	err = bus.Dispatch(demoTenantCtx, getItems)
+	Expect(err).IsNil()
+	Expect(getItems.Result).HasLen(1)
+	Expect(getItems.Result[0].User.ID).Equals(aryaStark.ID)
+	Expect(getItems.Result[0].User.Email).Equals(aryaStark.Email)
+	Expect(getItems.Result[0].Event).Equals(enum.NotificationEventNewComment.UserSettingsKeyName)
+	Expect(getItems.Result[0].Frequency).Equals(enum.NotificationDigestDaily)
+	Expect(getItems.Result[0].Timezone).Equals("Europe/Berlin")
+	Expect(getItems.Result[0].Title).Equals("**Jon Snow** left a comment on **Title**")
+	Expect(getItems.Result[0].Link).Equals("/posts/1/title")
+	Expect(getItems.Result[0].BaseURL).Equals("http://demo.test.fider.io:3000")
+
+	markAsSent := &cmd.MarkDigestItemsAsSent{ItemIDs: []int{getItems.Result[0].ID}}
+	err = bus.Dispatch(demoTenantCtx, markAsSent)
+	Expect(err).IsNil()
+	Expect(markAsSent.Result).Equals([]int{getItems.Result[0].ID})
+
+	markAsSent = &cmd.MarkDigestItemsAsSent{ItemIDs: []int{getItems.Result[0].ID}}
+	err = bus.Dispatch(demoTenantCtx, markAsSent)
+	Expect(err).IsNil()
+	Expect(markAsSent.Result).HasLen(0)
+
+	getItems = &query.GetPendingDigestItems{}
+	err = bus.Dispatch(demoTenantCtx, getItems)
+	Expect(err).IsNil()
+	Expect(getItems.Result).HasLen(0)
+
+	err = bus.Dispatch(demoTenantCtx, &cmd.MarkDigestItemsAsPending{ItemIDs: markAsSent.ItemIDs})
+	Expect(err).IsNil()
+
+	getItems = &query.GetPendingDigestItems{}
+	err = bus.Dispatch(demoTenantCtx, getItems)
+	Expect(err).IsNil()
+	Expect(getItems.Result).HasLen(1)
+}
@@ -15,11 +15,13 @@ import (
 type dbOAuthConfig struct {
 	ID                int    `db:"id"`
 	Provider          string `db:"provider"`
+	Protocol          int    `db:"protocol"`
 	DisplayName       string `db:"display_name"`
 	LogoBlobKey       string `db:"logo_bkey"`
 	Status            int    `db:"status"`
 	ClientID          string `db:"client_id"`
 	ClientSecret      string `db:"client_secret"`
+	IssuerURL         string `db:"issuer_url"`
 	AuthorizeURL      string `db:"authorize_url"`
 	TokenURL          string `db:"token_url"`
 	Scope             string `db:"scope"`
@@ -33,11 +35,13 @@ func (m *dbOAuthConfig) toModel() *models.OAuthConfig {
 	return &models.OAuthConfig{
 		ID:                m.ID,
 		Provider:          m.Provider,
+		Protocol:          m.Protocol,
 		DisplayName:       m.DisplayName,
 		Status:            m.Status,
 		LogoBlobKey:       m.LogoBlobKey,
 		ClientID:          m.ClientID,
 		ClientSecret:      m.ClientSecret,
+		IssuerURL:         m.IssuerURL,
 		AuthorizeURL:      m.AuthorizeURL,
 		TokenURL:          m.TokenURL,
 		ProfileURL:        m.ProfileURL,
@@ -56,8 +60,8 @@ func getCustomOAuthConfigByProvider(ctx context.Context, q *query.GetCustomOAuth
 
 		config := &dbOAuthConfig{}
 		err := trx.Get(config, `
-		SELECT id, provider, display_name, status, logo_bkey,
-					 client_id, client_secret, authorize_url,
+		SELECT id, provider, protocol, display_name, status, logo_bkey,
+					 client_id, client_secret, issuer_url, authorize_url,
 					 profile_url, token_url, scope, json_user_id_path,
 					 json_user_name_path, json_user_email_path
 		FROM oauth_providers
@@ -77,8 +81,8 @@ func listCustomOAuthConfig(ctx context.Context, q *query.ListCustomOAuthConfig)
 		configs := []*dbOAuthConfig{}
 		if tenant != nil {
 			err := trx.Select(&configs, `
-			SELECT id, provider, display_name, status, logo_bkey,
-						 client_id, client_secret, authorize_url,
+			SELECT id, provider, protocol, display_name, status, logo_bkey,
+						 client_id, client_secret, issuer_url, authorize_url,
 						 profile_url, token_url, scope, json_user_id_path,
 						 json_user_name_path, json_user_email_path
 			FROM oauth_providers
@@ -110,29 +114,32 @@ func saveCustomOAuthConfig(ctx context.Context, c *cmd.SaveCustomOAuthConfig) er
 				tenant_id, provider, display_name, status,
 				client_id, client_secret, authorize_url,
 				profile_url, token_url, scope, json_user_id_path,
-				json_user_name_path, json_user_email_path, logo_bkey
-			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+				json_user_name_path, json_user_email_path, logo_bkey,
+				protocol, issuer_url
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
 			RETURNING id`
 
 			err = trx.Get(&c.Config.ID, query, tenant.ID, c.Config.Provider,
 				c.Config.DisplayName, c.Config.Status, c.Config.ClientID, c.Config.ClientSecret,
 				c.Config.AuthorizeURL, c.Config.ProfileURL, c.Config.TokenURL,
 				c.Config.Scope, c.Config.JSONUserIDPath, c.Config.JSONUserNamePath,
-				c.Config.JSONUserEmailPath, c.Config.Logo.BlobKey)
+				c.Config.JSONUserEmailPath, c.Config.Logo.BlobKey,
+				c.Config.Protocol, c.Config.IssuerURL)
 		} else {
 			query := `
 				UPDATE oauth_providers 
 				SET display_name = $3, status = $4, client_id = $5, client_secret = $6, 
 						authorize_url = $7, profile_url = $8, token_url = $9, scope = $10, 
 						json_user_id_path = $11, json_user_name_path = $12, json_user_email_path = $13,
-						logo_bkey = $14
+						logo_bkey = $14, protocol = $15, issuer_url = $16
 			WHERE tenant_id = $1 AND id = $2`
 
 			_, err = trx.Execute(query, tenant.ID, c.Config.ID,
 				c.Config.DisplayName, c.Config.Status, c.Config.ClientID, c.Config.ClientSecret,
 				c.Config.AuthorizeURL, c.Config.ProfileURL, c.Config.TokenURL,
 				c.Config.Scope, c.Config.JSONUserIDPath, c.Config.JSONUserNamePath,
-				c.Config.JSONUserEmailPath, c.Config.Logo.BlobKey)
+				c.Config.JSONUserEmailPath, c.Config.Logo.BlobKey,
+				c.Config.Protocol, c.Config.IssuerURL)
 		}
 
 		if err != nil {
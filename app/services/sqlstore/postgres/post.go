@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/getfider/fider/app/models/enum"
@@ -16,6 +17,7 @@ import (
 
 	"github.com/getfider/fider/app/models"
 	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/pkg/cursor"
 	"github.com/getfider/fider/app/pkg/dbx"
 	"github.com/getfider/fider/app/pkg/errors"
 )
@@ -27,12 +29,16 @@ type dbPost struct {
 	Slug           string         `db:"slug"`
 	Description    string         `db:"description"`
 	CreatedAt      time.Time      `db:"created_at"`
+	UpdatedAt      time.Time      `db:"updated_at"`
 	User           *dbUser        `db:"user"`
 	HasVoted       bool           `db:"has_voted"`
 	VotesCount     int            `db:"votes_count"`
 	CommentsCount  int            `db:"comments_count"`
 	RecentVotes    int            `db:"recent_votes_count"`
 	RecentComments int            `db:"recent_comments_count"`
+	Score          int            `db:"score"`
+	RecentScore    int            `db:"recent_score"`
+	VotedCredits   int            `db:"voted_credits"`
 	Status         int            `db:"status"`
 	Response       sql.NullString `db:"response"`
 	RespondedAt    dbx.NullTime   `db:"response_date"`
@@ -52,8 +58,11 @@ func (i *dbPost) toModel(ctx context.Context) *models.Post {
 		Slug:          i.Slug,
 		Description:   i.Description,
 		CreatedAt:     i.CreatedAt,
+		UpdatedAt:     i.UpdatedAt,
 		HasVoted:      i.HasVoted,
 		VotesCount:    i.VotesCount,
+		Score:         i.Score,
+		VotedCredits:  i.VotedCredits,
 		CommentsCount: i.CommentsCount,
 		Status:        enum.PostStatus(i.Status),
 		User:          i.User.toModel(ctx),
@@ -78,6 +87,22 @@ func (i *dbPost) toModel(ctx context.Context) *models.Post {
 	return post
 }
 
+type dbPostMerge struct {
+	ID         int       `db:"id"`
+	PostID     int       `db:"post_id"`
+	OriginalID int       `db:"original_id"`
+	MergedAt   time.Time `db:"merged_at"`
+}
+
+func (m *dbPostMerge) toModel() *models.PostMerge {
+	return &models.PostMerge{
+		ID:         m.ID,
+		PostID:     m.PostID,
+		OriginalID: m.OriginalID,
+		MergedAt:   m.MergedAt,
+	}
+}
+
 var (
 	sqlSelectPostsWhere = `	WITH 
 													agg_tags AS ( 
@@ -109,7 +134,9 @@ var (
 															SELECT 
 															post_id, 
 																	COUNT(CASE WHEN post_votes.created_at > CURRENT_DATE - INTERVAL '30 days'  THEN 1 END) as recent,
-																	COUNT(*) as all
+																	COUNT(*) as all,
+																	SUM(CASE WHEN post_votes.created_at > CURRENT_DATE - INTERVAL '30 days'  THEN post_votes.credits END) as recent_score,
+																	SUM(post_votes.credits) as score
 															FROM post_votes 
 															INNER JOIN posts
 															ON posts.id = post_votes.post_id
@@ -123,9 +150,12 @@ var (
 																p.slug, 
 																p.description, 
 																p.created_at,
+																COALESCE(p.updated_at, p.created_at) AS updated_at,
 																COALESCE(agg_s.all, 0) as votes_count,
 																COALESCE(agg_c.all, 0) as comments_count,
 																COALESCE(agg_s.recent, 0) AS recent_votes_count,
+																COALESCE(agg_s.score, 0) AS score,
+																COALESCE(agg_s.recent_score, 0) AS recent_score,
 																COALESCE(agg_c.recent, 0) AS recent_comments_count,																
 																p.status, 
 																u.id AS user_id, 
@@ -149,7 +179,8 @@ var (
 																d.slug AS original_slug,
 																d.status AS original_status,
 																COALESCE(agg_t.tags, ARRAY[]::text[]) AS tags,
-																COALESCE(%s, false) AS has_voted
+																COALESCE(%s, false) AS has_voted,
+																COALESCE(%s, 0) AS voted_credits
 													FROM posts p
 													INNER JOIN users u
 													ON u.id = p.user_id
@@ -202,13 +233,19 @@ func setPostResponse(ctx context.Context, c *cmd.SetPostResponse) error {
 
 		_, err := trx.Execute(`
 		UPDATE posts 
-		SET response = $3, original_id = NULL, response_date = $4, response_user_id = $5, status = $6 
+		SET response = $3, original_id = NULL, response_date = $4, response_user_id = $5, status = $6, updated_at = NOW() 
 		WHERE id = $1 and tenant_id = $2
 		`, c.Post.ID, tenant.ID, c.Text, respondedAt, user.ID, c.Status)
 		if err != nil {
 			return errors.Wrap(err, "failed to update post's response")
 		}
 
+		if c.Post.Status != c.Status {
+			if err := addPostStatusChange(trx, tenant, user, c.Post.ID); err != nil {
+				return err
+			}
+		}
+
 		c.Post.Status = c.Status
 		c.Post.Response = &models.PostResponse{
 			Text:        c.Text,
@@ -241,13 +278,19 @@ func markPostAsDuplicate(ctx context.Context, c *cmd.MarkPostAsDuplicate) error
 
 		_, err = trx.Execute(`
 		UPDATE posts 
-		SET response = '', original_id = $3, response_date = $4, response_user_id = $5, status = $6 
+		SET response = '', original_id = $3, response_date = $4, response_user_id = $5, status = $6, updated_at = NOW() 
 		WHERE id = $1 and tenant_id = $2
 		`, c.Post.ID, tenant.ID, c.Original.ID, respondedAt, user.ID, enum.PostDuplicate)
 		if err != nil {
 			return errors.Wrap(err, "failed to update post's response")
 		}
 
+		if c.Post.Status != enum.PostDuplicate {
+			if err := addPostStatusChange(trx, tenant, user, c.Post.ID); err != nil {
+				return err
+			}
+		}
+
 		c.Post.Status = enum.PostDuplicate
 		c.Post.Response = &models.PostResponse{
 			RespondedAt: respondedAt,
@@ -263,6 +306,176 @@ func markPostAsDuplicate(ctx context.Context, c *cmd.MarkPostAsDuplicate) error
 	})
 }
 
+func mergePost(ctx context.Context, c *cmd.MergePost) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *models.Tenant, user *models.User) error {
+		now := time.Now()
+
+		_, err := trx.Execute(`
+		INSERT INTO post_merges (tenant_id, post_id, original_id, prev_status, prev_response, prev_response_date, prev_response_user_id, prev_original_id, merged_by_id, merged_at)
+		SELECT tenant_id, id, $3, status, response, response_date, response_user_id, original_id, $4, $5
+		FROM posts
+		WHERE id = $1 AND tenant_id = $2
+		`, c.Post.ID, tenant.ID, c.Original.ID, user.ID, now)
+		if err != nil {
+			return errors.Wrap(err, "failed to record merge of post with id '%d'", c.Post.ID)
+		}
+
+		_, err = trx.Execute(`
+		UPDATE post_votes SET post_id = $3, merged_from_id = $1
+		WHERE post_id = $1 AND tenant_id = $2
+		AND user_id NOT IN (SELECT user_id FROM post_votes WHERE post_id = $3 AND tenant_id = $2)
+		`, c.Post.ID, tenant.ID, c.Original.ID)
+		if err != nil {
+			return errors.Wrap(err, "failed to move votes of post with id '%d'", c.Post.ID)
+		}
+
+		_, err = trx.Execute(`
+		UPDATE attachments SET post_id = $3
+		WHERE post_id = $1 AND tenant_id = $2 AND comment_id IS NOT NULL
+		`, c.Post.ID, tenant.ID, c.Original.ID)
+		if err != nil {
+			return errors.Wrap(err, "failed to move comment attachments of post with id '%d'", c.Post.ID)
+		}
+
+		_, err = trx.Execute(`
+		UPDATE comments SET post_id = $3, merged_from_id = $1
+		WHERE post_id = $1 AND tenant_id = $2
+		`, c.Post.ID, tenant.ID, c.Original.ID)
+		if err != nil {
+			return errors.Wrap(err, "failed to move comments of post with id '%d'", c.Post.ID)
+		}
+
+		_, err = trx.Execute(`
+		INSERT INTO post_subscribers (tenant_id, user_id, post_id, created_at, updated_at, status, merged_from_id)
+		SELECT tenant_id, user_id, $3, $4, $4, status, $1
+		FROM post_subscribers
+		WHERE post_id = $1 AND tenant_id = $2
+		ON CONFLICT DO NOTHING
+		`, c.Post.ID, tenant.ID, c.Original.ID, now)
+		if err != nil {
+			return errors.Wrap(err, "failed to copy subscribers of post with id '%d'", c.Post.ID)
+		}
+
+		_, err = trx.Execute(`
+		UPDATE posts 
+		SET response = '', original_id = $3, response_date = $4, response_user_id = $5, status = $6, updated_at = NOW() 
+		WHERE id = $1 and tenant_id = $2
+		`, c.Post.ID, tenant.ID, c.Original.ID, now, user.ID, enum.PostDuplicate)
+		if err != nil {
+			return errors.Wrap(err, "failed to update post's response")
+		}
+
+		if err := addPostStatusChange(trx, tenant, user, c.Post.ID); err != nil {
+			return err
+		}
+
+		c.Post.Status = enum.PostDuplicate
+		c.Post.Response = &models.PostResponse{
+			RespondedAt: now,
+			User:        user,
+			Original: &models.OriginalPost{
+				Number: c.Original.Number,
+				Title:  c.Original.Title,
+				Slug:   c.Original.Slug,
+				Status: c.Original.Status,
+			},
+		}
+
+		q := &query.GetPostByID{PostID: c.Original.ID}
+		if err := getPostByID(ctx, q); err != nil {
+			return err
+		}
+		c.Result = q.Result
+		return nil
+	})
+}
+
+func unmergePost(ctx context.Context, c *cmd.UnmergePost) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *models.Tenant, user *models.User) error {
+		postID, originalID := c.Merge.PostID, c.Merge.OriginalID
+
+		_, err := trx.Execute(`
+		UPDATE post_votes SET post_id = $1, merged_from_id = NULL
+		WHERE post_id = $3 AND merged_from_id = $1 AND tenant_id = $2
+		`, postID, tenant.ID, originalID)
+		if err != nil {
+			return errors.Wrap(err, "failed to move votes back to post with id '%d'", postID)
+		}
+
+		_, err = trx.Execute(`
+		UPDATE comments SET post_id = $1, merged_from_id = NULL
+		WHERE post_id = $3 AND merged_from_id = $1 AND tenant_id = $2
+		`, postID, tenant.ID, originalID)
+		if err != nil {
+			return errors.Wrap(err, "failed to move comments back to post with id '%d'", postID)
+		}
+
+		_, err = trx.Execute(`
+		UPDATE attachments SET post_id = $1
+		WHERE post_id = $3 AND tenant_id = $2
+		AND comment_id IN (SELECT id FROM comments WHERE post_id = $1 AND tenant_id = $2)
+		`, postID, tenant.ID, originalID)
+		if err != nil {
+			return errors.Wrap(err, "failed to move comment attachments back to post with id '%d'", postID)
+		}
+
+		_, err = trx.Execute(`
+		DELETE FROM post_subscribers WHERE post_id = $3 AND merged_from_id = $1 AND tenant_id = $2
+		`, postID, tenant.ID, originalID)
+		if err != nil {
+			return errors.Wrap(err, "failed to remove merged subscribers from post with id '%d'", originalID)
+		}
+
+		_, err = trx.Execute(`
+		UPDATE posts p
+		SET status = m.prev_status, response = m.prev_response, response_date = m.prev_response_date, 
+				response_user_id = m.prev_response_user_id, original_id = m.prev_original_id, updated_at = NOW()
+		FROM post_merges m
+		WHERE m.id = $3 AND m.tenant_id = $2 AND p.id = $1 AND p.tenant_id = $2
+		`, postID, tenant.ID, c.Merge.ID)
+		if err != nil {
+			return errors.Wrap(err, "failed to restore status of post with id '%d'", postID)
+		}
+
+		if err := addPostStatusChange(trx, tenant, user, postID); err != nil {
+			return err
+		}
+
+		_, err = trx.Execute(`
+		UPDATE post_merges SET unmerged_at = $3, unmerged_by_id = $4 WHERE id = $1 AND tenant_id = $2
+		`, c.Merge.ID, tenant.ID, time.Now(), user.ID)
+		if err != nil {
+			return errors.Wrap(err, "failed to record unmerge of post with id '%d'", postID)
+		}
+
+		q := &query.GetPostByID{PostID: postID}
+		if err := getPostByID(ctx, q); err != nil {
+			return err
+		}
+		c.Result = q.Result
+		return nil
+	})
+}
+
+func getActivePostMerge(ctx context.Context, q *query.GetActivePostMerge) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *models.Tenant, user *models.User) error {
+		q.Result = nil
+
+		merge := dbPostMerge{}
+		err := trx.Get(&merge, `
+		SELECT id, post_id, original_id, merged_at 
+		FROM post_merges 
+		WHERE post_id = $1 AND tenant_id = $2 AND unmerged_at IS NULL
+		`, q.PostID, tenant.ID)
+		if err != nil {
+			return errors.Wrap(err, "failed to get active merge of post with id '%d'", q.PostID)
+		}
+
+		q.Result = merge.toModel()
+		return nil
+	})
+}
+
 func countPostPerStatus(ctx context.Context, q *query.CountPostPerStatus) error {
 	return using(ctx, func(trx *dbx.Trx, tenant *models.Tenant, user *models.User) error {
 
@@ -296,6 +509,10 @@ func addNewPost(ctx context.Context, c *cmd.AddNewPost) error {
 			return errors.Wrap(err, "failed add new post")
 		}
 
+		if err := addPostStatusChange(trx, tenant, user, id); err != nil {
+			return err
+		}
+
 		q := &query.GetPostByID{PostID: id}
 		if err := getPostByID(ctx, q); err != nil {
 			return err
@@ -312,7 +529,7 @@ func addNewPost(ctx context.Context, c *cmd.AddNewPost) error {
 
 func updatePost(ctx context.Context, c *cmd.UpdatePost) error {
 	return using(ctx, func(trx *dbx.Trx, tenant *models.Tenant, user *models.User) error {
-		_, err := trx.Execute(`UPDATE posts SET title = $1, slug = $2, description = $3 
+		_, err := trx.Execute(`UPDATE posts SET title = $1, slug = $2, description = $3, updated_at = NOW() 
 													 WHERE id = $4 AND tenant_id = $5`, c.Title, slug.Make(c.Title), c.Description, c.Post.ID, tenant.ID)
 		if err != nil {
 			return errors.Wrap(err, "failed update post")
@@ -360,51 +577,175 @@ func getPostByNumber(ctx context.Context, q *query.GetPostByNumber) error {
 	})
 }
 
+type postSortField struct {
+	column string
+	cast   string
+	value  func(post *dbPost) string
+}
+
+var postSortFields = map[string]postSortField{
+	"id": {"id", "int", func(post *dbPost) string {
+		return strconv.Itoa(post.ID)
+	}},
+	"created": {"created_at", "timestamptz", func(post *dbPost) string {
+		return post.CreatedAt.Format(time.RFC3339Nano)
+	}},
+	"updated": {"updated_at", "timestamptz", func(post *dbPost) string {
+		return post.UpdatedAt.Format(time.RFC3339Nano)
+	}},
+	"votes": {"votes_count", "int", func(post *dbPost) string {
+		return strconv.Itoa(post.VotesCount)
+	}},
+	"score": {"score", "int", func(post *dbPost) string {
+		return strconv.Itoa(post.Score)
+	}},
+	"comments": {"comments_count", "int", func(post *dbPost) string {
+		return strconv.Itoa(post.CommentsCount)
+	}},
+}
+
+func parseCursorValue(value, cast string) error {
+	var err error
+	if cast == "timestamptz" {
+		_, err = time.Parse(time.RFC3339Nano, value)
+	} else {
+		_, err = strconv.Atoi(value)
+	}
+	if err != nil {
+		return cursor.ErrInvalid
+	}
+	return nil
+}
+
+//maxSearchPostsLimit is the largest page of posts that can be requested, only internal callers can ask for all of them
+const maxSearchPostsLimit = 100
+
 func searchPosts(ctx context.Context, q *query.SearchPosts) error {
 	return using(ctx, func(trx *dbx.Trx, tenant *models.Tenant, user *models.User) error {
 		innerQuery := buildPostQuery(user, "p.tenant_id = $1 AND p.status = ANY($2)")
 
-		if q.Tags == nil {
-			q.Tags = []string{}
+		limit, err := strconv.Atoi(q.Limit)
+		if err != nil || limit < 0 {
+			limit = 30
+		} else if limit > maxSearchPostsLimit {
+			limit = maxSearchPostsLimit
 		}
 
-		if q.Limit != "all" {
-			if _, err := strconv.Atoi(q.Limit); err != nil {
-				q.Limit = "30"
+		var after *cursor.Cursor
+		if q.Cursor != "" {
+			if after, err = cursor.Parse(q.Cursor); err != nil {
+				return err
+			}
+			if q.Sort == "" {
+				q.Sort = after.Sort
+			} else if q.Sort != after.Sort {
+				return cursor.ErrInvalid
 			}
 		}
 
+		sortField, hasSortField := postSortFields[q.Sort]
+		if q.Sort != "" && !hasSortField {
+			return errors.New("invalid sort field '%s'", q.Sort)
+		}
+
+		direction := "DESC"
+		if q.Order == "asc" {
+			direction = "ASC"
+		}
+
 		var (
-			posts []*dbPost
-			err   error
+			statuses   []enum.PostStatus
+			sort       string
+			conditions []string
 		)
+		args := []interface{}{tenant.ID, nil}
+		arg := func(value interface{}) string {
+			args = append(args, value)
+			return fmt.Sprintf("$%d", len(args))
+		}
+
 		if q.Query != "" {
-			scoreField := "ts_rank(setweight(to_tsvector(title), 'A') || setweight(to_tsvector(description), 'B'), to_tsquery('english', $3)) + similarity(title, $4) + similarity(description, $4)"
-			sql := fmt.Sprintf(`
-				SELECT * FROM (%s) AS q 
-				WHERE %s > 0.1
-				ORDER BY %s DESC
-				LIMIT %s
-			`, innerQuery, scoreField, scoreField, q.Limit)
-			err = trx.Select(&posts, sql, tenant.ID, pq.Array([]enum.PostStatus{
+			tsQuery, text := arg(ToTSQuery(q.Query)), arg(q.Query)
+			scoreField := fmt.Sprintf("ts_rank(setweight(to_tsvector(title), 'A') || setweight(to_tsvector(description), 'B'), to_tsquery('english', %s)) + similarity(title, %s) + similarity(description, %s)", tsQuery, text, text)
+			conditions = append(conditions, scoreField+" > 0.1")
+			sort = scoreField
+			statuses = []enum.PostStatus{
 				enum.PostOpen,
 				enum.PostStarted,
 				enum.PostPlanned,
 				enum.PostCompleted,
 				enum.PostDeclined,
-			}), ToTSQuery(q.Query), q.Query)
+			}
 		} else {
-			condition, statuses, sort := getViewData(q.View)
-			sql := fmt.Sprintf(`
-				SELECT * FROM (%s) AS q 
-				WHERE tags @> $3 %s
-				ORDER BY %s DESC
-				LIMIT %s
-			`, innerQuery, condition, sort, q.Limit)
-			err = trx.Select(&posts, sql, tenant.ID, pq.Array(statuses), pq.Array(q.Tags))
+			var condition string
+			condition, statuses, sort = getViewData(q.View, tenant.VotingMode)
+			if condition != "" {
+				conditions = append(conditions, condition)
+			}
 		}
 
-		if err != nil {
+		if len(q.Statuses) > 0 {
+			statuses = q.Statuses
+		}
+		args[1] = pq.Array(statuses)
+
+		if len(q.Tags) > 0 {
+			operator := "@>"
+			if q.MatchAnyTag {
+				operator = "&&"
+			}
+			conditions = append(conditions, fmt.Sprintf("tags %s %s", operator, arg(pq.Array(q.Tags))))
+		}
+		if q.AuthorID > 0 {
+			conditions = append(conditions, "user_id = "+arg(q.AuthorID))
+		}
+		if !q.CreatedAfter.IsZero() {
+			conditions = append(conditions, "created_at >= "+arg(q.CreatedAfter))
+		}
+		if !q.CreatedBefore.IsZero() {
+			conditions = append(conditions, "created_at < "+arg(q.CreatedBefore))
+		}
+		if !q.UpdatedAfter.IsZero() {
+			conditions = append(conditions, "updated_at >= "+arg(q.UpdatedAfter))
+		}
+		if !q.UpdatedBefore.IsZero() {
+			conditions = append(conditions, "updated_at < "+arg(q.UpdatedBefore))
+		}
+
+		orderBy := fmt.Sprintf("%s %s", sort, direction)
+		if hasSortField {
+			//id is used as a tie breaker so that cursors always point to a single row
+			orderBy = fmt.Sprintf("%s %s, id %s", sortField.column, direction, direction)
+			if after != nil {
+				if err := parseCursorValue(after.Value, sortField.cast); err != nil {
+					return err
+				}
+				operator := "<"
+				if direction == "ASC" {
+					operator = ">"
+				}
+				conditions = append(conditions, fmt.Sprintf("(%s, id) %s (%s::%s, %s)", sortField.column, operator, arg(after.Value), sortField.cast, arg(after.ID)))
+			}
+		}
+
+		where := ""
+		if len(conditions) > 0 {
+			where = "WHERE " + strings.Join(conditions, " AND ")
+		}
+
+		var limitValue interface{}
+		if q.Limit != "all" {
+			limitValue = limit
+		}
+
+		posts := []*dbPost{}
+		sql := fmt.Sprintf(`
+			SELECT * FROM (%s) AS q 
+			%s
+			ORDER BY %s
+			LIMIT %s
+		`, innerQuery, where, orderBy, arg(limitValue))
+		if err := trx.Select(&posts, sql, args...); err != nil {
 			return errors.Wrap(err, "failed to search posts")
 		}
 
@@ -412,6 +753,11 @@ func searchPosts(ctx context.Context, q *query.SearchPosts) error {
 		for i, post := range posts {
 			q.Result[i] = post.toModel(ctx)
 		}
+
+		if hasSortField && q.Limit != "all" && limit > 0 && len(posts) == limit {
+			last := posts[len(posts)-1]
+			q.NextCursor = cursor.New(q.Sort, sortField.value(last), last.ID).String()
+		}
 		return nil
 	})
 }
@@ -427,6 +773,17 @@ func getAllPosts(ctx context.Context, q *query.GetAllPosts) error {
 	})
 }
 
+func addPostStatusChange(trx *dbx.Trx, tenant *models.Tenant, user *models.User, postID int) error {
+	_, err := trx.Execute(`
+	INSERT INTO post_status_history (tenant_id, post_id, status, changed_by_id, changed_at)
+	SELECT tenant_id, id, status, $3, NOW() FROM posts WHERE id = $1 AND tenant_id = $2
+	`, postID, tenant.ID, user.ID)
+	if err != nil {
+		return errors.Wrap(err, "failed to add status change of post with id '%d'", postID)
+	}
+	return nil
+}
+
 func querySinglePost(ctx context.Context, trx *dbx.Trx, query string, args ...interface{}) (*models.Post, error) {
 	post := dbPost{}
 
@@ -443,8 +800,10 @@ func buildPostQuery(user *models.User, filter string) string {
 		tagCondition = ``
 	}
 	hasVotedSubQuery := "null"
+	votedCreditsSubQuery := "null"
 	if user != nil {
 		hasVotedSubQuery = fmt.Sprintf("(SELECT true FROM post_votes WHERE post_id = p.id AND user_id = %d)", user.ID)
+		votedCreditsSubQuery = fmt.Sprintf("(SELECT credits FROM post_votes WHERE post_id = p.id AND user_id = %d)", user.ID)
 	}
-	return fmt.Sprintf(sqlSelectPostsWhere, tagCondition, hasVotedSubQuery, filter)
+	return fmt.Sprintf(sqlSelectPostsWhere, tagCondition, hasVotedSubQuery, votedCreditsSubQuery, filter)
 }
@@ -1,6 +1,7 @@
 package postgres_test
 
 import (
+	"fmt"
 	"io/ioutil"
 	"testing"
 	"time"
@@ -13,6 +14,7 @@ import (
 	"github.com/getfider/fider/app/models/cmd"
 	. "github.com/getfider/fider/app/pkg/assert"
 	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/cursor"
 	"github.com/getfider/fider/app/pkg/env"
 	"github.com/getfider/fider/app/pkg/errors"
 )
@@ -263,6 +265,102 @@ func TestPostStorage_AddVote(t *testing.T) {
 	Expect(getPost.Result.VotesCount).Equals(2)
 }
 
+func TestPostStorage_AddVote_WithCredits(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	newPost := &cmd.AddNewPost{Title: "My new post", Description: "with this description"}
+	err := bus.Dispatch(jonSnowCtx, newPost)
+	Expect(err).IsNil()
+
+	err = bus.Dispatch(jonSnowCtx,
+		&cmd.AddVote{Post: newPost.Result, User: aryaStark, Credits: 3},
+		&cmd.AddVote{Post: newPost.Result, User: jonSnow, Credits: 2},
+	)
+	Expect(err).IsNil()
+
+	getPost := &query.GetPostByID{PostID: newPost.Result.ID}
+	err = bus.Dispatch(jonSnowCtx, getPost)
+	Expect(err).IsNil()
+	Expect(getPost.Result.VotesCount).Equals(2)
+	Expect(getPost.Result.Score).Equals(5)
+	Expect(getPost.Result.VotedCredits).Equals(2)
+
+	err = bus.Dispatch(jonSnowCtx, &cmd.AddVote{Post: newPost.Result, User: jonSnow, Credits: 1})
+	Expect(err).IsNil()
+
+	err = bus.Dispatch(jonSnowCtx, &cmd.AddVote{Post: newPost.Result, User: aryaStark})
+	Expect(err).IsNil()
+
+	getPost = &query.GetPostByID{PostID: newPost.Result.ID}
+	err = bus.Dispatch(jonSnowCtx, getPost)
+	Expect(err).IsNil()
+	Expect(getPost.Result.VotesCount).Equals(2)
+	Expect(getPost.Result.Score).Equals(4)
+	Expect(getPost.Result.VotedCredits).Equals(1)
+}
+
+func TestPostStorage_AddVote_BudgetExceeded(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	aryaStark.Tenant.VotingMode = enum.VotingModeBudgeted
+	aryaStark.Tenant.VoteBudget = 5
+
+	newPost1 := &cmd.AddNewPost{Title: "My new post", Description: "with this description"}
+	newPost2 := &cmd.AddNewPost{Title: "My other post", Description: "with another description"}
+	err := bus.Dispatch(jonSnowCtx, newPost1, newPost2)
+	Expect(err).IsNil()
+
+	err = bus.Dispatch(aryaStarkCtx, &cmd.AddVote{Post: newPost1.Result, User: aryaStark, Credits: 4})
+	Expect(err).IsNil()
+
+	err = bus.Dispatch(aryaStarkCtx, &cmd.AddVote{Post: newPost2.Result, User: aryaStark, Credits: 2})
+	Expect(errors.Cause(err)).Equals(app.ErrVoteBudgetExceeded)
+
+	err = bus.Dispatch(aryaStarkCtx, &cmd.AddVote{Post: newPost1.Result, User: aryaStark, Credits: 5})
+	Expect(err).IsNil()
+
+	usedCredits := &query.GetUsedVoteCredits{}
+	err = bus.Dispatch(aryaStarkCtx, usedCredits)
+	Expect(err).IsNil()
+	Expect(usedCredits.Result).Equals(5)
+}
+
+func TestPostStorage_UsedVoteCredits_RefundedWhenClosed(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	newPost1 := &cmd.AddNewPost{Title: "My new post", Description: "with this description"}
+	newPost2 := &cmd.AddNewPost{Title: "My other post", Description: "with another description"}
+	err := bus.Dispatch(jonSnowCtx, newPost1, newPost2)
+	Expect(err).IsNil()
+
+	err = bus.Dispatch(aryaStarkCtx,
+		&cmd.AddVote{Post: newPost1.Result, User: aryaStark, Credits: 3},
+		&cmd.AddVote{Post: newPost2.Result, User: aryaStark, Credits: 2},
+	)
+	Expect(err).IsNil()
+
+	usedCredits := &query.GetUsedVoteCredits{}
+	err = bus.Dispatch(aryaStarkCtx, usedCredits)
+	Expect(err).IsNil()
+	Expect(usedCredits.Result).Equals(5)
+
+	usedCredits = &query.GetUsedVoteCredits{ExcludePostID: newPost2.Result.ID}
+	err = bus.Dispatch(aryaStarkCtx, usedCredits)
+	Expect(err).IsNil()
+	Expect(usedCredits.Result).Equals(3)
+
+	err = bus.Dispatch(jonSnowCtx, &cmd.SetPostResponse{Post: newPost1.Result, Text: "Done!", Status: enum.PostCompleted})
+	Expect(err).IsNil()
+
+	usedCredits = &query.GetUsedVoteCredits{}
+	err = bus.Dispatch(aryaStarkCtx, usedCredits)
+	Expect(err).IsNil()
+	Expect(usedCredits.Result).Equals(2)
+}
+
 func TestPostStorage_AddVote_Twice(t *testing.T) {
 	SetupDatabaseTest(t)
 	defer TeardownDatabaseTest()
@@ -423,6 +521,69 @@ func TestPostStorage_SetResponse_AsDuplicate(t *testing.T) {
 	Expect(getPost2.Result.Response.Original.Status).Equals(newPost1.Result.Status)
 }
 
+func TestPostStorage_MergeAndUnmerge(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	newPost1 := &cmd.AddNewPost{Title: "My new post", Description: "with this description"}
+	err := bus.Dispatch(jonSnowCtx, newPost1)
+	Expect(err).IsNil()
+
+	newPost2 := &cmd.AddNewPost{Title: "My other post", Description: "with similar description"}
+	err = bus.Dispatch(aryaStarkCtx, newPost2)
+	Expect(err).IsNil()
+
+	err = bus.Dispatch(
+		jonSnowCtx,
+		&cmd.AddVote{Post: newPost1.Result, User: jonSnow},
+		&cmd.AddVote{Post: newPost2.Result, User: jonSnow},
+		&cmd.AddVote{Post: newPost2.Result, User: aryaStark},
+	)
+	Expect(err).IsNil()
+
+	err = bus.Dispatch(aryaStarkCtx, &cmd.AddNewComment{Post: newPost2.Result, Content: "Comment #1"})
+	Expect(err).IsNil()
+
+	merge := &cmd.MergePost{Post: newPost2.Result, Original: newPost1.Result}
+	err = bus.Dispatch(jonSnowCtx, merge)
+	Expect(err).IsNil()
+	Expect(merge.Result.VotesCount).Equals(2)
+	Expect(merge.Result.CommentsCount).Equals(1)
+
+	comments := &query.GetCommentsByPost{Post: newPost1.Result}
+	err = bus.Dispatch(jonSnowCtx, comments)
+	Expect(err).IsNil()
+	Expect(comments.Result).HasLen(1)
+	Expect(comments.Result[0].MergedFrom).Equals(newPost2.Result.Number)
+
+	subscribers := &query.GetActiveSubscribers{Number: newPost1.Result.Number, Channel: enum.NotificationChannelWeb, Event: enum.NotificationEventNewComment}
+	err = bus.Dispatch(jonSnowCtx, subscribers)
+	Expect(err).IsNil()
+	Expect(subscribers.Result).HasLen(2)
+
+	getMerge := &query.GetActivePostMerge{PostID: newPost2.Result.ID}
+	err = bus.Dispatch(jonSnowCtx, getMerge)
+	Expect(err).IsNil()
+	Expect(getMerge.Result.OriginalID).Equals(newPost1.Result.ID)
+
+	unmerge := &cmd.UnmergePost{Merge: getMerge.Result}
+	err = bus.Dispatch(jonSnowCtx, unmerge)
+	Expect(err).IsNil()
+	Expect(unmerge.Result.Status).Equals(enum.PostOpen)
+	Expect(unmerge.Result.Response).IsNil()
+	Expect(unmerge.Result.VotesCount).Equals(1)
+	Expect(unmerge.Result.CommentsCount).Equals(1)
+
+	getPost1 := &query.GetPostByID{PostID: newPost1.Result.ID}
+	err = bus.Dispatch(jonSnowCtx, getPost1)
+	Expect(err).IsNil()
+	Expect(getPost1.Result.VotesCount).Equals(1)
+	Expect(getPost1.Result.CommentsCount).Equals(0)
+
+	err = bus.Dispatch(jonSnowCtx, &query.GetActivePostMerge{PostID: newPost2.Result.ID})
+	Expect(errors.Cause(err)).Equals(app.ErrNotFound)
+}
+
 func TestPostStorage_SetResponse_AsDeleted(t *testing.T) {
 	SetupDatabaseTest(t)
 	defer TeardownDatabaseTest()
@@ -651,3 +812,141 @@ func TestPostStorage_Attachments(t *testing.T) {
 	Expect(err).IsNil()
 	Expect(getAttachments1.Result).HasLen(0)
 }
+
+func TestPostStorage_SearchWithCursor(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	for _, title := range []string{"My first post", "My second post", "My third post"} {
+		bus.MustDispatch(jonSnowCtx, &cmd.AddNewPost{Title: title, Description: "with this description"})
+	}
+
+	page1 := &query.SearchPosts{Sort: "created", Order: "asc", Limit: "2"}
+	err := bus.Dispatch(demoTenantCtx, page1)
+	Expect(err).IsNil()
+	Expect(page1.Result).HasLen(2)
+	Expect(page1.Result[0].Title).Equals("My first post")
+	Expect(page1.Result[1].Title).Equals("My second post")
+	Expect(page1.NextCursor).NotEquals("")
+
+	page2 := &query.SearchPosts{Order: "asc", Limit: "2", Cursor: page1.NextCursor}
+	err = bus.Dispatch(demoTenantCtx, page2)
+	Expect(err).IsNil()
+	Expect(page2.Result).HasLen(1)
+	Expect(page2.Result[0].Title).Equals("My third post")
+	Expect(page2.NextCursor).Equals("")
+
+	invalid := &query.SearchPosts{Sort: "votes", Limit: "2", Cursor: page1.NextCursor}
+	err = bus.Dispatch(demoTenantCtx, invalid)
+	Expect(errors.Cause(err)).Equals(cursor.ErrInvalid)
+}
+
+func TestPostStorage_SearchByIDWithCursor(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	for _, title := range []string{"My first post", "My second post", "My third post"} {
+		bus.MustDispatch(jonSnowCtx, &cmd.AddNewPost{Title: title, Description: "with this description"})
+	}
+
+	page1 := &query.SearchPosts{Sort: "id", Limit: "2"}
+	err := bus.Dispatch(demoTenantCtx, page1)
+	Expect(err).IsNil()
+	Expect(page1.Result).HasLen(2)
+	Expect(page1.Result[0].Title).Equals("My third post")
+	Expect(page1.Result[1].Title).Equals("My second post")
+	Expect(page1.NextCursor).NotEquals("")
+
+	page2 := &query.SearchPosts{Limit: "2", Cursor: page1.NextCursor}
+	err = bus.Dispatch(demoTenantCtx, page2)
+	Expect(err).IsNil()
+	Expect(page2.Result).HasLen(1)
+	Expect(page2.Result[0].Title).Equals("My first post")
+	Expect(page2.NextCursor).Equals("")
+}
+
+func TestPostStorage_SearchLimitIsCapped(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	for i := 1; i <= 101; i++ {
+		bus.MustDispatch(jonSnowCtx, &cmd.AddNewPost{Title: fmt.Sprintf("My post number %d", i), Description: "with this description"})
+	}
+
+	search := &query.SearchPosts{Sort: "id", Limit: "500"}
+	err := bus.Dispatch(demoTenantCtx, search)
+	Expect(err).IsNil()
+	Expect(search.Result).HasLen(100)
+	Expect(search.NextCursor).NotEquals("")
+
+	all := &query.SearchPosts{View: "all", Limit: "all"}
+	err = bus.Dispatch(demoTenantCtx, all)
+	Expect(err).IsNil()
+	Expect(len(all.Result) > 100).IsTrue()
+}
+
+func TestPostStorage_SearchWithFilters(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	post1 := &cmd.AddNewPost{Title: "My first post", Description: "with this description"}
+	post2 := &cmd.AddNewPost{Title: "My second post", Description: "with this description"}
+	bus.MustDispatch(jonSnowCtx, post1)
+	bus.MustDispatch(aryaStarkCtx, post2)
+
+	addBug := &cmd.AddNewTag{Name: "Bug", Color: "FF0000", IsPublic: true}
+	addUX := &cmd.AddNewTag{Name: "UX", Color: "00FF00", IsPublic: true}
+	bus.MustDispatch(jonSnowCtx, addBug, addUX)
+	bus.MustDispatch(jonSnowCtx, &cmd.AssignTag{Tag: addBug.Result, Post: post1.Result})
+	bus.MustDispatch(jonSnowCtx, &cmd.AssignTag{Tag: addUX.Result, Post: post2.Result})
+	bus.MustDispatch(jonSnowCtx, &cmd.SetPostResponse{Post: post2.Result, Text: "Done!", Status: enum.PostCompleted})
+
+	allTags := &query.SearchPosts{View: "all", Tags: []string{"bug", "ux"}}
+	anyTag := &query.SearchPosts{View: "all", Tags: []string{"bug", "ux"}, MatchAnyTag: true}
+	byAuthor := &query.SearchPosts{View: "all", AuthorID: aryaStark.ID}
+	byStatus := &query.SearchPosts{Statuses: []enum.PostStatus{enum.PostCompleted}}
+	createdLater := &query.SearchPosts{View: "all", CreatedAfter: time.Now().Add(1 * time.Hour)}
+	updatedEarlier := &query.SearchPosts{View: "all", UpdatedBefore: time.Now().Add(1 * time.Hour)}
+	err := bus.Dispatch(jonSnowCtx, allTags, anyTag, byAuthor, byStatus, createdLater, updatedEarlier)
+	Expect(err).IsNil()
+
+	Expect(allTags.Result).HasLen(0)
+	Expect(anyTag.Result).HasLen(2)
+	Expect(byAuthor.Result).HasLen(1)
+	Expect(byAuthor.Result[0].Title).Equals("My second post")
+	Expect(byStatus.Result).HasLen(1)
+	Expect(byStatus.Result[0].Title).Equals("My second post")
+	Expect(createdLater.Result).HasLen(0)
+	Expect(updatedEarlier.Result).HasLen(2)
+}
+
+func TestPostStorage_ListCommentsWithCursor(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	newPost := &cmd.AddNewPost{Title: "My new post", Description: "with this description"}
+	bus.MustDispatch(jonSnowCtx, newPost)
+	bus.MustDispatch(jonSnowCtx, &cmd.AddNewComment{Post: newPost.Result, Content: "Comment #1"})
+	bus.MustDispatch(aryaStarkCtx, &cmd.AddNewComment{Post: newPost.Result, Content: "Comment #2"})
+	bus.MustDispatch(jonSnowCtx, &cmd.AddNewComment{Post: newPost.Result, Content: "Comment #3"})
+
+	page1 := &query.GetCommentsByPost{Post: newPost.Result, Limit: 2}
+	err := bus.Dispatch(jonSnowCtx, page1)
+	Expect(err).IsNil()
+	Expect(page1.Result).HasLen(2)
+	Expect(page1.Result[0].Content).Equals("Comment #1")
+	Expect(page1.NextCursor).NotEquals("")
+
+	page2 := &query.GetCommentsByPost{Post: newPost.Result, Limit: 2, Cursor: page1.NextCursor}
+	err = bus.Dispatch(jonSnowCtx, page2)
+	Expect(err).IsNil()
+	Expect(page2.Result).HasLen(1)
+	Expect(page2.Result[0].Content).Equals("Comment #3")
+	Expect(page2.NextCursor).Equals("")
+
+	byAuthor := &query.GetCommentsByPost{Post: newPost.Result, AuthorID: aryaStark.ID}
+	err = bus.Dispatch(jonSnowCtx, byAuthor)
+	Expect(err).IsNil()
+	Expect(byAuthor.Result).HasLen(1)
+	Expect(byAuthor.Result[0].Content).Equals("Comment #2")
+}
@@ -30,6 +30,9 @@ func (s Service) Enabled() bool {
 
 func (s Service) Init() {
 	bus.AddHandler(storeEvent)
+	bus.AddHandler(addAuditLog)
+	bus.AddHandler(listAuditLogs)
+	bus.AddHandler(getAnalytics)
 
 	bus.AddListener(purgeExpiredNotifications)
 
@@ -42,6 +45,11 @@ func (s Service) Init() {
 	bus.AddHandler(addSubscriber)
 	bus.AddHandler(removeSubscriber)
 	bus.AddHandler(getActiveSubscribers)
+	bus.AddHandler(addToDigest)
+	bus.AddHandler(markDigestItemsAsSent)
+	bus.AddHandler(markDigestItemsAsPending)
+	bus.AddHandler(getTenantsWithPendingDigest)
+	bus.AddHandler(getPendingDigestItems)
 
 	bus.AddHandler(getTagBySlug)
 	bus.AddHandler(getAssignedTags)
@@ -55,6 +63,7 @@ func (s Service) Init() {
 	bus.AddHandler(addVote)
 	bus.AddHandler(removeVote)
 	bus.AddHandler(listPostVotes)
+	bus.AddHandler(getUsedVoteCredits)
 
 	bus.AddHandler(addNewPost)
 	bus.AddHandler(updatePost)
@@ -63,8 +72,12 @@ func (s Service) Init() {
 	bus.AddHandler(getPostByNumber)
 	bus.AddHandler(searchPosts)
 	bus.AddHandler(getAllPosts)
+	bus.AddHandler(getRoadmap)
 	bus.AddHandler(countPostPerStatus)
 	bus.AddHandler(markPostAsDuplicate)
+	bus.AddHandler(mergePost)
+	bus.AddHandler(unmergePost)
+	bus.AddHandler(getActivePostMerge)
 	bus.AddHandler(setPostResponse)
 	bus.AddHandler(postIsReferenced)
 
@@ -73,6 +86,16 @@ func (s Service) Init() {
 	bus.AddHandler(uploadImage)
 	bus.AddHandler(uploadImages)
 
+	bus.AddHandler(setFileAttachments)
+	bus.AddHandler(getFileAttachments)
+	bus.AddHandler(listPostFileAttachments)
+	bus.AddHandler(getFileAttachmentByKey)
+	bus.AddHandler(getFileAttachmentsSize)
+	bus.AddHandler(uploadFiles)
+
+	bus.AddHandler(getReferencedBlobKeys)
+	bus.AddHandler(claimScheduledTask)
+
 	bus.AddHandler(addNewComment)
 	bus.AddHandler(updateComment)
 	bus.AddHandler(deleteComment)
@@ -98,14 +121,25 @@ func (s Service) Init() {
 	bus.AddHandler(getUserByProvider)
 	bus.AddHandler(getAllUsers)
 
+	bus.AddHandler(getAPITokenByKey)
+	bus.AddHandler(getAPITokenByID)
+	bus.AddHandler(listCurrentUserAPITokens)
+	bus.AddHandler(listAllAPITokens)
+	bus.AddHandler(createAPIToken)
+	bus.AddHandler(revokeAPIToken)
+	bus.AddHandler(markAPITokenAsUsed)
+
 	bus.AddHandler(createTenant)
 	bus.AddHandler(getFirstTenant)
+	bus.AddHandler(getAllTenants)
 	bus.AddHandler(getTenantByDomain)
+	bus.AddHandler(getTenantByID)
 	bus.AddHandler(activateTenant)
 	bus.AddHandler(isSubdomainAvailable)
 	bus.AddHandler(isCNAMEAvailable)
 	bus.AddHandler(updateTenantSettings)
 	bus.AddHandler(updateTenantPrivacySettings)
+	bus.AddHandler(updateTenantVotingSettings)
 	bus.AddHandler(updateTenantAdvancedSettings)
 	bus.AddHandler(updateTenantBillingSettings)
 
@@ -116,12 +150,39 @@ func (s Service) Init() {
 	bus.AddHandler(listCustomOAuthConfig)
 	bus.AddHandler(getCustomOAuthConfigByProvider)
 	bus.AddHandler(saveCustomOAuthConfig)
+
+	bus.AddHandler(getSAMLConfig)
+	bus.AddHandler(saveSAMLConfig)
+	bus.AddHandler(useSAMLAssertion)
+
+	bus.AddHandler(getCustomEmailTemplate)
+	bus.AddHandler(listCustomEmailTemplates)
+	bus.AddHandler(saveEmailTemplate)
+	bus.AddHandler(resetEmailTemplate)
+
+	bus.AddHandler(logEmailDelivery)
+	bus.AddHandler(setEmailDeliveryStatus)
+	bus.AddHandler(isEmailSuppressed)
+	bus.AddHandler(listEmailSuppressions)
+	bus.AddHandler(deleteEmailSuppression)
+
+	bus.AddHandler(listWebhooks)
+	bus.AddHandler(listActiveWebhooksByEvent)
+	bus.AddHandler(getWebhookByID)
+	bus.AddHandler(createWebhook)
+	bus.AddHandler(updateWebhook)
+	bus.AddHandler(deleteWebhook)
+	bus.AddHandler(logWebhookDelivery)
+	bus.AddHandler(listWebhookDeliveries)
+
+	bus.AddHandler(importRecords)
 }
 
 type SqlHandler func(trx *dbx.Trx, tenant *models.Tenant, user *models.User) error
 
 func using(ctx context.Context, handler SqlHandler) error {
-	trx := ctx.Value(app.TransactionCtxKey).(*dbx.Trx)
+	//Commands run with the context of the handler, so that their spans are children of the message being handled
+	trx := ctx.Value(app.TransactionCtxKey).(*dbx.Trx).WithContext(ctx)
 	tenant, _ := ctx.Value(app.TenantCtxKey).(*models.Tenant)
 	user, _ := ctx.Value(app.UserCtxKey).(*models.User)
 	return handler(trx, tenant, user)
@@ -0,0 +1,87 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/models/query"
+	"github.com/getfider/fider/app/pkg/dbx"
+	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/lib/pq"
+)
+
+type dbPostStatusChange struct {
+	PostID    int       `db:"post_id"`
+	Status    int       `db:"status"`
+	ChangedAt time.Time `db:"changed_at"`
+}
+
+//Completed posts keep piling up, so only the most recently updated ones are shown
+var roadmapColumns = []struct {
+	status enum.PostStatus
+	sort   string
+	limit  string
+}{
+	{enum.PostPlanned, "votes", "all"},
+	{enum.PostStarted, "votes", "all"},
+	{enum.PostCompleted, "updated", "50"},
+}
+
+func getRoadmap(ctx context.Context, q *query.GetRoadmap) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *models.Tenant, user *models.User) error {
+		q.Result = make([]*models.RoadmapColumn, len(roadmapColumns))
+
+		postIDs := make([]int, 0)
+		roadmapPosts := make(map[int]*models.RoadmapPost)
+		for i, column := range roadmapColumns {
+			searchQuery := &query.SearchPosts{
+				Statuses:    []enum.PostStatus{column.status},
+				Tags:        q.Tags,
+				MatchAnyTag: q.MatchAnyTag,
+				Sort:        column.sort,
+				Limit:       column.limit,
+			}
+			if err := searchPosts(ctx, searchQuery); err != nil {
+				return errors.Wrap(err, "failed to get roadmap posts with status '%s'", column.status.Name())
+			}
+
+			q.Result[i] = &models.RoadmapColumn{
+				Status: column.status,
+				Posts:  make([]*models.RoadmapPost, len(searchQuery.Result)),
+			}
+			for j, post := range searchQuery.Result {
+				roadmapPost := &models.RoadmapPost{Post: post, History: make([]*models.PostStatusChange, 0)}
+				roadmapPosts[post.ID] = roadmapPost
+				postIDs = append(postIDs, post.ID)
+				q.Result[i].Posts[j] = roadmapPost
+			}
+		}
+
+		if len(postIDs) == 0 {
+			return nil
+		}
+
+		changes := []*dbPostStatusChange{}
+		err := trx.Select(&changes, `
+			SELECT post_id, status, changed_at 
+			FROM post_status_history 
+			WHERE tenant_id = $1 AND post_id = ANY($2)
+			ORDER BY changed_at, id
+		`, tenant.ID, pq.Array(postIDs))
+		if err != nil {
+			return errors.Wrap(err, "failed to get status history of roadmap posts")
+		}
+
+		for _, change := range changes {
+			if roadmapPost, ok := roadmapPosts[change.PostID]; ok {
+				roadmapPost.History = append(roadmapPost.History, &models.PostStatusChange{
+					Status:    enum.PostStatus(change.Status),
+					ChangedAt: change.ChangedAt,
+				})
+			}
+		}
+		return nil
+	})
+}
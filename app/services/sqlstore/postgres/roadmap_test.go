@@ -0,0 +1,68 @@
+package postgres_test
+
+import (
+	"testing"
+
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/models/query"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/bus"
+)
+
+func TestRoadmapStorage_GetRoadmap(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	post1 := &cmd.AddNewPost{Title: "My first post", Description: "with this description"}
+	post2 := &cmd.AddNewPost{Title: "My second post", Description: "with this description"}
+	post3 := &cmd.AddNewPost{Title: "My third post", Description: "with this description"}
+	bus.MustDispatch(jonSnowCtx, post1, post2, post3)
+
+	bus.MustDispatch(jonSnowCtx, &cmd.SetPostResponse{Post: post1.Result, Text: "Soon!", Status: enum.PostPlanned})
+	bus.MustDispatch(jonSnowCtx, &cmd.SetPostResponse{Post: post2.Result, Text: "On it!", Status: enum.PostStarted})
+	bus.MustDispatch(jonSnowCtx, &cmd.SetPostResponse{Post: post2.Result, Text: "Done!", Status: enum.PostCompleted})
+
+	getRoadmap := &query.GetRoadmap{}
+	err := bus.Dispatch(jonSnowCtx, getRoadmap)
+	Expect(err).IsNil()
+	Expect(getRoadmap.Result).HasLen(3)
+
+	planned, started, completed := getRoadmap.Result[0], getRoadmap.Result[1], getRoadmap.Result[2]
+	Expect(planned.Status).Equals(enum.PostPlanned)
+	Expect(planned.Posts).HasLen(1)
+	Expect(planned.Posts[0].Post.Title).Equals("My first post")
+	Expect(planned.Posts[0].History).HasLen(2)
+	Expect(planned.Posts[0].History[0].Status).Equals(enum.PostOpen)
+	Expect(planned.Posts[0].History[1].Status).Equals(enum.PostPlanned)
+
+	Expect(started.Status).Equals(enum.PostStarted)
+	Expect(started.Posts).HasLen(0)
+
+	Expect(completed.Status).Equals(enum.PostCompleted)
+	Expect(completed.Posts).HasLen(1)
+	Expect(completed.Posts[0].History).HasLen(3)
+	Expect(completed.Posts[0].History[1].Status).Equals(enum.PostStarted)
+	Expect(completed.Posts[0].History[2].Status).Equals(enum.PostCompleted)
+}
+
+func TestRoadmapStorage_GetRoadmap_WithTags(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	post1 := &cmd.AddNewPost{Title: "My first post", Description: "with this description"}
+	post2 := &cmd.AddNewPost{Title: "My second post", Description: "with this description"}
+	bus.MustDispatch(jonSnowCtx, post1, post2)
+	bus.MustDispatch(jonSnowCtx, &cmd.SetPostResponse{Post: post1.Result, Text: "Soon!", Status: enum.PostPlanned})
+	bus.MustDispatch(jonSnowCtx, &cmd.SetPostResponse{Post: post2.Result, Text: "Soon!", Status: enum.PostPlanned})
+
+	addBug := &cmd.AddNewTag{Name: "Bug", Color: "FF0000", IsPublic: true}
+	bus.MustDispatch(jonSnowCtx, addBug)
+	bus.MustDispatch(jonSnowCtx, &cmd.AssignTag{Tag: addBug.Result, Post: post2.Result})
+
+	getRoadmap := &query.GetRoadmap{Tags: []string{"bug"}}
+	err := bus.Dispatch(jonSnowCtx, getRoadmap)
+	Expect(err).IsNil()
+	Expect(getRoadmap.Result[0].Posts).HasLen(1)
+	Expect(getRoadmap.Result[0].Posts[0].Post.Title).Equals("My second post")
+}
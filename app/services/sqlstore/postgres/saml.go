@@ -0,0 +1,105 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/query"
+	"github.com/getfider/fider/app/pkg/dbx"
+	"github.com/getfider/fider/app/pkg/errors"
+)
+
+type dbSAMLConfig struct {
+	IsEnabled          bool   `db:"is_enabled"`
+	DisplayName        string `db:"display_name"`
+	IdPMetadata        string `db:"idp_metadata"`
+	IdPEntityID        string `db:"idp_entity_id"`
+	IdPSSOURL          string `db:"idp_sso_url"`
+	NameAttribute      string `db:"name_attribute"`
+	EmailAttribute     string `db:"email_attribute"`
+	RoleAttribute      string `db:"role_attribute"`
+	AdministratorRoles string `db:"administrator_roles"`
+	CollaboratorRoles  string `db:"collaborator_roles"`
+}
+
+func (m *dbSAMLConfig) toModel() *models.SAMLConfig {
+	return &models.SAMLConfig{
+		IsEnabled:          m.IsEnabled,
+		DisplayName:        m.DisplayName,
+		IdPMetadata:        m.IdPMetadata,
+		IdPEntityID:        m.IdPEntityID,
+		IdPSSOURL:          m.IdPSSOURL,
+		NameAttribute:      m.NameAttribute,
+		EmailAttribute:     m.EmailAttribute,
+		RoleAttribute:      m.RoleAttribute,
+		AdministratorRoles: m.AdministratorRoles,
+		CollaboratorRoles:  m.CollaboratorRoles,
+	}
+}
+
+func getSAMLConfig(ctx context.Context, q *query.GetSAMLConfig) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *models.Tenant, user *models.User) error {
+		if tenant == nil {
+			return app.ErrNotFound
+		}
+
+		config := &dbSAMLConfig{}
+		err := trx.Get(config, `
+			SELECT is_enabled, display_name, idp_metadata, idp_entity_id, idp_sso_url,
+						 name_attribute, email_attribute, role_attribute,
+						 administrator_roles, collaborator_roles
+			FROM saml_configs
+			WHERE tenant_id = $1
+		`, tenant.ID)
+		if err != nil {
+			return err
+		}
+
+		q.Result = config.toModel()
+		return nil
+	})
+}
+
+func saveSAMLConfig(ctx context.Context, c *cmd.SaveSAMLConfig) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *models.Tenant, user *models.User) error {
+		_, err := trx.Execute(`
+			INSERT INTO saml_configs (
+				tenant_id, is_enabled, display_name, idp_metadata, idp_entity_id, idp_sso_url,
+				name_attribute, email_attribute, role_attribute, administrator_roles, collaborator_roles, updated_at
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+			ON CONFLICT (tenant_id) DO UPDATE
+			SET is_enabled = $2, display_name = $3, idp_metadata = $4, idp_entity_id = $5, idp_sso_url = $6,
+					name_attribute = $7, email_attribute = $8, role_attribute = $9,
+					administrator_roles = $10, collaborator_roles = $11, updated_at = $12
+		`, tenant.ID, c.Config.IsEnabled, c.Config.DisplayName, c.Config.IdPMetadata,
+			c.Config.IdPEntityID, c.Config.IdPSSOURL, c.Config.NameAttribute, c.Config.EmailAttribute,
+			c.Config.RoleAttribute, c.Config.AdministratorRoles, c.Config.CollaboratorRoles, time.Now())
+		if err != nil {
+			return errors.Wrap(err, "failed to save SAML configuration")
+		}
+		return nil
+	})
+}
+
+func useSAMLAssertion(ctx context.Context, c *cmd.UseSAMLAssertion) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *models.Tenant, user *models.User) error {
+		_, err := trx.Execute("DELETE FROM saml_assertions WHERE tenant_id = $1 AND expires_at < $2", tenant.ID, time.Now())
+		if err != nil {
+			return errors.Wrap(err, "failed to delete expired SAML assertions")
+		}
+
+		rows, err := trx.Execute(`
+			INSERT INTO saml_assertions (tenant_id, assertion_id, expires_at) VALUES ($1, $2, $3)
+			ON CONFLICT DO NOTHING
+		`, tenant.ID, c.AssertionID, c.ExpiresAt)
+		if err != nil {
+			return errors.Wrap(err, "failed to store SAML assertion")
+		}
+
+		c.AlreadyUsed = rows == 0
+		return nil
+	})
+}
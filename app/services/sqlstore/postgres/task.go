@@ -0,0 +1,34 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/pkg/dbx"
+	"github.com/getfider/fider/app/pkg/errors"
+)
+
+//claimScheduledTask records a run of given task, unless it has already run within the interval.
+//Concurrent claims wait for each other, so that only one of the instances that scheduled the task runs it
+func claimScheduledTask(ctx context.Context, c *cmd.ClaimScheduledTask) error {
+	return using(ctx, func(trx *dbx.Trx, _ *models.Tenant, _ *models.User) error {
+		now := time.Now()
+		var tasks []*struct {
+			Name string `db:"name"`
+		}
+		err := trx.Select(&tasks, `
+			INSERT INTO scheduled_tasks (name, last_run_at) VALUES ($1, $2)
+			ON CONFLICT (name) DO UPDATE SET last_run_at = $2
+			WHERE scheduled_tasks.last_run_at <= $3
+			RETURNING name
+		`, c.Name, now, now.Add(-c.Interval))
+		if err != nil {
+			return errors.Wrap(err, "failed to claim scheduled task '%s'", c.Name)
+		}
+
+		c.Result = len(tasks) > 0
+		return nil
+	})
+}
@@ -0,0 +1,35 @@
+package postgres_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/getfider/fider/app/models/cmd"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/bus"
+)
+
+func TestClaimScheduledTask(t *testing.T) {
+	ctx := SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	claim := &cmd.ClaimScheduledTask{Name: "collect_blob_garbage", Interval: time.Hour}
+	err := bus.Dispatch(ctx, claim)
+	Expect(err).IsNil()
+	Expect(claim.Result).IsTrue()
+
+	claim = &cmd.ClaimScheduledTask{Name: "collect_blob_garbage", Interval: time.Hour}
+	err = bus.Dispatch(ctx, claim)
+	Expect(err).IsNil()
+	Expect(claim.Result).IsFalse()
+
+	claim = &cmd.ClaimScheduledTask{Name: "send_digests", Interval: time.Hour}
+	err = bus.Dispatch(ctx, claim)
+	Expect(err).IsNil()
+	Expect(claim.Result).IsTrue()
+
+	claim = &cmd.ClaimScheduledTask{Name: "collect_blob_garbage", Interval: 0}
+	err = bus.Dispatch(ctx, claim)
+	Expect(err).IsNil()
+	Expect(claim.Result).IsTrue()
+}
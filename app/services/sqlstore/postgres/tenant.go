@@ -17,17 +17,22 @@ import (
 )
 
 type dbTenant struct {
-	ID             int              `db:"id"`
-	Name           string           `db:"name"`
-	Subdomain      string           `db:"subdomain"`
-	CNAME          string           `db:"cname"`
-	Invitation     string           `db:"invitation"`
-	WelcomeMessage string           `db:"welcome_message"`
-	Status         int              `db:"status"`
-	IsPrivate      bool             `db:"is_private"`
-	LogoBlobKey    string           `db:"logo_bkey"`
-	CustomCSS      string           `db:"custom_css"`
-	Billing        *dbTenantBilling `db:"billing"`
+	ID               int              `db:"id"`
+	Name             string           `db:"name"`
+	Subdomain        string           `db:"subdomain"`
+	CNAME            string           `db:"cname"`
+	Invitation       string           `db:"invitation"`
+	WelcomeMessage   string           `db:"welcome_message"`
+	Status           int              `db:"status"`
+	IsPrivate        bool             `db:"is_private"`
+	IsRoadmapPublic  bool             `db:"is_roadmap_public"`
+	VotingMode       int              `db:"voting_mode"`
+	VoteBudget       int              `db:"vote_budget"`
+	LogoBlobKey      string           `db:"logo_bkey"`
+	CustomCSS        string           `db:"custom_css"`
+	Locale           string           `db:"locale"`
+	AllowedFileTypes string           `db:"allowed_file_types"`
+	Billing          *dbTenantBilling `db:"billing"`
 }
 
 func (t *dbTenant) toModel() *models.Tenant {
@@ -36,16 +41,21 @@ func (t *dbTenant) toModel() *models.Tenant {
 	}
 
 	tenant := &models.Tenant{
-		ID:             t.ID,
-		Name:           t.Name,
-		Subdomain:      t.Subdomain,
-		CNAME:          t.CNAME,
-		Invitation:     t.Invitation,
-		WelcomeMessage: t.WelcomeMessage,
-		Status:         t.Status,
-		IsPrivate:      t.IsPrivate,
-		LogoBlobKey:    t.LogoBlobKey,
-		CustomCSS:      t.CustomCSS,
+		ID:               t.ID,
+		Name:             t.Name,
+		Subdomain:        t.Subdomain,
+		CNAME:            t.CNAME,
+		Invitation:       t.Invitation,
+		WelcomeMessage:   t.WelcomeMessage,
+		Status:           t.Status,
+		IsPrivate:        t.IsPrivate,
+		IsRoadmapPublic:  t.IsRoadmapPublic,
+		VotingMode:       enum.VotingMode(t.VotingMode),
+		VoteBudget:       t.VoteBudget,
+		LogoBlobKey:      t.LogoBlobKey,
+		CustomCSS:        t.CustomCSS,
+		Locale:           t.Locale,
+		AllowedFileTypes: t.AllowedFileTypes,
 	}
 
 	if t.Billing != nil && t.Billing.TrialEndsAt.Valid {
@@ -131,10 +141,26 @@ func isSubdomainAvailable(ctx context.Context, q *query.IsSubdomainAvailable) er
 
 func updateTenantPrivacySettings(ctx context.Context, c *cmd.UpdateTenantPrivacySettings) error {
 	return using(ctx, func(trx *dbx.Trx, tenant *models.Tenant, user *models.User) error {
-		_, err := trx.Execute("UPDATE tenants SET is_private = $1 WHERE id = $2", c.Settings.IsPrivate, tenant.ID)
+		_, err := trx.Execute("UPDATE tenants SET is_private = $1, is_roadmap_public = $2 WHERE id = $3", c.Settings.IsPrivate, c.Settings.IsRoadmapPublic, tenant.ID)
 		if err != nil {
 			return errors.Wrap(err, "failed update tenant privacy settings")
 		}
+
+		tenant.IsPrivate = c.Settings.IsPrivate
+		tenant.IsRoadmapPublic = c.Settings.IsRoadmapPublic
+		return nil
+	})
+}
+
+func updateTenantVotingSettings(ctx context.Context, c *cmd.UpdateTenantVotingSettings) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *models.Tenant, user *models.User) error {
+		_, err := trx.Execute("UPDATE tenants SET voting_mode = $1, vote_budget = $2 WHERE id = $3", c.Settings.Mode, c.Settings.Budget, tenant.ID)
+		if err != nil {
+			return errors.Wrap(err, "failed update tenant voting settings")
+		}
+
+		tenant.VotingMode = c.Settings.Mode
+		tenant.VoteBudget = c.Settings.Budget
 		return nil
 	})
 }
@@ -145,8 +171,8 @@ func updateTenantSettings(ctx context.Context, c *cmd.UpdateTenantSettings) erro
 			c.Settings.Logo.BlobKey = ""
 		}
 
-		query := "UPDATE tenants SET name = $1, invitation = $2, welcome_message = $3, cname = $4, logo_bkey = $5 WHERE id = $6"
-		_, err := trx.Execute(query, c.Settings.Title, c.Settings.Invitation, c.Settings.WelcomeMessage, c.Settings.CNAME, c.Settings.Logo.BlobKey, tenant.ID)
+		query := "UPDATE tenants SET name = $1, invitation = $2, welcome_message = $3, cname = $4, logo_bkey = $5, locale = $6 WHERE id = $7"
+		_, err := trx.Execute(query, c.Settings.Title, c.Settings.Invitation, c.Settings.WelcomeMessage, c.Settings.CNAME, c.Settings.Logo.BlobKey, c.Settings.Locale, tenant.ID)
 		if err != nil {
 			return errors.Wrap(err, "failed update tenant settings")
 		}
@@ -155,6 +181,7 @@ func updateTenantSettings(ctx context.Context, c *cmd.UpdateTenantSettings) erro
 		tenant.Invitation = c.Settings.Invitation
 		tenant.CNAME = c.Settings.CNAME
 		tenant.WelcomeMessage = c.Settings.WelcomeMessage
+		tenant.Locale = c.Settings.Locale
 
 		return nil
 	})
@@ -185,13 +212,14 @@ func updateTenantBillingSettings(ctx context.Context, c *cmd.UpdateTenantBilling
 
 func updateTenantAdvancedSettings(ctx context.Context, c *cmd.UpdateTenantAdvancedSettings) error {
 	return using(ctx, func(trx *dbx.Trx, tenant *models.Tenant, user *models.User) error {
-		query := "UPDATE tenants SET custom_css = $1 WHERE id = $2"
-		_, err := trx.Execute(query, c.Settings.CustomCSS, tenant.ID)
+		query := "UPDATE tenants SET custom_css = $1, allowed_file_types = $2 WHERE id = $3"
+		_, err := trx.Execute(query, c.Settings.CustomCSS, c.Settings.AllowedFileTypes, tenant.ID)
 		if err != nil {
 			return errors.Wrap(err, "failed update tenant advanced settings")
 		}
 
 		tenant.CustomCSS = c.Settings.CustomCSS
+		tenant.AllowedFileTypes = c.Settings.AllowedFileTypes
 		return nil
 	})
 }
@@ -279,12 +307,32 @@ func createTenant(ctx context.Context, c *cmd.CreateTenant) error {
 	})
 }
 
+func getAllTenants(ctx context.Context, q *query.GetAllTenants) error {
+	return using(ctx, func(trx *dbx.Trx, _ *models.Tenant, _ *models.User) error {
+		var tenants []*dbTenant
+		err := trx.Select(&tenants, `
+			SELECT t.id, t.name, t.subdomain, t.cname, t.invitation, t.welcome_message, t.status, t.is_private, t.is_roadmap_public, t.voting_mode, t.vote_budget, t.logo_bkey, t.custom_css, t.locale, t.allowed_file_types
+			FROM tenants t
+			ORDER BY t.id
+		`)
+		if err != nil {
+			return errors.Wrap(err, "failed to get all tenants")
+		}
+
+		q.Result = make([]*models.Tenant, len(tenants))
+		for i, t := range tenants {
+			q.Result[i] = t.toModel()
+		}
+		return nil
+	})
+}
+
 func getFirstTenant(ctx context.Context, q *query.GetFirstTenant) error {
 	return using(ctx, func(trx *dbx.Trx, _ *models.Tenant, _ *models.User) error {
 		tenant := dbTenant{}
 
 		err := trx.Get(&tenant, `
-			SELECT t.id, t.name, t.subdomain, t.cname, t.invitation, t.welcome_message, t.status, t.is_private, t.logo_bkey, t.custom_css,
+			SELECT t.id, t.name, t.subdomain, t.cname, t.invitation, t.welcome_message, t.status, t.is_private, t.is_roadmap_public, t.voting_mode, t.vote_budget, t.logo_bkey, t.custom_css, t.locale, t.allowed_file_types,
 						 tb.trial_ends_at AS billing_trial_ends_at,
 						 tb.subscription_ends_at AS billing_subscription_ends_at,
 						 tb.stripe_customer_id AS billing_stripe_customer_id,
@@ -310,7 +358,7 @@ func getTenantByDomain(ctx context.Context, q *query.GetTenantByDomain) error {
 		tenant := dbTenant{}
 
 		err := trx.Get(&tenant, `
-			SELECT t.id, t.name, t.subdomain, t.cname, t.invitation, t.welcome_message, t.status, t.is_private, t.logo_bkey, t.custom_css,
+			SELECT t.id, t.name, t.subdomain, t.cname, t.invitation, t.welcome_message, t.status, t.is_private, t.is_roadmap_public, t.voting_mode, t.vote_budget, t.logo_bkey, t.custom_css, t.locale, t.allowed_file_types,
 						 tb.trial_ends_at AS billing_trial_ends_at,
 						 tb.subscription_ends_at AS billing_subscription_ends_at,
 						 tb.stripe_customer_id AS billing_stripe_customer_id,
@@ -330,3 +378,28 @@ func getTenantByDomain(ctx context.Context, q *query.GetTenantByDomain) error {
 		return nil
 	})
 }
+
+func getTenantByID(ctx context.Context, q *query.GetTenantByID) error {
+	return using(ctx, func(trx *dbx.Trx, _ *models.Tenant, _ *models.User) error {
+		tenant := dbTenant{}
+
+		err := trx.Get(&tenant, `
+			SELECT t.id, t.name, t.subdomain, t.cname, t.invitation, t.welcome_message, t.status, t.is_private, t.is_roadmap_public, t.voting_mode, t.vote_budget, t.logo_bkey, t.custom_css, t.locale, t.allowed_file_types,
+						 tb.trial_ends_at AS billing_trial_ends_at,
+						 tb.subscription_ends_at AS billing_subscription_ends_at,
+						 tb.stripe_customer_id AS billing_stripe_customer_id,
+						 tb.stripe_plan_id AS billing_stripe_plan_id,
+						 tb.stripe_subscription_id AS billing_stripe_subscription_id
+			FROM tenants t
+			LEFT JOIN tenants_billing tb
+			ON tb.tenant_id = t.id
+			WHERE t.id = $1
+		`, q.TenantID)
+		if err != nil {
+			return errors.Wrap(err, "failed to get tenant with id '%d'", q.TenantID)
+		}
+
+		q.Result = tenant.toModel()
+		return nil
+	})
+}
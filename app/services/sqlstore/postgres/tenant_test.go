@@ -39,6 +39,7 @@ func TestTenantStorage_Add_Activate(t *testing.T) {
 	Expect(getByDomain.Result.Subdomain).Equals("mydomain")
 	Expect(getByDomain.Result.Status).Equals(enum.TenantPending)
 	Expect(getByDomain.Result.IsPrivate).IsFalse()
+	Expect(getByDomain.Result.IsRoadmapPublic).IsTrue()
 
 	err = bus.Dispatch(ctx, &cmd.ActivateTenant{TenantID: createTenant.Result.ID})
 	Expect(err).IsNil()
@@ -121,7 +122,7 @@ func TestTenantStorage_UpdatePrivacy(t *testing.T) {
 	defer TeardownDatabaseTest()
 
 	setPrivate := &cmd.UpdateTenantPrivacySettings{
-		Settings: &models.UpdateTenantPrivacy{IsPrivate: true},
+		Settings: &models.UpdateTenantPrivacy{IsPrivate: true, IsRoadmapPublic: false},
 	}
 	getByDomain := &query.GetTenantByDomain{
 		Domain: "demo",
@@ -129,6 +130,27 @@ func TestTenantStorage_UpdatePrivacy(t *testing.T) {
 	err := bus.Dispatch(demoTenantCtx, setPrivate, getByDomain)
 	Expect(err).IsNil()
 	Expect(getByDomain.Result.IsPrivate).IsTrue()
+	Expect(getByDomain.Result.IsRoadmapPublic).IsFalse()
+}
+
+func TestTenantStorage_UpdateVoting(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	getByDomain := &query.GetTenantByDomain{Domain: "demo"}
+	err := bus.Dispatch(demoTenantCtx, getByDomain)
+	Expect(err).IsNil()
+	Expect(getByDomain.Result.VotingMode).Equals(enum.VotingModeSimple)
+	Expect(getByDomain.Result.VoteBudget).Equals(10)
+
+	setVoting := &cmd.UpdateTenantVotingSettings{
+		Settings: &models.UpdateTenantVoting{Mode: enum.VotingModeBudgeted, Budget: 15},
+	}
+	getByDomain = &query.GetTenantByDomain{Domain: "demo"}
+	err = bus.Dispatch(demoTenantCtx, setVoting, getByDomain)
+	Expect(err).IsNil()
+	Expect(getByDomain.Result.VotingMode).Equals(enum.VotingModeBudgeted)
+	Expect(getByDomain.Result.VoteBudget).Equals(15)
 }
 
 func TestTenantStorage_GetByDomain_NotFound(t *testing.T) {
@@ -198,6 +220,7 @@ func TestTenantStorage_UpdateSettings(t *testing.T) {
 		Invitation:     "Leave us your suggestion",
 		WelcomeMessage: "Welcome!",
 		CNAME:          "demo.company.com",
+		Locale:         "de",
 	}
 	err := bus.Dispatch(demoTenantCtx, &cmd.UpdateTenantSettings{Settings: settings})
 	Expect(err).IsNil()
@@ -211,6 +234,7 @@ func TestTenantStorage_UpdateSettings(t *testing.T) {
 	Expect(getByDomain.Result.WelcomeMessage).Equals("Welcome!")
 	Expect(getByDomain.Result.CNAME).Equals("demo.company.com")
 	Expect(getByDomain.Result.LogoBlobKey).Equals("some-logo-key.png")
+	Expect(getByDomain.Result.Locale).Equals("de")
 }
 
 func TestTenantStorage_AdvancedSettings(t *testing.T) {
@@ -25,6 +25,7 @@ type dbUser struct {
 	Status        sql.NullInt64  `db:"status"`
 	AvatarType    sql.NullInt64  `db:"avatar_type"`
 	AvatarBlobKey sql.NullString `db:"avatar_bkey"`
+	Locale        sql.NullString `db:"locale"`
 	Providers     []*dbUserProvider
 }
 
@@ -50,6 +51,7 @@ func (u *dbUser) toModel(ctx context.Context) *models.User {
 		AvatarType:    avatarType,
 		AvatarBlobKey: u.AvatarBlobKey.String,
 		AvatarURL:     buildAvatarURL(ctx, avatarType, int(u.ID.Int64), u.Name.String, u.AvatarBlobKey.String),
+		Locale:        u.Locale.String,
 	}
 
 	for i, p := range u.Providers {
@@ -123,6 +125,8 @@ func deleteCurrentUser(ctx context.Context, c *cmd.DeleteCurrentUser) error {
 			{"post_votes", "user_id"},
 			{"post_subscribers", "user_id"},
 			{"email_verifications", "user_id"},
+			{"api_tokens", "user_id"},
+			{"digest_items", "user_id"},
 		}
 
 		for _, table := range tables {
@@ -307,8 +311,8 @@ func updateCurrentUser(ctx context.Context, c *cmd.UpdateCurrentUser) error {
 		if c.Avatar.Remove {
 			c.Avatar.BlobKey = ""
 		}
-		cmd := "UPDATE users SET name = $3, avatar_type = $4, avatar_bkey = $5 WHERE id = $1 AND tenant_id = $2"
-		_, err := trx.Execute(cmd, user.ID, tenant.ID, c.Name, c.AvatarType, c.Avatar.BlobKey)
+		cmd := "UPDATE users SET name = $3, avatar_type = $4, avatar_bkey = $5, locale = $6 WHERE id = $1 AND tenant_id = $2"
+		_, err := trx.Execute(cmd, user.ID, tenant.ID, c.Name, c.AvatarType, c.Avatar.BlobKey, c.Locale)
 		if err != nil {
 			return errors.Wrap(err, "failed to update user")
 		}
@@ -365,7 +369,7 @@ func getAllUsers(ctx context.Context, q *query.GetAllUsers) error {
 	return using(ctx, func(trx *dbx.Trx, tenant *models.Tenant, user *models.User) error {
 		var users []*dbUser
 		err := trx.Select(&users, `
-			SELECT id, name, email, tenant_id, role, status, avatar_type, avatar_bkey
+			SELECT id, name, email, tenant_id, role, status, avatar_type, avatar_bkey, locale
 			FROM users 
 			WHERE tenant_id = $1 
 			AND status != $2
@@ -384,7 +388,7 @@ func getAllUsers(ctx context.Context, q *query.GetAllUsers) error {
 
 func queryUser(ctx context.Context, trx *dbx.Trx, filter string, args ...interface{}) (*models.User, error) {
 	user := dbUser{}
-	sql := fmt.Sprintf("SELECT id, name, email, tenant_id, role, status, avatar_type, avatar_bkey FROM users WHERE status != %d AND ", enum.UserDeleted)
+	sql := fmt.Sprintf("SELECT id, name, email, tenant_id, role, status, avatar_type, avatar_bkey, locale FROM users WHERE status != %d AND ", enum.UserDeleted)
 	err := trx.Get(&user, sql+filter, args...)
 	if err != nil {
 		return nil, err
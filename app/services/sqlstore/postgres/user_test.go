@@ -227,6 +227,7 @@ func TestUserStorage_UpdateSettings(t *testing.T) {
 		Avatar: &models.ImageUpload{
 			BlobKey: "jon.png",
 		},
+		Locale: "fr",
 	})
 	Expect(err).IsNil()
 
@@ -235,6 +236,7 @@ func TestUserStorage_UpdateSettings(t *testing.T) {
 	Expect(err).IsNil()
 	Expect(getUser.Result.Name).Equals("Jon Stark")
 	Expect(getUser.Result.AvatarBlobKey).Equals("jon.png")
+	Expect(getUser.Result.Locale).Equals("fr")
 }
 
 func TestUserStorage_ChangeRole(t *testing.T) {
@@ -5,12 +5,14 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/getfider/fider/app"
 	"github.com/getfider/fider/app/models"
 	"github.com/getfider/fider/app/models/cmd"
 	"github.com/getfider/fider/app/models/enum"
 	"github.com/getfider/fider/app/models/query"
 	"github.com/getfider/fider/app/pkg/dbx"
 	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/lib/pq"
 )
 
 type dbVote struct {
@@ -21,11 +23,13 @@ type dbVote struct {
 		AvatarType    int64  `db:"avatar_type"`
 		AvatarBlobKey string `db:"avatar_bkey"`
 	} `db:"user"`
+	Credits   int       `db:"credits"`
 	CreatedAt time.Time `db:"created_at"`
 }
 
 func (v *dbVote) toModel(ctx context.Context) *models.Vote {
 	vote := &models.Vote{
+		Credits:   v.Credits,
 		CreatedAt: v.CreatedAt,
 		User: &models.VoteUser{
 			ID:        v.User.ID,
@@ -43,9 +47,33 @@ func addVote(ctx context.Context, c *cmd.AddVote) error {
 			return nil
 		}
 
+		credits, conflict := c.Credits, "DO NOTHING"
+		if credits > 0 {
+			conflict = "(user_id, post_id) DO UPDATE SET credits = $5"
+		} else {
+			credits = 1
+		}
+
+		if c.Credits > 0 && tenant.VotingMode == enum.VotingModeBudgeted {
+			// Votes of the same user wait for each other, so concurrent requests can't spend the same credits twice
+			_, err := trx.Execute("SELECT id FROM users WHERE id = $1 AND tenant_id = $2 FOR UPDATE", c.User.ID, tenant.ID)
+			if err != nil {
+				return errors.Wrap(err, "failed to lock user votes")
+			}
+
+			used, err := usedVoteCredits(trx, tenant, c.User.ID, c.Post.ID)
+			if err != nil {
+				return err
+			}
+
+			if used+c.Credits > tenant.VoteBudget {
+				return app.ErrVoteBudgetExceeded
+			}
+		}
+
 		_, err := trx.Execute(
-			`INSERT INTO post_votes (tenant_id, user_id, post_id, created_at) VALUES ($1, $2, $3, $4) ON CONFLICT DO NOTHING`,
-			tenant.ID, c.User.ID, c.Post.ID, time.Now(),
+			`INSERT INTO post_votes (tenant_id, user_id, post_id, created_at, credits) VALUES ($1, $2, $3, $4, $5) ON CONFLICT `+conflict,
+			tenant.ID, c.User.ID, c.Post.ID, time.Now(), credits,
 		)
 
 		if err != nil {
@@ -83,6 +111,7 @@ func listPostVotes(ctx context.Context, q *query.ListPostVotes) error {
 		err := trx.Select(&votes, `
 		SELECT 
 			pv.created_at, 
+			pv.credits,
 			u.id AS user_id,
 			u.name AS user_name,
 			u.email AS user_email,
@@ -108,3 +137,42 @@ func listPostVotes(ctx context.Context, q *query.ListPostVotes) error {
 		return nil
 	})
 }
+
+func getUsedVoteCredits(ctx context.Context, q *query.GetUsedVoteCredits) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *models.Tenant, user *models.User) error {
+		q.Result = 0
+		if user == nil {
+			return nil
+		}
+
+		used, err := usedVoteCredits(trx, tenant, user.ID, q.ExcludePostID)
+		q.Result = used
+		return err
+	})
+}
+
+func usedVoteCredits(trx *dbx.Trx, tenant *models.Tenant, userID, excludePostID int) (int, error) {
+	var used int
+
+	// Credits placed on closed posts (e.g. completed or declined) are refunded to the user
+	err := trx.Scalar(&used, `
+	SELECT COALESCE(SUM(pv.credits), 0)
+	FROM post_votes pv
+	INNER JOIN posts p
+	ON p.id = pv.post_id
+	AND p.tenant_id = pv.tenant_id
+	WHERE pv.user_id = $1
+	AND pv.tenant_id = $2
+	AND pv.post_id <> $3
+	AND p.status = ANY($4)
+	`, userID, tenant.ID, excludePostID, pq.Array([]enum.PostStatus{
+		enum.PostOpen,
+		enum.PostStarted,
+		enum.PostPlanned,
+	}))
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to get used vote credits")
+	}
+
+	return used, nil
+}
@@ -0,0 +1,240 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/models/query"
+	"github.com/getfider/fider/app/pkg/dbx"
+	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/lib/pq"
+)
+
+type dbWebhook struct {
+	ID        int       `db:"id"`
+	Name      string    `db:"name"`
+	URL       string    `db:"url"`
+	Secret    string    `db:"secret"`
+	Events    []string  `db:"events"`
+	IsActive  bool      `db:"is_active"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+func (w *dbWebhook) toModel() *models.Webhook {
+	events := make([]enum.WebhookEvent, len(w.Events))
+	for i, event := range w.Events {
+		events[i] = enum.WebhookEvent(event)
+	}
+
+	return &models.Webhook{
+		ID:        w.ID,
+		Name:      w.Name,
+		URL:       w.URL,
+		Secret:    w.Secret,
+		Events:    events,
+		IsActive:  w.IsActive,
+		CreatedAt: w.CreatedAt,
+	}
+}
+
+type dbWebhookDelivery struct {
+	ID          int            `db:"id"`
+	Event       string         `db:"event"`
+	Attempt     int            `db:"attempt"`
+	StatusCode  int            `db:"status_code"`
+	Success     bool           `db:"success"`
+	Error       dbx.NullString `db:"error"`
+	Payload     string         `db:"payload"`
+	DurationMs  int            `db:"duration_ms"`
+	DeliveredAt time.Time      `db:"delivered_at"`
+}
+
+func (d *dbWebhookDelivery) toModel() *models.WebhookDelivery {
+	return &models.WebhookDelivery{
+		ID:          d.ID,
+		Event:       enum.WebhookEvent(d.Event),
+		Attempt:     d.Attempt,
+		StatusCode:  d.StatusCode,
+		Success:     d.Success,
+		Error:       d.Error.String,
+		Payload:     d.Payload,
+		DurationMs:  d.DurationMs,
+		DeliveredAt: d.DeliveredAt,
+	}
+}
+
+func webhookEventsToArray(events []enum.WebhookEvent) interface{} {
+	values := make([]string, len(events))
+	for i, event := range events {
+		values[i] = string(event)
+	}
+	return pq.Array(values)
+}
+
+func listWebhooks(ctx context.Context, q *query.ListWebhooks) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *models.Tenant, user *models.User) error {
+		webhooks, err := queryWebhooks(trx, `
+			SELECT id, name, url, secret, events, is_active, created_at
+			FROM webhooks
+			WHERE tenant_id = $1
+			ORDER BY id
+		`, tenant.ID)
+		if err != nil {
+			return errors.Wrap(err, "failed to list webhooks")
+		}
+
+		q.Result = webhooks
+		return nil
+	})
+}
+
+func listActiveWebhooksByEvent(ctx context.Context, q *query.ListActiveWebhooksByEvent) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *models.Tenant, user *models.User) error {
+		webhooks, err := queryWebhooks(trx, `
+			SELECT id, name, url, secret, events, is_active, created_at
+			FROM webhooks
+			WHERE tenant_id = $1 AND is_active = true AND $2 = ANY(events)
+			ORDER BY id
+		`, tenant.ID, string(q.Event))
+		if err != nil {
+			return errors.Wrap(err, "failed to list active webhooks for event '%s'", q.Event)
+		}
+
+		q.Result = webhooks
+		return nil
+	})
+}
+
+func getWebhookByID(ctx context.Context, q *query.GetWebhookByID) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *models.Tenant, user *models.User) error {
+		webhook, err := queryWebhookByID(trx, tenant, q.WebhookID)
+		q.Result = webhook
+		return err
+	})
+}
+
+func createWebhook(ctx context.Context, c *cmd.CreateWebhook) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *models.Tenant, user *models.User) error {
+		var id int
+		err := trx.Scalar(&id, `
+			INSERT INTO webhooks (tenant_id, name, url, secret, events, is_active, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			RETURNING id
+		`, tenant.ID, c.Name, c.URL, c.Secret, webhookEventsToArray(c.Events), c.IsActive, time.Now())
+		if err != nil {
+			return errors.Wrap(err, "failed to create webhook")
+		}
+
+		webhook, err := queryWebhookByID(trx, tenant, id)
+		c.Result = webhook
+		return err
+	})
+}
+
+func updateWebhook(ctx context.Context, c *cmd.UpdateWebhook) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *models.Tenant, user *models.User) error {
+		_, err := trx.Execute(`
+			UPDATE webhooks SET name = $1, url = $2, events = $3, is_active = $4
+			WHERE id = $5 AND tenant_id = $6
+		`, c.Name, c.URL, webhookEventsToArray(c.Events), c.IsActive, c.WebhookID, tenant.ID)
+		if err != nil {
+			return errors.Wrap(err, "failed to update webhook with id '%d'", c.WebhookID)
+		}
+
+		webhook, err := queryWebhookByID(trx, tenant, c.WebhookID)
+		c.Result = webhook
+		return err
+	})
+}
+
+func deleteWebhook(ctx context.Context, c *cmd.DeleteWebhook) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *models.Tenant, user *models.User) error {
+		_, err := trx.Execute(`DELETE FROM webhook_deliveries WHERE webhook_id = $1 AND tenant_id = $2`, c.WebhookID, tenant.ID)
+		if err != nil {
+			return errors.Wrap(err, "failed to delete deliveries of webhook with id '%d'", c.WebhookID)
+		}
+
+		_, err = trx.Execute(`DELETE FROM webhooks WHERE id = $1 AND tenant_id = $2`, c.WebhookID, tenant.ID)
+		if err != nil {
+			return errors.Wrap(err, "failed to delete webhook with id '%d'", c.WebhookID)
+		}
+		return nil
+	})
+}
+
+func logWebhookDelivery(ctx context.Context, c *cmd.LogWebhookDelivery) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *models.Tenant, user *models.User) error {
+		dbError := sql.NullString{
+			String: c.Error,
+			Valid:  len(c.Error) > 0,
+		}
+
+		_, err := trx.Execute(`
+			INSERT INTO webhook_deliveries (tenant_id, webhook_id, event, attempt, status_code, success, error, payload, duration_ms, delivered_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		`, tenant.ID, c.Webhook.ID, string(c.Event), c.Attempt, c.StatusCode, c.Success, dbError, c.Payload, c.DurationMs, time.Now())
+		if err != nil {
+			return errors.Wrap(err, "failed to log webhook delivery")
+		}
+		return nil
+	})
+}
+
+func listWebhookDeliveries(ctx context.Context, q *query.ListWebhookDeliveries) error {
+	return using(ctx, func(trx *dbx.Trx, tenant *models.Tenant, user *models.User) error {
+		limit := q.Limit
+		if limit <= 0 || limit > 100 {
+			limit = 50
+		}
+
+		deliveries := []*dbWebhookDelivery{}
+		err := trx.Select(&deliveries, `
+			SELECT id, event, attempt, status_code, success, error, payload, duration_ms, delivered_at
+			FROM webhook_deliveries
+			WHERE webhook_id = $1 AND tenant_id = $2
+			ORDER BY delivered_at DESC, id DESC
+			LIMIT $3
+		`, q.WebhookID, tenant.ID, limit)
+		if err != nil {
+			return errors.Wrap(err, "failed to list deliveries of webhook with id '%d'", q.WebhookID)
+		}
+
+		q.Result = make([]*models.WebhookDelivery, len(deliveries))
+		for i, delivery := range deliveries {
+			q.Result[i] = delivery.toModel()
+		}
+		return nil
+	})
+}
+
+func queryWebhookByID(trx *dbx.Trx, tenant *models.Tenant, id int) (*models.Webhook, error) {
+	webhook := dbWebhook{}
+	err := trx.Get(&webhook, `
+		SELECT id, name, url, secret, events, is_active, created_at
+		FROM webhooks
+		WHERE id = $1 AND tenant_id = $2
+	`, id, tenant.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get webhook with id '%d'", id)
+	}
+
+	return webhook.toModel(), nil
+}
+
+func queryWebhooks(trx *dbx.Trx, query string, args ...interface{}) ([]*models.Webhook, error) {
+	webhooks := []*dbWebhook{}
+	err := trx.Select(&webhooks, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var result = make([]*models.Webhook, len(webhooks))
+	for i, webhook := range webhooks {
+		result[i] = webhook.toModel()
+	}
+	return result, nil
+}
@@ -0,0 +1,106 @@
+package postgres_test
+
+import (
+	"testing"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/models/query"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/errors"
+)
+
+func TestWebhookStorage_CreateUpdateAndGet(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	createWebhook := &cmd.CreateWebhook{
+		Name:     "Slack Bot",
+		URL:      "https://hooks.example.com/fider",
+		Secret:   "my-secret",
+		Events:   []enum.WebhookEvent{enum.WebhookEventPostCreated, enum.WebhookEventVoteAdded},
+		IsActive: true,
+	}
+	err := bus.Dispatch(demoTenantCtx, createWebhook)
+	Expect(err).IsNil()
+	Expect(createWebhook.Result.ID).NotEquals(0)
+
+	updateWebhook := &cmd.UpdateWebhook{
+		WebhookID: createWebhook.Result.ID,
+		Name:      "Internal Bot",
+		URL:       "https://internal.example.com/fider",
+		Events:    []enum.WebhookEvent{enum.WebhookEventCommentCreated},
+		IsActive:  false,
+	}
+	err = bus.Dispatch(demoTenantCtx, updateWebhook)
+	Expect(err).IsNil()
+
+	getWebhook := &query.GetWebhookByID{WebhookID: createWebhook.Result.ID}
+	err = bus.Dispatch(demoTenantCtx, getWebhook)
+	Expect(err).IsNil()
+	Expect(getWebhook.Result.Name).Equals("Internal Bot")
+	Expect(getWebhook.Result.URL).Equals("https://internal.example.com/fider")
+	Expect(getWebhook.Result.Secret).Equals("my-secret")
+	Expect(getWebhook.Result.Events).Equals([]enum.WebhookEvent{enum.WebhookEventCommentCreated})
+	Expect(getWebhook.Result.IsActive).IsFalse()
+
+	getWebhook = &query.GetWebhookByID{WebhookID: createWebhook.Result.ID}
+	err = bus.Dispatch(avengersTenantCtx, getWebhook)
+	Expect(errors.Cause(err)).Equals(app.ErrNotFound)
+}
+
+func TestWebhookStorage_ListActiveByEvent(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	bus.MustDispatch(demoTenantCtx,
+		&cmd.CreateWebhook{Name: "A", URL: "https://a.example.com", Secret: "a", Events: []enum.WebhookEvent{enum.WebhookEventPostCreated}, IsActive: true},
+		&cmd.CreateWebhook{Name: "B", URL: "https://b.example.com", Secret: "b", Events: []enum.WebhookEvent{enum.WebhookEventPostCreated}, IsActive: false},
+		&cmd.CreateWebhook{Name: "C", URL: "https://c.example.com", Secret: "c", Events: []enum.WebhookEvent{enum.WebhookEventVoteAdded}, IsActive: true},
+	)
+	bus.MustDispatch(avengersTenantCtx,
+		&cmd.CreateWebhook{Name: "D", URL: "https://d.example.com", Secret: "d", Events: []enum.WebhookEvent{enum.WebhookEventPostCreated}, IsActive: true},
+	)
+
+	listAll := &query.ListWebhooks{}
+	err := bus.Dispatch(demoTenantCtx, listAll)
+	Expect(err).IsNil()
+	Expect(listAll.Result).HasLen(3)
+
+	listActive := &query.ListActiveWebhooksByEvent{Event: enum.WebhookEventPostCreated}
+	err = bus.Dispatch(demoTenantCtx, listActive)
+	Expect(err).IsNil()
+	Expect(listActive.Result).HasLen(1)
+	Expect(listActive.Result[0].Name).Equals("A")
+}
+
+func TestWebhookStorage_LogDeliveryAndDelete(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	createWebhook := &cmd.CreateWebhook{Name: "A", URL: "https://a.example.com", Secret: "a", Events: []enum.WebhookEvent{enum.WebhookEventPostCreated}, IsActive: true}
+	bus.MustDispatch(demoTenantCtx, createWebhook)
+
+	bus.MustDispatch(demoTenantCtx,
+		&cmd.LogWebhookDelivery{Webhook: createWebhook.Result, Event: enum.WebhookEventPostCreated, Attempt: 1, StatusCode: 500, Success: false, Error: "Internal Server Error", Payload: "{}", DurationMs: 20},
+		&cmd.LogWebhookDelivery{Webhook: createWebhook.Result, Event: enum.WebhookEventPostCreated, Attempt: 2, StatusCode: 200, Success: true, Payload: "{}", DurationMs: 15},
+	)
+
+	listDeliveries := &query.ListWebhookDeliveries{WebhookID: createWebhook.Result.ID}
+	err := bus.Dispatch(demoTenantCtx, listDeliveries)
+	Expect(err).IsNil()
+	Expect(listDeliveries.Result).HasLen(2)
+	Expect(listDeliveries.Result[0].Attempt).Equals(2)
+	Expect(listDeliveries.Result[0].Success).IsTrue()
+	Expect(listDeliveries.Result[1].Attempt).Equals(1)
+	Expect(listDeliveries.Result[1].Error).Equals("Internal Server Error")
+
+	err = bus.Dispatch(demoTenantCtx, &cmd.DeleteWebhook{WebhookID: createWebhook.Result.ID})
+	Expect(err).IsNil()
+
+	getWebhook := &query.GetWebhookByID{WebhookID: createWebhook.Result.ID}
+	err = bus.Dispatch(demoTenantCtx, getWebhook)
+	Expect(errors.Cause(err)).Equals(app.ErrNotFound)
+}
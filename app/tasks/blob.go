@@ -0,0 +1,61 @@
+package tasks
+
+import (
+	"context"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/dto"
+	"github.com/getfider/fider/app/models/query"
+	"github.com/getfider/fider/app/pkg/blobgc"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/env"
+	"github.com/getfider/fider/app/pkg/log"
+	"github.com/getfider/fider/app/pkg/worker"
+)
+
+//CollectBlobGarbage deletes the blobs of every tenant that are no longer referenced and reports the missing ones
+func CollectBlobGarbage() worker.Task {
+	return describe("Collect blob garbage", func(c *worker.Context) error {
+		//Every instance schedules this task, but it only runs once per interval.
+		//Some slack is given so that a run is not skipped when the previous one started a bit late
+		interval := env.Config.BlobStorage.GC.Interval
+		claim := &cmd.ClaimScheduledTask{Name: "collect_blob_garbage", Interval: interval - interval/10}
+		if err := bus.Dispatch(c, claim); err != nil {
+			return c.Failure(err)
+		}
+		if !claim.Result {
+			log.Debug(c, "Blob garbage was already collected by another instance.")
+			return nil
+		}
+
+		getTenants := &query.GetAllTenants{}
+		if err := bus.Dispatch(c, getTenants); err != nil {
+			return c.Failure(err)
+		}
+
+		for _, tenant := range getTenants.Result {
+			ctx := context.WithValue(c, app.TenantCtxKey, tenant)
+			report, err := blobgc.Collect(ctx, env.Config.BlobStorage.GC.GracePeriod, false)
+			if err != nil {
+				return c.Failure(err)
+			}
+
+			if len(report.Deleted) > 0 {
+				log.Infof(c, "Deleted @{Count} unreferenced blobs of tenant @{TenantID}.", dto.Props{
+					"Count":    len(report.Deleted),
+					"TenantID": tenant.ID,
+				})
+			}
+
+			for _, key := range report.Missing {
+				log.Warnf(c, "Blob '@{BlobKey}' of tenant @{TenantID} is referenced but doesn't exist.", dto.Props{
+					"BlobKey":  key,
+					"TenantID": tenant.ID,
+				})
+			}
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,58 @@
+package tasks_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/query"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/env"
+	"github.com/getfider/fider/app/pkg/mock"
+	"github.com/getfider/fider/app/tasks"
+)
+
+func TestCollectBlobGarbageTask(t *testing.T) {
+	RegisterT(t)
+	env.Config.BlobStorage.GC.Interval = 10 * time.Hour
+
+	var claim *cmd.ClaimScheduledTask
+	bus.AddHandler(func(ctx context.Context, c *cmd.ClaimScheduledTask) error {
+		claim = c
+		c.Result = true
+		return nil
+	})
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetAllTenants) error {
+		q.Result = []*models.Tenant{}
+		return nil
+	})
+
+	err := mock.NewWorker().Execute(tasks.CollectBlobGarbage())
+	Expect(err).IsNil()
+	Expect(claim.Name).Equals("collect_blob_garbage")
+	Expect(claim.Interval).Equals(9 * time.Hour)
+}
+
+func TestCollectBlobGarbageTask_AlreadyCollected(t *testing.T) {
+	RegisterT(t)
+	env.Config.BlobStorage.GC.Interval = 10 * time.Hour
+
+	bus.AddHandler(func(ctx context.Context, c *cmd.ClaimScheduledTask) error {
+		c.Result = false
+		return nil
+	})
+
+	getTenants := false
+	bus.AddHandler(func(ctx context.Context, q *query.GetAllTenants) error {
+		getTenants = true
+		return nil
+	})
+
+	err := mock.NewWorker().Execute(tasks.CollectBlobGarbage())
+	Expect(err).IsNil()
+	Expect(getTenants).IsFalse()
+}
@@ -0,0 +1,164 @@
+package tasks
+
+import (
+	"net/mail"
+	"net/url"
+	"strings"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/dto"
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/models/query"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/env"
+	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/getfider/fider/app/pkg/i18n"
+	"github.com/getfider/fider/app/pkg/log"
+	"github.com/getfider/fider/app/pkg/web"
+	"github.com/getfider/fider/app/pkg/worker"
+	"github.com/getfider/fider/app/services/email"
+)
+
+//newReplyableRecipient returns a recipient that can comment on given post by replying to the email
+func newReplyableRecipient(c *worker.Context, user *models.User, post *models.Post) dto.Recipient {
+	to := dto.NewRecipient(user.Name, user.Email, dto.Props{})
+	to.ReplyTo = email.ReplyAddress(c.Tenant().ID, user.ID, post.Number)
+	return to
+}
+
+//withReplyHint tells recipients that they can reply to the email, when reply by email is enabled
+func withReplyHint(props dto.Props) dto.Props {
+	if env.IsReplyByEmailEnabled() {
+		props["reply"] = true
+	}
+	return props
+}
+
+//ReceiveReply posts the reply to a notification email as a comment on behalf of the user it was sent to
+func ReceiveReply(message *models.InboundEmail) worker.Task {
+	return describe("Receive reply", func(c *worker.Context) error {
+		if message.AutoSubmitted {
+			log.Debugf(c, "Ignoring automatic reply from @{From}.", dto.Props{
+				"From": message.From,
+			})
+			return nil
+		}
+
+		var token *email.ReplyToken
+		for _, address := range message.To {
+			if parsed, err := email.ParseReplyAddress(address); err == nil {
+				token = parsed
+				break
+			}
+		}
+
+		//Invalid messages are dropped without a bounce, as their sender could be forged
+		if token == nil {
+			log.Warnf(c, "Ignoring reply from @{From} without a valid reply address.", dto.Props{
+				"From": message.From,
+			})
+			return nil
+		}
+
+		getTenant := &query.GetTenantByID{TenantID: token.TenantID}
+		if err := bus.Dispatch(c, getTenant); err != nil {
+			if errors.Cause(err) == app.ErrNotFound {
+				return nil
+			}
+			return c.Failure(err)
+		}
+
+		tenant := getTenant.Result
+		if tenant.Status != enum.TenantActive {
+			return nil
+		}
+		c.Set(app.TenantCtxKey, tenant)
+		if request, ok := c.Value(app.RequestCtxKey).(web.Request); ok {
+			if u, err := url.Parse(web.TenantBaseURL(c, tenant)); err == nil {
+				request.URL = u
+				c.Set(app.RequestCtxKey, request)
+			}
+		}
+
+		getUser := &query.GetUserByID{UserID: token.UserID}
+		if err := bus.Dispatch(c, getUser); err != nil {
+			if errors.Cause(err) == app.ErrNotFound {
+				return nil
+			}
+			return c.Failure(err)
+		}
+
+		user := getUser.Result
+		if user.Status != enum.UserActive || user.Email == "" {
+			return nil
+		}
+		c.Set(app.UserCtxKey, user)
+
+		//Bounces are only sent to the owner of the reply address, never to the sender of the message
+		if sender, err := mail.ParseAddress(message.From); err != nil || !strings.EqualFold(sender.Address, user.Email) {
+			return bounceReply(c, user, message, "it was sent from %s, which is not the email address of your account", message.From)
+		}
+
+		getPost := &query.GetPostByNumber{Number: token.PostNumber}
+		if err := bus.Dispatch(c, getPost); err != nil {
+			if errors.Cause(err) == app.ErrNotFound {
+				return bounceReply(c, user, message, "the post you replied to doesn't exist anymore")
+			}
+			return c.Failure(err)
+		}
+
+		post := getPost.Result
+		if post.Status == enum.PostDeleted {
+			return bounceReply(c, user, message, "the post you replied to has been deleted")
+		}
+
+		content := email.StripReply(message.Text)
+		if content == "" {
+			return bounceReply(c, user, message, "we couldn't find any text in it")
+		}
+
+		addNewComment := &cmd.AddNewComment{
+			Post:    post,
+			Content: content,
+		}
+		if err := bus.Dispatch(c, addNewComment); err != nil {
+			return c.Failure(err)
+		}
+
+		//Follow-up tasks run inline, as retrying this task because of them would add the same comment twice
+		for _, task := range []worker.Task{
+			NotifyAboutNewComment(post, &models.NewComment{Number: post.Number, Content: content}),
+			TriggerWebhooks(enum.WebhookEventCommentCreated, web.Map{
+				"post":    post,
+				"comment": addNewComment.Result,
+			}),
+		} {
+			_ = task.Job(c)
+		}
+
+		return nil
+	}, message)
+}
+
+//bounceReply tells the user why their reply has not been posted, in their own language.
+//Message is not quoted, otherwise anyone could use the bounce to send their own text to the user
+func bounceReply(c *worker.Context, user *models.User, message *models.InboundEmail, reason string, args ...interface{}) error {
+	to := dto.NewRecipient(user.Name, user.Email, dto.Props{})
+	locale := i18n.UserLocale(user, c.Tenant())
+
+	bus.Publish(i18n.WithLocale(c, locale), &cmd.SendMail{
+		From:         c.Tenant().Name,
+		To:           []dto.Recipient{to},
+		TemplateName: "reply_bounce",
+		Props: dto.Props{
+			"tenantName": c.Tenant().Name,
+			"subject":    message.Subject,
+			"reason":     i18n.T(locale, reason, args...),
+			"logo":       web.LogoURL(c),
+		},
+	})
+
+	return nil
+}
@@ -0,0 +1,182 @@
+package tasks_test
+
+import (
+	"context"
+	"html/template"
+	"testing"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/models/query"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/env"
+	"github.com/getfider/fider/app/pkg/mock"
+	"github.com/getfider/fider/app/services/email"
+	"github.com/getfider/fider/app/services/email/emailmock"
+	"github.com/getfider/fider/app/tasks"
+)
+
+var replyPost = &models.Post{
+	ID:     1,
+	Number: 1,
+	Title:  "Add support for TypeScript",
+	Slug:   "add-support-for-typescript",
+	Status: enum.PostOpen,
+}
+
+func setupReplyTest() (*mock.Worker, *cmd.AddNewComment) {
+	worker := mock.NewWorker()
+	env.Config.Email.Inbound.Domain = "inbound.test.fider.io"
+
+	bus.Init(emailmock.Service{})
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetTenantByID) error {
+		if q.TenantID == mock.DemoTenant.ID {
+			q.Result = mock.DemoTenant
+			return nil
+		}
+		return app.ErrNotFound
+	})
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetUserByID) error {
+		if q.UserID == mock.JonSnow.ID {
+			q.Result = mock.JonSnow
+			return nil
+		}
+		return app.ErrNotFound
+	})
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetPostByNumber) error {
+		if q.Number == replyPost.Number {
+			q.Result = replyPost
+			return nil
+		}
+		return app.ErrNotFound
+	})
+
+	addNewComment := &cmd.AddNewComment{}
+	bus.AddHandler(func(ctx context.Context, c *cmd.AddNewComment) error {
+		*addNewComment = *c
+		addNewComment.Result = &models.Comment{ID: 1, Content: c.Content, User: ctx.Value(app.UserCtxKey).(*models.User)}
+		return nil
+	})
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetActiveSubscribers) error {
+		q.Result = []*models.User{}
+		if q.Channel == enum.NotificationChannelEmail {
+			q.Result = []*models.User{mock.AryaStark}
+		}
+		return nil
+	})
+
+	bus.AddHandler(func(ctx context.Context, c *cmd.AddToDigest) error {
+		return nil
+	})
+
+	bus.AddHandler(func(ctx context.Context, q *query.ListActiveWebhooksByEvent) error {
+		q.Result = []*models.Webhook{}
+		return nil
+	})
+
+	return worker, addNewComment
+}
+
+func TestReceiveReplyTask(t *testing.T) {
+	RegisterT(t)
+	worker, addNewComment := setupReplyTest()
+
+	task := tasks.ReceiveReply(&models.InboundEmail{
+		From:    "Jon Snow <jon.snow@got.com>",
+		To:      []string{email.ReplyAddress(mock.DemoTenant.ID, mock.JonSnow.ID, replyPost.Number)},
+		Subject: "Re: [Demonstration] Add support for TypeScript",
+		Text:    "I agree!\n\nOn Mon, 1 Jul 2019 at 10:00, Fider <noreply@fider.io> wrote:\n> TypeScript is great",
+	})
+
+	err := worker.
+		WithBaseURL("http://test.fider.io").
+		Execute(task)
+
+	Expect(err).IsNil()
+	Expect(addNewComment.Post).Equals(replyPost)
+	Expect(addNewComment.Content).Equals("I agree!")
+	Expect(addNewComment.Result.User).Equals(mock.JonSnow)
+	Expect(emailmock.MessageHistory).HasLen(1)
+	Expect(emailmock.MessageHistory[0].TemplateName).Equals("new_comment")
+	Expect(emailmock.MessageHistory[0].Tenant).Equals(mock.DemoTenant)
+	Expect(emailmock.MessageHistory[0].Props["userName"]).Equals("Jon Snow")
+	Expect(emailmock.MessageHistory[0].Props["view"]).Equals(template.HTML("<a href='http://demo.test.fider.io/posts/1/add-support-for-typescript'>View it on your browser</a>"))
+}
+
+func TestReceiveReplyTask_InvalidAddress(t *testing.T) {
+	RegisterT(t)
+	worker, addNewComment := setupReplyTest()
+
+	task := tasks.ReceiveReply(&models.InboundEmail{
+		From: "jon.snow@got.com",
+		To:   []string{"reply+1.1.1.abcdef@inbound.test.fider.io"},
+		Text: "I agree!",
+	})
+
+	err := worker.Execute(task)
+	Expect(err).IsNil()
+	Expect(addNewComment.Content).Equals("")
+	Expect(emailmock.MessageHistory).HasLen(0)
+}
+
+func TestReceiveReplyTask_AutoSubmitted(t *testing.T) {
+	RegisterT(t)
+	worker, addNewComment := setupReplyTest()
+
+	task := tasks.ReceiveReply(&models.InboundEmail{
+		From:          "jon.snow@got.com",
+		To:            []string{email.ReplyAddress(mock.DemoTenant.ID, mock.JonSnow.ID, replyPost.Number)},
+		Text:          "I'm out of office",
+		AutoSubmitted: true,
+	})
+
+	err := worker.Execute(task)
+	Expect(err).IsNil()
+	Expect(addNewComment.Content).Equals("")
+	Expect(emailmock.MessageHistory).HasLen(0)
+}
+
+func TestReceiveReplyTask_Bounce(t *testing.T) {
+	testCases := []struct {
+		from       string
+		postNumber int
+		text       string
+	}{
+		{"arya.stark@got.com", replyPost.Number, "I agree!"},
+		{"jon.snow@got.com", 999, "I agree!"},
+		{"jon.snow@got.com", replyPost.Number, "> TypeScript is great"},
+	}
+
+	for _, testCase := range testCases {
+		RegisterT(t)
+		worker, addNewComment := setupReplyTest()
+
+		task := tasks.ReceiveReply(&models.InboundEmail{
+			From:    testCase.from,
+			To:      []string{email.ReplyAddress(mock.DemoTenant.ID, mock.JonSnow.ID, testCase.postNumber)},
+			Subject: "Re: [Demonstration] Add support for TypeScript",
+			Text:    testCase.text,
+		})
+
+		err := worker.
+			WithBaseURL("http://test.fider.io").
+			Execute(task)
+
+		Expect(err).IsNil()
+		Expect(addNewComment.Content).Equals("")
+		Expect(emailmock.MessageHistory).HasLen(1)
+		Expect(emailmock.MessageHistory[0].TemplateName).Equals("reply_bounce")
+		Expect(emailmock.MessageHistory[0].Tenant).Equals(mock.DemoTenant)
+		Expect(emailmock.MessageHistory[0].To).HasLen(1)
+		Expect(emailmock.MessageHistory[0].To[0].Address).Equals("jon.snow@got.com")
+		Expect(emailmock.MessageHistory[0].Props["subject"]).Equals("Re: [Demonstration] Add support for TypeScript")
+		Expect(emailmock.MessageHistory[0].Props["content"]).IsNil()
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"html/template"
+	"net/http"
 	"strings"
 	"time"
 
@@ -14,13 +15,42 @@ import (
 	"github.com/getfider/fider/app/models/enum"
 	"github.com/getfider/fider/app/models/query"
 	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/getfider/fider/app/pkg/i18n"
+	"github.com/getfider/fider/app/pkg/log"
 	"github.com/getfider/fider/app/pkg/markdown"
 	"github.com/getfider/fider/app/pkg/web"
 	"github.com/getfider/fider/app/pkg/worker"
 )
 
-func describe(name string, job worker.Job) worker.Task {
-	return worker.Task{Name: name, Job: job}
+func init() {
+	worker.Register("Send sign up email", SendSignUpEmail)
+	worker.Register("Send sign in email", SendSignInEmail)
+	worker.Register("Send change email confirmation", SendChangeEmailConfirmation)
+	worker.Register("Notify about new post", NotifyAboutNewPost)
+	worker.Register("Notify about new comment", NotifyAboutNewComment)
+	worker.Register("Notify about post status change", NotifyAboutStatusChange)
+	worker.Register("Notify about deleted post", NotifyAboutDeletedPost)
+	worker.Register("Send invites", SendInvites)
+	worker.Register("Send digests", SendDigests)
+	worker.Register("Receive reply", ReceiveReply)
+	worker.Register("Trigger webhooks", triggerWebhooks)
+	worker.Register("Deliver webhook", DeliverWebhook)
+	worker.Register("Collect blob garbage", CollectBlobGarbage)
+}
+
+//describe creates a task with given name and job
+//args must be the same arguments given to the task constructor, so durable workers can rebuild it later
+func describe(name string, job worker.Job, args ...interface{}) worker.Task {
+	return worker.Task{Name: name, Job: job, Args: args}
+}
+
+//describeOnce creates a task that durable workers don't retry when it fails,
+//because running it again would repeat the notifications and emails that were already sent
+func describeOnce(name string, job worker.Job, args ...interface{}) worker.Task {
+	task := describe(name, job, args...)
+	task.MaxAttempts = 1
+	return task
 }
 
 func link(baseURL, path string, args ...interface{}) template.HTML {
@@ -48,7 +78,7 @@ func SendSignUpEmail(model *models.CreateTenant, baseURL string) worker.Task {
 		})
 
 		return nil
-	})
+	}, model, baseURL)
 }
 
 //SendSignInEmail is used to send the sign in email to requestor
@@ -69,7 +99,7 @@ func SendSignInEmail(model *models.SignInByEmail) worker.Task {
 		})
 
 		return nil
-	})
+	}, model)
 }
 
 //SendChangeEmailConfirmation is used to send the change email confirmation email to requestor
@@ -87,7 +117,7 @@ func SendChangeEmailConfirmation(model *models.ChangeUserEmail) worker.Task {
 			"link":     link(web.BaseURL(c), "/change-email/verify?k=%s", model.VerificationKey),
 		})
 
-		bus.Publish(c, &cmd.SendMail{
+		bus.Publish(i18n.WithLocale(c, i18n.UserLocale(c.User(), c.Tenant())), &cmd.SendMail{
 			From:         c.Tenant().Name,
 			To:           []dto.Recipient{to},
 			TemplateName: "change_emailaddress_email",
@@ -97,32 +127,25 @@ func SendChangeEmailConfirmation(model *models.ChangeUserEmail) worker.Task {
 		})
 
 		return nil
-	})
+	}, model)
 }
 
 //NotifyAboutNewPost sends a notification (web and email) to subscribers
 func NotifyAboutNewPost(post *models.Post) worker.Task {
-	return describe("Notify about new post", func(c *worker.Context) error {
+	return describeOnce("Notify about new post", func(c *worker.Context) error {
+		title := func(locale string) string {
+			return i18n.T(locale, "New post: **%s**", post.Title)
+		}
+		link := fmt.Sprintf("/posts/%d/%s", post.Number, post.Slug)
+
 		// Web notification
 		users, err := getActiveSubscribers(c, post, enum.NotificationChannelWeb, enum.NotificationEventNewPost)
 		if err != nil {
 			return c.Failure(err)
 		}
 
-		title := fmt.Sprintf("New post: **%s**", post.Title)
-		link := fmt.Sprintf("/posts/%d/%s", post.Number, post.Slug)
-		for _, user := range users {
-			if user.ID != c.User().ID {
-				err = bus.Dispatch(c, &cmd.AddNewNotification{
-					User:   user,
-					Title:  title,
-					Link:   link,
-					PostID: post.ID,
-				})
-				if err != nil {
-					return c.Failure(err)
-				}
-			}
+		if err = addNotifications(c, users, post, title, link); err != nil {
+			return c.Failure(err)
 		}
 
 		// Email notification
@@ -131,58 +154,57 @@ func NotifyAboutNewPost(post *models.Post) worker.Task {
 			return c.Failure(err)
 		}
 
-		to := make([]dto.Recipient, 0)
-		for _, user := range users {
-			if user.ID != c.User().ID {
-				to = append(to, dto.NewRecipient(user.Name, user.Email, dto.Props{}))
-			}
+		users, err = addToDigest(c, users, enum.NotificationEventNewPost, post, title, link)
+		if err != nil {
+			return c.Failure(err)
 		}
 
-		props := dto.Props{
-			"title":      post.Title,
-			"tenantName": c.Tenant().Name,
-			"userName":   c.User().Name,
-			"content":    markdown.Simple(post.Description),
-			"postLink":   linkWithText(fmt.Sprintf("#%d", post.Number), web.BaseURL(c), "/posts/%d/%s", post.Number, post.Slug),
-			"view":       linkWithText("View it on your browser", web.BaseURL(c), "/posts/%d/%s", post.Number, post.Slug),
-			"change":     linkWithText("change your notification settings", web.BaseURL(c), "/settings"),
-			"logo":       web.LogoURL(c),
-		}
+		locales, groups := groupByLocale(c, users)
+		for _, locale := range locales {
+			to := make([]dto.Recipient, 0)
+			for _, user := range groups[locale] {
+				to = append(to, newReplyableRecipient(c, user, post))
+			}
 
-		bus.Publish(c, &cmd.SendMail{
-			From:         c.User().Name,
-			To:           to,
-			TemplateName: "new_post",
-			Props:        props,
-		})
+			props := dto.Props{
+				"title":      post.Title,
+				"tenantName": c.Tenant().Name,
+				"userName":   c.User().Name,
+				"content":    markdown.Simple(post.Description),
+				"postLink":   linkWithText(fmt.Sprintf("#%d", post.Number), web.BaseURL(c), "/posts/%d/%s", post.Number, post.Slug),
+				"view":       linkWithText(i18n.T(locale, "View it on your browser"), web.BaseURL(c), "/posts/%d/%s", post.Number, post.Slug),
+				"change":     linkWithText(i18n.T(locale, "change your notification settings"), web.BaseURL(c), "/settings"),
+				"logo":       web.LogoURL(c),
+			}
+
+			bus.Publish(i18n.WithLocale(c, locale), &cmd.SendMail{
+				From:         c.User().Name,
+				To:           to,
+				TemplateName: "new_post",
+				Props:        withReplyHint(props),
+			})
+		}
 
 		return nil
-	})
+	}, post)
 }
 
 //NotifyAboutNewComment sends a notification (web and email) to subscribers
 func NotifyAboutNewComment(post *models.Post, comment *models.NewComment) worker.Task {
-	return describe("Notify about new comment", func(c *worker.Context) error {
+	return describeOnce("Notify about new comment", func(c *worker.Context) error {
+		title := func(locale string) string {
+			return i18n.T(locale, "**%s** left a comment on **%s**", c.User().Name, post.Title)
+		}
+		link := fmt.Sprintf("/posts/%d/%s", post.Number, post.Slug)
+
 		// Web notification
 		users, err := getActiveSubscribers(c, post, enum.NotificationChannelWeb, enum.NotificationEventNewComment)
 		if err != nil {
 			return c.Failure(err)
 		}
 
-		title := fmt.Sprintf("**%s** left a comment on **%s**", c.User().Name, post.Title)
-		link := fmt.Sprintf("/posts/%d/%s", post.Number, post.Slug)
-		for _, user := range users {
-			if user.ID != c.User().ID {
-				err = bus.Dispatch(c, &cmd.AddNewNotification{
-					User:   user,
-					Title:  title,
-					Link:   link,
-					PostID: post.ID,
-				})
-				if err != nil {
-					return c.Failure(err)
-				}
-			}
+		if err = addNotifications(c, users, post, title, link); err != nil {
+			return c.Failure(err)
 		}
 
 		// Email notification
@@ -191,64 +213,63 @@ func NotifyAboutNewComment(post *models.Post, comment *models.NewComment) worker
 			return c.Failure(err)
 		}
 
-		to := make([]dto.Recipient, 0)
-		for _, user := range users {
-			if user.ID != c.User().ID {
-				to = append(to, dto.NewRecipient(user.Name, user.Email, dto.Props{}))
-			}
+		users, err = addToDigest(c, users, enum.NotificationEventNewComment, post, title, link)
+		if err != nil {
+			return c.Failure(err)
 		}
 
-		props := dto.Props{
-			"title":       post.Title,
-			"tenantName":  c.Tenant().Name,
-			"userName":    c.User().Name,
-			"content":     markdown.Simple(comment.Content),
-			"postLink":    linkWithText(fmt.Sprintf("#%d", post.Number), web.BaseURL(c), "/posts/%d/%s", post.Number, post.Slug),
-			"view":        linkWithText("View it on your browser", web.BaseURL(c), "/posts/%d/%s", post.Number, post.Slug),
-			"unsubscribe": linkWithText("unsubscribe from it", web.BaseURL(c), "/posts/%d/%s", post.Number, post.Slug),
-			"change":      linkWithText("change your notification settings", web.BaseURL(c), "/settings"),
-			"logo":        web.LogoURL(c),
-		}
+		locales, groups := groupByLocale(c, users)
+		for _, locale := range locales {
+			to := make([]dto.Recipient, 0)
+			for _, user := range groups[locale] {
+				to = append(to, newReplyableRecipient(c, user, post))
+			}
 
-		bus.Publish(c, &cmd.SendMail{
-			From:         c.User().Name,
-			To:           to,
-			TemplateName: "new_comment",
-			Props:        props,
-		})
+			props := dto.Props{
+				"title":       post.Title,
+				"tenantName":  c.Tenant().Name,
+				"userName":    c.User().Name,
+				"content":     markdown.Simple(comment.Content),
+				"postLink":    linkWithText(fmt.Sprintf("#%d", post.Number), web.BaseURL(c), "/posts/%d/%s", post.Number, post.Slug),
+				"view":        linkWithText(i18n.T(locale, "View it on your browser"), web.BaseURL(c), "/posts/%d/%s", post.Number, post.Slug),
+				"unsubscribe": linkWithText(i18n.T(locale, "unsubscribe from it"), web.BaseURL(c), "/posts/%d/%s", post.Number, post.Slug),
+				"change":      linkWithText(i18n.T(locale, "change your notification settings"), web.BaseURL(c), "/settings"),
+				"logo":        web.LogoURL(c),
+			}
+
+			bus.Publish(i18n.WithLocale(c, locale), &cmd.SendMail{
+				From:         c.User().Name,
+				To:           to,
+				TemplateName: "new_comment",
+				Props:        withReplyHint(props),
+			})
+		}
 
 		return nil
-	})
+	}, post, comment)
 }
 
 //NotifyAboutStatusChange sends a notification (web and email) to subscribers
 func NotifyAboutStatusChange(post *models.Post, prevStatus enum.PostStatus) worker.Task {
-	return describe("Notify about post status change", func(c *worker.Context) error {
+	return describeOnce("Notify about post status change", func(c *worker.Context) error {
 		//Don't notify if previous status is the same
 		if prevStatus == post.Status {
 			return nil
 		}
 
+		title := func(locale string) string {
+			return i18n.T(locale, "**%s** changed status of **%s** to **%s**", c.User().Name, post.Title, i18n.T(locale, post.Status.Name()))
+		}
+		link := fmt.Sprintf("/posts/%d/%s", post.Number, post.Slug)
+
 		// Web notification
 		users, err := getActiveSubscribers(c, post, enum.NotificationChannelWeb, enum.NotificationEventChangeStatus)
 		if err != nil {
 			return c.Failure(err)
 		}
 
-		title := fmt.Sprintf("**%s** changed status of **%s** to **%s**", c.User().Name, post.Title, post.Status.Name())
-		link := fmt.Sprintf("/posts/%d/%s", post.Number, post.Slug)
-		for _, user := range users {
-			if user.ID != c.User().ID {
-				err = bus.Dispatch(c, &cmd.AddNewNotification{
-					User:   user,
-					Title:  title,
-					Link:   link,
-					PostID: post.ID,
-				})
-				if err != nil {
-					return c.Failure(err)
-				}
-			}
+		if err = addNotifications(c, users, post, title, link); err != nil {
+			return c.Failure(err)
 		}
 
 		// Email notification
@@ -257,45 +278,54 @@ func NotifyAboutStatusChange(post *models.Post, prevStatus enum.PostStatus) work
 			return c.Failure(err)
 		}
 
+		users, err = addToDigest(c, users, enum.NotificationEventChangeStatus, post, title, link)
+		if err != nil {
+			return c.Failure(err)
+		}
+
 		var duplicate template.HTML
 		if post.Status == enum.PostDuplicate {
 			duplicate = linkWithText(post.Response.Original.Title, web.BaseURL(c), "/posts/%d/%s", post.Response.Original.Number, post.Response.Original.Slug)
 		}
 
-		to := make([]dto.Recipient, 0)
-		for _, user := range users {
-			if user.ID != c.User().ID {
-				to = append(to, dto.NewRecipient(user.Name, user.Email, dto.Props{}))
+		locales, groups := groupByLocale(c, users)
+		for _, locale := range locales {
+			to := make([]dto.Recipient, 0)
+			for _, user := range groups[locale] {
+				to = append(to, newReplyableRecipient(c, user, post))
 			}
-		}
 
-		props := dto.Props{
-			"title":       post.Title,
-			"postLink":    linkWithText(fmt.Sprintf("#%d", post.Number), web.BaseURL(c), "/posts/%d/%s", post.Number, post.Slug),
-			"tenantName":  c.Tenant().Name,
-			"content":     markdown.Simple(post.Response.Text),
-			"status":      post.Status.Name(),
-			"duplicate":   duplicate,
-			"view":        linkWithText("View it on your browser", web.BaseURL(c), "/posts/%d/%s", post.Number, post.Slug),
-			"unsubscribe": linkWithText("unsubscribe from it", web.BaseURL(c), "/posts/%d/%s", post.Number, post.Slug),
-			"change":      linkWithText("change your notification settings", web.BaseURL(c), "/settings"),
-			"logo":        web.LogoURL(c),
-		}
+			props := dto.Props{
+				"title":       post.Title,
+				"postLink":    linkWithText(fmt.Sprintf("#%d", post.Number), web.BaseURL(c), "/posts/%d/%s", post.Number, post.Slug),
+				"tenantName":  c.Tenant().Name,
+				"content":     markdown.Simple(post.Response.Text),
+				"status":      i18n.T(locale, post.Status.Name()),
+				"duplicate":   duplicate,
+				"view":        linkWithText(i18n.T(locale, "View it on your browser"), web.BaseURL(c), "/posts/%d/%s", post.Number, post.Slug),
+				"unsubscribe": linkWithText(i18n.T(locale, "unsubscribe from it"), web.BaseURL(c), "/posts/%d/%s", post.Number, post.Slug),
+				"change":      linkWithText(i18n.T(locale, "change your notification settings"), web.BaseURL(c), "/settings"),
+				"logo":        web.LogoURL(c),
+			}
 
-		bus.Publish(c, &cmd.SendMail{
-			From:         c.User().Name,
-			To:           to,
-			TemplateName: "change_status",
-			Props:        props,
-		})
+			bus.Publish(i18n.WithLocale(c, locale), &cmd.SendMail{
+				From:         c.User().Name,
+				To:           to,
+				TemplateName: "change_status",
+				Props:        withReplyHint(props),
+			})
+		}
 
 		return nil
-	})
+	}, post, prevStatus)
 }
 
 //NotifyAboutDeletedPost sends a notification (web and email) to subscribers of the post that has been deleted
 func NotifyAboutDeletedPost(post *models.Post) worker.Task {
-	return describe("Notify about deleted post", func(c *worker.Context) error {
+	return describeOnce("Notify about deleted post", func(c *worker.Context) error {
+		title := func(locale string) string {
+			return i18n.T(locale, "**%s** deleted **%s**", c.User().Name, post.Title)
+		}
 
 		// Web notification
 		users, err := getActiveSubscribers(c, post, enum.NotificationChannelWeb, enum.NotificationEventChangeStatus)
@@ -303,18 +333,8 @@ func NotifyAboutDeletedPost(post *models.Post) worker.Task {
 			return c.Failure(err)
 		}
 
-		title := fmt.Sprintf("**%s** deleted **%s**", c.User().Name, post.Title)
-		for _, user := range users {
-			if user.ID != c.User().ID {
-				err = bus.Dispatch(c, &cmd.AddNewNotification{
-					User:   user,
-					Title:  title,
-					PostID: post.ID,
-				})
-				if err != nil {
-					return c.Failure(err)
-				}
-			}
+		if err = addNotifications(c, users, post, title, ""); err != nil {
+			return c.Failure(err)
 		}
 
 		// Email notification
@@ -323,35 +343,248 @@ func NotifyAboutDeletedPost(post *models.Post) worker.Task {
 			return c.Failure(err)
 		}
 
-		to := make([]dto.Recipient, 0)
-		for _, user := range users {
-			if user.ID != c.User().ID {
+		locales, groups := groupByLocale(c, users)
+		for _, locale := range locales {
+			to := make([]dto.Recipient, 0)
+			for _, user := range groups[locale] {
 				to = append(to, dto.NewRecipient(user.Name, user.Email, dto.Props{}))
 			}
+
+			props := dto.Props{
+				"title":      post.Title,
+				"tenantName": c.Tenant().Name,
+				"content":    markdown.Simple(post.Response.Text),
+				"change":     linkWithText(i18n.T(locale, "change your notification settings"), web.BaseURL(c), "/settings"),
+				"logo":       web.LogoURL(c),
+			}
+
+			bus.Publish(i18n.WithLocale(c, locale), &cmd.SendMail{
+				From:         c.User().Name,
+				To:           to,
+				TemplateName: "delete_post",
+				Props:        props,
+			})
 		}
 
-		props := dto.Props{
-			"title":      post.Title,
-			"tenantName": c.Tenant().Name,
-			"content":    markdown.Simple(post.Response.Text),
-			"change":     linkWithText("change your notification settings", web.BaseURL(c), "/settings"),
-			"logo":       web.LogoURL(c),
+		return nil
+	}, post)
+}
+
+//groupByLocale groups users by their preferred locale, ignoring the user that triggered the notification
+//Locales are returned in the order they first appear, so that each group is notified in a predictable order
+func groupByLocale(c *worker.Context, users []*models.User) ([]string, map[string][]*models.User) {
+	locales := make([]string, 0)
+	groups := make(map[string][]*models.User)
+	for _, user := range users {
+		if user.ID == c.User().ID {
+			continue
 		}
 
-		bus.Publish(c, &cmd.SendMail{
-			From:         c.User().Name,
-			To:           to,
-			TemplateName: "delete_post",
-			Props:        props,
-		})
+		locale := i18n.UserLocale(user, c.Tenant())
+		if _, ok := groups[locale]; !ok {
+			locales = append(locales, locale)
+		}
+		groups[locale] = append(groups[locale], user)
+	}
+	return locales, groups
+}
+
+//addNotifications adds a web notification to each user, with a title in their own language
+func addNotifications(c *worker.Context, users []*models.User, post *models.Post, title func(locale string) string, link string) error {
+	for _, user := range users {
+		if user.ID != c.User().ID {
+			err := bus.Dispatch(c, &cmd.AddNewNotification{
+				User:   user,
+				Title:  title(i18n.UserLocale(user, c.Tenant())),
+				Link:   link,
+				PostID: post.ID,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+//addToDigest queues given event on the digest of users that prefer daily or weekly summaries
+//Returns all other users, which should still be notified immediately
+func addToDigest(c *worker.Context, users []*models.User, event enum.NotificationEvent, post *models.Post, title func(locale string) string, link string) ([]*models.User, error) {
+	locales, groups := groupByLocale(c, users)
+
+	queued := make(map[int]bool)
+	for _, locale := range locales {
+		addToDigest := &cmd.AddToDigest{
+			Users:   groups[locale],
+			Event:   event,
+			Title:   title(locale),
+			Link:    link,
+			BaseURL: web.BaseURL(c),
+			PostID:  post.ID,
+		}
+		if err := bus.Dispatch(c, addToDigest); err != nil {
+			return nil, err
+		}
+
+		for _, user := range addToDigest.Result {
+			queued[user.ID] = true
+		}
+	}
+
+	immediate := make([]*models.User, 0, len(users))
+	for _, user := range users {
+		if user.ID != c.User().ID && !queued[user.ID] {
+			immediate = append(immediate, user)
+		}
+	}
+	return immediate, nil
+}
+
+//SendDigests sends a single email to each user with all their digest items that are due
+func SendDigests() worker.Task {
+	return describe("Send digests", func(c *worker.Context) error {
+		getTenants := &query.GetTenantsWithPendingDigest{}
+		if err := bus.Dispatch(c, getTenants); err != nil {
+			return c.Failure(err)
+		}
+
+		now := time.Now()
+		for _, tenant := range getTenants.Result {
+			if err := sendTenantDigests(c, tenant, now); err != nil {
+				return c.Failure(err)
+			}
+		}
 
 		return nil
 	})
 }
 
+func sendTenantDigests(c *worker.Context, tenant *models.Tenant, now time.Time) error {
+	ctx := context.WithValue(c, app.TenantCtxKey, tenant)
+
+	getItems := &query.GetPendingDigestItems{}
+	if err := bus.Dispatch(ctx, getItems); err != nil {
+		return err
+	}
+
+	itemIDs := make([]int, 0)
+	for _, item := range getItems.Result {
+		if !item.DueAt().After(now) {
+			itemIDs = append(itemIDs, item.ID)
+		}
+	}
+
+	if len(itemIDs) == 0 {
+		return nil
+	}
+
+	markAsSent := &cmd.MarkDigestItemsAsSent{ItemIDs: itemIDs}
+	if err := bus.Dispatch(ctx, markAsSent); err != nil {
+		return err
+	}
+
+	sent := make(map[int]bool, len(markAsSent.Result))
+	for _, id := range markAsSent.Result {
+		sent[id] = true
+	}
+
+	//Items are sorted by user, so each user receives a single email with all of their items
+	var (
+		baseURL string
+		to      []dto.Recipient
+		locales []string
+		items   [][]int
+		current *models.User
+		entries []dto.Props
+		ids     []int
+	)
+
+	flush := func() {
+		if current != nil && len(entries) > 0 {
+			locale := i18n.UserLocale(current, tenant)
+			to = append(to, dto.NewRecipient(current.Name, current.Email, dto.Props{
+				"items":  entries,
+				"change": linkWithText(i18n.T(locale, "change your notification settings"), baseURL, "/settings"),
+			}))
+			locales = append(locales, locale)
+			items = append(items, ids)
+		}
+		entries = nil
+		ids = nil
+	}
+
+	for _, item := range getItems.Result {
+		if !sent[item.ID] {
+			continue
+		}
+
+		if current == nil || current.ID != item.User.ID {
+			flush()
+			current = item.User
+		}
+
+		baseURL = item.BaseURL
+		entry := dto.Props{
+			"title": markdown.Simple(item.Title),
+		}
+		if item.Link != "" {
+			entry["view"] = linkWithText(i18n.T(i18n.UserLocale(current, tenant), "View it on your browser"), item.BaseURL, item.Link)
+		}
+		entries = append(entries, entry)
+		ids = append(ids, item.ID)
+	}
+	flush()
+
+	if len(to) == 0 {
+		return nil
+	}
+
+	//Digests are not started by a request, so the address of the site is taken from the items
+	if req, err := http.NewRequest("GET", baseURL, nil); err == nil {
+		request := web.WrapRequest(req)
+		request.URL = req.URL
+		request.IsSecure = req.URL.Scheme == "https"
+		ctx = context.WithValue(ctx, app.RequestCtxKey, request)
+	}
+
+	//Digests are sent one by one because the list of items can't be batched as recipient variables.
+	//Items of a digest that fails to be sent are marked as pending again, so that they are sent on next run
+	for i, recipient := range to {
+		err := sendDigest(i18n.WithLocale(ctx, locales[i]), &cmd.SendMail{
+			From:         tenant.Name,
+			To:           []dto.Recipient{recipient},
+			TemplateName: "digest",
+			Props: dto.Props{
+				"tenantName": tenant.Name,
+				"logo":       web.LogoURL(ctx),
+			},
+		})
+		if err != nil {
+			log.Error(ctx, err)
+			if err := bus.Dispatch(ctx, &cmd.MarkDigestItemsAsPending{ItemIDs: items[i]}); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+//sendDigest returns an error instead of panicking when the email can't be sent,
+//otherwise the whole task would be rolled back, including the digests that were already sent
+func sendDigest(ctx context.Context, msg *cmd.SendMail) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.Panicked(r)
+		}
+	}()
+	bus.Publish(ctx, msg)
+	return nil
+}
+
 //SendInvites sends one email to each invited recipient
 func SendInvites(subject, message string, invitations []*models.UserInvitation) worker.Task {
-	return describe("Send invites", func(c *worker.Context) error {
+	return describeOnce("Send invites", func(c *worker.Context) error {
 		to := make([]dto.Recipient, len(invitations))
 		for i, invite := range invitations {
 			err := bus.Dispatch(c, &cmd.SaveVerificationKey{
@@ -381,7 +614,7 @@ func SendInvites(subject, message string, invitations []*models.UserInvitation)
 		})
 
 		return nil
-	})
+	}, subject, message, invitations)
 }
 
 func getActiveSubscribers(ctx context.Context, post *models.Post, channel enum.NotificationChannel, event enum.NotificationEvent) ([]*models.User, error) {
@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/getfider/fider/app"
 	"github.com/getfider/fider/app/models/enum"
 	"github.com/getfider/fider/app/models/query"
 
@@ -15,6 +16,8 @@ import (
 	. "github.com/getfider/fider/app/pkg/assert"
 	"github.com/getfider/fider/app/pkg/bus"
 	"github.com/getfider/fider/app/pkg/mock"
+	"github.com/getfider/fider/app/pkg/web"
+	"github.com/getfider/fider/app/pkg/worker"
 	"github.com/getfider/fider/app/services/email/emailmock"
 	"github.com/getfider/fider/app/tasks"
 )
@@ -135,6 +138,11 @@ func TestNotifyAboutNewPostTask(t *testing.T) {
 		return nil
 	})
 
+	bus.AddHandler(func(ctx context.Context, c *cmd.AddToDigest) error {
+		c.Result = []*models.User{}
+		return nil
+	})
+
 	worker := mock.NewWorker()
 	post := &models.Post{
 		ID:          1,
@@ -180,6 +188,63 @@ func TestNotifyAboutNewPostTask(t *testing.T) {
 	Expect(addNewNotification.User).Equals(mock.AryaStark)
 }
 
+func TestNotifyAboutNewPostTask_Localized(t *testing.T) {
+	RegisterT(t)
+	bus.Init(emailmock.Service{})
+
+	addNewNotifications := make([]*cmd.AddNewNotification, 0)
+	bus.AddHandler(func(ctx context.Context, c *cmd.AddNewNotification) error {
+		addNewNotifications = append(addNewNotifications, c)
+		return nil
+	})
+
+	sansa := &models.User{ID: 10, Name: "Sansa Stark", Email: "sansa.stark@got.com", Locale: "fr"}
+	bus.AddHandler(func(ctx context.Context, q *query.GetActiveSubscribers) error {
+		q.Result = []*models.User{
+			mock.AryaStark,
+			sansa,
+		}
+		return nil
+	})
+
+	bus.AddHandler(func(ctx context.Context, c *cmd.AddToDigest) error {
+		c.Result = []*models.User{}
+		return nil
+	})
+
+	worker := mock.NewWorker()
+	post := &models.Post{
+		ID:          1,
+		Number:      1,
+		Title:       "Add support for TypeScript",
+		Slug:        "add-support-for-typescript",
+		Description: "TypeScript is great, please add support for it",
+	}
+	task := tasks.NotifyAboutNewPost(post)
+
+	err := worker.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		WithBaseURL("http://domain.com").
+		Execute(task)
+
+	Expect(err).IsNil()
+	Expect(emailmock.MessageHistory).HasLen(2)
+	Expect(emailmock.MessageHistory[0].Locale).Equals("en")
+	Expect(emailmock.MessageHistory[0].To).HasLen(1)
+	Expect(emailmock.MessageHistory[0].To[0].Address).Equals("arya.stark@got.com")
+	Expect(emailmock.MessageHistory[1].Locale).Equals("fr")
+	Expect(emailmock.MessageHistory[1].To).HasLen(1)
+	Expect(emailmock.MessageHistory[1].To[0].Address).Equals("sansa.stark@got.com")
+	Expect(emailmock.MessageHistory[1].Props["view"]).Equals(template.HTML("<a href='http://domain.com/posts/1/add-support-for-typescript'>Voir dans votre navigateur</a>"))
+
+	Expect(addNewNotifications).HasLen(2)
+	Expect(addNewNotifications[0].User).Equals(mock.AryaStark)
+	Expect(addNewNotifications[0].Title).Equals("New post: **Add support for TypeScript**")
+	Expect(addNewNotifications[1].User).Equals(sansa)
+	Expect(addNewNotifications[1].Title).Equals("Nouvelle suggestion : **Add support for TypeScript**")
+}
+
 func TestNotifyAboutNewCommentTask(t *testing.T) {
 	RegisterT(t)
 	bus.Init(emailmock.Service{})
@@ -197,6 +262,11 @@ func TestNotifyAboutNewCommentTask(t *testing.T) {
 		return nil
 	})
 
+	bus.AddHandler(func(ctx context.Context, c *cmd.AddToDigest) error {
+		c.Result = []*models.User{}
+		return nil
+	})
+
 	worker := mock.NewWorker()
 	post := &models.Post{
 		ID:          1,
@@ -265,6 +335,11 @@ func TestNotifyAboutStatusChangeTask(t *testing.T) {
 		return nil
 	})
 
+	bus.AddHandler(func(ctx context.Context, c *cmd.AddToDigest) error {
+		c.Result = []*models.User{}
+		return nil
+	})
+
 	worker := mock.NewWorker()
 	post := &models.Post{
 		ID:          1,
@@ -402,6 +477,11 @@ func TestNotifyAboutStatusChangeTask_Duplicate(t *testing.T) {
 		return nil
 	})
 
+	bus.AddHandler(func(ctx context.Context, c *cmd.AddToDigest) error {
+		c.Result = []*models.User{}
+		return nil
+	})
+
 	worker := mock.NewWorker()
 	post := &models.Post{
 		ID:     2,
@@ -511,3 +591,203 @@ func TestSendInvites(t *testing.T) {
 	Expect(savedKeys[1].Key).Equals("5678")
 	Expect(savedKeys[1].Request.GetEmail()).Equals("user2@domain.com")
 }
+
+func TestTasks_CanBePersisted(t *testing.T) {
+	RegisterT(t)
+
+	post := &models.Post{ID: 1, Number: 1, Title: "Add support for TypeScript", User: mock.AryaStark}
+	taskList := []worker.Task{
+		tasks.SendSignUpEmail(&models.CreateTenant{Name: "Jon Snow", Email: "jon.snow@got.com"}, "http://domain.com"),
+		tasks.SendSignInEmail(&models.SignInByEmail{Email: "jon.snow@got.com"}),
+		tasks.SendChangeEmailConfirmation(&models.ChangeUserEmail{Email: "jon.snow@got.com"}),
+		tasks.NotifyAboutNewPost(post),
+		tasks.NotifyAboutNewComment(post, &models.NewComment{Number: 1, Content: "I agree"}),
+		tasks.NotifyAboutStatusChange(post, enum.PostPlanned),
+		tasks.NotifyAboutDeletedPost(post),
+		tasks.SendInvites("My Subject", "Click here: %invite%", []*models.UserInvitation{
+			&models.UserInvitation{Email: "user1@domain.com", VerificationKey: "1234"},
+		}),
+		tasks.TriggerWebhooks(enum.WebhookEventPostCreated, web.Map{"post": post}),
+		tasks.SendDigests(),
+		tasks.ReceiveReply(&models.InboundEmail{From: "jon.snow@got.com", To: []string{"reply+1.1.1.abc@inbound.fider.io"}, Text: "I agree"}),
+	}
+
+	for _, task := range taskList {
+		ctx := context.WithValue(context.Background(), app.TenantCtxKey, mock.DemoTenant)
+		task.OriginContext = context.WithValue(ctx, app.UserCtxKey, mock.JonSnow)
+
+		payload, err := worker.Marshal(task)
+		Expect(err).IsNil()
+
+		rebuilt, err := worker.Unmarshal(payload)
+		Expect(err).IsNil()
+		Expect(rebuilt.Name).Equals(task.Name)
+		Expect(rebuilt.Args).Equals(task.Args)
+		Expect(rebuilt.OriginContext.Value(app.TenantCtxKey)).Equals(mock.DemoTenant)
+		Expect(rebuilt.OriginContext.Value(app.UserCtxKey)).Equals(mock.JonSnow)
+	}
+}
+
+func TestNotifyAboutNewCommentTask_Digest(t *testing.T) {
+	RegisterT(t)
+	bus.Init(emailmock.Service{})
+
+	bus.AddHandler(func(ctx context.Context, c *cmd.AddNewNotification) error {
+		return nil
+	})
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetActiveSubscribers) error {
+		q.Result = []*models.User{
+			mock.JonSnow,
+			mock.AryaStark,
+		}
+		return nil
+	})
+
+	var addToDigest *cmd.AddToDigest
+	bus.AddHandler(func(ctx context.Context, c *cmd.AddToDigest) error {
+		addToDigest = c
+		c.Result = []*models.User{mock.JonSnow}
+		return nil
+	})
+
+	worker := mock.NewWorker()
+	post := &models.Post{
+		ID:     1,
+		Number: 1,
+		Title:  "Add support for TypeScript",
+		Slug:   "add-support-for-typescript",
+	}
+	comment := &models.NewComment{
+		Number:  post.Number,
+		Content: "I agree",
+	}
+
+	err := worker.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.AryaStark).
+		WithBaseURL("http://domain.com").
+		Execute(tasks.NotifyAboutNewComment(post, comment))
+
+	Expect(err).IsNil()
+	Expect(addToDigest).IsNotNil()
+	Expect(addToDigest.Users).Equals([]*models.User{mock.JonSnow})
+	Expect(addToDigest.Event.UserSettingsKeyName).Equals(enum.NotificationEventNewComment.UserSettingsKeyName)
+	Expect(addToDigest.Title).Equals("**Arya Stark** left a comment on **Add support for TypeScript**")
+	Expect(addToDigest.Link).Equals("/posts/1/add-support-for-typescript")
+	Expect(addToDigest.BaseURL).Equals("http://domain.com")
+	Expect(addToDigest.PostID).Equals(post.ID)
+
+	Expect(emailmock.MessageHistory).HasLen(0)
+}
+
+func TestSendDigestsTask(t *testing.T) {
+	RegisterT(t)
+	bus.Init(emailmock.Service{})
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetTenantsWithPendingDigest) error {
+		q.Result = []*models.Tenant{mock.DemoTenant}
+		return nil
+	})
+
+	twoDaysAgo := time.Now().AddDate(0, 0, -2)
+	bus.AddHandler(func(ctx context.Context, q *query.GetPendingDigestItems) error {
+		Expect(ctx.Value(app.TenantCtxKey)).Equals(mock.DemoTenant)
+		q.Result = []*models.DigestItem{
+			{ID: 1, User: mock.JonSnow, Frequency: enum.NotificationDigestDaily, Title: "New post: **Add support for TypeScript**", Link: "/posts/1/add-support-for-typescript", BaseURL: "http://domain.com", CreatedAt: twoDaysAgo},
+			{ID: 2, User: mock.JonSnow, Frequency: enum.NotificationDigestWeekly, Title: "New post: **Dark mode**", Link: "/posts/2/dark-mode", BaseURL: "http://domain.com", CreatedAt: time.Now()},
+			{ID: 3, User: mock.AryaStark, Frequency: enum.NotificationDigestDaily, Timezone: "Europe/London", Title: "**Jon Snow** left a comment on **Add support for TypeScript**", Link: "/posts/1/add-support-for-typescript", BaseURL: "http://domain.com", CreatedAt: twoDaysAgo},
+		}
+		return nil
+	})
+
+	var markAsSent *cmd.MarkDigestItemsAsSent
+	bus.AddHandler(func(ctx context.Context, c *cmd.MarkDigestItemsAsSent) error {
+		markAsSent = c
+		c.Result = c.ItemIDs
+		return nil
+	})
+
+	err := mock.NewWorker().Execute(tasks.SendDigests())
+
+	Expect(err).IsNil()
+	Expect(markAsSent.ItemIDs).Equals([]int{1, 3})
+	Expect(emailmock.MessageHistory).HasLen(2)
+	for _, message := range emailmock.MessageHistory {
+		Expect(message.TemplateName).Equals("digest")
+		Expect(message.Tenant).Equals(mock.DemoTenant)
+		Expect(message.From).Equals(mock.DemoTenant.Name)
+		Expect(message.Props).Equals(dto.Props{
+			"tenantName": "Demonstration",
+			"logo":       "https://getfider.com/images/logo-100x100.png",
+		})
+		Expect(message.To).HasLen(1)
+	}
+	Expect(emailmock.MessageHistory[0].To[0]).Equals(dto.Recipient{
+		Name:    "Jon Snow",
+		Address: "jon.snow@got.com",
+		Props: dto.Props{
+			"items": []dto.Props{
+				{
+					"title": template.HTML("<p>New post: <strong>Add support for TypeScript</strong></p>"),
+					"view":  template.HTML("<a href='http://domain.com/posts/1/add-support-for-typescript'>View it on your browser</a>"),
+				},
+			},
+			"change": template.HTML("<a href='http://domain.com/settings'>change your notification settings</a>"),
+		},
+	})
+	Expect(emailmock.MessageHistory[1].To[0].Address).Equals("arya.stark@got.com")
+}
+
+func TestSendDigestsTask_SendFailure(t *testing.T) {
+	RegisterT(t)
+	bus.Init(emailmock.Service{})
+	bus.AddListener(func(ctx context.Context, c *cmd.SendMail) {
+		if c.To[0].Address == mock.JonSnow.Email {
+			panic("connection refused")
+		}
+	})
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetTenantsWithPendingDigest) error {
+		q.Result = []*models.Tenant{mock.DemoTenant}
+		return nil
+	})
+
+	twoDaysAgo := time.Now().AddDate(0, 0, -2)
+	bus.AddHandler(func(ctx context.Context, q *query.GetPendingDigestItems) error {
+		q.Result = []*models.DigestItem{
+			{ID: 1, User: mock.JonSnow, Frequency: enum.NotificationDigestDaily, Title: "New post: **Add support for TypeScript**", BaseURL: "http://domain.com", CreatedAt: twoDaysAgo},
+			{ID: 2, User: mock.JonSnow, Frequency: enum.NotificationDigestDaily, Title: "New post: **Dark mode**", BaseURL: "http://domain.com", CreatedAt: twoDaysAgo},
+			{ID: 3, User: mock.AryaStark, Frequency: enum.NotificationDigestDaily, Title: "New post: **Dark mode**", BaseURL: "http://domain.com", CreatedAt: twoDaysAgo},
+		}
+		return nil
+	})
+
+	bus.AddHandler(func(ctx context.Context, c *cmd.MarkDigestItemsAsSent) error {
+		c.Result = c.ItemIDs
+		return nil
+	})
+
+	var markAsPending *cmd.MarkDigestItemsAsPending
+	bus.AddHandler(func(ctx context.Context, c *cmd.MarkDigestItemsAsPending) error {
+		markAsPending = c
+		return nil
+	})
+
+	err := mock.NewWorker().Execute(tasks.SendDigests())
+
+	Expect(err).IsNil()
+	Expect(markAsPending.ItemIDs).Equals([]int{1, 2})
+	Expect(emailmock.MessageHistory).HasLen(2)
+	Expect(emailmock.MessageHistory[1].To[0].Address).Equals("arya.stark@got.com")
+}
+
+func TestNotificationTasks_RunOnlyOnce(t *testing.T) {
+	RegisterT(t)
+
+	post := &models.Post{ID: 1, Number: 1, Title: "Add support for TypeScript"}
+	Expect(tasks.NotifyAboutNewPost(post).MaxAttempts).Equals(1)
+	Expect(tasks.NotifyAboutNewComment(post, &models.NewComment{Number: 1}).MaxAttempts).Equals(1)
+	Expect(tasks.NotifyAboutDeletedPost(post).MaxAttempts).Equals(1)
+	Expect(tasks.SendDigests().MaxAttempts).Equals(0)
+}
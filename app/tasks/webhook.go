@@ -0,0 +1,161 @@
+package tasks
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/dto"
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/models/query"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/crypto"
+	"github.com/getfider/fider/app/pkg/env"
+	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/getfider/fider/app/pkg/log"
+	"github.com/getfider/fider/app/pkg/rand"
+	"github.com/getfider/fider/app/pkg/web"
+	"github.com/getfider/fider/app/pkg/worker"
+)
+
+//TriggerWebhooks delivers a signed event payload to every active webhook subscribed to given event
+func TriggerWebhooks(event enum.WebhookEvent, data web.Map) worker.Task {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		err = errors.Wrap(err, "failed to marshal webhook data for '%s'", event)
+		return describe("Trigger webhooks", func(c *worker.Context) error {
+			return c.Failure(err)
+		})
+	}
+	return triggerWebhooks(event, encoded)
+}
+
+func triggerWebhooks(event enum.WebhookEvent, data json.RawMessage) worker.Task {
+	return describe("Trigger webhooks", func(c *worker.Context) error {
+		listWebhooks := &query.ListActiveWebhooksByEvent{Event: event}
+		if err := bus.Dispatch(c, listWebhooks); err != nil {
+			return c.Failure(err)
+		}
+
+		if len(listWebhooks.Result) == 0 {
+			return nil
+		}
+
+		payload, err := json.Marshal(webhookPayload(c, event, data))
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		for _, webhook := range listWebhooks.Result {
+			deliverWebhook(c, webhook, event, payload, rand.String(32), 1)
+		}
+
+		return nil
+	}, event, data)
+}
+
+func webhookPayload(c *worker.Context, event enum.WebhookEvent, data json.RawMessage) web.Map {
+	payload := web.Map{
+		"event":     event,
+		"createdAt": time.Now().UTC(),
+		"data":      data,
+	}
+
+	if tenant := c.Tenant(); tenant != nil {
+		payload["tenant"] = web.Map{
+			"id":        tenant.ID,
+			"name":      tenant.Name,
+			"subdomain": tenant.Subdomain,
+			"url":       web.BaseURL(c),
+		}
+	}
+
+	if user := c.User(); user != nil {
+		payload["actor"] = web.Map{
+			"id":   user.ID,
+			"name": user.Name,
+			"role": user.Role,
+		}
+	}
+
+	return payload
+}
+
+//DeliverWebhook retries the delivery of a payload to given webhook, which is skipped if the webhook has been deleted or disabled since
+func DeliverWebhook(webhookID int, event enum.WebhookEvent, payload json.RawMessage, deliveryID string, attempt int) worker.Task {
+	return describe("Deliver webhook", func(c *worker.Context) error {
+		getWebhook := &query.GetWebhookByID{WebhookID: webhookID}
+		if err := bus.Dispatch(c, getWebhook); err != nil {
+			if errors.Cause(err) == app.ErrNotFound {
+				return nil
+			}
+			return c.Failure(err)
+		}
+
+		if !getWebhook.Result.IsActive {
+			return nil
+		}
+
+		deliverWebhook(c, getWebhook.Result, event, payload, deliveryID, attempt)
+		return nil
+	}, webhookID, event, payload, deliveryID, attempt)
+}
+
+//deliverWebhook sends the payload to given webhook once, enqueuing the next attempt with an exponential backoff when it fails.
+//It never fails itself, otherwise durable workers would also retry the task and the same delivery would be sent twice
+func deliverWebhook(c *worker.Context, webhook *models.Webhook, event enum.WebhookEvent, payload []byte, deliveryID string, attempt int) {
+	req := &cmd.HTTPRequest{
+		URL:    webhook.URL,
+		Body:   bytes.NewReader(payload),
+		Method: "POST",
+		Headers: map[string]string{
+			"Content-Type":      "application/json",
+			"User-Agent":        "Fider-Webhook",
+			"X-Fider-Event":     string(event),
+			"X-Fider-Delivery":  deliveryID,
+			"X-Fider-Signature": "sha256=" + crypto.HMACSHA256(webhook.Secret, payload),
+		},
+	}
+
+	start := time.Now()
+	err := bus.Dispatch(c, req)
+
+	delivery := &cmd.LogWebhookDelivery{
+		Webhook:    webhook,
+		Event:      event,
+		Attempt:    attempt,
+		StatusCode: req.ResponseStatusCode,
+		Payload:    string(payload),
+		DurationMs: int(time.Since(start) / time.Millisecond),
+	}
+
+	if err != nil {
+		delivery.Error = err.Error()
+	} else if req.ResponseStatusCode < 200 || req.ResponseStatusCode >= 300 {
+		delivery.Error = fmt.Sprintf("unexpected status code %d", req.ResponseStatusCode)
+	} else {
+		delivery.Success = true
+	}
+
+	if err := bus.Dispatch(c, delivery); err != nil {
+		log.Error(c, err)
+	}
+
+	if !delivery.Success {
+		if attempt < env.Config.Webhooks.MaxAttempts {
+			retry := DeliverWebhook(webhook.ID, event, payload, deliveryID, attempt+1)
+			retry.RunAt = time.Now().Add(env.Config.Webhooks.RetryDelay * time.Duration(1<<uint(attempt-1)))
+			c.Enqueue(retry)
+		} else {
+			log.Warnf(c, "Webhook @{WebhookID} failed to deliver @{Event} after @{Attempts} attempts", dto.Props{
+				"WebhookID": webhook.ID,
+				"Event":     event,
+				"Attempts":  attempt,
+			})
+		}
+	}
+}
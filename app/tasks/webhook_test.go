@@ -0,0 +1,220 @@
+package tasks_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"testing"
+
+	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/models/cmd"
+	"github.com/getfider/fider/app/models/enum"
+	"github.com/getfider/fider/app/models/query"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/bus"
+	"github.com/getfider/fider/app/pkg/crypto"
+	"github.com/getfider/fider/app/pkg/env"
+	"github.com/getfider/fider/app/pkg/mock"
+	"github.com/getfider/fider/app/pkg/web"
+	"github.com/getfider/fider/app/services/httpclient/httpclientmock"
+	"github.com/getfider/fider/app/tasks"
+)
+
+func TestTriggerWebhooksTask(t *testing.T) {
+	RegisterT(t)
+	bus.Init(httpclientmock.Service{})
+
+	webhook := &models.Webhook{ID: 1, URL: "https://hooks.example.com/fider", Secret: "s3cr3t", IsActive: true}
+	bus.AddHandler(func(ctx context.Context, q *query.ListActiveWebhooksByEvent) error {
+		Expect(q.Event).Equals(enum.WebhookEventPostCreated)
+		q.Result = []*models.Webhook{webhook}
+		return nil
+	})
+
+	deliveries := make([]*cmd.LogWebhookDelivery, 0)
+	bus.AddHandler(func(ctx context.Context, c *cmd.LogWebhookDelivery) error {
+		deliveries = append(deliveries, c)
+		return nil
+	})
+
+	post := &models.Post{ID: 1, Number: 1, Title: "Add support for TypeScript", Slug: "add-support-for-typescript"}
+	task := tasks.TriggerWebhooks(enum.WebhookEventPostCreated, web.Map{"post": post})
+
+	err := mock.NewWorker().
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		WithBaseURL("http://domain.com").
+		Execute(task)
+
+	Expect(err).IsNil()
+	Expect(httpclientmock.RequestsHistory).HasLen(1)
+
+	req := httpclientmock.RequestsHistory[0]
+	body, _ := ioutil.ReadAll(req.Body)
+	Expect(req.URL.String()).Equals("https://hooks.example.com/fider")
+	Expect(req.Method).Equals("POST")
+	Expect(req.Header.Get("Content-Type")).Equals("application/json")
+	Expect(req.Header.Get("X-Fider-Event")).Equals("post.created")
+	Expect(req.Header.Get("X-Fider-Signature")).Equals("sha256=" + crypto.HMACSHA256("s3cr3t", body))
+
+	payload := make(map[string]interface{})
+	_ = json.Unmarshal(body, &payload)
+	Expect(payload["event"]).Equals("post.created")
+	Expect(payload["tenant"].(map[string]interface{})["subdomain"]).Equals(mock.DemoTenant.Subdomain)
+	Expect(payload["actor"].(map[string]interface{})["name"]).Equals("Jon Snow")
+	Expect(payload["data"].(map[string]interface{})["post"].(map[string]interface{})["title"]).Equals("Add support for TypeScript")
+
+	Expect(deliveries).HasLen(1)
+	Expect(deliveries[0].Webhook).Equals(webhook)
+	Expect(deliveries[0].Attempt).Equals(1)
+	Expect(deliveries[0].Success).IsTrue()
+	Expect(deliveries[0].StatusCode).Equals(200)
+}
+
+func TestTriggerWebhooksTask_RetryOnFailure(t *testing.T) {
+	RegisterT(t)
+
+	webhook := &models.Webhook{ID: 1, URL: "https://hooks.example.com/fider", Secret: "s3cr3t", IsActive: true}
+	bus.AddHandler(func(ctx context.Context, q *query.ListActiveWebhooksByEvent) error {
+		q.Result = []*models.Webhook{webhook}
+		return nil
+	})
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetWebhookByID) error {
+		Expect(q.WebhookID).Equals(webhook.ID)
+		q.Result = webhook
+		return nil
+	})
+
+	requests := 0
+	bus.AddHandler(func(ctx context.Context, c *cmd.HTTPRequest) error {
+		requests++
+		if requests == 1 {
+			return errors.New("connection refused")
+		}
+		c.ResponseStatusCode = 500
+		return nil
+	})
+
+	deliveries := make([]*cmd.LogWebhookDelivery, 0)
+	bus.AddHandler(func(ctx context.Context, c *cmd.LogWebhookDelivery) error {
+		deliveries = append(deliveries, c)
+		return nil
+	})
+
+	task := tasks.TriggerWebhooks(enum.WebhookEventVoteAdded, web.Map{})
+	err := mock.NewWorker().
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		Execute(task)
+
+	Expect(err).IsNil()
+	Expect(requests).Equals(env.Config.Webhooks.MaxAttempts)
+	Expect(deliveries).HasLen(env.Config.Webhooks.MaxAttempts)
+	Expect(deliveries[0].Error).Equals("connection refused")
+	Expect(deliveries[1].Error).Equals("unexpected status code 500")
+	for i, delivery := range deliveries {
+		Expect(delivery.Attempt).Equals(i + 1)
+		Expect(delivery.Success).IsFalse()
+	}
+}
+
+func TestTriggerWebhooksTask_NoWebhooks(t *testing.T) {
+	RegisterT(t)
+	bus.Init(httpclientmock.Service{})
+
+	bus.AddHandler(func(ctx context.Context, q *query.ListActiveWebhooksByEvent) error {
+		q.Result = []*models.Webhook{}
+		return nil
+	})
+
+	task := tasks.TriggerWebhooks(enum.WebhookEventCommentDeleted, web.Map{})
+	err := mock.NewWorker().
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		Execute(task)
+
+	Expect(err).IsNil()
+	Expect(httpclientmock.RequestsHistory).HasLen(0)
+}
+
+func TestTriggerWebhooksTask_ContinueWhenLogFails(t *testing.T) {
+	RegisterT(t)
+	bus.Init(httpclientmock.Service{})
+
+	bus.AddHandler(func(ctx context.Context, q *query.ListActiveWebhooksByEvent) error {
+		q.Result = []*models.Webhook{
+			{ID: 1, URL: "https://hooks.example.com/first", Secret: "s3cr3t", IsActive: true},
+			{ID: 2, URL: "https://hooks.example.com/second", Secret: "s3cr3t", IsActive: true},
+		}
+		return nil
+	})
+
+	bus.AddHandler(func(ctx context.Context, c *cmd.LogWebhookDelivery) error {
+		return errors.New("database is down")
+	})
+
+	task := tasks.TriggerWebhooks(enum.WebhookEventPostCreated, web.Map{})
+	err := mock.NewWorker().
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		Execute(task)
+
+	Expect(err).IsNil()
+	Expect(httpclientmock.RequestsHistory).HasLen(2)
+	Expect(httpclientmock.RequestsHistory[1].URL.String()).Equals("https://hooks.example.com/second")
+}
+
+func TestDeliverWebhookTask_InactiveWebhook(t *testing.T) {
+	RegisterT(t)
+	bus.Init(httpclientmock.Service{})
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetWebhookByID) error {
+		q.Result = &models.Webhook{ID: q.WebhookID, URL: "https://hooks.example.com/fider", IsActive: false}
+		return nil
+	})
+
+	task := tasks.DeliverWebhook(1, enum.WebhookEventPostCreated, []byte("{}"), "abc", 2)
+	err := mock.NewWorker().
+		OnTenant(mock.DemoTenant).
+		Execute(task)
+
+	Expect(err).IsNil()
+	Expect(httpclientmock.RequestsHistory).HasLen(0)
+}
+
+func TestDeliverWebhookTask_LogFails(t *testing.T) {
+	RegisterT(t)
+	bus.Init(httpclientmock.Service{})
+
+	bus.AddHandler(func(ctx context.Context, q *query.GetWebhookByID) error {
+		q.Result = &models.Webhook{ID: q.WebhookID, URL: "https://hooks.example.com/fider", Secret: "s3cr3t", IsActive: true}
+		return nil
+	})
+
+	bus.AddHandler(func(ctx context.Context, c *cmd.LogWebhookDelivery) error {
+		return errors.New("database is down")
+	})
+
+	task := tasks.DeliverWebhook(1, enum.WebhookEventPostCreated, []byte("{}"), "abc", 2)
+	err := mock.NewWorker().
+		OnTenant(mock.DemoTenant).
+		Execute(task)
+
+	Expect(err).IsNil()
+	Expect(httpclientmock.RequestsHistory).HasLen(1)
+}
+
+func TestTriggerWebhooksTask_InvalidData(t *testing.T) {
+	RegisterT(t)
+	bus.Init(httpclientmock.Service{})
+
+	task := tasks.TriggerWebhooks(enum.WebhookEventPostCreated, web.Map{"post": make(chan int)})
+	err := mock.NewWorker().
+		OnTenant(mock.DemoTenant).
+		Execute(task)
+
+	Expect(err).IsNotNil()
+	Expect(httpclientmock.RequestsHistory).HasLen(0)
+}
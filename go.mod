@@ -6,6 +6,7 @@ require (
 	github.com/aws/aws-sdk-go v1.25.25
 	github.com/cosmtrek/air v1.0.2
 	github.com/dgrijalva/jwt-go v3.2.0+incompatible
+	github.com/disintegration/imaging v1.6.0
 	github.com/goenning/imagic v0.0.1
 	github.com/goenning/letteravatar v0.0.0-20180605200324-553181ed4055
 	github.com/goenning/vat v0.1.0
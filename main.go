@@ -41,6 +41,10 @@ func main() {
 		os.Exit(cmd.RunPing())
 	} else if len(args) > 0 && args[0] == "migrate" {
 		os.Exit(cmd.RunMigrate())
+	} else if len(args) > 0 && args[0] == "import" {
+		os.Exit(cmd.RunImport(args[1:]))
+	} else if len(args) > 0 && args[0] == "blobs" {
+		os.Exit(cmd.RunBlobs(args[1:]))
 	} else {
 		os.Exit(cmd.RunServer(settings))
 	}
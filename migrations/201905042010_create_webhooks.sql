@@ -0,0 +1,31 @@
+CREATE TABLE IF NOT EXISTS webhooks (
+  id         SERIAL NOT NULL,
+  tenant_id  INT NOT NULL,
+  name       VARCHAR(60) NOT NULL,
+  url        VARCHAR(300) NOT NULL,
+  secret     VARCHAR(64) NOT NULL,
+  events     VARCHAR(30)[] NOT NULL,
+  is_active  BOOLEAN NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (id),
+  FOREIGN KEY (tenant_id) REFERENCES tenants(id)
+);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+  id            SERIAL NOT NULL,
+  tenant_id     INT NOT NULL,
+  webhook_id    INT NOT NULL,
+  event         VARCHAR(30) NOT NULL,
+  attempt       INT NOT NULL,
+  status_code   INT NOT NULL,
+  success       BOOLEAN NOT NULL,
+  error         TEXT NULL,
+  payload       TEXT NOT NULL,
+  duration_ms   INT NOT NULL,
+  delivered_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (id),
+  FOREIGN KEY (tenant_id) REFERENCES tenants(id),
+  FOREIGN KEY (webhook_id) REFERENCES webhooks(id) ON DELETE CASCADE
+);
+
+CREATE INDEX webhook_deliveries_webhook_id_idx ON webhook_deliveries (tenant_id, webhook_id, delivered_at);
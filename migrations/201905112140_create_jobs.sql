@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS jobs (
+  id           BIGSERIAL NOT NULL,
+  name         VARCHAR(100) NOT NULL,
+  args         BYTEA NOT NULL,
+  origin       BYTEA NOT NULL,
+  status       SMALLINT NOT NULL,
+  attempts     INT NOT NULL,
+  max_attempts INT NOT NULL,
+  run_at       TIMESTAMPTZ NOT NULL,
+  locked_by    VARCHAR(100) NULL,
+  locked_until TIMESTAMPTZ NULL,
+  last_error   TEXT NULL,
+  created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (id)
+);
+
+CREATE INDEX jobs_status_run_at_idx ON jobs (status, run_at);
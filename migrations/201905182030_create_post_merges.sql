@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS post_merges (
+  id                    SERIAL NOT NULL,
+  tenant_id             INT NOT NULL,
+  post_id               INT NOT NULL,
+  original_id           INT NOT NULL,
+  prev_status           INT NOT NULL,
+  prev_response         TEXT NULL,
+  prev_response_date    TIMESTAMPTZ NULL,
+  prev_response_user_id INT NULL,
+  prev_original_id      INT NULL,
+  merged_by_id          INT NOT NULL,
+  merged_at             TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  unmerged_by_id        INT NULL,
+  unmerged_at           TIMESTAMPTZ NULL,
+  PRIMARY KEY (id),
+  FOREIGN KEY (tenant_id) REFERENCES tenants(id),
+  FOREIGN KEY (post_id, tenant_id) REFERENCES posts(id, tenant_id),
+  FOREIGN KEY (original_id, tenant_id) REFERENCES posts(id, tenant_id),
+  FOREIGN KEY (merged_by_id, tenant_id) REFERENCES users(id, tenant_id),
+  FOREIGN KEY (unmerged_by_id, tenant_id) REFERENCES users(id, tenant_id)
+);
+
+CREATE UNIQUE INDEX post_merges_active_idx ON post_merges (tenant_id, post_id) WHERE unmerged_at IS NULL;
+
+ALTER TABLE post_votes ADD merged_from_id INT NULL;
+ALTER TABLE comments ADD merged_from_id INT NULL;
+ALTER TABLE post_subscribers ADD merged_from_id INT NULL;
@@ -0,0 +1,4 @@
+ALTER TABLE tenants ADD voting_mode SMALLINT NOT NULL DEFAULT 1;
+ALTER TABLE tenants ADD vote_budget INT NOT NULL DEFAULT 10;
+
+ALTER TABLE post_votes ADD credits SMALLINT NOT NULL DEFAULT 1;
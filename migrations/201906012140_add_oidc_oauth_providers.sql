@@ -0,0 +1,2 @@
+ALTER TABLE oauth_providers ADD protocol SMALLINT NOT NULL DEFAULT 1;
+ALTER TABLE oauth_providers ADD issuer_url VARCHAR(300) NOT NULL DEFAULT '';
@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS saml_configs (
+  tenant_id           INT NOT NULL,
+  is_enabled          BOOLEAN NOT NULL,
+  display_name        VARCHAR(50) NOT NULL,
+  idp_metadata        TEXT NOT NULL,
+  idp_entity_id       VARCHAR(300) NOT NULL,
+  idp_sso_url         VARCHAR(300) NOT NULL,
+  name_attribute      VARCHAR(100) NOT NULL,
+  email_attribute     VARCHAR(100) NOT NULL,
+  role_attribute      VARCHAR(100) NOT NULL,
+  administrator_roles VARCHAR(300) NOT NULL,
+  collaborator_roles  VARCHAR(300) NOT NULL,
+  updated_at          TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (tenant_id),
+  FOREIGN KEY (tenant_id) REFERENCES tenants(id)
+);
+
+CREATE TABLE IF NOT EXISTS saml_assertions (
+  tenant_id    INT NOT NULL,
+  assertion_id VARCHAR(200) NOT NULL,
+  expires_at   TIMESTAMPTZ NOT NULL,
+  PRIMARY KEY (tenant_id, assertion_id),
+  FOREIGN KEY (tenant_id) REFERENCES tenants(id)
+);
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS api_tokens (
+  id            SERIAL NOT NULL,
+  tenant_id     INT NOT NULL,
+  user_id       INT NOT NULL,
+  name          VARCHAR(60) NOT NULL,
+  key_hash      VARCHAR(64) NOT NULL,
+  scopes        VARCHAR(30)[] NOT NULL,
+  expires_at    TIMESTAMPTZ NULL,
+  last_used_at  TIMESTAMPTZ NULL,
+  revoked_at    TIMESTAMPTZ NULL,
+  created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (id),
+  FOREIGN KEY (tenant_id) REFERENCES tenants(id),
+  FOREIGN KEY (user_id, tenant_id) REFERENCES users(id, tenant_id)
+);
+
+CREATE UNIQUE INDEX api_tokens_key_hash_idx ON api_tokens (key_hash);
+CREATE INDEX api_tokens_user_id_idx ON api_tokens (tenant_id, user_id);
@@ -0,0 +1,4 @@
+ALTER TABLE posts ADD updated_at TIMESTAMPTZ NULL;
+
+CREATE INDEX posts_tenant_created_at_idx ON posts (tenant_id, created_at);
+CREATE INDEX posts_tenant_updated_at_idx ON posts (tenant_id, (COALESCE(updated_at, created_at)));
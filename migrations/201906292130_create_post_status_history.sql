@@ -0,0 +1,23 @@
+ALTER TABLE tenants ADD is_roadmap_public BOOLEAN NOT NULL DEFAULT true;
+
+CREATE TABLE IF NOT EXISTS post_status_history (
+  id            SERIAL NOT NULL,
+  tenant_id     INT NOT NULL,
+  post_id       INT NOT NULL,
+  status        INT NOT NULL,
+  changed_by_id INT NULL,
+  changed_at    TIMESTAMPTZ NOT NULL,
+  PRIMARY KEY (id),
+  FOREIGN KEY (tenant_id) REFERENCES tenants(id),
+  FOREIGN KEY (post_id, tenant_id) REFERENCES posts(id, tenant_id),
+  FOREIGN KEY (changed_by_id, tenant_id) REFERENCES users(id, tenant_id)
+);
+
+CREATE INDEX post_status_history_post_id_idx ON post_status_history (tenant_id, post_id);
+
+INSERT INTO post_status_history (tenant_id, post_id, status, changed_by_id, changed_at)
+SELECT tenant_id, id, 0, user_id, created_at FROM posts;
+
+INSERT INTO post_status_history (tenant_id, post_id, status, changed_by_id, changed_at)
+SELECT tenant_id, id, status, response_user_id, response_date FROM posts 
+WHERE status <> 0 AND response_date IS NOT NULL;
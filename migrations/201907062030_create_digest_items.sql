@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS digest_items (
+  id          SERIAL NOT NULL,
+  tenant_id   INT NOT NULL,
+  user_id     INT NOT NULL,
+  post_id     INT NULL,
+  event       VARCHAR(50) NOT NULL,
+  frequency   VARCHAR(20) NOT NULL,
+  title       VARCHAR(400) NOT NULL,
+  link        VARCHAR(2048) NULL,
+  base_url    VARCHAR(500) NOT NULL,
+  created_at  TIMESTAMPTZ NOT NULL,
+  sent_at     TIMESTAMPTZ NULL,
+  PRIMARY KEY (id),
+  FOREIGN KEY (tenant_id) REFERENCES tenants(id),
+  FOREIGN KEY (user_id, tenant_id) REFERENCES users(id, tenant_id),
+  FOREIGN KEY (post_id, tenant_id) REFERENCES posts(id, tenant_id)
+);
+
+CREATE INDEX digest_items_pending_idx ON digest_items (tenant_id, user_id) WHERE sent_at IS NULL;
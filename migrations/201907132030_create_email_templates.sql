@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS email_templates (
+  tenant_id  INT NOT NULL,
+  name       VARCHAR(50) NOT NULL,
+  subject    VARCHAR(200) NOT NULL,
+  body       TEXT NOT NULL,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (tenant_id, name),
+  FOREIGN KEY (tenant_id) REFERENCES tenants(id)
+);
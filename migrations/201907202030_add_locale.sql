@@ -0,0 +1,2 @@
+ALTER TABLE tenants ADD locale VARCHAR(10) NOT NULL DEFAULT 'en';
+ALTER TABLE users ADD locale VARCHAR(10) NOT NULL DEFAULT '';
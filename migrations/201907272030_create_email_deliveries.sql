@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS email_deliveries (
+  id            SERIAL PRIMARY KEY,
+  tenant_id     INT NULL,
+  message_id    VARCHAR(200) NOT NULL,
+  provider      VARCHAR(20) NOT NULL,
+  template_name VARCHAR(50) NOT NULL,
+  address       VARCHAR(200) NOT NULL,
+  status        VARCHAR(20) NOT NULL,
+  reason        TEXT NULL,
+  created_at    TIMESTAMPTZ NOT NULL,
+  updated_at    TIMESTAMPTZ NOT NULL,
+  FOREIGN KEY (tenant_id) REFERENCES tenants(id)
+);
+
+CREATE INDEX email_deliveries_message_id_idx ON email_deliveries (message_id, address);
+CREATE INDEX email_deliveries_address_idx ON email_deliveries (address, status);
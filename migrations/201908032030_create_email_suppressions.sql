@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS email_suppressions (
+  tenant_id  INT NOT NULL,
+  address    VARCHAR(200) NOT NULL,
+  reason     VARCHAR(20) NOT NULL,
+  detail     TEXT NULL,
+  created_at TIMESTAMPTZ NOT NULL,
+  PRIMARY KEY (tenant_id, address),
+  FOREIGN KEY (tenant_id) REFERENCES tenants(id)
+);
+
+INSERT INTO email_suppressions (tenant_id, address, reason, detail, created_at)
+SELECT DISTINCT ON (tenant_id, address) tenant_id, address, status, reason, updated_at
+FROM email_deliveries
+WHERE tenant_id IS NOT NULL AND status IN ('bounced', 'complained')
+ORDER BY tenant_id, address, updated_at DESC;
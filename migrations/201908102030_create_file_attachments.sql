@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS file_attachments (
+  id           SERIAL PRIMARY KEY,
+  tenant_id    INT NOT NULL,
+  post_id      INT NOT NULL,
+  comment_id   INT NULL,
+  user_id      INT NOT NULL,
+  file_bkey    VARCHAR(512) NOT NULL,
+  file_name    VARCHAR(200) NOT NULL,
+  content_type VARCHAR(200) NOT NULL,
+  size         INT NOT NULL,
+  created_at   TIMESTAMPTZ NOT NULL,
+  FOREIGN KEY (tenant_id) REFERENCES tenants(id),
+  FOREIGN KEY (post_id) REFERENCES posts(id),
+  FOREIGN KEY (comment_id) REFERENCES comments(id),
+  FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+CREATE INDEX file_attachments_post_id_idx ON file_attachments (tenant_id, post_id);
+CREATE UNIQUE INDEX file_attachments_file_bkey_idx ON file_attachments (tenant_id, file_bkey);
+
+ALTER TABLE tenants ADD allowed_file_types TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS audit_logs (
+  id          SERIAL PRIMARY KEY,
+  tenant_id   INT NOT NULL,
+  actor_id    INT NULL,
+  action      VARCHAR(50) NOT NULL,
+  target_type VARCHAR(50) NOT NULL,
+  target_id   INT NULL,
+  target_name VARCHAR(200) NOT NULL,
+  before      JSONB NULL,
+  after       JSONB NULL,
+  client_ip   VARCHAR(50) NULL,
+  created_at  TIMESTAMPTZ NOT NULL,
+  FOREIGN KEY (tenant_id) REFERENCES tenants(id),
+  FOREIGN KEY (actor_id, tenant_id) REFERENCES users(id, tenant_id)
+);
+
+CREATE INDEX audit_logs_tenant_id_created_at_idx ON audit_logs (tenant_id, created_at);
@@ -0,0 +1,6 @@
+ALTER TABLE events ADD user_id INT NULL;
+ALTER TABLE events ADD CONSTRAINT events_user_id_fkey FOREIGN KEY (user_id, tenant_id) REFERENCES users(id, tenant_id);
+
+CREATE INDEX events_tenant_created_at_idx ON events (tenant_id, created_at);
+CREATE INDEX post_votes_tenant_created_at_idx ON post_votes (tenant_id, created_at);
+CREATE INDEX comments_tenant_created_at_idx ON comments (tenant_id, created_at);
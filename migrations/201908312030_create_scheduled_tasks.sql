@@ -0,0 +1,5 @@
+CREATE TABLE IF NOT EXISTS scheduled_tasks (
+  name        VARCHAR(100) NOT NULL,
+  last_run_at TIMESTAMPTZ NOT NULL,
+  PRIMARY KEY (name)
+);
@@ -58,6 +58,13 @@ export const AsyncPrivacySettingsPage = load(() =>
   )
 );
 
+export const AsyncVotingSettingsPage = load(() =>
+  import(
+    /* webpackChunkName: "VotingSettings.page" */
+    "@fider/pages/Administration/pages/VotingSettings.page"
+  )
+);
+
 export const AsyncExportPage = load(() =>
   import(
     /* webpackChunkName: "Export.page" */
@@ -65,6 +72,41 @@ export const AsyncExportPage = load(() =>
   )
 );
 
+export const AsyncImportPage = load(() =>
+  import(
+    /* webpackChunkName: "Import.page" */
+    "@fider/pages/Administration/pages/Import.page"
+  )
+);
+
+export const AsyncAuditLogPage = load(() =>
+  import(
+    /* webpackChunkName: "AuditLog.page" */
+    "@fider/pages/Administration/pages/AuditLog.page"
+  )
+);
+
+export const AsyncAnalyticsPage = load(() =>
+  import(
+    /* webpackChunkName: "Analytics.page" */
+    "@fider/pages/Administration/pages/Analytics.page"
+  )
+);
+
+export const AsyncSAMLSettingsPage = load(() =>
+  import(
+    /* webpackChunkName: "SAMLSettings.page" */
+    "@fider/pages/Administration/pages/SAMLSettings.page"
+  )
+);
+
+export const AsyncEmailTemplatesPage = load(() =>
+  import(
+    /* webpackChunkName: "EmailTemplates.page" */
+    "@fider/pages/Administration/pages/EmailTemplates.page"
+  )
+);
+
 export const AsyncInvitationsPage = load(() =>
   import(
     /* webpackChunkName: "Invitations.page" */
@@ -121,6 +163,13 @@ export const AsyncMyNotificationsPage = load(() =>
   )
 );
 
+export const AsyncRoadmapPage = load(() =>
+  import(
+    /* webpackChunkName: "Roadmap.page" */
+    "@fider/pages/Roadmap/Roadmap.page"
+  )
+);
+
 export const AsyncMySettingsPage = load(() =>
   import(
     /* webpackChunkName: "MySettings.page" */
@@ -2,19 +2,24 @@ import "./VoteCounter.scss";
 
 import React, { useState } from "react";
 import { Post, PostStatus } from "@fider/models";
-import { actions, device, classSet } from "@fider/services";
+import { actions, device, classSet, notify } from "@fider/services";
 import { SignInModal } from "@fider/components";
 import { FaCaretUp } from "react-icons/fa";
 import { useFider } from "@fider/hooks";
 
+const maxVoteCredits = 3;
+
 interface VoteCounterProps {
   post: Post;
 }
 
 export const VoteCounter = (props: VoteCounterProps) => {
   const fider = useFider();
+  const isBudgeted = fider.session.tenant.votingMode === "budgeted";
   const [hasVoted, setHasVoted] = useState(props.post.hasVoted);
   const [votesCount, setVotesCount] = useState(props.post.votesCount);
+  const [credits, setCredits] = useState(props.post.votedCredits);
+  const [score, setScore] = useState(props.post.score);
   const [isSignInModalOpen, setIsSignInModalOpen] = useState(false);
 
   const voteOrUndo = async () => {
@@ -32,6 +37,31 @@ export const VoteCounter = (props: VoteCounterProps) => {
     }
   };
 
+  // On budgeted voting, each click places one more credit on the post, until it cycles back to no vote
+  const placeCredit = async () => {
+    if (!fider.session.isAuthenticated) {
+      setIsSignInModalOpen(true);
+      return;
+    }
+
+    const newCredits = hasVoted ? (credits + 1) % (maxVoteCredits + 1) : 1;
+    const response =
+      newCredits === 0 ? await actions.removeVote(props.post.number) : await actions.addVote(props.post.number, newCredits);
+
+    if (response.ok) {
+      setScore(score - (hasVoted ? credits : 0) + newCredits);
+      if (!hasVoted) {
+        setVotesCount(votesCount + 1);
+      } else if (newCredits === 0) {
+        setVotesCount(votesCount - 1);
+      }
+      setHasVoted(newCredits > 0);
+      setCredits(newCredits);
+    } else if (response.error && response.error.errors) {
+      notify.error(response.error.errors.map(e => e.message).join(" "));
+    }
+  };
+
   const hideModal = () => setIsSignInModalOpen(false);
 
   const status = PostStatus.Get(props.post.status);
@@ -42,24 +72,32 @@ export const VoteCounter = (props: VoteCounterProps) => {
     "no-touch": !device.isTouch()
   });
 
+  const count = isBudgeted ? score : votesCount;
+  const title = isBudgeted
+    ? `${votesCount} voters · You placed ${hasVoted ? credits : 0} of ${maxVoteCredits} credits`
+    : undefined;
+
   const vote = (
-    <button className={className} onClick={voteOrUndo}>
+    <button className={className} title={title} onClick={isBudgeted ? placeCredit : voteOrUndo}>
       <FaCaretUp />
-      {votesCount}
+      {count}
     </button>
   );
 
   const disabled = (
-    <button className={className}>
+    <button className={className} title={title}>
       <FaCaretUp />
-      {votesCount}
+      {count}
     </button>
   );
 
   return (
     <>
       <SignInModal isOpen={isSignInModalOpen} onClose={hideModal} />
-      <div className="c-vote-counter">{status.closed ? disabled : vote}</div>
+      <div className="c-vote-counter">
+        {status.closed ? disabled : vote}
+        {isBudgeted && hasVoted && !status.closed && <span className="credits">{credits}×</span>}
+      </div>
     </>
   );
 };
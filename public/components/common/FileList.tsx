@@ -0,0 +1,38 @@
+import "./FileList.scss";
+
+import React from "react";
+import { FaPaperclip } from "react-icons/fa";
+import { FileAttachment } from "@fider/models";
+
+interface FileListProps {
+  files?: FileAttachment[];
+}
+
+const formatSize = (size: number): string => {
+  if (size < 1024) {
+    return `${size} B`;
+  } else if (size < 1024 * 1024) {
+    return `${Math.round(size / 1024)} KB`;
+  }
+  return `${(size / 1024 / 1024).toFixed(1)} MB`;
+};
+
+export const FileList = (props: FileListProps) => {
+  if (!props.files || props.files.length === 0) {
+    return null;
+  }
+
+  return (
+    <ul className="c-file-list">
+      {props.files.map(x => (
+        <li key={x.bkey}>
+          <FaPaperclip />
+          <a href={`/files/${x.bkey}`} rel="nofollow" download={x.fileName}>
+            {x.fileName}
+          </a>
+          <span className="info">{formatSize(x.size)}</span>
+        </li>
+      ))}
+    </ul>
+  );
+};
@@ -58,6 +58,9 @@ export const Header = () => {
   );
 
   const showRightMenu = fider.session.isAuthenticated || !fider.session.tenant.isPrivate;
+  const showRoadmap =
+    showRightMenu &&
+    (fider.session.tenant.isRoadmapPublic || (fider.session.isAuthenticated && fider.session.user.isCollaborator));
   return (
     <div id="c-header">
       <EnvironmentInfo />
@@ -68,6 +71,11 @@ export const Header = () => {
             <TenantLogo size={100} />
             <span>{fider.session.tenant.name}</span>
           </a>
+          {showRoadmap && (
+            <a href="/roadmap" className="c-menu-item-roadmap">
+              Roadmap
+            </a>
+          )}
           {showRightMenu && (
             <div onClick={showModal} className="c-menu-item-signin">
               {fider.session.isAuthenticated && <Avatar user={fider.session.user} />}
@@ -0,0 +1,100 @@
+import "./FileUploader.scss";
+
+import React, { useState } from "react";
+import { ValidationContext } from "./Form";
+import { DisplayError, hasError } from "./DisplayError";
+import { classSet, fileToBase64 } from "@fider/services";
+import { Button, ButtonClickEvent } from "@fider/components";
+import { FaPaperclip } from "react-icons/fa";
+import { FileAttachment, FileUpload } from "@fider/models";
+
+interface FileUploaderProps {
+  field: string;
+  maxUploads: number;
+  allowedTypes: string;
+  files?: FileAttachment[];
+  onChange?: (uploads: FileUpload[]) => void;
+}
+
+interface FileUploaderItem {
+  name: string;
+  upload: FileUpload;
+}
+
+export const FileUploader = (props: FileUploaderProps) => {
+  const [items, setItems] = useState<FileUploaderItem[]>(
+    (props.files || []).map(f => ({ name: f.fileName, upload: { bkey: f.bkey, remove: false } }))
+  );
+  const [removed, setRemoved] = useState<FileUpload[]>([]);
+  let fileSelector: HTMLInputElement | null;
+
+  const triggerOnChange = (newItems: FileUploaderItem[], newRemoved: FileUpload[]) => {
+    setItems(newItems);
+    setRemoved(newRemoved);
+    if (props.onChange) {
+      props.onChange(newItems.map(x => x.upload).concat(newRemoved));
+    }
+  };
+
+  const fileChanged = async (e: React.ChangeEvent<HTMLInputElement>) => {
+    if (e.target.files && e.target.files[0]) {
+      const file = e.target.files[0];
+      const content = await fileToBase64(file);
+      const upload = { upload: { fileName: file.name, content, contentType: file.type }, remove: false };
+      triggerOnChange([...items, { name: file.name, upload }], removed);
+      e.target.value = "";
+    }
+  };
+
+  const removeFile = (item: FileUploaderItem) => async (e: ButtonClickEvent) => {
+    const newRemoved = item.upload.bkey ? [...removed, { bkey: item.upload.bkey, remove: true }] : removed;
+    triggerOnChange(items.filter(x => x !== item), newRemoved);
+  };
+
+  const selectFile = async (e: ButtonClickEvent) => {
+    if (fileSelector) {
+      fileSelector.click();
+    }
+  };
+
+  if (!props.allowedTypes) {
+    return null;
+  }
+
+  return (
+    <ValidationContext.Consumer>
+      {ctx => (
+        <div
+          className={classSet({
+            "c-form-field": true,
+            "c-file-uploader": true,
+            "m-error": hasError(props.field, ctx.error)
+          })}
+        >
+          {items.map((item, i) => (
+            <div key={i} className="c-file-uploader-item">
+              <span>{item.name}</span>
+              <Button onClick={removeFile(item)} color="danger" size="mini">
+                X
+              </Button>
+            </div>
+          ))}
+          <input
+            ref={e => (fileSelector = e)}
+            type="file"
+            onChange={fileChanged}
+            accept={props.allowedTypes.replace(/\s/g, "")}
+          />
+          <DisplayError fields={[props.field]} error={ctx.error} />
+          {items.length < props.maxUploads && (
+            <div className="c-form-field-wrapper">
+              <Button onClick={selectFile} title="Attach a file">
+                <FaPaperclip />
+              </Button>
+            </div>
+          )}
+        </div>
+      )}
+    </ValidationContext.Consumer>
+  );
+};
@@ -4,6 +4,7 @@ export * from "./form/Form";
 export * from "./form/Input";
 export * from "./form/ImageUploader";
 export * from "./form/MultiImageUploader";
+export * from "./form/FileUploader";
 export * from "./form/TextArea";
 export * from "./form/RadioButton";
 export * from "./form/DisplayError";
@@ -33,6 +34,7 @@ export * from "./Logo";
 export * from "./Toggle";
 export * from "./FiderVersion";
 export * from "./DropDown";
+export * from "./FileList";
 
 import Textarea from "react-textarea-autosize";
 export { Textarea };
@@ -7,7 +7,12 @@ export interface Tenant {
   welcomeMessage: string;
   status: TenantStatus;
   isPrivate: boolean;
+  isRoadmapPublic: boolean;
+  votingMode: "simple" | "budgeted";
+  voteBudget: number;
   logoBlobKey: string;
+  locale: string;
+  allowedFileTypes: string;
   billing?: {
     stripePlanID: string;
     subscriptionEndsAt: string;
@@ -29,6 +34,30 @@ export interface User {
   avatarURL: string;
 }
 
+export interface APIToken {
+  id: number;
+  name: string;
+  user: User;
+  scopes: string[];
+  expiresAt?: string;
+  lastUsedAt?: string;
+  createdAt: string;
+}
+
+export const APIScopes = [
+  "posts:read",
+  "posts:write",
+  "comments:write",
+  "votes:write",
+  "tags:write",
+  "users:read",
+  "users:admin",
+  "audit:read",
+  "analytics:read"
+];
+
+export const AdministratorOnlyAPIScopes = ["users:admin", "audit:read"];
+
 export enum UserAvatarType {
   Letter = "letter",
   Gravatar = "gravatar",
@@ -62,4 +91,5 @@ export interface CurrentUser {
   status: UserStatus;
   isAdministrator: boolean;
   isCollaborator: boolean;
+  locale: string;
 }
@@ -7,11 +7,14 @@ export interface Post {
   title: string;
   description: string;
   createdAt: string;
+  updatedAt: string;
   status: string;
   user: User;
   hasVoted: boolean;
   response: PostResponse | null;
   votesCount: number;
+  score: number;
+  votedCredits: number;
   commentsCount: number;
   tags: string[];
 }
@@ -64,14 +67,39 @@ export interface PostResponse {
   };
 }
 
+export interface PostStatusChange {
+  status: string;
+  changedAt: string;
+}
+
+export interface RoadmapPost {
+  post: Post;
+  history: PostStatusChange[];
+}
+
+export interface RoadmapColumn {
+  status: string;
+  posts: RoadmapPost[];
+}
+
 export interface Comment {
   id: number;
   content: string;
   createdAt: string;
   user: User;
   attachments?: string[];
+  files?: FileAttachment[];
   editedAt?: string;
   editedBy?: User;
+  mergedFrom?: number;
+}
+
+export interface FileAttachment {
+  bkey: string;
+  fileName: string;
+  contentType: string;
+  size: number;
+  commentID?: number;
 }
 
 export interface Tag {
@@ -84,6 +112,7 @@ export interface Tag {
 
 export interface Vote {
   createdAt: Date;
+  credits: number;
   user: {
     id: number;
     name: string;
@@ -1,3 +1,5 @@
+import { User } from "./identity";
+
 export interface OAuthProviderOption {
   provider: string;
   displayName: string;
@@ -22,6 +24,13 @@ export interface SystemSettings {
   tenantAssetsURL: string;
   globalAssetsURL: string;
   oauth: OAuthProviderOption[];
+  locale: string;
+  languages: Language[];
+}
+
+export interface Language {
+  locale: string;
+  name: string;
 }
 
 export interface UserSettings {
@@ -33,10 +42,17 @@ export const OAuthConfigStatus = {
   Enabled: 2
 };
 
+export const OAuthProtocol = {
+  OAuth2: 1,
+  OIDC: 2
+};
+
 export interface OAuthConfig {
   provider: string;
   displayName: string;
   status: number;
+  protocol: number;
+  issuerURL: string;
   clientID: string;
   clientSecret: string;
   authorizeURL: string;
@@ -49,6 +65,94 @@ export interface OAuthConfig {
   jsonUserEmailPath: string;
 }
 
+export interface SAMLConfig {
+  isEnabled: boolean;
+  displayName: string;
+  idpMetadata: string;
+  idpEntityID: string;
+  idpSSOURL: string;
+  nameAttribute: string;
+  emailAttribute: string;
+  roleAttribute: string;
+  administratorRoles: string;
+  collaboratorRoles: string;
+}
+
+export interface EmailTemplate {
+  name: string;
+  subject: string;
+  body: string;
+  variables: string[];
+  isCustomized: boolean;
+}
+
+export interface EmailSuppression {
+  address: string;
+  reason: "bounced" | "complained";
+  detail?: string;
+  createdAt: string;
+}
+
+export interface AuditLog {
+  id: number;
+  action: string;
+  actor?: User;
+  targetType: string;
+  targetID?: number;
+  targetName?: string;
+  before?: { [key: string]: any };
+  after?: { [key: string]: any };
+  clientIP: string;
+  createdAt: string;
+}
+
+export interface AnalyticsPoint {
+  date: string;
+  count: number;
+}
+
+export interface AnalyticsPost {
+  number: number;
+  title: string;
+  slug: string;
+  status: string;
+  votes: number;
+  comments: number;
+}
+
+export interface AnalyticsTag {
+  name: string;
+  slug: string;
+  color: string;
+  posts: number;
+  votes: number;
+  comments: number;
+}
+
+export interface Analytics {
+  since: string;
+  until: string;
+  interval: string;
+  series: {
+    posts: AnalyticsPoint[];
+    votes: AnalyticsPoint[];
+    comments: AnalyticsPoint[];
+    activeUsers: AnalyticsPoint[];
+    signUps: AnalyticsPoint[];
+    statusChanges: { [status: string]: AnalyticsPoint[] };
+    events: { [name: string]: AnalyticsPoint[] };
+  };
+  topVoted: AnalyticsPost[];
+  trending: AnalyticsPost[];
+  responseTime: {
+    responded: number;
+    unresponded: number;
+    averageSeconds: number;
+    medianSeconds: number;
+  };
+  tags: AnalyticsTag[];
+}
+
 export interface ImageUpload {
   bkey?: string;
   upload?: {
@@ -58,3 +162,13 @@ export interface ImageUpload {
   };
   remove: boolean;
 }
+
+export interface FileUpload {
+  bkey?: string;
+  upload?: {
+    fileName: string;
+    content: string;
+    contentType: string;
+  };
+  remove: boolean;
+}
@@ -1,7 +1,18 @@
 import React, { useState } from "react";
-import { OAuthConfig, OAuthConfigStatus, ImageUpload } from "@fider/models";
+import { OAuthConfig, OAuthConfigStatus, OAuthProtocol, ImageUpload } from "@fider/models";
 import { Failure, actions } from "@fider/services";
-import { Form, Button, Input, Heading, SocialSignInButton, Field, ImageUploader, Toggle } from "@fider/components";
+import {
+  Form,
+  Button,
+  Input,
+  Heading,
+  SocialSignInButton,
+  Field,
+  ImageUploader,
+  Toggle,
+  Select,
+  SelectOption
+} from "@fider/components";
 import { useFider } from "@fider/hooks";
 
 interface OAuthFormProps {
@@ -14,6 +25,8 @@ export const OAuthForm: React.FC<OAuthFormProps> = props => {
   const [provider] = useState((props.config && props.config.provider) || "");
   const [displayName, setDisplayName] = useState((props.config && props.config.displayName) || "");
   const [enabled, setEnabled] = useState((props.config && props.config.status === OAuthConfigStatus.Enabled) || false);
+  const [protocol, setProtocol] = useState((props.config && props.config.protocol) || OAuthProtocol.OAuth2);
+  const [issuerURL, setIssuerURL] = useState((props.config && props.config.issuerURL) || "");
   const [clientID, setClientID] = useState((props.config && props.config.clientID) || "");
   const [clientSecret, setClientSecret] = useState((props.config && props.config.clientSecret) || "");
   const [clientSecretEnabled, setClientSecretEnabled] = useState(!props.config);
@@ -33,6 +46,8 @@ export const OAuthForm: React.FC<OAuthFormProps> = props => {
     const result = await actions.saveOAuthConfig({
       provider,
       status: enabled ? OAuthConfigStatus.Enabled : OAuthConfigStatus.Disabled,
+      protocol,
+      issuerURL,
       displayName,
       clientID,
       clientSecret: clientSecretEnabled ? clientSecret : "",
@@ -62,11 +77,23 @@ export const OAuthForm: React.FC<OAuthFormProps> = props => {
     props.onCancel();
   };
 
+  const handleProtocolChange = (option?: SelectOption) => {
+    if (option) {
+      setProtocol(parseInt(option.value, 10));
+    }
+  };
+
   const enableClientSecret = () => {
     setClientSecret("");
     setClientSecretEnabled(true);
   };
 
+  const isOIDC = protocol === OAuthProtocol.OIDC;
+  const protocolOptions = [
+    { value: OAuthProtocol.OAuth2.toString(), label: "OAuth 2.0" },
+    { value: OAuthProtocol.OIDC.toString(), label: "OpenID Connect" }
+  ];
+
   const title = props.config ? `OAuth Provider: ${props.config.displayName}` : "New OAuth Provider";
   return (
     <>
@@ -104,6 +131,30 @@ export const OAuthForm: React.FC<OAuthFormProps> = props => {
           </div>
         </div>
 
+        <Select
+          field="protocol"
+          label="Protocol"
+          defaultValue={protocol.toString()}
+          options={protocolOptions}
+          onChange={handleProtocolChange}
+        />
+
+        {isOIDC && (
+          <Input
+            field="issuerURL"
+            label="Issuer URL"
+            maxLength={300}
+            value={issuerURL}
+            disabled={!fider.session.user.isAdministrator}
+            onChange={setIssuerURL}
+          >
+            <p className="info">
+              The endpoints and signing keys are discovered from <strong>/.well-known/openid-configuration</strong>{" "}
+              under this URL. User profile is read from the standard claims of the ID Token.
+            </p>
+          </Input>
+        )}
+
         <Input
           field="clientID"
           label="Client ID"
@@ -133,22 +184,26 @@ export const OAuthForm: React.FC<OAuthFormProps> = props => {
             )
           }
         />
-        <Input
-          field="authorizeURL"
-          label="Authorize URL"
-          maxLength={300}
-          value={authorizeURL}
-          disabled={!fider.session.user.isAdministrator}
-          onChange={setAuthorizeURL}
-        />
-        <Input
-          field="tokenURL"
-          label="Token URL"
-          maxLength={300}
-          value={tokenURL}
-          disabled={!fider.session.user.isAdministrator}
-          onChange={setTokenURL}
-        />
+        {!isOIDC && (
+          <>
+            <Input
+              field="authorizeURL"
+              label="Authorize URL"
+              maxLength={300}
+              value={authorizeURL}
+              disabled={!fider.session.user.isAdministrator}
+              onChange={setAuthorizeURL}
+            />
+            <Input
+              field="tokenURL"
+              label="Token URL"
+              maxLength={300}
+              value={tokenURL}
+              disabled={!fider.session.user.isAdministrator}
+              onChange={setTokenURL}
+            />
+          </>
+        )}
 
         <Input
           field="scope"
@@ -164,74 +219,76 @@ export const OAuthForm: React.FC<OAuthFormProps> = props => {
           </p>
         </Input>
 
-        <h3>User Profile</h3>
-        <p className="info">
-          This section is used to configure how Fider will fetch user after the authentication process.
-        </p>
+        {!isOIDC && (
+          <>
+            <h3>User Profile</h3>
+            <p className="info">
+              This section is used to configure how Fider will fetch user after the authentication process.
+            </p>
 
-        <Input
-          field="profileURL"
-          label="Profile API URL"
-          maxLength={300}
-          value={profileURL}
-          disabled={!fider.session.user.isAdministrator}
-          onChange={setProfileURL}
-        >
-          <p className="info">
-            The URL to fetch the authenticated user info. If empty, Fider will try to parse the user info from the
-            Access Token.
-          </p>
-        </Input>
+            <Input
+              field="profileURL"
+              label="Profile API URL"
+              maxLength={300}
+              value={profileURL}
+              disabled={!fider.session.user.isAdministrator}
+              onChange={setProfileURL}
+            >
+              <p className="info">
+                The URL to fetch the authenticated user info. If empty, Fider will try to parse the user info from the
+                Access Token.
+              </p>
+            </Input>
 
-        <h4>JSON Path</h4>
+            <h4>JSON Path</h4>
 
-        <div className="row">
-          <Input
-            field="jsonUserIDPath"
-            label="ID"
-            className="col-sm-4"
-            maxLength={100}
-            value={jsonUserIDPath}
-            disabled={!fider.session.user.isAdministrator}
-            onChange={setJSONUserIDPath}
-          >
-            <p className="info">
-              Path to extract User ID from the JSON. This ID <strong>must</strong> be unique within the provider or
-              unexpected side effects might happen. For example below, the path would be <strong>id</strong>.
-            </p>
-          </Input>
-          <Input
-            field="jsonUserNamePath"
-            label="Name"
-            className="col-sm-4"
-            maxLength={100}
-            value={jsonUserNamePath}
-            disabled={!fider.session.user.isAdministrator}
-            onChange={setJSONUserNamePath}
-          >
-            <p className="info">
-              Path to extract user Display Name from the JSON. This is optional, but <strong>highly</strong>{" "}
-              recommended. For the example below, the path would be <strong>profile.name</strong>.
-            </p>
-          </Input>
-          <Input
-            field="jsonUserEmailPath"
-            label="Email"
-            className="col-sm-4"
-            maxLength={100}
-            value={jsonUserEmailPath}
-            disabled={!fider.session.user.isAdministrator}
-            onChange={setJSONUserEmailPath}
-          >
-            <p className="info">
-              Path to extract user Email from the JSON. This is optional, but <strong>highly</strong> recommended. For
-              the example below, the path would be <strong>profile.emails[0]</strong>.
-            </p>
-          </Input>
-        </div>
-        <pre>
-          <h5>Example Response</h5>
-          {`
+            <div className="row">
+              <Input
+                field="jsonUserIDPath"
+                label="ID"
+                className="col-sm-4"
+                maxLength={100}
+                value={jsonUserIDPath}
+                disabled={!fider.session.user.isAdministrator}
+                onChange={setJSONUserIDPath}
+              >
+                <p className="info">
+                  Path to extract User ID from the JSON. This ID <strong>must</strong> be unique within the provider or
+                  unexpected side effects might happen. For example below, the path would be <strong>id</strong>.
+                </p>
+              </Input>
+              <Input
+                field="jsonUserNamePath"
+                label="Name"
+                className="col-sm-4"
+                maxLength={100}
+                value={jsonUserNamePath}
+                disabled={!fider.session.user.isAdministrator}
+                onChange={setJSONUserNamePath}
+              >
+                <p className="info">
+                  Path to extract user Display Name from the JSON. This is optional, but <strong>highly</strong>{" "}
+                  recommended. For the example below, the path would be <strong>profile.name</strong>.
+                </p>
+              </Input>
+              <Input
+                field="jsonUserEmailPath"
+                label="Email"
+                className="col-sm-4"
+                maxLength={100}
+                value={jsonUserEmailPath}
+                disabled={!fider.session.user.isAdministrator}
+                onChange={setJSONUserEmailPath}
+              >
+                <p className="info">
+                  Path to extract user Email from the JSON. This is optional, but <strong>highly</strong> recommended.
+                  For the example below, the path would be <strong>profile.emails[0]</strong>.
+                </p>
+              </Input>
+            </div>
+            <pre>
+              <h5>Example Response</h5>
+              {`
   { 
   id: "35235"
   title: "Sr. Account Manager",
@@ -243,8 +300,10 @@ export const OAuthForm: React.FC<OAuthFormProps> = props => {
     ]
   }
 }
-          `}
-        </pre>
+              `}
+            </pre>
+          </>
+        )}
 
         <div className="row">
           <div className="col-sm-4">
@@ -49,6 +49,7 @@ export const SideMenu = (props: SiteMenuProps) => {
       <div className="c-side-menu" style={style}>
         <SideMenuItem name="general" title="General" href="/admin" isActive={activeItem === "general"} />
         <SideMenuItem name="privacy" title="Privacy" href="/admin/privacy" isActive={activeItem === "privacy"} />
+        <SideMenuItem name="voting" title="Voting" href="/admin/voting" isActive={activeItem === "voting"} />
         <SideMenuItem name="members" title="Members" href="/admin/members" isActive={activeItem === "members"} />
         <SideMenuItem name="tags" title="Tags" href="/admin/tags" isActive={activeItem === "tags"} />
         <SideMenuItem
@@ -64,12 +65,17 @@ export const SideMenu = (props: SiteMenuProps) => {
           isActive={activeItem === "authentication"}
         />
         <SideMenuItem name="advanced" title="Advanced" href="/admin/advanced" isActive={activeItem === "advanced"} />
+        <SideMenuItem name="analytics" title="Analytics" href="/admin/analytics" isActive={activeItem === "analytics"} />
         {fider.session.user.isAdministrator && (
           <>
             {fider.isBillingEnabled() && !!fider.session.tenant.billing && (
               <SideMenuItem name="billing" title="Billing" href="/admin/billing" isActive={activeItem === "billing"} />
             )}
+            <SideMenuItem name="saml" title="SAML" href="/admin/saml" isActive={activeItem === "saml"} />
+            <SideMenuItem name="emails" title="Emails" href="/admin/emails" isActive={activeItem === "emails"} />
             <SideMenuItem name="export" title="Export" href="/admin/export" isActive={activeItem === "export"} />
+            <SideMenuItem name="import" title="Import" href="/admin/import" isActive={activeItem === "import"} />
+            <SideMenuItem name="audit" title="Audit Log" href="/admin/audit" isActive={activeItem === "audit"} />
           </>
         )}
       </div>
@@ -9,10 +9,12 @@ import { AdminBasePage } from "../components/AdminBasePage";
 
 interface AdvancedSettingsPageProps {
   customCSS: string;
+  allowedFileTypes: string;
 }
 
 interface AdvancedSettingsPageState {
   customCSS: string;
+  allowedFileTypes: string;
   error?: Failure;
 }
 
@@ -27,7 +29,8 @@ export default class AdvancedSettingsPage extends AdminBasePage<AdvancedSettings
     super(props);
 
     this.state = {
-      customCSS: this.props.customCSS
+      customCSS: this.props.customCSS,
+      allowedFileTypes: this.props.allowedFileTypes
     };
   }
 
@@ -35,8 +38,12 @@ export default class AdvancedSettingsPage extends AdminBasePage<AdvancedSettings
     this.setState({ customCSS });
   };
 
+  private setAllowedFileTypes = (allowedFileTypes: string): void => {
+    this.setState({ allowedFileTypes });
+  };
+
   private handleSave = async (e: ButtonClickEvent): Promise<void> => {
-    const result = await actions.updateTenantAdvancedSettings(this.state.customCSS);
+    const result = await actions.updateTenantAdvancedSettings(this.state.customCSS, this.state.allowedFileTypes);
     if (result.ok) {
       location.reload();
     } else {
@@ -78,6 +85,21 @@ export default class AdvancedSettingsPage extends AdminBasePage<AdvancedSettings
           </ul>
         </TextArea>
 
+        <TextArea
+          field="allowedFileTypes"
+          label="Allowed File Types"
+          disabled={!Fider.session.user.isAdministrator}
+          minRows={2}
+          value={this.state.allowedFileTypes}
+          onChange={this.setAllowedFileTypes}
+          placeholder="application/pdf, text/*"
+        >
+          <p className="info">
+            MIME types of the files that users can attach to posts and comments, separated by commas. Use a wildcard
+            like <strong>text/*</strong> to allow all types of a family. Leave it empty to disable file attachments.
+          </p>
+        </TextArea>
+
         {Fider.session.user.isAdministrator && (
           <div className="field">
             <Button color="positive" onClick={this.handleSave}>
@@ -0,0 +1,240 @@
+import "./Analytics.page.scss";
+
+import React from "react";
+import { Form, Select, SelectOption, Input, Button, Field, List, ListItem, Segment, Segments } from "@fider/components";
+import { Analytics, AnalyticsPoint, AnalyticsPost, PostStatus } from "@fider/models";
+import { actions, Failure } from "@fider/services";
+import { AdminBasePage } from "../components/AdminBasePage";
+import { FaChartBar } from "react-icons/fa";
+
+interface AnalyticsPageProps {
+  analytics: Analytics;
+  intervals: string[];
+}
+
+interface AnalyticsPageState {
+  analytics: Analytics;
+  filters: actions.AnalyticsFilters;
+  error?: Failure;
+}
+
+const formatDuration = (seconds: number): string => {
+  const hours = seconds / 3600;
+  if (hours < 1) {
+    return `${Math.round(seconds / 60)} minutes`;
+  }
+  if (hours < 48) {
+    return `${Math.round(hours)} hours`;
+  }
+  return `${Math.round(hours / 24)} days`;
+};
+
+const statusTitle = (value: string): string => {
+  const status = PostStatus.All.filter(s => s.value === value)[0];
+  return status ? status.title : value;
+};
+
+const sum = (points: AnalyticsPoint[]): number => {
+  return points.reduce((total, point) => total + point.count, 0);
+};
+
+const BarChart = (props: { title: string; points: AnalyticsPoint[] }) => {
+  const max = Math.max(1, ...props.points.map(p => p.count));
+  return (
+    <div className="c-bar-chart">
+      <h4>
+        {props.title} <span className="l-total">{sum(props.points)}</span>
+      </h4>
+      <div className="l-bars">
+        {props.points.map(point => (
+          <div
+            key={point.date}
+            className="l-bar"
+            title={`${point.date.substring(0, 10)}: ${point.count}`}
+            style={{ height: `${(point.count / max) * 100}%` }}
+          />
+        ))}
+      </div>
+    </div>
+  );
+};
+
+const PostRanking = (props: { title: string; posts: AnalyticsPost[] }) => (
+  <>
+    <h4>{props.title}</h4>
+    {props.posts.length === 0 ? (
+      <p className="info">No activity in this period.</p>
+    ) : (
+      <List divided={true}>
+        {props.posts.map(post => (
+          <ListItem key={post.number}>
+            <a href={`/posts/${post.number}/${post.slug}`}>
+              #{post.number} {post.title}
+            </a>
+            <div className="l-post-details">
+              {PostStatus.Get(post.status).title} · {post.votes} votes · {post.comments} comments
+            </div>
+          </ListItem>
+        ))}
+      </List>
+    )}
+  </>
+);
+
+export default class AnalyticsPage extends AdminBasePage<AnalyticsPageProps, AnalyticsPageState> {
+  public id = "p-admin-analytics";
+  public name = "analytics";
+  public icon = FaChartBar;
+  public title = "Analytics";
+  public subtitle = "Follow the activity of your site over time";
+
+  constructor(props: AnalyticsPageProps) {
+    super(props);
+    this.state = {
+      analytics: props.analytics,
+      filters: {
+        since: props.analytics.since.substring(0, 10),
+        until: props.analytics.until.substring(0, 10),
+        interval: props.analytics.interval
+      }
+    };
+  }
+
+  private setSince = (since: string) => {
+    this.setState({ filters: { ...this.state.filters, since: since || undefined } });
+  };
+
+  private setUntil = (until: string) => {
+    this.setState({ filters: { ...this.state.filters, until: until || undefined } });
+  };
+
+  private setInterval = (option?: SelectOption) => {
+    const interval = option ? option.value : undefined;
+    this.setState({ filters: { ...this.state.filters, interval } });
+  };
+
+  private refresh = async () => {
+    const result = await actions.getAnalytics(this.state.filters);
+    if (result.ok) {
+      this.setState({ analytics: result.data, error: undefined });
+    } else {
+      this.setState({ error: result.error });
+    }
+  };
+
+  public content() {
+    const { series, responseTime } = this.state.analytics;
+    const intervalOptions = this.props.intervals.map(i => ({ value: i, label: i }));
+
+    return (
+      <>
+        <Form error={this.state.error} className="l-filters">
+          <div className="row">
+            <div className="col-md-4">
+              <Input
+                field="since"
+                label="From"
+                placeholder="YYYY-MM-DD"
+                maxLength={25}
+                value={this.state.filters.since || ""}
+                onChange={this.setSince}
+              />
+            </div>
+            <div className="col-md-4">
+              <Input
+                field="until"
+                label="Until"
+                placeholder="YYYY-MM-DD"
+                maxLength={25}
+                value={this.state.filters.until || ""}
+                onChange={this.setUntil}
+              />
+            </div>
+            <div className="col-md-4">
+              <Select
+                field="interval"
+                label="Interval"
+                defaultValue={this.state.filters.interval}
+                options={intervalOptions}
+                onChange={this.setInterval}
+              />
+            </div>
+          </div>
+          <Field>
+            <Button color="positive" onClick={this.refresh}>
+              Refresh
+            </Button>
+          </Field>
+        </Form>
+
+        <div className="l-charts">
+          <BarChart title="New posts" points={series.posts} />
+          <BarChart title="Votes" points={series.votes} />
+          <BarChart title="Comments" points={series.comments} />
+          <BarChart title="Active users" points={series.activeUsers} />
+          <BarChart title="Sign ups" points={series.signUps} />
+          {Object.keys(series.statusChanges).map(status => (
+            <BarChart
+              key={status}
+              title={`Moved to ${statusTitle(status)}`}
+              points={series.statusChanges[status]}
+            />
+          ))}
+          {Object.keys(series.events).map(name => (
+            <BarChart key={name} title={name} points={series.events[name]} />
+          ))}
+        </div>
+
+        <h4>Response time</h4>
+        <Segments>
+          <Segment>
+            <strong>{responseTime.responded}</strong> posts responded by staff
+          </Segment>
+          <Segment>
+            <strong>{responseTime.unresponded}</strong> posts waiting for a response
+          </Segment>
+          <Segment>
+            <strong>{formatDuration(responseTime.averageSeconds)}</strong> on average
+          </Segment>
+          <Segment>
+            <strong>{formatDuration(responseTime.medianSeconds)}</strong> median
+          </Segment>
+        </Segments>
+
+        <div className="row">
+          <div className="col-md-6">
+            <PostRanking title="Top voted" posts={this.state.analytics.topVoted} />
+          </div>
+          <div className="col-md-6">
+            <PostRanking title="Trending" posts={this.state.analytics.trending} />
+          </div>
+        </div>
+
+        <h4>Tags</h4>
+        <table className="l-tags">
+          <thead>
+            <tr>
+              <th>Tag</th>
+              <th>Posts</th>
+              <th>Votes</th>
+              <th>Comments</th>
+            </tr>
+          </thead>
+          <tbody>
+            {this.state.analytics.tags.map(tag => (
+              <tr key={tag.slug}>
+                <td>
+                  <span className="l-tag-color" style={{ backgroundColor: `#${tag.color}` }} />
+                  {tag.name}
+                </td>
+                <td>{tag.posts}</td>
+                <td>{tag.votes}</td>
+                <td>{tag.comments}</td>
+              </tr>
+            ))}
+          </tbody>
+        </table>
+      </>
+    );
+  }
+}
@@ -0,0 +1,162 @@
+import "./AuditLog.page.scss";
+
+import React from "react";
+import { Form, Select, SelectOption, Input, Button, Field, List, ListItem, Moment } from "@fider/components";
+import { AuditLog, User } from "@fider/models";
+import { actions, Failure } from "@fider/services";
+import { AdminBasePage } from "../components/AdminBasePage";
+import { FaHistory } from "react-icons/fa";
+
+interface AuditLogPageProps {
+  logs: AuditLog[];
+  users: User[];
+  actions: string[];
+}
+
+interface AuditLogPageState {
+  logs: AuditLog[];
+  filters: actions.AuditLogFilters;
+  error?: Failure;
+}
+
+const formatValue = (value: any): string => {
+  if (value === undefined || value === null || value === "") {
+    return "(empty)";
+  }
+  return typeof value === "string" ? value : JSON.stringify(value);
+};
+
+const AuditLogChanges = (props: { log: AuditLog }) => {
+  const before = props.log.before || {};
+  const after = props.log.after || {};
+  const keys = Object.keys({ ...before, ...after }).filter(
+    key => formatValue(before[key]) !== formatValue(after[key])
+  );
+
+  if (keys.length === 0) {
+    return null;
+  }
+
+  return (
+    <ul className="l-changes">
+      {keys.map(key => (
+        <li key={key}>
+          <strong>{key}</strong>: {props.log.before && <del>{formatValue(before[key])}</del>}{" "}
+          {props.log.after && <ins>{formatValue(after[key])}</ins>}
+        </li>
+      ))}
+    </ul>
+  );
+};
+
+export default class AuditLogPage extends AdminBasePage<AuditLogPageProps, AuditLogPageState> {
+  public id = "p-admin-audit";
+  public name = "audit";
+  public icon = FaHistory;
+  public title = "Audit Log";
+  public subtitle = "Review administrative and moderation actions";
+
+  constructor(props: AuditLogPageProps) {
+    super(props);
+    this.state = {
+      logs: props.logs,
+      filters: {}
+    };
+  }
+
+  private setActor = (option?: SelectOption) => {
+    const actor = option ? parseInt(option.value, 10) || undefined : undefined;
+    this.setState({ filters: { ...this.state.filters, actor } });
+  };
+
+  private setAction = (option?: SelectOption) => {
+    const action = option ? option.value || undefined : undefined;
+    this.setState({ filters: { ...this.state.filters, action } });
+  };
+
+  private setCreatedAfter = (createdAfter: string) => {
+    this.setState({ filters: { ...this.state.filters, createdAfter: createdAfter || undefined } });
+  };
+
+  private setCreatedBefore = (createdBefore: string) => {
+    this.setState({ filters: { ...this.state.filters, createdBefore: createdBefore || undefined } });
+  };
+
+  private search = async () => {
+    const result = await actions.listAuditLogs(this.state.filters);
+    if (result.ok) {
+      this.setState({ logs: result.data, error: undefined });
+    } else {
+      this.setState({ error: result.error });
+    }
+  };
+
+  public content() {
+    const actorOptions = [{ value: "", label: "Anyone" }].concat(
+      this.props.users.map(u => ({ value: u.id.toString(), label: u.name }))
+    );
+    const actionOptions = [{ value: "", label: "Any action" }].concat(
+      this.props.actions.map(a => ({ value: a, label: a }))
+    );
+
+    return (
+      <>
+        <Form error={this.state.error} className="l-filters">
+          <div className="row">
+            <div className="col-md-3">
+              <Select field="actor" label="Actor" options={actorOptions} onChange={this.setActor} />
+            </div>
+            <div className="col-md-3">
+              <Select field="action" label="Action" options={actionOptions} onChange={this.setAction} />
+            </div>
+            <div className="col-md-3">
+              <Input
+                field="createdAfter"
+                label="From"
+                placeholder="YYYY-MM-DD"
+                maxLength={25}
+                value={this.state.filters.createdAfter || ""}
+                onChange={this.setCreatedAfter}
+              />
+            </div>
+            <div className="col-md-3">
+              <Input
+                field="createdBefore"
+                label="Until"
+                placeholder="YYYY-MM-DD"
+                maxLength={25}
+                value={this.state.filters.createdBefore || ""}
+                onChange={this.setCreatedBefore}
+              />
+            </div>
+          </div>
+          <Field>
+            <Button color="positive" onClick={this.search}>
+              Search
+            </Button>
+            <Button href={actions.getAuditLogExportURL(this.state.filters)}>Export to CSV</Button>
+          </Field>
+        </Form>
+        {this.state.logs.length === 0 ? (
+          <p className="info">No actions have been recorded for the selected filters.</p>
+        ) : (
+          <List divided={true}>
+            {this.state.logs.map(log => (
+              <ListItem key={log.id}>
+                <div className="l-log-header">
+                  <strong>{log.actor ? log.actor.name : "System"}</strong> <code>{log.action}</code>{" "}
+                  {log.targetName || log.targetType}
+                </div>
+                <AuditLogChanges log={log} />
+                <div className="l-log-details">
+                  <Moment date={log.createdAt} />
+                  {log.clientIP && <span> · {log.clientIP}</span>}
+                </div>
+              </ListItem>
+            ))}
+          </List>
+        )}
+      </>
+    );
+  }
+}
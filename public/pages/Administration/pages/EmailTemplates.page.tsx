@@ -0,0 +1,188 @@
+import "./EmailTemplates.page.scss";
+
+import React from "react";
+import { Form, Field, Input, TextArea, Button, Select, SelectOption } from "@fider/components";
+import { EmailTemplate } from "@fider/models";
+import { actions, notify, Failure, Fider } from "@fider/services";
+import { FaEnvelope } from "react-icons/fa";
+import { AdminBasePage } from "../components/AdminBasePage";
+
+interface EmailTemplatesPageProps {
+  templates: EmailTemplate[];
+  defaults: { [name: string]: EmailTemplate };
+}
+
+interface EmailTemplatesPageState {
+  templates: EmailTemplate[];
+  selected: string;
+  subject: string;
+  body: string;
+  preview?: { subject: string; body: string };
+  error?: Failure;
+}
+
+const previewDelay = 500;
+
+const templateLabels: { [name: string]: string } = {
+  new_post: "New post",
+  new_comment: "New comment",
+  change_status: "Status change",
+  delete_post: "Deleted post",
+  digest: "Digest",
+  invite_email: "Invitation",
+  signin_email: "Sign in",
+  change_emailaddress_email: "Email change confirmation",
+  reply_bounce: "Reply by email failure"
+};
+
+export default class EmailTemplatesPage extends AdminBasePage<EmailTemplatesPageProps, EmailTemplatesPageState> {
+  public id = "p-admin-emails";
+  public name = "emails";
+  public icon = FaEnvelope;
+  public title = "Emails";
+  public subtitle = "Customize the emails sent to your users";
+
+  private previewTimer?: number;
+
+  constructor(props: EmailTemplatesPageProps) {
+    super(props);
+
+    const first = props.templates[0];
+    this.state = {
+      templates: props.templates,
+      selected: first.name,
+      subject: first.subject,
+      body: first.body
+    };
+  }
+
+  public componentDidMount() {
+    this.preview();
+  }
+
+  public componentWillUnmount() {
+    window.clearTimeout(this.previewTimer);
+  }
+
+  private get current(): EmailTemplate {
+    return this.state.templates.filter(t => t.name === this.state.selected)[0];
+  }
+
+  private select = (option?: SelectOption) => {
+    if (option) {
+      const template = this.state.templates.filter(t => t.name === option.value)[0];
+      this.setState(
+        { selected: template.name, subject: template.subject, body: template.body, error: undefined },
+        this.preview
+      );
+    }
+  };
+
+  private setSubject = (subject: string) => this.setState({ subject }, this.schedulePreview);
+  private setBody = (body: string) => this.setState({ body }, this.schedulePreview);
+
+  private schedulePreview = () => {
+    window.clearTimeout(this.previewTimer);
+    this.previewTimer = window.setTimeout(this.preview, previewDelay);
+  };
+
+  private preview = async () => {
+    const result = await actions.previewEmailTemplate(this.state.selected, this.state.subject, this.state.body);
+    if (result.ok) {
+      this.setState({ preview: result.data, error: undefined });
+    } else {
+      this.setState({ error: result.error });
+    }
+  };
+
+  private updateCurrent = (changes: Partial<EmailTemplate>) => {
+    const templates = this.state.templates.map(t => (t.name === this.state.selected ? { ...t, ...changes } : t));
+    this.setState({ templates });
+  };
+
+  private save = async () => {
+    const result = await actions.saveEmailTemplate(this.state.selected, this.state.subject, this.state.body);
+    if (result.ok) {
+      this.updateCurrent({ subject: this.state.subject, body: this.state.body, isCustomized: true });
+      this.setState({ error: undefined });
+      notify.success("Your email template has been saved.");
+    } else {
+      this.setState({ error: result.error });
+    }
+  };
+
+  private reset = async () => {
+    const result = await actions.resetEmailTemplate(this.state.selected);
+    if (result.ok) {
+      const original = this.props.defaults[this.state.selected];
+      this.updateCurrent({ subject: original.subject, body: original.body, isCustomized: false });
+      this.setState({ subject: original.subject, body: original.body, error: undefined }, this.preview);
+      notify.success("The default email template has been restored.");
+    }
+  };
+
+  private sendSample = async () => {
+    const result = await actions.sendSampleEmailTemplate(this.state.selected, this.state.subject, this.state.body);
+    if (result.ok) {
+      this.setState({ error: undefined });
+      notify.success(
+        <span>
+          A sample email has been sent to <strong>{Fider.session.user.email}</strong>
+        </span>
+      );
+    } else {
+      this.setState({ error: result.error });
+    }
+  };
+
+  public content() {
+    const options = this.state.templates.map(t => ({
+      value: t.name,
+      label: `${templateLabels[t.name] || t.name}${t.isCustomized ? " (customized)" : ""}`
+    }));
+
+    return (
+      <Form error={this.state.error}>
+        <Select
+          field="template"
+          label="Email"
+          defaultValue={this.state.selected}
+          options={options}
+          onChange={this.select}
+        />
+        <Input
+          field="subject"
+          label="Subject"
+          maxLength={200}
+          value={this.state.subject}
+          onChange={this.setSubject}
+        />
+        <TextArea field="body" label="Body" minRows={12} value={this.state.body} onChange={this.setBody}>
+          <p className="info">
+            The body is the HTML inside the email layout. Use <code>{"{{ .name }}"}</code> to include a variable,{" "}
+            <code>{"{{ if .name }}...{{ end }}"}</code> for conditionals and <code>{"{{ range .name }}"}</code> for
+            lists. Available variables:{" "}
+            {this.current.variables.map(v => (
+              <code key={v}>{v} </code>
+            ))}
+          </p>
+        </TextArea>
+        <div className="field">
+          <Button color="positive" onClick={this.save}>
+            Save
+          </Button>
+          <Button onClick={this.sendSample}>Send me a sample</Button>
+          {this.current.isCustomized && <Button onClick={this.reset}>Restore default</Button>}
+        </div>
+        {this.state.preview && (
+          <Field label="Preview">
+            <p>
+              <strong>Subject:</strong> {this.state.preview.subject}
+            </p>
+            <iframe className="email-preview" sandbox="" srcDoc={this.state.preview.body} />
+          </Field>
+        )}
+      </Form>
+    );
+  }
+}
@@ -2,7 +2,16 @@ import "./GeneralSettings.page.scss";
 
 import React from "react";
 
-import { Button, ButtonClickEvent, TextArea, Form, Input, ImageUploader } from "@fider/components/common";
+import {
+  Button,
+  ButtonClickEvent,
+  TextArea,
+  Form,
+  Input,
+  ImageUploader,
+  Select,
+  SelectOption
+} from "@fider/components/common";
 import { actions, Failure, Fider } from "@fider/services";
 import { FaCogs } from "react-icons/fa";
 import { AdminBasePage } from "../components/AdminBasePage";
@@ -14,6 +23,7 @@ interface GeneralSettingsPageState {
   invitation: string;
   welcomeMessage: string;
   cname: string;
+  locale: string;
   error?: Failure;
 }
 
@@ -31,7 +41,8 @@ export default class GeneralSettingsPage extends AdminBasePage<{}, GeneralSettin
       title: Fider.session.tenant.name,
       cname: Fider.session.tenant.cname,
       welcomeMessage: Fider.session.tenant.welcomeMessage,
-      invitation: Fider.session.tenant.invitation
+      invitation: Fider.session.tenant.invitation,
+      locale: Fider.session.tenant.locale
     };
   }
 
@@ -79,6 +90,12 @@ export default class GeneralSettingsPage extends AdminBasePage<{}, GeneralSettin
     this.setState({ cname });
   };
 
+  private setLocale = (opt?: SelectOption): void => {
+    if (opt) {
+      this.setState({ locale: opt.value });
+    }
+  };
+
   public content() {
     return (
       <Form error={this.state.error}>
@@ -124,6 +141,19 @@ export default class GeneralSettingsPage extends AdminBasePage<{}, GeneralSettin
           </p>
         </Input>
 
+        <Select
+          label="Language"
+          field="locale"
+          defaultValue={this.state.locale}
+          options={Fider.settings.languages.map(l => ({ value: l.locale, label: l.name }))}
+          onChange={this.setLocale}
+        >
+          <p className="info">
+            The language of the emails, notifications and page titles of this site. Users can choose a different
+            language on their settings.
+          </p>
+        </Select>
+
         <ImageUploader
           label="Logo"
           field="logo"
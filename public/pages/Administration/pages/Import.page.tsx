@@ -0,0 +1,140 @@
+import React from "react";
+
+import { Button, Form, Field, Segment, Select, SelectOption, List, ListItem } from "@fider/components";
+import { actions, notify, fileToBase64, Failure } from "@fider/services";
+import { FaFileImport } from "react-icons/fa";
+import { AdminBasePage } from "../components/AdminBasePage";
+
+interface ImportPageState {
+  kind: string;
+  content: string;
+  report?: actions.ImportReport;
+  error?: Failure;
+}
+
+export default class ImportPage extends AdminBasePage<{}, ImportPageState> {
+  public id = "p-admin-import";
+  public name = "import";
+  public icon = FaFileImport;
+  public title = "Import";
+  public subtitle = "Bring your data from a backup or another tool";
+
+  constructor(props: {}) {
+    super(props);
+    this.state = {
+      kind: "backup",
+      content: ""
+    };
+  }
+
+  private setKind = (option?: SelectOption) => {
+    if (option) {
+      this.setState({ kind: option.value, report: undefined });
+    }
+  };
+
+  private fileChanged = async (e: React.ChangeEvent<HTMLInputElement>) => {
+    if (e.target.files && e.target.files[0]) {
+      const content = await fileToBase64(e.target.files[0]);
+      this.setState({ content, report: undefined });
+    }
+  };
+
+  private validate = async () => {
+    await this.import(true);
+  };
+
+  private confirm = async () => {
+    await this.import(false);
+  };
+
+  private async import(dryRun: boolean) {
+    const response = await actions.importData(this.state.kind, this.state.content, dryRun);
+    if (response.ok) {
+      this.setState({ report: response.data, error: undefined });
+      if (!dryRun && response.data.errors.length === 0) {
+        notify.success("Your data has been imported.");
+      }
+    } else {
+      this.setState({ report: undefined, error: response.error });
+    }
+  }
+
+  private renderReport(report: actions.ImportReport) {
+    const kinds = Object.keys(report.imported).sort();
+    return (
+      <Segment>
+        <Field label={report.dryRun ? "Validation report" : "Import report"}>
+          {report.errors.length > 0 ? (
+            <p className="info">Nothing has been imported. Fix the following errors and try again.</p>
+          ) : report.dryRun ? (
+            <p className="info">The file is valid. This is what will be imported.</p>
+          ) : (
+            <p className="info">This is what has been imported.</p>
+          )}
+        </Field>
+        <List>
+          {kinds.map(kind => (
+            <ListItem key={kind}>
+              <strong>{kind}</strong>: {report.imported[kind]}
+            </ListItem>
+          ))}
+          {report.warnings.map((warning, i) => (
+            <ListItem key={`w${i}`}>⚠️ {warning}</ListItem>
+          ))}
+          {report.errors.map((error, i) => (
+            <ListItem key={`e${i}`}>❌ {error}</ListItem>
+          ))}
+        </List>
+      </Segment>
+    );
+  }
+
+  public content() {
+    const report = this.state.report;
+    const canImport = !!report && report.dryRun && report.errors.length === 0;
+
+    return (
+      <Form error={this.state.error}>
+        <Segment>
+          <Select
+            field="kind"
+            label="What do you want to import?"
+            defaultValue={this.state.kind}
+            options={[
+              { value: "backup", label: "A backup.zip exported from Fider" },
+              { value: "posts", label: "Posts from a CSV file" },
+              { value: "comments", label: "Comments from a CSV file" },
+              { value: "votes", label: "Votes from a CSV file" }
+            ]}
+            onChange={this.setKind}
+          />
+          <Field label="File">
+            <input type="file" accept={this.state.kind === "backup" ? ".zip" : ".csv"} onChange={this.fileChanged} />
+            {this.state.kind === "backup" ? (
+              <p className="info">
+                Backups can only be restored into a site without any post. Users are matched by their email address.
+              </p>
+            ) : (
+              <p className="info">
+                The first line must be a header. Authors are identified by the <code>created_by_email</code> column, or
+                by <code>created_by</code> name when the email is unknown. Files exported as <code>posts.csv</code> can
+                be imported as they are. Posts need a <code>title</code>, while comments and votes need the{" "}
+                <code>post_number</code> they belong to.
+              </p>
+            )}
+          </Field>
+          <Field>
+            <Button disabled={!this.state.content} onClick={this.validate}>
+              Validate
+            </Button>
+            <Button color="positive" disabled={!canImport} onClick={this.confirm}>
+              Import
+            </Button>
+          </Field>
+        </Segment>
+        {report && this.renderReport(report)}
+      </Form>
+    );
+  }
+}
@@ -1,8 +1,19 @@
 import "./ManageMembers.page.scss";
 
 import React from "react";
-import { Segment, List, Input, ListItem, Avatar, UserName, DropDown, DropDownItem } from "@fider/components/common";
-import { User, UserRole, UserStatus } from "@fider/models";
+import {
+  Segment,
+  List,
+  Input,
+  ListItem,
+  Avatar,
+  UserName,
+  DropDown,
+  DropDownItem,
+  Button,
+  Moment
+} from "@fider/components/common";
+import { User, UserRole, UserStatus, EmailSuppression } from "@fider/models";
 import { AdminBasePage } from "../components/AdminBasePage";
 import { FaUsers, FaEllipsisH, FaTimes, FaSearch } from "react-icons/fa";
 import { actions, Fider } from "@fider/services";
@@ -11,10 +22,12 @@ interface ManageMembersPageState {
   query: string;
   users: User[];
   visibleUsers: User[];
+  suppressions: EmailSuppression[];
 }
 
 interface ManageMembersPageProps {
   users: User[];
+  suppressions: EmailSuppression[];
 }
 
 interface UserListItemProps {
@@ -81,10 +94,18 @@ export default class ManageMembersPage extends AdminBasePage<ManageMembersPagePr
     this.state = {
       query: "",
       users,
-      visibleUsers: users.slice(0, 10)
+      visibleUsers: users.slice(0, 10),
+      suppressions: this.props.suppressions
     };
   }
 
+  private clearSuppression = async (suppression: EmailSuppression) => {
+    const result = await actions.clearEmailSuppression(suppression.address);
+    if (result.ok) {
+      this.setState({ suppressions: this.state.suppressions.filter(x => x.address !== suppression.address) });
+    }
+  };
+
   private showMore = (event: React.MouseEvent<HTMLElement> | React.TouchEvent<HTMLElement>): void => {
     event.preventDefault();
     this.setState({
@@ -198,6 +219,33 @@ export default class ManageMembersPage extends AdminBasePage<ManageMembersPagePr
             <strong>&middot; Blocked</strong> users are unable to log into this site.
           </li>
         </ul>
+        {Fider.session.user.isAdministrator && this.state.suppressions.length > 0 && (
+          <div className="l-suppressions">
+            <h4 className="title">Suppressed email addresses</h4>
+            <p className="info">
+              Emails are no longer sent to these addresses because they bounced or were reported as spam. Remove an
+              address from this list once it has been fixed.
+            </p>
+            <Segment>
+              <List divided={true}>
+                {this.state.suppressions.map(s => (
+                  <ListItem key={s.address}>
+                    <Button size="mini" className="right" onClick={this.clearSuppression.bind(this, s)}>
+                      Remove
+                    </Button>
+                    <div className="l-suppression-details">
+                      <strong>{s.address}</strong>
+                      <span className={s.reason}>
+                        {s.reason === "bounced" ? "bounced" : "reported as spam"} <Moment date={s.createdAt} />
+                      </span>
+                      {s.detail && <span className="info">{s.detail}</span>}
+                    </div>
+                  </ListItem>
+                ))}
+              </List>
+            </Segment>
+          </div>
+        )}
       </>
     );
   }
@@ -8,6 +8,7 @@ import { FaKey } from "react-icons/fa";
 
 interface PrivacySettingsPageState {
   isPrivate: boolean;
+  isRoadmapPublic: boolean;
 }
 
 export default class PrivacySettingsPage extends AdminBasePage<{}, PrivacySettingsPageState> {
@@ -21,7 +22,8 @@ export default class PrivacySettingsPage extends AdminBasePage<{}, PrivacySettin
     super(props);
 
     this.state = {
-      isPrivate: Fider.session.tenant.isPrivate
+      isPrivate: Fider.session.tenant.isPrivate,
+      isRoadmapPublic: Fider.session.tenant.isRoadmapPublic
     };
   }
 
@@ -30,15 +32,26 @@ export default class PrivacySettingsPage extends AdminBasePage<{}, PrivacySettin
       state => ({
         isPrivate: active
       }),
-      async () => {
-        const response = await actions.updateTenantPrivacy(this.state.isPrivate);
-        if (response.ok) {
-          notify.success("Your privacy settings have been saved.");
-        }
-      }
+      this.save
     );
   };
 
+  private toggleRoadmap = async (active: boolean) => {
+    this.setState(
+      state => ({
+        isRoadmapPublic: active
+      }),
+      this.save
+    );
+  };
+
+  private save = async () => {
+    const response = await actions.updateTenantPrivacy(this.state.isPrivate, this.state.isRoadmapPublic);
+    if (response.ok) {
+      notify.success("Your privacy settings have been saved.");
+    }
+  };
+
   public content() {
     return (
       <Form>
@@ -50,6 +63,18 @@ export default class PrivacySettingsPage extends AdminBasePage<{}, PrivacySettin
             enabled, only already registered and invited users will be able to sign in to this site.
           </p>
         </div>
+        <div className="c-form-field">
+          <label htmlFor="roadmap">Public roadmap</label>
+          <Toggle
+            disabled={!Fider.session.user.isAdministrator}
+            active={this.state.isRoadmapPublic}
+            onToggle={this.toggleRoadmap}
+          />
+          <p className="info">
+            The roadmap lists all planned, started and recently completed posts. <br /> If disabled, only
+            collaborators and administrators will be able to see it.
+          </p>
+        </div>
       </Form>
     );
   }
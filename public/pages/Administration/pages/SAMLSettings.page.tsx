@@ -0,0 +1,184 @@
+import React from "react";
+import { Form, Field, Input, TextArea, Toggle, Button } from "@fider/components";
+import { SAMLConfig } from "@fider/models";
+import { actions, notify, Failure } from "@fider/services";
+import { FaIdBadge } from "react-icons/fa";
+import { AdminBasePage } from "../components/AdminBasePage";
+
+interface SAMLSettingsPageProps {
+  config?: SAMLConfig;
+  entityID: string;
+  acsURL: string;
+  metadataURL: string;
+}
+
+interface SAMLSettingsPageState {
+  isEnabled: boolean;
+  displayName: string;
+  idpMetadata: string;
+  nameAttribute: string;
+  emailAttribute: string;
+  roleAttribute: string;
+  administratorRoles: string;
+  collaboratorRoles: string;
+  error?: Failure;
+}
+
+export default class SAMLSettingsPage extends AdminBasePage<SAMLSettingsPageProps, SAMLSettingsPageState> {
+  public id = "p-admin-saml";
+  public name = "saml";
+  public icon = FaIdBadge;
+  public title = "SAML";
+  public subtitle = "Sign in users with your SAML 2.0 Identity Provider";
+
+  constructor(props: SAMLSettingsPageProps) {
+    super(props);
+
+    const config = props.config;
+    this.state = {
+      isEnabled: config ? config.isEnabled : false,
+      displayName: config ? config.displayName : "",
+      idpMetadata: config ? config.idpMetadata : "",
+      nameAttribute: config ? config.nameAttribute : "",
+      emailAttribute: config ? config.emailAttribute : "",
+      roleAttribute: config ? config.roleAttribute : "",
+      administratorRoles: config ? config.administratorRoles : "",
+      collaboratorRoles: config ? config.collaboratorRoles : ""
+    };
+  }
+
+  private handleSave = async () => {
+    const response = await actions.saveSAMLConfig({
+      isEnabled: this.state.isEnabled,
+      displayName: this.state.displayName,
+      idpMetadata: this.state.idpMetadata,
+      nameAttribute: this.state.nameAttribute,
+      emailAttribute: this.state.emailAttribute,
+      roleAttribute: this.state.roleAttribute,
+      administratorRoles: this.state.administratorRoles,
+      collaboratorRoles: this.state.collaboratorRoles
+    });
+    if (response.ok) {
+      this.setState({ error: undefined });
+      notify.success("Your SAML settings have been saved.");
+    } else {
+      this.setState({ error: response.error });
+    }
+  };
+
+  private setEnabled = (isEnabled: boolean) => this.setState({ isEnabled });
+  private setDisplayName = (displayName: string) => this.setState({ displayName });
+  private setIdPMetadata = (idpMetadata: string) => this.setState({ idpMetadata });
+  private setNameAttribute = (nameAttribute: string) => this.setState({ nameAttribute });
+  private setEmailAttribute = (emailAttribute: string) => this.setState({ emailAttribute });
+  private setRoleAttribute = (roleAttribute: string) => this.setState({ roleAttribute });
+  private setAdministratorRoles = (administratorRoles: string) => this.setState({ administratorRoles });
+  private setCollaboratorRoles = (collaboratorRoles: string) => this.setState({ collaboratorRoles });
+
+  public content() {
+    return (
+      <Form error={this.state.error}>
+        <Field label="Service Provider">
+          <p className="info">Use these values to register Fider as an application on your Identity Provider.</p>
+          <p>
+            <strong>Entity ID:</strong> <code>{this.props.entityID}</code>
+          </p>
+          <p>
+            <strong>Assertion Consumer Service URL:</strong> <code>{this.props.acsURL}</code>
+          </p>
+          <p>
+            <strong>Metadata:</strong>{" "}
+            <a href={this.props.metadataURL} target="_blank">
+              {this.props.metadataURL}
+            </a>
+          </p>
+        </Field>
+        <Field label="Status">
+          <Toggle active={this.state.isEnabled} onToggle={this.setEnabled} />
+          <span>{this.state.isEnabled ? "Enabled" : "Disabled"}</span>
+        </Field>
+        <Input
+          field="displayName"
+          label="Display Name"
+          maxLength={50}
+          value={this.state.displayName}
+          placeholder="Company SSO"
+          onChange={this.setDisplayName}
+        >
+          <p className="info">The text shown on the sign in button.</p>
+        </Input>
+        <TextArea
+          field="idpMetadata"
+          label="Identity Provider Metadata"
+          minRows={6}
+          value={this.state.idpMetadata}
+          placeholder="<md:EntityDescriptor ...>"
+          onChange={this.setIdPMetadata}
+        />
+        {this.props.config && this.props.config.idpEntityID && (
+          <p className="info">
+            Currently trusting <strong>{this.props.config.idpEntityID}</strong> at {this.props.config.idpSSOURL}
+          </p>
+        )}
+        <Input
+          field="nameAttribute"
+          label="Name Attribute"
+          maxLength={100}
+          value={this.state.nameAttribute}
+          placeholder="displayName"
+          onChange={this.setNameAttribute}
+        />
+        <Input
+          field="emailAttribute"
+          label="Email Attribute"
+          maxLength={100}
+          value={this.state.emailAttribute}
+          placeholder="email"
+          onChange={this.setEmailAttribute}
+        />
+        <Input
+          field="roleAttribute"
+          label="Role Attribute"
+          maxLength={100}
+          value={this.state.roleAttribute}
+          placeholder="groups"
+          onChange={this.setRoleAttribute}
+        >
+          <p className="info">
+            Optional. When set, the role of users is updated every time they sign in. Users without any of the roles
+            below become visitors.
+          </p>
+        </Input>
+        {this.state.roleAttribute && (
+          <>
+            <Input
+              field="administratorRoles"
+              label="Administrator Roles"
+              maxLength={300}
+              value={this.state.administratorRoles}
+              placeholder="fider-admins"
+              onChange={this.setAdministratorRoles}
+            >
+              <p className="info">Comma separated list of values.</p>
+            </Input>
+            <Input
+              field="collaboratorRoles"
+              label="Collaborator Roles"
+              maxLength={300}
+              value={this.state.collaboratorRoles}
+              placeholder="fider-collaborators"
+              onChange={this.setCollaboratorRoles}
+            >
+              <p className="info">Comma separated list of values.</p>
+            </Input>
+          </>
+        )}
+        <div className="field">
+          <Button color="positive" onClick={this.handleSave}>
+            Save
+          </Button>
+        </div>
+      </Form>
+    );
+  }
+}
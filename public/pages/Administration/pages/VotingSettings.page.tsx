@@ -0,0 +1,88 @@
+import React from "react";
+import { Form, Select, SelectOption, Input, Button } from "@fider/components";
+import { actions, notify, Failure, Fider } from "@fider/services";
+import { AdminBasePage } from "@fider/pages/Administration/components/AdminBasePage";
+import { FaCaretUp } from "react-icons/fa";
+
+interface VotingSettingsPageState {
+  mode: string;
+  budget: string;
+  error?: Failure;
+}
+
+export default class VotingSettingsPage extends AdminBasePage<{}, VotingSettingsPageState> {
+  public id = "p-admin-voting";
+  public name = "voting";
+  public icon = FaCaretUp;
+  public title = "Voting";
+  public subtitle = "Manage how users vote on your site";
+
+  constructor(props: {}) {
+    super(props);
+
+    this.state = {
+      mode: Fider.session.tenant.votingMode,
+      budget: Fider.session.tenant.voteBudget.toString()
+    };
+  }
+
+  private setMode = (option?: SelectOption) => {
+    if (option) {
+      this.setState({ mode: option.value });
+    }
+  };
+
+  private setBudget = (budget: string) => {
+    this.setState({ budget });
+  };
+
+  private handleSave = async () => {
+    const response = await actions.updateTenantVoting(this.state.mode, parseInt(this.state.budget, 10) || 0);
+    if (response.ok) {
+      this.setState({ error: undefined });
+      notify.success("Your voting settings have been saved.");
+    } else {
+      this.setState({ error: response.error });
+    }
+  };
+
+  public content() {
+    const isAdmin = Fider.session.user.isAdministrator;
+    return (
+      <Form error={this.state.error}>
+        <Select
+          field="mode"
+          label="Voting mode"
+          defaultValue={this.state.mode}
+          options={[
+            { value: "simple", label: "Simple: one vote per user per post" },
+            { value: "budgeted", label: "Budgeted: users spread a limited number of credits" }
+          ]}
+          onChange={this.setMode}
+        />
+        {this.state.mode === "budgeted" && (
+          <Input
+            field="budget"
+            label="Credits per user"
+            maxLength={3}
+            disabled={!isAdmin}
+            value={this.state.budget}
+            onChange={this.setBudget}
+          >
+            <p className="info">
+              Each user can place up to 3 credits on a single post. Credits placed on completed, declined or duplicated
+              posts are refunded.
+            </p>
+          </Input>
+        )}
+        {isAdmin && (
+          <div className="field">
+            <Button color="positive" onClick={this.handleSave}>
+              Save
+            </Button>
+          </div>
+        )}
+      </Form>
+    );
+  }
+}
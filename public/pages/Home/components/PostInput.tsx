@@ -1,8 +1,8 @@
 import React, { useState, useEffect, useRef } from "react";
-import { Button, ButtonClickEvent, Input, Form, TextArea, MultiImageUploader } from "@fider/components";
+import { Button, ButtonClickEvent, Input, Form, TextArea, MultiImageUploader, FileUploader } from "@fider/components";
 import { SignInModal } from "@fider/components";
 import { cache, actions, Failure } from "@fider/services";
-import { ImageUpload } from "@fider/models";
+import { ImageUpload, FileUpload } from "@fider/models";
 import { useFider } from "@fider/hooks";
 
 interface PostInputProps {
@@ -27,6 +27,7 @@ export const PostInput = (props: PostInputProps) => {
   const [description, setDescription] = useState(getCachedValue(CACHE_DESCRIPTION_KEY));
   const [isSignInModalOpen, setIsSignInModalOpen] = useState(false);
   const [attachments, setAttachments] = useState<ImageUpload[]>([]);
+  const [files, setFiles] = useState<FileUpload[]>([]);
   const [error, setError] = useState<Failure | undefined>(undefined);
 
   useEffect(() => {
@@ -56,7 +57,7 @@ export const PostInput = (props: PostInputProps) => {
 
   const submit = async (event: ButtonClickEvent) => {
     if (title) {
-      const result = await actions.createPost(title, description, attachments);
+      const result = await actions.createPost(title, description, attachments, files);
       if (result.ok) {
         clearError();
         cache.session.remove(CACHE_TITLE_KEY, CACHE_DESCRIPTION_KEY);
@@ -78,6 +79,12 @@ export const PostInput = (props: PostInputProps) => {
         placeholder="Describe your suggestion (optional)"
       />
       <MultiImageUploader field="attachments" maxUploads={3} previewMaxWidth={100} onChange={setAttachments} />
+      <FileUploader
+        field="files"
+        maxUploads={3}
+        allowedTypes={fider.session.tenant.allowedFileTypes}
+        onChange={setFiles}
+      />
       <Button type="submit" color="positive" onClick={submit}>
         Submit
       </Button>
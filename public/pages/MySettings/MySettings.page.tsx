@@ -8,7 +8,7 @@ import { UserSettings, UserAvatarType, ImageUpload } from "@fider/models";
 import { Failure, actions, Fider } from "@fider/services";
 import { FaRegAddressCard } from "react-icons/fa";
 import { NotificationSettings } from "./components/NotificationSettings";
-import { APIKeyForm } from "./components/APIKeyForm";
+import { APITokensForm } from "./components/APITokensForm";
 import { DangerZone } from "./components/DangerZone";
 
 interface MySettingsPageState {
@@ -17,6 +17,7 @@ interface MySettingsPageState {
   newEmail: string;
   avatar?: ImageUpload;
   avatarType: UserAvatarType;
+  locale: string;
   changingEmail: boolean;
   error?: Failure;
   userSettings: UserSettings;
@@ -35,6 +36,7 @@ export default class MySettingsPage extends React.Component<MySettingsPageProps,
       avatarType: Fider.session.user.avatarType,
       newEmail: "",
       name: Fider.session.user.name,
+      locale: Fider.session.user.locale,
       userSettings: this.props.userSettings
     };
   }
@@ -44,6 +46,7 @@ export default class MySettingsPage extends React.Component<MySettingsPageProps,
       name: this.state.name,
       avatarType: this.state.avatarType,
       avatar: this.state.avatar,
+      locale: this.state.locale,
       settings: this.state.userSettings
     });
     if (result.ok) {
@@ -84,6 +87,12 @@ export default class MySettingsPage extends React.Component<MySettingsPageProps,
     }
   };
 
+  private localeChanged = (opt?: SelectOption) => {
+    if (opt) {
+      this.setState({ locale: opt.value });
+    }
+  };
+
   private setName = (name: string) => {
     this.setState({ name });
   };
@@ -202,6 +211,19 @@ export default class MySettingsPage extends React.Component<MySettingsPageProps,
                 )}
               </Select>
 
+              <Select
+                label="Language"
+                field="locale"
+                defaultValue={this.state.locale}
+                options={[
+                  { label: "Site default", value: "" },
+                  ...Fider.settings.languages.map(l => ({ label: l.name, value: l.locale }))
+                ]}
+                onChange={this.localeChanged}
+              >
+                <p className="info">The language used on this site and on the emails you receive.</p>
+              </Select>
+
               <NotificationSettings
                 userSettings={this.props.userSettings}
                 settingsChanged={this.setNotificationSettings}
@@ -217,7 +239,7 @@ export default class MySettingsPage extends React.Component<MySettingsPageProps,
         {Fider.session.user.isCollaborator && (
           <div className="row">
             <div className="col-lg-7">
-              <APIKeyForm />
+              <APITokensForm />
             </div>
           </div>
         )}
@@ -0,0 +1,189 @@
+import React from "react";
+import { Button, Form, Input, Checkbox, Field, Select, SelectOption } from "@fider/components";
+import { APIToken, APIScopes, AdministratorOnlyAPIScopes } from "@fider/models";
+import { actions, formatDate, Failure, Fider } from "@fider/services";
+
+interface APITokensFormState {
+  tokens: APIToken[];
+  isAdding: boolean;
+  name: string;
+  scopes: string[];
+  expiresInDays: number;
+  newKey?: string;
+  error?: Failure;
+}
+
+export class APITokensForm extends React.Component<{}, APITokensFormState> {
+  constructor(props: {}) {
+    super(props);
+    this.state = {
+      tokens: [],
+      isAdding: false,
+      name: "",
+      scopes: [],
+      expiresInDays: 90
+    };
+  }
+
+  public async componentDidMount() {
+    const result = await actions.listMyAPITokens();
+    if (result.ok) {
+      this.setState({ tokens: result.data });
+    }
+  }
+
+  private startAdding = () => {
+    this.setState({ isAdding: true, name: "", scopes: [], expiresInDays: 90, newKey: undefined, error: undefined });
+  };
+
+  private cancel = () => {
+    this.setState({ isAdding: false, error: undefined });
+  };
+
+  private setName = (name: string) => {
+    this.setState({ name });
+  };
+
+  private setExpiration = (option?: SelectOption) => {
+    if (option) {
+      this.setState({ expiresInDays: parseInt(option.value, 10) });
+    }
+  };
+
+  private toggleScope = (scope: string) => (checked: boolean) => {
+    const scopes = this.state.scopes.filter(s => s !== scope);
+    this.setState({ scopes: checked ? scopes.concat(scope) : scopes });
+  };
+
+  private create = async () => {
+    const result = await actions.createAPIToken(this.state.name, this.state.scopes, this.state.expiresInDays);
+    if (result.ok) {
+      this.setState({
+        isAdding: false,
+        newKey: result.data.key,
+        tokens: this.state.tokens.concat(result.data.token),
+        error: undefined
+      });
+    } else {
+      this.setState({ error: result.error });
+    }
+  };
+
+  private revoke = async (token: APIToken) => {
+    const result = await actions.revokeAPIToken(token.id);
+    if (result.ok) {
+      this.setState({ tokens: this.state.tokens.filter(t => t.id !== token.id) });
+    }
+  };
+
+  private renderNewKey() {
+    return (
+      <>
+        <p className="info">
+          Your new API Token is: <code>{this.state.newKey}</code>
+        </p>
+        <p className="info">Store it securely on your servers and never store it in the client side of your app.</p>
+      </>
+    );
+  }
+
+  private renderForm() {
+    const scopes = APIScopes.filter(
+      s => AdministratorOnlyAPIScopes.indexOf(s) === -1 || Fider.session.user.isAdministrator
+    );
+    return (
+      <Form error={this.state.error}>
+        <Input field="name" label="Name" maxLength={60} value={this.state.name} onChange={this.setName} />
+        <Field label="Scopes">
+          {scopes.map(scope => (
+            <Checkbox
+              key={scope}
+              field={`scope-${scope}`}
+              checked={this.state.scopes.indexOf(scope) >= 0}
+              onChange={this.toggleScope(scope)}
+            >
+              {scope}
+            </Checkbox>
+          ))}
+        </Field>
+        <Select
+          field="expiresInDays"
+          label="Expiration"
+          defaultValue={this.state.expiresInDays.toString()}
+          options={[
+            { value: "30", label: "30 days" },
+            { value: "90", label: "90 days" },
+            { value: "365", label: "1 year" },
+            { value: "0", label: "Never" }
+          ]}
+          onChange={this.setExpiration}
+        />
+        <Button color="positive" size="tiny" onClick={this.create}>
+          Create
+        </Button>
+        <Button size="tiny" onClick={this.cancel}>
+          Cancel
+        </Button>
+      </Form>
+    );
+  }
+
+  private renderToken(token: APIToken) {
+    return (
+      <tr key={token.id}>
+        <td>{token.name}</td>
+        <td>{token.scopes.join(", ")}</td>
+        <td>{token.expiresAt ? formatDate(token.expiresAt, "short") : "Never"}</td>
+        <td>{token.lastUsedAt ? formatDate(token.lastUsedAt, "short") : "Never"}</td>
+        <td>
+          <Button size="mini" onClick={this.revoke.bind(this, token)}>
+            Revoke
+          </Button>
+        </td>
+      </tr>
+    );
+  }
+
+  public render() {
+    return (
+      <div className="l-api-tokens">
+        <h4>API Tokens</h4>
+        <p className="info">
+          Each token only grants the scopes it was created with and can be revoked at any time. Tokens are only shown
+          when created, so take note of it.
+        </p>
+        <p className="info">
+          To learn how to use the API, read the{" "}
+          <a href="https://getfider.com/docs/api" target="_blank">
+            official documentation
+          </a>
+          .
+        </p>
+        {this.state.tokens.length > 0 && (
+          <table>
+            <thead>
+              <tr>
+                <th>Name</th>
+                <th>Scopes</th>
+                <th>Expires</th>
+                <th>Last used</th>
+                <th />
+              </tr>
+            </thead>
+            <tbody>{this.state.tokens.map(t => this.renderToken(t))}</tbody>
+          </table>
+        )}
+        {this.state.newKey && this.renderNewKey()}
+        {this.state.isAdding ? (
+          this.renderForm()
+        ) : (
+          <p>
+            <Button size="tiny" onClick={this.startAdding}>
+              New API Token
+            </Button>
+          </p>
+        )}
+      </div>
+    );
+  }
+}
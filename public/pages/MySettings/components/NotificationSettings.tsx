@@ -1,7 +1,7 @@
 import React, { useState } from "react";
 
 import { UserSettings } from "@fider/models";
-import { Toggle, Segment, Segments, Field } from "@fider/components";
+import { Toggle, Segment, Segments, Field, Select, SelectOption, Input } from "@fider/components";
 import { useFider } from "@fider/hooks";
 
 interface NotificationSettingsProps {
@@ -13,6 +13,13 @@ type Channel = number;
 const WebChannel: Channel = 1;
 const EmailChannel: Channel = 2;
 
+const TimezoneSettingsKey = "timezone";
+const digestOptions = [
+  { value: "immediate", label: "Immediately" },
+  { value: "daily", label: "Daily digest" },
+  { value: "weekly", label: "Weekly digest" }
+];
+
 export const NotificationSettings = (props: NotificationSettingsProps) => {
   const fider = useFider();
   const [userSettings, setUserSettings] = useState(props.userSettings);
@@ -33,6 +40,35 @@ export const NotificationSettings = (props: NotificationSettingsProps) => {
     props.settingsChanged(nextSettings);
   };
 
+  const change = (settingsKey: string, value: string) => {
+    const nextSettings = {
+      ...userSettings,
+      [settingsKey]: value
+    };
+    setUserSettings(nextSettings);
+    props.settingsChanged(nextSettings);
+  };
+
+  const setTimezone = (value: string) => change(TimezoneSettingsKey, value);
+
+  const digest = (settingsKey: string) => {
+    if (!isEnabled(settingsKey, EmailChannel)) {
+      return null;
+    }
+
+    const digestKey = settingsKey.replace("event_notification_", "event_digest_");
+    const onChange = (option?: SelectOption) => option && change(digestKey, option.value);
+    return (
+      <Select
+        field={digestKey}
+        label="Email delivery"
+        defaultValue={userSettings[digestKey] || "immediate"}
+        options={digestOptions}
+        onChange={onChange}
+      />
+    );
+  };
+
   const icon = (settingsKey: string, channel: Channel) => {
     const active = isEnabled(settingsKey, channel);
     const label = channel === WebChannel ? "Web" : "Email";
@@ -88,6 +124,7 @@ export const NotificationSettings = (props: NotificationSettingsProps) => {
               {icon("event_notification_new_post", WebChannel)}
               {icon("event_notification_new_post", EmailChannel)}
             </p>
+            {digest("event_notification_new_post")}
           </Segment>
           <Segment>
             <span className="event-title">Discussion</span>
@@ -100,6 +137,7 @@ export const NotificationSettings = (props: NotificationSettingsProps) => {
               {icon("event_notification_new_comment", WebChannel)}
               {icon("event_notification_new_comment", EmailChannel)}
             </p>
+            {digest("event_notification_new_comment")}
           </Segment>
           <Segment>
             <span className="event-title">Status Changed</span>
@@ -112,9 +150,23 @@ export const NotificationSettings = (props: NotificationSettingsProps) => {
               {icon("event_notification_change_status", WebChannel)}
               {icon("event_notification_change_status", EmailChannel)}
             </p>
+            {digest("event_notification_change_status")}
           </Segment>
         </Segments>
       </div>
+
+      <Input
+        field={TimezoneSettingsKey}
+        label="Timezone"
+        placeholder="UTC"
+        value={userSettings[TimezoneSettingsKey] || ""}
+        onChange={setTimezone}
+      >
+        <p className="info">
+          Daily digests are sent every morning and weekly digests on Monday mornings, on this timezone. Yours is
+          probably <strong>{Intl.DateTimeFormat().resolvedOptions().timeZone}</strong>.
+        </p>
+      </Input>
     </>
   );
 };
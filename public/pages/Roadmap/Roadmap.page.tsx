@@ -0,0 +1,67 @@
+import "./Roadmap.page.scss";
+
+import React from "react";
+import { RoadmapColumn, RoadmapPost, PostStatus, Tag } from "@fider/models";
+import { ShowTag, Moment } from "@fider/components";
+import { navigator, querystring } from "@fider/services";
+import { TagsFilter } from "@fider/pages/Home/components/TagsFilter";
+
+export interface RoadmapPageProps {
+  columns: RoadmapColumn[];
+  tags: Tag[];
+}
+
+const RoadmapCard = (props: { item: RoadmapPost; tags: Tag[] }) => {
+  const post = props.item.post;
+  return (
+    <div className="c-roadmap-card">
+      <a className="title" href={`/posts/${post.number}/${post.slug}`}>
+        {post.title}
+      </a>
+      <div className="info">{post.votesCount} votes</div>
+      {props.tags
+        .filter(tag => post.tags.indexOf(tag.slug) >= 0)
+        .map(tag => (
+          <ShowTag key={tag.id} size="tiny" tag={tag} />
+        ))}
+      <ul className="timeline">
+        {props.item.history.map((change, i) => (
+          <li key={i}>
+            {PostStatus.Get(change.status).title} <Moment date={change.changedAt} />
+          </li>
+        ))}
+      </ul>
+    </div>
+  );
+};
+
+const RoadmapPage = (props: RoadmapPageProps) => {
+  const selectionChanged = (selected: string[]) => {
+    navigator.goTo(`/roadmap${querystring.stringify({ tags: selected })}`);
+  };
+
+  return (
+    <div id="p-roadmap" className="page container">
+      <div className="l-roadmap-filter">
+        <span className="subtitle">Roadmap</span>
+        <TagsFilter tags={props.tags} defaultSelection={querystring.getArray("tags")} selectionChanged={selectionChanged} />
+      </div>
+      <div className="row">
+        {props.columns.map(column => (
+          <div key={column.status} className="l-roadmap-column col-md-4">
+            <h4>
+              {PostStatus.Get(column.status).title} <span className="info">({column.posts.length})</span>
+            </h4>
+            {column.posts.length === 0 ? (
+              <p className="info center">Nothing here yet.</p>
+            ) : (
+              column.posts.map(item => <RoadmapCard key={item.post.id} item={item} tags={props.tags} />)
+            )}
+          </div>
+        ))}
+      </div>
+    </div>
+  );
+};
+
+export default RoadmapPage;
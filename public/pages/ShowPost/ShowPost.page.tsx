@@ -2,7 +2,7 @@ import "./ShowPost.page.scss";
 
 import React from "react";
 
-import { Comment, Post, Tag, Vote, ImageUpload } from "@fider/models";
+import { Comment, Post, Tag, Vote, ImageUpload, FileAttachment, FileUpload } from "@fider/models";
 import { actions, Failure, Fider } from "@fider/services";
 
 import {
@@ -19,7 +19,9 @@ import {
   Form,
   TextArea,
   MultiImageUploader,
-  ImageViewer
+  ImageViewer,
+  FileUploader,
+  FileList
 } from "@fider/components";
 import { FaSave, FaTimes, FaEdit } from "react-icons/fa";
 import { ResponseForm } from "./components/ResponseForm";
@@ -36,12 +38,14 @@ interface ShowPostPageProps {
   tags: Tag[];
   votes: Vote[];
   attachments: string[];
+  files: FileAttachment[];
 }
 
 interface ShowPostPageState {
   editMode: boolean;
   newTitle: string;
   attachments: ImageUpload[];
+  files: FileUpload[];
   newDescription: string;
   error?: Failure;
 }
@@ -54,7 +58,8 @@ export default class ShowPostPage extends React.Component<ShowPostPageProps, Sho
       editMode: false,
       newTitle: this.props.post.title,
       newDescription: this.props.post.description,
-      attachments: []
+      attachments: [],
+      files: []
     };
   }
 
@@ -63,7 +68,8 @@ export default class ShowPostPage extends React.Component<ShowPostPageProps, Sho
       this.props.post.number,
       this.state.newTitle,
       this.state.newDescription,
-      this.state.attachments
+      this.state.attachments,
+      this.state.files
     );
     if (result.ok) {
       location.reload();
@@ -86,6 +92,10 @@ export default class ShowPostPage extends React.Component<ShowPostPageProps, Sho
     this.setState({ attachments });
   };
 
+  private setFiles = (files: FileUpload[]) => {
+    this.setState({ files });
+  };
+
   private cancelEdit = async () => {
     this.setState({ error: undefined, editMode: false });
   };
@@ -130,6 +140,13 @@ export default class ShowPostPage extends React.Component<ShowPostPageProps, Sho
                 previewMaxWidth={100}
                 onChange={this.setAttachments}
               />
+              <FileUploader
+                field="files"
+                files={this.props.files}
+                maxUploads={3}
+                allowedTypes={Fider.session.tenant.allowedFileTypes}
+                onChange={this.setFiles}
+              />
             </Form>
           ) : (
             <>
@@ -137,6 +154,7 @@ export default class ShowPostPage extends React.Component<ShowPostPageProps, Sho
               {this.props.attachments.map(x => (
                 <ImageViewer key={x} bkey={x} />
               ))}
+              <FileList files={this.props.files} />
             </>
           )}
           <ShowPostResponse showUser={true} status={this.props.post.status} response={this.props.post.response} />
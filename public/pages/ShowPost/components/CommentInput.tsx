@@ -1,7 +1,7 @@
 import React, { useState, useRef } from "react";
 
-import { Post, ImageUpload } from "@fider/models";
-import { Avatar, UserName, Button, TextArea, Form, MultiImageUploader } from "@fider/components/common";
+import { Post, ImageUpload, FileUpload } from "@fider/models";
+import { Avatar, UserName, Button, TextArea, Form, MultiImageUploader, FileUploader } from "@fider/components/common";
 import { SignInModal } from "@fider/components";
 
 import { cache, actions, Failure, Fider } from "@fider/services";
@@ -21,6 +21,7 @@ export const CommentInput = (props: CommentInputProps) => {
   const [content, setContent] = useState((fider.session.isAuthenticated && cache.session.get(getCacheKey())) || "");
   const [isSignInModalOpen, setIsSignInModalOpen] = useState(false);
   const [attachments, setAttachments] = useState<ImageUpload[]>([]);
+  const [files, setFiles] = useState<FileUpload[]>([]);
   const [error, setError] = useState<Failure | undefined>(undefined);
 
   const commentChanged = (newContent: string) => {
@@ -34,7 +35,7 @@ export const CommentInput = (props: CommentInputProps) => {
   const submit = async () => {
     clearError();
 
-    const result = await actions.createComment(props.post.number, content, attachments);
+    const result = await actions.createComment(props.post.number, content, attachments, files);
     if (result.ok) {
       cache.session.remove(getCacheKey());
       location.reload();
@@ -69,6 +70,12 @@ export const CommentInput = (props: CommentInputProps) => {
           {content && (
             <>
               <MultiImageUploader field="attachments" maxUploads={2} previewMaxWidth={100} onChange={setAttachments} />
+              <FileUploader
+                field="files"
+                maxUploads={2}
+                allowedTypes={fider.session.tenant.allowedFileTypes}
+                onChange={setFiles}
+              />
               <Button color="positive" onClick={submit}>
                 Submit
               </Button>
@@ -1,8 +1,9 @@
 import React, { useState } from "react";
 import { PostStatus, Post } from "@fider/models";
-import { actions, navigator, Failure } from "@fider/services";
-import { Form, Modal, Button, List, ListItem, TextArea } from "@fider/components";
+import { actions, navigator, notify, Failure } from "@fider/services";
+import { Form, Modal, Button, List, ListItem, TextArea, Field, DisplayError } from "@fider/components";
 import { useFider } from "@fider/hooks";
+import { PostSearch } from "./PostSearch";
 
 interface ModerationPanelProps {
   post: Post;
@@ -11,11 +12,15 @@ interface ModerationPanelProps {
 export const ModerationPanel = (props: ModerationPanelProps) => {
   const fider = useFider();
   const [showConfirmation, setShowConfirmation] = useState(false);
+  const [showMerge, setShowMerge] = useState(false);
   const [text, setText] = useState("");
+  const [originalNumber, setOriginalNumber] = useState(0);
   const [error, setError] = useState<Failure>();
 
   const hideModal = async () => setShowConfirmation(false);
   const showModal = async () => setShowConfirmation(true);
+  const hideMergeModal = async () => setShowMerge(false);
+  const showMergeModal = async () => setShowMerge(true);
 
   const handleDelete = async () => {
     const response = await actions.deletePost(props.post.number, text);
@@ -27,11 +32,48 @@ export const ModerationPanel = (props: ModerationPanelProps) => {
     }
   };
 
+  const handleMerge = async () => {
+    const response = await actions.mergePost(props.post.number, originalNumber);
+    if (response.ok) {
+      navigator.goTo(`/posts/${response.data.number}/${response.data.slug}`);
+    } else if (response.error) {
+      setError(response.error);
+    }
+  };
+
+  const handleUnmerge = async () => {
+    const response = await actions.unmergePost(props.post.number);
+    if (response.ok) {
+      location.reload();
+    } else if (response.error && response.error.errors) {
+      notify.error(response.error.errors.map(e => e.message).join(" "));
+    }
+  };
+
   const status = PostStatus.Get(props.post.status);
-  if (!fider.session.isAuthenticated || !fider.session.user.isAdministrator || status.closed) {
+  if (!fider.session.isAuthenticated || !fider.session.user.isAdministrator) {
     return null;
   }
 
+  if (status.closed) {
+    if (status !== PostStatus.Duplicate) {
+      return null;
+    }
+
+    return (
+      <>
+        <span className="subtitle">Moderation</span>
+        <List>
+          <ListItem>
+            <Button size="tiny" fluid={true} onClick={handleUnmerge}>
+              Unmerge
+            </Button>
+          </ListItem>
+        </List>
+      </>
+    );
+  }
+
   const modal = (
     <Modal.Window isOpen={showConfirmation} onClose={hideModal} center={false} size="large">
       <Modal.Content>
@@ -60,11 +102,43 @@ export const ModerationPanel = (props: ModerationPanelProps) => {
     </Modal.Window>
   );
 
+  const mergeModal = (
+    <Modal.Window isOpen={showMerge} onClose={hideMergeModal} center={false} size="large">
+      <Modal.Content>
+        <Form error={error}>
+          <Field>
+            <PostSearch exclude={[props.post.number]} onChanged={setOriginalNumber} />
+          </Field>
+          <DisplayError fields={["originalNumber"]} error={error} />
+          <span className="info">
+            Votes, comments and subscribers of this post will be moved to the original post. This operation can be
+            undone for a limited time.
+          </span>
+        </Form>
+      </Modal.Content>
+
+      <Modal.Footer>
+        <Button color="positive" onClick={handleMerge}>
+          Merge
+        </Button>
+        <Button color="cancel" onClick={hideMergeModal}>
+          Cancel
+        </Button>
+      </Modal.Footer>
+    </Modal.Window>
+  );
+
   return (
     <>
       {modal}
+      {mergeModal}
       <span className="subtitle">Moderation</span>
       <List>
+        <ListItem>
+          <Button size="tiny" fluid={true} onClick={showMergeModal}>
+            Merge
+          </Button>
+        </ListItem>
         <ListItem>
           <Button color="danger" size="tiny" fluid={true} onClick={showModal}>
             Delete
@@ -1,5 +1,5 @@
 import React, { useState } from "react";
-import { Comment, Post, ImageUpload } from "@fider/models";
+import { Comment, Post, ImageUpload, FileUpload } from "@fider/models";
 import {
   Avatar,
   UserName,
@@ -12,7 +12,9 @@ import {
   DropDownItem,
   Modal,
   ImageViewer,
-  MultiImageUploader
+  MultiImageUploader,
+  FileUploader,
+  FileList
 } from "@fider/components";
 import { formatDate, Failure, actions } from "@fider/services";
 import { FaEllipsisH } from "react-icons/fa";
@@ -29,6 +31,7 @@ export const ShowComment = (props: ShowCommentProps) => {
   const [newContent, setNewContent] = useState("");
   const [isDeleteConfirmationModalOpen, setIsDeleteConfirmationModalOpen] = useState(false);
   const [attachments, setAttachments] = useState<ImageUpload[]>([]);
+  const [files, setFiles] = useState<FileUpload[]>([]);
   const [error, setError] = useState<Failure>();
 
   const canEditComment = (): boolean => {
@@ -47,7 +50,7 @@ export const ShowComment = (props: ShowCommentProps) => {
   };
 
   const saveEdit = async () => {
-    const response = await actions.updateComment(props.post.number, props.comment.id, newContent, attachments);
+    const response = await actions.updateComment(props.post.number, props.comment.id, newContent, attachments, files);
     if (response.ok) {
       location.reload();
     } else {
@@ -112,6 +115,12 @@ export const ShowComment = (props: ShowCommentProps) => {
     </div>
   );
 
+  const mergedMetadata = !!comment.mergedFrom && (
+    <div className="c-comment-metadata">
+      <span title={`This comment was originally posted on #${comment.mergedFrom}`}>· merged from #{comment.mergedFrom}</span>
+    </div>
+  );
+
   return (
     <div className="c-comment">
       {modal()}
@@ -122,6 +131,7 @@ export const ShowComment = (props: ShowCommentProps) => {
           · <Moment date={comment.createdAt} />
         </div>
         {editedMetadata}
+        {mergedMetadata}
         {!isEditing && canEditComment() && (
           <DropDown
             className="l-more-actions"
@@ -154,6 +164,13 @@ export const ShowComment = (props: ShowCommentProps) => {
                 previewMaxWidth={100}
                 onChange={setAttachments}
               />
+              <FileUploader
+                field="files"
+                files={comment.files}
+                maxUploads={2}
+                allowedTypes={fider.session.tenant.allowedFileTypes}
+                onChange={setFiles}
+              />
               <Button size="tiny" onClick={saveEdit} color="positive">
                 Save
               </Button>
@@ -165,6 +182,7 @@ export const ShowComment = (props: ShowCommentProps) => {
             <>
               <MultiLineText text={comment.content} style="simple" />
               {comment.attachments && comment.attachments.map(x => <ImageViewer key={x} bkey={x} />)}
+              <FileList files={comment.files} />
             </>
           )}
         </div>
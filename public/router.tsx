@@ -20,13 +20,20 @@ const route = (path: string, component: any, showHeader: boolean = true): PageCo
 const pathRegex = [
   route("", Pages.AsyncHomePage),
   route("/posts/:number*", Pages.AsyncShowPostPage),
+  route("/roadmap", Pages.AsyncRoadmapPage),
   route("/admin/members", Pages.AsyncManageMembersPage),
   route("/admin/tags", Pages.AsyncManageTagsPage),
   route("/admin/privacy", Pages.AsyncPrivacySettingsPage),
+  route("/admin/voting", Pages.AsyncVotingSettingsPage),
   route("/admin/billing", Pages.AsyncBillingPage),
   route("/admin/export", Pages.AsyncExportPage),
+  route("/admin/import", Pages.AsyncImportPage),
+  route("/admin/audit", Pages.AsyncAuditLogPage),
+  route("/admin/analytics", Pages.AsyncAnalyticsPage),
   route("/admin/invitations", Pages.AsyncInvitationsPage),
   route("/admin/authentication", Pages.AsyncManageAuthenticationPage),
+  route("/admin/saml", Pages.AsyncSAMLSettingsPage),
+  route("/admin/emails", Pages.AsyncEmailTemplatesPage),
   route("/admin/advanced", Pages.AsyncAdvancedSettingsPage),
   route("/admin", Pages.AsyncGeneralSettingsPage),
   route("/signin", Pages.AsyncSignInPage, false),
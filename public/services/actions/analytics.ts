@@ -0,0 +1,17 @@
+import { http, Result, querystring } from "@fider/services";
+import { Analytics } from "@fider/models";
+
+export interface AnalyticsFilters {
+  since?: string;
+  until?: string;
+  interval?: string;
+}
+
+export const getAnalytics = async (filters: AnalyticsFilters): Promise<Result<Analytics>> => {
+  const qs = querystring.stringify({
+    since: filters.since,
+    until: filters.until,
+    interval: filters.interval
+  });
+  return await http.get<Analytics>(`/api/v1/analytics${qs}`);
+};
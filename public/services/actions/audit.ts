@@ -0,0 +1,26 @@
+import { http, Result, querystring } from "@fider/services";
+import { AuditLog } from "@fider/models";
+
+export interface AuditLogFilters {
+  actor?: number;
+  action?: string;
+  createdAfter?: string;
+  createdBefore?: string;
+}
+
+const auditLogQueryString = (filters: AuditLogFilters): string => {
+  return querystring.stringify({
+    actor: filters.actor,
+    action: filters.action,
+    createdAfter: filters.createdAfter,
+    createdBefore: filters.createdBefore
+  });
+};
+
+export const listAuditLogs = async (filters: AuditLogFilters): Promise<Result<AuditLog[]>> => {
+  return await http.get<AuditLog[]>(`/api/v1/audit${auditLogQueryString(filters)}`);
+};
+
+export const getAuditLogExportURL = (filters: AuditLogFilters): string => {
+  return `/api/v1/audit.csv${auditLogQueryString(filters)}`;
+};
@@ -6,3 +6,5 @@ export * from "./billing";
 export * from "./notification";
 export * from "./invite";
 export * from "./infra";
+export * from "./audit";
+export * from "./analytics";
@@ -1,5 +1,5 @@
 import { http, Result, querystring } from "@fider/services";
-import { Post, Vote, ImageUpload } from "@fider/models";
+import { Post, Vote, ImageUpload, FileUpload } from "@fider/models";
 
 export const getAllPosts = async (): Promise<Result<Post[]>> => {
   return await http.get<Post[]>("/api/v1/posts");
@@ -17,7 +17,7 @@ export const searchPosts = async (params: SearchPostsParams): Promise<Result<Pos
     `/api/v1/posts${querystring.stringify({
       tags: params.tags,
       query: params.query,
-      view: params.view,
+      view: params.view || "trending",
       limit: params.limit
     })}`
   );
@@ -31,8 +31,20 @@ export const deletePost = async (postNumber: number, text: string): Promise<Resu
     .then(http.event("post", "delete"));
 };
 
-export const addVote = async (postNumber: number): Promise<Result> => {
-  return http.post(`/api/v1/posts/${postNumber}/votes`).then(http.event("post", "vote"));
+export const mergePost = async (postNumber: number, originalNumber: number): Promise<Result<Post>> => {
+  return http
+    .post<Post>(`/api/v1/posts/${postNumber}/merge`, {
+      originalNumber
+    })
+    .then(http.event("post", "merge"));
+};
+
+export const unmergePost = async (postNumber: number): Promise<Result<Post>> => {
+  return http.delete<Post>(`/api/v1/posts/${postNumber}/merge`).then(http.event("post", "unmerge"));
+};
+
+export const addVote = async (postNumber: number, credits?: number): Promise<Result> => {
+  return http.post(`/api/v1/posts/${postNumber}/votes`, { credits }).then(http.event("post", "vote"));
 };
 
 export const removeVote = async (postNumber: number): Promise<Result> => {
@@ -54,10 +66,11 @@ export const listVotes = async (postNumber: number): Promise<Result<Vote[]>> =>
 export const createComment = async (
   postNumber: number,
   content: string,
-  attachments: ImageUpload[]
+  attachments: ImageUpload[],
+  files: FileUpload[] = []
 ): Promise<Result> => {
   return http
-    .post(`/api/v1/posts/${postNumber}/comments`, { content, attachments })
+    .post(`/api/v1/posts/${postNumber}/comments`, { content, attachments, files })
     .then(http.event("comment", "create"));
 };
 
@@ -65,10 +78,11 @@ export const updateComment = async (
   postNumber: number,
   commentID: number,
   content: string,
-  attachments: ImageUpload[]
+  attachments: ImageUpload[],
+  files: FileUpload[] = []
 ): Promise<Result> => {
   return http
-    .put(`/api/v1/posts/${postNumber}/comments/${commentID}`, { content, attachments })
+    .put(`/api/v1/posts/${postNumber}/comments/${commentID}`, { content, attachments, files })
     .then(http.event("comment", "update"));
 };
 
@@ -102,10 +116,11 @@ interface CreatePostResponse {
 export const createPost = async (
   title: string,
   description: string,
-  attachments: ImageUpload[]
+  attachments: ImageUpload[],
+  files: FileUpload[] = []
 ): Promise<Result<CreatePostResponse>> => {
   return http
-    .post<CreatePostResponse>(`/api/v1/posts`, { title, description, attachments })
+    .post<CreatePostResponse>(`/api/v1/posts`, { title, description, attachments, files })
     .then(http.event("post", "create"));
 };
 
@@ -113,9 +128,10 @@ export const updatePost = async (
   postNumber: number,
   title: string,
   description: string,
-  attachments: ImageUpload[]
+  attachments: ImageUpload[],
+  files: FileUpload[] = []
 ): Promise<Result> => {
   return http
-    .put(`/api/v1/posts/${postNumber}`, { title, description, attachments })
+    .put(`/api/v1/posts/${postNumber}`, { title, description, attachments, files })
     .then(http.event("post", "update"));
 };
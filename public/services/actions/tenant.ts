@@ -28,19 +28,28 @@ export interface UpdateTenantSettingsRequest {
   invitation: string;
   welcomeMessage: string;
   cname: string;
+  locale: string;
 }
 
 export const updateTenantSettings = async (request: UpdateTenantSettingsRequest): Promise<Result> => {
   return await http.post("/_api/admin/settings/general", request);
 };
 
-export const updateTenantAdvancedSettings = async (customCSS: string): Promise<Result> => {
-  return await http.post("/_api/admin/settings/advanced", { customCSS });
+export const updateTenantAdvancedSettings = async (customCSS: string, allowedFileTypes: string): Promise<Result> => {
+  return await http.post("/_api/admin/settings/advanced", { customCSS, allowedFileTypes });
 };
 
-export const updateTenantPrivacy = async (isPrivate: boolean): Promise<Result> => {
+export const updateTenantPrivacy = async (isPrivate: boolean, isRoadmapPublic: boolean): Promise<Result> => {
   return await http.post("/_api/admin/settings/privacy", {
-    isPrivate
+    isPrivate,
+    isRoadmapPublic
+  });
+};
+
+export const updateTenantVoting = async (mode: string, budget: number): Promise<Result> => {
+  return await http.post("/_api/admin/settings/voting", {
+    mode,
+    budget
   });
 };
 
@@ -75,6 +84,10 @@ export const unblockUser = async (userID: number): Promise<Result> => {
   return await http.delete(`/_api/admin/users/${userID}/block`);
 };
 
+export const clearEmailSuppression = async (address: string): Promise<Result> => {
+  return await http.delete(`/_api/admin/email-suppressions/${encodeURIComponent(address)}`);
+};
+
 export const getOAuthConfig = async (provider: string): Promise<Result<OAuthConfig>> => {
   return await http.get<OAuthConfig>(`/_api/admin/oauth/${provider}`);
 };
@@ -82,6 +95,8 @@ export const getOAuthConfig = async (provider: string): Promise<Result<OAuthConf
 export interface CreateEditOAuthConfigRequest {
   provider: string;
   status: number;
+  protocol: number;
+  issuerURL: string;
   displayName: string;
   clientID: string;
   clientSecret: string;
@@ -98,3 +113,58 @@ export interface CreateEditOAuthConfigRequest {
 export const saveOAuthConfig = async (request: CreateEditOAuthConfigRequest): Promise<Result> => {
   return await http.post("/_api/admin/oauth", request);
 };
+
+export interface CreateEditSAMLConfigRequest {
+  isEnabled: boolean;
+  displayName: string;
+  idpMetadata: string;
+  nameAttribute: string;
+  emailAttribute: string;
+  roleAttribute: string;
+  administratorRoles: string;
+  collaboratorRoles: string;
+}
+
+export const saveSAMLConfig = async (request: CreateEditSAMLConfigRequest): Promise<Result> => {
+  return await http.post("/_api/admin/saml", request);
+};
+
+export interface EmailTemplatePreview {
+  subject: string;
+  body: string;
+}
+
+export const saveEmailTemplate = async (name: string, subject: string, body: string): Promise<Result> => {
+  return await http.put(`/_api/admin/email-templates/${name}`, { subject, body });
+};
+
+export const resetEmailTemplate = async (name: string): Promise<Result> => {
+  return await http.delete(`/_api/admin/email-templates/${name}`);
+};
+
+export const previewEmailTemplate = async (
+  name: string,
+  subject: string,
+  body: string
+): Promise<Result<EmailTemplatePreview>> => {
+  return await http.post<EmailTemplatePreview>(`/_api/admin/email-templates/${name}/preview`, { subject, body });
+};
+
+export const sendSampleEmailTemplate = async (name: string, subject: string, body: string): Promise<Result> => {
+  return await http.post(`/_api/admin/email-templates/${name}/sample`, { subject, body });
+};
+
+export interface ImportReport {
+  dryRun: boolean;
+  imported: { [kind: string]: number };
+  warnings: string[];
+  errors: string[];
+}
+
+export const importData = async (kind: string, content: string, dryRun: boolean): Promise<Result<ImportReport>> => {
+  return await http.post<ImportReport>("/_api/admin/import", {
+    kind,
+    content,
+    dryRun
+  });
+};
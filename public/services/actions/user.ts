@@ -1,10 +1,11 @@
 import { http, Result } from "@fider/services/http";
-import { UserSettings, UserAvatarType, ImageUpload } from "@fider/models";
+import { UserSettings, UserAvatarType, ImageUpload, APIToken } from "@fider/models";
 
 interface UpdateUserSettings {
   name: string;
   avatar?: ImageUpload;
   avatarType: UserAvatarType;
+  locale: string;
   settings: UserSettings;
 }
 
@@ -22,6 +23,27 @@ export const deleteCurrentAccount = async (): Promise<Result> => {
   return await http.delete("/_api/user");
 };
 
-export const regenerateAPIKey = async (): Promise<Result<{ apiKey: string }>> => {
-  return await http.post<{ apiKey: string }>("/_api/user/regenerate-apikey");
+export const listMyAPITokens = async (): Promise<Result<APIToken[]>> => {
+  return await http.get<APIToken[]>("/_api/user/tokens");
+};
+
+interface CreateAPITokenResponse {
+  token: APIToken;
+  key: string;
+}
+
+export const createAPIToken = async (
+  name: string,
+  scopes: string[],
+  expiresInDays: number
+): Promise<Result<CreateAPITokenResponse>> => {
+  return await http.post<CreateAPITokenResponse>("/_api/user/tokens", {
+    name,
+    scopes,
+    expiresInDays
+  });
+};
+
+export const revokeAPIToken = async (id: number): Promise<Result> => {
+  return await http.delete(`/_api/user/tokens/${id}`);
 };
@@ -0,0 +1,17 @@
+subject: Bestätige deine neue E-Mail-Adresse
+body:
+<tr>
+  <td>
+    <p>Hallo <strong>{{ .name }}</strong>,</p>
+    <p>Du hast beantragt, deine E-Mail-Adresse von {{ .oldEmail }} auf {{ .newEmail }} zu ändern.</p>
+    <p>Klicke auf den folgenden Link, um diesen Vorgang zu bestätigen.</p>
+  </td>
+</tr>
+<tr>
+  <td>{{ .link }}</td>
+</tr>
+<tr>
+  <td>
+    <span style="color:#666;font-size:11px">Dieser Link läuft in 24 Stunden ab und kann nur einmal verwendet werden.</span>
+  </td>
+</tr>
\ No newline at end of file
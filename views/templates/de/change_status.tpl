@@ -0,0 +1,29 @@
+subject: [{{ .tenantName }}] {{ .title }}
+body:
+<tr>
+  <td>
+    {{ if .duplicate }}
+      <strong>{{ .title }} ({{ .postLink }})</strong> wurde als <strong>{{ .status }}</strong> von {{ .duplicate }} geschlossen.
+    {{ else }}
+      Der Status von <strong>{{ .title }} ({{ .postLink }})</strong> wurde auf <strong>{{ .status }}</strong> geändert.
+    {{ end }}
+  </td>
+</tr>
+<tr>
+  <td></td>
+  <td height="10" style="line-height:1px;">&nbsp;</td>
+  <td></td>
+</tr>
+<tr>
+  <td style="border-top:1px solid #efefef;">{{ .content }}</td>
+</tr>
+<tr>
+  <td>
+    <span style="color:#666;font-size:11px">
+    — <br />
+    {{ if .reply }}Antworte auf diese E-Mail, um einen Kommentar zu hinterlassen. <br />{{ end }}
+    Du erhältst diese E-Mail, weil du diese Diskussion abonniert hast. <br />
+    {{ .view }}, {{ .unsubscribe }} oder {{ .change }}.
+    </span>
+  </td>
+</tr>
\ No newline at end of file
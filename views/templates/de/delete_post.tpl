@@ -0,0 +1,24 @@
+subject: [{{ .tenantName }}] {{ .title }}
+body:
+<tr>
+  <td>
+    <strong>{{ .title }}</strong> wurde <strong>gelöscht</strong>.
+  </td>
+</tr>
+<tr>
+  <td></td>
+  <td height="10" style="line-height:1px;">&nbsp;</td>
+  <td></td>
+</tr>
+<tr>
+  <td style="border-top:1px solid #efefef;">{{ .content }}</td>
+</tr>
+<tr>
+  <td>
+    <span style="color:#666;font-size:11px">
+    — <br />
+    Du erhältst diese E-Mail, weil du diese Diskussion abonniert hast. <br />
+    {{ .change }}.
+    </span>
+  </td>
+</tr>
\ No newline at end of file
@@ -0,0 +1,29 @@
+subject: [{{ .tenantName }}] Zusammenfassung der letzten Aktivitäten
+body:
+<tr>
+  <td>
+    Das ist auf <strong>{{ .tenantName }}</strong> seit deiner letzten Zusammenfassung passiert.
+  </td>
+</tr>
+<tr>
+  <td></td>
+  <td height="10" style="line-height:1px;">&nbsp;</td>
+  <td></td>
+</tr>
+{{ range .items }}
+<tr>
+  <td style="border-top:1px solid #efefef;">
+    {{ .title }}
+    {{ if .view }}<span style="font-size:12px">{{ .view }}</span>{{ end }}
+  </td>
+</tr>
+{{ end }}
+<tr>
+  <td>
+    <span style="color:#666;font-size:11px">
+    — <br />
+    Du erhältst diese E-Mail, weil du eine Zusammenfassung der Benachrichtigungen gewählt hast. <br />
+    Du kannst {{ .change }}.
+    </span>
+  </td>
+</tr>
\ No newline at end of file
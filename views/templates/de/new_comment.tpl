@@ -0,0 +1,28 @@
+subject: [{{ .tenantName }}] {{ .title }}
+body:
+<tr>
+  <td>
+    <strong>{{ .userName }}</strong> hat einen Kommentar hinterlassen zu 
+    <strong>
+      {{ .title }} ({{ .postLink }})
+    </strong>
+  </td>
+</tr>
+<tr>
+  <td></td>
+  <td height="10" style="line-height:1px;">&nbsp;</td>
+  <td></td>
+</tr>
+<tr>
+  <td style="border-top:1px solid #efefef;">{{ .content }}</td>
+</tr>
+<tr>
+  <td>
+    <span style="color:#666;font-size:11px">
+    — <br />
+    {{ if .reply }}Antworte auf diese E-Mail, um einen Kommentar zu hinterlassen. <br />{{ end }}
+    Du erhältst diese E-Mail, weil du diese Diskussion abonniert hast. <br />
+    {{ .view }}, {{ .unsubscribe }} oder {{ .change }}.
+    </span>
+  </td>
+</tr>
\ No newline at end of file
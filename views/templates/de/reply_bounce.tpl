@@ -0,0 +1,15 @@
+subject: [{{ .tenantName }}] Deine Antwort konnte nicht veröffentlicht werden
+body:
+<tr>
+  <td>
+    Deine Antwort auf <strong>{{ .subject }}</strong> konnte nicht als Kommentar veröffentlicht werden, weil {{ .reason }}.
+  </td>
+</tr>
+<tr>
+  <td>
+    <span style="color:#666;font-size:11px">
+    — <br />
+    Du erhältst diese E-Mail, weil wir eine an dein Konto gerichtete E-Mail-Antwort erhalten haben.
+    </span>
+  </td>
+</tr>
\ No newline at end of file
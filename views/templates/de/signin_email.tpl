@@ -0,0 +1,16 @@
+subject: Bei {{ .tenantName }} anmelden
+body:
+<tr>
+  <td>Klicke auf den folgenden Link, um dich bei <strong>{{ .tenantName }}</strong> anzumelden.</td>
+</tr>
+<tr>
+  <td height="20">&nbsp;</td>
+</tr>
+<tr>
+  <td>{{ .link }}</td>
+</tr>
+<tr>
+  <td>
+    <span style="color:#666;font-size:11px">Dieser Link läuft in 30 Minuten ab und kann nur einmal verwendet werden.</span>
+  </td>
+</tr>
\ No newline at end of file
@@ -0,0 +1,29 @@
+subject: [{{ .tenantName }}] Summary of recent activity
+body:
+<tr>
+  <td>
+    Here is what happened on <strong>{{ .tenantName }}</strong> since your last digest.
+  </td>
+</tr>
+<tr>
+  <td></td>
+  <td height="10" style="line-height:1px;">&nbsp;</td>
+  <td></td>
+</tr>
+{{ range .items }}
+<tr>
+  <td style="border-top:1px solid #efefef;">
+    {{ .title }}
+    {{ if .view }}<span style="font-size:12px">{{ .view }}</span>{{ end }}
+  </td>
+</tr>
+{{ end }}
+<tr>
+  <td>
+    <span style="color:#666;font-size:11px">
+    — <br />
+    You are receiving this because you chose to receive a summary of notifications. <br />
+    You can {{ .change }}.
+    </span>
+  </td>
+</tr>
\ No newline at end of file
@@ -0,0 +1,17 @@
+subject: Confirmez votre nouvelle adresse email
+body:
+<tr>
+  <td>
+    <p>Bonjour <strong>{{ .name }}</strong>,</p>
+    <p>Vous avez demandé à changer votre adresse email de {{ .oldEmail }} en {{ .newEmail }}.</p>
+    <p>Cliquez sur le lien ci-dessous pour confirmer cette opération.</p>
+  </td>
+</tr>
+<tr>
+  <td>{{ .link }}</td>
+</tr>
+<tr>
+  <td>
+    <span style="color:#666;font-size:11px">Ce lien expirera dans 24 heures et ne peut être utilisé qu'une seule fois.</span>
+  </td>
+</tr>
\ No newline at end of file
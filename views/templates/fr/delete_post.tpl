@@ -0,0 +1,24 @@
+subject: [{{ .tenantName }}] {{ .title }}
+body:
+<tr>
+  <td>
+    <strong>{{ .title }}</strong> a été <strong>supprimée</strong>.
+  </td>
+</tr>
+<tr>
+  <td></td>
+  <td height="10" style="line-height:1px;">&nbsp;</td>
+  <td></td>
+</tr>
+<tr>
+  <td style="border-top:1px solid #efefef;">{{ .content }}</td>
+</tr>
+<tr>
+  <td>
+    <span style="color:#666;font-size:11px">
+    — <br />
+    Vous recevez cet email car vous êtes abonné à cette discussion. <br />
+    {{ .change }}.
+    </span>
+  </td>
+</tr>
\ No newline at end of file
@@ -0,0 +1,29 @@
+subject: [{{ .tenantName }}] Résumé de l'activité récente
+body:
+<tr>
+  <td>
+    Voici ce qui s'est passé sur <strong>{{ .tenantName }}</strong> depuis votre dernier résumé.
+  </td>
+</tr>
+<tr>
+  <td></td>
+  <td height="10" style="line-height:1px;">&nbsp;</td>
+  <td></td>
+</tr>
+{{ range .items }}
+<tr>
+  <td style="border-top:1px solid #efefef;">
+    {{ .title }}
+    {{ if .view }}<span style="font-size:12px">{{ .view }}</span>{{ end }}
+  </td>
+</tr>
+{{ end }}
+<tr>
+  <td>
+    <span style="color:#666;font-size:11px">
+    — <br />
+    Vous recevez cet email car vous avez choisi de recevoir un résumé des notifications. <br />
+    Vous pouvez {{ .change }}.
+    </span>
+  </td>
+</tr>
\ No newline at end of file
@@ -0,0 +1,25 @@
+subject: [{{ .tenantName }}] {{ .title }}
+body:
+<tr>
+  <td>
+    <strong>{{ .userName }}</strong> a créé une nouvelle suggestion <strong>{{ .title }} ({{ .postLink }})</strong>.
+  </td>
+</tr>
+<tr>
+  <td></td>
+  <td height="10" style="line-height:1px;">&nbsp;</td>
+  <td></td>
+</tr>
+<tr>
+  <td style="border-top:1px solid #efefef;">{{ .content }}</td>
+</tr>
+<tr>
+  <td>
+    <span style="color:#666;font-size:11px">
+    — <br />
+    {{ if .reply }}Répondez à cet email pour laisser un commentaire. <br />{{ end }}
+    Vous recevez cet email car vous êtes abonné à cet événement. <br />
+    {{ .view }} ou {{ .change }}.
+    </span>
+  </td>
+</tr>
\ No newline at end of file
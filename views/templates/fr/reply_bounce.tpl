@@ -0,0 +1,15 @@
+subject: [{{ .tenantName }}] Votre réponse n'a pas pu être publiée
+body:
+<tr>
+  <td>
+    Votre réponse à <strong>{{ .subject }}</strong> n'a pas pu être publiée comme commentaire car {{ .reason }}.
+  </td>
+</tr>
+<tr>
+  <td>
+    <span style="color:#666;font-size:11px">
+    — <br />
+    Vous recevez cet email car nous avons reçu une réponse par email adressée à votre compte.
+    </span>
+  </td>
+</tr>
\ No newline at end of file
@@ -0,0 +1,16 @@
+subject: Connexion à {{ .tenantName }}
+body:
+<tr>
+  <td>Cliquez sur le lien ci-dessous pour vous connecter à <strong>{{ .tenantName }}</strong>.</td>
+</tr>
+<tr>
+  <td height="20">&nbsp;</td>
+</tr>
+<tr>
+  <td>{{ .link }}</td>
+</tr>
+<tr>
+  <td>
+    <span style="color:#666;font-size:11px">Ce lien expirera dans 30 minutes et ne peut être utilisé qu'une seule fois.</span>
+  </td>
+</tr>
\ No newline at end of file
@@ -20,6 +20,7 @@ body:
   <td>
     <span style="color:#666;font-size:11px">
     — <br />
+    {{ if .reply }}Reply to this email to leave a comment. <br />{{ end }}
     You are receiving this because you are subscribed to this thread. <br />
     {{ .view }}, {{ .unsubscribe }} or {{ .change }}.
     </span>
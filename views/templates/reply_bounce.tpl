@@ -0,0 +1,15 @@
+subject: [{{ .tenantName }}] Your reply could not be posted
+body:
+<tr>
+  <td>
+    Your reply to <strong>{{ .subject }}</strong> could not be posted as a comment because {{ .reason }}.
+  </td>
+</tr>
+<tr>
+  <td>
+    <span style="color:#666;font-size:11px">
+    — <br />
+    You are receiving this because we received an email reply addressed to your account.
+    </span>
+  </td>
+</tr>
\ No newline at end of file